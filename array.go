@@ -79,6 +79,9 @@ func (a *arrayObject) init() {
 }
 
 func (a *arrayObject) _setLengthInt(l uint32, throw bool) bool {
+	if l > a.length {
+		a.val.runtime.checkArrayLength(int64(l))
+	}
 	ret := true
 	if l <= a.length {
 		if a.propValueCount > 0 {
@@ -31,6 +31,9 @@ func (a *sparseArrayObject) findIdx(idx uint32) int {
 }
 
 func (a *sparseArrayObject) _setLengthInt(l uint32, throw bool) bool {
+	if l > a.length {
+		a.val.runtime.checkArrayLength(int64(l))
+	}
 	ret := true
 	if l <= a.length {
 		if a.propValueCount > 0 {
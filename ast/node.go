@@ -294,6 +294,14 @@ type (
 		Meta, Property *Identifier
 		Idx            file.Idx
 	}
+
+	// ImportCall is a dynamic import, i.e. import(specifier) or import(specifier, options).
+	ImportCall struct {
+		Import           file.Idx
+		Argument         Expression
+		Options          Expression
+		RightParenthesis file.Idx
+	}
 )
 
 // _expressionNode
@@ -325,6 +333,7 @@ func (*ThisExpression) _expressionNode()        {}
 func (*SuperExpression) _expressionNode()       {}
 func (*UnaryExpression) _expressionNode()       {}
 func (*MetaProperty) _expressionNode()          {}
+func (*ImportCall) _expressionNode()            {}
 func (*ObjectPattern) _expressionNode()         {}
 func (*ArrayPattern) _expressionNode()          {}
 func (*Binding) _expressionNode()               {}
@@ -402,6 +411,7 @@ type (
 		Into   ForInto
 		Source Expression
 		Body   Statement
+		Await  bool
 	}
 
 	ForStatement struct {
@@ -456,7 +466,8 @@ type (
 
 	LexicalDeclaration struct {
 		Idx   file.Idx
-		Token token.Token
+		Token token.Token // LET, CONST or USING
+		Await bool        // set for 'await using' declarations
 		List  []*Binding
 	}
 
@@ -479,6 +490,32 @@ type (
 	ClassDeclaration struct {
 		Class *ClassLiteral
 	}
+
+	// ImportDeclaration represents an import declaration. For an import that only loads the module
+	// (import "m") all the bindings are nil.
+	ImportDeclaration struct {
+		Import           file.Idx
+		DefaultBinding   *Identifier        // import x from "m"
+		NamespaceBinding *Identifier        // import * as x from "m"
+		NamedImports     []*ImportSpecifier // import {x, y as z} from "m"
+		ModuleSpecifier  *StringLiteral
+		With             *WithClause // import x from "m" with { type: "json" }
+	}
+
+	// ExportDeclaration represents an export declaration. Depending on the form, either Declaration,
+	// Expression (export default <expression>) or Specifiers is set. For 'export * as ns from "m"'
+	// Star is true and Specifiers contains a single item with LocalName set to nil.
+	ExportDeclaration struct {
+		Export          file.Idx
+		Declaration     Statement // VariableStatement, LexicalDeclaration, FunctionDeclaration or ClassDeclaration
+		Default         bool
+		Expression      Expression
+		Star            bool
+		Specifiers      []*ExportSpecifier
+		ModuleSpecifier *StringLiteral
+		With            *WithClause
+		RightBrace      file.Idx
+	}
 )
 
 // _statementNode
@@ -507,6 +544,8 @@ func (*WithStatement) _statementNode()       {}
 func (*LexicalDeclaration) _statementNode()  {}
 func (*FunctionDeclaration) _statementNode() {}
 func (*ClassDeclaration) _statementNode()    {}
+func (*ImportDeclaration) _statementNode()   {}
+func (*ExportDeclaration) _statementNode()   {}
 
 // =========== //
 // Declaration //
@@ -546,6 +585,33 @@ type (
 		Source          string
 		DeclarationList []*VariableDeclaration
 	}
+
+	// ImportSpecifier is an item of an import list. ImportName is either an IdentifierName or a string
+	// literal, Alias is the local binding (the same name as ImportName when there is no 'as' clause).
+	ImportSpecifier struct {
+		ImportName *StringLiteral
+		Alias      *Identifier
+	}
+
+	// ExportSpecifier is an item of an export list. Both names are either IdentifierNames or string literals.
+	ExportSpecifier struct {
+		LocalName  *StringLiteral
+		ExportName *StringLiteral
+	}
+
+	// WithClause is the list of import attributes of an import or export declaration,
+	// i.e. with { type: "json" }.
+	WithClause struct {
+		With       file.Idx
+		Attributes []*ImportAttribute
+		RightBrace file.Idx
+	}
+
+	// ImportAttribute is an item of a WithClause. Key is either an IdentifierName or a string literal.
+	ImportAttribute struct {
+		Key   *StringLiteral
+		Value *StringLiteral
+	}
 )
 
 type (
@@ -577,9 +643,11 @@ type (
 	}
 
 	ForDeclaration struct {
-		Idx     file.Idx
-		IsConst bool
-		Target  BindingTarget
+		Idx          file.Idx
+		IsConst      bool
+		IsUsing      bool
+		IsAwaitUsing bool
+		Target       BindingTarget
 	}
 
 	ForIntoExpression struct {
@@ -663,6 +731,7 @@ func (self *ThisExpression) Idx0() file.Idx        { return self.Idx }
 func (self *SuperExpression) Idx0() file.Idx       { return self.Idx }
 func (self *UnaryExpression) Idx0() file.Idx       { return self.Idx }
 func (self *MetaProperty) Idx0() file.Idx          { return self.Idx }
+func (self *ImportCall) Idx0() file.Idx            { return self.Import }
 
 func (self *BadStatement) Idx0() file.Idx        { return self.From }
 func (self *BlockStatement) Idx0() file.Idx      { return self.LeftBrace }
@@ -689,6 +758,8 @@ func (self *WithStatement) Idx0() file.Idx       { return self.With }
 func (self *LexicalDeclaration) Idx0() file.Idx  { return self.Idx }
 func (self *FunctionDeclaration) Idx0() file.Idx { return self.Function.Idx0() }
 func (self *ClassDeclaration) Idx0() file.Idx    { return self.Class.Idx0() }
+func (self *ImportDeclaration) Idx0() file.Idx   { return self.Import }
+func (self *ExportDeclaration) Idx0() file.Idx   { return self.Export }
 func (self *Binding) Idx0() file.Idx             { return self.Target.Idx0() }
 
 func (self *ForLoopInitializerExpression) Idx0() file.Idx  { return self.Expression.Idx0() }
@@ -702,6 +773,15 @@ func (self *VariableDeclaration) Idx0() file.Idx { return self.Var }
 func (self *FieldDefinition) Idx0() file.Idx     { return self.Idx }
 func (self *MethodDefinition) Idx0() file.Idx    { return self.Idx }
 func (self *ClassStaticBlock) Idx0() file.Idx    { return self.Static }
+func (self *ImportSpecifier) Idx0() file.Idx     { return self.ImportName.Idx0() }
+func (self *WithClause) Idx0() file.Idx          { return self.With }
+func (self *ImportAttribute) Idx0() file.Idx     { return self.Key.Idx0() }
+func (self *ExportSpecifier) Idx0() file.Idx {
+	if self.LocalName != nil {
+		return self.LocalName.Idx0()
+	}
+	return self.ExportName.Idx0()
+}
 
 func (self *ForDeclaration) Idx0() file.Idx    { return self.Idx }
 func (self *ForIntoVar) Idx0() file.Idx        { return self.Binding.Idx0() }
@@ -755,6 +835,7 @@ func (self *UnaryExpression) Idx1() file.Idx {
 func (self *MetaProperty) Idx1() file.Idx {
 	return self.Property.Idx1()
 }
+func (self *ImportCall) Idx1() file.Idx { return self.RightParenthesis + 1 }
 
 func (self *BadStatement) Idx1() file.Idx        { return self.To }
 func (self *BlockStatement) Idx1() file.Idx      { return self.RightBrace + 1 }
@@ -794,6 +875,25 @@ func (self *WithStatement) Idx1() file.Idx       { return self.Body.Idx1() }
 func (self *LexicalDeclaration) Idx1() file.Idx  { return self.List[len(self.List)-1].Idx1() }
 func (self *FunctionDeclaration) Idx1() file.Idx { return self.Function.Idx1() }
 func (self *ClassDeclaration) Idx1() file.Idx    { return self.Class.Idx1() }
+func (self *ImportDeclaration) Idx1() file.Idx {
+	if self.With != nil {
+		return self.With.Idx1()
+	}
+	return self.ModuleSpecifier.Idx1()
+}
+func (self *ExportDeclaration) Idx1() file.Idx {
+	switch {
+	case self.With != nil:
+		return self.With.Idx1()
+	case self.ModuleSpecifier != nil:
+		return self.ModuleSpecifier.Idx1()
+	case self.Declaration != nil:
+		return self.Declaration.Idx1()
+	case self.Expression != nil:
+		return self.Expression.Idx1()
+	}
+	return self.RightBrace + 1
+}
 func (self *Binding) Idx1() file.Idx {
 	if self.Initializer != nil {
 		return self.Initializer.Idx1()
@@ -839,6 +939,11 @@ func (self *ClassStaticBlock) Idx1() file.Idx {
 	return self.Block.Idx1()
 }
 
+func (self *ImportSpecifier) Idx1() file.Idx { return self.Alias.Idx1() }
+func (self *WithClause) Idx1() file.Idx      { return self.RightBrace + 1 }
+func (self *ImportAttribute) Idx1() file.Idx { return self.Value.Idx1() }
+func (self *ExportSpecifier) Idx1() file.Idx { return self.ExportName.Idx1() }
+
 func (self *ForDeclaration) Idx1() file.Idx    { return self.Target.Idx1() }
 func (self *ForIntoVar) Idx1() file.Idx        { return self.Binding.Idx1() }
 func (self *ForIntoExpression) Idx1() file.Idx { return self.Expression.Idx1() }
@@ -23,6 +23,7 @@ func (r *Runtime) newArrayObject() *arrayObject {
 }
 
 func setArrayValues(a *arrayObject, values []Value) *arrayObject {
+	a.val.runtime.checkArrayLength(int64(len(values)))
 	a.values = values
 	a.length = uint32(len(values))
 	a.objCount = len(values)
@@ -188,7 +189,7 @@ func (r *Runtime) arrayproto_join(call FunctionCall) Value {
 		return stringEmpty
 	}
 
-	var buf valueStringBuilder
+	buf := limitedStringBuilder{r: r}
 
 	element0 := o.self.getIdx(valueInt(0), nil)
 	if element0 != nil && element0 != _undefined && element0 != _null {
@@ -221,7 +222,7 @@ func (r *Runtime) arrayproto_toString(call FunctionCall) Value {
 	})
 }
 
-func (r *Runtime) writeItemLocaleString(item Value, buf *valueStringBuilder) {
+func (r *Runtime) writeItemLocaleString(item Value, buf *limitedStringBuilder) {
 	if item != nil && item != _undefined && item != _null {
 		if f, ok := r.getVStr(item, "toLocaleString").(*Object); ok {
 			if c, ok := f.self.assertCallable(); ok {
@@ -238,7 +239,7 @@ func (r *Runtime) writeItemLocaleString(item Value, buf *valueStringBuilder) {
 
 func (r *Runtime) arrayproto_toLocaleString(call FunctionCall) Value {
 	array := call.This.ToObject(r)
-	var buf valueStringBuilder
+	buf := limitedStringBuilder{r: r}
 	if a := r.checkStdArrayObj(array); a != nil {
 		for i, item := range a.values {
 			if i > 0 {
@@ -545,11 +546,7 @@ func (r *Runtime) arrayproto_at(call FunctionCall) Value {
 	if idx >= length || idx < 0 {
 		return _undefined
 	}
-	i := valueInt(idx)
-	if o.self.hasPropertyIdx(i) {
-		return o.self.getIdx(i, nil)
-	}
-	return _undefined
+	return nilSafe(o.self.getIdx(valueInt(idx), nil))
 }
 
 func (r *Runtime) arrayproto_indexOf(call FunctionCall) Value {
@@ -1124,7 +1121,7 @@ func (r *Runtime) arrayproto_findLast(call FunctionCall) Value {
 	}
 	for k := int64(l - 1); k >= 0; k-- {
 		idx := valueInt(k)
-		kValue := o.self.getIdx(idx, nil)
+		kValue := nilSafe(o.self.getIdx(idx, nil))
 		fc.Arguments[0], fc.Arguments[1] = kValue, idx
 		if predicate(fc).ToBoolean() {
 			return kValue
@@ -1144,7 +1141,7 @@ func (r *Runtime) arrayproto_findLastIndex(call FunctionCall) Value {
 	}
 	for k := int64(l - 1); k >= 0; k-- {
 		idx := valueInt(k)
-		kValue := o.self.getIdx(idx, nil)
+		kValue := nilSafe(o.self.getIdx(idx, nil))
 		fc.Arguments[0], fc.Arguments[1] = kValue, idx
 		if predicate(fc).ToBoolean() {
 			return idx
@@ -1324,6 +1321,7 @@ func (r *Runtime) array_from(call FunctionCall) Value {
 		}
 		if mapFn == nil {
 			if a := r.checkStdArrayObjWithProto(arr); a != nil {
+				r.checkArrayLength(l)
 				values := make([]Value, l)
 				for k := int64(0); k < l; k++ {
 					values[k] = nilSafe(arrayLike.self.getIdx(valueInt(k), nil))
@@ -1348,6 +1346,148 @@ func (r *Runtime) array_from(call FunctionCall) Value {
 	return arr
 }
 
+// arrayFromAsync holds the state of an Array.fromAsync() call between the awaits.
+type arrayFromAsync struct {
+	r         *Runtime
+	pcap      *promiseCapability
+	arr       *Object
+	mapFn     func(FunctionCall) Value
+	thisArg   Value
+	iter      *iteratorRecord
+	arrayLike *Object
+	length    int64
+	k         int64
+}
+
+func (a *arrayFromAsync) reject(reason Value) {
+	a.pcap.reject(reason)
+}
+
+// closeAndReject closes the iterator (if any) and rejects the result with the reason.
+func (a *arrayFromAsync) closeAndReject(reason Value) {
+	if a.iter != nil {
+		_ = a.r.vm.try(func() {
+			a.iter.returnIter()
+		})
+	}
+	a.pcap.reject(reason)
+}
+
+func (a *arrayFromAsync) step() {
+	a.pcap.try(func() {
+		if a.iter != nil {
+			if a.iter.next == nil {
+				panic(a.r.NewTypeError("iterator.next is missing or not a function"))
+			}
+			res := a.iter.next(FunctionCall{This: a.iter.iterator})
+			a.r.awaitValue(res, a.onNext, a.reject)
+			return
+		}
+		if a.k >= a.length {
+			a.arr.self.setOwnStr("length", intToValue(a.length), true)
+			a.pcap.resolve(a.arr)
+			return
+		}
+		a.r.awaitValue(nilSafe(a.arrayLike.self.getIdx(valueInt(a.k), nil)), a.onValue, a.reject)
+	})
+}
+
+func (a *arrayFromAsync) onNext(res Value) {
+	a.pcap.try(func() {
+		resObj, ok := res.(*Object)
+		if !ok {
+			panic(a.r.NewTypeError("Iterator result %s is not an object", res.String()))
+		}
+		if nilSafe(resObj.self.getStr("done", nil)).ToBoolean() {
+			a.arr.self.setOwnStr("length", intToValue(a.k), true)
+			a.pcap.resolve(a.arr)
+			return
+		}
+		a.onValue(nilSafe(resObj.self.getStr("value", nil)))
+	})
+}
+
+func (a *arrayFromAsync) onValue(value Value) {
+	if a.mapFn == nil {
+		a.addValue(value)
+		return
+	}
+	var mapped Value
+	ex := a.r.vm.try(func() {
+		mapped = a.mapFn(FunctionCall{This: a.thisArg, Arguments: []Value{value, intToValue(a.k)}})
+	})
+	if ex != nil {
+		a.closeAndReject(ex.val)
+		return
+	}
+	ex = a.r.vm.try(func() {
+		a.r.awaitValue(mapped, a.addValue, a.closeAndReject)
+	})
+	if ex != nil {
+		a.closeAndReject(ex.val)
+	}
+}
+
+func (a *arrayFromAsync) addValue(value Value) {
+	ex := a.r.vm.try(func() {
+		createDataPropertyOrThrow(a.arr, intToValue(a.k), value)
+	})
+	if ex != nil {
+		a.closeAndReject(ex.val)
+		return
+	}
+	a.k++
+	a.step()
+}
+
+func (r *Runtime) array_fromAsync(call FunctionCall) Value {
+	pcap := r.newPromiseCapability(r.global.Promise)
+	pcap.try(func() {
+		a := &arrayFromAsync{
+			r:       r,
+			pcap:    pcap,
+			thisArg: call.Argument(2),
+		}
+		if mapFnArg := call.Argument(1); mapFnArg != _undefined {
+			if mapFnObj, ok := mapFnArg.(*Object); ok {
+				if fn, ok := mapFnObj.self.assertCallable(); ok {
+					a.mapFn = fn
+				}
+			}
+			if a.mapFn == nil {
+				panic(r.NewTypeError("%s is not a function", mapFnArg))
+			}
+		}
+		var ctor func(args []Value, newTarget *Object) *Object
+		if o, ok := call.This.(*Object); ok {
+			ctor = o.self.assertConstructor()
+		}
+		items := call.Argument(0)
+		if usingAsyncIterator := toMethod(r.getV(items, SymAsyncIterator)); usingAsyncIterator != nil {
+			a.iter = r.getIterator(items, usingAsyncIterator)
+		} else if usingIterator := toMethod(r.getV(items, SymIterator)); usingIterator != nil {
+			a.iter = r.createAsyncFromSyncIterator(r.getIterator(items, usingIterator))
+		}
+		if a.iter != nil {
+			if ctor != nil {
+				a.arr = ctor([]Value{}, nil)
+			} else {
+				a.arr = r.newArrayValues(nil)
+			}
+		} else {
+			a.arrayLike = items.ToObject(r)
+			a.length = toLength(a.arrayLike.self.getStr("length", nil))
+			if ctor != nil {
+				a.arr = ctor([]Value{intToValue(a.length)}, nil)
+			} else {
+				a.arr = r.newArrayValues(nil)
+			}
+		}
+		a.step()
+	})
+	return pcap.promise
+}
+
 func (r *Runtime) array_isArray(call FunctionCall) Value {
 	if o, ok := call.Argument(0).(*Object); ok {
 		if isArray(o) {
@@ -1438,6 +1578,7 @@ func (r *Runtime) createArrayProto(val *Object) objectImpl {
 	o._putSym(SymIterator, valueProp(r.global.arrayValues, true, false, true))
 
 	bl := r.newBaseObject(nil, classObject)
+	bl.setOwnStr("at", valueTrue, true)
 	bl.setOwnStr("copyWithin", valueTrue, true)
 	bl.setOwnStr("entries", valueTrue, true)
 	bl.setOwnStr("fill", valueTrue, true)
@@ -1460,6 +1601,7 @@ func (r *Runtime) createArrayProto(val *Object) objectImpl {
 func (r *Runtime) createArray(val *Object) objectImpl {
 	o := r.newNativeFuncConstructObj(val, r.builtin_newArray, "Array", r.global.ArrayPrototype, 1)
 	o._putProp("from", r.newNativeFunc(r.array_from, nil, "from", nil, 1), true, false, true)
+	o._putProp("fromAsync", r.newNativeFunc(r.array_fromAsync, nil, "fromAsync", nil, 1), true, false, true)
 	o._putProp("isArray", r.newNativeFunc(r.array_isArray, nil, "isArray", nil, 1), true, false, true)
 	o._putProp("of", r.newNativeFunc(r.array_of, nil, "of", nil, 0), true, false, true)
 	r.putSpeciesReturnThis(o)
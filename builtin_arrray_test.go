@@ -323,3 +323,62 @@ func TestArrayFlatMap(t *testing.T) {
 	`
 	testScriptWithTestLibX(SCRIPT, _undefined, t)
 }
+
+func TestArrayAt(t *testing.T) {
+	const SCRIPT = `
+	var array = [1, 2, , 4];
+	assert.sameValue(array.at(0), 1, "#1");
+	assert.sameValue(array.at(-1), 4, "#2");
+	assert.sameValue(array.at(-4), 1, "#3");
+	assert.sameValue(array.at(-5), undefined, "#4");
+	assert.sameValue(array.at(4), undefined, "#5");
+	assert.sameValue(array.at(1.7), 2, "#6");
+	assert.sameValue(array.at(), 1, "#7");
+
+	Array.prototype[2] = "proto";
+	try {
+		assert.sameValue(array.at(2), "proto", "hole");
+	} finally {
+		delete Array.prototype[2];
+	}
+
+	assert.sameValue(Array.prototype.at.call({length: 2, 1: "x"}, -1), "x", "array-like");
+	assert.sameValue(Array.prototype[Symbol.unscopables].at, true, "unscopables");
+
+	assert.sameValue("abc".at(-1), "c", "string #1");
+	assert.sameValue("abc".at(3), undefined, "string #2");
+	assert.sameValue("😀".at(0), "\ud83d", "string #3");
+
+	assert.sameValue(new Int8Array([1, 2, 3]).at(-2), 2, "typed array #1");
+	assert.sameValue(new Float64Array(1).at(1), undefined, "typed array #2");
+	assert.throws(TypeError, function() { Int8Array.prototype.at.call([1], 0) }, "typed array #3");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestArrayFindLast(t *testing.T) {
+	const SCRIPT = `
+	var array = [1, 2, 3, 4];
+	var visited = [];
+	assert.sameValue(array.findLast(function(v, i, a) {
+		visited.push(i);
+		assert.sameValue(a, array, "array argument");
+		return v % 2 === 1;
+	}), 3, "#1");
+	assert(compareArray(visited, [3, 2]), "visiting order");
+	assert.sameValue(array.findLastIndex(function(v) { return v < 3 }), 1, "#2");
+	assert.sameValue(array.findLast(function() { return false }), undefined, "#3");
+	assert.sameValue(array.findLastIndex(function() { return false }), -1, "#4");
+
+	var holes = [1, , 3];
+	assert.sameValue(holes.findLastIndex(function(v) { return v === undefined }), 1, "holes #1");
+	assert.sameValue(typeof holes.findLast(function(v) { return v === undefined }), "undefined", "holes #2");
+
+	var thisArg = {};
+	array.findLast(function() { assert.sameValue(this, thisArg, "thisArg"); return true }, thisArg);
+	assert.sameValue(Array.prototype.findLast.call({length: 2, 0: "a", 1: "b"}, function() { return true }), "b", "array-like");
+	assert.throws(TypeError, function() { array.findLast() }, "non-callable predicate");
+	assert.sameValue(Array.prototype[Symbol.unscopables].findLastIndex, true, "unscopables");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
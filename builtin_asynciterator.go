@@ -0,0 +1,166 @@
+package goja
+
+type asyncFromSyncIterObject struct {
+	baseObject
+	syncIter *iteratorRecord
+}
+
+func (r *Runtime) getAsyncIterator(obj Value) *iteratorRecord {
+	if method := toMethod(r.getV(obj, SymAsyncIterator)); method != nil {
+		return r.getIterator(obj, method)
+	}
+	return r.createAsyncFromSyncIterator(r.getIterator(obj, nil))
+}
+
+func (r *Runtime) createAsyncFromSyncIterator(syncIter *iteratorRecord) *iteratorRecord {
+	o := &Object{runtime: r}
+	ai := &asyncFromSyncIterObject{
+		syncIter: syncIter,
+	}
+	ai.class = classObject
+	ai.val = o
+	ai.extensible = true
+	o.self = ai
+	ai.prototype = r.global.AsyncFromSyncIteratorPrototype
+	ai.init()
+
+	var next func(FunctionCall) Value
+	if nextObj, ok := o.self.getStr("next", nil).(*Object); ok {
+		next, _ = nextObj.self.assertCallable()
+	}
+	return &iteratorRecord{
+		iterator: o,
+		next:     next,
+	}
+}
+
+// awaitValue resolves v into a Promise and calls onFulfilled or onRejected when it is settled, much like 'await'
+// does. Any exceptions thrown by the callbacks are ignored, so they should be handled by the callbacks themselves.
+func (r *Runtime) awaitValue(v Value, onFulfilled, onRejected func(Value)) {
+	p := r.promiseResolve(r.global.Promise, v)
+	p.self.(*Promise).addReactions(&promiseReaction{
+		typ: promiseReactionFulfill,
+		handler: &jobCallback{callback: func(call FunctionCall) Value {
+			onFulfilled(call.Argument(0))
+			return _undefined
+		}},
+	}, &promiseReaction{
+		typ: promiseReactionReject,
+		handler: &jobCallback{callback: func(call FunctionCall) Value {
+			onRejected(call.Argument(0))
+			return _undefined
+		}},
+	})
+}
+
+func (r *Runtime) asyncFromSyncIteratorContinuation(result *Object, pcap *promiseCapability, syncIter *iteratorRecord, closeOnRejection bool) {
+	done := nilSafe(result.self.getStr("done", nil)).ToBoolean()
+	value := nilSafe(result.self.getStr("value", nil))
+	var valueWrapper *Object
+	ex := r.vm.try(func() {
+		valueWrapper = r.promiseResolve(r.global.Promise, value)
+	})
+	if ex != nil {
+		if !done && closeOnRejection {
+			syncIter.returnIter()
+		}
+		panic(ex)
+	}
+	onFulfilled := r.newNativeFunc(func(call FunctionCall) Value {
+		return r.createIterResultObject(call.Argument(0), done)
+	}, nil, "", nil, 1)
+	var onRejected Value = _undefined
+	if !done && closeOnRejection {
+		onRejected = r.newNativeFunc(func(call FunctionCall) Value {
+			syncIter.returnIter()
+			panic(call.Argument(0))
+		}, nil, "", nil, 1)
+	}
+	r.performPromiseThen(valueWrapper.self.(*Promise), onFulfilled, onRejected, pcap)
+}
+
+func (r *Runtime) asyncFromSyncIterProto_next(call FunctionCall) Value {
+	ai := r.toObject(call.This).self.(*asyncFromSyncIterObject)
+	pcap := r.newPromiseCapability(r.global.Promise)
+	pcap.try(func() {
+		syncIter := ai.syncIter
+		if syncIter.next == nil {
+			panic(r.NewTypeError("iterator.next is missing or not a function"))
+		}
+		var args []Value
+		if len(call.Arguments) > 0 {
+			args = call.Arguments[:1]
+		}
+		result := r.toObject(syncIter.next(FunctionCall{This: syncIter.iterator, Arguments: args}))
+		r.asyncFromSyncIteratorContinuation(result, pcap, syncIter, true)
+	})
+	return pcap.promise
+}
+
+func (r *Runtime) asyncFromSyncIterProto_return(call FunctionCall) Value {
+	ai := r.toObject(call.This).self.(*asyncFromSyncIterObject)
+	pcap := r.newPromiseCapability(r.global.Promise)
+	pcap.try(func() {
+		syncIter := ai.syncIter
+		retMethod := toMethod(syncIter.iterator.self.getStr("return", nil))
+		if retMethod == nil {
+			pcap.resolve(r.createIterResultObject(call.Argument(0), true))
+			return
+		}
+		var args []Value
+		if len(call.Arguments) > 0 {
+			args = call.Arguments[:1]
+		}
+		result, ok := retMethod(FunctionCall{This: syncIter.iterator, Arguments: args}).(*Object)
+		if !ok {
+			panic(r.NewTypeError("iterator.return() result is not an object"))
+		}
+		r.asyncFromSyncIteratorContinuation(result, pcap, syncIter, false)
+	})
+	return pcap.promise
+}
+
+func (r *Runtime) asyncFromSyncIterProto_throw(call FunctionCall) Value {
+	ai := r.toObject(call.This).self.(*asyncFromSyncIterObject)
+	pcap := r.newPromiseCapability(r.global.Promise)
+	pcap.try(func() {
+		syncIter := ai.syncIter
+		throwMethod := toMethod(syncIter.iterator.self.getStr("throw", nil))
+		if throwMethod == nil {
+			syncIter.returnIter()
+			panic(r.NewTypeError("The iterator does not provide a 'throw' method"))
+		}
+		var args []Value
+		if len(call.Arguments) > 0 {
+			args = call.Arguments[:1]
+		}
+		result, ok := throwMethod(FunctionCall{This: syncIter.iterator, Arguments: args}).(*Object)
+		if !ok {
+			panic(r.NewTypeError("iterator.throw() result is not an object"))
+		}
+		r.asyncFromSyncIteratorContinuation(result, pcap, syncIter, true)
+	})
+	return pcap.promise
+}
+
+func (r *Runtime) createAsyncIterProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putSym(SymAsyncIterator, valueProp(r.newNativeFunc(r.returnThis, nil, "[Symbol.asyncIterator]", nil, 0), true, false, true))
+	return o
+}
+
+func (r *Runtime) createAsyncFromSyncIterProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.AsyncIteratorPrototype, classObject)
+
+	o._putProp("next", r.newNativeFunc(r.asyncFromSyncIterProto_next, nil, "next", nil, 1), true, false, true)
+	o._putProp("return", r.newNativeFunc(r.asyncFromSyncIterProto_return, nil, "return", nil, 1), true, false, true)
+	o._putProp("throw", r.newNativeFunc(r.asyncFromSyncIterProto_throw, nil, "throw", nil, 1), true, false, true)
+
+	return o
+}
+
+func (r *Runtime) initAsyncIterators() {
+	r.global.AsyncIteratorPrototype = r.newLazyObject(r.createAsyncIterProto)
+	r.global.AsyncFromSyncIteratorPrototype = r.newLazyObject(r.createAsyncFromSyncIterProto)
+}
@@ -0,0 +1,277 @@
+package goja
+
+import (
+	"math"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// There is no SharedArrayBuffer, however the same memory may still be shared between several Runtimes (e.g. by
+// passing the same []byte to NewArrayBuffer()), so all Atomics operations are serialised using a global lock.
+// For the same reason Atomics.wait() and Atomics.notify() work on any ArrayBuffer.
+//
+// The ordinary reads and writes of the typed array elements do not take the lock, so the ordering guarantees only
+// hold between the Atomics operations: the memory shared between Runtimes running concurrently must only be
+// accessed with the Atomics functions.
+var (
+	atomicsLock    sync.Mutex
+	atomicsWaiters = make(map[unsafe.Pointer][]chan struct{})
+)
+
+func (r *Runtime) atomicsValidateTypedArray(v Value, waitable bool) *typedArrayObject {
+	if o, ok := v.(*Object); ok {
+		if ta, ok := o.self.(*typedArrayObject); ok {
+			switch ta.typedArray.(type) {
+			case *int32Array, *bigInt64Array:
+				ta.viewedArrayBuf.ensureNotDetached(true)
+				return ta
+			case *int8Array, *uint8Array, *int16Array, *uint16Array, *uint32Array, *bigUint64Array:
+				if !waitable {
+					ta.viewedArrayBuf.ensureNotDetached(true)
+					return ta
+				}
+			}
+		}
+	}
+	if waitable {
+		panic(r.NewTypeError("%s is not an Int32Array or BigInt64Array", v))
+	}
+	panic(r.NewTypeError("%s is not an integer TypedArray", v))
+}
+
+func (r *Runtime) atomicsValidateIndex(ta *typedArrayObject, idx Value) int {
+	i := r.toIndex(idx)
+	if i >= ta.length {
+		panic(r.newError(r.global.RangeError, "Invalid atomic access index"))
+	}
+	return i
+}
+
+// atomicsToValue converts the argument as required by the content type of the array (i.e. ToBigInt or
+// ToIntegerOrInfinity).
+func (r *Runtime) atomicsToValue(ta *typedArrayObject, v Value) Value {
+	if ta.isBigInt() {
+		return toBigInt(v)
+	}
+	num := v.ToNumber()
+	if _, ok := num.(valueInt); ok {
+		return num
+	}
+	f := num.ToFloat()
+	if math.IsNaN(f) || f == 0 {
+		return intToValue(0)
+	}
+	return floatToValue(math.Trunc(f))
+}
+
+func (r *Runtime) atomicsReadModifyWrite(call FunctionCall, op func(old, v uint64) uint64) Value {
+	ta := r.atomicsValidateTypedArray(call.Argument(0), false)
+	idx := r.atomicsValidateIndex(ta, call.Argument(1))
+	raw := ta.typedArray.toRaw(r.atomicsToValue(ta, call.Argument(2)))
+	ta.viewedArrayBuf.ensureNotDetached(true)
+	atomicsLock.Lock()
+	defer atomicsLock.Unlock()
+	old := ta.typedArray.get(ta.offset + idx)
+	ta.typedArray.setRaw(ta.offset+idx, op(ta.typedArray.getRaw(ta.offset+idx), raw))
+	return old
+}
+
+func (r *Runtime) atomics_add(call FunctionCall) Value {
+	return r.atomicsReadModifyWrite(call, func(old, v uint64) uint64 {
+		return old + v
+	})
+}
+
+func (r *Runtime) atomics_and(call FunctionCall) Value {
+	return r.atomicsReadModifyWrite(call, func(old, v uint64) uint64 {
+		return old & v
+	})
+}
+
+func (r *Runtime) atomics_compareExchange(call FunctionCall) Value {
+	ta := r.atomicsValidateTypedArray(call.Argument(0), false)
+	idx := r.atomicsValidateIndex(ta, call.Argument(1))
+	expected := ta.typedArray.toRaw(r.atomicsToValue(ta, call.Argument(2)))
+	replacement := ta.typedArray.toRaw(r.atomicsToValue(ta, call.Argument(3)))
+	ta.viewedArrayBuf.ensureNotDetached(true)
+	atomicsLock.Lock()
+	defer atomicsLock.Unlock()
+	old := ta.typedArray.get(ta.offset + idx)
+	if ta.typedArray.getRaw(ta.offset+idx) == expected {
+		ta.typedArray.setRaw(ta.offset+idx, replacement)
+	}
+	return old
+}
+
+func (r *Runtime) atomics_exchange(call FunctionCall) Value {
+	return r.atomicsReadModifyWrite(call, func(_, v uint64) uint64 {
+		return v
+	})
+}
+
+func (r *Runtime) atomics_isLockFree(call FunctionCall) Value {
+	switch call.Argument(0).ToInteger() {
+	case 1, 2, 4, 8:
+		return valueTrue
+	}
+	return valueFalse
+}
+
+func (r *Runtime) atomics_load(call FunctionCall) Value {
+	ta := r.atomicsValidateTypedArray(call.Argument(0), false)
+	idx := r.atomicsValidateIndex(ta, call.Argument(1))
+	atomicsLock.Lock()
+	defer atomicsLock.Unlock()
+	return ta.typedArray.get(ta.offset + idx)
+}
+
+func (r *Runtime) atomics_or(call FunctionCall) Value {
+	return r.atomicsReadModifyWrite(call, func(old, v uint64) uint64 {
+		return old | v
+	})
+}
+
+func (r *Runtime) atomics_store(call FunctionCall) Value {
+	ta := r.atomicsValidateTypedArray(call.Argument(0), false)
+	idx := r.atomicsValidateIndex(ta, call.Argument(1))
+	v := r.atomicsToValue(ta, call.Argument(2))
+	raw := ta.typedArray.toRaw(v)
+	ta.viewedArrayBuf.ensureNotDetached(true)
+	atomicsLock.Lock()
+	defer atomicsLock.Unlock()
+	ta.typedArray.setRaw(ta.offset+idx, raw)
+	return v
+}
+
+func (r *Runtime) atomics_sub(call FunctionCall) Value {
+	return r.atomicsReadModifyWrite(call, func(old, v uint64) uint64 {
+		return old - v
+	})
+}
+
+func (r *Runtime) atomics_xor(call FunctionCall) Value {
+	return r.atomicsReadModifyWrite(call, func(old, v uint64) uint64 {
+		return old ^ v
+	})
+}
+
+func (r *Runtime) atomics_wait(call FunctionCall) Value {
+	ta := r.atomicsValidateTypedArray(call.Argument(0), true)
+	idx := r.atomicsValidateIndex(ta, call.Argument(1))
+	expected := ta.typedArray.toRaw(r.atomicsToValue(ta, call.Argument(2)))
+	timeout := math.Inf(1)
+	if t := call.Argument(3); t != _undefined {
+		timeout = t.ToFloat()
+		if math.IsNaN(timeout) {
+			timeout = math.Inf(1)
+		} else if timeout < 0 {
+			timeout = 0
+		}
+	}
+	ta.viewedArrayBuf.ensureNotDetached(true)
+	key := unsafe.Pointer(&ta.viewedArrayBuf.data[(ta.offset+idx)*ta.elemSize])
+
+	atomicsLock.Lock()
+	if ta.typedArray.getRaw(ta.offset+idx) != expected {
+		atomicsLock.Unlock()
+		return asciiString("not-equal")
+	}
+	ch := make(chan struct{})
+	atomicsWaiters[key] = append(atomicsWaiters[key], ch)
+	atomicsLock.Unlock()
+
+	var timer <-chan time.Time
+	if !math.IsInf(timeout, 1) {
+		t := time.NewTimer(time.Duration(timeout * float64(time.Millisecond)))
+		defer t.Stop()
+		timer = t.C
+	}
+	// the wait is cut short by Interrupt and by the time limit, the interrupt takes effect when this function returns
+	timeLimit, stopTimeLimit := r.vm.timeLimitChan()
+	defer stopTimeLimit()
+	select {
+	case <-ch:
+		return asciiString("ok")
+	case <-timer:
+	case <-r.vm.interruptChan():
+	case <-timeLimit:
+		r.vm.Interrupt(&TimeLimitError{Limit: r.vm.timeLimit})
+	}
+
+	atomicsLock.Lock()
+	defer atomicsLock.Unlock()
+	waiters := atomicsWaiters[key]
+	for i, w := range waiters {
+		if w == ch {
+			waiters = append(waiters[:i], waiters[i+1:]...)
+			if len(waiters) == 0 {
+				delete(atomicsWaiters, key)
+			} else {
+				atomicsWaiters[key] = waiters
+			}
+			return asciiString("timed-out")
+		}
+	}
+	// notified after the timeout but before the lock was re-acquired
+	return asciiString("ok")
+}
+
+func (r *Runtime) atomics_notify(call FunctionCall) Value {
+	ta := r.atomicsValidateTypedArray(call.Argument(0), true)
+	idx := r.atomicsValidateIndex(ta, call.Argument(1))
+	count := math.Inf(1)
+	if c := call.Argument(2); c != _undefined {
+		count = math.Max(math.Trunc(c.ToFloat()), 0)
+		if math.IsNaN(count) {
+			count = 0
+		}
+	}
+	ta.viewedArrayBuf.ensureNotDetached(true)
+	key := unsafe.Pointer(&ta.viewedArrayBuf.data[(ta.offset+idx)*ta.elemSize])
+
+	atomicsLock.Lock()
+	defer atomicsLock.Unlock()
+	waiters := atomicsWaiters[key]
+	n := 0
+	for n < len(waiters) && float64(n) < count {
+		close(waiters[n])
+		n++
+	}
+	if n == len(waiters) {
+		delete(atomicsWaiters, key)
+	} else {
+		atomicsWaiters[key] = waiters[n:]
+	}
+	return intToValue(int64(n))
+}
+
+func (r *Runtime) createAtomics(val *Object) objectImpl {
+	o := &baseObject{
+		class:      classObject,
+		val:        val,
+		extensible: true,
+		prototype:  r.global.ObjectPrototype,
+	}
+	o.init()
+
+	o._putProp("add", r.newNativeFunc(r.atomics_add, nil, "add", nil, 3), true, false, true)
+	o._putProp("and", r.newNativeFunc(r.atomics_and, nil, "and", nil, 3), true, false, true)
+	o._putProp("compareExchange", r.newNativeFunc(r.atomics_compareExchange, nil, "compareExchange", nil, 4), true, false, true)
+	o._putProp("exchange", r.newNativeFunc(r.atomics_exchange, nil, "exchange", nil, 3), true, false, true)
+	o._putProp("isLockFree", r.newNativeFunc(r.atomics_isLockFree, nil, "isLockFree", nil, 1), true, false, true)
+	o._putProp("load", r.newNativeFunc(r.atomics_load, nil, "load", nil, 2), true, false, true)
+	o._putProp("notify", r.newNativeFunc(r.atomics_notify, nil, "notify", nil, 3), true, false, true)
+	o._putProp("or", r.newNativeFunc(r.atomics_or, nil, "or", nil, 3), true, false, true)
+	o._putProp("store", r.newNativeFunc(r.atomics_store, nil, "store", nil, 3), true, false, true)
+	o._putProp("sub", r.newNativeFunc(r.atomics_sub, nil, "sub", nil, 3), true, false, true)
+	o._putProp("wait", r.newNativeFunc(r.atomics_wait, nil, "wait", nil, 4), true, false, true)
+	o._putProp("xor", r.newNativeFunc(r.atomics_xor, nil, "xor", nil, 3), true, false, true)
+	o._putSym(SymToStringTag, valueProp(asciiString("Atomics"), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) initAtomics() {
+	r.addToGlobal("Atomics", r.newLazyObject(r.createAtomics))
+}
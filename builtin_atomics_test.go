@@ -0,0 +1,147 @@
+package goja
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAtomics(t *testing.T) {
+	const SCRIPT = `
+const ia = new Int32Array(4);
+assert.sameValue(Atomics.store(ia, 0, 5.7), 5, "store returns the converted value");
+assert.sameValue(Atomics.load(ia, 0), 5, "load");
+assert.sameValue(Atomics.add(ia, 0, 3), 5, "add returns the old value");
+assert.sameValue(ia[0], 8, "add");
+assert.sameValue(Atomics.sub(ia, 0, 10), 8, "sub");
+assert.sameValue(ia[0], -2, "sub");
+assert.sameValue(Atomics.exchange(ia, 0, 0b1100), -2, "exchange");
+assert.sameValue(Atomics.and(ia, 0, 0b1010), 0b1100, "and");
+assert.sameValue(ia[0], 0b1000, "and result");
+assert.sameValue(Atomics.or(ia, 0, 0b0011), 0b1000, "or");
+assert.sameValue(ia[0], 0b1011, "or result");
+assert.sameValue(Atomics.xor(ia, 0, 0b1111), 0b1011, "xor");
+assert.sameValue(ia[0], 0b0100, "xor result");
+assert.sameValue(Atomics.compareExchange(ia, 0, 1, 10), 4, "compareExchange mismatch");
+assert.sameValue(ia[0], 4, "compareExchange mismatch result");
+assert.sameValue(Atomics.compareExchange(ia, 0, 4, 10), 4, "compareExchange match");
+assert.sameValue(ia[0], 10, "compareExchange match result");
+
+const u8 = new Uint8Array(2);
+assert.sameValue(Atomics.add(u8, 1, 300), 0, "Uint8 add");
+assert.sameValue(u8[1], 44, "Uint8 wraps");
+assert.sameValue(Atomics.sub(u8, 0, 1), 0, "Uint8 sub");
+assert.sameValue(u8[0], 255, "Uint8 sub wraps");
+const i8 = new Int8Array(1);
+i8[0] = -1;
+assert.sameValue(Atomics.compareExchange(i8, 0, 255, 1), -1, "Int8 compareExchange");
+assert.sameValue(i8[0], 1, "Int8 compareExchange compares the converted values");
+
+const bi = new BigInt64Array(1);
+assert.sameValue(Atomics.store(bi, 0, 5n), 5n, "BigInt store");
+assert.sameValue(Atomics.add(bi, 0, 2n), 5n, "BigInt add");
+assert.sameValue(Atomics.load(bi, 0), 7n, "BigInt load");
+assert.throws(TypeError, function() {
+	Atomics.store(bi, 0, 1);
+}, "Number into BigInt64Array");
+
+const view = new Int32Array(ia.buffer, 8);
+Atomics.store(view, 1, 42);
+assert.sameValue(ia[3], 42, "offset");
+
+assert.throws(RangeError, function() {
+	Atomics.load(ia, 4);
+}, "index out of range");
+assert.throws(RangeError, function() {
+	Atomics.load(ia, -1);
+}, "negative index");
+assert.throws(TypeError, function() {
+	Atomics.load(new Float64Array(1), 0);
+}, "Float64Array");
+assert.throws(TypeError, function() {
+	Atomics.load(new Uint8ClampedArray(1), 0);
+}, "Uint8ClampedArray");
+assert.throws(TypeError, function() {
+	Atomics.load([1], 0);
+}, "Array");
+
+assert.sameValue(Atomics.wait(ia, 0, 0), "not-equal", "wait not-equal");
+assert.sameValue(Atomics.wait(ia, 0, 10, 0), "timed-out", "wait timed-out");
+assert.sameValue(Atomics.notify(ia, 0), 0, "notify with no waiters");
+assert.throws(TypeError, function() {
+	Atomics.wait(u8, 0, 0, 0);
+}, "wait on Uint8Array");
+
+assert.sameValue(Atomics.isLockFree(4), true, "isLockFree(4)");
+assert.sameValue(Atomics.isLockFree(3), false, "isLockFree(3)");
+assert.sameValue(Object.prototype.toString.call(Atomics), "[object Atomics]", "toStringTag");
+`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestAtomicsWaitNotifySharedMemory(t *testing.T) {
+	data := make([]byte, 8)
+
+	r1 := New()
+	r1.Set("buf", r1.NewArrayBuffer(data))
+	r2 := New()
+	r2.Set("buf", r2.NewArrayBuffer(data))
+
+	res := make(chan Value, 1)
+	go func() {
+		v, err := r1.RunString(`Atomics.wait(new Int32Array(buf), 1, 0)`)
+		if err != nil {
+			t.Error(err)
+		}
+		res <- v
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		// the waiter may not have started waiting yet
+		v, err := r2.RunString(`Atomics.notify(new Int32Array(buf), 1)`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v.ToInteger() == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	select {
+	case v := <-res:
+		if v.String() != "ok" {
+			t.Fatal(v)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("waiter was not woken up")
+	}
+}
+
+func TestAtomicsWaitInterrupt(t *testing.T) {
+	r := New()
+	time.AfterFunc(50*time.Millisecond, func() {
+		r.Interrupt("halt")
+	})
+	_, err := r.RunString(`Atomics.wait(new Int32Array(4), 0, 0)`)
+	if ie, ok := err.(*InterruptedError); !ok || ie.Value() != "halt" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	atomicsLock.Lock()
+	n := len(atomicsWaiters)
+	atomicsLock.Unlock()
+	if n != 0 {
+		t.Fatal("the waiter has not been removed")
+	}
+
+	r.SetTimeLimit(50 * time.Millisecond)
+	_, err = r.RunString(`Atomics.wait(new Int32Array(4), 0, 0)`)
+	var tle *TimeLimitError
+	if !errors.As(err, &tle) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
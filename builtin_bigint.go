@@ -0,0 +1,340 @@
+package goja
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/dop251/goja/parser"
+)
+
+// maxBigIntBits is the maximum size of a BigInt value that can be produced by shifts, exponentiation
+// and BigInt.asUintN(). Exceeding it results in a RangeError.
+const maxBigIntBits = 1 << 30
+
+var (
+	bigIntOne       = big.NewInt(1)
+	bigIntMaxUint64 = new(big.Int).SetUint64(math.MaxUint64)
+
+	errBigIntTooBig = rangeError("Maximum BigInt size exceeded")
+)
+
+// stringToBigInt implements https://tc39.es/ecma262/#sec-stringtobigint.
+// Returns nil if the string cannot be converted.
+func stringToBigInt(s valueString) *big.Int {
+	str := strings.Trim(s.String(), parser.WhitespaceChars)
+	if str == "" {
+		return new(big.Int)
+	}
+	base := 10
+	if len(str) > 2 && str[0] == '0' {
+		switch str[1] {
+		case 'x', 'X':
+			base = 16
+		case 'o', 'O':
+			base = 8
+		case 'b', 'B':
+			base = 2
+		}
+		if base != 10 {
+			str = str[2:]
+			if str[0] == '+' || str[0] == '-' {
+				return nil
+			}
+		}
+	}
+	if b, ok := new(big.Int).SetString(str, base); ok {
+		return b
+	}
+	return nil
+}
+
+// compareBigIntFloat compares a BigInt with a Number. The second return value is false if f is NaN.
+func compareBigIntFloat(b *big.Int, f float64) (int, bool) {
+	if math.IsNaN(f) {
+		return 0, false
+	}
+	if math.IsInf(f, 1) {
+		return -1, true
+	}
+	if math.IsInf(f, -1) {
+		return 1, true
+	}
+	return new(big.Float).SetInt(b).Cmp(big.NewFloat(f)), true
+}
+
+func bigIntToNumber(b *valueBigInt) Value {
+	f, _ := new(big.Float).SetInt((*big.Int)(b)).Float64()
+	return floatToValue(f)
+}
+
+// toNumberFromNumeric converts a value to a Number the way the Number() function does, i.e. BigInts
+// are converted rather than throwing a TypeError.
+func toNumberFromNumeric(v Value) Value {
+	prim := toNumeric(v)
+	if b, ok := prim.(*valueBigInt); ok {
+		return bigIntToNumber(b)
+	}
+	return prim
+}
+
+// numberToBigInt implements https://tc39.es/ecma262/#sec-numbertobigint
+func numberToBigInt(v Value) *valueBigInt {
+	switch n := v.(type) {
+	case valueInt:
+		return (*valueBigInt)(big.NewInt(int64(n)))
+	case valueFloat:
+		f := float64(n)
+		if !math.IsNaN(f) && !math.IsInf(f, 0) && f == math.Trunc(f) {
+			b, _ := big.NewFloat(f).Int(nil)
+			return (*valueBigInt)(b)
+		}
+	}
+	panic(rangeError("The number " + v.String() + " cannot be converted to a BigInt because it is not an integer"))
+}
+
+// exportToBigInt converts a Number with an integral value into *big.Int. The second result is false if v is not a
+// Number.
+func exportToBigInt(v Value) (*big.Int, bool, error) {
+	switch n := v.(type) {
+	case valueInt:
+		return big.NewInt(int64(n)), true, nil
+	case valueFloat:
+		f := float64(n)
+		if math.IsNaN(f) || math.IsInf(f, 0) || f != math.Trunc(f) {
+			return nil, true, fmt.Errorf("%v is not an integer", v)
+		}
+		b, _ := big.NewFloat(f).Int(nil)
+		return b, true, nil
+	}
+	return nil, false, nil
+}
+
+// exportToBigFloat converts a Number or a BigInt into *big.Float without a loss of precision. The second result is
+// false if v is neither of these.
+func exportToBigFloat(v Value) (*big.Float, bool, error) {
+	switch n := v.(type) {
+	case valueInt:
+		return new(big.Float).SetInt64(int64(n)), true, nil
+	case valueFloat:
+		if math.IsNaN(float64(n)) {
+			return nil, true, errors.New("NaN can't be represented by big.Float")
+		}
+		return big.NewFloat(float64(n)), true, nil
+	case *valueBigInt:
+		return new(big.Float).SetInt((*big.Int)(n)), true, nil
+	}
+	return nil, false, nil
+}
+
+// toBigInt implements https://tc39.es/ecma262/#sec-tobigint
+func toBigInt(v Value) *valueBigInt {
+	switch prim := toPrimitiveNumber(v).(type) {
+	case *valueBigInt:
+		return prim
+	case valueString:
+		if b := stringToBigInt(prim); b != nil {
+			return (*valueBigInt)(b)
+		}
+		panic(syntaxError("Cannot convert " + prim.String() + " to a BigInt"))
+	case valueBool:
+		if prim {
+			return (*valueBigInt)(big.NewInt(1))
+		}
+		return (*valueBigInt)(new(big.Int))
+	case valueUndefined, valueNull, valueInt, valueFloat:
+		panic(typeError("Cannot convert " + prim.String() + " to a BigInt"))
+	case *Symbol:
+		panic(typeError("Cannot convert a Symbol value to a BigInt"))
+	default:
+		panic(typeError("Cannot convert value to a BigInt"))
+	}
+}
+
+// toBigUint64 implements https://tc39.es/ecma262/#sec-tobiguint64
+func toBigUint64(v Value) uint64 {
+	b := (*big.Int)(toBigInt(v))
+	if b.IsUint64() {
+		return b.Uint64()
+	}
+	if b.IsInt64() {
+		return uint64(b.Int64())
+	}
+	return new(big.Int).And(b, bigIntMaxUint64).Uint64()
+}
+
+// toBigInt64 implements https://tc39.es/ecma262/#sec-tobigint64
+func toBigInt64(v Value) int64 {
+	return int64(toBigUint64(v))
+}
+
+// bigIntOperands returns the values of both operands if at least one of them is a BigInt. The operands
+// must be primitive. If only one of them is a BigInt a TypeError is thrown.
+func bigIntOperands(left, right Value) (*big.Int, *big.Int, bool) {
+	leftBi, leftOk := left.(*valueBigInt)
+	rightBi, rightOk := right.(*valueBigInt)
+	if leftOk != rightOk {
+		panic(errMixBigIntType)
+	}
+	if leftOk {
+		return (*big.Int)(leftBi), (*big.Int)(rightBi), true
+	}
+	return nil, nil, false
+}
+
+func bigIntShiftLeft(x, n *big.Int) *valueBigInt {
+	if n.Sign() < 0 {
+		return bigIntShiftRight(x, new(big.Int).Neg(n))
+	}
+	if x.Sign() == 0 {
+		return (*valueBigInt)(new(big.Int))
+	}
+	if !n.IsInt64() || int64(x.BitLen())+n.Int64() > maxBigIntBits {
+		panic(errBigIntTooBig)
+	}
+	return (*valueBigInt)(new(big.Int).Lsh(x, uint(n.Int64())))
+}
+
+func bigIntShiftRight(x, n *big.Int) *valueBigInt {
+	if n.Sign() < 0 {
+		return bigIntShiftLeft(x, new(big.Int).Neg(n))
+	}
+	if !n.IsInt64() || n.Int64() > int64(x.BitLen()) {
+		if x.Sign() < 0 {
+			return (*valueBigInt)(big.NewInt(-1))
+		}
+		return (*valueBigInt)(new(big.Int))
+	}
+	return (*valueBigInt)(new(big.Int).Rsh(x, uint(n.Int64())))
+}
+
+func bigIntPow(x, y *big.Int) *valueBigInt {
+	if y.Sign() < 0 {
+		panic(rangeError("Exponent must be non-negative"))
+	}
+	if x.CmpAbs(bigIntOne) > 0 {
+		if !y.IsInt64() || int64(x.BitLen())*y.Int64() > maxBigIntBits {
+			panic(errBigIntTooBig)
+		}
+	}
+	return (*valueBigInt)(new(big.Int).Exp(x, y, nil))
+}
+
+func bigIntQuo(x, y *big.Int) *valueBigInt {
+	if y.Sign() == 0 {
+		panic(rangeError("Division by zero"))
+	}
+	return (*valueBigInt)(new(big.Int).Quo(x, y))
+}
+
+func bigIntRem(x, y *big.Int) *valueBigInt {
+	if y.Sign() == 0 {
+		panic(rangeError("Division by zero"))
+	}
+	return (*valueBigInt)(new(big.Int).Rem(x, y))
+}
+
+func (r *Runtime) thisBigIntValue(v Value, methodName string) *big.Int {
+	switch t := v.(type) {
+	case *valueBigInt:
+		return (*big.Int)(t)
+	case *Object:
+		if pvo, ok := t.self.(*primitiveValueObject); ok {
+			if b, ok := pvo.pValue.(*valueBigInt); ok {
+				return (*big.Int)(b)
+			}
+		}
+	}
+	panic(r.NewTypeError("Method BigInt.prototype.%s called on incompatible receiver %s", methodName, r.objectproto_toString(FunctionCall{This: v})))
+}
+
+func (r *Runtime) builtin_BigInt(call FunctionCall) Value {
+	prim := toPrimitiveNumber(call.Argument(0))
+	switch prim.(type) {
+	case valueInt, valueFloat:
+		return numberToBigInt(prim)
+	}
+	return toBigInt(prim)
+}
+
+func (r *Runtime) bigint_asIntN(call FunctionCall) Value {
+	bits := r.toIndex(call.Argument(0))
+	x := (*big.Int)(toBigInt(call.Argument(1)))
+	if bits == 0 {
+		return (*valueBigInt)(new(big.Int))
+	}
+	if x.BitLen() < bits {
+		return (*valueBigInt)(x)
+	}
+	mod := new(big.Int).Lsh(bigIntOne, uint(bits))
+	res := new(big.Int).Mod(x, mod)
+	if res.Bit(bits-1) != 0 {
+		res.Sub(res, mod)
+	}
+	return (*valueBigInt)(res)
+}
+
+func (r *Runtime) bigint_asUintN(call FunctionCall) Value {
+	bits := r.toIndex(call.Argument(0))
+	x := (*big.Int)(toBigInt(call.Argument(1)))
+	if x.Sign() >= 0 && x.BitLen() <= bits {
+		return (*valueBigInt)(x)
+	}
+	if bits > maxBigIntBits {
+		panic(errBigIntTooBig)
+	}
+	mod := new(big.Int).Lsh(bigIntOne, uint(bits))
+	return (*valueBigInt)(new(big.Int).Mod(x, mod))
+}
+
+func (r *Runtime) bigintproto_toString(call FunctionCall) Value {
+	x := r.thisBigIntValue(call.This, "toString")
+	radix := 10
+	if arg := call.Argument(0); arg != _undefined {
+		radix = int(arg.ToInteger())
+	}
+	if radix < 2 || radix > 36 {
+		panic(r.newError(r.global.RangeError, "toString() radix argument must be between 2 and 36"))
+	}
+	return asciiString(x.Text(radix))
+}
+
+func (r *Runtime) bigintproto_toLocaleString(call FunctionCall) Value {
+	return asciiString(r.thisBigIntValue(call.This, "toLocaleString").String())
+}
+
+func (r *Runtime) bigintproto_valueOf(call FunctionCall) Value {
+	return (*valueBigInt)(r.thisBigIntValue(call.This, "valueOf"))
+}
+
+func (r *Runtime) createBigIntProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("constructor", r.global.BigInt, true, false, true)
+	o._putProp("toLocaleString", r.newNativeFunc(r.bigintproto_toLocaleString, nil, "toLocaleString", nil, 0), true, false, true)
+	o._putProp("toString", r.newNativeFunc(r.bigintproto_toString, nil, "toString", nil, 0), true, false, true)
+	o._putProp("valueOf", r.newNativeFunc(r.bigintproto_valueOf, nil, "valueOf", nil, 0), true, false, true)
+	o._putSym(SymToStringTag, valueProp(asciiString(classBigInt), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) createBigInt(val *Object) objectImpl {
+	o := r.newNativeFuncObj(val, r.builtin_BigInt, func(args []Value, proto *Object) *Object {
+		panic(r.NewTypeError("BigInt is not a constructor"))
+	}, "BigInt", r.global.BigIntPrototype, intToValue(1))
+
+	o._putProp("asIntN", r.newNativeFunc(r.bigint_asIntN, nil, "asIntN", nil, 2), true, false, true)
+	o._putProp("asUintN", r.newNativeFunc(r.bigint_asUintN, nil, "asUintN", nil, 2), true, false, true)
+
+	return o
+}
+
+func (r *Runtime) initBigInt() {
+	r.global.BigIntPrototype = r.newLazyObject(r.createBigIntProto)
+
+	r.global.BigInt = r.newLazyObject(r.createBigInt)
+	r.addToGlobal("BigInt", r.global.BigInt)
+}
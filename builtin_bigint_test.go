@@ -0,0 +1,190 @@
+package goja
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestBigIntLiterals(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue(typeof 1n, "bigint", "typeof");
+	assert.sameValue(0x1Fn, 31n, "hex");
+	assert.sameValue(0o17n, 15n, "octal");
+	assert.sameValue(0b101n, 5n, "binary");
+	assert.sameValue(String(123456789012345678901234567890n), "123456789012345678901234567890", "toString");
+	assert.sameValue(-0n, 0n, "negative zero");
+	assert.sameValue(Object.is(-0n, 0n), true, "Object.is negative zero");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestBigIntArithmetic(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue(9007199254740993n + 1n, 9007199254740994n, "add");
+	assert.sameValue(1n - 3n, -2n, "sub");
+	assert.sameValue(1234567890123n * 1000000n, 1234567890123000000n, "mul");
+	assert.sameValue(7n / 2n, 3n, "div");
+	assert.sameValue(-7n / 2n, -3n, "div truncates");
+	assert.sameValue(-7n % 2n, -1n, "mod");
+	assert.sameValue(2n ** 64n, 18446744073709551616n, "exp");
+	assert.sameValue(-5n, 0n - 5n, "neg");
+	assert.sameValue(~5n, -6n, "bnot");
+	assert.sameValue(6n & 3n, 2n, "and");
+	assert.sameValue(6n | 3n, 7n, "or");
+	assert.sameValue(6n ^ 3n, 5n, "xor");
+	assert.sameValue(1n << 70n, 1180591620717411303424n, "shl");
+	assert.sameValue(-9n >> 1n, -5n, "sar");
+	assert.sameValue(1n << -1n, 0n, "negative shift");
+	var x = 1n;
+	x++;
+	++x;
+	x--;
+	assert.sameValue(x, 2n, "inc/dec");
+	assert.sameValue("a" + 1n, "a1", "string concat");
+
+	assert.throws(TypeError, function() { 1n + 1 }, "mix add");
+	assert.throws(TypeError, function() { 1 * 1n }, "mix mul");
+	assert.throws(TypeError, function() { +1n }, "unary plus");
+	assert.throws(TypeError, function() { 1n >>> 0n }, "unsigned shift");
+	assert.throws(RangeError, function() { 1n / 0n }, "div by zero");
+	assert.throws(RangeError, function() { 1n % 0n }, "mod by zero");
+	assert.throws(RangeError, function() { 2n ** -1n }, "negative exponent");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestBigIntComparison(t *testing.T) {
+	const SCRIPT = `
+	assert(1n < 2, "bigint < number");
+	assert(2 > 1n, "number > bigint");
+	assert(1n < 1.5, "bigint < fraction");
+	assert(!(1n < NaN), "NaN");
+	assert(1n < Infinity, "Infinity");
+	assert(-1n > -Infinity, "-Infinity");
+	assert(10n > "9", "bigint > string");
+	assert(!(10n < "x"), "unparsable string");
+	assert(9007199254740993n > 9007199254740992, "precision");
+	assert(1n == 1, "loose equality with number");
+	assert(1n != 1.5, "loose inequality with fraction");
+	assert(1n == "1", "loose equality with string");
+	assert(0n == false, "loose equality with boolean");
+	assert(1n !== 1, "strict equality with number");
+	assert(Object(1n) == 1n, "wrapper");
+	var m = new Map();
+	m.set(1n, "one");
+	assert.sameValue(m.get(1n), "one", "map key");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestBigIntBuiltins(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue(BigInt(42), 42n, "from number");
+	assert.sameValue(BigInt("0x10"), 16n, "from hex string");
+	assert.sameValue(BigInt(" 12 "), 12n, "from padded string");
+	assert.sameValue(BigInt(""), 0n, "from empty string");
+	assert.sameValue(BigInt(true), 1n, "from boolean");
+	assert.throws(RangeError, function() { BigInt(1.5) }, "fraction");
+	assert.throws(SyntaxError, function() { BigInt("1.5") }, "fraction string");
+	assert.throws(TypeError, function() { BigInt(undefined) }, "undefined");
+	assert.throws(TypeError, function() { new BigInt(1) }, "new");
+
+	assert.sameValue(BigInt.asIntN(8, 255n), -1n, "asIntN");
+	assert.sameValue(BigInt.asIntN(64, 2n ** 63n), -(2n ** 63n), "asIntN 64");
+	assert.sameValue(BigInt.asUintN(8, -1n), 255n, "asUintN");
+	assert.sameValue(BigInt.asUintN(0, 5n), 0n, "asUintN 0");
+
+	assert.sameValue((255n).toString(16), "ff", "toString radix");
+	assert.sameValue((-255n).toString(2), "-11111111", "toString negative");
+	assert.sameValue(Object(5n).valueOf(), 5n, "valueOf");
+	assert.sameValue(Object.prototype.toString.call(1n), "[object BigInt]", "toStringTag");
+	assert.sameValue(Number(2n ** 53n + 1n), 9007199254740992, "Number()");
+	assert.throws(TypeError, function() { Math.abs(1n) }, "Math");
+	assert.throws(TypeError, function() { JSON.stringify({a: 1n}) }, "JSON");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestBigIntTypedArrays(t *testing.T) {
+	const SCRIPT = `
+	var a = new BigInt64Array(2);
+	a[0] = -1n;
+	a[1] = 2n ** 64n + 5n;
+	assert.sameValue(a[0], -1n, "BigInt64Array");
+	assert.sameValue(a[1], 5n, "BigInt64Array wraps");
+	var u = new BigUint64Array(a.buffer);
+	assert.sameValue(u[0], 2n ** 64n - 1n, "BigUint64Array");
+	assert.sameValue(BigUint64Array.BYTES_PER_ELEMENT, 8, "BYTES_PER_ELEMENT");
+	assert.sameValue(a.indexOf(5n), 1, "indexOf");
+	assert(a.includes(-1n), "includes");
+	assert.sameValue(new BigInt64Array([3n, 1n, 2n]).sort().join(), "1,2,3", "sort");
+	assert.throws(TypeError, function() { a[0] = 1 }, "number into BigInt64Array");
+	assert.throws(TypeError, function() { new Float64Array(1)[0] = 1n }, "BigInt into Float64Array");
+	assert.throws(TypeError, function() { new Float64Array(a) }, "content type mismatch");
+	assert.throws(TypeError, function() { new Float64Array(2).set(a) }, "content type mismatch in set");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestBigIntExport(t *testing.T) {
+	vm := New()
+	v, err := vm.RunString("123456789012345678901234567890n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, ok := v.Export().(*big.Int)
+	if !ok {
+		t.Fatalf("Unexpected export type: %T", v.Export())
+	}
+	if b.String() != "123456789012345678901234567890" {
+		t.Fatal(b)
+	}
+	b.SetInt64(0)
+	if v.String() != "123456789012345678901234567890" {
+		t.Fatal("Exported value is not a copy")
+	}
+}
+
+func TestBigIntToValueExportTo(t *testing.T) {
+	vm := New()
+	b, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	vm.Set("b", b)
+	vm.Set("nilInt", (*big.Int)(nil))
+	vm.Set("f", big.NewFloat(1.5))
+	res, err := vm.RunString(`typeof b === "bigint" && b + 1n === 123456789012345678901234567891n && nilInt === null && typeof f === "object"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.ToBoolean() {
+		t.Fatal("unexpected conversion")
+	}
+	b.SetInt64(0)
+	if s := vm.Get("b").String(); s != "123456789012345678901234567890" {
+		t.Fatal("ToValue does not copy", s)
+	}
+
+	vm.SetBigFloatToNumber(true)
+	vm.Set("f", new(big.Float).SetPrec(200).Quo(big.NewFloat(1), big.NewFloat(3)))
+	if res, err := vm.RunString(`f === 1/3`); err != nil || !res.ToBoolean() {
+		t.Fatal(res, err)
+	}
+
+	var bi *big.Int
+	if err := vm.ExportTo(vm.ToValue(1e20), &bi); err != nil || bi.String() != "100000000000000000000" {
+		t.Fatal(bi, err)
+	}
+	if err := vm.ExportTo(vm.ToValue(1.5), &bi); err == nil {
+		t.Fatal("expected an error")
+	}
+	var bf *big.Float
+	v, _ := vm.RunString(`2n ** 70n`)
+	if err := vm.ExportTo(v, &bf); err != nil || bf.Text('f', 0) != "1180591620717411303424" {
+		t.Fatal(bf, err)
+	}
+	if err := vm.ExportTo(vm.ToValue(0.25), &bf); err != nil || bf.Text('g', 10) != "0.25" {
+		t.Fatal(bf, err)
+	}
+	if err := vm.ExportTo(_NaN, &bf); err == nil {
+		t.Fatal("expected an error")
+	}
+}
@@ -0,0 +1,521 @@
+package goja
+
+import (
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja/unistring"
+)
+
+// ConsolePrinter receives the output of the console builtin (see Runtime.EnableConsole). Each call receives one
+// complete message which may consist of several lines and does not include the trailing newline.
+type ConsolePrinter interface {
+	Log(s string)
+	Warn(s string)
+	Error(s string)
+}
+
+type writerConsolePrinter struct {
+	w io.Writer
+}
+
+func (p writerConsolePrinter) print(s string) {
+	_, _ = io.WriteString(p.w, s+"\n")
+}
+
+func (p writerConsolePrinter) Log(s string) {
+	p.print(s)
+}
+
+func (p writerConsolePrinter) Warn(s string) {
+	p.print(s)
+}
+
+func (p writerConsolePrinter) Error(s string) {
+	p.print(s)
+}
+
+// NewConsolePrinter returns a ConsolePrinter that writes all messages to w, each followed by a newline.
+func NewConsolePrinter(w io.Writer) ConsolePrinter {
+	return writerConsolePrinter{w: w}
+}
+
+type consoleLevel int
+
+const (
+	consoleLevelLog consoleLevel = iota
+	consoleLevelWarn
+	consoleLevelError
+)
+
+type consoleState struct {
+	r       *Runtime
+	printer ConsolePrinter
+
+	groupIndent string
+	counts      map[string]int
+	timers      map[string]time.Time
+}
+
+func (c *consoleState) print(level consoleLevel, s string) {
+	if c.groupIndent != "" {
+		s = c.groupIndent + strings.Replace(s, "\n", "\n"+c.groupIndent, -1)
+	}
+	switch level {
+	case consoleLevelWarn:
+		c.printer.Warn(s)
+	case consoleLevelError:
+		c.printer.Error(s)
+	default:
+		c.printer.Log(s)
+	}
+}
+
+// format implements util.format() from Node.js. If the first argument is a string it may contain the %s, %d, %i,
+// %f, %j, %o, %O, %c and %% substitutions. The remaining arguments are appended separated by spaces, strings
+// as they are, everything else formatted with inspect.
+func (c *consoleState) format(args []Value) string {
+	if len(args) == 0 {
+		return ""
+	}
+	r := c.r
+	var sb strings.Builder
+	a := 0
+	if f, ok := args[0].(valueString); ok {
+		a = 1
+		s := f.String()
+		last := 0
+		for i := 0; i < len(s)-1; i++ {
+			if s[i] != '%' {
+				continue
+			}
+			if s[i+1] == '%' {
+				sb.WriteString(s[last:i])
+				sb.WriteByte('%')
+				last = i + 2
+				i++
+				continue
+			}
+			if a >= len(args) {
+				continue
+			}
+			var str string
+			switch s[i+1] {
+			case 's':
+				switch arg := args[a].(type) {
+				case *valueBigInt:
+					str = arg.String() + "n"
+				case *Object, *Symbol, valueFloat:
+					str = r.inspect(arg, 0)
+				default:
+					str = arg.String()
+				}
+			case 'd':
+				switch arg := args[a].(type) {
+				case *valueBigInt:
+					str = arg.String() + "n"
+				case *Symbol:
+					str = "NaN"
+				default:
+					str = r.inspect(arg.ToNumber(), 0)
+				}
+			case 'i':
+				switch arg := args[a].(type) {
+				case *valueBigInt:
+					str = arg.String() + "n"
+				case *Symbol:
+					str = "NaN"
+				default:
+					str = r.inspect(r.builtin_parseInt(FunctionCall{Arguments: []Value{arg}}), 0)
+				}
+			case 'f':
+				if _, ok := args[a].(*Symbol); ok {
+					str = "NaN"
+				} else {
+					str = r.inspect(r.builtin_parseFloat(FunctionCall{Arguments: []Value{args[a]}}), 0)
+				}
+			case 'j':
+				var res Value
+				if ex := r.vm.try(func() {
+					res = r.builtinJSON_stringify(FunctionCall{Arguments: args[a : a+1]})
+				}); ex != nil {
+					if strings.Contains(ex.Value().String(), "circular") {
+						str = "[Circular]"
+					} else {
+						panic(ex)
+					}
+				} else {
+					str = nilSafe(res).String()
+				}
+			case 'o':
+				str = r.inspect(args[a], 4)
+			case 'O':
+				str = r.inspect(args[a], 2)
+			case 'c':
+			default:
+				continue
+			}
+			sb.WriteString(s[last:i])
+			sb.WriteString(str)
+			last = i + 2
+			i++
+			a++
+		}
+		sb.WriteString(s[last:])
+	}
+	for ; a < len(args); a++ {
+		if a > 0 {
+			sb.WriteByte(' ')
+		}
+		if s, ok := args[a].(valueString); ok {
+			sb.WriteString(s.String())
+		} else {
+			sb.WriteString(r.inspect(args[a], 2))
+		}
+	}
+	return sb.String()
+}
+
+func (c *consoleState) logger(level consoleLevel) func(FunctionCall) Value {
+	return func(call FunctionCall) Value {
+		c.print(level, c.format(call.Arguments))
+		return _undefined
+	}
+}
+
+func consoleLabel(call FunctionCall) string {
+	if arg := call.Argument(0); arg != _undefined {
+		return arg.String()
+	}
+	return "default"
+}
+
+func (c *consoleState) dir(call FunctionCall) Value {
+	c.print(consoleLevelLog, c.r.inspect(call.Argument(0), 2))
+	return _undefined
+}
+
+func (c *consoleState) assert(call FunctionCall) Value {
+	if call.Argument(0).ToBoolean() {
+		return _undefined
+	}
+	msg := "Assertion failed"
+	if len(call.Arguments) > 1 {
+		msg += ": " + c.format(call.Arguments[1:])
+	}
+	c.print(consoleLevelError, msg)
+	return _undefined
+}
+
+func (c *consoleState) trace(call FunctionCall) Value {
+	var sb strings.Builder
+	sb.WriteString("Trace")
+	if len(call.Arguments) > 0 {
+		sb.WriteString(": ")
+		sb.WriteString(c.format(call.Arguments))
+	}
+	for _, frame := range c.r.CaptureCallStack(0, nil) {
+		if frame.prg == nil && frame.funcName == "trace" {
+			continue
+		}
+		sb.WriteString("\n    at ")
+		var b valueStringBuilder
+		frame.WriteToValueBuilder(&b)
+		sb.WriteString(b.String().String())
+	}
+	c.print(consoleLevelError, sb.String())
+	return _undefined
+}
+
+func (c *consoleState) group(call FunctionCall) Value {
+	if len(call.Arguments) > 0 {
+		c.print(consoleLevelLog, c.format(call.Arguments))
+	}
+	c.groupIndent += "  "
+	return _undefined
+}
+
+func (c *consoleState) groupEnd(call FunctionCall) Value {
+	if len(c.groupIndent) >= 2 {
+		c.groupIndent = c.groupIndent[:len(c.groupIndent)-2]
+	}
+	return _undefined
+}
+
+func (c *consoleState) count(call FunctionCall) Value {
+	label := consoleLabel(call)
+	if c.counts == nil {
+		c.counts = make(map[string]int)
+	}
+	c.counts[label]++
+	c.print(consoleLevelLog, label+": "+strconv.Itoa(c.counts[label]))
+	return _undefined
+}
+
+func (c *consoleState) countReset(call FunctionCall) Value {
+	label := consoleLabel(call)
+	if _, exists := c.counts[label]; !exists {
+		c.print(consoleLevelWarn, "Count for '"+label+"' does not exist")
+		return _undefined
+	}
+	delete(c.counts, label)
+	return _undefined
+}
+
+func (c *consoleState) time(call FunctionCall) Value {
+	label := consoleLabel(call)
+	if _, exists := c.timers[label]; exists {
+		c.print(consoleLevelWarn, "Label '"+label+"' already exists for console.time()")
+		return _undefined
+	}
+	if c.timers == nil {
+		c.timers = make(map[string]time.Time)
+	}
+	c.timers[label] = c.r.now()
+	return _undefined
+}
+
+func formatConsoleDuration(d time.Duration) string {
+	ms := float64(d) / float64(time.Millisecond)
+	if ms >= 1000 {
+		return strconv.FormatFloat(ms/1000, 'f', 3, 64) + "s"
+	}
+	return strconv.FormatFloat(math.Max(ms, 0), 'f', 3, 64) + "ms"
+}
+
+func (c *consoleState) timeLogImpl(call FunctionCall, method string, end bool) {
+	label := consoleLabel(call)
+	start, exists := c.timers[label]
+	if !exists {
+		c.print(consoleLevelWarn, "No such label '"+label+"' for console."+method+"()")
+		return
+	}
+	msg := label + ": " + formatConsoleDuration(c.r.now().Sub(start))
+	if end {
+		delete(c.timers, label)
+	} else if len(call.Arguments) > 1 {
+		msg += " " + c.format(call.Arguments[1:])
+	}
+	c.print(consoleLevelLog, msg)
+}
+
+func (c *consoleState) timeLog(call FunctionCall) Value {
+	c.timeLogImpl(call, "timeLog", false)
+	return _undefined
+}
+
+func (c *consoleState) timeEnd(call FunctionCall) Value {
+	c.timeLogImpl(call, "timeEnd", true)
+	return _undefined
+}
+
+// table implements console.table() rendering the data using box-drawing characters like Node.js does.
+func (c *consoleState) table(call FunctionCall) Value {
+	data, ok := call.Argument(0).(*Object)
+	if !ok {
+		return c.logger(consoleLevelLog)(call)
+	}
+	r := c.r
+	cell := func(v Value) string {
+		ins := &inspector{r: r, depth: 0, breakLength: math.MaxInt32}
+		if o, ok := v.(*Object); ok && !isArray(o) && len(o.self.stringKeys(false, nil)) > 2 {
+			ins.depth = -1
+			return ins.formatObject(o, 1)
+		}
+		return ins.format(v, 0)
+	}
+
+	var filter []string
+	if props, ok := call.Argument(1).(*Object); ok {
+		for _, p := range r.iterableToList(props, nil) {
+			filter = append(filter, p.String())
+		}
+	}
+
+	indexHeader := "(index)"
+	var index []string
+	var rows []Value
+	switch obj := data.self.(type) {
+	case *mapObject:
+		indexHeader = "(iteration index)"
+		var keys []Value
+		for iter := obj.m.newIter(); ; {
+			entry := iter.next()
+			if entry == nil {
+				break
+			}
+			index = append(index, strconv.Itoa(len(index)))
+			keys = append(keys, entry.key)
+			rows = append(rows, entry.value)
+		}
+		c.print(consoleLevelLog, renderConsoleTable(indexHeader, index, rows, filter, map[string][]Value{"Key": keys}, cell))
+		return _undefined
+	case *setObject:
+		indexHeader = "(iteration index)"
+		for iter := obj.m.newIter(); ; {
+			entry := iter.next()
+			if entry == nil {
+				break
+			}
+			index = append(index, strconv.Itoa(len(index)))
+			rows = append(rows, entry.key)
+		}
+	default:
+		for item, next := iterateEnumerableProperties(data)(); next != nil; item, next = next() {
+			index = append(index, inspectKeyName(item.name))
+			rows = append(rows, item.value)
+		}
+	}
+	c.print(consoleLevelLog, renderConsoleTable(indexHeader, index, rows, filter, nil, cell))
+	return _undefined
+}
+
+func renderConsoleTable(indexHeader string, index []string, rows []Value, filter []string, extra map[string][]Value, cell func(Value) string) string {
+	head := []string{indexHeader}
+	columns := [][]string{index}
+	colIdx := make(map[string]int)
+	addColumn := func(name string) int {
+		if i, exists := colIdx[name]; exists {
+			return i
+		}
+		head = append(head, name)
+		columns = append(columns, make([]string, len(rows)))
+		colIdx[name] = len(head) - 1
+		return len(head) - 1
+	}
+	for name, values := range extra {
+		i := addColumn(name)
+		for j, v := range values {
+			columns[i][j] = cell(v)
+		}
+	}
+	for _, name := range filter {
+		addColumn(name)
+	}
+	var values []string
+	var hasValues []bool
+	for j, row := range rows {
+		if o, ok := row.(*Object); ok {
+			if _, isFunc := o.self.assertCallable(); !isFunc {
+				for item, next := iterateEnumerableProperties(o)(); next != nil; item, next = next() {
+					name := inspectKeyName(item.name)
+					if filter != nil {
+						if _, exists := colIdx[name]; !exists {
+							continue
+						}
+					}
+					i := addColumn(name)
+					columns[i][j] = cell(item.value)
+				}
+				values = append(values, "")
+				hasValues = append(hasValues, false)
+				continue
+			}
+		}
+		values = append(values, cell(row))
+		hasValues = append(hasValues, true)
+	}
+	for _, has := range hasValues {
+		if has {
+			head = append(head, "Values")
+			columns = append(columns, values)
+			break
+		}
+	}
+
+	widths := make([]int, len(head))
+	for i, h := range head {
+		widths[i] = inspectWidth(h)
+		for _, v := range columns[i] {
+			if w := inspectWidth(v); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+	renderRow := func(sb *strings.Builder, cells func(i int) string) {
+		sb.WriteString("│ ")
+		for i := range head {
+			s := cells(i)
+			needed := widths[i] - inspectWidth(s)
+			sb.WriteString(strings.Repeat(" ", needed/2))
+			sb.WriteString(s)
+			sb.WriteString(strings.Repeat(" ", needed-needed/2))
+			if i != len(head)-1 {
+				sb.WriteString(" │ ")
+			}
+		}
+		sb.WriteString(" │\n")
+	}
+	divider := func(sb *strings.Builder, left, middle, right string) {
+		sb.WriteString(left)
+		for i, w := range widths {
+			if i > 0 {
+				sb.WriteString(middle)
+			}
+			sb.WriteString(strings.Repeat("─", w+2))
+		}
+		sb.WriteString(right)
+	}
+
+	var sb strings.Builder
+	divider(&sb, "┌", "┬", "┐\n")
+	renderRow(&sb, func(i int) string { return head[i] })
+	divider(&sb, "├", "┼", "┤\n")
+	for j := range rows {
+		renderRow(&sb, func(i int) string { return columns[i][j] })
+	}
+	divider(&sb, "└", "┴", "┘")
+	return sb.String()
+}
+
+func (c *consoleState) createObject(val *Object) objectImpl {
+	r := c.r
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	put := func(name string, f func(FunctionCall) Value) {
+		o._putProp(unistring.NewFromString(name), r.newNativeFunc(f, nil, unistring.NewFromString(name), nil, 0), true, true, true)
+	}
+	put("log", c.logger(consoleLevelLog))
+	put("info", c.logger(consoleLevelLog))
+	put("debug", c.logger(consoleLevelLog))
+	put("warn", c.logger(consoleLevelWarn))
+	put("error", c.logger(consoleLevelError))
+	put("dir", c.dir)
+	put("dirxml", c.logger(consoleLevelLog))
+	put("table", c.table)
+	put("trace", c.trace)
+	put("assert", c.assert)
+	put("group", c.group)
+	put("groupCollapsed", c.group)
+	put("groupEnd", c.groupEnd)
+	put("count", c.count)
+	put("countReset", c.countReset)
+	put("time", c.time)
+	put("timeLog", c.timeLog)
+	put("timeEnd", c.timeEnd)
+	o._putSym(SymToStringTag, valueProp(asciiString("console"), false, false, true))
+
+	return o
+}
+
+// EnableConsole adds the console global. Objects are formatted in the same way as util.inspect() in Node.js does
+// it, including the handling of circular references. The output is sent to printer, if it is nil the messages of
+// all levels are written to os.Stdout. Calling EnableConsole again replaces the printer.
+//
+// console.debug() and console.info() are equivalent to console.log(). console.trace() and failed assertions are
+// reported using the Error level.
+func (r *Runtime) EnableConsole(printer ConsolePrinter) {
+	if printer == nil {
+		printer = NewConsolePrinter(os.Stdout)
+	}
+	if r.console != nil {
+		r.console.printer = printer
+		return
+	}
+	r.console = &consoleState{r: r, printer: printer}
+	r.addToGlobal("console", r.newLazyObject(r.console.createObject))
+}
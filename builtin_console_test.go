@@ -0,0 +1,214 @@
+package goja
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testConsolePrinter struct {
+	out []string
+}
+
+func (p *testConsolePrinter) Log(s string) {
+	p.out = append(p.out, s)
+}
+
+func (p *testConsolePrinter) Warn(s string) {
+	p.out = append(p.out, "warn: "+s)
+}
+
+func (p *testConsolePrinter) Error(s string) {
+	p.out = append(p.out, "error: "+s)
+}
+
+func runConsoleScript(t *testing.T, script string) []string {
+	r := New()
+	p := &testConsolePrinter{}
+	r.EnableConsole(p)
+	if _, err := r.RunString(script); err != nil {
+		t.Fatal(err)
+	}
+	return p.out
+}
+
+func checkConsoleOutput(t *testing.T, out, expected []string) {
+	t.Helper()
+	if len(out) != len(expected) {
+		t.Fatalf("Unexpected output: %q", out)
+	}
+	for i, s := range expected {
+		if out[i] != s {
+			t.Errorf("%d: expected\n%s\ngot\n%s", i, s, out[i])
+		}
+	}
+}
+
+func TestConsoleDisabled(t *testing.T) {
+	testScript(`typeof console`, asciiString("undefined"), t)
+}
+
+func TestConsoleFormat(t *testing.T) {
+	out := runConsoleScript(t, `
+	console.log("%s=%d %i %f %j %% %c|", "a", "42", 3.7, "1.5e1", {a: 1}, "color: red", "extra", 1, -0, 10n, Symbol("s"));
+	console.log("%s %s", "only one");
+	console.log(1, "two", [3], undefined, null);
+	console.info("info");
+	console.debug("debug");
+	console.warn("warn");
+	console.error("error");
+	`)
+	checkConsoleOutput(t, out, []string{
+		`a=42 3 15 {"a":1} % | extra 1 -0 10n Symbol(s)`,
+		"only one %s",
+		"1 two [ 3 ] undefined null",
+		"info",
+		"debug",
+		"warn: warn",
+		"error: error",
+	})
+}
+
+func TestConsoleInspect(t *testing.T) {
+	out := runConsoleScript(t, `
+	var o = {a: 1, b: "str", c: [1, 2, {d: {e: 1}}], "key-x": null, [Symbol("q")]: undefined};
+	o.self = o;
+	console.log(o);
+	console.log(Array.from({length: 28}, function(_, i) { return i + 1 }));
+	console.log(new Map([["a", 1], [{}, [2]]]), new Set([1, "x"]));
+	class Foo { constructor() { this.x = 1 } }
+	class Bar extends Foo {}
+	console.log(new Foo(), Foo, Bar, function named() {}, () => {}, async function af() {});
+	console.log(Object.create(null), [1,,,4], new Uint8Array([1, 2]), new ArrayBuffer(3), new Date(0), /a+/gi);
+	console.log(new Number(3), new String("ab"), Promise.resolve(5), new Promise(function() {}));
+	console.log({get g() { return 1 }, set s(v) {}, get gs() { return 1 }, set gs(v) {}});
+	console.log(["it's"], ["it's \"q\""], ["a\nb"]);
+	console.dir({a: {b: {c: {d: 1}}}});
+	console.log({aaaaaaaaaaaa: "aaaaaaaaaaaaaaaaa", bbbbbbbbbbbbbb: "bbbbbbbbbbbbbbbbbbbb", cccccccccc: "cccccccccccccccccc"});
+	console.log(new Proxy({p: 1}, {}), {[Symbol.toStringTag]: "Tag"});
+	`)
+	checkConsoleOutput(t, out, []string{
+		"<ref *1> {\n  a: 1,\n  b: 'str',\n  c: [ 1, 2, { d: [Object] } ],\n  'key-x': null,\n  self: [Circular *1],\n  [Symbol(q)]: undefined\n}",
+		"[\n   1,  2,  3,  4,  5,  6,  7,  8,\n   9, 10, 11, 12, 13, 14, 15, 16,\n  17, 18, 19, 20, 21, 22, 23, 24,\n  25, 26, 27, 28\n]",
+		"Map(2) { 'a' => 1, {} => [ 2 ] } Set(2) { 1, 'x' }",
+		"Foo { x: 1 } [class Foo] [class Bar extends Foo] [Function: named] [Function (anonymous)] [AsyncFunction: af]",
+		"[Object: null prototype] {} [ 1, <2 empty items>, 4 ] Uint8Array(2) [ 1, 2 ] ArrayBuffer { [Uint8Contents]: <00 00 00>, byteLength: 3 } 1970-01-01T00:00:00.000Z /a+/gi",
+		"[Number: 3] [String: 'ab'] Promise { 5 } Promise { <pending> }",
+		"{ g: [Getter], s: [Setter], gs: [Getter/Setter] }",
+		"[ \"it's\" ] [ `it's \"q\"` ] [ 'a\\nb' ]",
+		"{ a: { b: { c: [Object] } } }",
+		"{\n  aaaaaaaaaaaa: 'aaaaaaaaaaaaaaaaa',\n  bbbbbbbbbbbbbb: 'bbbbbbbbbbbbbbbbbbbb',\n  cccccccccc: 'cccccccccccccccccc'\n}",
+		"{ p: 1 } Object [Tag] { [Symbol(Symbol.toStringTag)]: 'Tag' }",
+	})
+}
+
+func TestConsoleTable(t *testing.T) {
+	out := runConsoleScript(t, `
+	console.table([{a: 1, b: "Y"}, {a: "Z", b: 2}]);
+	console.table([1, "two", {x: 3}]);
+	console.table(new Map([["k", {v: 1}]]));
+	console.table([{a: 1, b: 2}], ["a"]);
+	console.table("not tabular");
+	`)
+	checkConsoleOutput(t, out, []string{
+		"┌─────────┬─────┬─────┐\n" +
+			"│ (index) │  a  │  b  │\n" +
+			"├─────────┼─────┼─────┤\n" +
+			"│    0    │  1  │ 'Y' │\n" +
+			"│    1    │ 'Z' │  2  │\n" +
+			"└─────────┴─────┴─────┘",
+		"┌─────────┬───┬────────┐\n" +
+			"│ (index) │ x │ Values │\n" +
+			"├─────────┼───┼────────┤\n" +
+			"│    0    │   │   1    │\n" +
+			"│    1    │   │ 'two'  │\n" +
+			"│    2    │ 3 │        │\n" +
+			"└─────────┴───┴────────┘",
+		"┌───────────────────┬─────┬───┐\n" +
+			"│ (iteration index) │ Key │ v │\n" +
+			"├───────────────────┼─────┼───┤\n" +
+			"│         0         │ 'k' │ 1 │\n" +
+			"└───────────────────┴─────┴───┘",
+		"┌─────────┬───┐\n" +
+			"│ (index) │ a │\n" +
+			"├─────────┼───┤\n" +
+			"│    0    │ 1 │\n" +
+			"└─────────┴───┘",
+		"not tabular",
+	})
+}
+
+func TestConsoleGroupCountAssert(t *testing.T) {
+	out := runConsoleScript(t, `
+	console.group("G");
+	console.warn("in\ngroup");
+	console.groupCollapsed();
+	console.log("nested");
+	console.groupEnd();
+	console.groupEnd();
+	console.groupEnd();
+	console.count(); console.count(); console.count("x"); console.countReset(); console.count(); console.countReset("nope");
+	console.assert(true, "no"); console.assert(false, "yes %s", "sir"); console.assert(0);
+	function f() { console.trace("here") }
+	f();
+	`)
+	checkConsoleOutput(t, out, []string{
+		"G",
+		"warn:   in\n  group",
+		"    nested",
+		"default: 1",
+		"default: 2",
+		"x: 1",
+		"default: 1",
+		"warn: Count for 'nope' does not exist",
+		"error: Assertion failed: yes sir",
+		"error: Assertion failed",
+		"error: Trace: here\n    at f (<eval>:11:30(4))\n    at <eval>:12:3(78)",
+	})
+}
+
+func TestConsoleTime(t *testing.T) {
+	r := New()
+	p := &testConsolePrinter{}
+	r.EnableConsole(p)
+	now := time.Unix(1000, 0)
+	r.SetTimeSource(func() time.Time {
+		return now
+	})
+	r.Set("advance", func(ms int) {
+		now = now.Add(time.Duration(ms) * time.Millisecond)
+	})
+	_, err := r.RunString(`
+	console.time();
+	console.time();
+	advance(12);
+	console.timeLog(undefined, "step", 1);
+	advance(1500);
+	console.timeEnd();
+	console.timeEnd();
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkConsoleOutput(t, p.out, []string{
+		"warn: Label 'default' already exists for console.time()",
+		"default: 12.000ms step 1",
+		"default: 1.512s",
+		"warn: No such label 'default' for console.timeEnd()",
+	})
+}
+
+func TestConsoleDefaultPrinter(t *testing.T) {
+	r := New()
+	var buf bytes.Buffer
+	r.EnableConsole(nil)
+	r.EnableConsole(NewConsolePrinter(&buf))
+	_, err := r.RunString(`console.log("a"); console.error({b: 1})`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := buf.String(); s != "a\n{ b: 1 }\n" {
+		t.Fatal(strings.TrimSpace(s))
+	}
+}
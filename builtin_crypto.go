@@ -0,0 +1,68 @@
+package goja
+
+import (
+	"crypto/rand"
+)
+
+func (r *Runtime) crypto_getRandomValues(call FunctionCall) Value {
+	arg := call.Argument(0)
+	if o, ok := arg.(*Object); ok {
+		if ta, ok := o.self.(*typedArrayObject); ok {
+			switch ta.typedArray.(type) {
+			case *float32Array, *float64Array:
+			default:
+				b, _ := r.bufferSourceBytes(o)
+				if len(b) > 65536 {
+					panic(r.newError(r.global.RangeError, "The ArrayBufferView's byte length (%d) exceeds the number of bytes of entropy available via this API (65536)", len(b)))
+				}
+				if _, err := rand.Read(b); err != nil {
+					panic(r.NewGoError(err))
+				}
+				return arg
+			}
+		}
+	}
+	panic(r.NewTypeError("The argument must be an integer-type TypedArray"))
+}
+
+func (r *Runtime) crypto_randomUUID(call FunctionCall) Value {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(r.NewGoError(err))
+	}
+	b[6] = b[6]&0x0F | 0x40 // version 4
+	b[8] = b[8]&0x3F | 0x80 // variant 10
+	buf := make([]byte, 36)
+	j := 0
+	for i, c := range b {
+		if i == 4 || i == 6 || i == 8 || i == 10 {
+			buf[j] = '-'
+			j++
+		}
+		buf[j] = hex[c>>4]
+		buf[j+1] = hex[c&0xF]
+		j += 2
+	}
+	return asciiString(buf)
+}
+
+func (r *Runtime) createCrypto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("getRandomValues", r.newNativeFunc(r.crypto_getRandomValues, nil, "getRandomValues", nil, 1), true, true, true)
+	o._putProp("randomUUID", r.newNativeFunc(r.crypto_randomUUID, nil, "randomUUID", nil, 0), true, true, true)
+	o._putSym(SymToStringTag, valueProp(asciiString("Crypto"), false, false, true))
+
+	return o
+}
+
+// EnableCrypto adds the crypto global with the getRandomValues() and randomUUID() methods as described in
+// https://w3c.github.io/webcrypto/#crypto-interface. The random values are obtained from crypto/rand. The
+// crypto.subtle interface is not provided.
+func (r *Runtime) EnableCrypto() {
+	if r.global.Crypto != nil {
+		return
+	}
+	r.global.Crypto = r.newLazyObject(r.createCrypto)
+	r.addToGlobal("crypto", r.global.Crypto)
+}
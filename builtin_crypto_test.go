@@ -0,0 +1,33 @@
+package goja
+
+import (
+	"testing"
+)
+
+func TestCryptoDisabled(t *testing.T) {
+	testScript(`typeof crypto`, asciiString("undefined"), t)
+}
+
+func TestCrypto(t *testing.T) {
+	const SCRIPT = `
+	var a = new Uint32Array(64);
+	assert.sameValue(crypto.getRandomValues(a), a, "returns the argument");
+	assert(a.some(function(v) { return v !== 0 }), "filled");
+	var view = new Uint8Array(new ArrayBuffer(8), 2, 4);
+	crypto.getRandomValues(view);
+	assert(compareArray(new Uint8Array(view.buffer, 0, 2), [0, 0]) && compareArray(new Uint8Array(view.buffer, 6), [0, 0]), "only the view is filled");
+	crypto.getRandomValues(new BigInt64Array(2));
+	crypto.getRandomValues(new Uint8ClampedArray(65536));
+	assert.throws(RangeError, function() { crypto.getRandomValues(new Uint8Array(65537)) }, "quota");
+	assert.throws(TypeError, function() { crypto.getRandomValues(new Float64Array(1)) }, "float");
+	assert.throws(TypeError, function() { crypto.getRandomValues([1, 2]) }, "array");
+
+	var uuid = crypto.randomUUID();
+	assert(/^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$/.test(uuid), uuid);
+	assert(uuid !== crypto.randomUUID(), "unique");
+	assert.sameValue(Object.prototype.toString.call(crypto), "[object Crypto]");
+	`
+	r := New()
+	r.EnableCrypto()
+	r.testScriptWithTestLib(SCRIPT, _undefined, t)
+}
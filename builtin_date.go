@@ -3,6 +3,8 @@ package goja
 import (
 	"fmt"
 	"math"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -24,7 +26,7 @@ func (r *Runtime) makeDate(args []Value, utc bool) (t time.Time, valid bool) {
 		if !valid {
 			pv := toPrimitive(args[0])
 			if val, ok := pv.(valueString); ok {
-				return dateParse(val.String())
+				return r.dateParse(val.String())
 			}
 			pv = pv.ToNumber()
 			var n int64
@@ -72,7 +74,7 @@ func (r *Runtime) builtin_date(FunctionCall) Value {
 }
 
 func (r *Runtime) date_parse(call FunctionCall) Value {
-	t, set := dateParse(call.Argument(0).toString().String())
+	t, set := r.dateParse(call.Argument(0).toString().String())
 	if set {
 		return intToValue(timeToMsec(t))
 	}
@@ -197,11 +199,45 @@ func (r *Runtime) dateproto_toTimeString(call FunctionCall) Value {
 	panic(r.NewTypeError("Method Date.prototype.toTimeString is called on incompatible receiver"))
 }
 
+var timeZoneCache sync.Map // map[string]*time.Location
+
+// intlTimeZone returns the location for an IANA time zone name (https://tc39.es/ecma402/#sec-isvalidtimezonename).
+// The names are resolved by time.LoadLocation, so on a host without the time zone database only UTC is available
+// unless the program imports time/tzdata (or is built with the goja_tzdata tag).
+func (r *Runtime) intlTimeZone(name string) *time.Location {
+	switch strings.ToUpper(name) {
+	case "UTC", "ETC/UTC", "GMT", "ETC/GMT":
+		return time.UTC
+	}
+	if loc, ok := timeZoneCache.Load(name); ok {
+		return loc.(*time.Location)
+	}
+	if name != "Local" {
+		if loc, err := time.LoadLocation(name); err == nil {
+			timeZoneCache.Store(name, loc)
+			return loc
+		}
+	}
+	panic(r.newError(r.global.RangeError, "Invalid time zone specified: %s", name))
+}
+
+// dateLocaleTime returns the time of the date in the time zone specified by the timeZone option, or in the local
+// time zone if the option is not present, and the layouts of the best available locale (see LocaleProvider).
+func (r *Runtime) dateLocaleTime(d *dateObject, locales, options Value) (time.Time, DateTimeLayouts) {
+	layouts := r.localeDateTimeLayouts(r.intlCanonicalizeLocaleList(locales))
+	t := d.time()
+	if tz := r.intlGetOption(r.intlGetOptionsObject(options), "timeZone", nil, ""); tz != "" {
+		t = t.In(r.intlTimeZone(tz))
+	}
+	return t, layouts
+}
+
 func (r *Runtime) dateproto_toLocaleString(call FunctionCall) Value {
 	obj := r.toObject(call.This)
 	if d, ok := obj.self.(*dateObject); ok {
 		if d.isSet() {
-			return asciiString(d.time().Format(datetimeLayout_en_GB))
+			t, layouts := r.dateLocaleTime(d, call.Argument(0), call.Argument(1))
+			return newStringValue(t.Format(layouts.DateTime))
 		} else {
 			return stringInvalidDate
 		}
@@ -213,7 +249,8 @@ func (r *Runtime) dateproto_toLocaleDateString(call FunctionCall) Value {
 	obj := r.toObject(call.This)
 	if d, ok := obj.self.(*dateObject); ok {
 		if d.isSet() {
-			return asciiString(d.time().Format(dateLayout_en_GB))
+			t, layouts := r.dateLocaleTime(d, call.Argument(0), call.Argument(1))
+			return newStringValue(t.Format(layouts.Date))
 		} else {
 			return stringInvalidDate
 		}
@@ -225,7 +262,8 @@ func (r *Runtime) dateproto_toLocaleTimeString(call FunctionCall) Value {
 	obj := r.toObject(call.This)
 	if d, ok := obj.self.(*dateObject); ok {
 		if d.isSet() {
-			return asciiString(d.time().Format(timeLayout_en_GB))
+			t, layouts := r.dateLocaleTime(d, call.Argument(0), call.Argument(1))
+			return newStringValue(t.Format(layouts.Time))
 		} else {
 			return stringInvalidDate
 		}
@@ -0,0 +1,374 @@
+package goja
+
+const (
+	classDisposableStack      = "DisposableStack"
+	classAsyncDisposableStack = "AsyncDisposableStack"
+)
+
+// disposableResource is a resource registered by a 'using' declaration or by one of the DisposableStack methods.
+type disposableResource struct {
+	value  Value
+	method func(FunctionCall) Value // nil for null or undefined values of 'await using' declarations
+	async  bool
+	// set if an 'await using' resource only has a [Symbol.dispose] method. The result of the call is not awaited.
+	syncMethod bool
+}
+
+// disposeCapability holds the resources of a block with 'using' declarations or of a DisposableStack
+// (see https://tc39.es/proposal-explicit-resource-management/#sec-disposecapability-records).
+type disposeCapability struct {
+	resources []disposableResource
+	// the exception thrown so far, either by the block or by a resource disposal
+	err Value
+}
+
+type disposableStackObject struct {
+	baseObject
+	capability disposeCapability
+	disposed   bool
+}
+
+func (r *Runtime) newDisposableStackObject(proto *Object, class string) *disposableStackObject {
+	o := &Object{runtime: r}
+	s := &disposableStackObject{}
+	s.class = class
+	s.val = o
+	s.extensible = true
+	o.self = s
+	s.prototype = proto
+	s.init()
+	return s
+}
+
+// getDisposeMethod implements https://tc39.es/proposal-explicit-resource-management/#sec-getdisposemethod
+func (r *Runtime) getDisposeMethod(v Value, async bool) (method func(FunctionCall) Value, syncMethod bool) {
+	if async {
+		if method = toMethod(r.getV(v, SymAsyncDispose)); method != nil {
+			return
+		}
+		syncMethod = true
+	}
+	method = toMethod(r.getV(v, SymDispose))
+	return
+}
+
+// add implements https://tc39.es/proposal-explicit-resource-management/#sec-adddisposableresource for the case
+// when the dispose method is not provided.
+func (dc *disposeCapability) add(r *Runtime, v Value, async bool) {
+	if v == _null || v == _undefined {
+		if async {
+			dc.resources = append(dc.resources, disposableResource{value: v, async: true})
+		}
+		return
+	}
+	if _, ok := v.(*Object); !ok {
+		panic(r.NewTypeError("%s is not disposable", v.String()))
+	}
+	method, syncMethod := r.getDisposeMethod(v, async)
+	if method == nil {
+		if async {
+			panic(r.NewTypeError("%s is not async disposable", v.String()))
+		}
+		panic(r.NewTypeError("%s is not disposable", v.String()))
+	}
+	dc.resources = append(dc.resources, disposableResource{value: v, method: method, async: async, syncMethod: syncMethod})
+}
+
+// addError records an exception thrown by the block or by a resource disposal. If there is one already the new one
+// is wrapped into a SuppressedError.
+func (dc *disposeCapability) addError(r *Runtime, err Value) {
+	if dc.err != nil {
+		dc.err = r.newSuppressedError(err, dc.err)
+	} else {
+		dc.err = err
+	}
+}
+
+// next disposes the remaining resources in the reverse order of their registration until it reaches one that has
+// been added by 'await using'. It returns the value that has to be awaited in this case, or false if there are no
+// resources left.
+func (dc *disposeCapability) next(r *Runtime) (Value, bool) {
+	for len(dc.resources) > 0 {
+		res := dc.resources[len(dc.resources)-1]
+		dc.resources[len(dc.resources)-1] = disposableResource{}
+		dc.resources = dc.resources[:len(dc.resources)-1]
+		var result Value = _undefined
+		if res.method != nil {
+			if ex := r.vm.try(func() {
+				result = res.method(FunctionCall{This: res.value})
+			}); ex != nil {
+				dc.addError(r, ex.val)
+				continue
+			}
+		}
+		if res.async {
+			if res.syncMethod {
+				result = _undefined
+			}
+			return result, true
+		}
+	}
+	return nil, false
+}
+
+// disposeSync disposes all remaining resources without awaiting anything and throws the recorded exception if any.
+func (dc *disposeCapability) disposeSync(r *Runtime) {
+	for {
+		if _, ok := dc.next(r); !ok {
+			break
+		}
+	}
+	if err := dc.err; err != nil {
+		dc.err = nil
+		panic(r.vm.exceptionFromValue(err))
+	}
+}
+
+// disposeAsync disposes all remaining resources awaiting the results of the asynchronous ones and settles pcap
+// when done.
+func (dc *disposeCapability) disposeAsync(r *Runtime, pcap *promiseCapability) {
+	var step func()
+	onFulfilled := r.newNativeFunc(func(FunctionCall) Value {
+		step()
+		return _undefined
+	}, nil, "", nil, 1)
+	onRejected := r.newNativeFunc(func(call FunctionCall) Value {
+		dc.addError(r, call.Argument(0))
+		step()
+		return _undefined
+	}, nil, "", nil, 1)
+	step = func() {
+		for {
+			result, ok := dc.next(r)
+			if !ok {
+				break
+			}
+			var p *Object
+			if ex := r.vm.try(func() {
+				p = r.promiseResolve(r.global.Promise, result)
+			}); ex != nil {
+				dc.addError(r, ex.val)
+				continue
+			}
+			r.performPromiseThen(p.self.(*Promise), onFulfilled, onRejected, nil)
+			return
+		}
+		if err := dc.err; err != nil {
+			dc.err = nil
+			pcap.reject(err)
+		} else {
+			pcap.resolve(_undefined)
+		}
+	}
+	step()
+}
+
+func (r *Runtime) newSuppressedError(err, suppressed Value) Value {
+	return r.builtin_SuppressedError([]Value{err, suppressed, asciiString("An error was suppressed during disposal")}, r.global.SuppressedErrorPrototype)
+}
+
+func (r *Runtime) thisDisposableStack(this Value, class, method string) *disposableStackObject {
+	if o, ok := this.(*Object); ok {
+		if s, ok := o.self.(*disposableStackObject); ok && s.class == class {
+			return s
+		}
+	}
+	panic(r.NewTypeError("Method %s.prototype.%s called on incompatible receiver %s", class, method, r.objectproto_toString(FunctionCall{This: this})))
+}
+
+func (s *disposableStackObject) checkNotDisposed() {
+	if s.disposed {
+		panic(s.val.runtime.newError(s.val.runtime.global.ReferenceError, "%s has already been disposed", s.class))
+	}
+}
+
+func (s *disposableStackObject) use(call FunctionCall) Value {
+	s.checkNotDisposed()
+	v := call.Argument(0)
+	s.capability.add(s.val.runtime, v, s.class == classAsyncDisposableStack)
+	return v
+}
+
+func (s *disposableStackObject) adopt(call FunctionCall) Value {
+	r := s.val.runtime
+	s.checkNotDisposed()
+	v := call.Argument(0)
+	onDispose, ok := assertCallable(call.Argument(1))
+	if !ok {
+		panic(r.NewTypeError("onDispose is not a function"))
+	}
+	s.capability.resources = append(s.capability.resources, disposableResource{
+		value: _undefined,
+		method: func(FunctionCall) Value {
+			return onDispose(FunctionCall{This: _undefined, Arguments: []Value{v}})
+		},
+		async: s.class == classAsyncDisposableStack,
+	})
+	return v
+}
+
+func (s *disposableStackObject) deferFunc(call FunctionCall) Value {
+	r := s.val.runtime
+	s.checkNotDisposed()
+	onDispose, ok := assertCallable(call.Argument(0))
+	if !ok {
+		panic(r.NewTypeError("onDispose is not a function"))
+	}
+	s.capability.resources = append(s.capability.resources, disposableResource{
+		value:  _undefined,
+		method: onDispose,
+		async:  s.class == classAsyncDisposableStack,
+	})
+	return _undefined
+}
+
+func (s *disposableStackObject) move(proto *Object) Value {
+	s.checkNotDisposed()
+	res := s.val.runtime.newDisposableStackObject(proto, s.class)
+	res.capability.resources = s.capability.resources
+	s.capability.resources = nil
+	s.disposed = true
+	return res.val
+}
+
+func (r *Runtime) builtin_newDisposableStack(args []Value, newTarget *Object) *Object {
+	if newTarget == nil {
+		panic(r.needNew("DisposableStack"))
+	}
+	proto := r.getPrototypeFromCtor(newTarget, r.global.DisposableStack, r.global.DisposableStackPrototype)
+	return r.newDisposableStackObject(proto, classDisposableStack).val
+}
+
+func (r *Runtime) disposableStackProto_getDisposed(call FunctionCall) Value {
+	return r.toBoolean(r.thisDisposableStack(call.This, classDisposableStack, "disposed").disposed)
+}
+
+func (r *Runtime) disposableStackProto_dispose(call FunctionCall) Value {
+	s := r.thisDisposableStack(call.This, classDisposableStack, "dispose")
+	if !s.disposed {
+		s.disposed = true
+		s.capability.disposeSync(r)
+	}
+	return _undefined
+}
+
+func (r *Runtime) disposableStackProto_use(call FunctionCall) Value {
+	return r.thisDisposableStack(call.This, classDisposableStack, "use").use(call)
+}
+
+func (r *Runtime) disposableStackProto_adopt(call FunctionCall) Value {
+	return r.thisDisposableStack(call.This, classDisposableStack, "adopt").adopt(call)
+}
+
+func (r *Runtime) disposableStackProto_defer(call FunctionCall) Value {
+	return r.thisDisposableStack(call.This, classDisposableStack, "defer").deferFunc(call)
+}
+
+func (r *Runtime) disposableStackProto_move(call FunctionCall) Value {
+	return r.thisDisposableStack(call.This, classDisposableStack, "move").move(r.global.DisposableStackPrototype)
+}
+
+func (r *Runtime) createDisposableStackProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("constructor", r.global.DisposableStack, true, false, true)
+	o.setOwnStr("disposed", &valueProperty{
+		getterFunc:   r.newNativeFunc(r.disposableStackProto_getDisposed, nil, "get disposed", nil, 0),
+		accessor:     true,
+		configurable: true,
+	}, true)
+	dispose := r.newNativeFunc(r.disposableStackProto_dispose, nil, "dispose", nil, 0)
+	o._putProp("dispose", dispose, true, false, true)
+	o._putProp("use", r.newNativeFunc(r.disposableStackProto_use, nil, "use", nil, 1), true, false, true)
+	o._putProp("adopt", r.newNativeFunc(r.disposableStackProto_adopt, nil, "adopt", nil, 2), true, false, true)
+	o._putProp("defer", r.newNativeFunc(r.disposableStackProto_defer, nil, "defer", nil, 1), true, false, true)
+	o._putProp("move", r.newNativeFunc(r.disposableStackProto_move, nil, "move", nil, 0), true, false, true)
+	o._putSym(SymDispose, valueProp(dispose, true, false, true))
+	o._putSym(SymToStringTag, valueProp(asciiString(classDisposableStack), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) createDisposableStack(val *Object) objectImpl {
+	return r.newNativeConstructOnly(val, r.builtin_newDisposableStack, r.global.DisposableStackPrototype, "DisposableStack", 0)
+}
+
+func (r *Runtime) builtin_newAsyncDisposableStack(args []Value, newTarget *Object) *Object {
+	if newTarget == nil {
+		panic(r.needNew("AsyncDisposableStack"))
+	}
+	proto := r.getPrototypeFromCtor(newTarget, r.global.AsyncDisposableStack, r.global.AsyncDisposableStackPrototype)
+	return r.newDisposableStackObject(proto, classAsyncDisposableStack).val
+}
+
+func (r *Runtime) asyncDisposableStackProto_getDisposed(call FunctionCall) Value {
+	return r.toBoolean(r.thisDisposableStack(call.This, classAsyncDisposableStack, "disposed").disposed)
+}
+
+func (r *Runtime) asyncDisposableStackProto_disposeAsync(call FunctionCall) Value {
+	pcap := r.newPromiseCapability(r.global.Promise)
+	if o, ok := call.This.(*Object); ok {
+		if s, ok := o.self.(*disposableStackObject); ok && s.class == classAsyncDisposableStack {
+			if s.disposed {
+				pcap.resolve(_undefined)
+			} else {
+				s.disposed = true
+				s.capability.disposeAsync(r, pcap)
+			}
+			return pcap.promise
+		}
+	}
+	pcap.reject(r.NewTypeError("Method AsyncDisposableStack.prototype.disposeAsync called on incompatible receiver %s", r.objectproto_toString(FunctionCall{This: call.This})))
+	return pcap.promise
+}
+
+func (r *Runtime) asyncDisposableStackProto_use(call FunctionCall) Value {
+	return r.thisDisposableStack(call.This, classAsyncDisposableStack, "use").use(call)
+}
+
+func (r *Runtime) asyncDisposableStackProto_adopt(call FunctionCall) Value {
+	return r.thisDisposableStack(call.This, classAsyncDisposableStack, "adopt").adopt(call)
+}
+
+func (r *Runtime) asyncDisposableStackProto_defer(call FunctionCall) Value {
+	return r.thisDisposableStack(call.This, classAsyncDisposableStack, "defer").deferFunc(call)
+}
+
+func (r *Runtime) asyncDisposableStackProto_move(call FunctionCall) Value {
+	return r.thisDisposableStack(call.This, classAsyncDisposableStack, "move").move(r.global.AsyncDisposableStackPrototype)
+}
+
+func (r *Runtime) createAsyncDisposableStackProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("constructor", r.global.AsyncDisposableStack, true, false, true)
+	o.setOwnStr("disposed", &valueProperty{
+		getterFunc:   r.newNativeFunc(r.asyncDisposableStackProto_getDisposed, nil, "get disposed", nil, 0),
+		accessor:     true,
+		configurable: true,
+	}, true)
+	disposeAsync := r.newNativeFunc(r.asyncDisposableStackProto_disposeAsync, nil, "disposeAsync", nil, 0)
+	o._putProp("disposeAsync", disposeAsync, true, false, true)
+	o._putProp("use", r.newNativeFunc(r.asyncDisposableStackProto_use, nil, "use", nil, 1), true, false, true)
+	o._putProp("adopt", r.newNativeFunc(r.asyncDisposableStackProto_adopt, nil, "adopt", nil, 2), true, false, true)
+	o._putProp("defer", r.newNativeFunc(r.asyncDisposableStackProto_defer, nil, "defer", nil, 1), true, false, true)
+	o._putProp("move", r.newNativeFunc(r.asyncDisposableStackProto_move, nil, "move", nil, 0), true, false, true)
+	o._putSym(SymAsyncDispose, valueProp(disposeAsync, true, false, true))
+	o._putSym(SymToStringTag, valueProp(asciiString(classAsyncDisposableStack), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) createAsyncDisposableStack(val *Object) objectImpl {
+	return r.newNativeConstructOnly(val, r.builtin_newAsyncDisposableStack, r.global.AsyncDisposableStackPrototype, "AsyncDisposableStack", 0)
+}
+
+func (r *Runtime) initDisposableStack() {
+	r.global.DisposableStackPrototype = r.newLazyObject(r.createDisposableStackProto)
+	r.global.DisposableStack = r.newLazyObject(r.createDisposableStack)
+	r.addToGlobal("DisposableStack", r.global.DisposableStack)
+
+	r.global.AsyncDisposableStackPrototype = r.newLazyObject(r.createAsyncDisposableStackProto)
+	r.global.AsyncDisposableStack = r.newLazyObject(r.createAsyncDisposableStack)
+	r.addToGlobal("AsyncDisposableStack", r.global.AsyncDisposableStack)
+}
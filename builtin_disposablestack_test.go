@@ -0,0 +1,123 @@
+package goja
+
+import "testing"
+
+func TestDisposableStack(t *testing.T) {
+	const SCRIPT = `
+	var log = [];
+	var stack = new DisposableStack();
+	var res = {
+		[Symbol.dispose]() {
+			log.push("use");
+		}
+	};
+	assert.sameValue(stack.use(res), res, "use() result");
+	assert.sameValue(stack.use(null), null, "use(null)");
+	stack.defer(function() {
+		log.push("defer");
+	});
+	assert.sameValue(stack.adopt(42, function(v) {
+		log.push("adopt " + v);
+	}), 42, "adopt() result");
+
+	var moved = stack.move();
+	assert(stack.disposed, "disposed after move");
+	assert(!moved.disposed, "moved is not disposed");
+	assert.throws(ReferenceError, function() {
+		stack.use(res);
+	});
+
+	moved[Symbol.dispose]();
+	assert(compareArray(log, ["adopt 42", "defer", "use"]), "log: " + log);
+	assert(moved.disposed, "moved.disposed");
+	moved.dispose();
+	assert.sameValue(log.length, 3, "dispose() is idempotent");
+
+	assert.sameValue(Object.prototype.toString.call(moved), "[object DisposableStack]");
+	assert.sameValue(DisposableStack.prototype[Symbol.dispose], DisposableStack.prototype.dispose);
+	assert.throws(TypeError, function() {
+		DisposableStack();
+	});
+	assert.throws(TypeError, function() {
+		new DisposableStack().use({});
+	});
+
+	var s = new DisposableStack();
+	s.defer(function() {
+		throw new Error("first");
+	});
+	s.defer(function() {
+		throw new Error("second");
+	});
+	try {
+		s.dispose();
+		throw new Error("should not reach");
+	} catch (e) {
+		assert(e instanceof SuppressedError, "SuppressedError");
+		assert.sameValue(e.error.message, "first");
+		assert.sameValue(e.suppressed.message, "second");
+	}
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestAsyncDisposableStack(t *testing.T) {
+	const SCRIPT = `
+	var log = [];
+	var stack = new AsyncDisposableStack();
+	stack.use({
+		[Symbol.dispose]() {
+			log.push("sync");
+		}
+	});
+	stack.use({
+		async [Symbol.asyncDispose]() {
+			await null;
+			log.push("async");
+		}
+	});
+	stack.defer(async function() {
+		log.push("defer");
+	});
+	var p = stack.disposeAsync();
+	assert(p instanceof Promise, "disposeAsync() returns a promise");
+	assert(stack.disposed, "disposed");
+	await p;
+	assert(compareArray(log, ["defer", "async", "sync"]), "log: " + log);
+
+	var s = new AsyncDisposableStack();
+	s.defer(function() {
+		return Promise.reject(new Error("rejected"));
+	});
+	var err;
+	try {
+		await s[Symbol.asyncDispose]();
+	} catch (e) {
+		err = e;
+	}
+	assert.sameValue(err.message, "rejected");
+
+	err = undefined;
+	try {
+		await AsyncDisposableStack.prototype.disposeAsync.call({});
+	} catch (e) {
+		err = e;
+	}
+	assert(err instanceof TypeError, "incompatible receiver");
+	`
+	testAsyncFuncWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestSuppressedError(t *testing.T) {
+	const SCRIPT = `
+	var e = new SuppressedError(1, 2, "msg");
+	assert.sameValue(e.error, 1);
+	assert.sameValue(e.suppressed, 2);
+	assert.sameValue(e.message, "msg");
+	assert.sameValue(e.name, "SuppressedError");
+	assert(e instanceof Error, "instanceof Error");
+	assert.sameValue(SuppressedError.length, 3);
+	assert(!SuppressedError().hasOwnProperty("message"), "no message");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
@@ -10,14 +10,28 @@ type errorObject struct {
 	stackPropAdded bool
 }
 
-func (e *errorObject) formatStack() valueString {
+func (e *errorObject) formatStack() Value {
+	r := e.val.runtime
+	if r.prepareStackTrace != nil {
+		if res := r.prepareStackTrace(e.val, e.stack); res != nil {
+			return res
+		}
+	}
 	var b valueStringBuilder
 	val := writeErrorString(&b, e.val)
 	if val != nil {
 		b.WriteString(val)
 	}
-	b.WriteRune('\n')
 
+	if r.stackTraceFormat == StackTraceFormatV8 {
+		for _, frame := range e.stack {
+			b.WriteASCII("\n    at ")
+			frame.writeV8ToValueBuilder(&b)
+		}
+		return b.String()
+	}
+
+	b.WriteRune('\n')
 	for _, frame := range e.stack {
 		b.WriteASCII("\tat ")
 		frame.WriteToValueBuilder(&b)
@@ -28,13 +42,14 @@ func (e *errorObject) formatStack() valueString {
 
 func (e *errorObject) addStackProp() Value {
 	if !e.stackPropAdded {
+		// set the flag first so that the stack property appears absent to a PrepareStackTraceFunc
+		e.stackPropAdded = true
 		res := e._putProp(propNameStack, e.formatStack(), true, false, true)
 		if len(e.propNames) > 1 {
 			// reorder property names to ensure 'stack' is the first one
 			copy(e.propNames[1:], e.propNames)
 			e.propNames[0] = propNameStack
 		}
-		e.stackPropAdded = true
 		return res
 	}
 	return nil
@@ -141,6 +156,24 @@ func (r *Runtime) builtin_AggregateError(args []Value, proto *Object) *Object {
 	return obj.val
 }
 
+func (r *Runtime) builtin_SuppressedError(args []Value, proto *Object) *Object {
+	obj := r.newErrorObject(proto, classSuppressedError)
+	if len(args) > 2 && args[2] != nil && args[2] != _undefined {
+		obj._putProp("message", args[2].toString(), true, false, true)
+	}
+	var err, suppressed Value = _undefined, _undefined
+	if len(args) > 0 {
+		err = args[0]
+	}
+	if len(args) > 1 {
+		suppressed = args[1]
+	}
+	obj._putProp("error", err, true, false, true)
+	obj._putProp("suppressed", suppressed, true, false, true)
+
+	return obj.val
+}
+
 func writeErrorString(sb *valueStringBuilder, obj *Object) valueString {
 	var nameStr, msgStr valueString
 	name := obj.self.getStr("name", nil)
@@ -197,6 +230,10 @@ func (r *Runtime) initErrors() {
 	r.global.AggregateError = r.newNativeFuncConstructProto(r.builtin_AggregateError, "AggregateError", r.global.AggregateErrorPrototype, r.global.Error, 2)
 	r.addToGlobal("AggregateError", r.global.AggregateError)
 
+	r.global.SuppressedErrorPrototype = r.createErrorPrototype(stringSuppressedError)
+	r.global.SuppressedError = r.newNativeFuncConstructProto(r.builtin_SuppressedError, "SuppressedError", r.global.SuppressedErrorPrototype, r.global.Error, 3)
+	r.addToGlobal("SuppressedError", r.global.SuppressedError)
+
 	r.global.TypeErrorPrototype = r.createErrorPrototype(stringTypeError)
 
 	r.global.TypeError = r.newNativeFuncConstructProto(r.builtin_Error, "TypeError", r.global.TypeErrorPrototype, r.global.Error, 1)
@@ -0,0 +1,571 @@
+package goja
+
+import (
+	"time"
+)
+
+const (
+	eventPhaseNone = iota
+	eventPhaseCapturing
+	eventPhaseAtTarget
+	eventPhaseBubbling
+)
+
+type eventObject struct {
+	baseObject
+
+	typ                      string
+	target, currentTarget    *Object
+	phase                    int
+	bubbles, cancelable      bool
+	composed                 bool
+	timeStamp                float64
+	initialized, dispatching bool
+	canceled                 bool
+	inPassiveListener        bool
+	stopPropagation          bool
+	stopImmediatePropagation bool
+
+	// CustomEvent
+	custom bool
+	detail Value
+}
+
+type eventListener struct {
+	typ                    string
+	callback               *Object
+	capture, once, passive bool
+	removed                bool
+}
+
+type eventTargetObject struct {
+	baseObject
+	listeners []*eventListener
+}
+
+func (r *Runtime) eventTimeStamp() float64 {
+	if r.performance != nil {
+		return r.performanceNow()
+	}
+	return float64(r.now().UnixNano()) / float64(time.Millisecond)
+}
+
+func (r *Runtime) newEventObject(proto *Object) *eventObject {
+	e := &eventObject{}
+	e.class = classObject
+	e.val = &Object{runtime: r, self: e}
+	e.extensible = true
+	e.prototype = proto
+	e.init()
+	e.timeStamp = r.eventTimeStamp()
+	e.initialized = true
+	return e
+}
+
+func (r *Runtime) initEventFromDict(e *eventObject, typ Value, dict Value) *Object {
+	e.typ = typ.String()
+	if init := r.webIDLDictionary(dict); init != nil {
+		e.bubbles = nilSafe(init.self.getStr("bubbles", nil)).ToBoolean()
+		e.cancelable = nilSafe(init.self.getStr("cancelable", nil)).ToBoolean()
+		e.composed = nilSafe(init.self.getStr("composed", nil)).ToBoolean()
+		if e.custom {
+			if detail := init.self.getStr("detail", nil); detail != nil {
+				e.detail = detail
+			}
+		}
+	}
+	return e.val
+}
+
+func (r *Runtime) builtin_newEvent(args []Value, newTarget *Object) *Object {
+	if newTarget == nil {
+		panic(r.needNew("Event"))
+	}
+	if len(args) == 0 {
+		panic(r.NewTypeError("The \"type\" argument must be specified"))
+	}
+	e := r.newEventObject(r.getPrototypeFromCtor(newTarget, r.global.Event, r.global.EventPrototype))
+	return r.initEventFromDict(e, args[0], argAt(args, 1))
+}
+
+func (r *Runtime) builtin_newCustomEvent(args []Value, newTarget *Object) *Object {
+	if newTarget == nil {
+		panic(r.needNew("CustomEvent"))
+	}
+	if len(args) == 0 {
+		panic(r.NewTypeError("The \"type\" argument must be specified"))
+	}
+	e := r.newEventObject(r.getPrototypeFromCtor(newTarget, r.global.CustomEvent, r.global.CustomEventPrototype))
+	e.custom = true
+	e.detail = _null
+	return r.initEventFromDict(e, args[0], argAt(args, 1))
+}
+
+func argAt(args []Value, idx int) Value {
+	if idx < len(args) {
+		return args[idx]
+	}
+	return _undefined
+}
+
+func (r *Runtime) thisEvent(this Value, method string) *eventObject {
+	if o, ok := this.(*Object); ok {
+		if e, ok := o.self.(*eventObject); ok {
+			return e
+		}
+	}
+	panic(r.NewTypeError("Method Event.prototype.%s called on incompatible receiver %s", method, r.objectproto_toString(FunctionCall{This: this})))
+}
+
+func (r *Runtime) thisCustomEvent(this Value, method string) *eventObject {
+	if o, ok := this.(*Object); ok {
+		if e, ok := o.self.(*eventObject); ok && e.custom {
+			return e
+		}
+	}
+	panic(r.NewTypeError("Method CustomEvent.prototype.%s called on incompatible receiver %s", method, r.objectproto_toString(FunctionCall{This: this})))
+}
+
+func objectOrNull(o *Object) Value {
+	if o == nil {
+		return _null
+	}
+	return o
+}
+
+func (r *Runtime) eventProto_getType(call FunctionCall) Value {
+	return newStringValue(r.thisEvent(call.This, "type").typ)
+}
+
+func (r *Runtime) eventProto_getTarget(call FunctionCall) Value {
+	return objectOrNull(r.thisEvent(call.This, "target").target)
+}
+
+func (r *Runtime) eventProto_getCurrentTarget(call FunctionCall) Value {
+	return objectOrNull(r.thisEvent(call.This, "currentTarget").currentTarget)
+}
+
+func (r *Runtime) eventProto_getEventPhase(call FunctionCall) Value {
+	return intToValue(int64(r.thisEvent(call.This, "eventPhase").phase))
+}
+
+func (r *Runtime) eventProto_getBubbles(call FunctionCall) Value {
+	return r.toBoolean(r.thisEvent(call.This, "bubbles").bubbles)
+}
+
+func (r *Runtime) eventProto_getCancelable(call FunctionCall) Value {
+	return r.toBoolean(r.thisEvent(call.This, "cancelable").cancelable)
+}
+
+func (r *Runtime) eventProto_getComposed(call FunctionCall) Value {
+	return r.toBoolean(r.thisEvent(call.This, "composed").composed)
+}
+
+func (r *Runtime) eventProto_getDefaultPrevented(call FunctionCall) Value {
+	return r.toBoolean(r.thisEvent(call.This, "defaultPrevented").canceled)
+}
+
+func (r *Runtime) eventProto_getIsTrusted(call FunctionCall) Value {
+	r.thisEvent(call.This, "isTrusted")
+	return valueFalse
+}
+
+func (r *Runtime) eventProto_getTimeStamp(call FunctionCall) Value {
+	return floatToValue(r.thisEvent(call.This, "timeStamp").timeStamp)
+}
+
+func (r *Runtime) eventProto_getReturnValue(call FunctionCall) Value {
+	return r.toBoolean(!r.thisEvent(call.This, "returnValue").canceled)
+}
+
+func (r *Runtime) eventProto_setReturnValue(call FunctionCall) Value {
+	e := r.thisEvent(call.This, "returnValue")
+	if !call.Argument(0).ToBoolean() {
+		e.setCanceled()
+	}
+	return _undefined
+}
+
+func (r *Runtime) eventProto_getCancelBubble(call FunctionCall) Value {
+	return r.toBoolean(r.thisEvent(call.This, "cancelBubble").stopPropagation)
+}
+
+func (r *Runtime) eventProto_setCancelBubble(call FunctionCall) Value {
+	e := r.thisEvent(call.This, "cancelBubble")
+	if call.Argument(0).ToBoolean() {
+		e.stopPropagation = true
+	}
+	return _undefined
+}
+
+func (e *eventObject) setCanceled() {
+	if e.cancelable && !e.inPassiveListener {
+		e.canceled = true
+	}
+}
+
+func (r *Runtime) eventProto_composedPath(call FunctionCall) Value {
+	e := r.thisEvent(call.This, "composedPath")
+	if e.currentTarget == nil {
+		return r.newArrayValues(nil)
+	}
+	return r.newArrayValues([]Value{e.currentTarget})
+}
+
+func (r *Runtime) eventProto_stopPropagation(call FunctionCall) Value {
+	r.thisEvent(call.This, "stopPropagation").stopPropagation = true
+	return _undefined
+}
+
+func (r *Runtime) eventProto_stopImmediatePropagation(call FunctionCall) Value {
+	e := r.thisEvent(call.This, "stopImmediatePropagation")
+	e.stopPropagation = true
+	e.stopImmediatePropagation = true
+	return _undefined
+}
+
+func (r *Runtime) eventProto_preventDefault(call FunctionCall) Value {
+	r.thisEvent(call.This, "preventDefault").setCanceled()
+	return _undefined
+}
+
+func (r *Runtime) eventProto_initEvent(call FunctionCall) Value {
+	e := r.thisEvent(call.This, "initEvent")
+	if !e.dispatching {
+		e.initialize(call.Argument(0).String(), call.Argument(1).ToBoolean(), call.Argument(2).ToBoolean())
+	}
+	return _undefined
+}
+
+// initialize implements https://dom.spec.whatwg.org/#concept-event-initialize
+func (e *eventObject) initialize(typ string, bubbles, cancelable bool) {
+	e.initialized = true
+	e.stopPropagation, e.stopImmediatePropagation, e.canceled = false, false, false
+	e.target = nil
+	e.typ, e.bubbles, e.cancelable = typ, bubbles, cancelable
+}
+
+func (r *Runtime) customEventProto_getDetail(call FunctionCall) Value {
+	return r.thisCustomEvent(call.This, "detail").detail
+}
+
+func (r *Runtime) customEventProto_initCustomEvent(call FunctionCall) Value {
+	e := r.thisCustomEvent(call.This, "initCustomEvent")
+	if !e.dispatching {
+		e.initialize(call.Argument(0).String(), call.Argument(1).ToBoolean(), call.Argument(2).ToBoolean())
+		e.detail = call.Argument(3)
+	}
+	return _undefined
+}
+
+func (r *Runtime) builtin_newEventTarget(args []Value, newTarget *Object) *Object {
+	if newTarget == nil {
+		panic(r.needNew("EventTarget"))
+	}
+	t := &eventTargetObject{}
+	t.class = classObject
+	t.val = &Object{runtime: r, self: t}
+	t.extensible = true
+	t.prototype = r.getPrototypeFromCtor(newTarget, r.global.EventTarget, r.global.EventTargetPrototype)
+	t.init()
+	return t.val
+}
+
+func (r *Runtime) thisEventTarget(this Value, method string) *eventTargetObject {
+	if o, ok := this.(*Object); ok {
+		if t, ok := o.self.(*eventTargetObject); ok {
+			return t
+		}
+		if o == r.globalObject && r.globalEvents != nil {
+			return r.globalEvents
+		}
+	}
+	panic(r.NewTypeError("Method EventTarget.prototype.%s called on incompatible receiver %s", method, r.objectproto_toString(FunctionCall{This: this})))
+}
+
+// flattenListenerOptions returns the capture flag and the options dictionary (if any), see
+// https://dom.spec.whatwg.org/#concept-flatten-options
+func (r *Runtime) flattenListenerOptions(options Value) (bool, *Object) {
+	if o, ok := options.(*Object); ok {
+		return nilSafe(o.self.getStr("capture", nil)).ToBoolean(), o
+	}
+	return options.ToBoolean(), nil
+}
+
+func (t *eventTargetObject) findListener(typ string, callback *Object, capture bool) int {
+	for i, l := range t.listeners {
+		if l.typ == typ && l.callback == callback && l.capture == capture {
+			return i
+		}
+	}
+	return -1
+}
+
+func (t *eventTargetObject) removeListener(idx int) {
+	t.listeners[idx].removed = true
+	copy(t.listeners[idx:], t.listeners[idx+1:])
+	t.listeners[len(t.listeners)-1] = nil
+	t.listeners = t.listeners[:len(t.listeners)-1]
+}
+
+// eventTargetProto_addEventListener implements https://dom.spec.whatwg.org/#dom-eventtarget-addeventlistener.
+// The signal option accepts any object with the aborted property and the addEventListener() method, so that an
+// AbortSignal implemented on top of EventTarget can be used.
+func (r *Runtime) eventTargetProto_addEventListener(call FunctionCall) Value {
+	t := r.thisEventTarget(call.This, "addEventListener")
+	typ := call.Argument(0).String()
+	capture, options := r.flattenListenerOptions(call.Argument(2))
+	l := &eventListener{typ: typ, capture: capture}
+	var signal *Object
+	if options != nil {
+		l.once = nilSafe(options.self.getStr("once", nil)).ToBoolean()
+		l.passive = nilSafe(options.self.getStr("passive", nil)).ToBoolean()
+		if s := nilSafe(options.self.getStr("signal", nil)); s != _undefined {
+			if s, ok := s.(*Object); ok {
+				signal = s
+			} else {
+				panic(r.NewTypeError("The signal option must be an AbortSignal"))
+			}
+		}
+	}
+	callback, ok := call.Argument(1).(*Object)
+	if !ok {
+		if call.Argument(1) != _null {
+			panic(r.NewTypeError("The listener must be an object or a function"))
+		}
+		return _undefined
+	}
+	l.callback = callback
+	if signal != nil && nilSafe(signal.self.getStr("aborted", nil)).ToBoolean() {
+		return _undefined
+	}
+	if t.findListener(typ, callback, capture) >= 0 {
+		return _undefined
+	}
+	t.listeners = append(t.listeners, l)
+	if signal != nil {
+		remove := r.newNativeFunc(func(FunctionCall) Value {
+			for i, listener := range t.listeners {
+				if listener == l {
+					t.removeListener(i)
+					break
+				}
+			}
+			return _undefined
+		}, nil, "", nil, 0)
+		opts := r.NewObject()
+		opts.self._putProp("once", valueTrue, true, true, true)
+		r.invoke(signal, "addEventListener", asciiString("abort"), remove, opts)
+	}
+	return _undefined
+}
+
+func (r *Runtime) eventTargetProto_removeEventListener(call FunctionCall) Value {
+	t := r.thisEventTarget(call.This, "removeEventListener")
+	capture, _ := r.flattenListenerOptions(call.Argument(2))
+	if callback, ok := call.Argument(1).(*Object); ok {
+		if idx := t.findListener(call.Argument(0).String(), callback, capture); idx >= 0 {
+			t.removeListener(idx)
+		}
+	}
+	return _undefined
+}
+
+// eventTargetProto_dispatchEvent implements https://dom.spec.whatwg.org/#concept-event-dispatch for a target
+// without parents, i.e. the event path consists of the target alone. Unlike in browsers an exception thrown by
+// a listener is not swallowed: the remaining listeners are still called, then the first exception is re-thrown.
+func (r *Runtime) eventTargetProto_dispatchEvent(call FunctionCall) Value {
+	t := r.thisEventTarget(call.This, "dispatchEvent")
+	var e *eventObject
+	if o, ok := call.Argument(0).(*Object); ok {
+		e, _ = o.self.(*eventObject)
+	}
+	if e == nil {
+		panic(r.NewTypeError("The event argument must be an instance of Event"))
+	}
+	if e.dispatching || !e.initialized {
+		panic(r.NewTypeError("The event is already being dispatched"))
+	}
+	return r.toBoolean(r.dispatchEvent(t.val, &t.listeners, e))
+}
+
+func (r *Runtime) dispatchEvent(target *Object, listeners *[]*eventListener, e *eventObject) bool {
+	e.dispatching = true
+	e.target = target
+	e.currentTarget = target
+	e.phase = eventPhaseAtTarget
+	var firstEx *Exception
+	for _, capturePass := range []bool{true, false} {
+		if e.stopPropagation {
+			break
+		}
+		list := append([]*eventListener(nil), *listeners...)
+		for _, l := range list {
+			if l.removed || l.capture != capturePass || l.typ != e.typ {
+				continue
+			}
+			if l.once {
+				for i, listener := range *listeners {
+					if listener == l {
+						l.removed = true
+						*listeners = append((*listeners)[:i], (*listeners)[i+1:]...)
+						break
+					}
+				}
+			}
+			e.inPassiveListener = l.passive
+			if ex := r.vm.try(func() {
+				if fn, ok := l.callback.self.assertCallable(); ok {
+					fn(FunctionCall{This: target, Arguments: []Value{e.val}})
+				} else {
+					handleEvent := l.callback.self.getStr("handleEvent", nil)
+					r.toCallable(handleEvent)(FunctionCall{This: l.callback, Arguments: []Value{e.val}})
+				}
+			}); ex != nil && firstEx == nil {
+				firstEx = ex
+			}
+			e.inPassiveListener = false
+			if e.stopImmediatePropagation {
+				break
+			}
+		}
+	}
+	e.phase = eventPhaseNone
+	e.currentTarget = nil
+	e.dispatching = false
+	e.stopPropagation = false
+	e.stopImmediatePropagation = false
+	if firstEx != nil {
+		panic(firstEx)
+	}
+	return !e.canceled
+}
+
+func (r *Runtime) putEventPhaseConstants(o *baseObject) {
+	o._putProp("NONE", intToValue(eventPhaseNone), false, true, false)
+	o._putProp("CAPTURING_PHASE", intToValue(eventPhaseCapturing), false, true, false)
+	o._putProp("AT_TARGET", intToValue(eventPhaseAtTarget), false, true, false)
+	o._putProp("BUBBLING_PHASE", intToValue(eventPhaseBubbling), false, true, false)
+}
+
+func (r *Runtime) createEventProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("constructor", r.global.Event, true, false, true)
+	r.putWebIDLAccessor(o, "type", r.eventProto_getType, nil)
+	r.putWebIDLAccessor(o, "target", r.eventProto_getTarget, nil)
+	r.putWebIDLAccessor(o, "srcElement", r.eventProto_getTarget, nil)
+	r.putWebIDLAccessor(o, "currentTarget", r.eventProto_getCurrentTarget, nil)
+	o._putProp("composedPath", r.newNativeFunc(r.eventProto_composedPath, nil, "composedPath", nil, 0), true, true, true)
+	r.putEventPhaseConstants(o)
+	r.putWebIDLAccessor(o, "eventPhase", r.eventProto_getEventPhase, nil)
+	o._putProp("stopPropagation", r.newNativeFunc(r.eventProto_stopPropagation, nil, "stopPropagation", nil, 0), true, true, true)
+	r.putWebIDLAccessor(o, "cancelBubble", r.eventProto_getCancelBubble, r.eventProto_setCancelBubble)
+	o._putProp("stopImmediatePropagation", r.newNativeFunc(r.eventProto_stopImmediatePropagation, nil, "stopImmediatePropagation", nil, 0), true, true, true)
+	r.putWebIDLAccessor(o, "bubbles", r.eventProto_getBubbles, nil)
+	r.putWebIDLAccessor(o, "cancelable", r.eventProto_getCancelable, nil)
+	r.putWebIDLAccessor(o, "returnValue", r.eventProto_getReturnValue, r.eventProto_setReturnValue)
+	o._putProp("preventDefault", r.newNativeFunc(r.eventProto_preventDefault, nil, "preventDefault", nil, 0), true, true, true)
+	r.putWebIDLAccessor(o, "defaultPrevented", r.eventProto_getDefaultPrevented, nil)
+	r.putWebIDLAccessor(o, "composed", r.eventProto_getComposed, nil)
+	r.putWebIDLAccessor(o, "isTrusted", r.eventProto_getIsTrusted, nil)
+	r.putWebIDLAccessor(o, "timeStamp", r.eventProto_getTimeStamp, nil)
+	o._putProp("initEvent", r.newNativeFunc(r.eventProto_initEvent, nil, "initEvent", nil, 1), true, true, true)
+	o._putSym(SymToStringTag, valueProp(asciiString("Event"), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) createEvent(val *Object) objectImpl {
+	o := r.newNativeConstructOnly(val, r.builtin_newEvent, r.global.EventPrototype, "Event", 1)
+	r.putEventPhaseConstants(&o.baseObject)
+	return o
+}
+
+func (r *Runtime) createCustomEventProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.EventPrototype, classObject)
+
+	o._putProp("constructor", r.global.CustomEvent, true, false, true)
+	r.putWebIDLAccessor(o, "detail", r.customEventProto_getDetail, nil)
+	o._putProp("initCustomEvent", r.newNativeFunc(r.customEventProto_initCustomEvent, nil, "initCustomEvent", nil, 1), true, true, true)
+	o._putSym(SymToStringTag, valueProp(asciiString("CustomEvent"), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) createCustomEvent(val *Object) objectImpl {
+	o := r.newNativeConstructOnly(val, r.builtin_newCustomEvent, r.global.CustomEventPrototype, "CustomEvent", 1)
+	o.prototype = r.global.Event
+	return o
+}
+
+func (r *Runtime) createEventTargetProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("constructor", r.global.EventTarget, true, false, true)
+	o._putProp("addEventListener", r.newNativeFunc(r.eventTargetProto_addEventListener, nil, "addEventListener", nil, 2), true, true, true)
+	o._putProp("removeEventListener", r.newNativeFunc(r.eventTargetProto_removeEventListener, nil, "removeEventListener", nil, 2), true, true, true)
+	o._putProp("dispatchEvent", r.newNativeFunc(r.eventTargetProto_dispatchEvent, nil, "dispatchEvent", nil, 1), true, true, true)
+	o._putSym(SymToStringTag, valueProp(asciiString("EventTarget"), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) createEventTarget(val *Object) objectImpl {
+	return r.newNativeConstructOnly(val, r.builtin_newEventTarget, r.global.EventTargetPrototype, "EventTarget", 0)
+}
+
+// globalEventTargetMethod makes an EventTarget method callable as a function of the global object, i.e. without
+// a receiver.
+func (r *Runtime) globalEventTargetMethod(method func(FunctionCall) Value) func(FunctionCall) Value {
+	return func(call FunctionCall) Value {
+		if call.This == _undefined || call.This == _null {
+			call.This = r.globalObject
+		}
+		return method(call)
+	}
+}
+
+// dispatchPromiseRejectionEvent dispatches an unhandledrejection or a rejectionhandled event (see
+// https://html.spec.whatwg.org/multipage/webappapis.html#the-promiserejectionevent-interface) to the global object.
+// The promise and reason attributes are read-only own properties of the event.
+func (r *Runtime) dispatchPromiseRejectionEvent(typ string, p *Promise) bool {
+	e := r.newEventObject(r.global.EventPrototype)
+	e.typ = typ
+	e.cancelable = typ == "unhandledrejection"
+	e._putProp("promise", p.val, false, true, true)
+	e._putProp("reason", p.result, false, true, true)
+	return r.dispatchEvent(r.globalObject, &r.globalEvents.listeners, e)
+}
+
+// EnableEvents adds the EventTarget, Event and CustomEvent globals as described in https://dom.spec.whatwg.org/.
+// There is no DOM tree, so an event is only delivered to the target it is dispatched on (capturing listeners
+// first, then the others) and the bubbles flag has no effect. The listeners are called synchronously by
+// dispatchEvent(). Event.timeStamp is relative to the time origin if the performance global is enabled (see
+// EnablePerformance), otherwise it is the number of milliseconds since the Unix epoch.
+//
+// The global object also becomes an event target: the addEventListener, removeEventListener and dispatchEvent
+// functions are added to it. If the Runtime has a Loop, the unhandledrejection and rejectionhandled events are
+// dispatched to it (see NewLoop).
+func (r *Runtime) EnableEvents() {
+	if r.global.EventTarget != nil {
+		return
+	}
+	r.global.EventPrototype = r.newLazyObject(r.createEventProto)
+	r.global.Event = r.newLazyObject(r.createEvent)
+	r.addToGlobal("Event", r.global.Event)
+
+	r.global.CustomEventPrototype = r.newLazyObject(r.createCustomEventProto)
+	r.global.CustomEvent = r.newLazyObject(r.createCustomEvent)
+	r.addToGlobal("CustomEvent", r.global.CustomEvent)
+
+	r.global.EventTargetPrototype = r.newLazyObject(r.createEventTargetProto)
+	r.global.EventTarget = r.newLazyObject(r.createEventTarget)
+	r.addToGlobal("EventTarget", r.global.EventTarget)
+
+	r.globalEvents = &eventTargetObject{}
+	r.globalEvents.val = r.globalObject
+	r.addToGlobal("addEventListener", r.newNativeFunc(r.globalEventTargetMethod(r.eventTargetProto_addEventListener), nil, "addEventListener", nil, 2))
+	r.addToGlobal("removeEventListener", r.newNativeFunc(r.globalEventTargetMethod(r.eventTargetProto_removeEventListener), nil, "removeEventListener", nil, 2))
+	r.addToGlobal("dispatchEvent", r.newNativeFunc(r.globalEventTargetMethod(r.eventTargetProto_dispatchEvent), nil, "dispatchEvent", nil, 1))
+}
@@ -0,0 +1,144 @@
+package goja
+
+import (
+	"testing"
+)
+
+func TestEventsDisabled(t *testing.T) {
+	testScript(`typeof EventTarget + typeof Event + typeof CustomEvent`, asciiString("undefinedundefinedundefined"), t)
+}
+
+func TestEventTarget(t *testing.T) {
+	const SCRIPT = `
+	var target = new EventTarget();
+	var log = [];
+	function a(e) { log.push("a:" + e.eventPhase + ":" + (this === target) + ":" + (e.currentTarget === target)) }
+	target.addEventListener("foo", a);
+	target.addEventListener("foo", a);
+	target.addEventListener("foo", function() { log.push("capture") }, true);
+	target.addEventListener("foo", { handleEvent: function(e) { log.push("handler:" + e.type) } });
+	target.addEventListener("foo", function() { log.push("once") }, { once: true });
+	target.addEventListener("bar", function() { log.push("bar") });
+
+	var e = new Event("foo");
+	assert.sameValue(e.eventPhase, Event.NONE);
+	assert.sameValue(target.dispatchEvent(e), true, "not canceled");
+	assert(compareArray(log, ["capture", "a:2:true:true", "handler:foo", "once"]), "first dispatch: " + log);
+	assert.sameValue(e.target, target, "target");
+	assert.sameValue(e.currentTarget, null, "currentTarget after dispatch");
+	assert.sameValue(e.eventPhase, 0, "eventPhase after dispatch");
+
+	log = [];
+	target.removeEventListener("foo", a, true);
+	target.dispatchEvent(new Event("foo"));
+	assert(compareArray(log, ["capture", "a:2:true:true", "handler:foo"]), "remove with a different capture: " + log);
+
+	log = [];
+	target.removeEventListener("foo", a);
+	target.dispatchEvent(new Event("foo"));
+	assert(compareArray(log, ["capture", "handler:foo"]), "removed: " + log);
+
+	assert.throws(TypeError, function() { target.dispatchEvent({ type: "foo" }) }, "not an event");
+	assert.throws(TypeError, function() { Event("foo") }, "needs new");
+	assert.throws(TypeError, function() { new Event() }, "type is required");
+	assert.sameValue(Object.prototype.toString.call(target), "[object EventTarget]");
+	`
+	r := New()
+	r.EnableEvents()
+	r.testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestEventCancel(t *testing.T) {
+	const SCRIPT = `
+	var target = new EventTarget();
+	target.addEventListener("foo", function(e) { e.preventDefault() }, { passive: true });
+	var e = new Event("foo", { cancelable: true });
+	assert.sameValue(target.dispatchEvent(e), true, "passive listeners cannot cancel");
+
+	target.addEventListener("foo", function(e) { e.preventDefault() });
+	assert.sameValue(target.dispatchEvent(e), false, "canceled");
+	assert.sameValue(e.defaultPrevented, true);
+	assert.sameValue(e.returnValue, false);
+
+	e = new Event("foo");
+	assert.sameValue(target.dispatchEvent(e), true, "not cancelable");
+	assert.sameValue(e.defaultPrevented, false);
+
+	var log = [];
+	var t2 = new EventTarget();
+	t2.addEventListener("foo", function(e) { log.push(1); e.stopImmediatePropagation() });
+	t2.addEventListener("foo", function() { log.push(2) });
+	t2.dispatchEvent(new Event("foo"));
+	assert(compareArray(log, [1]), "stopImmediatePropagation: " + log);
+
+	log = [];
+	t2.addEventListener("foo", function(e) { log.push("capture"); e.stopPropagation() }, true);
+	t2.dispatchEvent(new Event("foo"));
+	assert(compareArray(log, ["capture"]), "stopPropagation: " + log);
+	`
+	r := New()
+	r.EnableEvents()
+	r.testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestEventListenerException(t *testing.T) {
+	const SCRIPT = `
+	var target = new EventTarget();
+	var called = false;
+	target.addEventListener("foo", function() { throw new Error("first") });
+	target.addEventListener("foo", function() { called = true });
+	assert.throws(Error, function() { target.dispatchEvent(new Event("foo")) });
+	assert(called, "the remaining listeners are called");
+	`
+	r := New()
+	r.EnableEvents()
+	r.testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestEventListenerSignal(t *testing.T) {
+	const SCRIPT = `
+	var controller = new EventTarget();
+	controller.aborted = false;
+	var target = new EventTarget();
+	var count = 0;
+	target.addEventListener("foo", function() { count++ }, { signal: controller });
+	target.dispatchEvent(new Event("foo"));
+	controller.aborted = true;
+	controller.dispatchEvent(new Event("abort"));
+	target.dispatchEvent(new Event("foo"));
+	assert.sameValue(count, 1, "removed on abort");
+	target.addEventListener("foo", function() { count++ }, { signal: controller });
+	target.dispatchEvent(new Event("foo"));
+	assert.sameValue(count, 1, "not added when already aborted");
+	`
+	r := New()
+	r.EnableEvents()
+	r.testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestCustomEvent(t *testing.T) {
+	const SCRIPT = `
+	var e = new CustomEvent("foo", { detail: { x: 1 }, bubbles: true });
+	assert(e instanceof Event, "instanceof Event");
+	assert.sameValue(Object.getPrototypeOf(CustomEvent), Event);
+	assert.sameValue(e.detail.x, 1);
+	assert.sameValue(e.bubbles, true);
+	assert.sameValue(new CustomEvent("foo").detail, null);
+	e.initCustomEvent("bar", false, true, 42);
+	assert.sameValue(e.type, "bar");
+	assert.sameValue(e.cancelable, true);
+	assert.sameValue(e.detail, 42);
+	assert.throws(TypeError, function() { CustomEvent.prototype.initCustomEvent.call(new Event("x")) });
+
+	class MyTarget extends EventTarget {}
+	var t = new MyTarget();
+	var got;
+	t.addEventListener("foo", function(e) { got = e.detail });
+	t.dispatchEvent(new CustomEvent("foo", { detail: "d" }));
+	assert.sameValue(got, "d");
+	assert.sameValue(Object.prototype.toString.call(e), "[object CustomEvent]");
+	`
+	r := New()
+	r.EnableEvents()
+	r.testScriptWithTestLib(SCRIPT, _undefined, t)
+}
@@ -0,0 +1,1072 @@
+package goja
+
+import (
+	"bytes"
+	gocontext "context"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dop251/goja/unistring"
+)
+
+type fetchHeader struct {
+	name, value string
+}
+
+type headersObject struct {
+	baseObject
+	// the names are lower-case, the values are byte strings (each rune is in the range 0-255)
+	list      []fetchHeader
+	immutable bool
+}
+
+type headersIterObject struct {
+	baseObject
+	headers *headersObject
+	idx     int
+	kind    iterationKind
+}
+
+// fetchBody is the body of a Request or a Response (https://fetch.spec.whatwg.org/#concept-body). It is always
+// fully buffered.
+type fetchBody struct {
+	body    []byte
+	hasBody bool
+	used    bool
+}
+
+type requestObject struct {
+	baseObject
+	fetchBody
+	method   string
+	url      string
+	headers  *headersObject
+	redirect string
+	signal   Value
+}
+
+type responseObject struct {
+	baseObject
+	fetchBody
+	typ        string
+	url        string
+	redirected bool
+	status     int
+	statusText string
+	headers    *headersObject
+}
+
+func isHTTPTokenChar(c rune) bool {
+	if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' {
+		return true
+	}
+	return strings.ContainsRune("!#$%&'*+-.^_`|~", c)
+}
+
+func isHTTPToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if !isHTTPTokenChar(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// toByteString implements the WebIDL ByteString conversion.
+func (r *Runtime) toByteString(v Value) string {
+	s := v.toString().String()
+	for _, c := range s {
+		if c > 0xFF {
+			panic(r.NewTypeError("Cannot convert %q to a ByteString because the character at index %d has a value greater than 255", s, strings.IndexRune(s, c)))
+		}
+	}
+	return s
+}
+
+// latin1Bytes converts a byte string to the bytes it represents.
+func latin1Bytes(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		b.WriteByte(byte(c))
+	}
+	return b.String()
+}
+
+// latin1String converts bytes to a byte string.
+func latin1String(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		b.WriteRune(rune(s[i]))
+	}
+	return b.String()
+}
+
+func (r *Runtime) headerName(v Value) string {
+	name := r.toByteString(v)
+	if !isHTTPToken(name) {
+		panic(r.NewTypeError("Invalid header name: %q", name))
+	}
+	return strings.ToLower(name)
+}
+
+// headerValue normalises a header value (https://fetch.spec.whatwg.org/#concept-header-value-normalize) and
+// validates it.
+func (r *Runtime) headerValue(v Value) string {
+	value := strings.Trim(r.toByteString(v), "\t\n\r ")
+	if strings.ContainsAny(value, "\x00\r\n") {
+		panic(r.NewTypeError("Invalid header value: %q", value))
+	}
+	return value
+}
+
+func (h *headersObject) get(name string) (string, bool) {
+	var values []string
+	for _, header := range h.list {
+		if header.name == name {
+			values = append(values, header.value)
+		}
+	}
+	return strings.Join(values, ", "), values != nil
+}
+
+func (h *headersObject) has(name string) bool {
+	for _, header := range h.list {
+		if header.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *headersObject) delete(name string) {
+	list := h.list[:0]
+	for _, header := range h.list {
+		if header.name != name {
+			list = append(list, header)
+		}
+	}
+	h.list = list
+}
+
+func (h *headersObject) set(name, value string) {
+	found := false
+	list := h.list[:0]
+	for _, header := range h.list {
+		if header.name == name {
+			if found {
+				continue
+			}
+			found = true
+			header.value = value
+		}
+		list = append(list, header)
+	}
+	if !found {
+		list = append(list, fetchHeader{name, value})
+	}
+	h.list = list
+}
+
+// sortAndCombine implements https://fetch.spec.whatwg.org/#concept-header-list-sort-and-combine
+func (h *headersObject) sortAndCombine() []fetchHeader {
+	var names []string
+	seen := make(map[string]bool)
+	for _, header := range h.list {
+		if !seen[header.name] {
+			seen[header.name] = true
+			names = append(names, header.name)
+		}
+	}
+	sort.Strings(names)
+	res := make([]fetchHeader, 0, len(names))
+	for _, name := range names {
+		if name == "set-cookie" {
+			for _, header := range h.list {
+				if header.name == name {
+					res = append(res, header)
+				}
+			}
+		} else {
+			value, _ := h.get(name)
+			res = append(res, fetchHeader{name, value})
+		}
+	}
+	return res
+}
+
+func (r *Runtime) newHeadersObject(proto *Object) *headersObject {
+	h := &headersObject{}
+	h.class = classObject
+	h.val = &Object{runtime: r, self: h}
+	h.extensible = true
+	h.prototype = proto
+	h.init()
+	return h
+}
+
+// fill implements https://fetch.spec.whatwg.org/#concept-headers-fill
+func (r *Runtime) fillHeaders(h *headersObject, init Value) {
+	o, ok := init.(*Object)
+	if !ok {
+		panic(r.NewTypeError("The provided value is not of type '(record<ByteString, ByteString> or sequence<sequence<ByteString>>)'"))
+	}
+	if method := toMethod(o.self.getSym(SymIterator, nil)); method != nil {
+		r.getIterator(o, method).iterate(func(item Value) {
+			var pair []Value
+			if obj, ok := item.(*Object); ok {
+				pair = r.iterableToList(obj, nil)
+			}
+			if len(pair) != 2 {
+				panic(r.NewTypeError("Each header pair must be an iterable [name, value] tuple"))
+			}
+			h.list = append(h.list, fetchHeader{r.headerName(pair[0]), r.headerValue(pair[1])})
+		})
+	} else {
+		for item, next := iterateEnumerableStringProperties(o)(); next != nil; item, next = next() {
+			h.list = append(h.list, fetchHeader{r.headerName(item.name), r.headerValue(nilSafe(item.value))})
+		}
+	}
+}
+
+func (r *Runtime) builtin_newHeaders(args []Value, newTarget *Object) *Object {
+	if newTarget == nil {
+		panic(r.needNew("Headers"))
+	}
+	h := r.newHeadersObject(r.getPrototypeFromCtor(newTarget, r.global.Headers, r.global.HeadersPrototype))
+	if init := argAt(args, 0); init != _undefined {
+		r.fillHeaders(h, init)
+	}
+	return h.val
+}
+
+func (r *Runtime) thisHeaders(this Value, method string) *headersObject {
+	if o, ok := this.(*Object); ok {
+		if h, ok := o.self.(*headersObject); ok {
+			return h
+		}
+	}
+	panic(r.NewTypeError("Method Headers.prototype.%s called on incompatible receiver %s", method, r.objectproto_toString(FunctionCall{This: this})))
+}
+
+func (r *Runtime) checkHeadersMutable(h *headersObject) {
+	if h.immutable {
+		panic(r.NewTypeError("Headers are immutable"))
+	}
+}
+
+func (r *Runtime) headersProto_append(call FunctionCall) Value {
+	h := r.thisHeaders(call.This, "append")
+	name, value := r.headerName(call.Argument(0)), r.headerValue(call.Argument(1))
+	r.checkHeadersMutable(h)
+	h.list = append(h.list, fetchHeader{name, value})
+	return _undefined
+}
+
+func (r *Runtime) headersProto_delete(call FunctionCall) Value {
+	h := r.thisHeaders(call.This, "delete")
+	name := r.headerName(call.Argument(0))
+	r.checkHeadersMutable(h)
+	h.delete(name)
+	return _undefined
+}
+
+func (r *Runtime) headersProto_get(call FunctionCall) Value {
+	h := r.thisHeaders(call.This, "get")
+	if value, ok := h.get(r.headerName(call.Argument(0))); ok {
+		return newStringValue(value)
+	}
+	return _null
+}
+
+func (r *Runtime) headersProto_getSetCookie(call FunctionCall) Value {
+	h := r.thisHeaders(call.This, "getSetCookie")
+	var res []Value
+	for _, header := range h.list {
+		if header.name == "set-cookie" {
+			res = append(res, newStringValue(header.value))
+		}
+	}
+	return r.newArrayValues(res)
+}
+
+func (r *Runtime) headersProto_has(call FunctionCall) Value {
+	h := r.thisHeaders(call.This, "has")
+	return r.toBoolean(h.has(r.headerName(call.Argument(0))))
+}
+
+func (r *Runtime) headersProto_set(call FunctionCall) Value {
+	h := r.thisHeaders(call.This, "set")
+	name, value := r.headerName(call.Argument(0)), r.headerValue(call.Argument(1))
+	r.checkHeadersMutable(h)
+	h.set(name, value)
+	return _undefined
+}
+
+func (r *Runtime) headersProto_forEach(call FunctionCall) Value {
+	h := r.thisHeaders(call.This, "forEach")
+	callback := r.toCallable(call.Argument(0))
+	thisArg := call.Argument(1)
+	for i := 0; ; i++ {
+		list := h.sortAndCombine()
+		if i >= len(list) {
+			break
+		}
+		callback(FunctionCall{This: thisArg, Arguments: []Value{newStringValue(list[i].value), newStringValue(list[i].name), h.val}})
+	}
+	return _undefined
+}
+
+func (r *Runtime) createHeadersIterator(this Value, kind iterationKind, method string) Value {
+	h := r.thisHeaders(this, method)
+	it := &headersIterObject{headers: h, kind: kind}
+	it.class = classObject
+	it.val = &Object{runtime: r, self: it}
+	it.extensible = true
+	it.prototype = r.global.HeadersIteratorPrototype
+	it.init()
+	return it.val
+}
+
+func (r *Runtime) headersProto_entries(call FunctionCall) Value {
+	return r.createHeadersIterator(call.This, iterationKindKeyValue, "entries")
+}
+
+func (r *Runtime) headersProto_keys(call FunctionCall) Value {
+	return r.createHeadersIterator(call.This, iterationKindKey, "keys")
+}
+
+func (r *Runtime) headersProto_values(call FunctionCall) Value {
+	return r.createHeadersIterator(call.This, iterationKindValue, "values")
+}
+
+func (r *Runtime) headersIterProto_next(call FunctionCall) Value {
+	if o, ok := call.This.(*Object); ok {
+		if it, ok := o.self.(*headersIterObject); ok {
+			if it.headers == nil {
+				return r.createIterResultObject(_undefined, true)
+			}
+			list := it.headers.sortAndCombine()
+			if it.idx >= len(list) {
+				it.headers = nil
+				return r.createIterResultObject(_undefined, true)
+			}
+			header := list[it.idx]
+			it.idx++
+			var res Value
+			switch it.kind {
+			case iterationKindKey:
+				res = newStringValue(header.name)
+			case iterationKindValue:
+				res = newStringValue(header.value)
+			default:
+				res = r.newArrayValues([]Value{newStringValue(header.name), newStringValue(header.value)})
+			}
+			return r.createIterResultObject(res, false)
+		}
+	}
+	panic(r.NewTypeError("Method Headers Iterator.prototype.next called on incompatible receiver %s", r.objectproto_toString(FunctionCall{This: call.This})))
+}
+
+// extractBody implements https://fetch.spec.whatwg.org/#concept-bodyinit-extract for the supported body types:
+// strings, URLSearchParams and BufferSources. Other values are converted to strings.
+func (r *Runtime) extractBody(v Value) (body []byte, contentType string) {
+	if o, ok := v.(*Object); ok {
+		if p, ok := o.self.(*urlSearchParamsObject); ok {
+			return []byte(serializeURLEncoded(p.list)), "application/x-www-form-urlencoded;charset=UTF-8"
+		}
+		if b, ok := r.bufferSourceBytes(v); ok {
+			return append([]byte{}, b...), ""
+		}
+	}
+	return []byte(v.toString().String()), "text/plain;charset=UTF-8"
+}
+
+func (r *Runtime) thisFetchBody(this Value, class, method string) *fetchBody {
+	if o, ok := this.(*Object); ok {
+		switch o := o.self.(type) {
+		case *requestObject:
+			if class == "Request" {
+				return &o.fetchBody
+			}
+		case *responseObject:
+			if class == "Response" {
+				return &o.fetchBody
+			}
+		}
+	}
+	panic(r.NewTypeError("Method %s.prototype.%s called on incompatible receiver %s", class, method, r.objectproto_toString(FunctionCall{This: this})))
+}
+
+// consumeBody implements https://fetch.spec.whatwg.org/#concept-body-consume-body
+func (r *Runtime) consumeBody(call FunctionCall, class, method string, convert func([]byte) Value) Value {
+	pcap := r.newPromiseCapability(r.global.Promise)
+	pcap.try(func() {
+		b := r.thisFetchBody(call.This, class, method)
+		if b.used {
+			panic(r.NewTypeError("Body is unusable: Body has already been read"))
+		}
+		if b.hasBody {
+			b.used = true
+		}
+		pcap.resolve(convert(b.body))
+	})
+	return pcap.promise
+}
+
+func (r *Runtime) putFetchBodyMethods(o *baseObject, class string) {
+	r.putWebIDLAccessor(o, "bodyUsed", func(call FunctionCall) Value {
+		return r.toBoolean(r.thisFetchBody(call.This, class, "bodyUsed").used)
+	}, nil)
+	o._putProp("arrayBuffer", r.newNativeFunc(func(call FunctionCall) Value {
+		return r.consumeBody(call, class, "arrayBuffer", func(b []byte) Value {
+			buf := r._newArrayBuffer(r.global.ArrayBufferPrototype, nil)
+			buf.data = append([]byte{}, b...)
+			return buf.val
+		})
+	}, nil, "arrayBuffer", nil, 0), true, true, true)
+	o._putProp("bytes", r.newNativeFunc(func(call FunctionCall) Value {
+		return r.consumeBody(call, class, "bytes", func(b []byte) Value {
+			return r.newUint8ArrayFromBytes(b)
+		})
+	}, nil, "bytes", nil, 0), true, true, true)
+	o._putProp("json", r.newNativeFunc(func(call FunctionCall) Value {
+		return r.consumeBody(call, class, "json", func(b []byte) Value {
+			return r.builtinJSON_parse(FunctionCall{Arguments: []Value{fetchBodyText(b)}})
+		})
+	}, nil, "json", nil, 0), true, true, true)
+	o._putProp("text", r.newNativeFunc(func(call FunctionCall) Value {
+		return r.consumeBody(call, class, "text", func(b []byte) Value {
+			return fetchBodyText(b)
+		})
+	}, nil, "text", nil, 0), true, true, true)
+}
+
+// fetchBodyText implements https://encoding.spec.whatwg.org/#utf-8-decode
+func fetchBodyText(b []byte) Value {
+	b = bytes.TrimPrefix(b, []byte("\xEF\xBB\xBF"))
+	if utf8.Valid(b) {
+		return newStringValue(string(b))
+	}
+	var sb strings.Builder
+	decodeUTF8(b, true, false, &sb)
+	return newStringValue(sb.String())
+}
+
+func (r *Runtime) newRequestObject(proto *Object) *requestObject {
+	req := &requestObject{redirect: "follow", signal: _null}
+	req.class = classObject
+	req.val = &Object{runtime: r, self: req}
+	req.extensible = true
+	req.prototype = proto
+	req.init()
+	return req
+}
+
+func normalizeMethod(method string) string {
+	switch upper := strings.ToUpper(method); upper {
+	case "DELETE", "GET", "HEAD", "OPTIONS", "POST", "PUT":
+		return upper
+	}
+	return method
+}
+
+// initRequest implements the Request constructor (https://fetch.spec.whatwg.org/#dom-request) for the supported
+// members of RequestInit: method, headers, body, redirect and signal.
+func (r *Runtime) initRequest(req *requestObject, input, initArg Value) {
+	var inputReq *requestObject
+	if o, ok := input.(*Object); ok {
+		inputReq, _ = o.self.(*requestObject)
+	}
+	if inputReq != nil {
+		req.method, req.url, req.redirect, req.signal = inputReq.method, inputReq.url, inputReq.redirect, inputReq.signal
+	} else {
+		s := input.toString().String()
+		u, ok := parseURL(s, nil)
+		if !ok {
+			panic(r.NewTypeError("Failed to parse URL from %s", s))
+		}
+		if u.username != "" || u.password != "" {
+			panic(r.NewTypeError("Request cannot be constructed from a URL that includes credentials: %s", s))
+		}
+		req.method = "GET"
+		req.url = u.serialize(false)
+	}
+	init := r.webIDLDictionary(initArg)
+	get := func(name string) Value {
+		if init == nil {
+			return _undefined
+		}
+		return nilSafe(init.self.getStr(unistring.NewFromString(name), nil))
+	}
+	if v := get("method"); v != _undefined {
+		method := r.toByteString(v)
+		if !isHTTPToken(method) {
+			panic(r.NewTypeError("'%s' is not a valid HTTP method", method))
+		}
+		switch strings.ToUpper(method) {
+		case "CONNECT", "TRACE", "TRACK":
+			panic(r.NewTypeError("'%s' HTTP method is unsupported", method))
+		}
+		req.method = normalizeMethod(method)
+	}
+	if v := get("redirect"); v != _undefined {
+		switch redirect := v.toString().String(); redirect {
+		case "follow", "error", "manual":
+			req.redirect = redirect
+		default:
+			panic(r.NewTypeError("The provided value '%s' is not a valid enum value of type RequestRedirect", redirect))
+		}
+	}
+	if v := get("signal"); v != _undefined {
+		if _, ok := v.(*Object); !ok && v != _null {
+			panic(r.NewTypeError("Failed to read the 'signal' property from 'RequestInit': The provided value is not of type 'AbortSignal'"))
+		}
+		req.signal = v
+	}
+
+	req.headers = r.newHeadersObject(r.global.HeadersPrototype)
+	if v := get("headers"); v != _undefined {
+		r.fillHeaders(req.headers, v)
+	} else if inputReq != nil {
+		req.headers.list = append([]fetchHeader(nil), inputReq.headers.list...)
+	}
+
+	body := get("body")
+	if body != _undefined && body != _null || inputReq != nil && inputReq.hasBody {
+		if req.method == "GET" || req.method == "HEAD" {
+			panic(r.NewTypeError("Request with GET/HEAD method cannot have body"))
+		}
+	}
+	if body != _undefined && body != _null {
+		var contentType string
+		req.body, contentType = r.extractBody(body)
+		req.hasBody = true
+		if contentType != "" && !req.headers.has("content-type") {
+			req.headers.list = append(req.headers.list, fetchHeader{"content-type", contentType})
+		}
+	} else if inputReq != nil && inputReq.hasBody {
+		if inputReq.used {
+			panic(r.NewTypeError("Cannot construct a Request with a Request object that has already been used"))
+		}
+		req.body, req.hasBody = inputReq.body, true
+		inputReq.used = true
+	}
+}
+
+func (r *Runtime) builtin_newRequest(args []Value, newTarget *Object) *Object {
+	if newTarget == nil {
+		panic(r.needNew("Request"))
+	}
+	if len(args) == 0 {
+		panic(r.NewTypeError("Failed to construct 'Request': 1 argument required, but only 0 present."))
+	}
+	req := r.newRequestObject(r.getPrototypeFromCtor(newTarget, r.global.Request, r.global.RequestPrototype))
+	r.initRequest(req, args[0], argAt(args, 1))
+	return req.val
+}
+
+func (r *Runtime) thisRequest(this Value, method string) *requestObject {
+	if o, ok := this.(*Object); ok {
+		if req, ok := o.self.(*requestObject); ok {
+			return req
+		}
+	}
+	panic(r.NewTypeError("Method Request.prototype.%s called on incompatible receiver %s", method, r.objectproto_toString(FunctionCall{This: this})))
+}
+
+func (r *Runtime) requestProto_getMethod(call FunctionCall) Value {
+	return newStringValue(r.thisRequest(call.This, "method").method)
+}
+
+func (r *Runtime) requestProto_getURL(call FunctionCall) Value {
+	return newStringValue(r.thisRequest(call.This, "url").url)
+}
+
+func (r *Runtime) requestProto_getHeaders(call FunctionCall) Value {
+	return r.thisRequest(call.This, "headers").headers.val
+}
+
+func (r *Runtime) requestProto_getRedirect(call FunctionCall) Value {
+	return asciiString(r.thisRequest(call.This, "redirect").redirect)
+}
+
+func (r *Runtime) requestProto_getSignal(call FunctionCall) Value {
+	return r.thisRequest(call.This, "signal").signal
+}
+
+func (r *Runtime) requestProto_clone(call FunctionCall) Value {
+	req := r.thisRequest(call.This, "clone")
+	if req.used {
+		panic(r.NewTypeError("Request body is already used"))
+	}
+	c := r.newRequestObject(r.global.RequestPrototype)
+	c.fetchBody = req.fetchBody
+	c.method, c.url, c.redirect, c.signal = req.method, req.url, req.redirect, req.signal
+	c.headers = r.newHeadersObject(r.global.HeadersPrototype)
+	c.headers.list = append([]fetchHeader(nil), req.headers.list...)
+	return c.val
+}
+
+func (r *Runtime) newResponseObject(proto *Object) *responseObject {
+	resp := &responseObject{typ: "default", status: 200}
+	resp.class = classObject
+	resp.val = &Object{runtime: r, self: resp}
+	resp.extensible = true
+	resp.prototype = proto
+	resp.init()
+	resp.headers = r.newHeadersObject(r.global.HeadersPrototype)
+	return resp
+}
+
+func isNullBodyStatus(status int) bool {
+	switch status {
+	case 101, 103, 204, 205, 304:
+		return true
+	}
+	return false
+}
+
+// initResponse implements https://fetch.spec.whatwg.org/#initialize-a-response
+func (r *Runtime) initResponse(resp *responseObject, initArg Value, body []byte, hasBody bool, contentType string) {
+	if init := r.webIDLDictionary(initArg); init != nil {
+		if v := nilSafe(init.self.getStr("status", nil)); v != _undefined {
+			resp.status = int(toUint16(v))
+			if resp.status < 200 || resp.status > 599 {
+				panic(r.newError(r.global.RangeError, "The status provided (%d) is outside the range [200, 599]", resp.status))
+			}
+		}
+		if v := nilSafe(init.self.getStr("statusText", nil)); v != _undefined {
+			resp.statusText = r.toByteString(v)
+			for _, c := range resp.statusText {
+				if c != '\t' && c != ' ' && (c < 0x21 || c == 0x7F) {
+					panic(r.NewTypeError("Invalid statusText"))
+				}
+			}
+		}
+		if v := nilSafe(init.self.getStr("headers", nil)); v != _undefined {
+			r.fillHeaders(resp.headers, v)
+		}
+	}
+	if hasBody {
+		if isNullBodyStatus(resp.status) {
+			panic(r.NewTypeError("Response with null body status cannot have body"))
+		}
+		resp.body, resp.hasBody = body, true
+		if contentType != "" && !resp.headers.has("content-type") {
+			resp.headers.list = append(resp.headers.list, fetchHeader{"content-type", contentType})
+		}
+	}
+}
+
+func (r *Runtime) builtin_newResponse(args []Value, newTarget *Object) *Object {
+	if newTarget == nil {
+		panic(r.needNew("Response"))
+	}
+	resp := r.newResponseObject(r.getPrototypeFromCtor(newTarget, r.global.Response, r.global.ResponsePrototype))
+	var body []byte
+	var contentType string
+	bodyArg := argAt(args, 0)
+	hasBody := bodyArg != _undefined && bodyArg != _null
+	if hasBody {
+		body, contentType = r.extractBody(bodyArg)
+	}
+	r.initResponse(resp, argAt(args, 1), body, hasBody, contentType)
+	return resp.val
+}
+
+func (r *Runtime) response_error(call FunctionCall) Value {
+	resp := r.newResponseObject(r.global.ResponsePrototype)
+	resp.typ = "error"
+	resp.status = 0
+	resp.headers.immutable = true
+	return resp.val
+}
+
+func (r *Runtime) response_redirect(call FunctionCall) Value {
+	s := call.Argument(0).toString().String()
+	u, ok := parseURL(s, nil)
+	if !ok {
+		panic(r.NewTypeError("Failed to parse URL from %s", s))
+	}
+	status := 302
+	if v := call.Argument(1); v != _undefined {
+		status = int(toUint16(v))
+	}
+	switch status {
+	case 301, 302, 303, 307, 308:
+	default:
+		panic(r.newError(r.global.RangeError, "Invalid status code %d", status))
+	}
+	resp := r.newResponseObject(r.global.ResponsePrototype)
+	resp.status = status
+	resp.headers.list = append(resp.headers.list, fetchHeader{"location", u.serialize(false)})
+	resp.headers.immutable = true
+	return resp.val
+}
+
+func (r *Runtime) response_json(call FunctionCall) Value {
+	s := r.builtinJSON_stringify(FunctionCall{Arguments: []Value{call.Argument(0)}})
+	if s == _undefined {
+		panic(r.NewTypeError("The data is not JSON serializable"))
+	}
+	resp := r.newResponseObject(r.global.ResponsePrototype)
+	r.initResponse(resp, call.Argument(1), []byte(s.String()), true, "application/json")
+	return resp.val
+}
+
+func (r *Runtime) thisResponse(this Value, method string) *responseObject {
+	if o, ok := this.(*Object); ok {
+		if resp, ok := o.self.(*responseObject); ok {
+			return resp
+		}
+	}
+	panic(r.NewTypeError("Method Response.prototype.%s called on incompatible receiver %s", method, r.objectproto_toString(FunctionCall{This: this})))
+}
+
+func (r *Runtime) responseProto_getType(call FunctionCall) Value {
+	return asciiString(r.thisResponse(call.This, "type").typ)
+}
+
+func (r *Runtime) responseProto_getURL(call FunctionCall) Value {
+	return newStringValue(r.thisResponse(call.This, "url").url)
+}
+
+func (r *Runtime) responseProto_getRedirected(call FunctionCall) Value {
+	return r.toBoolean(r.thisResponse(call.This, "redirected").redirected)
+}
+
+func (r *Runtime) responseProto_getStatus(call FunctionCall) Value {
+	return intToValue(int64(r.thisResponse(call.This, "status").status))
+}
+
+func (r *Runtime) responseProto_getOk(call FunctionCall) Value {
+	status := r.thisResponse(call.This, "ok").status
+	return r.toBoolean(status >= 200 && status <= 299)
+}
+
+func (r *Runtime) responseProto_getStatusText(call FunctionCall) Value {
+	return newStringValue(r.thisResponse(call.This, "statusText").statusText)
+}
+
+func (r *Runtime) responseProto_getHeaders(call FunctionCall) Value {
+	return r.thisResponse(call.This, "headers").headers.val
+}
+
+func (r *Runtime) responseProto_clone(call FunctionCall) Value {
+	resp := r.thisResponse(call.This, "clone")
+	if resp.used {
+		panic(r.NewTypeError("Response body is already used"))
+	}
+	c := r.newResponseObject(r.global.ResponsePrototype)
+	c.fetchBody = resp.fetchBody
+	c.typ, c.url, c.redirected, c.status, c.statusText = resp.typ, resp.url, resp.redirected, resp.status, resp.statusText
+	c.headers.list = append([]fetchHeader(nil), resp.headers.list...)
+	c.headers.immutable = resp.headers.immutable
+	return c.val
+}
+
+// errFetchRedirect is returned by CheckRedirect for the requests with the "error" redirect mode.
+var errFetchRedirect = errors.New("unexpected redirect")
+
+// fetchResult is the response received by doFetch.
+type fetchResult struct {
+	hresp      *http.Response
+	body       []byte
+	redirected bool
+}
+
+// newHTTPRequest converts the request into an HTTP request bound to ctx. It must be called on the Runtime
+// goroutine, the result can then be sent by doFetch from any goroutine.
+func newHTTPRequest(ctx gocontext.Context, req *requestObject) (*http.Request, error) {
+	var body io.Reader
+	if req.hasBody {
+		body = bytes.NewReader(req.body)
+	}
+	hr, err := http.NewRequestWithContext(ctx, req.method, req.url, body)
+	if err != nil {
+		return nil, err
+	}
+	for _, header := range req.headers.list {
+		if header.name == "host" {
+			hr.Host = latin1Bytes(header.value)
+			continue
+		}
+		hr.Header.Add(header.name, latin1Bytes(header.value))
+	}
+	return hr, nil
+}
+
+// doFetch sends the HTTP request and reads the whole response body. It does not use the Runtime, so it may run on
+// any goroutine.
+func doFetch(client http.Client, hr *http.Request, redirect string) (*fetchResult, error) {
+	res := &fetchResult{}
+	switch redirect {
+	case "error":
+		client.CheckRedirect = func(*http.Request, []*http.Request) error {
+			return errFetchRedirect
+		}
+	case "manual":
+		client.CheckRedirect = func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	default:
+		checkRedirect := client.CheckRedirect
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			if checkRedirect != nil {
+				if err := checkRedirect(req, via); err != nil {
+					return err
+				}
+			} else if len(via) >= 10 {
+				// the default policy of http.Client
+				return errors.New("stopped after 10 redirects")
+			}
+			res.redirected = true
+			return nil
+		}
+	}
+	hresp, err := client.Do(hr)
+	if err != nil {
+		return nil, err
+	}
+	defer hresp.Body.Close()
+	res.body, err = io.ReadAll(hresp.Body)
+	if err != nil {
+		return nil, err
+	}
+	res.hresp = hresp
+	return res, nil
+}
+
+func (r *Runtime) newFetchResponse(req *requestObject, res *fetchResult) *responseObject {
+	hresp := res.hresp
+	resp := r.newResponseObject(r.global.ResponsePrototype)
+	resp.typ = "basic"
+	resp.url = hresp.Request.URL.String()
+	resp.redirected = res.redirected
+	resp.status = hresp.StatusCode
+	resp.statusText = latin1String(strings.TrimPrefix(hresp.Status, strconv.Itoa(hresp.StatusCode)+" "))
+	names := make([]string, 0, len(hresp.Header))
+	for name := range hresp.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range hresp.Header[name] {
+			resp.headers.list = append(resp.headers.list, fetchHeader{strings.ToLower(name), latin1String(value)})
+		}
+	}
+	resp.headers.immutable = true
+	if req.method != "HEAD" && !isNullBodyStatus(resp.status) {
+		resp.body, resp.hasBody = res.body, true
+	}
+	return resp
+}
+
+// abortReason returns the reason of an aborted signal, or a new AbortError if it has none.
+func (r *Runtime) abortReason(signal *Object) Value {
+	reason := nilSafe(signal.self.getStr("reason", nil))
+	if reason == _undefined {
+		e := r.newError(r.global.Error, "This operation was aborted").(*Object)
+		e.self._putProp("name", asciiString("AbortError"), true, false, true)
+		reason = e
+	}
+	return reason
+}
+
+// onAbort calls cancel when the signal is aborted, if the signal is an event target. The returned function removes
+// the listener.
+func (r *Runtime) onAbort(signal *Object, cancel func()) (remove func()) {
+	if _, ok := assertCallable(signal.self.getStr("addEventListener", nil)); !ok {
+		return func() {}
+	}
+	listener := r.newNativeFunc(func(FunctionCall) Value {
+		cancel()
+		return _undefined
+	}, nil, "", nil, 0)
+	r.invoke(signal, "addEventListener", asciiString("abort"), listener)
+	return func() {
+		if _, ok := assertCallable(signal.self.getStr("removeEventListener", nil)); ok {
+			r.invoke(signal, "removeEventListener", asciiString("abort"), listener)
+		}
+	}
+}
+
+func (r *Runtime) builtin_fetch(call FunctionCall) Value {
+	pcap := r.newPromiseCapability(r.global.Promise)
+	pcap.try(func() {
+		req := r.newRequestObject(r.global.RequestPrototype)
+		r.initRequest(req, call.Argument(0), call.Argument(1))
+		signal, _ := req.signal.(*Object)
+		if signal != nil && nilSafe(signal.self.getStr("aborted", nil)).ToBoolean() {
+			panic(r.abortReason(signal))
+		}
+		ctx, cancel := gocontext.WithCancel(r.Context())
+		hr, err := newHTTPRequest(ctx, req)
+		if err != nil {
+			cancel()
+			panic(r.fetchError(err))
+		}
+		removeListener := func() {}
+		if signal != nil {
+			removeListener = r.onAbort(signal, cancel)
+		}
+		settle := func(res *fetchResult, err error) {
+			cancel()
+			removeListener()
+			if err != nil {
+				if signal != nil && nilSafe(signal.self.getStr("aborted", nil)).ToBoolean() {
+					pcap.reject(r.abortReason(signal))
+				} else {
+					pcap.reject(r.fetchError(err))
+				}
+				return
+			}
+			pcap.resolve(r.newFetchResponse(req, res).val)
+		}
+		client := *r.fetchClient
+		if l := r.loop; l != nil {
+			l.refs++
+			go func() {
+				res, err := doFetch(client, hr, req.redirect)
+				l.post(func() error {
+					l.refs--
+					settle(res, err)
+					return nil
+				})
+			}()
+			return
+		}
+		settle(doFetch(client, hr, req.redirect))
+	})
+	return pcap.promise
+}
+
+func (r *Runtime) fetchError(err error) *Object {
+	e := r.NewTypeError("fetch failed")
+	e.self._putProp("cause", r.NewGoError(err), true, false, true)
+	return e
+}
+
+func (r *Runtime) createHeadersProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("constructor", r.global.Headers, true, false, true)
+	o._putProp("append", r.newNativeFunc(r.headersProto_append, nil, "append", nil, 2), true, true, true)
+	o._putProp("delete", r.newNativeFunc(r.headersProto_delete, nil, "delete", nil, 1), true, true, true)
+	o._putProp("get", r.newNativeFunc(r.headersProto_get, nil, "get", nil, 1), true, true, true)
+	o._putProp("getSetCookie", r.newNativeFunc(r.headersProto_getSetCookie, nil, "getSetCookie", nil, 0), true, true, true)
+	o._putProp("has", r.newNativeFunc(r.headersProto_has, nil, "has", nil, 1), true, true, true)
+	o._putProp("set", r.newNativeFunc(r.headersProto_set, nil, "set", nil, 2), true, true, true)
+	o._putProp("forEach", r.newNativeFunc(r.headersProto_forEach, nil, "forEach", nil, 1), true, true, true)
+	o._putProp("keys", r.newNativeFunc(r.headersProto_keys, nil, "keys", nil, 0), true, true, true)
+	o._putProp("values", r.newNativeFunc(r.headersProto_values, nil, "values", nil, 0), true, true, true)
+	entries := r.newNativeFunc(r.headersProto_entries, nil, "entries", nil, 0)
+	o._putProp("entries", entries, true, true, true)
+	o._putSym(SymIterator, valueProp(entries, true, false, true))
+	o._putSym(SymToStringTag, valueProp(asciiString("Headers"), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) createHeaders(val *Object) objectImpl {
+	return r.newNativeConstructOnly(val, r.builtin_newHeaders, r.global.HeadersPrototype, "Headers", 0)
+}
+
+func (r *Runtime) createHeadersIterProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.IteratorPrototype, classObject)
+
+	o._putProp("next", r.newNativeFunc(r.headersIterProto_next, nil, "next", nil, 0), true, true, true)
+	o._putSym(SymToStringTag, valueProp(asciiString("Headers Iterator"), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) createRequestProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("constructor", r.global.Request, true, false, true)
+	r.putWebIDLAccessor(o, "method", r.requestProto_getMethod, nil)
+	r.putWebIDLAccessor(o, "url", r.requestProto_getURL, nil)
+	r.putWebIDLAccessor(o, "headers", r.requestProto_getHeaders, nil)
+	r.putWebIDLAccessor(o, "redirect", r.requestProto_getRedirect, nil)
+	r.putWebIDLAccessor(o, "signal", r.requestProto_getSignal, nil)
+	o._putProp("clone", r.newNativeFunc(r.requestProto_clone, nil, "clone", nil, 0), true, true, true)
+	r.putFetchBodyMethods(o, "Request")
+	o._putSym(SymToStringTag, valueProp(asciiString("Request"), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) createRequest(val *Object) objectImpl {
+	return r.newNativeConstructOnly(val, r.builtin_newRequest, r.global.RequestPrototype, "Request", 1)
+}
+
+func (r *Runtime) createResponseProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("constructor", r.global.Response, true, false, true)
+	r.putWebIDLAccessor(o, "type", r.responseProto_getType, nil)
+	r.putWebIDLAccessor(o, "url", r.responseProto_getURL, nil)
+	r.putWebIDLAccessor(o, "redirected", r.responseProto_getRedirected, nil)
+	r.putWebIDLAccessor(o, "status", r.responseProto_getStatus, nil)
+	r.putWebIDLAccessor(o, "ok", r.responseProto_getOk, nil)
+	r.putWebIDLAccessor(o, "statusText", r.responseProto_getStatusText, nil)
+	r.putWebIDLAccessor(o, "headers", r.responseProto_getHeaders, nil)
+	o._putProp("clone", r.newNativeFunc(r.responseProto_clone, nil, "clone", nil, 0), true, true, true)
+	r.putFetchBodyMethods(o, "Response")
+	o._putSym(SymToStringTag, valueProp(asciiString("Response"), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) createResponse(val *Object) objectImpl {
+	o := r.newNativeConstructOnly(val, r.builtin_newResponse, r.global.ResponsePrototype, "Response", 0)
+	o._putProp("error", r.newNativeFunc(r.response_error, nil, "error", nil, 0), true, true, true)
+	o._putProp("redirect", r.newNativeFunc(r.response_redirect, nil, "redirect", nil, 1), true, true, true)
+	o._putProp("json", r.newNativeFunc(r.response_json, nil, "json", nil, 1), true, true, true)
+
+	return o
+}
+
+// EnableFetch adds the fetch function and the Headers, Request and Response globals as described in
+// https://fetch.spec.whatwg.org/. The requests are performed by client (http.DefaultClient if nil). Calling it
+// again replaces the client.
+//
+// If the Runtime has an event loop (see NewLoop), the request is performed on a separate goroutine and the promise
+// is settled by the loop once the whole response body has been read, the loop keeps running until then. Without a
+// loop fetch() blocks until the response is read and returns a settled promise. In both cases the request is
+// cancelled when the context of the RunProgramContext call that has started it is done. The bodies are always
+// buffered, there is no 'body' stream. The CORS-related members of RequestInit (mode, credentials, etc.) are
+// ignored. The signal member accepts any object with the 'aborted' and 'reason' properties, such as an
+// AbortSignal. If it also has the addEventListener() method, its 'abort' event cancels the request in progress.
+func (r *Runtime) EnableFetch(client *http.Client) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	r.fetchClient = client
+	if r.global.Request != nil {
+		return
+	}
+	r.global.HeadersIteratorPrototype = r.newLazyObject(r.createHeadersIterProto)
+	r.global.HeadersPrototype = r.newLazyObject(r.createHeadersProto)
+	r.global.Headers = r.newLazyObject(r.createHeaders)
+	r.addToGlobal("Headers", r.global.Headers)
+
+	r.global.RequestPrototype = r.newLazyObject(r.createRequestProto)
+	r.global.Request = r.newLazyObject(r.createRequest)
+	r.addToGlobal("Request", r.global.Request)
+
+	r.global.ResponsePrototype = r.newLazyObject(r.createResponseProto)
+	r.global.Response = r.newLazyObject(r.createResponse)
+	r.addToGlobal("Response", r.global.Response)
+
+	r.addToGlobal("fetch", r.newNativeFunc(r.builtin_fetch, nil, "fetch", nil, 1))
+}
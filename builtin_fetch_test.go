@@ -0,0 +1,255 @@
+package goja
+
+import (
+	gocontext "context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func runAsyncTestScript(r *Runtime, script string, t *testing.T) {
+	t.Helper()
+	_, err := r.RunString(TESTLIB + `
+	var result;
+	(async function() {
+	` + script + `
+	})().then(() => { result = "ok" }, e => { result = e instanceof Error ? e.stack : String(e) });
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res := r.Get("result"); res == nil || res.String() != "ok" {
+		t.Fatal(res)
+	}
+}
+
+func TestFetchDisabled(t *testing.T) {
+	testScript(`typeof fetch + typeof Headers + typeof Request + typeof Response`, asciiString("undefinedundefinedundefinedundefined"), t)
+}
+
+func TestHeaders(t *testing.T) {
+	const SCRIPT = `
+	var h = new Headers({ "Content-Type": "text/plain", "X-B": " 1 " });
+	h.append("x-b", "2");
+	h.append("Set-Cookie", "a=1");
+	h.append("Set-Cookie", "b=2");
+	assert.sameValue(h.get("X-b"), "1, 2", "combined");
+	assert.sameValue(h.get("missing"), null);
+	assert(h.has("content-type"), "has");
+	assert(compareArray(h.getSetCookie(), ["a=1", "b=2"]), "getSetCookie");
+	var entries = [];
+	for (var [name, value] of h) {
+		entries.push(name + "=" + value);
+	}
+	assert(compareArray(entries, ["content-type=text/plain", "set-cookie=a=1", "set-cookie=b=2", "x-b=1, 2"]), "sorted: " + entries);
+	h.set("X-B", "3");
+	assert.sameValue(h.get("x-b"), "3", "set");
+	h.delete("X-B");
+	assert.sameValue(h.has("x-b"), false, "delete");
+	assert(compareArray(Array.from(new Headers([["a", "1"], ["b", "2"]]).keys()), ["a", "b"]), "from pairs");
+	assert(compareArray(Array.from(new Headers(new Headers({ a: "1" })).values()), ["1"]), "from Headers");
+	assert.throws(TypeError, () => h.append("bad name", "x"), "invalid name");
+	assert.throws(TypeError, () => h.append("x", "a\nb"), "invalid value");
+	assert.throws(TypeError, () => h.append("x", "Ā"), "not a ByteString");
+	assert.throws(TypeError, () => new Headers([["a"]]), "invalid pair");
+	assert.throws(TypeError, () => Response.error().headers.set("a", "b"), "immutable");
+	assert.sameValue(Object.prototype.toString.call(h), "[object Headers]");
+	`
+	r := New()
+	r.EnableFetch(nil)
+	r.testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestRequestResponse(t *testing.T) {
+	const SCRIPT = `
+	var req = new Request("HTTP://Example.com/a/../b?q", { method: "post", body: "hello", headers: { "X-A": "1" } });
+	assert.sameValue(req.url, "http://example.com/b?q");
+	assert.sameValue(req.method, "POST");
+	assert.sameValue(req.headers.get("content-type"), "text/plain;charset=UTF-8");
+	assert.sameValue(req.redirect, "follow");
+	var copy = new Request(req);
+	assert.sameValue(req.bodyUsed, true, "the body is transferred");
+	assert.sameValue(copy.headers.get("x-a"), "1");
+	assert.sameValue(await copy.clone().text(), "hello");
+	assert.sameValue(await copy.text(), "hello");
+	await copy.text().then(() => { throw new Error("should be rejected") }, e => assert(e instanceof TypeError, "used"));
+	assert.throws(TypeError, () => new Request("/relative"), "relative URL");
+	assert.throws(TypeError, () => new Request("http://u:p@example.com/"), "credentials");
+	assert.throws(TypeError, () => new Request("http://example.com/", { body: "x" }), "GET with body");
+	assert.throws(TypeError, () => new Request("http://example.com/", { method: "CONNECT" }), "forbidden method");
+	assert.sameValue(new Request("http://example.com/", { method: "patch" }).method, "patch", "not normalised");
+
+	var resp = new Response(new Uint8Array([0xEF, 0xBB, 0xBF, 0x68, 0x69, 0xFF]), { status: 201, statusText: "Created", headers: [["X-A", "1"]] });
+	assert.sameValue(resp.status, 201);
+	assert.sameValue(resp.ok, true);
+	assert.sameValue(resp.statusText, "Created");
+	assert.sameValue(resp.type, "default");
+	assert.sameValue(resp.headers.get("content-type"), null, "no content type for BufferSource");
+	assert.sameValue(await resp.text(), "hi�");
+	assert.sameValue(resp.bodyUsed, true);
+
+	resp = Response.json({ a: [1] }, { status: 404 });
+	assert.sameValue(resp.ok, false);
+	assert.sameValue(resp.headers.get("content-type"), "application/json");
+	assert.sameValue((await resp.json()).a[0], 1);
+	var buf = await new Response(new URLSearchParams({ a: "b c" })).arrayBuffer();
+	assert(buf instanceof ArrayBuffer, "arrayBuffer");
+	assert.sameValue(buf.byteLength, 5);
+	assert(compareArray(await new Response("ab").bytes(), [0x61, 0x62]), "bytes");
+	assert.sameValue(await new Response().text(), "", "no body");
+
+	resp = Response.redirect("http://example.com/x", 301);
+	assert.sameValue(resp.status, 301);
+	assert.sameValue(resp.headers.get("location"), "http://example.com/x");
+	assert.throws(RangeError, () => Response.redirect("http://example.com/", 200));
+	assert.sameValue(Response.error().type, "error");
+	assert.sameValue(Response.error().status, 0);
+	assert.throws(RangeError, () => new Response("", { status: 100 }));
+	assert.throws(TypeError, () => new Response("x", { status: 204 }), "null body status");
+	assert.throws(TypeError, () => Response.json(undefined));
+	assert.throws(TypeError, () => Response(), "needs new");
+	`
+	r := New()
+	r.EnableFetch(nil)
+	r.EnableURL()
+	runAsyncTestScript(r, SCRIPT, t)
+}
+
+func TestFetch(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/echo", func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		w.Header().Set("Content-Type", req.Header.Get("Content-Type"))
+		w.Header().Set("X-Method", req.Method)
+		w.Header().Set("X-Custom", req.Header.Get("X-Custom"))
+		w.Header().Add("Set-Cookie", "a=1")
+		w.Header().Add("Set-Cookie", "b=2")
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write(body)
+	})
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, req *http.Request) {
+		http.Redirect(w, req, "/echo", http.StatusFound)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	const SCRIPT = `
+	var resp = await fetch(base + "/echo", { method: "PUT", body: JSON.stringify({ a: 1 }), headers: { "Content-Type": "application/json", "X-Custom": "v" } });
+	assert.sameValue(resp.status, 202);
+	assert.sameValue(resp.statusText, "Accepted");
+	assert.sameValue(resp.ok, true);
+	assert.sameValue(resp.type, "basic");
+	assert.sameValue(resp.url, base + "/echo");
+	assert.sameValue(resp.redirected, false);
+	assert.sameValue(resp.headers.get("x-method"), "PUT");
+	assert.sameValue(resp.headers.get("x-custom"), "v");
+	assert(compareArray(resp.headers.getSetCookie(), ["a=1", "b=2"]), "set-cookie");
+	assert.throws(TypeError, () => resp.headers.set("a", "b"), "immutable");
+	assert.sameValue((await resp.json()).a, 1);
+
+	resp = await fetch(new Request(base + "/redirect"));
+	assert.sameValue(resp.redirected, true);
+	assert.sameValue(resp.url, base + "/echo");
+	assert.sameValue(resp.headers.get("x-method"), "GET");
+
+	resp = await fetch(base + "/redirect", { redirect: "manual" });
+	assert.sameValue(resp.status, 302);
+	assert.sameValue(resp.headers.get("location"), "/echo");
+
+	await fetch(base + "/redirect", { redirect: "error" }).then(() => { throw new Error("should be rejected") }, e => {
+		assert(e instanceof TypeError, "redirect error");
+		assert(e.cause instanceof Error, "cause");
+	});
+	await fetch("http://[::1").then(() => { throw new Error("should be rejected") }, e => assert(e instanceof TypeError, "invalid URL"));
+	var reason = new Error("aborted");
+	await fetch(base + "/echo", { signal: { aborted: true, reason: reason } }).then(() => { throw new Error("should be rejected") }, e => assert.sameValue(e, reason, "abort reason"));
+	await fetch(base + "/echo", { signal: { aborted: true } }).then(() => { throw new Error("should be rejected") }, e => assert.sameValue(e.name, "AbortError"));
+	`
+	r := New()
+	r.EnableFetch(srv.Client())
+	r.Set("base", srv.URL)
+	runAsyncTestScript(r, SCRIPT, t)
+}
+
+func TestFetchAsync(t *testing.T) {
+	release := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, req *http.Request) {
+		select {
+		case <-release:
+			_, _ = io.WriteString(w, "done")
+		case <-req.Context().Done():
+		}
+	})
+	mux.HandleFunc("/hang", func(w http.ResponseWriter, req *http.Request) {
+		<-req.Context().Done()
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	defer close(release)
+
+	r := New()
+	r.EnableFetch(srv.Client())
+	r.EnableEvents()
+	r.Set("base", srv.URL)
+	r.Set("release", func() {
+		release <- struct{}{}
+	})
+	loop := NewLoop(r)
+	err := loop.Run(func(r *Runtime) {
+		r.RunProgram(testLib())
+		_, err := r.RunString(`
+		var log = [];
+		var signal = new EventTarget();
+		signal.aborted = false;
+		var reason = new Error("stop");
+		fetch(base + "/hang", { signal }).then(() => log.push("not aborted"), e => log.push(e === reason ? "aborted" : String(e)));
+
+		fetch(base + "/slow").then(resp => resp.text()).then(text => {
+			log.push("fetched " + text);
+			signal.aborted = true;
+			signal.reason = reason;
+			signal.dispatchEvent(new Event("abort"));
+		});
+		setTimeout(() => {
+			log.push("timer");
+			release();
+		}, 0);
+		`)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res := r.Get("log").Export(); len(res.([]interface{})) != 3 {
+		t.Fatal(res)
+	} else if log := res.([]interface{}); log[0] != "timer" || log[1] != "fetched done" || log[2] != "aborted" {
+		t.Fatal(log)
+	}
+}
+
+func TestFetchContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		<-req.Context().Done()
+	}))
+	defer srv.Close()
+
+	r := New()
+	r.EnableFetch(srv.Client())
+	r.Set("base", srv.URL)
+	ctx, cancel := gocontext.WithTimeout(gocontext.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := r.RunStringContext(ctx, `fetch(base); for (;;) {}`)
+	if !errors.Is(err, gocontext.DeadlineExceeded) {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Fatalf("took %v", d)
+	}
+}
@@ -0,0 +1,480 @@
+package goja
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dop251/goja/unistring"
+	"golang.org/x/text/language"
+)
+
+// intlDefaultLocale is the default locale of the default LocaleProvider.
+const intlDefaultLocale = "en-US"
+
+var intlLanguageTagRe = regexp.MustCompile(`(?i)^[a-z]{2,3}(?:-[a-z]{4})?(?:-(?:[a-z]{2}|\d{3}))?(?:-(?:[a-z\d]{5,8}|\d[a-z\d]{3}))*(?:-[a-wyz\d](?:-[a-z\d]{2,8})+)*(?:-x(?:-[a-z\d]{1,8})+)?$`)
+
+// intlUnicodeTypeRe matches the type of a Unicode extension keyword, e.g. a collation or a numbering system.
+var intlUnicodeTypeRe = regexp.MustCompile(`(?i)^[a-z\d]{3,8}(?:-[a-z\d]{3,8})*$`)
+
+// intlLocale is a language tag split into the part without the extensions and the keywords of the Unicode
+// extension ("-u-").
+type intlLocale struct {
+	base     string
+	keywords map[string]string
+}
+
+// intlCanonicalizeTag validates and canonicalizes a language tag
+// (see https://tc39.es/ecma402/#sec-canonicalizeunicodelocaleid).
+func intlCanonicalizeTag(s string) (string, bool) {
+	if !intlLanguageTagRe.MatchString(s) {
+		return "", false
+	}
+	t, err := language.Parse(s)
+	if err == nil {
+		return t.String(), true
+	}
+	if _, ok := err.(language.ValueError); !ok {
+		return "", false
+	}
+	// well-formed but unknown to x/text, only normalise the case
+	parts := strings.Split(strings.ToLower(s), "-")
+	for i := 1; i < len(parts) && len(parts[i-1]) != 1; i++ {
+		switch p := parts[i]; {
+		case len(p) == 4 && p[0] > '9':
+			parts[i] = strings.ToUpper(p[:1]) + p[1:]
+		case len(p) == 2:
+			parts[i] = strings.ToUpper(p)
+		}
+	}
+	return strings.Join(parts, "-"), true
+}
+
+func parseIntlLocale(tag string) intlLocale {
+	l := intlLocale{base: tag}
+	if i := strings.Index(tag, "-x-"); i != -1 {
+		tag = tag[:i]
+		l.base = tag
+	}
+	i := strings.Index(tag, "-u-")
+	if i == -1 {
+		return l
+	}
+	l.base = tag[:i]
+	l.keywords = make(map[string]string)
+	key := ""
+	var value []string
+	flush := func() {
+		if key != "" {
+			if _, exists := l.keywords[key]; !exists {
+				if len(value) == 0 {
+					l.keywords[key] = "true"
+				} else {
+					l.keywords[key] = strings.Join(value, "-")
+				}
+			}
+		}
+	}
+	for _, p := range strings.Split(tag[i+3:], "-") {
+		if len(p) == 1 {
+			// the start of another extension
+			break
+		}
+		if len(p) == 2 {
+			flush()
+			key, value = p, nil
+		} else if key != "" {
+			value = append(value, p)
+		}
+	}
+	flush()
+	return l
+}
+
+func (l intlLocale) String() string {
+	if len(l.keywords) == 0 {
+		return l.base
+	}
+	keys := make([]string, 0, len(l.keywords))
+	for k := range l.keywords {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString(l.base)
+	b.WriteString("-u")
+	for _, k := range keys {
+		b.WriteByte('-')
+		b.WriteString(k)
+		if v := l.keywords[k]; v != "true" {
+			b.WriteByte('-')
+			b.WriteString(v)
+		}
+	}
+	return b.String()
+}
+
+// intlCanonicalizeLocaleList implements https://tc39.es/ecma402/#sec-canonicalizelocalelist
+func (r *Runtime) intlCanonicalizeLocaleList(locales Value) []string {
+	if locales == _undefined {
+		return nil
+	}
+	var items []Value
+	if s, ok := locales.(valueString); ok {
+		items = []Value{s}
+	} else {
+		o := locales.ToObject(r)
+		l := toLength(o.self.getStr("length", nil))
+		for k := int64(0); k < l; k++ {
+			if !o.self.hasPropertyIdx(valueInt(k)) {
+				continue
+			}
+			v := o.self.getIdx(valueInt(k), nil)
+			switch v.(type) {
+			case valueString, *Object:
+			default:
+				panic(r.NewTypeError("Language tag must be a string or an object"))
+			}
+			items = append(items, v)
+		}
+	}
+	var res []string
+	seen := make(map[string]bool)
+	for _, item := range items {
+		s := item.toString().String()
+		tag, ok := intlCanonicalizeTag(s)
+		if !ok {
+			panic(r.newError(r.global.RangeError, "Incorrect locale information provided: %s", s))
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			res = append(res, tag)
+		}
+	}
+	return res
+}
+
+// intlBestAvailableLocale implements https://tc39.es/ecma402/#sec-bestavailablelocale
+func intlBestAvailableLocale(available func(locale string) bool, locale string) string {
+	candidate := locale
+	for {
+		if available(candidate) {
+			return candidate
+		}
+		pos := strings.LastIndexByte(candidate, '-')
+		if pos == -1 {
+			return ""
+		}
+		if pos >= 2 && candidate[pos-2] == '-' {
+			pos -= 2
+		}
+		candidate = candidate[:pos]
+	}
+}
+
+// intlLookupLocale implements https://tc39.es/ecma402/#sec-lookupmatcher. The result's base is the matching available
+// locale, its keywords are the ones of the requested locale. If none of the requested locales is available the result
+// is the default locale of the LocaleProvider.
+func (r *Runtime) intlLookupLocale(available func(locale string) bool, requested []string) intlLocale {
+	for _, tag := range requested {
+		l := parseIntlLocale(tag)
+		if a := intlBestAvailableLocale(available, l.base); a != "" {
+			l.base = a
+			return l
+		}
+	}
+	return intlLocale{base: r.locales().DefaultLocale()}
+}
+
+// intlLanguageAvailable returns an availability function for the locales whose language satisfies has.
+func intlLanguageAvailable(has func(lang string) bool) func(locale string) bool {
+	return func(locale string) bool {
+		t, err := language.Parse(locale)
+		if err != nil {
+			return false
+		}
+		base, _ := t.Base()
+		return has(base.String())
+	}
+}
+
+func (r *Runtime) intlSupportedLocales(available func(locale string) bool, locales, options Value) Value {
+	requested := r.intlCanonicalizeLocaleList(locales)
+	if options != _undefined {
+		r.intlGetOption(options.ToObject(r), "localeMatcher", []string{"lookup", "best fit"}, "best fit")
+	}
+	res := make([]Value, 0, len(requested))
+	for _, tag := range requested {
+		if intlBestAvailableLocale(available, parseIntlLocale(tag).base) != "" {
+			res = append(res, newStringValue(tag))
+		}
+	}
+	return r.newArrayValues(res)
+}
+
+// intlGetOptionsObject implements https://tc39.es/ecma402/#sec-getoptionsobject
+func (r *Runtime) intlGetOptionsObject(options Value) *Object {
+	if options == _undefined {
+		return nil
+	}
+	if o, ok := options.(*Object); ok {
+		return o
+	}
+	panic(r.NewTypeError("Options must be an object"))
+}
+
+// intlGetOption implements https://tc39.es/ecma402/#sec-getoption for string options. If allowed is nil any value
+// is accepted. The result is def if the option is not present.
+func (r *Runtime) intlGetOption(opts *Object, name string, allowed []string, def string) string {
+	if opts == nil {
+		return def
+	}
+	v := opts.self.getStr(unistring.NewFromString(name), nil)
+	if v == nil || v == _undefined {
+		return def
+	}
+	s := v.toString().String()
+	if allowed == nil {
+		return s
+	}
+	for _, a := range allowed {
+		if s == a {
+			return s
+		}
+	}
+	panic(r.newError(r.global.RangeError, "Value %s out of range for %s options property %s", s, "Intl", name))
+}
+
+// intlGetBoolOption reads a boolean option. The second result is false if the option is not present.
+func (r *Runtime) intlGetBoolOption(opts *Object, name string) (bool, bool) {
+	if opts == nil {
+		return false, false
+	}
+	v := opts.self.getStr(unistring.NewFromString(name), nil)
+	if v == nil || v == _undefined {
+		return false, false
+	}
+	return v.ToBoolean(), true
+}
+
+// intlGetNumberOption implements https://tc39.es/ecma402/#sec-getnumberoption. The result is def if the option is
+// not present.
+func (r *Runtime) intlGetNumberOption(opts *Object, name string, min, max, def int) int {
+	if opts == nil {
+		return def
+	}
+	v := opts.self.getStr(unistring.NewFromString(name), nil)
+	if v == nil || v == _undefined {
+		return def
+	}
+	f := v.ToFloat()
+	if math.IsNaN(f) || f < float64(min) || f > float64(max) {
+		panic(r.newError(r.global.RangeError, "%s value is out of range", name))
+	}
+	return int(math.Floor(f))
+}
+
+// intlDigitOptions are the digit options of the number formatting
+// (see https://tc39.es/ecma402/#sec-setnfdigitoptions). Only the halfExpand rounding mode is supported.
+type intlDigitOptions struct {
+	minInt           int
+	minFrac, maxFrac int
+	// zero if the significant digits are not used
+	minSig, maxSig int
+}
+
+func (r *Runtime) intlGetDigitOptions(opts *Object, defMinFrac, defMaxFrac int) (o intlDigitOptions) {
+	o.minInt = r.intlGetNumberOption(opts, "minimumIntegerDigits", 1, 21, 1)
+	minFrac := r.intlGetNumberOption(opts, "minimumFractionDigits", 0, 100, -1)
+	maxFrac := r.intlGetNumberOption(opts, "maximumFractionDigits", 0, 100, -1)
+	minSig := r.intlGetNumberOption(opts, "minimumSignificantDigits", 1, 21, -1)
+	maxSig := r.intlGetNumberOption(opts, "maximumSignificantDigits", 1, 21, -1)
+	o.minFrac, o.maxFrac = defMinFrac, defMaxFrac
+	if minSig != -1 || maxSig != -1 {
+		o.minSig, o.maxSig = minSig, maxSig
+		if o.minSig == -1 {
+			o.minSig = 1
+		}
+		if o.maxSig == -1 {
+			o.maxSig = 21
+		} else if o.minSig > o.maxSig {
+			panic(r.newError(r.global.RangeError, "maximumSignificantDigits value is out of range"))
+		}
+	}
+	if minFrac != -1 || maxFrac != -1 {
+		switch {
+		case minFrac == -1:
+			o.minFrac, o.maxFrac = defMinFrac, maxFrac
+			if o.minFrac > maxFrac {
+				o.minFrac = maxFrac
+			}
+		case maxFrac == -1:
+			o.minFrac, o.maxFrac = minFrac, defMaxFrac
+			if o.maxFrac < minFrac {
+				o.maxFrac = minFrac
+			}
+		case minFrac > maxFrac:
+			panic(r.newError(r.global.RangeError, "maximumFractionDigits value is out of range"))
+		default:
+			o.minFrac, o.maxFrac = minFrac, maxFrac
+		}
+	}
+	return
+}
+
+func (o *intlDigitOptions) putResolved(res *Object) {
+	res.self._putProp("minimumIntegerDigits", intToValue(int64(o.minInt)), true, true, true)
+	res.self._putProp("minimumFractionDigits", intToValue(int64(o.minFrac)), true, true, true)
+	res.self._putProp("maximumFractionDigits", intToValue(int64(o.maxFrac)), true, true, true)
+	if o.maxSig != 0 {
+		res.self._putProp("minimumSignificantDigits", intToValue(int64(o.minSig)), true, true, true)
+		res.self._putProp("maximumSignificantDigits", intToValue(int64(o.maxSig)), true, true, true)
+	}
+}
+
+// intlDigits is a non-negative decimal number 0.d[0]d[1]... * 10^exp with scale visible fraction digits. The digits
+// have no trailing zeros.
+type intlDigits struct {
+	digits []byte
+	exp    int
+	scale  int
+}
+
+// round converts a finite non-negative number to digits. Like ICU it uses the shortest decimal representation of the
+// number rather than its exact binary value.
+func (o *intlDigitOptions) round(f float64) (d intlDigits) {
+	d.exp = 1
+	if f != 0 {
+		s := strconv.FormatFloat(f, 'e', -1, 64)
+		e := strings.IndexByte(s, 'e')
+		exp, _ := strconv.Atoi(s[e+1:])
+		d.exp = exp + 1
+		for i := 0; i < e; i++ {
+			if s[i] != '.' {
+				d.digits = append(d.digits, s[i]-'0')
+			}
+		}
+	}
+	keep := d.exp + o.maxFrac
+	if o.maxSig != 0 {
+		keep = o.maxSig
+	}
+	if keep < len(d.digits) {
+		roundUp := keep >= 0 && d.digits[keep] >= 5
+		if keep < 0 {
+			keep = 0
+		}
+		d.digits = d.digits[:keep]
+		if roundUp {
+			i := keep - 1
+			for ; i >= 0 && d.digits[i] == 9; i-- {
+			}
+			if i < 0 {
+				d.digits = append(d.digits[:0], 1)
+				d.exp++
+			} else {
+				d.digits[i]++
+				d.digits = d.digits[:i+1]
+			}
+		}
+	}
+	for len(d.digits) > 0 && d.digits[len(d.digits)-1] == 0 {
+		d.digits = d.digits[:len(d.digits)-1]
+	}
+	if len(d.digits) == 0 {
+		d.exp = 1
+	}
+	d.scale = len(d.digits) - d.exp
+	if o.maxSig != 0 {
+		if s := o.minSig - d.exp; s > d.scale {
+			d.scale = s
+		}
+	} else if o.minFrac > d.scale {
+		d.scale = o.minFrac
+	}
+	if d.scale < 0 {
+		d.scale = 0
+	}
+	return
+}
+
+// intlNumberPart is an element of the result of formatToParts() for a number.
+type intlNumberPart struct {
+	typ, value string
+}
+
+// parts formats the digits using at least minInt integer digits.
+func (d intlDigits) parts(minInt int, sym NumberSymbols) []intlNumberPart {
+	digit := func(i int) byte {
+		if i >= 0 && i < len(d.digits) {
+			return '0' + d.digits[i]
+		}
+		return '0'
+	}
+	intLen := d.exp
+	if intLen < minInt {
+		intLen = minInt
+	}
+	integer := make([]byte, intLen)
+	for i := range integer {
+		integer[i] = digit(d.exp - intLen + i)
+	}
+	var res []intlNumberPart
+	if len(integer) >= 3+sym.MinimumGroupingDigits {
+		first := len(integer) % 3
+		if first == 0 {
+			first = 3
+		}
+		res = append(res, intlNumberPart{"integer", string(integer[:first])})
+		for i := first; i < len(integer); i += 3 {
+			res = append(res, intlNumberPart{"group", sym.Group}, intlNumberPart{"integer", string(integer[i : i+3])})
+		}
+	} else {
+		res = append(res, intlNumberPart{"integer", string(integer)})
+	}
+	if d.scale > 0 {
+		fraction := make([]byte, d.scale)
+		for i := range fraction {
+			fraction[i] = digit(d.exp + i)
+		}
+		res = append(res, intlNumberPart{"decimal", sym.Decimal}, intlNumberPart{"fraction", string(fraction)})
+	}
+	return res
+}
+
+func (r *Runtime) intl_getCanonicalLocales(call FunctionCall) Value {
+	tags := r.intlCanonicalizeLocaleList(call.Argument(0))
+	res := make([]Value, len(tags))
+	for i, tag := range tags {
+		res[i] = newStringValue(tag)
+	}
+	return r.newArrayValues(res)
+}
+
+func (r *Runtime) createIntl(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("getCanonicalLocales", r.newNativeFunc(r.intl_getCanonicalLocales, nil, "getCanonicalLocales", nil, 1), true, false, true)
+	o._putProp("Collator", r.global.IntlCollator, true, false, true)
+	o._putProp("ListFormat", r.global.IntlListFormat, true, false, true)
+	o._putProp("PluralRules", r.global.IntlPluralRules, true, false, true)
+	o._putProp("RelativeTimeFormat", r.global.IntlRelativeTimeFormat, true, false, true)
+	o._putSym(SymToStringTag, valueProp(asciiString("Intl"), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) initIntl() {
+	r.global.IntlCollatorPrototype = r.newLazyObject(r.createIntlCollatorProto)
+	r.global.IntlCollator = r.newLazyObject(r.createIntlCollator)
+	r.global.IntlListFormatPrototype = r.newLazyObject(r.createIntlListFormatProto)
+	r.global.IntlListFormat = r.newLazyObject(r.createIntlListFormat)
+	r.global.IntlPluralRulesPrototype = r.newLazyObject(r.createIntlPluralRulesProto)
+	r.global.IntlPluralRules = r.newLazyObject(r.createIntlPluralRules)
+	r.global.IntlRelativeTimeFormatPrototype = r.newLazyObject(r.createIntlRelativeTimeFormatProto)
+	r.global.IntlRelativeTimeFormat = r.newLazyObject(r.createIntlRelativeTimeFormat)
+
+	r.addToGlobal("Intl", r.newLazyObject(r.createIntl))
+}
@@ -0,0 +1,233 @@
+package goja
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
+)
+
+type intlCollatorObject struct {
+	baseObject
+	locale            string
+	usage             string
+	sensitivity       string
+	ignorePunctuation bool
+	collation         string
+	numeric           bool
+
+	collator     intlCollator
+	boundCompare *Object
+}
+
+type intlCollator struct {
+	*collate.Collator
+	// x/text/collate takes diacritics into account at the case level, so for the "case" sensitivity they are
+	// removed before comparing
+	stripMarks bool
+}
+
+// intlCollatorLocales contains the locales supported by x/text/collate and intlCollatorCollations the collation types
+// available for each of them (apart from the default one).
+var intlCollatorLocales, intlCollatorCollations = func() (map[string]bool, map[string][]string) {
+	locales := make(map[string]bool)
+	collations := make(map[string][]string)
+	for _, t := range collate.Supported() {
+		l := parseIntlLocale(t.String())
+		locales[l.base] = true
+		if co := l.keywords["co"]; co != "" && co != "standard" && co != "search" {
+			collations[l.base] = append(collations[l.base], co)
+		}
+	}
+	return locales, collations
+}()
+
+func intlCollatorAvailable(locale string) bool {
+	return intlCollatorLocales[locale]
+}
+
+func (r *Runtime) newIntlCollatorObject(proto *Object) *intlCollatorObject {
+	o := &intlCollatorObject{}
+	o.class = classObject
+	o.val = &Object{runtime: r, self: o}
+	o.extensible = true
+	o.prototype = proto
+	o.init()
+	return o
+}
+
+// initCollator implements https://tc39.es/ecma402/#sec-initializecollator
+func (c *intlCollatorObject) initCollator(r *Runtime, locales, options Value) {
+	requested := r.intlCanonicalizeLocaleList(locales)
+	var opts *Object
+	if options != _undefined {
+		opts = options.ToObject(r)
+	}
+	c.usage = r.intlGetOption(opts, "usage", []string{"sort", "search"}, "sort")
+	r.intlGetOption(opts, "localeMatcher", []string{"lookup", "best fit"}, "best fit")
+	collation := r.intlGetOption(opts, "collation", nil, "")
+	if collation != "" && !intlUnicodeTypeRe.MatchString(collation) {
+		panic(r.newError(r.global.RangeError, "Invalid collation: %s", collation))
+	}
+	numeric, hasNumeric := r.intlGetBoolOption(opts, "numeric")
+	r.intlGetOption(opts, "caseFirst", []string{"upper", "lower", "false"}, "")
+
+	l := r.intlLookupLocale(intlCollatorAvailable, requested)
+	resolved := intlLocale{base: l.base, keywords: make(map[string]string)}
+
+	c.collation = "default"
+	isSupported := func(co string) bool {
+		for _, s := range intlCollatorCollations[l.base] {
+			if s == co {
+				return true
+			}
+		}
+		return false
+	}
+	if co, ok := l.keywords["co"]; ok && isSupported(co) {
+		c.collation = co
+		resolved.keywords["co"] = co
+	}
+	if collation = strings.ToLower(collation); collation != "" && collation != c.collation && isSupported(collation) {
+		c.collation = collation
+		delete(resolved.keywords, "co")
+	}
+
+	if kn, ok := l.keywords["kn"]; ok && (kn == "true" || kn == "false") {
+		c.numeric = kn == "true"
+		resolved.keywords["kn"] = kn
+	}
+	if hasNumeric && numeric != c.numeric {
+		c.numeric = numeric
+		delete(resolved.keywords, "kn")
+	}
+	c.locale = resolved.String()
+
+	c.sensitivity = r.intlGetOption(opts, "sensitivity", []string{"base", "accent", "case", "variant"}, "variant")
+	c.ignorePunctuation, _ = r.intlGetBoolOption(opts, "ignorePunctuation")
+
+	c.collator = newIntlCollator(l.base, c.collation, c.sensitivity, c.ignorePunctuation, c.numeric)
+}
+
+func newIntlCollator(locale, collation, sensitivity string, ignorePunctuation, numeric bool) intlCollator {
+	tag := language.Make(locale)
+	set := func(key, value string) {
+		if t, err := tag.SetTypeForKey(key, value); err == nil {
+			tag = t
+		}
+	}
+	if collation != "default" {
+		set("co", collation)
+	}
+	if ignorePunctuation {
+		set("ka", "shifted")
+	}
+	if numeric {
+		set("kn", "true")
+	}
+	switch sensitivity {
+	case "base":
+		set("ks", "level1")
+	case "accent":
+		set("ks", "level2")
+	case "case":
+		set("ks", "level1")
+		set("kc", "true")
+	}
+	return intlCollator{Collator: collate.New(tag), stripMarks: sensitivity == "case"}
+}
+
+func (c intlCollator) compare(x, y valueString) int {
+	a, b := norm.NFD.String(x.String()), norm.NFD.String(y.String())
+	if c.stripMarks {
+		a, b = stripNonSpacingMarks(a), stripNonSpacingMarks(b)
+	}
+	return c.CompareString(a, b)
+}
+
+func stripNonSpacingMarks(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.Is(unicode.Mn, r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+func (r *Runtime) builtin_newIntlCollator(args []Value, newTarget *Object) *Object {
+	if newTarget == nil {
+		newTarget = r.global.IntlCollator
+	}
+	proto := r.getPrototypeFromCtor(newTarget, r.global.IntlCollator, r.global.IntlCollatorPrototype)
+	c := r.newIntlCollatorObject(proto)
+	var locales, options Value = _undefined, _undefined
+	if len(args) > 0 {
+		locales = args[0]
+	}
+	if len(args) > 1 {
+		options = args[1]
+	}
+	c.initCollator(r, locales, options)
+	return c.val
+}
+
+func (r *Runtime) intlCollator_supportedLocalesOf(call FunctionCall) Value {
+	return r.intlSupportedLocales(intlCollatorAvailable, call.Argument(0), call.Argument(1))
+}
+
+func (r *Runtime) thisIntlCollator(this Value, method string) *intlCollatorObject {
+	if o, ok := this.(*Object); ok {
+		if c, ok := o.self.(*intlCollatorObject); ok {
+			return c
+		}
+	}
+	panic(r.NewTypeError("Method Intl.Collator.prototype.%s called on incompatible receiver %s", method, r.objectproto_toString(FunctionCall{This: this})))
+}
+
+func (r *Runtime) intlCollatorProto_getCompare(call FunctionCall) Value {
+	c := r.thisIntlCollator(call.This, "compare")
+	if c.boundCompare == nil {
+		c.boundCompare = r.newNativeFunc(func(call FunctionCall) Value {
+			return intToValue(int64(c.collator.compare(call.Argument(0).toString(), call.Argument(1).toString())))
+		}, nil, "", nil, 2)
+	}
+	return c.boundCompare
+}
+
+func (r *Runtime) intlCollatorProto_resolvedOptions(call FunctionCall) Value {
+	c := r.thisIntlCollator(call.This, "resolvedOptions")
+	o := r.NewObject()
+	o.self._putProp("locale", newStringValue(c.locale), true, true, true)
+	o.self._putProp("usage", asciiString(c.usage), true, true, true)
+	o.self._putProp("sensitivity", asciiString(c.sensitivity), true, true, true)
+	o.self._putProp("ignorePunctuation", r.toBoolean(c.ignorePunctuation), true, true, true)
+	o.self._putProp("collation", asciiString(c.collation), true, true, true)
+	o.self._putProp("numeric", r.toBoolean(c.numeric), true, true, true)
+	return o
+}
+
+func (r *Runtime) createIntlCollatorProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("constructor", r.global.IntlCollator, true, false, true)
+	o.setOwnStr("compare", &valueProperty{
+		getterFunc:   r.newNativeFunc(r.intlCollatorProto_getCompare, nil, "get compare", nil, 0),
+		accessor:     true,
+		configurable: true,
+	}, true)
+	o._putProp("resolvedOptions", r.newNativeFunc(r.intlCollatorProto_resolvedOptions, nil, "resolvedOptions", nil, 0), true, false, true)
+	o._putSym(SymToStringTag, valueProp(asciiString("Intl.Collator"), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) createIntlCollator(val *Object) objectImpl {
+	o := r.newNativeFuncAndConstruct(val, func(call FunctionCall) Value {
+		return r.builtin_newIntlCollator(call.Arguments, nil)
+	}, r.builtin_newIntlCollator, r.global.IntlCollatorPrototype, "Collator", intToValue(0))
+	o._putProp("supportedLocalesOf", r.newNativeFunc(r.intlCollator_supportedLocalesOf, nil, "supportedLocalesOf", nil, 1), true, false, true)
+
+	return o
+}
@@ -0,0 +1,213 @@
+package goja
+
+import "golang.org/x/text/language"
+
+type intlListFormatObject struct {
+	baseObject
+	locale string
+	typ    string
+	style  string
+
+	patterns intlListPatterns
+}
+
+// intlListPatterns are the separators inserted between the list elements: pair is used for lists of two elements,
+// end between the last two elements of longer lists and middle between the others.
+type intlListPatterns struct {
+	middle, pair, end string
+}
+
+var intlListTypes = []string{"conjunction", "disjunction", "unit"}
+var intlListStyles = []string{"long", "short", "narrow"}
+
+// intlListFormatData contains the CLDR list patterns indexed by type and style (in the order of intlListTypes and
+// intlListStyles).
+var intlListFormatData = map[string][3][3]intlListPatterns{
+	"de": {
+		{{", ", " und ", " und "}, {", ", " und ", " und "}, {", ", " und ", " und "}},
+		{{", ", " oder ", " oder "}, {", ", " oder ", " oder "}, {", ", " oder ", " oder "}},
+		{{", ", ", ", " und "}, {", ", ", ", " und "}, {" ", " ", " "}},
+	},
+	"en": {
+		{{", ", " and ", ", and "}, {", ", " & ", ", & "}, {", ", ", ", ", "}},
+		{{", ", " or ", ", or "}, {", ", " or ", ", or "}, {", ", " or ", ", or "}},
+		{{", ", ", ", ", "}, {", ", ", ", ", "}, {" ", " ", " "}},
+	},
+	"es": {
+		{{", ", " y ", " y "}, {", ", " y ", " y "}, {", ", " y ", " y "}},
+		{{", ", " o ", " o "}, {", ", " o ", " o "}, {", ", " o ", " o "}},
+		{{", ", " y ", " y "}, {", ", " y ", " y "}, {" ", " ", " "}},
+	},
+	"fr": {
+		{{", ", " et ", " et "}, {", ", " et ", " et "}, {", ", " et ", " et "}},
+		{{", ", " ou ", " ou "}, {", ", " ou ", " ou "}, {", ", " ou ", " ou "}},
+		{{", ", " et ", " et "}, {", ", " et ", " et "}, {" ", " ", " "}},
+	},
+	"ja": {
+		{{"、", "、", "、"}, {"、", "、", "、"}, {"、", "、", "、"}},
+		{{"、", "または", "、または"}, {"、", "または", "、または"}, {"、", "または", "、または"}},
+		{{" ", " ", " "}, {" ", " ", " "}, {" ", " ", " "}},
+	},
+	"zh": {
+		{{"、", "和", "和"}, {"、", "和", "和"}, {"、", "和", "和"}},
+		{{"、", "或", "或"}, {"、", "或", "或"}, {"、", "或", "或"}},
+		{{"", "", ""}, {"", "", ""}, {"", "", ""}},
+	},
+}
+
+var intlListFormatAvailable = intlLanguageAvailable(func(lang string) bool {
+	_, ok := intlListFormatData[lang]
+	return ok
+})
+
+func intlListFormatPatterns(locale, typ, style string) intlListPatterns {
+	base, _ := language.Make(locale).Base()
+	data, ok := intlListFormatData[base.String()]
+	if !ok {
+		data = intlListFormatData["en"]
+	}
+	var t, s int
+	for i, v := range intlListTypes {
+		if v == typ {
+			t = i
+		}
+	}
+	for i, v := range intlListStyles {
+		if v == style {
+			s = i
+		}
+	}
+	return data[t][s]
+}
+
+func (r *Runtime) builtin_newIntlListFormat(args []Value, newTarget *Object) *Object {
+	if newTarget == nil {
+		panic(r.needNew("Intl.ListFormat"))
+	}
+	proto := r.getPrototypeFromCtor(newTarget, r.global.IntlListFormat, r.global.IntlListFormatPrototype)
+	f := &intlListFormatObject{}
+	f.class = classObject
+	f.val = &Object{runtime: r, self: f}
+	f.extensible = true
+	f.prototype = proto
+	f.init()
+
+	var locales, options Value = _undefined, _undefined
+	if len(args) > 0 {
+		locales = args[0]
+	}
+	if len(args) > 1 {
+		options = args[1]
+	}
+	requested := r.intlCanonicalizeLocaleList(locales)
+	opts := r.intlGetOptionsObject(options)
+	r.intlGetOption(opts, "localeMatcher", []string{"lookup", "best fit"}, "best fit")
+	f.locale = r.intlLookupLocale(intlListFormatAvailable, requested).base
+	f.typ = r.intlGetOption(opts, "type", intlListTypes, "conjunction")
+	f.style = r.intlGetOption(opts, "style", intlListStyles, "long")
+	f.patterns = intlListFormatPatterns(f.locale, f.typ, f.style)
+	return f.val
+}
+
+func (r *Runtime) intlListFormat_supportedLocalesOf(call FunctionCall) Value {
+	return r.intlSupportedLocales(intlListFormatAvailable, call.Argument(0), call.Argument(1))
+}
+
+func (r *Runtime) thisIntlListFormat(this Value, method string) *intlListFormatObject {
+	if o, ok := this.(*Object); ok {
+		if f, ok := o.self.(*intlListFormatObject); ok {
+			return f
+		}
+	}
+	panic(r.NewTypeError("Method Intl.ListFormat.prototype.%s called on incompatible receiver %s", method, r.objectproto_toString(FunctionCall{This: this})))
+}
+
+// intlStringListFromIterable implements https://tc39.es/ecma402/#sec-createstringlistfromiterable
+func (r *Runtime) intlStringListFromIterable(iterable Value) []valueString {
+	if iterable == _undefined {
+		return nil
+	}
+	var list []valueString
+	r.getIterator(iterable, nil).iterate(func(item Value) {
+		s, ok := item.(valueString)
+		if !ok {
+			panic(r.NewTypeError("Iterable yielded %s which is not a string", item.String()))
+		}
+		list = append(list, s)
+	})
+	return list
+}
+
+// parts implements https://tc39.es/ecma402/#sec-createpartsfromlist. The parts alternate between elements (even
+// indexes) and literals (odd indexes).
+func (f *intlListFormatObject) parts(list []valueString) []valueString {
+	if len(list) == 0 {
+		return nil
+	}
+	res := make([]valueString, 0, 2*len(list)-1)
+	res = append(res, list[0])
+	for i := 1; i < len(list); i++ {
+		sep := f.patterns.middle
+		if len(list) == 2 {
+			sep = f.patterns.pair
+		} else if i == len(list)-1 {
+			sep = f.patterns.end
+		}
+		res = append(res, newStringValue(sep), list[i])
+	}
+	return res
+}
+
+func (r *Runtime) intlListFormatProto_format(call FunctionCall) Value {
+	f := r.thisIntlListFormat(call.This, "format")
+	b := limitedStringBuilder{r: r}
+	for _, p := range f.parts(r.intlStringListFromIterable(call.Argument(0))) {
+		b.WriteString(p)
+	}
+	return b.String()
+}
+
+func (r *Runtime) intlListFormatProto_formatToParts(call FunctionCall) Value {
+	f := r.thisIntlListFormat(call.This, "formatToParts")
+	parts := f.parts(r.intlStringListFromIterable(call.Argument(0)))
+	res := make([]Value, len(parts))
+	for i, p := range parts {
+		o := r.NewObject()
+		if i%2 == 0 {
+			o.self._putProp("type", asciiString("element"), true, true, true)
+		} else {
+			o.self._putProp("type", asciiString("literal"), true, true, true)
+		}
+		o.self._putProp("value", p, true, true, true)
+		res[i] = o
+	}
+	return r.newArrayValues(res)
+}
+
+func (r *Runtime) intlListFormatProto_resolvedOptions(call FunctionCall) Value {
+	f := r.thisIntlListFormat(call.This, "resolvedOptions")
+	o := r.NewObject()
+	o.self._putProp("locale", newStringValue(f.locale), true, true, true)
+	o.self._putProp("type", asciiString(f.typ), true, true, true)
+	o.self._putProp("style", asciiString(f.style), true, true, true)
+	return o
+}
+
+func (r *Runtime) createIntlListFormatProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("constructor", r.global.IntlListFormat, true, false, true)
+	o._putProp("format", r.newNativeFunc(r.intlListFormatProto_format, nil, "format", nil, 1), true, false, true)
+	o._putProp("formatToParts", r.newNativeFunc(r.intlListFormatProto_formatToParts, nil, "formatToParts", nil, 1), true, false, true)
+	o._putProp("resolvedOptions", r.newNativeFunc(r.intlListFormatProto_resolvedOptions, nil, "resolvedOptions", nil, 0), true, false, true)
+	o._putSym(SymToStringTag, valueProp(asciiString("Intl.ListFormat"), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) createIntlListFormat(val *Object) objectImpl {
+	o := r.newNativeConstructOnly(val, r.builtin_newIntlListFormat, r.global.IntlListFormatPrototype, "ListFormat", 0)
+	o._putProp("supportedLocalesOf", r.newNativeFunc(r.intlListFormat_supportedLocalesOf, nil, "supportedLocalesOf", nil, 1), true, false, true)
+
+	return o
+}
@@ -0,0 +1,196 @@
+package goja
+
+import (
+	"math"
+
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+)
+
+type intlPluralRulesObject struct {
+	baseObject
+	locale  string
+	tag     language.Tag
+	ordinal bool
+	digits  intlDigitOptions
+}
+
+var intlPluralForms = [...]struct {
+	form plural.Form
+	name string
+}{
+	{plural.Zero, "zero"},
+	{plural.One, "one"},
+	{plural.Two, "two"},
+	{plural.Few, "few"},
+	{plural.Many, "many"},
+	{plural.Other, "other"},
+}
+
+func intlPluralRulesAvailable(locale string) bool {
+	_, err := language.Parse(locale)
+	return err == nil
+}
+
+func intlPluralFormName(f plural.Form) string {
+	for _, pf := range intlPluralForms {
+		if pf.form == f {
+			return pf.name
+		}
+	}
+	return "other"
+}
+
+func (p *intlPluralRulesObject) rules() *plural.Rules {
+	if p.ordinal {
+		return plural.Ordinal
+	}
+	return plural.Cardinal
+}
+
+// resolve implements https://tc39.es/ecma402/#sec-resolveplural
+func (p *intlPluralRulesObject) resolve(n float64) string {
+	if math.IsNaN(n) || math.IsInf(n, 0) {
+		return "other"
+	}
+	d := p.digits.round(math.Abs(n))
+	return intlPluralFormName(p.rules().MatchDigits(p.tag, d.digits, d.exp, d.scale))
+}
+
+// categories returns the plural categories used by the locale. x/text does not expose them directly so they are
+// determined by matching a set of numbers that covers all the CLDR rules.
+func (p *intlPluralRulesObject) categories() []string {
+	var found [len(intlPluralForms)]bool
+	rules := p.rules()
+	match := func(digits []byte, exp, scale int) {
+		f := rules.MatchDigits(p.tag, digits, exp, scale)
+		for i, pf := range intlPluralForms {
+			if pf.form == f {
+				found[i] = true
+			}
+		}
+	}
+	for n := 0; n < 1000; n++ {
+		digits := []byte{byte(n / 100), byte(n / 10 % 10), byte(n % 10)}
+		match(digits, 3, 0)
+		match(digits, 2, 1)
+		match(digits, 1, 2)
+	}
+	for _, n := range []byte{1, 2, 5} {
+		match([]byte{n}, 7, 0)
+	}
+	var res []string
+	for i, f := range found {
+		if f {
+			res = append(res, intlPluralForms[i].name)
+		}
+	}
+	return res
+}
+
+func (r *Runtime) builtin_newIntlPluralRules(args []Value, newTarget *Object) *Object {
+	if newTarget == nil {
+		panic(r.needNew("Intl.PluralRules"))
+	}
+	proto := r.getPrototypeFromCtor(newTarget, r.global.IntlPluralRules, r.global.IntlPluralRulesPrototype)
+	p := &intlPluralRulesObject{}
+	p.class = classObject
+	p.val = &Object{runtime: r, self: p}
+	p.extensible = true
+	p.prototype = proto
+	p.init()
+
+	var locales, options Value = _undefined, _undefined
+	if len(args) > 0 {
+		locales = args[0]
+	}
+	if len(args) > 1 {
+		options = args[1]
+	}
+	requested := r.intlCanonicalizeLocaleList(locales)
+	var opts *Object
+	if options != _undefined {
+		opts = options.ToObject(r)
+	}
+	r.intlGetOption(opts, "localeMatcher", []string{"lookup", "best fit"}, "best fit")
+	p.ordinal = r.intlGetOption(opts, "type", []string{"cardinal", "ordinal"}, "cardinal") == "ordinal"
+	p.digits = r.intlGetDigitOptions(opts, 0, 3)
+	p.locale = r.intlLookupLocale(intlPluralRulesAvailable, requested).base
+	p.tag = language.Make(p.locale)
+	return p.val
+}
+
+func (r *Runtime) intlPluralRules_supportedLocalesOf(call FunctionCall) Value {
+	return r.intlSupportedLocales(intlPluralRulesAvailable, call.Argument(0), call.Argument(1))
+}
+
+func (r *Runtime) thisIntlPluralRules(this Value, method string) *intlPluralRulesObject {
+	if o, ok := this.(*Object); ok {
+		if p, ok := o.self.(*intlPluralRulesObject); ok {
+			return p
+		}
+	}
+	panic(r.NewTypeError("Method Intl.PluralRules.prototype.%s called on incompatible receiver %s", method, r.objectproto_toString(FunctionCall{This: this})))
+}
+
+func (r *Runtime) intlPluralRulesProto_select(call FunctionCall) Value {
+	p := r.thisIntlPluralRules(call.This, "select")
+	return asciiString(p.resolve(call.Argument(0).ToFloat()))
+}
+
+// selectRange implements https://tc39.es/ecma402/#sec-intl.pluralrules.prototype.selectrange. x/text has no plural
+// range data, so the category of the end of the range is used which is what CLDR specifies for most locales.
+func (r *Runtime) intlPluralRulesProto_selectRange(call FunctionCall) Value {
+	p := r.thisIntlPluralRules(call.This, "selectRange")
+	start, end := call.Argument(0), call.Argument(1)
+	if start == _undefined || end == _undefined {
+		panic(r.NewTypeError("start and end are required"))
+	}
+	x, y := start.ToFloat(), end.ToFloat()
+	if math.IsNaN(x) || math.IsNaN(y) {
+		panic(r.newError(r.global.RangeError, "Invalid range"))
+	}
+	return asciiString(p.resolve(y))
+}
+
+func (r *Runtime) intlPluralRulesProto_resolvedOptions(call FunctionCall) Value {
+	p := r.thisIntlPluralRules(call.This, "resolvedOptions")
+	o := r.NewObject()
+	o.self._putProp("locale", newStringValue(p.locale), true, true, true)
+	if p.ordinal {
+		o.self._putProp("type", asciiString("ordinal"), true, true, true)
+	} else {
+		o.self._putProp("type", asciiString("cardinal"), true, true, true)
+	}
+	p.digits.putResolved(o)
+	categories := p.categories()
+	values := make([]Value, len(categories))
+	for i, c := range categories {
+		values[i] = asciiString(c)
+	}
+	o.self._putProp("pluralCategories", r.newArrayValues(values), true, true, true)
+	o.self._putProp("roundingIncrement", intToValue(1), true, true, true)
+	o.self._putProp("roundingMode", asciiString("halfExpand"), true, true, true)
+	o.self._putProp("roundingPriority", asciiString("auto"), true, true, true)
+	o.self._putProp("trailingZeroDisplay", asciiString("auto"), true, true, true)
+	return o
+}
+
+func (r *Runtime) createIntlPluralRulesProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("constructor", r.global.IntlPluralRules, true, false, true)
+	o._putProp("select", r.newNativeFunc(r.intlPluralRulesProto_select, nil, "select", nil, 1), true, false, true)
+	o._putProp("selectRange", r.newNativeFunc(r.intlPluralRulesProto_selectRange, nil, "selectRange", nil, 2), true, false, true)
+	o._putProp("resolvedOptions", r.newNativeFunc(r.intlPluralRulesProto_resolvedOptions, nil, "resolvedOptions", nil, 0), true, false, true)
+	o._putSym(SymToStringTag, valueProp(asciiString("Intl.PluralRules"), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) createIntlPluralRules(val *Object) objectImpl {
+	o := r.newNativeConstructOnly(val, r.builtin_newIntlPluralRules, r.global.IntlPluralRulesPrototype, "PluralRules", 0)
+	o._putProp("supportedLocalesOf", r.newNativeFunc(r.intlPluralRules_supportedLocalesOf, nil, "supportedLocalesOf", nil, 1), true, false, true)
+
+	return o
+}
@@ -0,0 +1,333 @@
+package goja
+
+import (
+	"math"
+	"strings"
+
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+)
+
+type intlRelativeTimeFormatObject struct {
+	baseObject
+	locale  string
+	style   string
+	numeric string
+
+	tag           language.Tag
+	units         *[8]intlRelativeTimeUnit
+	day2          [2]string
+	numberSymbols NumberSymbols
+}
+
+// intlRelativeTimeUnit contains the CLDR patterns for a unit. The future and past patterns are for the "one" and
+// "other" plural categories, relative contains the names for -1, 0 and 1 (if any).
+type intlRelativeTimeUnit struct {
+	future, past [2]string
+	relative     [3]string
+}
+
+var intlRelativeTimeUnits = []string{"second", "minute", "hour", "day", "week", "month", "quarter", "year"}
+
+// intlRelativeTimeFormatData contains the long and the short styles of each locale, the narrow style is the same as
+// the short one.
+var intlRelativeTimeFormatData = map[string][2][8]intlRelativeTimeUnit{
+	"de": {{
+		{[2]string{"in {0} Sekunde", "in {0} Sekunden"}, [2]string{"vor {0} Sekunde", "vor {0} Sekunden"}, [3]string{"", "jetzt", ""}},
+		{[2]string{"in {0} Minute", "in {0} Minuten"}, [2]string{"vor {0} Minute", "vor {0} Minuten"}, [3]string{"", "in dieser Minute", ""}},
+		{[2]string{"in {0} Stunde", "in {0} Stunden"}, [2]string{"vor {0} Stunde", "vor {0} Stunden"}, [3]string{"", "in dieser Stunde", ""}},
+		{[2]string{"in {0} Tag", "in {0} Tagen"}, [2]string{"vor {0} Tag", "vor {0} Tagen"}, [3]string{"gestern", "heute", "morgen"}},
+		{[2]string{"in {0} Woche", "in {0} Wochen"}, [2]string{"vor {0} Woche", "vor {0} Wochen"}, [3]string{"letzte Woche", "diese Woche", "nächste Woche"}},
+		{[2]string{"in {0} Monat", "in {0} Monaten"}, [2]string{"vor {0} Monat", "vor {0} Monaten"}, [3]string{"letzten Monat", "diesen Monat", "nächsten Monat"}},
+		{[2]string{"in {0} Quartal", "in {0} Quartalen"}, [2]string{"vor {0} Quartal", "vor {0} Quartalen"}, [3]string{"letztes Quartal", "dieses Quartal", "nächstes Quartal"}},
+		{[2]string{"in {0} Jahr", "in {0} Jahren"}, [2]string{"vor {0} Jahr", "vor {0} Jahren"}, [3]string{"letztes Jahr", "dieses Jahr", "nächstes Jahr"}},
+	}, {
+		{[2]string{"in {0} Sek.", "in {0} Sek."}, [2]string{"vor {0} Sek.", "vor {0} Sek."}, [3]string{"", "jetzt", ""}},
+		{[2]string{"in {0} Min.", "in {0} Min."}, [2]string{"vor {0} Min.", "vor {0} Min."}, [3]string{"", "in dieser Minute", ""}},
+		{[2]string{"in {0} Std.", "in {0} Std."}, [2]string{"vor {0} Std.", "vor {0} Std."}, [3]string{"", "in dieser Stunde", ""}},
+		{[2]string{"in {0} Tag", "in {0} Tagen"}, [2]string{"vor {0} Tag", "vor {0} Tagen"}, [3]string{"gestern", "heute", "morgen"}},
+		{[2]string{"in {0} Woche", "in {0} Wochen"}, [2]string{"vor {0} Woche", "vor {0} Wochen"}, [3]string{"letzte Woche", "diese Woche", "nächste Woche"}},
+		{[2]string{"in {0} Monat", "in {0} Monaten"}, [2]string{"vor {0} Monat", "vor {0} Monaten"}, [3]string{"letzten Monat", "diesen Monat", "nächsten Monat"}},
+		{[2]string{"in {0} Quart.", "in {0} Quart."}, [2]string{"vor {0} Quart.", "vor {0} Quart."}, [3]string{"letztes Quartal", "dieses Quartal", "nächstes Quartal"}},
+		{[2]string{"in {0} J.", "in {0} J."}, [2]string{"vor {0} J.", "vor {0} J."}, [3]string{"letztes Jahr", "dieses Jahr", "nächstes Jahr"}},
+	}},
+	"en": {{
+		{[2]string{"in {0} second", "in {0} seconds"}, [2]string{"{0} second ago", "{0} seconds ago"}, [3]string{"", "now", ""}},
+		{[2]string{"in {0} minute", "in {0} minutes"}, [2]string{"{0} minute ago", "{0} minutes ago"}, [3]string{"", "this minute", ""}},
+		{[2]string{"in {0} hour", "in {0} hours"}, [2]string{"{0} hour ago", "{0} hours ago"}, [3]string{"", "this hour", ""}},
+		{[2]string{"in {0} day", "in {0} days"}, [2]string{"{0} day ago", "{0} days ago"}, [3]string{"yesterday", "today", "tomorrow"}},
+		{[2]string{"in {0} week", "in {0} weeks"}, [2]string{"{0} week ago", "{0} weeks ago"}, [3]string{"last week", "this week", "next week"}},
+		{[2]string{"in {0} month", "in {0} months"}, [2]string{"{0} month ago", "{0} months ago"}, [3]string{"last month", "this month", "next month"}},
+		{[2]string{"in {0} quarter", "in {0} quarters"}, [2]string{"{0} quarter ago", "{0} quarters ago"}, [3]string{"last quarter", "this quarter", "next quarter"}},
+		{[2]string{"in {0} year", "in {0} years"}, [2]string{"{0} year ago", "{0} years ago"}, [3]string{"last year", "this year", "next year"}},
+	}, {
+		{[2]string{"in {0} sec.", "in {0} sec."}, [2]string{"{0} sec. ago", "{0} sec. ago"}, [3]string{"", "now", ""}},
+		{[2]string{"in {0} min.", "in {0} min."}, [2]string{"{0} min. ago", "{0} min. ago"}, [3]string{"", "this minute", ""}},
+		{[2]string{"in {0} hr.", "in {0} hr."}, [2]string{"{0} hr. ago", "{0} hr. ago"}, [3]string{"", "this hour", ""}},
+		{[2]string{"in {0} day", "in {0} days"}, [2]string{"{0} day ago", "{0} days ago"}, [3]string{"yesterday", "today", "tomorrow"}},
+		{[2]string{"in {0} wk.", "in {0} wk."}, [2]string{"{0} wk. ago", "{0} wk. ago"}, [3]string{"last wk.", "this wk.", "next wk."}},
+		{[2]string{"in {0} mo.", "in {0} mo."}, [2]string{"{0} mo. ago", "{0} mo. ago"}, [3]string{"last mo.", "this mo.", "next mo."}},
+		{[2]string{"in {0} qtr.", "in {0} qtrs."}, [2]string{"{0} qtr. ago", "{0} qtrs. ago"}, [3]string{"last qtr.", "this qtr.", "next qtr."}},
+		{[2]string{"in {0} yr.", "in {0} yr."}, [2]string{"{0} yr. ago", "{0} yr. ago"}, [3]string{"last yr.", "this yr.", "next yr."}},
+	}},
+	"es": {{
+		{[2]string{"dentro de {0} segundo", "dentro de {0} segundos"}, [2]string{"hace {0} segundo", "hace {0} segundos"}, [3]string{"", "ahora", ""}},
+		{[2]string{"dentro de {0} minuto", "dentro de {0} minutos"}, [2]string{"hace {0} minuto", "hace {0} minutos"}, [3]string{"", "este minuto", ""}},
+		{[2]string{"dentro de {0} hora", "dentro de {0} horas"}, [2]string{"hace {0} hora", "hace {0} horas"}, [3]string{"", "esta hora", ""}},
+		{[2]string{"dentro de {0} día", "dentro de {0} días"}, [2]string{"hace {0} día", "hace {0} días"}, [3]string{"ayer", "hoy", "mañana"}},
+		{[2]string{"dentro de {0} semana", "dentro de {0} semanas"}, [2]string{"hace {0} semana", "hace {0} semanas"}, [3]string{"la semana pasada", "esta semana", "la próxima semana"}},
+		{[2]string{"dentro de {0} mes", "dentro de {0} meses"}, [2]string{"hace {0} mes", "hace {0} meses"}, [3]string{"el mes pasado", "este mes", "el próximo mes"}},
+		{[2]string{"dentro de {0} trimestre", "dentro de {0} trimestres"}, [2]string{"hace {0} trimestre", "hace {0} trimestres"}, [3]string{"el trimestre pasado", "este trimestre", "el próximo trimestre"}},
+		{[2]string{"dentro de {0} año", "dentro de {0} años"}, [2]string{"hace {0} año", "hace {0} años"}, [3]string{"el año pasado", "este año", "el próximo año"}},
+	}, {
+		{[2]string{"dentro de {0} s", "dentro de {0} s"}, [2]string{"hace {0} s", "hace {0} s"}, [3]string{"", "ahora", ""}},
+		{[2]string{"dentro de {0} min", "dentro de {0} min"}, [2]string{"hace {0} min", "hace {0} min"}, [3]string{"", "este minuto", ""}},
+		{[2]string{"dentro de {0} h", "dentro de {0} h"}, [2]string{"hace {0} h", "hace {0} h"}, [3]string{"", "esta hora", ""}},
+		{[2]string{"dentro de {0} día", "dentro de {0} días"}, [2]string{"hace {0} día", "hace {0} días"}, [3]string{"ayer", "hoy", "mañana"}},
+		{[2]string{"dentro de {0} sem.", "dentro de {0} sem."}, [2]string{"hace {0} sem.", "hace {0} sem."}, [3]string{"sem. ant.", "esta sem.", "próx. sem."}},
+		{[2]string{"dentro de {0} m", "dentro de {0} m"}, [2]string{"hace {0} m", "hace {0} m"}, [3]string{"el mes pasado", "este mes", "el próximo mes"}},
+		{[2]string{"dentro de {0} trim.", "dentro de {0} trim."}, [2]string{"hace {0} trim.", "hace {0} trim."}, [3]string{"el trim. pasado", "este trim.", "el próx. trim."}},
+		{[2]string{"dentro de {0} a", "dentro de {0} a"}, [2]string{"hace {0} a", "hace {0} a"}, [3]string{"el año pasado", "este año", "el próximo año"}},
+	}},
+	"fr": {{
+		{[2]string{"dans {0} seconde", "dans {0} secondes"}, [2]string{"il y a {0} seconde", "il y a {0} secondes"}, [3]string{"", "maintenant", ""}},
+		{[2]string{"dans {0} minute", "dans {0} minutes"}, [2]string{"il y a {0} minute", "il y a {0} minutes"}, [3]string{"", "cette minute-ci", ""}},
+		{[2]string{"dans {0} heure", "dans {0} heures"}, [2]string{"il y a {0} heure", "il y a {0} heures"}, [3]string{"", "cette heure-ci", ""}},
+		{[2]string{"dans {0} jour", "dans {0} jours"}, [2]string{"il y a {0} jour", "il y a {0} jours"}, [3]string{"hier", "aujourd’hui", "demain"}},
+		{[2]string{"dans {0} semaine", "dans {0} semaines"}, [2]string{"il y a {0} semaine", "il y a {0} semaines"}, [3]string{"la semaine dernière", "cette semaine", "la semaine prochaine"}},
+		{[2]string{"dans {0} mois", "dans {0} mois"}, [2]string{"il y a {0} mois", "il y a {0} mois"}, [3]string{"le mois dernier", "ce mois-ci", "le mois prochain"}},
+		{[2]string{"dans {0} trimestre", "dans {0} trimestres"}, [2]string{"il y a {0} trimestre", "il y a {0} trimestres"}, [3]string{"le trimestre dernier", "ce trimestre", "le trimestre prochain"}},
+		{[2]string{"dans {0} an", "dans {0} ans"}, [2]string{"il y a {0} an", "il y a {0} ans"}, [3]string{"l’année dernière", "cette année", "l’année prochaine"}},
+	}, {
+		{[2]string{"dans {0} s", "dans {0} s"}, [2]string{"il y a {0} s", "il y a {0} s"}, [3]string{"", "maintenant", ""}},
+		{[2]string{"dans {0} min", "dans {0} min"}, [2]string{"il y a {0} min", "il y a {0} min"}, [3]string{"", "cette minute-ci", ""}},
+		{[2]string{"dans {0} h", "dans {0} h"}, [2]string{"il y a {0} h", "il y a {0} h"}, [3]string{"", "cette heure-ci", ""}},
+		{[2]string{"dans {0} j", "dans {0} j"}, [2]string{"il y a {0} j", "il y a {0} j"}, [3]string{"hier", "aujourd’hui", "demain"}},
+		{[2]string{"dans {0} sem.", "dans {0} sem."}, [2]string{"il y a {0} sem.", "il y a {0} sem."}, [3]string{"la semaine dernière", "cette semaine", "la semaine prochaine"}},
+		{[2]string{"dans {0} m.", "dans {0} m."}, [2]string{"il y a {0} m.", "il y a {0} m."}, [3]string{"le mois dernier", "ce mois-ci", "le mois prochain"}},
+		{[2]string{"dans {0} trim.", "dans {0} trim."}, [2]string{"il y a {0} trim.", "il y a {0} trim."}, [3]string{"le trimestre dernier", "ce trimestre", "le trimestre prochain"}},
+		{[2]string{"dans {0} a", "dans {0} a"}, [2]string{"il y a {0} a", "il y a {0} a"}, [3]string{"l’année dernière", "cette année", "l’année prochaine"}},
+	}},
+	"ja": {{
+		{[2]string{"{0} 秒後", "{0} 秒後"}, [2]string{"{0} 秒前", "{0} 秒前"}, [3]string{"", "今", ""}},
+		{[2]string{"{0} 分後", "{0} 分後"}, [2]string{"{0} 分前", "{0} 分前"}, [3]string{"", "1 分以内", ""}},
+		{[2]string{"{0} 時間後", "{0} 時間後"}, [2]string{"{0} 時間前", "{0} 時間前"}, [3]string{"", "1 時間以内", ""}},
+		{[2]string{"{0} 日後", "{0} 日後"}, [2]string{"{0} 日前", "{0} 日前"}, [3]string{"昨日", "今日", "明日"}},
+		{[2]string{"{0} 週間後", "{0} 週間後"}, [2]string{"{0} 週間前", "{0} 週間前"}, [3]string{"先週", "今週", "来週"}},
+		{[2]string{"{0} か月後", "{0} か月後"}, [2]string{"{0} か月前", "{0} か月前"}, [3]string{"先月", "今月", "来月"}},
+		{[2]string{"{0} 四半期後", "{0} 四半期後"}, [2]string{"{0} 四半期前", "{0} 四半期前"}, [3]string{"前四半期", "今四半期", "翌四半期"}},
+		{[2]string{"{0} 年後", "{0} 年後"}, [2]string{"{0} 年前", "{0} 年前"}, [3]string{"昨年", "今年", "来年"}},
+	}, {
+		{[2]string{"{0} 秒後", "{0} 秒後"}, [2]string{"{0} 秒前", "{0} 秒前"}, [3]string{"", "今", ""}},
+		{[2]string{"{0} 分後", "{0} 分後"}, [2]string{"{0} 分前", "{0} 分前"}, [3]string{"", "1 分以内", ""}},
+		{[2]string{"{0} 時間後", "{0} 時間後"}, [2]string{"{0} 時間前", "{0} 時間前"}, [3]string{"", "1 時間以内", ""}},
+		{[2]string{"{0} 日後", "{0} 日後"}, [2]string{"{0} 日前", "{0} 日前"}, [3]string{"昨日", "今日", "明日"}},
+		{[2]string{"{0} 週間後", "{0} 週間後"}, [2]string{"{0} 週間前", "{0} 週間前"}, [3]string{"先週", "今週", "来週"}},
+		{[2]string{"{0} か月後", "{0} か月後"}, [2]string{"{0} か月前", "{0} か月前"}, [3]string{"先月", "今月", "来月"}},
+		{[2]string{"{0} 四半期後", "{0} 四半期後"}, [2]string{"{0} 四半期前", "{0} 四半期前"}, [3]string{"前四半期", "今四半期", "翌四半期"}},
+		{[2]string{"{0} 年後", "{0} 年後"}, [2]string{"{0} 年前", "{0} 年前"}, [3]string{"昨年", "今年", "来年"}},
+	}},
+	"zh": {{
+		{[2]string{"{0}秒钟后", "{0}秒钟后"}, [2]string{"{0}秒钟前", "{0}秒钟前"}, [3]string{"", "现在", ""}},
+		{[2]string{"{0}分钟后", "{0}分钟后"}, [2]string{"{0}分钟前", "{0}分钟前"}, [3]string{"", "此刻", ""}},
+		{[2]string{"{0}小时后", "{0}小时后"}, [2]string{"{0}小时前", "{0}小时前"}, [3]string{"", "这一时间 / 此时", ""}},
+		{[2]string{"{0}天后", "{0}天后"}, [2]string{"{0}天前", "{0}天前"}, [3]string{"昨天", "今天", "明天"}},
+		{[2]string{"{0}周后", "{0}周后"}, [2]string{"{0}周前", "{0}周前"}, [3]string{"上周", "本周", "下周"}},
+		{[2]string{"{0}个月后", "{0}个月后"}, [2]string{"{0}个月前", "{0}个月前"}, [3]string{"上个月", "本月", "下个月"}},
+		{[2]string{"{0}个季度后", "{0}个季度后"}, [2]string{"{0}个季度前", "{0}个季度前"}, [3]string{"上季度", "本季度", "下季度"}},
+		{[2]string{"{0}年后", "{0}年后"}, [2]string{"{0}年前", "{0}年前"}, [3]string{"去年", "今年", "明年"}},
+	}, {
+		{[2]string{"{0}秒后", "{0}秒后"}, [2]string{"{0}秒前", "{0}秒前"}, [3]string{"", "现在", ""}},
+		{[2]string{"{0}分钟后", "{0}分钟后"}, [2]string{"{0}分钟前", "{0}分钟前"}, [3]string{"", "此刻", ""}},
+		{[2]string{"{0}小时后", "{0}小时后"}, [2]string{"{0}小时前", "{0}小时前"}, [3]string{"", "这一时间 / 此时", ""}},
+		{[2]string{"{0}天后", "{0}天后"}, [2]string{"{0}天前", "{0}天前"}, [3]string{"昨天", "今天", "明天"}},
+		{[2]string{"{0}周后", "{0}周后"}, [2]string{"{0}周前", "{0}周前"}, [3]string{"上周", "本周", "下周"}},
+		{[2]string{"{0}个月后", "{0}个月后"}, [2]string{"{0}个月前", "{0}个月前"}, [3]string{"上个月", "本月", "下个月"}},
+		{[2]string{"{0}个季度后", "{0}个季度后"}, [2]string{"{0}个季度前", "{0}个季度前"}, [3]string{"上季度", "本季度", "下季度"}},
+		{[2]string{"{0}年后", "{0}年后"}, [2]string{"{0}年前", "{0}年前"}, [3]string{"去年", "今年", "明年"}},
+	}},
+}
+
+// intlRelativeTimeDay2 contains the names for -2 and 2 days.
+var intlRelativeTimeDay2 = map[string][2]string{
+	"de": {"vorgestern", "übermorgen"},
+	"es": {"anteayer", "pasado mañana"},
+	"fr": {"avant-hier", "après-demain"},
+	"ja": {"一昨日", "明後日"},
+	"zh": {"前天", "后天"},
+}
+
+var intlRelativeTimeFormatAvailable = intlLanguageAvailable(func(lang string) bool {
+	_, ok := intlRelativeTimeFormatData[lang]
+	return ok
+})
+
+func (r *Runtime) builtin_newIntlRelativeTimeFormat(args []Value, newTarget *Object) *Object {
+	if newTarget == nil {
+		panic(r.needNew("Intl.RelativeTimeFormat"))
+	}
+	proto := r.getPrototypeFromCtor(newTarget, r.global.IntlRelativeTimeFormat, r.global.IntlRelativeTimeFormatPrototype)
+	f := &intlRelativeTimeFormatObject{}
+	f.class = classObject
+	f.val = &Object{runtime: r, self: f}
+	f.extensible = true
+	f.prototype = proto
+	f.init()
+
+	var locales, options Value = _undefined, _undefined
+	if len(args) > 0 {
+		locales = args[0]
+	}
+	if len(args) > 1 {
+		options = args[1]
+	}
+	requested := r.intlCanonicalizeLocaleList(locales)
+	opts := r.intlGetOptionsObject(options)
+	r.intlGetOption(opts, "localeMatcher", []string{"lookup", "best fit"}, "best fit")
+	if ns := r.intlGetOption(opts, "numberingSystem", nil, ""); ns != "" && !intlUnicodeTypeRe.MatchString(ns) {
+		panic(r.newError(r.global.RangeError, "Invalid numberingSystem: %s", ns))
+	}
+	f.locale = r.intlLookupLocale(intlRelativeTimeFormatAvailable, requested).base
+	f.style = r.intlGetOption(opts, "style", []string{"long", "short", "narrow"}, "long")
+	f.numeric = r.intlGetOption(opts, "numeric", []string{"always", "auto"}, "always")
+
+	f.tag = language.Make(f.locale)
+	base, _ := f.tag.Base()
+	data, ok := intlRelativeTimeFormatData[base.String()]
+	if !ok {
+		data = intlRelativeTimeFormatData["en"]
+	}
+	if f.style == "long" {
+		f.units = &data[0]
+	} else {
+		f.units = &data[1]
+	}
+	f.day2 = intlRelativeTimeDay2[base.String()]
+	_, f.numberSymbols = r.localeNumberSymbols([]string{f.locale})
+	return f.val
+}
+
+func (r *Runtime) intlRelativeTimeFormat_supportedLocalesOf(call FunctionCall) Value {
+	return r.intlSupportedLocales(intlRelativeTimeFormatAvailable, call.Argument(0), call.Argument(1))
+}
+
+func (r *Runtime) thisIntlRelativeTimeFormat(this Value, method string) *intlRelativeTimeFormatObject {
+	if o, ok := this.(*Object); ok {
+		if f, ok := o.self.(*intlRelativeTimeFormatObject); ok {
+			return f
+		}
+	}
+	panic(r.NewTypeError("Method Intl.RelativeTimeFormat.prototype.%s called on incompatible receiver %s", method, r.objectproto_toString(FunctionCall{This: this})))
+}
+
+// parts implements https://tc39.es/ecma402/#sec-PartitionRelativeTimePattern. The unit of the number parts is set
+// by the caller.
+func (f *intlRelativeTimeFormatObject) parts(r *Runtime, value, unit Value) ([]intlNumberPart, string) {
+	v := value.ToFloat()
+	u := unit.toString().String()
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		panic(r.newError(r.global.RangeError, "Invalid value: %s", value.String()))
+	}
+	idx := -1
+	for i, name := range intlRelativeTimeUnits {
+		if u == name || u == name+"s" {
+			idx = i
+			u = name
+			break
+		}
+	}
+	if idx == -1 {
+		panic(r.newError(r.global.RangeError, "Invalid unit argument: %s", u))
+	}
+	data := &f.units[idx]
+	if f.numeric == "auto" && v == math.Trunc(v) {
+		var s string
+		switch {
+		case v >= -1 && v <= 1:
+			s = data.relative[int(v)+1]
+		case u == "day" && v == -2:
+			s = f.day2[0]
+		case u == "day" && v == 2:
+			s = f.day2[1]
+		}
+		if s != "" {
+			return []intlNumberPart{{"literal", s}}, u
+		}
+	}
+
+	digitOpts := intlDigitOptions{minInt: 1, maxFrac: 3}
+	d := digitOpts.round(math.Abs(v))
+	form := 1
+	if plural.Cardinal.MatchDigits(f.tag, d.digits, d.exp, d.scale) == plural.One {
+		form = 0
+	}
+	pattern := data.future[form]
+	if v < 0 || v == 0 && math.Signbit(v) {
+		pattern = data.past[form]
+	}
+	pos := strings.Index(pattern, "{0}")
+	var res []intlNumberPart
+	if pos > 0 {
+		res = append(res, intlNumberPart{"literal", pattern[:pos]})
+	}
+	res = append(res, d.parts(1, f.numberSymbols)...)
+	if rest := pattern[pos+3:]; rest != "" {
+		res = append(res, intlNumberPart{"literal", rest})
+	}
+	return res, u
+}
+
+func (r *Runtime) intlRelativeTimeFormatProto_format(call FunctionCall) Value {
+	f := r.thisIntlRelativeTimeFormat(call.This, "format")
+	parts, _ := f.parts(r, call.Argument(0), call.Argument(1))
+	var b strings.Builder
+	for _, p := range parts {
+		b.WriteString(p.value)
+	}
+	return newStringValue(b.String())
+}
+
+func (r *Runtime) intlRelativeTimeFormatProto_formatToParts(call FunctionCall) Value {
+	f := r.thisIntlRelativeTimeFormat(call.This, "formatToParts")
+	parts, unit := f.parts(r, call.Argument(0), call.Argument(1))
+	res := make([]Value, len(parts))
+	for i, p := range parts {
+		o := r.NewObject()
+		o.self._putProp("type", asciiString(p.typ), true, true, true)
+		o.self._putProp("value", newStringValue(p.value), true, true, true)
+		if p.typ != "literal" {
+			o.self._putProp("unit", asciiString(unit), true, true, true)
+		}
+		res[i] = o
+	}
+	return r.newArrayValues(res)
+}
+
+func (r *Runtime) intlRelativeTimeFormatProto_resolvedOptions(call FunctionCall) Value {
+	f := r.thisIntlRelativeTimeFormat(call.This, "resolvedOptions")
+	o := r.NewObject()
+	o.self._putProp("locale", newStringValue(f.locale), true, true, true)
+	o.self._putProp("style", asciiString(f.style), true, true, true)
+	o.self._putProp("numeric", asciiString(f.numeric), true, true, true)
+	o.self._putProp("numberingSystem", asciiString("latn"), true, true, true)
+	return o
+}
+
+func (r *Runtime) createIntlRelativeTimeFormatProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("constructor", r.global.IntlRelativeTimeFormat, true, false, true)
+	o._putProp("format", r.newNativeFunc(r.intlRelativeTimeFormatProto_format, nil, "format", nil, 2), true, false, true)
+	o._putProp("formatToParts", r.newNativeFunc(r.intlRelativeTimeFormatProto_formatToParts, nil, "formatToParts", nil, 2), true, false, true)
+	o._putProp("resolvedOptions", r.newNativeFunc(r.intlRelativeTimeFormatProto_resolvedOptions, nil, "resolvedOptions", nil, 0), true, false, true)
+	o._putSym(SymToStringTag, valueProp(asciiString("Intl.RelativeTimeFormat"), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) createIntlRelativeTimeFormat(val *Object) objectImpl {
+	o := r.newNativeConstructOnly(val, r.builtin_newIntlRelativeTimeFormat, r.global.IntlRelativeTimeFormatPrototype, "RelativeTimeFormat", 0)
+	o._putProp("supportedLocalesOf", r.newNativeFunc(r.intlRelativeTimeFormat_supportedLocalesOf, nil, "supportedLocalesOf", nil, 1), true, false, true)
+
+	return o
+}
@@ -0,0 +1,271 @@
+package goja
+
+import "testing"
+
+func TestIntlGetCanonicalLocales(t *testing.T) {
+	const SCRIPT = `
+	assert(compareArray(Intl.getCanonicalLocales(["EN-us", "en-US", "zh-hant-tw"]), ["en-US", "zh-Hant-TW"]), "canonical");
+	assert(compareArray(Intl.getCanonicalLocales("qaa-latn"), ["qaa-Latn"]), "unknown language");
+	assert(compareArray(Intl.getCanonicalLocales(), []), "undefined");
+	assert.throws(RangeError, () => Intl.getCanonicalLocales("en-"), "invalid tag");
+	assert.throws(TypeError, () => Intl.getCanonicalLocales([1]), "non-string");
+	assert.sameValue(Object.prototype.toString.call(Intl), "[object Intl]", "toStringTag");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestIntlCollator(t *testing.T) {
+	const SCRIPT = `
+	const c = new Intl.Collator("en");
+	assert.sameValue(c.compare("a", "B"), -1, "case-insensitive primary order");
+	assert.sameValue(c.compare("a", "á"), -1, "accent");
+	assert.sameValue(c.compare("résumé", "resume"), 1, "accented is greater");
+	assert.sameValue(c.compare("x", "x"), 0, "equal");
+	assert.sameValue(c.compare, c.compare, "compare is cached");
+	assert(compareArray(["b", "A", "a", "C"].sort(c.compare), ["a", "A", "b", "C"]), "sort");
+
+	assert.sameValue(new Intl.Collator("en", {sensitivity: "base"}).compare("a", "Á"), 0, "base");
+	assert.sameValue(new Intl.Collator("en", {sensitivity: "accent"}).compare("a", "A"), 0, "accent ignores case");
+	assert.sameValue(new Intl.Collator("en", {sensitivity: "accent"}).compare("a", "á"), -1, "accent");
+	assert.sameValue(new Intl.Collator("en", {sensitivity: "case"}).compare("a", "á"), 0, "case ignores accents");
+	assert.sameValue(new Intl.Collator("en", {sensitivity: "case"}).compare("a", "A"), -1, "case");
+
+	assert.sameValue(new Intl.Collator("en").compare("2", "10"), 1, "non-numeric");
+	assert.sameValue(new Intl.Collator("en", {numeric: true}).compare("2", "10"), -1, "numeric");
+	assert.sameValue(new Intl.Collator("en-u-kn").compare("2", "10"), -1, "numeric extension");
+	assert.sameValue(new Intl.Collator("en", {ignorePunctuation: true}).compare("a-b", "ab"), 0, "ignorePunctuation");
+
+	assert.sameValue(new Intl.Collator("sv").compare("ä", "z"), 1, "Swedish");
+	assert.sameValue(new Intl.Collator("de").compare("ä", "z"), -1, "German");
+
+	const ro = new Intl.Collator("de-DE-u-co-phonebk-kn", {numeric: false}).resolvedOptions();
+	assert.sameValue(ro.locale, "de-u-co-phonebk", "locale");
+	assert.sameValue(ro.collation, "phonebk", "collation");
+	assert.sameValue(ro.numeric, false, "numeric");
+	assert.sameValue(ro.usage, "sort", "usage");
+	assert.sameValue(ro.sensitivity, "variant", "sensitivity");
+	assert.sameValue(ro.ignorePunctuation, false, "ignorePunctuation");
+	assert.sameValue(Intl.Collator().resolvedOptions().locale, "en-US", "default locale");
+
+	assert(compareArray(Intl.Collator.supportedLocalesOf(["de-AT", "tlh", "zh-Hant"]), ["de-AT", "zh-Hant"]), "supportedLocalesOf");
+	assert(Intl.Collator() instanceof Intl.Collator, "call without new");
+	assert.throws(RangeError, () => new Intl.Collator("en", {sensitivity: "bogus"}), "invalid option");
+	assert.throws(TypeError, () => new Intl.Collator("en", null), "null options");
+	assert.throws(TypeError, () => Intl.Collator.prototype.resolvedOptions.call({}), "receiver");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestStringLocaleCompare(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue("a".localeCompare("B"), -1, "default");
+	assert.sameValue("ä".localeCompare("z", "sv"), 1, "locale");
+	assert.sameValue("ä".localeCompare("z", "de"), -1, "locale de");
+	assert.sameValue("a".localeCompare("A", undefined, {sensitivity: "base"}), 0, "options");
+	assert.sameValue("item 2".localeCompare("item 10", "en", {numeric: true}), -1, "numeric");
+	assert.sameValue("Å".localeCompare("Å"), 0, "canonical equivalence");
+	assert.throws(RangeError, () => "a".localeCompare("b", "invalid-"), "invalid locale");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestIntlPluralRules(t *testing.T) {
+	const SCRIPT = `
+	const en = new Intl.PluralRules("en-US");
+	assert.sameValue(en.select(0), "other", "0");
+	assert.sameValue(en.select(1), "one", "1");
+	assert.sameValue(en.select(-1), "one", "-1");
+	assert.sameValue(en.select(2), "other", "2");
+	assert.sameValue(en.select(1.5), "other", "1.5");
+	assert.sameValue(en.select(NaN), "other", "NaN");
+	assert.sameValue(new Intl.PluralRules("en", {minimumFractionDigits: 1}).select(1), "other", "1.0");
+	assert.sameValue(new Intl.PluralRules("en", {maximumFractionDigits: 0}).select(1.2), "one", "rounded");
+
+	const ord = new Intl.PluralRules("en", {type: "ordinal"});
+	assert(compareArray([1, 2, 3, 4, 11, 12, 13, 21, 22, 23, 101].map(n => ord.select(n)),
+		["one", "two", "few", "other", "other", "other", "other", "one", "two", "few", "one"]), "ordinal");
+
+	const ru = new Intl.PluralRules("ru");
+	assert(compareArray([1, 2, 5, 21, 1.5].map(n => ru.select(n)), ["one", "few", "many", "one", "other"]), "ru");
+	assert.sameValue(new Intl.PluralRules("ar").select(0), "zero", "ar");
+	assert.sameValue(new Intl.PluralRules("ja").select(1), "other", "ja");
+
+	assert.sameValue(en.selectRange(1, 2), "other", "selectRange");
+	assert.sameValue(ru.selectRange(0, 1), "one", "selectRange ru");
+	assert.throws(RangeError, () => en.selectRange(NaN, 1), "selectRange NaN");
+	assert.throws(TypeError, () => en.selectRange(1), "selectRange undefined");
+
+	const ro = ru.resolvedOptions();
+	assert.sameValue(ro.locale, "ru", "locale");
+	assert.sameValue(ro.type, "cardinal", "type");
+	assert.sameValue(ro.minimumIntegerDigits, 1, "minimumIntegerDigits");
+	assert.sameValue(ro.maximumFractionDigits, 3, "maximumFractionDigits");
+	assert(compareArray(ro.pluralCategories, ["one", "few", "many", "other"]), "pluralCategories");
+	assert(compareArray(ord.resolvedOptions().pluralCategories, ["one", "two", "few", "other"]), "ordinal pluralCategories");
+	assert.sameValue(new Intl.PluralRules("en", {maximumSignificantDigits: 2}).resolvedOptions().maximumSignificantDigits, 2, "significant");
+
+	assert.throws(TypeError, () => Intl.PluralRules(), "requires new");
+	assert.throws(RangeError, () => new Intl.PluralRules("en", {type: "bogus"}), "type");
+	assert.throws(RangeError, () => new Intl.PluralRules("en", {minimumFractionDigits: 4, maximumFractionDigits: 2}), "fraction digits");
+	assert(compareArray(Intl.PluralRules.supportedLocalesOf(["fr-CA", "zz"]), ["fr-CA"]), "supportedLocalesOf");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestIntlListFormat(t *testing.T) {
+	const SCRIPT = `
+	const lf = new Intl.ListFormat("en");
+	assert.sameValue(lf.format([]), "", "empty");
+	assert.sameValue(lf.format(["a"]), "a", "one");
+	assert.sameValue(lf.format(["a", "b"]), "a and b", "two");
+	assert.sameValue(lf.format(["a", "b", "c", "d"]), "a, b, c, and d", "four");
+	assert.sameValue(lf.format(new Set(["x", "y", "z"])), "x, y, and z", "iterable");
+	assert.sameValue(new Intl.ListFormat("en", {type: "disjunction"}).format(["a", "b", "c"]), "a, b, or c", "disjunction");
+	assert.sameValue(new Intl.ListFormat("en", {style: "short"}).format(["a", "b"]), "a & b", "short");
+	assert.sameValue(new Intl.ListFormat("en", {type: "unit", style: "narrow"}).format(["1h", "2m"]), "1h 2m", "unit narrow");
+	assert.sameValue(new Intl.ListFormat("de").format(["a", "b", "c"]), "a, b und c", "de");
+	assert.sameValue(new Intl.ListFormat("ja").format(["a", "b", "c"]), "a、b、c", "ja");
+
+	const parts = lf.formatToParts(["a", "b"]);
+	assert.sameValue(parts.length, 3, "parts length");
+	assert.sameValue(parts[0].type, "element", "parts[0].type");
+	assert.sameValue(parts[1].type, "literal", "parts[1].type");
+	assert.sameValue(parts[1].value, " and ", "parts[1].value");
+
+	const ro = new Intl.ListFormat("fr-CA", {type: "unit"}).resolvedOptions();
+	assert.sameValue(ro.locale, "fr-CA", "locale");
+	assert.sameValue(ro.type, "unit", "type");
+	assert.sameValue(ro.style, "long", "style");
+	assert.sameValue(new Intl.ListFormat("zz").resolvedOptions().locale, "en-US", "default locale");
+
+	assert.throws(TypeError, () => lf.format(["a", 1]), "non-string element");
+	assert.throws(TypeError, () => Intl.ListFormat(), "requires new");
+	assert.throws(TypeError, () => new Intl.ListFormat("en", "long"), "options not an object");
+	assert.throws(RangeError, () => new Intl.ListFormat("en", {type: "bogus"}), "type");
+	assert(compareArray(Intl.ListFormat.supportedLocalesOf(["es-MX", "zz"]), ["es-MX"]), "supportedLocalesOf");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestIntlRelativeTimeFormat(t *testing.T) {
+	const SCRIPT = `
+	const rtf = new Intl.RelativeTimeFormat("en");
+	assert.sameValue(rtf.format(1, "day"), "in 1 day", "1 day");
+	assert.sameValue(rtf.format(-3, "days"), "3 days ago", "-3 days");
+	assert.sameValue(rtf.format(-0, "second"), "0 seconds ago", "-0");
+	assert.sameValue(rtf.format(1.5, "hour"), "in 1.5 hours", "fraction");
+	assert.sameValue(rtf.format(1234.5678, "year"), "in 1,234.568 years", "grouping");
+	assert.sameValue(rtf.format(-1, "day"), "1 day ago", "always");
+
+	const auto = new Intl.RelativeTimeFormat("en", {numeric: "auto"});
+	assert.sameValue(auto.format(-1, "day"), "yesterday", "yesterday");
+	assert.sameValue(auto.format(0, "year"), "this year", "this year");
+	assert.sameValue(auto.format(1, "week"), "next week", "next week");
+	assert.sameValue(auto.format(0, "second"), "now", "now");
+	assert.sameValue(auto.format(2, "day"), "in 2 days", "no name");
+	assert.sameValue(new Intl.RelativeTimeFormat("de", {numeric: "auto"}).format(2, "day"), "übermorgen", "de +2 days");
+
+	assert.sameValue(new Intl.RelativeTimeFormat("en", {style: "short"}).format(3, "month"), "in 3 mo.", "short");
+	assert.sameValue(new Intl.RelativeTimeFormat("de").format(-2, "hour"), "vor 2 Stunden", "de");
+	assert.sameValue(new Intl.RelativeTimeFormat("de").format(1234.5, "day"), "in 1.234,5 Tagen", "de number");
+	assert.sameValue(new Intl.RelativeTimeFormat("es").format(1000, "day"), "dentro de 1000 días", "es grouping");
+	assert.sameValue(new Intl.RelativeTimeFormat("fr").format(0, "day"), "dans 0 jour", "fr 0 is singular");
+	assert.sameValue(new Intl.RelativeTimeFormat("ja").format(-5, "minute"), "5 分前", "ja");
+
+	const parts = rtf.formatToParts(-1234, "day");
+	assert.sameValue(parts.length, 4, "parts length");
+	assert.sameValue(parts[0].type, "integer", "parts[0].type");
+	assert.sameValue(parts[0].value, "1", "parts[0].value");
+	assert.sameValue(parts[0].unit, "day", "parts[0].unit");
+	assert.sameValue(parts[1].type, "group", "parts[1].type");
+	assert.sameValue(parts[3].type, "literal", "parts[3].type");
+	assert.sameValue(parts[3].value, " days ago", "parts[3].value");
+	assert.sameValue(parts[3].unit, undefined, "literal has no unit");
+	const autoParts = auto.formatToParts(1, "days");
+	assert.sameValue(autoParts.length, 1, "auto parts length");
+	assert.sameValue(autoParts[0].value, "tomorrow", "auto parts value");
+
+	const ro = new Intl.RelativeTimeFormat("en-GB", {style: "narrow", numeric: "auto"}).resolvedOptions();
+	assert.sameValue(ro.locale, "en-GB", "locale");
+	assert.sameValue(ro.style, "narrow", "style");
+	assert.sameValue(ro.numeric, "auto", "numeric");
+	assert.sameValue(ro.numberingSystem, "latn", "numberingSystem");
+
+	assert.throws(RangeError, () => rtf.format(1, "decade"), "unit");
+	assert.throws(RangeError, () => rtf.format(Infinity, "day"), "value");
+	assert.throws(TypeError, () => Intl.RelativeTimeFormat(), "requires new");
+	assert.throws(RangeError, () => new Intl.RelativeTimeFormat("en", {numeric: "never"}), "numeric");
+	assert.throws(TypeError, () => Intl.RelativeTimeFormat.prototype.format.call({}, 1, "day"), "receiver");
+	assert(compareArray(Intl.RelativeTimeFormat.supportedLocalesOf(["de-AT", "zz"]), ["de-AT"]), "supportedLocalesOf");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestNumberToLocaleString(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue((1234567.891).toLocaleString(), "1,234,567.891");
+	assert.sameValue((1234.5678).toLocaleString("en"), "1,234.568", "rounding");
+	assert.sameValue((-1234.5).toLocaleString("de-DE"), "-1.234,5", "de");
+	assert.sameValue((1234).toLocaleString("es"), "1234", "es minimum grouping digits");
+	assert.sameValue((12345).toLocaleString("es"), "12.345", "es");
+	assert.sameValue((12345).toLocaleString("en", { useGrouping: false }), "12345", "useGrouping");
+	assert.sameValue((1.5).toLocaleString("en", { minimumFractionDigits: 2 }), "1.50", "minimumFractionDigits");
+	assert.sameValue((7).toLocaleString("en", { minimumIntegerDigits: 3 }), "007", "minimumIntegerDigits");
+	assert.sameValue((-0).toLocaleString(), "-0");
+	assert.sameValue(NaN.toLocaleString(), "NaN");
+	assert.sameValue((-Infinity).toLocaleString(), "-∞");
+	assert.sameValue([1234, 5].toLocaleString(), "1,234,5", "Array.prototype.toLocaleString");
+	assert.throws(RangeError, () => (1).toLocaleString("en-"));
+	assert.throws(TypeError, () => Number.prototype.toLocaleString.call("1"));
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+type testLocaleProvider struct{}
+
+func (testLocaleProvider) DefaultLocale() string {
+	return "de-CH"
+}
+
+func (testLocaleProvider) NumberSymbols(locale string) (NumberSymbols, bool) {
+	if locale == "de-CH" {
+		return NumberSymbols{Group: "’", Decimal: ".", MinimumGroupingDigits: 1}, true
+	}
+	return NumberSymbols{}, false
+}
+
+func (testLocaleProvider) DateTimeLayouts(locale string) (DateTimeLayouts, bool) {
+	switch locale {
+	case "de-CH":
+		return DateTimeLayouts{DateTime: "2.1.2006, 15:04:05", Date: "2.1.2006", Time: "15:04:05"}, true
+	case "en":
+		return DateTimeLayouts{DateTime: "1/2/2006, 3:04:05 PM", Date: "1/2/2006", Time: "3:04:05 PM"}, true
+	}
+	return DateTimeLayouts{}, false
+}
+
+func TestLocaleProvider(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue((1234.5).toLocaleString(), "1’234.5", "default locale");
+	assert.sameValue((1234.5).toLocaleString("fr"), "1’234.5", "unsupported locale");
+	const d = new Date(Date.UTC(2021, 6, 1, 13, 30, 15));
+	assert.sameValue(d.toLocaleString(undefined, { timeZone: "UTC" }), "1.7.2021, 13:30:15");
+	assert.sameValue(d.toLocaleString("en-US", { timeZone: "UTC" }), "7/1/2021, 1:30:15 PM", "less specific tag");
+	assert.sameValue(d.toLocaleDateString("en", { timeZone: "UTC" }), "7/1/2021");
+	assert.sameValue(d.toLocaleTimeString(["fr", "en"], { timeZone: "UTC" }), "1:30:15 PM", "second requested locale");
+	assert.sameValue(new Intl.ListFormat().resolvedOptions().locale, "de-CH", "Intl default locale");
+	assert.sameValue(new Intl.RelativeTimeFormat("de-CH").format(1234, "day"), "in 1’234 Tagen", "RelativeTimeFormat");
+	`
+	r := New()
+	r.SetLocaleProvider(testLocaleProvider{})
+	r.testScriptWithTestLib(SCRIPT, _undefined, t)
+
+	r.SetLocaleProvider(nil)
+	v, err := r.RunString(`(1234.5).toLocaleString()`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := v.String(); s != "1,234.5" {
+		t.Fatal(s)
+	}
+}
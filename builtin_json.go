@@ -16,10 +16,32 @@ import (
 
 const hex = "0123456789abcdef"
 
+// jsonParseRecord holds the source text of a value produced by JSON.parse. It is only built when a reviver is
+// supplied and is used to populate the context argument of the reviver (see
+// https://tc39.es/proposal-json-parse-with-source/).
+type jsonParseRecord struct {
+	value    Value
+	source   string // primitive values only
+	elements []*jsonParseRecord
+	entries  map[unistring.String]*jsonParseRecord
+}
+
 func (r *Runtime) builtinJSON_parse(call FunctionCall) Value {
-	d := json.NewDecoder(strings.NewReader(call.Argument(0).toString().String()))
+	src := call.Argument(0).toString().String()
+	d := json.NewDecoder(strings.NewReader(src))
+
+	var reviver func(FunctionCall) Value
+
+	if arg1 := call.Argument(1); arg1 != _undefined {
+		reviver, _ = arg1.ToObject(r).self.assertCallable()
+	}
 
-	value, err := r.builtinJSON_decodeValue(d)
+	var rec *jsonParseRecord
+	if reviver != nil {
+		rec = &jsonParseRecord{}
+	}
+
+	value, err := r.builtinJSON_decodeValue(d, src, rec)
 	if err != nil {
 		panic(r.newError(r.global.SyntaxError, err.Error()))
 	}
@@ -28,29 +50,41 @@ func (r *Runtime) builtinJSON_parse(call FunctionCall) Value {
 		panic(r.newError(r.global.SyntaxError, "Unexpected token at the end: %v", tok))
 	}
 
-	var reviver func(FunctionCall) Value
-
-	if arg1 := call.Argument(1); arg1 != _undefined {
-		reviver, _ = arg1.ToObject(r).self.assertCallable()
-	}
-
 	if reviver != nil {
 		root := r.NewObject()
 		createDataPropertyOrThrow(root, stringEmpty, value)
-		return r.builtinJSON_reviveWalk(reviver, root, stringEmpty)
+		return r.builtinJSON_reviveWalk(reviver, root, stringEmpty, rec)
 	}
 
 	return value
 }
 
-func (r *Runtime) builtinJSON_decodeToken(d *json.Decoder, tok json.Token) (Value, error) {
+// parseJSON parses src the same way JSON.parse() without a reviver does.
+func (r *Runtime) parseJSON(src string) (Value, error) {
+	d := json.NewDecoder(strings.NewReader(src))
+	value, err := r.builtinJSON_decodeValue(d, src, nil)
+	if err != nil {
+		return nil, err
+	}
+	if tok, err := d.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected token at the end: %v", tok)
+	}
+	return value, nil
+}
+
+// builtinJSON_tokenSource returns the source text of the token that has been read from d starting at offset start.
+func builtinJSON_tokenSource(d *json.Decoder, src string, start int64) string {
+	return strings.TrimLeft(src[start:d.InputOffset()], " \t\r\n:,")
+}
+
+func (r *Runtime) builtinJSON_decodeToken(d *json.Decoder, src string, tok json.Token, rec *jsonParseRecord) (Value, error) {
 	switch tok := tok.(type) {
 	case json.Delim:
 		switch tok {
 		case '{':
-			return r.builtinJSON_decodeObject(d)
+			return r.builtinJSON_decodeObject(d, src, rec)
 		case '[':
-			return r.builtinJSON_decodeArray(d)
+			return r.builtinJSON_decodeArray(d, src, rec)
 		}
 	case nil:
 		return _null, nil
@@ -67,15 +101,23 @@ func (r *Runtime) builtinJSON_decodeToken(d *json.Decoder, tok json.Token) (Valu
 	return nil, fmt.Errorf("Unexpected token (%T): %v", tok, tok)
 }
 
-func (r *Runtime) builtinJSON_decodeValue(d *json.Decoder) (Value, error) {
+func (r *Runtime) builtinJSON_decodeValue(d *json.Decoder, src string, rec *jsonParseRecord) (Value, error) {
+	start := d.InputOffset()
 	tok, err := d.Token()
 	if err != nil {
 		return nil, err
 	}
-	return r.builtinJSON_decodeToken(d, tok)
+	if rec != nil {
+		rec.source = builtinJSON_tokenSource(d, src, start)
+	}
+	value, err := r.builtinJSON_decodeToken(d, src, tok, rec)
+	if rec != nil {
+		rec.value = value
+	}
+	return value, err
 }
 
-func (r *Runtime) builtinJSON_decodeObject(d *json.Decoder) (*Object, error) {
+func (r *Runtime) builtinJSON_decodeObject(d *json.Decoder, src string, rec *jsonParseRecord) (*Object, error) {
 	object := r.NewObject()
 	for {
 		key, end, err := r.builtinJSON_decodeObjectKey(d)
@@ -85,12 +127,23 @@ func (r *Runtime) builtinJSON_decodeObject(d *json.Decoder) (*Object, error) {
 		if end {
 			break
 		}
-		value, err := r.builtinJSON_decodeValue(d)
+		var entry *jsonParseRecord
+		if rec != nil {
+			entry = &jsonParseRecord{}
+		}
+		value, err := r.builtinJSON_decodeValue(d, src, entry)
 		if err != nil {
 			return nil, err
 		}
 
-		object.self._putProp(unistring.NewFromString(key), value, true, true, true)
+		name := unistring.NewFromString(key)
+		object.self._putProp(name, value, true, true, true)
+		if rec != nil {
+			if rec.entries == nil {
+				rec.entries = make(map[unistring.String]*jsonParseRecord)
+			}
+			rec.entries[name] = entry
+		}
 	}
 	return object, nil
 }
@@ -112,9 +165,10 @@ func (r *Runtime) builtinJSON_decodeObjectKey(d *json.Decoder) (string, bool, er
 	return "", false, fmt.Errorf("Unexpected token (%T): %v", tok, tok)
 }
 
-func (r *Runtime) builtinJSON_decodeArray(d *json.Decoder) (*Object, error) {
+func (r *Runtime) builtinJSON_decodeArray(d *json.Decoder, src string, rec *jsonParseRecord) (*Object, error) {
 	var arrayValue []Value
 	for {
+		start := d.InputOffset()
 		tok, err := d.Token()
 		if err != nil {
 			return nil, err
@@ -124,24 +178,45 @@ func (r *Runtime) builtinJSON_decodeArray(d *json.Decoder) (*Object, error) {
 				break
 			}
 		}
-		value, err := r.builtinJSON_decodeToken(d, tok)
+		var element *jsonParseRecord
+		if rec != nil {
+			element = &jsonParseRecord{source: builtinJSON_tokenSource(d, src, start)}
+		}
+		value, err := r.builtinJSON_decodeToken(d, src, tok, element)
 		if err != nil {
 			return nil, err
 		}
+		if rec != nil {
+			element.value = value
+			rec.elements = append(rec.elements, element)
+		}
 		arrayValue = append(arrayValue, value)
 	}
 	return r.newArrayValues(arrayValue), nil
 }
 
-func (r *Runtime) builtinJSON_reviveWalk(reviver func(FunctionCall) Value, holder *Object, name Value) Value {
+func (r *Runtime) builtinJSON_reviveWalk(reviver func(FunctionCall) Value, holder *Object, name Value, rec *jsonParseRecord) Value {
 	value := nilSafe(holder.get(name, nil))
 
+	context := r.NewObject()
+	if rec != nil && rec.value != nil && value.SameAs(rec.value) {
+		if _, ok := value.(*Object); !ok {
+			createDataPropertyOrThrow(context, asciiString("source"), newStringValue(rec.source))
+		}
+	} else {
+		rec = nil
+	}
+
 	if object, ok := value.(*Object); ok {
 		if isArray(object) {
 			length := toLength(object.self.getStr("length", nil))
 			for index := int64(0); index < length; index++ {
 				name := asciiString(strconv.FormatInt(index, 10))
-				value := r.builtinJSON_reviveWalk(reviver, object, name)
+				var element *jsonParseRecord
+				if rec != nil && index < int64(len(rec.elements)) {
+					element = rec.elements[index]
+				}
+				value := r.builtinJSON_reviveWalk(reviver, object, name, element)
 				if value == _undefined {
 					object.delete(name, false)
 				} else {
@@ -150,7 +225,11 @@ func (r *Runtime) builtinJSON_reviveWalk(reviver func(FunctionCall) Value, holde
 			}
 		} else {
 			for _, name := range object.self.stringKeys(false, nil) {
-				value := r.builtinJSON_reviveWalk(reviver, object, name)
+				var entry *jsonParseRecord
+				if rec != nil {
+					entry = rec.entries[name.string()]
+				}
+				value := r.builtinJSON_reviveWalk(reviver, object, name, entry)
 				if value == _undefined {
 					object.self.deleteStr(name.string(), false)
 				} else {
@@ -161,10 +240,62 @@ func (r *Runtime) builtinJSON_reviveWalk(reviver func(FunctionCall) Value, holde
 	}
 	return reviver(FunctionCall{
 		This:      holder,
-		Arguments: []Value{name, value},
+		Arguments: []Value{name, value, context},
 	})
 }
 
+// rawJSONObject is the object returned by JSON.rawJSON(). JSON.stringify() outputs its text verbatim.
+type rawJSONObject struct {
+	baseObject
+	text string
+}
+
+func (r *Runtime) newRawJSON(str valueString) *Object {
+	text := str.String()
+	if text == "" || isJSONWhiteSpace(text[0]) || isJSONWhiteSpace(text[len(text)-1]) {
+		panic(r.newError(r.global.SyntaxError, "Invalid value for JSON.rawJSON"))
+	}
+	d := json.NewDecoder(strings.NewReader(text))
+	tok, err := d.Token()
+	if err != nil {
+		panic(r.newError(r.global.SyntaxError, err.Error()))
+	}
+	if _, ok := tok.(json.Delim); ok {
+		panic(r.newError(r.global.SyntaxError, "JSON.rawJSON cannot create objects or arrays"))
+	}
+	if tok, err := d.Token(); err != io.EOF {
+		panic(r.newError(r.global.SyntaxError, "Unexpected token at the end: %v", tok))
+	}
+
+	v := &Object{runtime: r}
+	o := &rawJSONObject{text: text}
+	o.class = classObject
+	o.val = v
+	o.extensible = true
+	v.self = o
+	o.init()
+	o._putProp("rawJSON", str, false, true, false)
+	o.extensible = false
+	return v
+}
+
+func (r *Runtime) builtinJSON_rawJSON(call FunctionCall) Value {
+	return r.newRawJSON(call.Argument(0).toString())
+}
+
+func (r *Runtime) builtinJSON_isRawJSON(call FunctionCall) Value {
+	if o, ok := call.Argument(0).(*Object); ok {
+		if _, ok := o.self.(*rawJSONObject); ok {
+			return valueTrue
+		}
+	}
+	return valueFalse
+}
+
+func isJSONWhiteSpace(c byte) bool {
+	return c == '\t' || c == '\n' || c == '\r' || c == ' '
+}
+
 type _builtinJSON_stringifyContext struct {
 	r                *Runtime
 	stack            []*Object
@@ -258,16 +389,42 @@ func (r *Runtime) builtinJSON_stringify(call FunctionCall) Value {
 
 	if ctx.do(call.Argument(0)) {
 		if ctx.allAscii {
+			r.checkStringLength(int64(ctx.buf.Len()))
 			return asciiString(ctx.buf.String())
 		} else {
-			return &importedString{
+			res := &importedString{
 				s: ctx.buf.String(),
 			}
+			r.checkStringLength(int64(res.length()))
+			return res
 		}
 	}
 	return _undefined
 }
 
+// checkLength checks the length of the output produced so far against the string length limit. The buffer holds
+// UTF-8, so unless the output is all ASCII only a lower bound of its UTF-16 length is checked here, the exact length
+// is checked once the output is complete.
+func (ctx *_builtinJSON_stringifyContext) checkLength() {
+	l := int64(ctx.buf.Len())
+	if !ctx.allAscii {
+		l /= 3
+	}
+	ctx.r.checkStringLength(l)
+}
+
+func (ctx *_builtinJSON_stringifyContext) writeRaw(s string) {
+	ctx.buf.WriteString(s)
+	if ctx.allAscii {
+		for i := 0; i < len(s); i++ {
+			if s[i] >= utf8.RuneSelf {
+				ctx.allAscii = false
+				break
+			}
+		}
+	}
+}
+
 func (ctx *_builtinJSON_stringifyContext) do(v Value) bool {
 	holder := ctx.r.NewObject()
 	createDataPropertyOrThrow(holder, stringEmpty, v)
@@ -275,10 +432,12 @@ func (ctx *_builtinJSON_stringifyContext) do(v Value) bool {
 }
 
 func (ctx *_builtinJSON_stringifyContext) str(key Value, holder *Object) bool {
+	ctx.checkLength()
 	value := nilSafe(holder.get(key, nil))
 
-	if object, ok := value.(*Object); ok {
-		if toJSON, ok := object.self.getStr("toJSON", nil).(*Object); ok {
+	switch value.(type) {
+	case *Object, *valueBigInt:
+		if toJSON, ok := ctx.r.getVStr(value, "toJSON").(*Object); ok {
 			if c, ok := toJSON.self.assertCallable(); ok {
 				value = c(FunctionCall{
 					This:      value,
@@ -295,6 +454,11 @@ func (ctx *_builtinJSON_stringifyContext) str(key Value, holder *Object) bool {
 		})
 	}
 
+	switch value1 := value.(type) {
+	case *valueRecord, *valueTuple:
+		value = value1.ToObject(ctx.r)
+	}
+
 	if o, ok := value.(*Object); ok {
 		switch o1 := o.self.(type) {
 		case *primitiveValueObject:
@@ -306,9 +470,17 @@ func (ctx *_builtinJSON_stringifyContext) str(key Value, holder *Object) bool {
 			}
 		case *stringObject:
 			value = o.toString()
+		case *rawJSONObject:
+			ctx.writeRaw(o1.text)
+			return true
 		case *objectGoReflect:
 			if o1.toJson != nil {
-				value = ctx.r.ToValue(o1.toJson())
+				v := o1.toJson()
+				if raw, ok := v.(RawJSON); ok {
+					ctx.writeRaw(string(raw))
+					return true
+				}
+				value = ctx.r.ToValue(v)
 			} else if v, ok := o1.origValue.Interface().(json.Marshaler); ok {
 				b, err := v.MarshalJSON()
 				if err != nil {
@@ -353,6 +525,8 @@ func (ctx *_builtinJSON_stringifyContext) str(key Value, holder *Object) bool {
 		}
 	case valueNull:
 		ctx.buf.WriteString("null")
+	case *valueBigInt:
+		ctx.r.typeErrorResult(true, "Do not know how to serialize a BigInt")
 	case *Object:
 		for _, object := range ctx.stack {
 			if value1 == object {
@@ -362,7 +536,7 @@ func (ctx *_builtinJSON_stringifyContext) str(key Value, holder *Object) bool {
 		ctx.stack = append(ctx.stack, value1)
 		defer func() { ctx.stack = ctx.stack[:len(ctx.stack)-1] }()
 		if _, ok := value1.self.assertCallable(); !ok {
-			if isArray(value1) {
+			if _, isTuple := value1.self.(*tupleObject); isTuple || isArray(value1) {
 				ctx.ja(value1)
 			} else {
 				ctx.jo(value1)
@@ -522,6 +696,8 @@ func (r *Runtime) initJSON() {
 	JSON := r.newBaseObject(r.global.ObjectPrototype, "JSON")
 	JSON._putProp("parse", r.newNativeFunc(r.builtinJSON_parse, nil, "parse", nil, 2), true, false, true)
 	JSON._putProp("stringify", r.newNativeFunc(r.builtinJSON_stringify, nil, "stringify", nil, 3), true, false, true)
+	JSON._putProp("rawJSON", r.newNativeFunc(r.builtinJSON_rawJSON, nil, "rawJSON", nil, 1), true, false, true)
+	JSON._putProp("isRawJSON", r.newNativeFunc(r.builtinJSON_isRawJSON, nil, "isRawJSON", nil, 1), true, false, true)
 	JSON._putSym(SymToStringTag, valueProp(asciiString(classJSON), false, false, true))
 
 	r.addToGlobal("JSON", JSON.val)
@@ -2,6 +2,7 @@ package goja
 
 import (
 	"encoding/json"
+	"errors"
 	"strings"
 	"testing"
 	"time"
@@ -61,6 +62,84 @@ func TestJSONParseReviver(t *testing.T) {
 	testScript(SCRIPT, intToValue(10), t)
 }
 
+func TestJSONParseReviverSource(t *testing.T) {
+	const SCRIPT = `
+	var sources = {};
+	var res = JSON.parse(' {"big": 12345678901234567890, "s" : "a\\u0062", "arr": [1.0, true, null, {"x": -0}]}', function(key, value, context) {
+		sources[key] = context.source;
+		if (key === "big") {
+			return BigInt(context.source);
+		}
+		return value;
+	});
+	assert.sameValue(res.big, 12345678901234567890n, "big");
+	assert.sameValue(sources.s, '"a\\u0062"', "s");
+	assert.sameValue(sources[0], "1.0", "0");
+	assert.sameValue(sources[1], "true", "1");
+	assert.sameValue(sources[2], "null", "2");
+	assert.sameValue(sources.x, "-0", "x");
+	assert.sameValue(sources.arr, undefined, "arr");
+	assert.sameValue(sources[""], undefined, "root");
+
+	var src;
+	JSON.parse('[1, 2]', function(key, value, context) {
+		if (key === "0") {
+			this[1] = 3;
+		}
+		if (key === "1") {
+			src = context.source;
+		}
+		return value;
+	});
+	assert.sameValue(src, undefined, "modified value");
+
+	JSON.parse(' 42 ', function(key, value, context) {
+		src = context.source;
+	});
+	assert.sameValue(src, "42", "primitive root");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestJSONRawJSON(t *testing.T) {
+	const SCRIPT = `
+	var raw = JSON.rawJSON("12345678901234567890");
+	assert(JSON.isRawJSON(raw), "isRawJSON");
+	assert(!JSON.isRawJSON({rawJSON: "1"}), "plain object");
+	assert(!JSON.isRawJSON(1), "primitive");
+	assert.sameValue(raw.rawJSON, "12345678901234567890", "rawJSON");
+	assert.sameValue(Object.getPrototypeOf(raw), null, "prototype");
+	assert(Object.isFrozen(raw), "frozen");
+	assert.sameValue(JSON.rawJSON('"str"').rawJSON, '"str"', "string");
+	assert.sameValue(JSON.rawJSON(null).rawJSON, "null", "null");
+
+	["", " 1", "1 ", "\t1", "1\n", "{}", "[]", "1 2", "abc", "01"].forEach(function(text) {
+		assert.throws(SyntaxError, function() {
+			JSON.rawJSON(text);
+		}, JSON.stringify(text));
+	});
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestJSONStringifyRawJSON(t *testing.T) {
+	const SCRIPT = `
+	var big = 12345678901234567890n;
+	var s = JSON.stringify({big: big, arr: [JSON.rawJSON('"\u00e9"'), 1]}, function(key, value) {
+		return typeof value === "bigint" ? JSON.rawJSON(value.toString()) : value;
+	});
+	assert.sameValue(s, '{"big":12345678901234567890,"arr":["\u00e9",1]}');
+	assert.sameValue(JSON.stringify(JSON.rawJSON("null")), "null", "top level");
+	assert.sameValue(JSON.stringify([JSON.rawJSON("1")], null, 2), "[\n  1\n]", "indent");
+
+	var parsed = JSON.parse(s, function(key, value, context) {
+		return key === "big" ? BigInt(context.source) : value;
+	});
+	assert.sameValue(parsed.big, big, "round trip");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
 func TestQuoteMalformedSurrogatePair(t *testing.T) {
 	testScript(`JSON.stringify("\uD800")`, asciiString(`"\ud800"`), t)
 }
@@ -89,3 +168,54 @@ func BenchmarkJSONStringify(b *testing.B) {
 		stringify(nil, o)
 	}
 }
+
+type testJSONLevel int
+
+func (l *testJSONLevel) MarshalJSON() ([]byte, error) {
+	if *l < 0 {
+		return nil, errors.New("negative level")
+	}
+	return json.Marshal(strings.Repeat("*", int(*l)))
+}
+
+func TestJSONMarshalerToValue(t *testing.T) {
+	type config struct {
+		Level testJSONLevel
+		Extra json.RawMessage
+		Ptr   *testJSONLevel
+	}
+	c := config{Level: 3, Extra: json.RawMessage(`{"a":[1,true,null]}`)}
+	vm := New()
+	vm.Set("c", c)
+	res, err := vm.RunString(`typeof c.Extra === "object" && c.Extra.a === undefined`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.ToBoolean() {
+		t.Fatal("unexpected conversion without the option")
+	}
+
+	vm.SetJSONMarshalerToValue(true)
+	vm.Set("c", c)
+	res, err = vm.RunString(`JSON.stringify(c)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := res.String(); s != `{"Level":"***","Extra":{"a":[1,true,null]},"Ptr":null}` {
+		t.Fatal(s)
+	}
+
+	c.Level = -1
+	vm.Set("c", c)
+	_, err = vm.RunString(`c.Level`)
+	if ex, ok := err.(*Exception); !ok || ex.Value().Export() == nil || !strings.Contains(ex.Error(), "negative level") {
+		t.Fatal(err)
+	}
+	_, err = vm.RunString(`c.Ptr`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.Set("raw", json.RawMessage(`{"a":1} x`)); err == nil {
+		t.Fatal("expected an error")
+	}
+}
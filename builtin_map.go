@@ -1,6 +1,7 @@
 package goja
 
 import (
+	"fmt"
 	"reflect"
 )
 
@@ -84,6 +85,12 @@ func (mo *mapObject) exportToMap(dst reflect.Value, typ reflect.Type, ctx *objec
 		if err != nil {
 			return err
 		}
+		if !isHashable(keyVal) {
+			return fmt.Errorf("could not use %v as a key of %v: the exported value is not hashable", entry.key, typ)
+		}
+		if dst.MapIndex(keyVal).IsValid() {
+			return fmt.Errorf("could not use %v as a key of %v: duplicate key %v", entry.key, typ, keyVal)
+		}
 		elemVal := reflect.New(elemTyp).Elem()
 		err = r.toReflectValue(entry.value, elemVal, ctx)
 		if err != nil {
@@ -94,6 +101,30 @@ func (mo *mapObject) exportToMap(dst reflect.Value, typ reflect.Type, ctx *objec
 	return nil
 }
 
+// isHashable returns true if v can be used as a map key, i.e. it does not contain values of incomparable
+// types stored in interfaces.
+func isHashable(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Interface:
+		return v.IsNil() || isHashable(v.Elem())
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !isHashable(v.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if !isHashable(v.Index(i)) {
+				return false
+			}
+		}
+		return true
+	}
+	return v.Type().Comparable()
+}
+
 func (r *Runtime) mapProto_clear(call FunctionCall) Value {
 	thisObj := r.toObject(call.This)
 	mo, ok := thisObj.self.(*mapObject)
@@ -102,6 +102,42 @@ func TestMapExportToNonNilMap(t *testing.T) {
 	}
 }
 
+func TestMapExportToKeyTypes(t *testing.T) {
+	type key struct {
+		X, Y int
+	}
+	vm := New()
+	res, err := vm.RunString(`new Map([[{X: 1, Y: 2}, "a"], [{X: 3}, "b"]])`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[key]string
+	if err := vm.ExportTo(res, &m); err != nil {
+		t.Fatal(err)
+	}
+	if len(m) != 2 || m[key{1, 2}] != "a" || m[key{3, 0}] != "b" {
+		t.Fatal(m)
+	}
+
+	res, err = vm.RunString(`new Map([[1, "a"], ["1", "b"]])`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m1 map[float64]string
+	if err := vm.ExportTo(res, &m1); err == nil {
+		t.Fatal("expected an error for duplicate keys")
+	}
+
+	res, err = vm.RunString(`new Map([[{}, 1]])`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m2 map[interface{}]int
+	if err := vm.ExportTo(res, &m2); err == nil {
+		t.Fatal("expected an error for an unhashable key")
+	}
+}
+
 func TestMapGetAdderGetIteratorOrder(t *testing.T) {
 	const SCRIPT = `
 	let getterCalled = 0;
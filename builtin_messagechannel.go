@@ -0,0 +1,408 @@
+package goja
+
+import (
+	"errors"
+	"sync"
+)
+
+// messageChannel is the state shared by the two ends of a channel.
+type messageChannel struct {
+	mu sync.Mutex
+}
+
+// MessagePort is one end of a channel created by NewMessageChannel or by the MessageChannel constructor. Unlike
+// the MessagePort JavaScript objects it is not tied to a Runtime: it can be passed to another goroutine and bound
+// to a Loop with Loop.WrapMessagePort. Use Loop.UnwrapMessagePort to take a port away from a Runtime.
+type MessagePort struct {
+	ch *messageChannel
+
+	// the fields below are guarded by ch.mu
+
+	// the entangled port, nil once the channel is closed
+	peer *MessagePort
+	// the loop delivering the messages and the object the messages are delivered to, nil while the port is not
+	// bound to a loop
+	owner *Loop
+	obj   *messagePortObject
+	// set while the port is bound to a loop or is being transferred between loops
+	taken bool
+
+	queue     []clonedMessage
+	started   bool
+	scheduled bool
+}
+
+type messagePortObject struct {
+	baseObject
+
+	loop *Loop
+	// nil once the port has been transferred
+	port      *MessagePort
+	onmessage Value
+}
+
+type messageChannelObject struct {
+	baseObject
+
+	port1, port2 *Object
+}
+
+// NewMessageChannel creates a pair of entangled ports. The messages posted to one of them are delivered to the
+// other one.
+func NewMessageChannel() (*MessagePort, *MessagePort) {
+	ch := &messageChannel{}
+	p1 := &MessagePort{ch: ch}
+	p2 := &MessagePort{ch: ch, peer: p1}
+	p1.peer = p2
+	return p1, p2
+}
+
+// Close disentangles the port. The messages which have not been delivered to it are discarded and the messages
+// posted to either end after that are dropped. It is goroutine-safe.
+func (p *MessagePort) Close() {
+	p.ch.mu.Lock()
+	peer := p.peer
+	if peer == nil {
+		p.ch.mu.Unlock()
+		return
+	}
+	p.peer, peer.peer = nil, nil
+	queue := p.queue
+	p.queue = nil
+	if l := peer.owner; l != nil {
+		// wake the loop up, it may no longer be kept alive by the peer
+		l.post(func() error {
+			return nil
+		})
+	}
+	p.ch.mu.Unlock()
+	for _, m := range queue {
+		m.discard()
+	}
+}
+
+// send queues the message for delivery to the entangled port.
+func (p *MessagePort) send(m clonedMessage) {
+	p.ch.mu.Lock()
+	peer := p.peer
+	if peer == nil {
+		p.ch.mu.Unlock()
+		m.discard()
+		return
+	}
+	peer.queue = append(peer.queue, m)
+	peer.scheduleLocked()
+	p.ch.mu.Unlock()
+}
+
+// scheduleLocked posts a task delivering the next queued message to the owner loop. The channel mutex must be
+// held.
+func (p *MessagePort) scheduleLocked() {
+	if l := p.owner; l != nil && p.started && !p.scheduled && len(p.queue) > 0 {
+		p.scheduled = true
+		l.post(func() error {
+			return l.deliverMessage(p)
+		})
+	}
+}
+
+// discard closes the ports transferred with a message which will never be delivered.
+func (m clonedMessage) discard() {
+	for _, c := range m.ports {
+		c.port.Close()
+	}
+}
+
+// deliverMessage runs the onmessage handler for the first queued message. Each message is delivered by a separate
+// task, so the promise jobs scheduled by the handler run before the next message is delivered.
+func (l *Loop) deliverMessage(p *MessagePort) error {
+	p.ch.mu.Lock()
+	if p.owner != l {
+		// transferred to another loop, which is responsible for the delivery now
+		p.ch.mu.Unlock()
+		return nil
+	}
+	p.scheduled = false
+	if !p.started || len(p.queue) == 0 {
+		p.ch.mu.Unlock()
+		return nil
+	}
+	m := p.queue[0]
+	p.queue[0] = clonedMessage{}
+	p.queue = p.queue[1:]
+	p.scheduleLocked()
+	o := p.obj
+	p.ch.mu.Unlock()
+
+	data, ports := l.deserializeMessage(m)
+	fn, ok := AssertFunction(o.onmessage)
+	if !ok {
+		return nil
+	}
+	_, err := fn(o.val, l.r.newMessageEvent(data, ports))
+	return err
+}
+
+// deserializeMessage binds the ports transferred with the message to the loop and creates the message data.
+func (l *Loop) deserializeMessage(m clonedMessage) (Value, []Value) {
+	memo := make(map[*clonedObject]*Object, len(m.ports))
+	ports := make([]Value, len(m.ports))
+	for i, c := range m.ports {
+		o := l.newMessagePort(c.port)
+		memo[c] = o
+		ports[i] = o
+	}
+	return l.r.deserializeCloned(m.data, memo), ports
+}
+
+func (l *Loop) newMessagePort(p *MessagePort) *Object {
+	r := l.r
+	r.initMessagePorts()
+	o := &messagePortObject{loop: l, port: p, onmessage: _null}
+	o.class = classObject
+	o.val = &Object{runtime: r, self: o}
+	o.extensible = true
+	o.prototype = r.global.MessagePortPrototype
+	o.init()
+
+	p.ch.mu.Lock()
+	p.owner = l
+	p.obj = o
+	p.taken = true
+	p.scheduleLocked()
+	p.ch.mu.Unlock()
+	l.ports[o] = struct{}{}
+	return o.val
+}
+
+// ship unbinds the port from the loop when it is transferred. The messages which arrive before it is bound to
+// another loop are queued.
+func (o *messagePortObject) ship() *MessagePort {
+	p := o.port
+	o.port = nil
+	delete(o.loop.ports, o)
+	p.ch.mu.Lock()
+	p.owner = nil
+	p.obj = nil
+	p.started = false
+	p.scheduled = false
+	p.ch.mu.Unlock()
+	return p
+}
+
+// keepsAlive returns true if the port may receive messages from a different loop or from Go code.
+func (o *messagePortObject) keepsAlive() bool {
+	if _, ok := AssertFunction(o.onmessage); !ok || o.port == nil {
+		return false
+	}
+	p := o.port
+	p.ch.mu.Lock()
+	defer p.ch.mu.Unlock()
+	return p.started && p.peer != nil && p.peer.owner != o.loop
+}
+
+func (l *Loop) portsAlive() bool {
+	for o := range l.ports {
+		if o.keepsAlive() {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *messagePortObject) start() {
+	if p := o.port; p != nil {
+		p.ch.mu.Lock()
+		p.started = true
+		p.scheduleLocked()
+		p.ch.mu.Unlock()
+	}
+}
+
+func (r *Runtime) thisMessagePort(this Value, method string) *messagePortObject {
+	if o, ok := this.(*Object); ok {
+		if p, ok := o.self.(*messagePortObject); ok {
+			return p
+		}
+	}
+	panic(r.NewTypeError("Method MessagePort.prototype.%s called on incompatible receiver %s", method, r.objectproto_toString(FunctionCall{This: this})))
+}
+
+func (r *Runtime) messagePortProto_postMessage(call FunctionCall) Value {
+	o := r.thisMessagePort(call.This, "postMessage")
+	transfer := r.transferList(call.Argument(1))
+	for _, t := range transfer.ports {
+		if t == o {
+			panic(r.newDataCloneError("The transfer list contains the source port"))
+		}
+	}
+	m := r.structuredSerialize(call.Argument(0), transfer)
+	if o.port == nil {
+		m.discard()
+		return _undefined
+	}
+	o.port.send(m)
+	return _undefined
+}
+
+func (r *Runtime) messagePortProto_start(call FunctionCall) Value {
+	r.thisMessagePort(call.This, "start").start()
+	return _undefined
+}
+
+func (r *Runtime) messagePortProto_close(call FunctionCall) Value {
+	o := r.thisMessagePort(call.This, "close")
+	if o.port != nil {
+		o.port.Close()
+		delete(o.loop.ports, o)
+	}
+	return _undefined
+}
+
+func (r *Runtime) messagePortProto_getOnmessage(call FunctionCall) Value {
+	return r.thisMessagePort(call.This, "onmessage").onmessage
+}
+
+func (r *Runtime) messagePortProto_setOnmessage(call FunctionCall) Value {
+	o := r.thisMessagePort(call.This, "onmessage")
+	if _, ok := AssertFunction(call.Argument(0)); ok {
+		o.onmessage = call.Argument(0)
+		o.start()
+	} else {
+		o.onmessage = _null
+	}
+	return _undefined
+}
+
+func (r *Runtime) builtin_newMessagePort(args []Value, newTarget *Object) *Object {
+	panic(r.NewTypeError("Illegal constructor"))
+}
+
+func (r *Runtime) createMessagePortProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("constructor", r.global.MessagePort, true, false, true)
+	o._putProp("postMessage", r.newNativeFunc(r.messagePortProto_postMessage, nil, "postMessage", nil, 1), true, true, true)
+	o._putProp("start", r.newNativeFunc(r.messagePortProto_start, nil, "start", nil, 0), true, true, true)
+	o._putProp("close", r.newNativeFunc(r.messagePortProto_close, nil, "close", nil, 0), true, true, true)
+	r.putWebIDLAccessor(o, "onmessage", r.messagePortProto_getOnmessage, r.messagePortProto_setOnmessage)
+	o._putSym(SymToStringTag, valueProp(asciiString("MessagePort"), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) createMessagePort(val *Object) objectImpl {
+	return r.newNativeConstructOnly(val, r.builtin_newMessagePort, r.global.MessagePortPrototype, "MessagePort", 0)
+}
+
+// initMessagePorts creates the MessagePort prototype, which is needed when a port is transferred to the Runtime
+// even if the MessageChannel global has not been added.
+func (r *Runtime) initMessagePorts() {
+	if r.global.MessagePort != nil {
+		return
+	}
+	r.global.MessagePortPrototype = r.newLazyObject(r.createMessagePortProto)
+	r.global.MessagePort = r.newLazyObject(r.createMessagePort)
+}
+
+func (l *Loop) builtin_newMessageChannel(args []Value, newTarget *Object) *Object {
+	r := l.r
+	if newTarget == nil {
+		panic(r.needNew("MessageChannel"))
+	}
+	p1, p2 := NewMessageChannel()
+	c := &messageChannelObject{}
+	c.class = classObject
+	c.val = &Object{runtime: r, self: c}
+	c.extensible = true
+	c.prototype = r.getPrototypeFromCtor(newTarget, r.global.MessageChannel, r.global.MessageChannelPrototype)
+	c.init()
+	c.port1 = l.newMessagePort(p1)
+	c.port2 = l.newMessagePort(p2)
+	return c.val
+}
+
+func (r *Runtime) thisMessageChannel(this Value, method string) *messageChannelObject {
+	if o, ok := this.(*Object); ok {
+		if c, ok := o.self.(*messageChannelObject); ok {
+			return c
+		}
+	}
+	panic(r.NewTypeError("Method MessageChannel.prototype.%s called on incompatible receiver %s", method, r.objectproto_toString(FunctionCall{This: this})))
+}
+
+func (r *Runtime) messageChannelProto_getPort1(call FunctionCall) Value {
+	return r.thisMessageChannel(call.This, "port1").port1
+}
+
+func (r *Runtime) messageChannelProto_getPort2(call FunctionCall) Value {
+	return r.thisMessageChannel(call.This, "port2").port2
+}
+
+func (r *Runtime) createMessageChannelProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("constructor", r.global.MessageChannel, true, false, true)
+	r.putWebIDLAccessor(o, "port1", r.messageChannelProto_getPort1, nil)
+	r.putWebIDLAccessor(o, "port2", r.messageChannelProto_getPort2, nil)
+	o._putSym(SymToStringTag, valueProp(asciiString("MessageChannel"), false, false, true))
+
+	return o
+}
+
+func (l *Loop) createMessageChannel(val *Object) objectImpl {
+	return l.r.newNativeConstructOnly(val, l.builtin_newMessageChannel, l.r.global.MessageChannelPrototype, "MessageChannel", 0)
+}
+
+// EnableMessageChannels adds the MessageChannel and MessagePort globals to the Runtime of the loop. The ports
+// can be passed to workers (see EnableWorkers) or to other ports by listing them in the transfer argument of
+// postMessage(), the messages are copied using the structured clone algorithm. The onmessage handler of a port
+// receives a plain event object with the 'type', 'data' and 'ports' properties, setting the handler starts the
+// delivery of the messages. addEventListener() and the messageerror event are not supported.
+//
+// A port with an onmessage handler keeps the loop running while its peer is bound to a different loop, is being
+// transferred or is held by Go code, until either end is closed. Use WrapMessagePort and UnwrapMessagePort to
+// pass the ports to and from Go code.
+func (l *Loop) EnableMessageChannels() {
+	r := l.r
+	if r.global.MessageChannel != nil {
+		return
+	}
+	r.initMessagePorts()
+	r.global.MessageChannelPrototype = r.newLazyObject(r.createMessageChannelProto)
+	r.global.MessageChannel = r.newLazyObject(l.createMessageChannel)
+	r.addToGlobal("MessageChannel", r.global.MessageChannel)
+	r.addToGlobal("MessagePort", r.global.MessagePort)
+}
+
+// WrapMessagePort binds the port to the loop and returns a MessagePort object for it. It returns an error if the
+// port is already bound to a loop or is being transferred. Like the rest of the Loop methods it must be called on
+// the goroutine running the loop.
+func (l *Loop) WrapMessagePort(p *MessagePort) (*Object, error) {
+	p.ch.mu.Lock()
+	if p.taken {
+		p.ch.mu.Unlock()
+		return nil, errors.New("the MessagePort is already in use")
+	}
+	p.taken = true
+	p.ch.mu.Unlock()
+	return l.newMessagePort(p), nil
+}
+
+// UnwrapMessagePort unbinds a MessagePort object of the loop from its port, in the same way transferring it
+// does, and returns the port. The messages which arrive before the port is bound to a loop again are queued.
+func (l *Loop) UnwrapMessagePort(v Value) (*MessagePort, error) {
+	if o, ok := v.(*Object); ok {
+		if po, ok := o.self.(*messagePortObject); ok && po.loop == l {
+			if po.port == nil {
+				return nil, errors.New("the MessagePort has been transferred")
+			}
+			p := po.ship()
+			p.ch.mu.Lock()
+			p.taken = false
+			p.ch.mu.Unlock()
+			return p, nil
+		}
+	}
+	return nil, errors.New("not a MessagePort of this loop")
+}
@@ -0,0 +1,196 @@
+package goja
+
+import (
+	"testing"
+)
+
+func TestMessageChannel(t *testing.T) {
+	r := New()
+	l := NewLoop(r)
+	l.EnableMessageChannels()
+	err := l.Run(func(r *Runtime) {
+		_, err := r.RunString(`
+		var received = [];
+		var ch = new MessageChannel();
+		ch.port1.onmessage = function(e) {
+			received.push(e.data);
+			Promise.resolve().then(function() { received.push("job") });
+		};
+		var obj = { n: 1 };
+		ch.port2.postMessage(obj);
+		ch.port2.postMessage("two");
+		obj.n = 2;
+
+		var late = new MessageChannel(), lateData;
+		late.port1.postMessage("queued");
+		late.port2.start();
+		late.port2.onmessage = function(e) { lateData = e.data };
+
+		var closed = new MessageChannel(), closedData;
+		closed.port1.onmessage = function(e) { closedData = e.data };
+		closed.port2.close();
+		closed.port2.postMessage("lost");
+		`)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := r.RunString(`
+	received.length === 4 && received[0].n === 1 && received[0] !== obj && received[1] === "job" && received[2] === "two" &&
+		lateData === "queued" && closedData === undefined;
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.ToBoolean() {
+		t.Fatal(r.Get("received"))
+	}
+}
+
+func TestMessageChannelErrors(t *testing.T) {
+	r := New()
+	l := NewLoop(r)
+	l.EnableMessageChannels()
+	err := l.Run(func(r *Runtime) {
+		_, err := r.RunString(`
+		function assertCloneError(f, msg) {
+			try {
+				f();
+			} catch (e) {
+				if (e.name === "DataCloneError") return;
+				throw e;
+			}
+			throw new Error(msg + ": no exception");
+		}
+		var { port1, port2 } = new MessageChannel();
+		var other = new MessageChannel();
+		assertCloneError(() => port1.postMessage(1, [port1]), "source port");
+		assertCloneError(() => port1.postMessage(other.port1), "not transferred");
+		assertCloneError(() => port1.postMessage(1, [other.port1, other.port1]), "duplicate");
+		port1.postMessage(other.port1, [other.port1]);
+		assertCloneError(() => port1.postMessage(1, [other.port1]), "transferred");
+		try {
+			new MessagePort();
+			throw new Error("MessagePort is constructible");
+		} catch (e) {
+			if (!(e instanceof TypeError)) throw e;
+		}
+		if (Object.prototype.toString.call(port1) !== "[object MessagePort]") throw new Error("toStringTag");
+		`)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMessagePortTransferToWorker(t *testing.T) {
+	l := newWorkerTestLoop(t, map[string]string{
+		"port.js": `
+		onmessage = function(e) {
+			var port = e.ports[0];
+			if (port !== e.data.port) throw new Error("ports differ");
+			port.onmessage = function(e) {
+				port.postMessage(e.data * 2);
+				port.close();
+				close();
+			};
+		};
+		`,
+	})
+	l.EnableMessageChannels()
+	var res Value
+	err := l.Run(func(r *Runtime) {
+		_, err := r.RunString(`
+		var w = new Worker("port.js");
+		var ch = new MessageChannel();
+		ch.port1.onmessage = function(e) {
+			res = e.data;
+		};
+		w.postMessage({ port: ch.port2 }, [ch.port2]);
+		ch.port1.postMessage(21);
+		var res;
+		`)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res = l.r.Get("res")
+	if res.ToInteger() != 42 {
+		t.Fatal(res)
+	}
+}
+
+func TestGoMessagePorts(t *testing.T) {
+	p1, p2 := NewMessageChannel()
+
+	receiver := NewLoop(New())
+	done := make(chan error, 1)
+	go func() {
+		done <- receiver.Run(func(r *Runtime) {
+			port, err := receiver.WrapMessagePort(p2)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			r.Set("port", port)
+			_, err = r.RunString(`
+			var received = [];
+			port.onmessage = function(e) {
+				received.push(e.data);
+				if (received.length === 3) port.close();
+			};
+			`)
+			if err != nil {
+				t.Error(err)
+			}
+		})
+	}()
+
+	sender := NewLoop(New())
+	err := sender.Run(func(r *Runtime) {
+		port, err := sender.WrapMessagePort(p1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := sender.WrapMessagePort(p1); err == nil {
+			t.Fatal("expected an error")
+		}
+		r.Set("port", port)
+		if _, err := r.RunString(`port.postMessage("a"); port.postMessage("b")`); err != nil {
+			t.Fatal(err)
+		}
+		p, err := sender.UnwrapMessagePort(port)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := sender.UnwrapMessagePort(port); err == nil {
+			t.Fatal("expected an error")
+		}
+		port, err = sender.WrapMessagePort(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Set("port", port)
+		if _, err := r.RunString(`port.postMessage("c")`); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if s := receiver.r.Get("received").String(); s != "a,b,c" {
+		t.Fatal(s)
+	}
+}
@@ -2,6 +2,7 @@ package goja
 
 import (
 	"math"
+	"strings"
 
 	"github.com/dop251/goja/ftoa"
 )
@@ -72,6 +73,34 @@ func (r *Runtime) numberproto_toString(call FunctionCall) Value {
 	return asciiString(ftoa.FToBaseStr(num, radix))
 }
 
+// numberproto_toLocaleString formats the number like Intl.NumberFormat with the decimal style would, using the
+// symbols supplied by the LocaleProvider. The digit options and the useGrouping option are supported.
+func (r *Runtime) numberproto_toLocaleString(call FunctionCall) Value {
+	num := r.toNumber(call.This).ToFloat()
+	_, sym := r.localeNumberSymbols(r.intlCanonicalizeLocaleList(call.Argument(0)))
+	opts := r.intlGetOptionsObject(call.Argument(1))
+	digits := r.intlGetDigitOptions(opts, 0, 3)
+	if useGrouping, ok := r.intlGetBoolOption(opts, "useGrouping"); ok && !useGrouping {
+		sym.MinimumGroupingDigits = math.MaxInt32
+	}
+
+	if math.IsNaN(num) {
+		return stringNaN
+	}
+	var b strings.Builder
+	if num < 0 || num == 0 && math.Signbit(num) {
+		b.WriteByte('-')
+	}
+	if math.IsInf(num, 0) {
+		b.WriteString("∞")
+	} else {
+		for _, p := range digits.round(math.Abs(num)).parts(digits.minInt, sym) {
+			b.WriteString(p.value)
+		}
+	}
+	return newStringValue(b.String())
+}
+
 func (r *Runtime) numberproto_toFixed(call FunctionCall) Value {
 	num := r.toNumber(call.This).ToFloat()
 	prec := call.Argument(0).ToInteger()
@@ -184,7 +213,7 @@ func (r *Runtime) initNumber() {
 	o := r.global.NumberPrototype.self
 	o._putProp("toExponential", r.newNativeFunc(r.numberproto_toExponential, nil, "toExponential", nil, 1), true, false, true)
 	o._putProp("toFixed", r.newNativeFunc(r.numberproto_toFixed, nil, "toFixed", nil, 1), true, false, true)
-	o._putProp("toLocaleString", r.newNativeFunc(r.numberproto_toString, nil, "toLocaleString", nil, 0), true, false, true)
+	o._putProp("toLocaleString", r.newNativeFunc(r.numberproto_toLocaleString, nil, "toLocaleString", nil, 0), true, false, true)
 	o._putProp("toPrecision", r.newNativeFunc(r.numberproto_toPrecision, nil, "toPrecision", nil, 1), true, false, true)
 	o._putProp("toString", r.newNativeFunc(r.numberproto_toString, nil, "toString", nil, 1), true, false, true)
 	o._putProp("valueOf", r.newNativeFunc(r.numberproto_valueOf, nil, "valueOf", nil, 0), true, false, true)
@@ -0,0 +1,397 @@
+package goja
+
+import (
+	"sort"
+	"time"
+)
+
+// PerformanceClock returns the monotonic time elapsed since the time origin of a Runtime (see
+// Runtime.EnablePerformance).
+type PerformanceClock func() time.Duration
+
+type performanceState struct {
+	timeOrigin float64
+	clock      PerformanceClock
+	entries    []*performanceEntryObject
+}
+
+type performanceEntryObject struct {
+	baseObject
+	name      string
+	entryType string
+	startTime float64
+	duration  float64
+	detail    Value
+}
+
+func (r *Runtime) performanceNow() float64 {
+	return float64(r.performance.clock()) / float64(time.Millisecond)
+}
+
+func (r *Runtime) newPerformanceEntry(proto *Object, name, entryType string, startTime, duration float64, detail Value) *performanceEntryObject {
+	e := &performanceEntryObject{
+		name:      name,
+		entryType: entryType,
+		startTime: startTime,
+		duration:  duration,
+		detail:    detail,
+	}
+	e.class = classObject
+	e.val = &Object{runtime: r, self: e}
+	e.extensible = true
+	e.prototype = proto
+	e.init()
+	return e
+}
+
+func (r *Runtime) thisPerformanceEntry(this Value, method string) *performanceEntryObject {
+	if o, ok := this.(*Object); ok {
+		if e, ok := o.self.(*performanceEntryObject); ok {
+			return e
+		}
+	}
+	panic(r.NewTypeError("Method PerformanceEntry.prototype.%s called on incompatible receiver %s", method, r.objectproto_toString(FunctionCall{This: this})))
+}
+
+func (r *Runtime) performanceEntryProto_getName(call FunctionCall) Value {
+	return newStringValue(r.thisPerformanceEntry(call.This, "name").name)
+}
+
+func (r *Runtime) performanceEntryProto_getEntryType(call FunctionCall) Value {
+	return asciiString(r.thisPerformanceEntry(call.This, "entryType").entryType)
+}
+
+func (r *Runtime) performanceEntryProto_getStartTime(call FunctionCall) Value {
+	return floatToValue(r.thisPerformanceEntry(call.This, "startTime").startTime)
+}
+
+func (r *Runtime) performanceEntryProto_getDuration(call FunctionCall) Value {
+	return floatToValue(r.thisPerformanceEntry(call.This, "duration").duration)
+}
+
+func (r *Runtime) performanceEntryProto_getDetail(call FunctionCall) Value {
+	return nilSafe(r.thisPerformanceEntry(call.This, "detail").detail)
+}
+
+func (r *Runtime) performanceEntryProto_toJSON(call FunctionCall) Value {
+	e := r.thisPerformanceEntry(call.This, "toJSON")
+	o := r.NewObject()
+	o.self._putProp("name", newStringValue(e.name), true, true, true)
+	o.self._putProp("entryType", asciiString(e.entryType), true, true, true)
+	o.self._putProp("startTime", floatToValue(e.startTime), true, true, true)
+	o.self._putProp("duration", floatToValue(e.duration), true, true, true)
+	if e.entryType != "resource" {
+		o.self._putProp("detail", nilSafe(e.detail), true, true, true)
+	}
+	return o
+}
+
+func (r *Runtime) builtin_newPerformanceEntry(args []Value, newTarget *Object) *Object {
+	panic(r.NewTypeError("Illegal constructor"))
+}
+
+// createMark implements https://w3c.github.io/user-timing/#the-performancemark-constructor
+func (r *Runtime) createMark(name Value, options Value, proto *Object) *performanceEntryObject {
+	startTime := r.performanceNow()
+	var detail Value = _null
+	if opts := r.webIDLDictionary(options); opts != nil {
+		if v := opts.self.getStr("startTime", nil); v != nil && v != _undefined {
+			startTime = v.ToFloat()
+			if startTime < 0 {
+				panic(r.NewTypeError("The startTime must not be negative"))
+			}
+		}
+		if v := opts.self.getStr("detail", nil); v != nil && v != _undefined {
+			detail = v
+		}
+	}
+	return r.newPerformanceEntry(proto, name.String(), "mark", startTime, 0, detail)
+}
+
+func (r *Runtime) builtin_newPerformanceMark(args []Value, newTarget *Object) *Object {
+	if newTarget == nil {
+		panic(r.needNew("PerformanceMark"))
+	}
+	proto := r.getPrototypeFromCtor(newTarget, r.global.PerformanceMark, r.global.PerformanceMarkPrototype)
+	var name, options Value = _undefined, _undefined
+	if len(args) > 0 {
+		name = args[0]
+	}
+	if len(args) > 1 {
+		options = args[1]
+	}
+	return r.createMark(name, options, proto).val
+}
+
+func (r *Runtime) performance_now(call FunctionCall) Value {
+	return floatToValue(r.performanceNow())
+}
+
+func (r *Runtime) performance_getTimeOrigin(call FunctionCall) Value {
+	return floatToValue(r.performance.timeOrigin)
+}
+
+func (r *Runtime) performance_toJSON(call FunctionCall) Value {
+	o := r.NewObject()
+	o.self._putProp("timeOrigin", floatToValue(r.performance.timeOrigin), true, true, true)
+	return o
+}
+
+func (r *Runtime) performance_mark(call FunctionCall) Value {
+	e := r.createMark(call.Argument(0), call.Argument(1), r.global.PerformanceMarkPrototype)
+	r.performance.entries = append(r.performance.entries, e)
+	return e.val
+}
+
+// markTimestamp converts a mark name or a timestamp to a timestamp, see
+// https://w3c.github.io/user-timing/#convert-a-mark-to-a-timestamp
+func (r *Runtime) markTimestamp(mark Value) float64 {
+	if s, ok := mark.(valueString); ok {
+		name := s.String()
+		entries := r.performance.entries
+		for i := len(entries) - 1; i >= 0; i-- {
+			if e := entries[i]; e.entryType == "mark" && e.name == name {
+				return e.startTime
+			}
+		}
+		panic(r.newError(r.global.SyntaxError, "The mark '%s' does not exist", name))
+	}
+	t := mark.ToFloat()
+	if t < 0 {
+		panic(r.NewTypeError("The timestamp must not be negative"))
+	}
+	return t
+}
+
+// performance_measure implements https://w3c.github.io/user-timing/#dom-performance-measure
+func (r *Runtime) performance_measure(call FunctionCall) Value {
+	name := call.Argument(0).String()
+	startOrOptions, endMark := call.Argument(1), call.Argument(2)
+	var start, end, duration Value = _undefined, _undefined, _undefined
+	var detail Value = _null
+	if o, ok := startOrOptions.(*Object); ok {
+		start = nilSafe(o.self.getStr("start", nil))
+		end = nilSafe(o.self.getStr("end", nil))
+		duration = nilSafe(o.self.getStr("duration", nil))
+		if v := nilSafe(o.self.getStr("detail", nil)); v != _undefined {
+			detail = v
+		}
+		if start != _undefined || end != _undefined || duration != _undefined || detail != _null {
+			if start == _undefined && end == _undefined {
+				panic(r.NewTypeError("Either start or end must be specified"))
+			}
+			if start != _undefined && end != _undefined && duration != _undefined {
+				panic(r.NewTypeError("Cannot specify start, end and duration together"))
+			}
+			if endMark != _undefined {
+				panic(r.NewTypeError("endMark cannot be specified together with options"))
+			}
+		} else {
+			end = endMark
+		}
+	} else {
+		start = startOrOptions
+		end = endMark
+	}
+
+	var endTime float64
+	switch {
+	case end != _undefined:
+		endTime = r.markTimestamp(end)
+	case start != _undefined && duration != _undefined:
+		endTime = r.markTimestamp(start) + duration.ToFloat()
+	default:
+		endTime = r.performanceNow()
+	}
+	var startTime float64
+	switch {
+	case start != _undefined:
+		startTime = r.markTimestamp(start)
+	case duration != _undefined && end != _undefined:
+		startTime = endTime - duration.ToFloat()
+	}
+
+	e := r.newPerformanceEntry(r.global.PerformanceMeasurePrototype, name, "measure", startTime, endTime-startTime, detail)
+	r.performance.entries = append(r.performance.entries, e)
+	return e.val
+}
+
+func (r *Runtime) performanceEntryList(filter func(e *performanceEntryObject) bool) Value {
+	var entries []*performanceEntryObject
+	for _, e := range r.performance.entries {
+		if filter(e) {
+			entries = append(entries, e)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].startTime < entries[j].startTime
+	})
+	values := make([]Value, len(entries))
+	for i, e := range entries {
+		values[i] = e.val
+	}
+	return r.newArrayValues(values)
+}
+
+func (r *Runtime) performance_getEntries(call FunctionCall) Value {
+	return r.performanceEntryList(func(*performanceEntryObject) bool {
+		return true
+	})
+}
+
+func (r *Runtime) performance_getEntriesByName(call FunctionCall) Value {
+	name := call.Argument(0).String()
+	typ := call.Argument(1)
+	return r.performanceEntryList(func(e *performanceEntryObject) bool {
+		return e.name == name && (typ == _undefined || e.entryType == typ.String())
+	})
+}
+
+func (r *Runtime) performance_getEntriesByType(call FunctionCall) Value {
+	typ := call.Argument(0).String()
+	return r.performanceEntryList(func(e *performanceEntryObject) bool {
+		return e.entryType == typ
+	})
+}
+
+func (r *Runtime) clearPerformanceEntries(entryType string, name Value) {
+	entries := r.performance.entries[:0]
+	for _, e := range r.performance.entries {
+		if e.entryType == entryType && (name == _undefined || e.name == name.String()) {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	for i := len(entries); i < len(r.performance.entries); i++ {
+		r.performance.entries[i] = nil
+	}
+	r.performance.entries = entries
+}
+
+func (r *Runtime) performance_clearMarks(call FunctionCall) Value {
+	r.clearPerformanceEntries("mark", call.Argument(0))
+	return _undefined
+}
+
+func (r *Runtime) performance_clearMeasures(call FunctionCall) Value {
+	r.clearPerformanceEntries("measure", call.Argument(0))
+	return _undefined
+}
+
+func (r *Runtime) createPerformanceEntryProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("constructor", r.global.PerformanceEntry, true, false, true)
+	r.putWebIDLAccessor(o, "name", r.performanceEntryProto_getName, nil)
+	r.putWebIDLAccessor(o, "entryType", r.performanceEntryProto_getEntryType, nil)
+	r.putWebIDLAccessor(o, "startTime", r.performanceEntryProto_getStartTime, nil)
+	r.putWebIDLAccessor(o, "duration", r.performanceEntryProto_getDuration, nil)
+	o._putProp("toJSON", r.newNativeFunc(r.performanceEntryProto_toJSON, nil, "toJSON", nil, 0), true, true, true)
+	o._putSym(SymToStringTag, valueProp(asciiString("PerformanceEntry"), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) createPerformanceEntry(val *Object) objectImpl {
+	return r.newNativeConstructOnly(val, r.builtin_newPerformanceEntry, r.global.PerformanceEntryPrototype, "PerformanceEntry", 0)
+}
+
+func (r *Runtime) createPerformanceMarkProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.PerformanceEntryPrototype, classObject)
+
+	o._putProp("constructor", r.global.PerformanceMark, true, false, true)
+	r.putWebIDLAccessor(o, "detail", r.performanceEntryProto_getDetail, nil)
+	o._putSym(SymToStringTag, valueProp(asciiString("PerformanceMark"), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) createPerformanceMark(val *Object) objectImpl {
+	o := r.newNativeConstructOnly(val, r.builtin_newPerformanceMark, r.global.PerformanceMarkPrototype, "PerformanceMark", 1)
+	o.prototype = r.global.PerformanceEntry
+	return o
+}
+
+func (r *Runtime) createPerformanceMeasureProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.PerformanceEntryPrototype, classObject)
+
+	o._putProp("constructor", r.global.PerformanceMeasure, true, false, true)
+	r.putWebIDLAccessor(o, "detail", r.performanceEntryProto_getDetail, nil)
+	o._putSym(SymToStringTag, valueProp(asciiString("PerformanceMeasure"), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) createPerformanceMeasure(val *Object) objectImpl {
+	o := r.newNativeConstructOnly(val, r.builtin_newPerformanceEntry, r.global.PerformanceMeasurePrototype, "PerformanceMeasure", 0)
+	o.prototype = r.global.PerformanceEntry
+	return o
+}
+
+func (r *Runtime) createPerformance(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("now", r.newNativeFunc(r.performance_now, nil, "now", nil, 0), true, true, true)
+	r.putWebIDLAccessor(o, "timeOrigin", r.performance_getTimeOrigin, nil)
+	o._putProp("toJSON", r.newNativeFunc(r.performance_toJSON, nil, "toJSON", nil, 0), true, true, true)
+	o._putProp("mark", r.newNativeFunc(r.performance_mark, nil, "mark", nil, 1), true, true, true)
+	o._putProp("measure", r.newNativeFunc(r.performance_measure, nil, "measure", nil, 1), true, true, true)
+	o._putProp("getEntries", r.newNativeFunc(r.performance_getEntries, nil, "getEntries", nil, 0), true, true, true)
+	o._putProp("getEntriesByName", r.newNativeFunc(r.performance_getEntriesByName, nil, "getEntriesByName", nil, 1), true, true, true)
+	o._putProp("getEntriesByType", r.newNativeFunc(r.performance_getEntriesByType, nil, "getEntriesByType", nil, 1), true, true, true)
+	o._putProp("clearMarks", r.newNativeFunc(r.performance_clearMarks, nil, "clearMarks", nil, 0), true, true, true)
+	o._putProp("clearMeasures", r.newNativeFunc(r.performance_clearMeasures, nil, "clearMeasures", nil, 0), true, true, true)
+	o._putSym(SymToStringTag, valueProp(asciiString("Performance"), false, false, true))
+
+	return o
+}
+
+// SetPerformanceClock sets the clock used by performance.now() and the User Timing methods. If not called, the
+// monotonic time elapsed since the call to EnablePerformance is used, nil resets the clock to the time elapsed
+// since this call. Use it together with SetTimeSource to make the timing deterministic.
+func (r *Runtime) SetPerformanceClock(clock PerformanceClock) {
+	if r.performance == nil {
+		r.performance = &performanceState{}
+	}
+	if clock == nil {
+		clock = elapsedClock()
+	}
+	r.performance.clock = clock
+}
+
+func elapsedClock() PerformanceClock {
+	start := time.Now()
+	return func() time.Duration {
+		return time.Since(start)
+	}
+}
+
+// EnablePerformance adds the performance global as described in https://w3c.github.io/hr-time/ and
+// https://w3c.github.io/user-timing/ along with the PerformanceEntry, PerformanceMark and PerformanceMeasure
+// classes. performance.timeOrigin is obtained from the time source (see SetTimeSource) when this method is called.
+// The mark and measure details are stored as they are rather than being cloned.
+func (r *Runtime) EnablePerformance() {
+	if r.global.Performance != nil {
+		return
+	}
+	if r.performance == nil {
+		r.performance = &performanceState{}
+	}
+	r.performance.timeOrigin = float64(r.now().UnixNano()) / float64(time.Millisecond)
+	if r.performance.clock == nil {
+		r.performance.clock = elapsedClock()
+	}
+
+	r.global.PerformanceEntryPrototype = r.newLazyObject(r.createPerformanceEntryProto)
+	r.global.PerformanceEntry = r.newLazyObject(r.createPerformanceEntry)
+	r.addToGlobal("PerformanceEntry", r.global.PerformanceEntry)
+	r.global.PerformanceMarkPrototype = r.newLazyObject(r.createPerformanceMarkProto)
+	r.global.PerformanceMark = r.newLazyObject(r.createPerformanceMark)
+	r.addToGlobal("PerformanceMark", r.global.PerformanceMark)
+	r.global.PerformanceMeasurePrototype = r.newLazyObject(r.createPerformanceMeasureProto)
+	r.global.PerformanceMeasure = r.newLazyObject(r.createPerformanceMeasure)
+	r.addToGlobal("PerformanceMeasure", r.global.PerformanceMeasure)
+
+	r.global.Performance = r.newLazyObject(r.createPerformance)
+	r.addToGlobal("performance", r.global.Performance)
+}
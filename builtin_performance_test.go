@@ -0,0 +1,96 @@
+package goja
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPerformanceDisabled(t *testing.T) {
+	testScript(`typeof performance === "undefined" && typeof PerformanceEntry === "undefined"`, valueTrue, t)
+}
+
+func TestPerformance(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue(performance.timeOrigin, 1500000000123.5, "timeOrigin");
+	assert.sameValue(performance.toJSON().timeOrigin, performance.timeOrigin, "toJSON");
+	assert.sameValue(performance.now(), 0, "now");
+	var m1 = performance.mark("a", {detail: {x: 1}});
+	assert(m1 instanceof PerformanceMark, "instanceof PerformanceMark");
+	assert(m1 instanceof PerformanceEntry, "instanceof PerformanceEntry");
+	assert.sameValue(m1.entryType, "mark");
+	assert.sameValue(m1.startTime, 0);
+	assert.sameValue(m1.duration, 0);
+	assert.sameValue(m1.detail.x, 1);
+	advance(10.5);
+	assert.sameValue(performance.now(), 10.5, "now after advance");
+	performance.mark("b");
+	advance(4);
+	performance.mark("a", {startTime: 1});
+
+	var m = performance.measure("a to b", "a", "b");
+	assert.sameValue(m.startTime, 1, "last mark with the name is used");
+	assert.sameValue(m.duration, 9.5);
+	assert.sameValue(m.detail, null);
+	m = performance.measure("to now");
+	assert.sameValue(m.startTime, 0);
+	assert.sameValue(m.duration, 14.5);
+	m = performance.measure("options", {start: "b", duration: 2, detail: "d"});
+	assert.sameValue(m.startTime, 10.5);
+	assert.sameValue(m.duration, 2);
+	assert.sameValue(m.detail, "d");
+	m = performance.measure("end", {end: 12, duration: 2});
+	assert.sameValue(m.startTime, 10);
+	assert(m instanceof PerformanceMeasure, "instanceof PerformanceMeasure");
+	assert.sameValue(JSON.stringify(m), '{"name":"end","entryType":"measure","startTime":10,"duration":2,"detail":null}');
+
+	assert.throws(SyntaxError, function() { performance.measure("x", "nope") }, "unknown mark");
+	assert.throws(TypeError, function() { performance.measure("x", {duration: 1}) }, "start or end");
+	assert.throws(TypeError, function() { performance.measure("x", {start: 0, end: 1, duration: 1}) }, "all three");
+	assert.throws(TypeError, function() { performance.mark("x", {startTime: -1}) }, "negative");
+
+	assert.sameValue(performance.getEntries().map(function(e) { return e.name }).join(), "a,to now,a,a to b,end,b,options");
+	assert.sameValue(performance.getEntriesByType("mark").length, 3);
+	assert.sameValue(performance.getEntriesByName("a", "mark").length, 2);
+	assert.sameValue(performance.getEntriesByName("a", "measure").length, 0);
+	performance.clearMarks("a");
+	assert.sameValue(performance.getEntriesByType("mark").length, 1);
+	performance.clearMeasures();
+	assert.sameValue(performance.getEntries().length, 1);
+
+	var mark = new PerformanceMark("standalone", {startTime: 5});
+	assert.sameValue(mark.startTime, 5);
+	assert.sameValue(performance.getEntries().length, 1, "the constructor does not record the mark");
+	assert.throws(TypeError, function() { new PerformanceEntry() });
+	assert.throws(TypeError, function() { new PerformanceMeasure() });
+	assert.sameValue(Object.prototype.toString.call(performance), "[object Performance]");
+	`
+	r := New()
+	r.SetTimeSource(func() time.Time {
+		return time.Unix(1500000000, 123500000)
+	})
+	var elapsed time.Duration
+	r.SetPerformanceClock(func() time.Duration {
+		return elapsed
+	})
+	r.Set("advance", func(ms float64) {
+		elapsed += time.Duration(ms * float64(time.Millisecond))
+	})
+	r.EnablePerformance()
+	r.testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestPerformanceNowMonotonic(t *testing.T) {
+	r := New()
+	r.EnablePerformance()
+	v, err := r.RunString(`
+	var t1 = performance.now();
+	var t2 = performance.now();
+	t1 >= 0 && t2 >= t1 && Math.abs(performance.timeOrigin - Date.now()) < 60000;
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !v.ToBoolean() {
+		t.Fatal("Unexpected result")
+	}
+}
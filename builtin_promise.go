@@ -17,7 +17,9 @@ const (
 )
 
 const (
+	// PromiseRejectionReject means that a promise has been rejected without any handlers.
 	PromiseRejectionReject PromiseRejectionOperation = iota
+	// PromiseRejectionHandle means that a handler has been added to a promise which was rejected without any.
 	PromiseRejectionHandle
 )
 
@@ -26,6 +28,8 @@ const (
 	promiseReactionReject
 )
 
+// PromiseRejectionTracker is the host hook called when a promise is rejected without a handler and when a handler
+// is added to such promise later, see Runtime.SetPromiseRejectionTracker.
 type PromiseRejectionTracker func(p *Promise, operation PromiseRejectionOperation)
 
 type jobCallback struct {
@@ -608,6 +612,12 @@ func (r *Runtime) NewPromise() (promise *Promise, resolve func(result interface{
 //
 // Setting a tracker replaces any existing one. Setting it to nil disables the functionality.
 //
+// The tracker is called synchronously, while the promise is being rejected or handled, so a promise reported with
+// PromiseRejectionReject may still get a handler before the control leaves the Runtime (e.g. a promise rejected and
+// awaited later in the same script). To find the rejections that remain unhandled, keep the reported promises until
+// they are reported with PromiseRejectionHandle and check what's left after RunString, RunProgram or a Callable call
+// returns, e.g. to log the reasons or to fail the request. A Loop (see NewLoop) does this after each task.
+//
 // See https://tc39.es/ecma262/#sec-host-promise-rejection-tracker for more details.
 func (r *Runtime) SetPromiseRejectionTracker(tracker PromiseRejectionTracker) {
 	r.promiseRejectionTracker = tracker
@@ -619,3 +629,39 @@ func (r *Runtime) SetPromiseRejectionTracker(tracker PromiseRejectionTracker) {
 func (r *Runtime) SetAsyncContextTracker(tracker AsyncContextTracker) {
 	r.asyncContextTracker = tracker
 }
+
+// SetManualJobs sets whether the promise jobs (the reactions of the promises, the continuations of the async
+// functions and the callbacks of queueMicrotask) are run by DrainJobs only. By default all the pending jobs are run
+// when control leaves the Runtime, i.e. when RunString, RunProgram or a Callable called from Go returns. With manual
+// jobs they are kept in the queue instead, so embedders which don't use a Loop can decide when and how many of them
+// run. The jobs are discarded if the Runtime is interrupted (see Interrupt). Disabling manual jobs doesn't run the
+// pending jobs, they run when control leaves the Runtime next time.
+func (r *Runtime) SetManualJobs(enabled bool) {
+	r.manualJobs = enabled
+}
+
+// PendingJobs returns the number of promise jobs waiting to be run, see SetManualJobs.
+func (r *Runtime) PendingJobs() int {
+	return len(r.jobQueue)
+}
+
+// DrainJobs runs the pending promise jobs in the order they have been enqueued, including the jobs enqueued by the
+// jobs it runs, until there are none left or maxN jobs have run (if maxN is positive). It returns the number of
+// jobs it has run. A script that keeps enqueuing jobs (e.g. an endless chain of promise reactions) can be detected
+// by PendingJobs returning a positive number after draining with a limit.
+//
+// An error is only returned if the Runtime has been interrupted, the exceptions thrown by the jobs reject their
+// promises (or are reported to the Loop for queueMicrotask callbacks). DrainJobs must not be called while the Runtime
+// is running.
+func (r *Runtime) DrainJobs(maxN int) (n int, err error) {
+	err = r.runWrapped(func() {
+		for len(r.jobQueue) > 0 && (maxN <= 0 || n < maxN) {
+			job := r.jobQueue[0]
+			r.jobQueue[0] = nil
+			r.jobQueue = r.jobQueue[1:]
+			job()
+			n++
+		}
+	})
+	return
+}
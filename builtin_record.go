@@ -0,0 +1,229 @@
+package goja
+
+import "github.com/dop251/goja/unistring"
+
+func (r *Runtime) checkRecordValue(v Value) Value {
+	if _, ok := v.(*Object); ok {
+		panic(r.NewTypeError("Records and Tuples cannot contain objects"))
+	}
+	return v
+}
+
+func (r *Runtime) builtin_Record(call FunctionCall) Value {
+	arg := call.Argument(0)
+	obj, ok := arg.(*Object)
+	if !ok {
+		panic(r.NewTypeError("Record() argument must be an object"))
+	}
+	var keys []valueString
+	var values []Value
+	for item, next := iterateEnumerableStringProperties(obj)(); next != nil; item, next = next() {
+		keys = append(keys, item.name.toString())
+		values = append(values, r.checkRecordValue(item.value))
+	}
+	if len(obj.self.symbols(false, nil)) > 0 {
+		panic(r.NewTypeError("Record may only have string as keys"))
+	}
+	return newRecord(keys, values)
+}
+
+func (r *Runtime) record_fromEntries(call FunctionCall) Value {
+	var keys []valueString
+	var values []Value
+	r.getIterator(call.Argument(0), nil).iterate(func(item Value) {
+		itemObj := r.toObject(item)
+		key := toPropertyKey(nilSafe(itemObj.self.getIdx(valueInt(0), nil)))
+		if _, ok := key.(*Symbol); ok {
+			panic(r.NewTypeError("Record may only have string as keys"))
+		}
+		keys = append(keys, key.toString())
+		values = append(values, r.checkRecordValue(nilSafe(itemObj.self.getIdx(valueInt(1), nil))))
+	})
+	return newRecord(keys, values)
+}
+
+func (r *Runtime) createRecord(val *Object) objectImpl {
+	o := r.newNativeFuncObj(val, r.builtin_Record, nil, "Record", nil, intToValue(1))
+	o._putProp("fromEntries", r.newNativeFunc(r.record_fromEntries, nil, "fromEntries", nil, 1), true, false, true)
+
+	return o
+}
+
+func (r *Runtime) newTuple(values []Value) *valueTuple {
+	for _, v := range values {
+		r.checkRecordValue(v)
+	}
+	return &valueTuple{values: values}
+}
+
+func (r *Runtime) tupleFromArrayLike(o *Object) *valueTuple {
+	l := toLength(o.self.getStr("length", nil))
+	values := make([]Value, l)
+	for i := range values {
+		values[i] = nilSafe(o.self.getIdx(valueInt(i), nil))
+	}
+	return r.newTuple(values)
+}
+
+func (r *Runtime) builtin_Tuple(call FunctionCall) Value {
+	return r.newTuple(append([]Value(nil), call.Arguments...))
+}
+
+func (r *Runtime) tuple_from(call FunctionCall) Value {
+	arr := r.array_from(FunctionCall{This: r.global.Array, Arguments: call.Arguments})
+	return r.tupleFromArrayLike(r.toObject(arr))
+}
+
+func (r *Runtime) thisTupleValue(v Value, method string) *valueTuple {
+	switch t := v.(type) {
+	case *valueTuple:
+		return t
+	case *Object:
+		if o, ok := t.self.(*tupleObject); ok {
+			return o.value
+		}
+	}
+	panic(r.NewTypeError("Method Tuple.prototype.%s called on incompatible receiver %s", method, r.objectproto_toString(FunctionCall{This: v})))
+}
+
+// tupleArrayMethod returns a Tuple.prototype method that calls the Array.prototype one on the wrapper object.
+// If toTuple is set, the resulting array is converted into a Tuple.
+func (r *Runtime) tupleArrayMethod(name string, method func(FunctionCall) Value, toTuple bool) func(FunctionCall) Value {
+	return func(call FunctionCall) Value {
+		t := r.thisTupleValue(call.This, name)
+		res := method(FunctionCall{This: t.baseObject(r), Arguments: call.Arguments})
+		if toTuple {
+			return r.tupleFromArrayLike(r.toObject(res))
+		}
+		return res
+	}
+}
+
+func (r *Runtime) tupleProto_getLength(call FunctionCall) Value {
+	return intToValue(int64(len(r.thisTupleValue(call.This, "length").values)))
+}
+
+func (r *Runtime) tupleProto_valueOf(call FunctionCall) Value {
+	return r.thisTupleValue(call.This, "valueOf")
+}
+
+func (r *Runtime) tupleProto_concat(call FunctionCall) Value {
+	t := r.thisTupleValue(call.This, "concat")
+	values := append([]Value(nil), t.values...)
+	for _, arg := range call.Arguments {
+		if t1, ok := arg.(*valueTuple); ok {
+			values = append(values, t1.values...)
+		} else {
+			values = append(values, r.checkRecordValue(arg))
+		}
+	}
+	return &valueTuple{values: values}
+}
+
+func (r *Runtime) tupleProto_toReversed(call FunctionCall) Value {
+	t := r.thisTupleValue(call.This, "toReversed")
+	values := make([]Value, len(t.values))
+	for i, v := range t.values {
+		values[len(values)-1-i] = v
+	}
+	return &valueTuple{values: values}
+}
+
+func (r *Runtime) tupleProto_toSorted(call FunctionCall) Value {
+	t := r.thisTupleValue(call.This, "toSorted")
+	arr := r.newArrayValues(append([]Value(nil), t.values...))
+	r.arrayproto_sort(FunctionCall{This: arr, Arguments: call.Arguments})
+	return r.tupleFromArrayLike(arr)
+}
+
+func (r *Runtime) tupleProto_with(call FunctionCall) Value {
+	t := r.thisTupleValue(call.This, "with")
+	idx := call.Argument(0).ToInteger()
+	if idx < 0 || idx >= int64(len(t.values)) {
+		panic(r.newError(r.global.RangeError, "Invalid index: %d", idx))
+	}
+	values := append([]Value(nil), t.values...)
+	values[idx] = r.checkRecordValue(call.Argument(1))
+	return &valueTuple{values: values}
+}
+
+func (r *Runtime) createTupleProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("constructor", r.global.Tuple, true, false, true)
+	o.setOwnStr("length", &valueProperty{
+		getterFunc:   r.newNativeFunc(r.tupleProto_getLength, nil, "get length", nil, 0),
+		accessor:     true,
+		configurable: true,
+	}, true)
+	o._putProp("valueOf", r.newNativeFunc(r.tupleProto_valueOf, nil, "valueOf", nil, 0), true, false, true)
+	o._putProp("concat", r.newNativeFunc(r.tupleProto_concat, nil, "concat", nil, 1), true, false, true)
+	o._putProp("toReversed", r.newNativeFunc(r.tupleProto_toReversed, nil, "toReversed", nil, 0), true, false, true)
+	o._putProp("toSorted", r.newNativeFunc(r.tupleProto_toSorted, nil, "toSorted", nil, 1), true, false, true)
+	o._putProp("with", r.newNativeFunc(r.tupleProto_with, nil, "with", nil, 2), true, false, true)
+
+	for _, m := range []struct {
+		name    unistring.String
+		method  func(FunctionCall) Value
+		length  int
+		toTuple bool
+	}{
+		{"at", r.arrayproto_at, 1, false},
+		{"every", r.arrayproto_every, 1, false},
+		{"filter", r.arrayproto_filter, 1, true},
+		{"find", r.arrayproto_find, 1, false},
+		{"findIndex", r.arrayproto_findIndex, 1, false},
+		{"findLast", r.arrayproto_findLast, 1, false},
+		{"findLastIndex", r.arrayproto_findLastIndex, 1, false},
+		{"forEach", r.arrayproto_forEach, 1, false},
+		{"includes", r.arrayproto_includes, 1, false},
+		{"indexOf", r.arrayproto_indexOf, 1, false},
+		{"join", r.arrayproto_join, 1, false},
+		{"lastIndexOf", r.arrayproto_lastIndexOf, 1, false},
+		{"map", r.arrayproto_map, 1, true},
+		{"reduce", r.arrayproto_reduce, 1, false},
+		{"reduceRight", r.arrayproto_reduceRight, 1, false},
+		{"slice", r.arrayproto_slice, 2, true},
+		{"some", r.arrayproto_some, 1, false},
+		{"toLocaleString", r.arrayproto_toLocaleString, 0, false},
+		{"toString", r.arrayproto_join, 0, false},
+		{"entries", r.arrayproto_entries, 0, false},
+		{"keys", r.arrayproto_keys, 0, false},
+	} {
+		o._putProp(m.name, r.newNativeFunc(r.tupleArrayMethod(m.name.String(), m.method, m.toTuple), nil, m.name, nil, m.length), true, false, true)
+	}
+
+	values := r.newNativeFunc(r.tupleArrayMethod("values", r.arrayproto_values, false), nil, "values", nil, 0)
+	o._putProp("values", values, true, false, true)
+	o._putSym(SymIterator, valueProp(values, true, false, true))
+	o._putSym(SymToStringTag, valueProp(asciiString(classTuple), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) createTuple(val *Object) objectImpl {
+	o := r.newNativeFuncObj(val, r.builtin_Tuple, nil, "Tuple", r.global.TuplePrototype, intToValue(0))
+	o._putProp("from", r.newNativeFunc(r.tuple_from, nil, "from", nil, 1), true, false, true)
+	o._putProp("of", r.newNativeFunc(r.builtin_Tuple, nil, "of", nil, 0), true, false, true)
+
+	return o
+}
+
+// EnableRecordsAndTuples adds the Record and Tuple globals that create immutable deeply compared primitives as
+// described in https://github.com/tc39/proposal-record-tuple. The proposal has been withdrawn, so the feature is
+// experimental and is disabled by default. The literal syntax (#{} and #[]) is not supported.
+//
+// Records and Tuples are compared by value, i.e. Tuple(1, 2) === Tuple(1, 2), and can be used as Map keys and
+// Set elements. typeof returns "record" and "tuple" respectively. When exported, a Record becomes a
+// map[string]interface{} and a Tuple becomes a []interface{}.
+func (r *Runtime) EnableRecordsAndTuples() {
+	if r.global.Tuple != nil {
+		return
+	}
+	r.global.TuplePrototype = r.newLazyObject(r.createTupleProto)
+	r.global.Tuple = r.newLazyObject(r.createTuple)
+	r.addToGlobal("Tuple", r.global.Tuple)
+
+	r.global.Record = r.newLazyObject(r.createRecord)
+	r.addToGlobal("Record", r.global.Record)
+}
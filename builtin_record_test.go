@@ -0,0 +1,123 @@
+package goja
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRecordsAndTuplesDisabled(t *testing.T) {
+	const SCRIPT = `
+	typeof Record === "undefined" && typeof Tuple === "undefined";
+	`
+	testScript(SCRIPT, valueTrue, t)
+}
+
+func TestTuple(t *testing.T) {
+	const SCRIPT = `
+	var t = Tuple(1, "a", Tuple(2));
+	assert.sameValue(typeof t, "tuple");
+	assert.sameValue(t.length, 3);
+	assert.sameValue(t[1], "a");
+	assert.sameValue(t[2][0], 2);
+	assert(t === Tuple(1, "a", Tuple(2)), "===");
+	assert(t == Tuple(1, "a", Tuple(2)), "==");
+	assert(t !== Tuple(1, "a", Tuple(3)), "!==");
+	assert(Tuple(-0) === Tuple(0), "-0 === 0");
+	assert(Tuple(NaN) === Tuple(NaN), "NaN === NaN");
+	assert(!Object.is(Tuple(-0), Tuple(0)), "Object.is(-0, 0)");
+	assert(Object.is(Tuple(NaN), Tuple(NaN)), "Object.is(NaN, NaN)");
+
+	assert.sameValue(String(t), "1,a,2");
+	assert.sameValue(JSON.stringify(t), '[1,"a",[2]]');
+	assert.sameValue(Object.prototype.toString.call(t), "[object Tuple]");
+	assert(compareArray([...t], [1, "a", Tuple(2)]), "spread");
+	assert(Object.isFrozen(Object(t)), "frozen");
+	assert.throws(TypeError, function() {
+		"use strict";
+		t[0] = 2;
+	});
+
+	assert.throws(TypeError, function() {
+		Tuple({});
+	});
+	assert.throws(TypeError, function() {
+		new Tuple();
+	});
+
+	assert(Tuple.from([1, 2], x => x * 2) === Tuple(2, 4), "from");
+	assert(Tuple.of(1, 2) === Tuple(1, 2), "of");
+	assert(Tuple(1, 2).map(x => x + 1) === Tuple(2, 3), "map");
+	assert(Tuple(1, 2, 3).filter(x => x > 1) === Tuple(2, 3), "filter");
+	assert(Tuple(1, 2, 3).slice(1) === Tuple(2, 3), "slice");
+	assert(Tuple(1).concat(Tuple(2), 3) === Tuple(1, 2, 3), "concat");
+	assert(Tuple(3, 1, 2).toSorted() === Tuple(1, 2, 3), "toSorted");
+	assert(Tuple(1, 2).toReversed() === Tuple(2, 1), "toReversed");
+	assert(Tuple(1, 2).with(1, 3) === Tuple(1, 3), "with");
+	assert.throws(RangeError, function() {
+		Tuple(1).with(1, 0);
+	});
+	assert(Tuple(1, NaN).includes(NaN), "includes");
+	assert.sameValue(Tuple(1, 2).indexOf(2), 1);
+	assert.sameValue(Tuple(1, 2).join("-"), "1-2");
+	assert.sameValue(Tuple(1, 2).reduce((a, b) => a + b), 3);
+	`
+	r := New()
+	r.EnableRecordsAndTuples()
+	r.testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestRecord(t *testing.T) {
+	const SCRIPT = `
+	var rec = Record({b: 1, a: Tuple("x")});
+	assert.sameValue(typeof rec, "record");
+	assert.sameValue(rec.a[0], "x");
+	assert(rec === Record({a: Tuple("x"), b: 1}), "===");
+	assert(rec !== Record({a: Tuple("x"), b: 2}), "!==");
+	assert(rec !== Record({a: Tuple("x")}), "different keys");
+	assert(compareArray(Object.keys(rec), ["a", "b"]), "keys are sorted");
+	assert.sameValue(JSON.stringify(rec), '{"a":["x"],"b":1}');
+	assert.sameValue(Object.getPrototypeOf(Object(rec)), null);
+	assert.sameValue(Object.prototype.toString.call(rec), "[object Record]");
+	assert(Record.fromEntries([["a", Tuple("x")], ["b", 1]]) === rec, "fromEntries");
+	assert.throws(TypeError, function() {
+		Record({a: {}});
+	});
+	assert.throws(TypeError, function() {
+		Record({[Symbol()]: 1});
+	});
+	assert.throws(TypeError, function() {
+		+rec;
+	});
+	`
+	r := New()
+	r.EnableRecordsAndTuples()
+	r.testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestRecordsAndTuplesMapKeys(t *testing.T) {
+	const SCRIPT = `
+	var m = new Map();
+	m.set(Tuple(1, Record({a: -0})), "v");
+	assert.sameValue(m.get(Tuple(1, Record({a: 0}))), "v");
+	assert(m.has(Tuple(1, Record({a: 0}))), "has");
+	assert(!m.has(Tuple(1, Record({a: 1}))), "!has");
+
+	var s = new Set([Tuple(1, 2), Tuple(1, 2), Record({a: NaN}), Record({a: NaN})]);
+	assert.sameValue(s.size, 2);
+	`
+	r := New()
+	r.EnableRecordsAndTuples()
+	r.testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestRecordsAndTuplesExport(t *testing.T) {
+	r := New()
+	r.EnableRecordsAndTuples()
+	v, err := r.RunString(`Tuple(1, Record({a: "b"}))`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := v.Export(); !reflect.DeepEqual(exp, []interface{}{int64(1), map[string]interface{}{"a": "b"}}) {
+		t.Fatalf("Unexpected export: %#v", exp)
+	}
+}
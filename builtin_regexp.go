@@ -3,6 +3,7 @@ package goja
 import (
 	"fmt"
 	"github.com/dop251/goja/parser"
+	"github.com/dop251/goja/unistring"
 	"regexp"
 	"strings"
 	"unicode/utf16"
@@ -25,12 +26,30 @@ func (r *Runtime) newRegexpObject(proto *Object) *regexpObject {
 func (r *Runtime) newRegExpp(pattern *regexpPattern, patternStr valueString, proto *Object) *regexpObject {
 	o := r.newRegexpObject(proto)
 
+	if r.regexpTimeout > 0 {
+		pattern.setTimeout(r, r.regexpTimeout)
+	}
 	o.pattern = pattern
 	o.source = patternStr
 
 	return o
 }
 
+// newGroupsObject creates the value of the "groups" property of a match result. captures must contain
+// the whole match followed by the values of all capturing groups.
+func (r *Runtime) newGroupsObject(groupNames []string, captures []Value) Value {
+	if groupNames == nil {
+		return _undefined
+	}
+	o := r.newBaseObject(nil, classObject)
+	for i, name := range groupNames {
+		if name != "" {
+			o._put(unistring.NewFromString(name), captures[i+1])
+		}
+	}
+	return o.val
+}
+
 func decodeHex(s string) (int, bool) {
 	var hex int
 	for i := 0; i < len(s); i++ {
@@ -183,7 +202,7 @@ func compileRegexpFromValueString(patternStr valueString, flags string) (*regexp
 }
 
 func compileRegexp(patternStr, flags string) (p *regexpPattern, err error) {
-	var global, ignoreCase, multiline, sticky, unicode bool
+	var global, ignoreCase, multiline, dotAll, sticky, unicode bool
 	var wrapper *regexpWrapper
 	var wrapper2 *regexp2Wrapper
 
@@ -211,6 +230,12 @@ func compileRegexp(patternStr, flags string) (p *regexpPattern, err error) {
 					return
 				}
 				ignoreCase = true
+			case 's':
+				if dotAll {
+					invalidFlags()
+					return
+				}
+				dotAll = true
 			case 'y':
 				if sticky {
 					invalidFlags()
@@ -229,6 +254,15 @@ func compileRegexp(patternStr, flags string) (p *regexpPattern, err error) {
 		}
 	}
 
+	patternStr, groupNames, err := parser.ExtractNamedGroups(patternStr)
+	if err != nil {
+		return
+	}
+
+	if dotAll {
+		patternStr = parser.ExpandDotAll(patternStr)
+	}
+
 	if unicode {
 		patternStr = convertRegexpToUnicode(patternStr)
 	} else {
@@ -273,8 +307,10 @@ func compileRegexp(patternStr, flags string) (p *regexpPattern, err error) {
 		global:         global,
 		ignoreCase:     ignoreCase,
 		multiline:      multiline,
+		dotAll:         dotAll,
 		sticky:         sticky,
 		unicode:        unicode,
+		groupNames:     groupNames,
 	}
 	return
 }
@@ -431,6 +467,9 @@ func (r *Runtime) regexpproto_toString(call FunctionCall) Value {
 		if this.pattern.multiline {
 			sb.WriteRune('m')
 		}
+		if this.pattern.dotAll {
+			sb.WriteRune('s')
+		}
 		if this.pattern.unicode {
 			sb.WriteRune('u')
 		}
@@ -566,6 +605,20 @@ func (r *Runtime) regexpproto_getUnicode(call FunctionCall) Value {
 	}
 }
 
+func (r *Runtime) regexpproto_getDotAll(call FunctionCall) Value {
+	if this, ok := r.toObject(call.This).self.(*regexpObject); ok {
+		if this.pattern.dotAll {
+			return valueTrue
+		} else {
+			return valueFalse
+		}
+	} else if call.This == r.global.RegExpPrototype {
+		return _undefined
+	} else {
+		panic(r.NewTypeError("Method RegExp.prototype.dotAll getter called on incompatible receiver %s", r.objectproto_toString(FunctionCall{This: call.This})))
+	}
+}
+
 func (r *Runtime) regexpproto_getSticky(call FunctionCall) Value {
 	if this, ok := r.toObject(call.This).self.(*regexpObject); ok {
 		if this.pattern.sticky {
@@ -581,7 +634,7 @@ func (r *Runtime) regexpproto_getSticky(call FunctionCall) Value {
 }
 
 func (r *Runtime) regexpproto_getFlags(call FunctionCall) Value {
-	var global, ignoreCase, multiline, sticky, unicode bool
+	var global, ignoreCase, multiline, dotAll, sticky, unicode bool
 
 	thisObj := r.toObject(call.This)
 	size := 0
@@ -603,6 +656,12 @@ func (r *Runtime) regexpproto_getFlags(call FunctionCall) Value {
 			size++
 		}
 	}
+	if v := thisObj.self.getStr("dotAll", nil); v != nil {
+		dotAll = v.ToBoolean()
+		if dotAll {
+			size++
+		}
+	}
 	if v := thisObj.self.getStr("sticky", nil); v != nil {
 		sticky = v.ToBoolean()
 		if sticky {
@@ -627,6 +686,9 @@ func (r *Runtime) regexpproto_getFlags(call FunctionCall) Value {
 	if multiline {
 		sb.WriteByte('m')
 	}
+	if dotAll {
+		sb.WriteByte('s')
+	}
 	if unicode {
 		sb.WriteByte('u')
 	}
@@ -1088,9 +1150,13 @@ func (r *Runtime) regexpproto_stdReplacerGeneric(rxObj *Object, s, replaceStr va
 			}
 			captures = append(captures, capN)
 		}
+		namedCaptures := nilSafe(obj.self.getStr("groups", nil))
 		var replacement valueString
 		if rcall != nil {
 			captures = append(captures, intToValue(int64(position)), s)
+			if namedCaptures != _undefined {
+				captures = append(captures, namedCaptures)
+			}
 			replacement = rcall(FunctionCall{
 				This:      _undefined,
 				Arguments: captures,
@@ -1098,10 +1164,22 @@ func (r *Runtime) regexpproto_stdReplacerGeneric(rxObj *Object, s, replaceStr va
 			if position >= nextSourcePosition {
 				resultBuf.WriteString(s.substring(nextSourcePosition, position))
 				resultBuf.WriteString(replacement)
+				r.checkStringLength(int64(resultBuf.length()))
 				nextSourcePosition = position + matchLength
 			}
 		} else {
 			if position >= nextSourcePosition {
+				var getNamedCapture func(unistring.String) valueString
+				if namedCaptures != _undefined {
+					groups := r.toObject(namedCaptures)
+					getNamedCapture = func(name unistring.String) valueString {
+						capture := nilSafe(groups.self.getStr(name, nil))
+						if capture != _undefined {
+							return capture.toString()
+						}
+						return stringEmpty
+					}
+				}
 				resultBuf.WriteString(s.substring(nextSourcePosition, position))
 				writeSubstitution(s, position, len(captures), func(idx int) valueString {
 					capture := captures[idx]
@@ -1109,18 +1187,22 @@ func (r *Runtime) regexpproto_stdReplacerGeneric(rxObj *Object, s, replaceStr va
 						return capture.toString()
 					}
 					return stringEmpty
-				}, replaceStr, &resultBuf)
+				}, getNamedCapture, replaceStr, &resultBuf)
+				r.checkStringLength(int64(resultBuf.length()))
 				nextSourcePosition = position + matchLength
 			}
 		}
 	}
 	if nextSourcePosition < lengthS {
 		resultBuf.WriteString(s.substring(nextSourcePosition, lengthS))
+		r.checkStringLength(int64(resultBuf.length()))
 	}
 	return resultBuf.String()
 }
 
-func writeSubstitution(s valueString, position int, numCaptures int, getCapture func(int) valueString, replaceStr valueString, buf *valueStringBuilder) {
+// writeSubstitution implements GetSubstitution. getNamedCapture is nil if the match has no named groups,
+// in which case "$<" is copied literally.
+func writeSubstitution(s valueString, position int, numCaptures int, getCapture func(int) valueString, getNamedCapture func(unistring.String) valueString, replaceStr valueString, buf *valueStringBuilder) {
 	l := s.length()
 	rl := replaceStr.length()
 	matched := getCapture(0)
@@ -1141,6 +1223,27 @@ func writeSubstitution(s valueString, position int, numCaptures int, getCapture
 				}
 			case '&':
 				buf.WriteString(matched)
+			case '<':
+				if getNamedCapture == nil {
+					buf.WriteRune('$')
+					buf.WriteRune('<')
+					break
+				}
+				end := -1
+				for j := i + 2; j < rl; j++ {
+					if replaceStr.charAt(j) == '>' {
+						end = j
+						break
+					}
+				}
+				if end == -1 {
+					buf.WriteRune('$')
+					buf.WriteRune('<')
+					break
+				}
+				buf.WriteString(getNamedCapture(replaceStr.substring(i+2, end).string()))
+				i = end
+				continue
 			default:
 				matchNumber := 0
 				j := i + 1
@@ -1200,7 +1303,7 @@ func (r *Runtime) regexpproto_stdReplacer(call FunctionCall) Value {
 		rx.updateLastIndex(index, nil, nil)
 	}
 
-	return stringReplace(s, found, replaceStr, rcall)
+	return r.stringReplace(s, found, replaceStr, rcall, rx.pattern.groupNames)
 }
 
 func (r *Runtime) regExpStringIteratorProto_next(call FunctionCall) Value {
@@ -1250,6 +1353,11 @@ func (r *Runtime) initRegExp() {
 		getterFunc:   r.newNativeFunc(r.regexpproto_getIgnoreCase, nil, "get ignoreCase", nil, 0),
 		accessor:     true,
 	}, false)
+	o.setOwnStr("dotAll", &valueProperty{
+		configurable: true,
+		getterFunc:   r.newNativeFunc(r.regexpproto_getDotAll, nil, "get dotAll", nil, 0),
+		accessor:     true,
+	}, false)
 	o.setOwnStr("unicode", &valueProperty{
 		configurable: true,
 		getterFunc:   r.newNativeFunc(r.regexpproto_getUnicode, nil, "get unicode", nil, 0),
@@ -1271,7 +1379,7 @@ func (r *Runtime) initRegExp() {
 	o._putSym(SymSearch, valueProp(r.newNativeFunc(r.regexpproto_stdSearch, nil, "[Symbol.search]", nil, 1), true, false, true))
 	o._putSym(SymSplit, valueProp(r.newNativeFunc(r.regexpproto_stdSplitter, nil, "[Symbol.split]", nil, 2), true, false, true))
 	o._putSym(SymReplace, valueProp(r.newNativeFunc(r.regexpproto_stdReplacer, nil, "[Symbol.replace]", nil, 2), true, false, true))
-	o.guard("exec", "global", "multiline", "ignoreCase", "unicode", "sticky")
+	o.guard("exec", "global", "multiline", "dotAll", "ignoreCase", "unicode", "sticky")
 
 	r.global.RegExp = r.newNativeFunc(r.builtin_RegExp, r.builtin_newRegExp, "RegExp", r.global.RegExpPrototype, 2)
 	rx := r.global.RegExp.self
@@ -2,6 +2,7 @@ package goja
 
 import (
 	"fmt"
+	"math"
 	"reflect"
 )
 
@@ -12,6 +13,15 @@ type setObject struct {
 	m *orderedMap
 }
 
+// setRecord is a Set Record, as returned by GetSetRecord() in the spec. It describes a set-like
+// argument of the Set.prototype methods such as union().
+type setRecord struct {
+	obj  *Object
+	size float64
+	has  func(FunctionCall) Value
+	keys func(FunctionCall) Value
+}
+
 type setIterObject struct {
 	baseObject
 	iter *orderedMapIter
@@ -90,8 +100,12 @@ func (so *setObject) exportToArrayOrSlice(dst reflect.Value, typ reflect.Type, c
 
 func (so *setObject) exportToMap(dst reflect.Value, typ reflect.Type, ctx *objectExportCtx) error {
 	dst.Set(reflect.MakeMap(typ))
+	ctx.putTyped(so.val, typ, dst.Interface())
 	keyTyp := typ.Key()
-	elemTyp := typ.Elem()
+	elemVal := reflect.New(typ.Elem()).Elem()
+	if elemVal.Kind() == reflect.Bool {
+		elemVal.SetBool(true)
+	}
 	iter := so.m.newIter()
 	r := so.val.runtime
 	for {
@@ -104,7 +118,10 @@ func (so *setObject) exportToMap(dst reflect.Value, typ reflect.Type, ctx *objec
 		if err != nil {
 			return err
 		}
-		dst.SetMapIndex(keyVal, reflect.Zero(elemTyp))
+		if !isHashable(keyVal) {
+			return fmt.Errorf("could not use %v as a key of %v: the exported value is not hashable", entry.key, typ)
+		}
+		dst.SetMapIndex(keyVal, elemVal)
 	}
 	return nil
 }
@@ -192,11 +209,222 @@ func (r *Runtime) setProto_values(call FunctionCall) Value {
 	return r.createSetIterator(call.This, iterationKindValue)
 }
 
-func (r *Runtime) builtin_newSet(args []Value, newTarget *Object) *Object {
-	if newTarget == nil {
-		panic(r.needNew("Set"))
+func (r *Runtime) setProto_union(call FunctionCall) Value {
+	thisObj := r.toObject(call.This)
+	so, ok := thisObj.self.(*setObject)
+	if !ok {
+		panic(r.NewTypeError("Method Set.prototype.union called on incompatible receiver %s", r.objectproto_toString(FunctionCall{This: thisObj})))
 	}
-	proto := r.getPrototypeFromCtor(newTarget, r.global.Set, r.global.SetPrototype)
+	other := r.getSetRecord(call.Argument(0))
+	res := r.copySetObject(so)
+	r.iterateSetKeys(other, func(key Value) bool {
+		res.m.set(key, nil)
+		return true
+	})
+	return res.val
+}
+
+func (r *Runtime) setProto_intersection(call FunctionCall) Value {
+	thisObj := r.toObject(call.This)
+	so, ok := thisObj.self.(*setObject)
+	if !ok {
+		panic(r.NewTypeError("Method Set.prototype.intersection called on incompatible receiver %s", r.objectproto_toString(FunctionCall{This: thisObj})))
+	}
+	other := r.getSetRecord(call.Argument(0))
+	res := r.newSetObject(r.global.SetPrototype)
+	if float64(so.m.size) <= other.size {
+		iter := so.m.newIter()
+		for {
+			entry := iter.next()
+			if entry == nil {
+				break
+			}
+			if other.has(FunctionCall{This: other.obj, Arguments: []Value{entry.key}}).ToBoolean() {
+				res.m.set(entry.key, nil)
+			}
+		}
+	} else {
+		r.iterateSetKeys(other, func(key Value) bool {
+			if so.m.has(key) {
+				res.m.set(key, nil)
+			}
+			return true
+		})
+	}
+	return res.val
+}
+
+func (r *Runtime) setProto_difference(call FunctionCall) Value {
+	thisObj := r.toObject(call.This)
+	so, ok := thisObj.self.(*setObject)
+	if !ok {
+		panic(r.NewTypeError("Method Set.prototype.difference called on incompatible receiver %s", r.objectproto_toString(FunctionCall{This: thisObj})))
+	}
+	other := r.getSetRecord(call.Argument(0))
+	res := r.copySetObject(so)
+	if float64(so.m.size) <= other.size {
+		iter := so.m.newIter()
+		for {
+			entry := iter.next()
+			if entry == nil {
+				break
+			}
+			if other.has(FunctionCall{This: other.obj, Arguments: []Value{entry.key}}).ToBoolean() {
+				res.m.remove(entry.key)
+			}
+		}
+	} else {
+		r.iterateSetKeys(other, func(key Value) bool {
+			res.m.remove(key)
+			return true
+		})
+	}
+	return res.val
+}
+
+func (r *Runtime) setProto_symmetricDifference(call FunctionCall) Value {
+	thisObj := r.toObject(call.This)
+	so, ok := thisObj.self.(*setObject)
+	if !ok {
+		panic(r.NewTypeError("Method Set.prototype.symmetricDifference called on incompatible receiver %s", r.objectproto_toString(FunctionCall{This: thisObj})))
+	}
+	other := r.getSetRecord(call.Argument(0))
+	res := r.copySetObject(so)
+	r.iterateSetKeys(other, func(key Value) bool {
+		if so.m.has(key) {
+			res.m.remove(key)
+		} else {
+			res.m.set(key, nil)
+		}
+		return true
+	})
+	return res.val
+}
+
+func (r *Runtime) setProto_isSubsetOf(call FunctionCall) Value {
+	thisObj := r.toObject(call.This)
+	so, ok := thisObj.self.(*setObject)
+	if !ok {
+		panic(r.NewTypeError("Method Set.prototype.isSubsetOf called on incompatible receiver %s", r.objectproto_toString(FunctionCall{This: thisObj})))
+	}
+	other := r.getSetRecord(call.Argument(0))
+	if float64(so.m.size) > other.size {
+		return valueFalse
+	}
+	iter := so.m.newIter()
+	for {
+		entry := iter.next()
+		if entry == nil {
+			break
+		}
+		if !other.has(FunctionCall{This: other.obj, Arguments: []Value{entry.key}}).ToBoolean() {
+			iter.close()
+			return valueFalse
+		}
+	}
+	return valueTrue
+}
+
+func (r *Runtime) setProto_isSupersetOf(call FunctionCall) Value {
+	thisObj := r.toObject(call.This)
+	so, ok := thisObj.self.(*setObject)
+	if !ok {
+		panic(r.NewTypeError("Method Set.prototype.isSupersetOf called on incompatible receiver %s", r.objectproto_toString(FunctionCall{This: thisObj})))
+	}
+	other := r.getSetRecord(call.Argument(0))
+	if float64(so.m.size) < other.size {
+		return valueFalse
+	}
+	res := true
+	r.iterateSetKeys(other, func(key Value) bool {
+		res = so.m.has(key)
+		return res
+	})
+	return r.toBoolean(res)
+}
+
+func (r *Runtime) setProto_isDisjointFrom(call FunctionCall) Value {
+	thisObj := r.toObject(call.This)
+	so, ok := thisObj.self.(*setObject)
+	if !ok {
+		panic(r.NewTypeError("Method Set.prototype.isDisjointFrom called on incompatible receiver %s", r.objectproto_toString(FunctionCall{This: thisObj})))
+	}
+	other := r.getSetRecord(call.Argument(0))
+	if float64(so.m.size) <= other.size {
+		iter := so.m.newIter()
+		for {
+			entry := iter.next()
+			if entry == nil {
+				break
+			}
+			if other.has(FunctionCall{This: other.obj, Arguments: []Value{entry.key}}).ToBoolean() {
+				iter.close()
+				return valueFalse
+			}
+		}
+		return valueTrue
+	}
+	res := true
+	r.iterateSetKeys(other, func(key Value) bool {
+		res = !so.m.has(key)
+		return res
+	})
+	return r.toBoolean(res)
+}
+
+func (r *Runtime) getSetRecord(v Value) *setRecord {
+	obj, ok := v.(*Object)
+	if !ok {
+		panic(r.NewTypeError("The argument is not a set-like object: %s", v))
+	}
+	numSize := nilSafe(obj.self.getStr("size", nil)).ToNumber()
+	if IsNaN(numSize) {
+		panic(r.NewTypeError("The 'size' property of a set-like object must be a number"))
+	}
+	size := math.Trunc(numSize.ToFloat())
+	if size < 0 {
+		panic(r.newError(r.global.RangeError, "The 'size' property of a set-like object must not be negative"))
+	}
+	has := toMethod(obj.self.getStr("has", nil))
+	if has == nil {
+		panic(r.NewTypeError("The 'has' property of a set-like object must be a function"))
+	}
+	keys := toMethod(obj.self.getStr("keys", nil))
+	if keys == nil {
+		panic(r.NewTypeError("The 'keys' property of a set-like object must be a function"))
+	}
+	return &setRecord{
+		obj:  obj,
+		size: size,
+		has:  has,
+		keys: keys,
+	}
+}
+
+// iterateSetKeys calls step for each value returned by the keys() iterator of the set-like object. The iteration
+// stops (and the iterator is closed) when step returns false.
+func (r *Runtime) iterateSetKeys(sr *setRecord, step func(Value) bool) {
+	iter, ok := sr.keys(FunctionCall{This: sr.obj}).(*Object)
+	if !ok {
+		panic(r.NewTypeError("keys() of a set-like object did not return an object"))
+	}
+	next := toMethod(iter.self.getStr("next", nil))
+	if next == nil {
+		panic(r.NewTypeError("iterator.next is missing or not a function"))
+	}
+	for {
+		res := r.toObject(next(FunctionCall{This: iter}))
+		if nilSafe(res.self.getStr("done", nil)).ToBoolean() {
+			break
+		}
+		if !step(nilSafe(res.self.getStr("value", nil))) {
+			(&iteratorRecord{iterator: iter, next: next}).returnIter()
+			break
+		}
+	}
+}
+
+func (r *Runtime) newSetObject(proto *Object) *setObject {
 	o := &Object{runtime: r}
 
 	so := &setObject{}
@@ -206,6 +434,29 @@ func (r *Runtime) builtin_newSet(args []Value, newTarget *Object) *Object {
 	o.self = so
 	so.prototype = proto
 	so.init()
+	return so
+}
+
+func (r *Runtime) copySetObject(src *setObject) *setObject {
+	so := r.newSetObject(r.global.SetPrototype)
+	iter := src.m.newIter()
+	for {
+		entry := iter.next()
+		if entry == nil {
+			break
+		}
+		so.m.set(entry.key, nil)
+	}
+	return so
+}
+
+func (r *Runtime) builtin_newSet(args []Value, newTarget *Object) *Object {
+	if newTarget == nil {
+		panic(r.needNew("Set"))
+	}
+	proto := r.getPrototypeFromCtor(newTarget, r.global.Set, r.global.SetPrototype)
+	so := r.newSetObject(proto)
+	o := so.val
 	if len(args) > 0 {
 		if arg := args[0]; arg != nil && arg != _undefined && arg != _null {
 			adder := so.getStr("add", nil)
@@ -282,6 +533,13 @@ func (r *Runtime) createSetProto(val *Object) objectImpl {
 	o._putProp("delete", r.newNativeFunc(r.setProto_delete, nil, "delete", nil, 1), true, false, true)
 	o._putProp("forEach", r.newNativeFunc(r.setProto_forEach, nil, "forEach", nil, 1), true, false, true)
 	o._putProp("has", r.newNativeFunc(r.setProto_has, nil, "has", nil, 1), true, false, true)
+	o._putProp("union", r.newNativeFunc(r.setProto_union, nil, "union", nil, 1), true, false, true)
+	o._putProp("intersection", r.newNativeFunc(r.setProto_intersection, nil, "intersection", nil, 1), true, false, true)
+	o._putProp("difference", r.newNativeFunc(r.setProto_difference, nil, "difference", nil, 1), true, false, true)
+	o._putProp("symmetricDifference", r.newNativeFunc(r.setProto_symmetricDifference, nil, "symmetricDifference", nil, 1), true, false, true)
+	o._putProp("isSubsetOf", r.newNativeFunc(r.setProto_isSubsetOf, nil, "isSubsetOf", nil, 1), true, false, true)
+	o._putProp("isSupersetOf", r.newNativeFunc(r.setProto_isSupersetOf, nil, "isSupersetOf", nil, 1), true, false, true)
+	o._putProp("isDisjointFrom", r.newNativeFunc(r.setProto_isDisjointFrom, nil, "isDisjointFrom", nil, 1), true, false, true)
 	o.setOwnStr("size", &valueProperty{
 		getterFunc:   r.newNativeFunc(r.setProto_getSize, nil, "get size", nil, 0),
 		accessor:     true,
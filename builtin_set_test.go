@@ -141,6 +141,37 @@ func TestSetExportToNonNilMap(t *testing.T) {
 	}
 }
 
+func TestSetExportToTypedSetMap(t *testing.T) {
+	vm := New()
+	res, err := vm.RunString(`new Set(["a", "b", 1])`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]bool
+	if err := vm.ExportTo(res, &m); err != nil {
+		t.Fatal(err)
+	}
+	if len(m) != 3 || !m["a"] || !m["b"] || !m["1"] {
+		t.Fatal(m)
+	}
+	var a []string
+	if err := vm.ExportTo(res, &a); err != nil {
+		t.Fatal(err)
+	}
+	if len(a) != 3 || a[0] != "a" || a[2] != "1" {
+		t.Fatal(a)
+	}
+
+	res, err = vm.RunString(`new Set([[1, 2]])`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m1 map[interface{}]struct{}
+	if err := vm.ExportTo(res, &m1); err == nil {
+		t.Fatal("expected an error for an unhashable element")
+	}
+}
+
 func TestSetGetAdderGetIteratorOrder(t *testing.T) {
 	const SCRIPT = `
 	let getterCalled = 0;
@@ -178,3 +209,107 @@ func TestSetGetAdderGetIteratorOrder(t *testing.T) {
 	`
 	testScript(SCRIPT, valueTrue, t)
 }
+
+func TestSetMethods(t *testing.T) {
+	const SCRIPT = `
+function values(s) {
+	return Array.from(s);
+}
+
+const a = new Set([1, 2, 3]);
+const b = new Set([3, 4]);
+
+assert(compareArray(values(a.union(b)), [1, 2, 3, 4]), "union");
+assert(compareArray(values(a.intersection(b)), [3]), "intersection");
+assert(compareArray(values(b.intersection(a)), [3]), "intersection (smaller receiver)");
+assert(compareArray(values(a.difference(b)), [1, 2]), "difference");
+assert(compareArray(values(b.difference(a)), [4]), "difference (smaller receiver)");
+assert(compareArray(values(a.symmetricDifference(b)), [1, 2, 4]), "symmetricDifference");
+
+assert.sameValue(new Set([1]).isSubsetOf(a), true, "isSubsetOf");
+assert.sameValue(b.isSubsetOf(a), false, "isSubsetOf false");
+assert.sameValue(a.isSupersetOf(new Set([1, 3])), true, "isSupersetOf");
+assert.sameValue(a.isSupersetOf(b), false, "isSupersetOf false");
+assert.sameValue(a.isDisjointFrom(new Set([5, 6])), true, "isDisjointFrom");
+assert.sameValue(a.isDisjointFrom(b), false, "isDisjointFrom false");
+assert.sameValue(new Set([7, 8, 9, 10]).isDisjointFrom(b), true, "isDisjointFrom (larger receiver)");
+
+// the result is a new Set and the receiver is not modified
+const u = a.union(b);
+assert.sameValue(Object.getPrototypeOf(u), Set.prototype, "result prototype");
+assert(u !== a, "new set");
+assert(compareArray(values(a), [1, 2, 3]), "receiver unchanged");
+
+// Map is set-like
+const m = new Map([[2, "x"], [5, "y"]]);
+assert(compareArray(values(a.union(m)), [1, 2, 3, 5]), "union with Map");
+assert.sameValue(new Set([2]).isSubsetOf(m), true, "isSubsetOf Map");
+
+// custom set-like object
+let hasCalls = 0, keysCalls = 0;
+const setLike = {
+	size: 2,
+	has(v) {
+		hasCalls++;
+		return v === 1 || v === -0;
+	},
+	keys() {
+		keysCalls++;
+		return [1, -0][Symbol.iterator]();
+	}
+};
+assert(compareArray(values(new Set([1, 2, 3]).intersection(setLike)), [1]), "intersection with set-like (keys)");
+assert.sameValue(keysCalls, 1, "keys called");
+assert.sameValue(hasCalls, 0, "has not called");
+assert(compareArray(values(new Set([2]).intersection(setLike)), []), "intersection with set-like (has)");
+assert.sameValue(hasCalls, 1, "has called");
+assert.sameValue(Object.is(values(new Set().union(setLike))[1], 0), true, "-0 is normalised");
+
+// closing the keys iterator on early exit
+let returnCalled = 0;
+const closing = {
+	size: 1,
+	has() {
+		return false;
+	},
+	keys() {
+		return {
+			next() {
+				return {value: 42, done: false};
+			},
+			return() {
+				returnCalled++;
+				return {};
+			}
+		};
+	}
+};
+assert.sameValue(new Set([1, 2]).isSupersetOf(closing), false, "isSupersetOf with infinite iterator");
+assert.sameValue(returnCalled, 1, "iterator closed");
+
+// invalid set-like arguments
+assert.throws(TypeError, function() {
+	a.union([1, 2]);
+}, "array");
+assert.throws(TypeError, function() {
+	a.union(1);
+}, "primitive");
+assert.throws(TypeError, function() {
+	a.union({size: NaN, has() {}, keys() {}});
+}, "NaN size");
+assert.throws(RangeError, function() {
+	a.union({size: -1, has() {}, keys() {}});
+}, "negative size");
+assert.throws(TypeError, function() {
+	a.union({size: 1, has: 1, keys() {}});
+}, "has not callable");
+assert.throws(TypeError, function() {
+	a.union({size: 1, has() {}, keys() { return 1; }});
+}, "keys() not returning an object");
+assert.throws(TypeError, function() {
+	Set.prototype.union.call(new Map(), b);
+}, "incompatible receiver");
+assert.sameValue(Set.prototype.isDisjointFrom.length, 1, "length");
+`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
@@ -0,0 +1,1853 @@
+package goja
+
+import (
+	"io"
+	"math"
+
+	"github.com/dop251/goja/unistring"
+)
+
+// streamChunkSize is the maximum number of bytes read from an io.Reader by a single pull.
+const streamChunkSize = 64 * 1024
+
+type streamQueueEntry struct {
+	value Value
+	size  float64
+	close bool
+}
+
+type streamQueue struct {
+	entries   []streamQueueEntry
+	totalSize float64
+}
+
+func (q *streamQueue) enqueue(value Value, size float64) {
+	q.entries = append(q.entries, streamQueueEntry{value: value, size: size})
+	q.totalSize += size
+}
+
+func (q *streamQueue) dequeue() Value {
+	e := q.entries[0]
+	q.entries[0] = streamQueueEntry{}
+	q.entries = q.entries[1:]
+	q.totalSize -= e.size
+	if q.totalSize < 0 {
+		q.totalSize = 0
+	}
+	return e.value
+}
+
+func (q *streamQueue) reset() {
+	q.entries = nil
+	q.totalSize = 0
+}
+
+type readRequest struct {
+	chunk func(Value)
+	close func()
+	error func(Value)
+}
+
+type readableStreamObject struct {
+	baseObject
+
+	state       string
+	storedError Value
+	disturbed   bool
+	reader      *readableStreamReaderObject
+	controller  *readableStreamControllerObject
+}
+
+type readableStreamReaderObject struct {
+	baseObject
+
+	stream       *readableStreamObject
+	closed       *promiseCapability
+	readRequests []*readRequest
+}
+
+type readableStreamControllerObject struct {
+	baseObject
+
+	stream        *readableStreamObject
+	queue         streamQueue
+	highWaterMark float64
+	size          func(FunctionCall) Value
+
+	started, closeRequested bool
+	pulling, pullAgain      bool
+
+	pullAlgorithm   func() *Object
+	cancelAlgorithm func(reason Value) *Object
+}
+
+type readableStreamIterObject struct {
+	baseObject
+
+	reader        *readableStreamReaderObject
+	preventCancel bool
+}
+
+type writableStreamObject struct {
+	baseObject
+
+	state        string
+	storedError  Value
+	backpressure bool
+	writer       *writableStreamWriterObject
+	controller   *writableStreamControllerObject
+
+	writeRequests        []*promiseCapability
+	inFlightWriteRequest *promiseCapability
+	closeRequest         *promiseCapability
+	inFlightCloseRequest *promiseCapability
+	pendingAbortRequest  *pendingAbortRequest
+}
+
+type pendingAbortRequest struct {
+	promise            *promiseCapability
+	reason             Value
+	wasAlreadyErroring bool
+}
+
+type writableStreamWriterObject struct {
+	baseObject
+
+	stream *writableStreamObject
+	ready  *promiseCapability
+	closed *promiseCapability
+}
+
+type writableStreamControllerObject struct {
+	baseObject
+
+	stream        *writableStreamObject
+	queue         streamQueue
+	highWaterMark float64
+	size          func(FunctionCall) Value
+	started       bool
+
+	writeAlgorithm func(chunk Value) *Object
+	closeAlgorithm func() *Object
+	abortAlgorithm func(reason Value) *Object
+}
+
+func (r *Runtime) newStreamPromise() *promiseCapability {
+	return r.newPromiseCapability(r.global.Promise)
+}
+
+func (r *Runtime) resolvedStreamPromise(v Value) *Object {
+	pcap := r.newStreamPromise()
+	pcap.resolve(v)
+	return pcap.promise
+}
+
+func (r *Runtime) rejectedStreamPromise(reason Value) *Object {
+	pcap := r.newStreamPromise()
+	pcap.reject(reason)
+	return pcap.promise
+}
+
+// rejectHandled rejects the promise marking it as handled, so the rejection is not reported to the tracker.
+func rejectHandled(pcap *promiseCapability, reason Value) {
+	pcap.promise.self.(*Promise).handled = true
+	pcap.reject(reason)
+}
+
+func isPendingPromise(pcap *promiseCapability) bool {
+	return pcap.promise.self.(*Promise).state == PromiseStatePending
+}
+
+// promiseCall calls fn and converts the result into a Promise. An exception thrown by fn rejects the promise.
+func (r *Runtime) promiseCall(fn func(FunctionCall) Value, this Value, args ...Value) *Object {
+	if fn == nil {
+		return r.resolvedStreamPromise(_undefined)
+	}
+	var p *Object
+	if ex := r.vm.try(func() {
+		p = r.promiseResolve(r.global.Promise, fn(FunctionCall{This: this, Arguments: args}))
+	}); ex != nil {
+		return r.rejectedStreamPromise(ex.val)
+	}
+	return p
+}
+
+// extractStrategy implements ExtractHighWaterMark and ExtractSizeAlgorithm.
+func (r *Runtime) extractStrategy(v Value, defaultHWM float64) (float64, func(FunctionCall) Value) {
+	strategy := r.webIDLDictionary(v)
+	if strategy == nil {
+		return defaultHWM, nil
+	}
+	hwm := defaultHWM
+	if v := strategy.self.getStr("highWaterMark", nil); v != nil && v != _undefined {
+		hwm = v.ToFloat()
+		if math.IsNaN(hwm) || hwm < 0 {
+			panic(r.newError(r.global.RangeError, "Invalid highWaterMark"))
+		}
+	}
+	var size func(FunctionCall) Value
+	if v := strategy.self.getStr("size", nil); v != nil && v != _undefined {
+		size = r.toCallable(v)
+	}
+	return hwm, size
+}
+
+func (r *Runtime) streamChunkSize(size func(FunctionCall) Value, chunk Value) float64 {
+	if size == nil {
+		return 1
+	}
+	s := size(FunctionCall{This: _undefined, Arguments: []Value{chunk}}).ToFloat()
+	if math.IsNaN(s) || s < 0 || math.IsInf(s, 1) {
+		panic(r.newError(r.global.RangeError, "Invalid chunk size"))
+	}
+	return s
+}
+
+func (r *Runtime) streamSourceMethod(source *Object, name unistring.String) func(FunctionCall) Value {
+	if source == nil {
+		return nil
+	}
+	v := source.self.getStr(name, nil)
+	if v == nil || v == _undefined {
+		return nil
+	}
+	return r.toCallable(v)
+}
+
+// ReadableStream
+
+func (r *Runtime) newReadableStreamObject(proto *Object) *readableStreamObject {
+	s := &readableStreamObject{state: "readable", storedError: _undefined}
+	s.class = classObject
+	s.val = &Object{runtime: r, self: s}
+	s.extensible = true
+	s.prototype = proto
+	s.init()
+	return s
+}
+
+func (s *readableStreamObject) locked() bool {
+	return s.reader != nil
+}
+
+func (r *Runtime) setupReadableStreamController(s *readableStreamObject, start func(c *readableStreamControllerObject) Value,
+	pull func() *Object, cancel func(reason Value) *Object, hwm float64, size func(FunctionCall) Value) {
+	c := &readableStreamControllerObject{
+		stream:          s,
+		highWaterMark:   hwm,
+		size:            size,
+		pullAlgorithm:   pull,
+		cancelAlgorithm: cancel,
+	}
+	c.class = classObject
+	c.val = &Object{runtime: r, self: c}
+	c.extensible = true
+	c.prototype = r.global.ReadableStreamDefaultControllerPrototype
+	c.init()
+	s.controller = c
+
+	var startResult Value = _undefined
+	if start != nil {
+		startResult = start(c)
+	}
+	r.awaitValue(startResult, func(Value) {
+		c.started = true
+		r.readableControllerCallPullIfNeeded(c)
+	}, func(e Value) {
+		r.readableControllerError(c, e)
+	})
+}
+
+func (r *Runtime) builtin_newReadableStream(args []Value, newTarget *Object) *Object {
+	if newTarget == nil {
+		panic(r.needNew("ReadableStream"))
+	}
+	var source *Object
+	if arg := argAt(args, 0); arg != _undefined && arg != _null {
+		source = r.toObject(arg)
+	}
+	if source != nil {
+		if typ := source.self.getStr("type", nil); typ != nil && typ != _undefined {
+			if typ.String() == "bytes" {
+				panic(r.newError(r.global.RangeError, "Readable byte streams are not supported"))
+			}
+			panic(r.NewTypeError("Invalid type %s", typ.String()))
+		}
+	}
+	hwm, size := r.extractStrategy(argAt(args, 1), 1)
+	proto := r.getPrototypeFromCtor(newTarget, r.global.ReadableStream, r.global.ReadableStreamPrototype)
+	s := r.newReadableStreamObject(proto)
+
+	startFn := r.streamSourceMethod(source, "start")
+	pullFn := r.streamSourceMethod(source, "pull")
+	cancelFn := r.streamSourceMethod(source, "cancel")
+	r.setupReadableStreamController(s, func(c *readableStreamControllerObject) Value {
+		if startFn == nil {
+			return _undefined
+		}
+		return startFn(FunctionCall{This: source, Arguments: []Value{c.val}})
+	}, func() *Object {
+		return r.promiseCall(pullFn, source, s.controller.val)
+	}, func(reason Value) *Object {
+		return r.promiseCall(cancelFn, source, reason)
+	}, hwm, size)
+	return s.val
+}
+
+// NewReadableStream creates a ReadableStream which reads the data from rd. Each chunk is a Uint8Array holding
+// the bytes returned by a single Read() call. The reads are performed when a consumer requests more data: if the
+// Runtime has an event loop (see NewLoop) they run on a separate goroutine and the chunks are enqueued by the loop,
+// otherwise they block the Runtime. The stream is closed when rd returns io.EOF and is errored with a GoError if it
+// returns any other error. If rd implements io.Closer it is closed when the stream is cancelled.
+//
+// The stream classes do not have to be added to the global object (see EnableStreams) for this to work.
+func (r *Runtime) NewReadableStream(rd io.Reader) *Object {
+	r.initStreams()
+	s := r.newReadableStreamObject(r.global.ReadableStreamPrototype)
+	// the pulls never overlap, so the buffer can be reused
+	buf := make([]byte, streamChunkSize)
+	r.setupReadableStreamController(s, nil, func() *Object {
+		pcap := r.newStreamPromise()
+		var n int
+		r.streamIO(func() (err error) {
+			n, err = rd.Read(buf)
+			return
+		}, func(err error) {
+			// the stream may have been cancelled while reading
+			if c := s.controller; c.canCloseOrEnqueue() {
+				if n > 0 {
+					r.readableControllerEnqueue(c, r.newUint8ArrayFromBytes(buf[:n]))
+				}
+				if err != nil {
+					if err == io.EOF {
+						r.readableControllerClose(c)
+					} else {
+						r.readableControllerError(c, r.NewGoError(err))
+					}
+				}
+			}
+			pcap.resolve(_undefined)
+		})
+		return pcap.promise
+	}, func(Value) *Object {
+		closer, ok := rd.(io.Closer)
+		if !ok {
+			return r.resolvedStreamPromise(_undefined)
+		}
+		return r.streamIOPromise(closer.Close)
+	}, 0, nil)
+	return s.val
+}
+
+// streamIO calls f, which performs blocking I/O, then calls settle with its result. If the Runtime has an event
+// loop, f runs on a separate goroutine and settle is called by the loop, which keeps running until then. Otherwise
+// both are called synchronously.
+func (r *Runtime) streamIO(f func() error, settle func(err error)) {
+	if l := r.loop; l != nil {
+		l.refs++
+		go func() {
+			err := f()
+			l.post(func() error {
+				l.refs--
+				settle(err)
+				return nil
+			})
+		}()
+		return
+	}
+	settle(f())
+}
+
+// streamIOPromise calls f with streamIO and returns a promise which is fulfilled when it succeeds or rejected with a
+// GoError when it fails.
+func (r *Runtime) streamIOPromise(f func() error) *Object {
+	pcap := r.newStreamPromise()
+	r.streamIO(f, func(err error) {
+		if err != nil {
+			pcap.reject(r.NewGoError(err))
+		} else {
+			pcap.resolve(_undefined)
+		}
+	})
+	return pcap.promise
+}
+
+func (r *Runtime) readableStreamClose(s *readableStreamObject) {
+	s.state = "closed"
+	if reader := s.reader; reader != nil {
+		reader.closed.resolve(_undefined)
+		requests := reader.readRequests
+		reader.readRequests = nil
+		for _, req := range requests {
+			req.close()
+		}
+	}
+}
+
+func (r *Runtime) readableStreamError(s *readableStreamObject, e Value) {
+	s.state = "errored"
+	s.storedError = e
+	if reader := s.reader; reader != nil {
+		rejectHandled(reader.closed, e)
+		requests := reader.readRequests
+		reader.readRequests = nil
+		for _, req := range requests {
+			req.error(e)
+		}
+	}
+}
+
+func (r *Runtime) readableStreamCancel(s *readableStreamObject, reason Value) *Object {
+	s.disturbed = true
+	switch s.state {
+	case "closed":
+		return r.resolvedStreamPromise(_undefined)
+	case "errored":
+		return r.rejectedStreamPromise(s.storedError)
+	}
+	r.readableStreamClose(s)
+	c := s.controller
+	c.queue.reset()
+	cancel := c.cancelAlgorithm
+	r.readableControllerClearAlgorithms(c)
+	pcap := r.newStreamPromise()
+	r.awaitValue(cancel(reason), func(Value) {
+		pcap.resolve(_undefined)
+	}, func(e Value) {
+		pcap.reject(e)
+	})
+	return pcap.promise
+}
+
+func (r *Runtime) readableStreamFulfillReadRequest(s *readableStreamObject, chunk Value, done bool) {
+	reader := s.reader
+	req := reader.readRequests[0]
+	reader.readRequests[0] = nil
+	reader.readRequests = reader.readRequests[1:]
+	if done {
+		req.close()
+	} else {
+		req.chunk(chunk)
+	}
+}
+
+func (s *readableStreamObject) numReadRequests() int {
+	if s.reader == nil {
+		return 0
+	}
+	return len(s.reader.readRequests)
+}
+
+// ReadableStreamDefaultController
+
+func (c *readableStreamControllerObject) desiredSize() Value {
+	switch c.stream.state {
+	case "errored":
+		return _null
+	case "closed":
+		return intToValue(0)
+	}
+	return floatToValue(c.highWaterMark - c.queue.totalSize)
+}
+
+func (c *readableStreamControllerObject) canCloseOrEnqueue() bool {
+	return !c.closeRequested && c.stream.state == "readable"
+}
+
+func (r *Runtime) readableControllerClearAlgorithms(c *readableStreamControllerObject) {
+	c.pullAlgorithm = nil
+	c.cancelAlgorithm = func(Value) *Object {
+		return r.resolvedStreamPromise(_undefined)
+	}
+	c.size = nil
+}
+
+func (r *Runtime) readableControllerShouldCallPull(c *readableStreamControllerObject) bool {
+	if !c.canCloseOrEnqueue() || !c.started || c.pullAlgorithm == nil {
+		return false
+	}
+	if c.stream.numReadRequests() > 0 {
+		return true
+	}
+	return c.highWaterMark-c.queue.totalSize > 0
+}
+
+func (r *Runtime) readableControllerCallPullIfNeeded(c *readableStreamControllerObject) {
+	if !r.readableControllerShouldCallPull(c) {
+		return
+	}
+	if c.pulling {
+		c.pullAgain = true
+		return
+	}
+	c.pulling = true
+	r.awaitValue(c.pullAlgorithm(), func(Value) {
+		c.pulling = false
+		if c.pullAgain {
+			c.pullAgain = false
+			r.readableControllerCallPullIfNeeded(c)
+		}
+	}, func(e Value) {
+		r.readableControllerError(c, e)
+	})
+}
+
+func (r *Runtime) readableControllerEnqueue(c *readableStreamControllerObject, chunk Value) {
+	if !c.canCloseOrEnqueue() {
+		panic(r.NewTypeError("The stream is not in a state that permits enqueue"))
+	}
+	s := c.stream
+	if s.numReadRequests() > 0 {
+		r.readableStreamFulfillReadRequest(s, chunk, false)
+	} else {
+		var size float64
+		if ex := r.vm.try(func() {
+			size = r.streamChunkSize(c.size, chunk)
+		}); ex != nil {
+			r.readableControllerError(c, ex.val)
+			panic(ex)
+		}
+		c.queue.enqueue(chunk, size)
+	}
+	r.readableControllerCallPullIfNeeded(c)
+}
+
+func (r *Runtime) readableControllerClose(c *readableStreamControllerObject) {
+	if !c.canCloseOrEnqueue() {
+		panic(r.NewTypeError("The stream is not in a state that permits close"))
+	}
+	c.closeRequested = true
+	if len(c.queue.entries) == 0 {
+		r.readableControllerClearAlgorithms(c)
+		r.readableStreamClose(c.stream)
+	}
+}
+
+func (r *Runtime) readableControllerError(c *readableStreamControllerObject, e Value) {
+	if c.stream.state != "readable" {
+		return
+	}
+	c.queue.reset()
+	r.readableControllerClearAlgorithms(c)
+	r.readableStreamError(c.stream, e)
+}
+
+func (r *Runtime) readableControllerPullSteps(c *readableStreamControllerObject, req *readRequest) {
+	s := c.stream
+	if len(c.queue.entries) > 0 {
+		chunk := c.queue.dequeue()
+		if c.closeRequested && len(c.queue.entries) == 0 {
+			r.readableControllerClearAlgorithms(c)
+			r.readableStreamClose(s)
+		} else {
+			r.readableControllerCallPullIfNeeded(c)
+		}
+		req.chunk(chunk)
+		return
+	}
+	s.reader.readRequests = append(s.reader.readRequests, req)
+	r.readableControllerCallPullIfNeeded(c)
+}
+
+func (r *Runtime) thisReadableStreamController(this Value, method string) *readableStreamControllerObject {
+	if o, ok := this.(*Object); ok {
+		if c, ok := o.self.(*readableStreamControllerObject); ok {
+			return c
+		}
+	}
+	panic(r.NewTypeError("Method ReadableStreamDefaultController.prototype.%s called on incompatible receiver %s", method, r.objectproto_toString(FunctionCall{This: this})))
+}
+
+func (r *Runtime) readableStreamControllerProto_getDesiredSize(call FunctionCall) Value {
+	return r.thisReadableStreamController(call.This, "desiredSize").desiredSize()
+}
+
+func (r *Runtime) readableStreamControllerProto_close(call FunctionCall) Value {
+	r.readableControllerClose(r.thisReadableStreamController(call.This, "close"))
+	return _undefined
+}
+
+func (r *Runtime) readableStreamControllerProto_enqueue(call FunctionCall) Value {
+	r.readableControllerEnqueue(r.thisReadableStreamController(call.This, "enqueue"), call.Argument(0))
+	return _undefined
+}
+
+func (r *Runtime) readableStreamControllerProto_error(call FunctionCall) Value {
+	r.readableControllerError(r.thisReadableStreamController(call.This, "error"), call.Argument(0))
+	return _undefined
+}
+
+// ReadableStreamDefaultReader
+
+func (r *Runtime) acquireReadableStreamReader(s *readableStreamObject, proto *Object) *readableStreamReaderObject {
+	if s.locked() {
+		panic(r.NewTypeError("ReadableStream is locked"))
+	}
+	reader := &readableStreamReaderObject{stream: s}
+	reader.class = classObject
+	reader.val = &Object{runtime: r, self: reader}
+	reader.extensible = true
+	reader.prototype = proto
+	reader.init()
+	s.reader = reader
+	reader.closed = r.newStreamPromise()
+	switch s.state {
+	case "closed":
+		reader.closed.resolve(_undefined)
+	case "errored":
+		rejectHandled(reader.closed, s.storedError)
+	}
+	return reader
+}
+
+func (r *Runtime) readableStreamReaderRead(reader *readableStreamReaderObject, req *readRequest) {
+	s := reader.stream
+	s.disturbed = true
+	switch s.state {
+	case "closed":
+		req.close()
+	case "errored":
+		req.error(s.storedError)
+	default:
+		r.readableControllerPullSteps(s.controller, req)
+	}
+}
+
+func (r *Runtime) readableStreamReaderRelease(reader *readableStreamReaderObject) {
+	s := reader.stream
+	e := r.NewTypeError("Reader was released")
+	if s.state == "readable" {
+		rejectHandled(reader.closed, e)
+	} else {
+		reader.closed = r.newStreamPromise()
+		rejectHandled(reader.closed, e)
+	}
+	s.reader = nil
+	reader.stream = nil
+	requests := reader.readRequests
+	reader.readRequests = nil
+	for _, req := range requests {
+		req.error(e)
+	}
+}
+
+func (r *Runtime) builtin_newReadableStreamDefaultReader(args []Value, newTarget *Object) *Object {
+	if newTarget == nil {
+		panic(r.needNew("ReadableStreamDefaultReader"))
+	}
+	s := r.toReadableStream(argAt(args, 0))
+	proto := r.getPrototypeFromCtor(newTarget, r.global.ReadableStreamDefaultReader, r.global.ReadableStreamDefaultReaderPrototype)
+	return r.acquireReadableStreamReader(s, proto).val
+}
+
+func (r *Runtime) thisReadableStreamReader(this Value, method string) *readableStreamReaderObject {
+	if o, ok := this.(*Object); ok {
+		if reader, ok := o.self.(*readableStreamReaderObject); ok {
+			return reader
+		}
+	}
+	panic(r.NewTypeError("Method ReadableStreamDefaultReader.prototype.%s called on incompatible receiver %s", method, r.objectproto_toString(FunctionCall{This: this})))
+}
+
+func (r *Runtime) readableStreamReaderProto_getClosed(call FunctionCall) Value {
+	return r.thisReadableStreamReader(call.This, "closed").closed.promise
+}
+
+func (r *Runtime) readableStreamReaderProto_read(call FunctionCall) Value {
+	reader := r.thisReadableStreamReader(call.This, "read")
+	if reader.stream == nil {
+		return r.rejectedStreamPromise(r.NewTypeError("Reader was released"))
+	}
+	pcap := r.newStreamPromise()
+	r.readableStreamReaderRead(reader, &readRequest{
+		chunk: func(chunk Value) {
+			pcap.resolve(r.createIterResultObject(chunk, false))
+		},
+		close: func() {
+			pcap.resolve(r.createIterResultObject(_undefined, true))
+		},
+		error: func(e Value) {
+			pcap.reject(e)
+		},
+	})
+	return pcap.promise
+}
+
+func (r *Runtime) readableStreamReaderProto_releaseLock(call FunctionCall) Value {
+	reader := r.thisReadableStreamReader(call.This, "releaseLock")
+	if reader.stream != nil {
+		r.readableStreamReaderRelease(reader)
+	}
+	return _undefined
+}
+
+func (r *Runtime) readableStreamReaderProto_cancel(call FunctionCall) Value {
+	reader := r.thisReadableStreamReader(call.This, "cancel")
+	if reader.stream == nil {
+		return r.rejectedStreamPromise(r.NewTypeError("Reader was released"))
+	}
+	return r.readableStreamCancel(reader.stream, call.Argument(0))
+}
+
+// ReadableStream.prototype
+
+func (r *Runtime) toReadableStream(v Value) *readableStreamObject {
+	if o, ok := v.(*Object); ok {
+		if s, ok := o.self.(*readableStreamObject); ok {
+			return s
+		}
+	}
+	panic(r.NewTypeError("The provided value is not of type 'ReadableStream'"))
+}
+
+func (r *Runtime) thisReadableStream(this Value, method string) *readableStreamObject {
+	if o, ok := this.(*Object); ok {
+		if s, ok := o.self.(*readableStreamObject); ok {
+			return s
+		}
+	}
+	panic(r.NewTypeError("Method ReadableStream.prototype.%s called on incompatible receiver %s", method, r.objectproto_toString(FunctionCall{This: this})))
+}
+
+func (r *Runtime) readableStreamProto_getLocked(call FunctionCall) Value {
+	return r.toBoolean(r.thisReadableStream(call.This, "locked").locked())
+}
+
+func (r *Runtime) readableStreamProto_cancel(call FunctionCall) Value {
+	s := r.thisReadableStream(call.This, "cancel")
+	if s.locked() {
+		return r.rejectedStreamPromise(r.NewTypeError("ReadableStream is locked"))
+	}
+	return r.readableStreamCancel(s, call.Argument(0))
+}
+
+func (r *Runtime) readableStreamProto_getReader(call FunctionCall) Value {
+	s := r.thisReadableStream(call.This, "getReader")
+	if opts := r.webIDLDictionary(call.Argument(0)); opts != nil {
+		if mode := opts.self.getStr("mode", nil); mode != nil && mode != _undefined {
+			if mode.String() == "byob" {
+				panic(r.NewTypeError("BYOB readers are not supported"))
+			}
+			panic(r.NewTypeError("Invalid mode %s", mode.String()))
+		}
+	}
+	return r.acquireReadableStreamReader(s, r.global.ReadableStreamDefaultReaderPrototype).val
+}
+
+func (r *Runtime) readableStreamProto_tee(call FunctionCall) Value {
+	s := r.thisReadableStream(call.This, "tee")
+	b1, b2 := r.readableStreamTee(s)
+	return r.newArrayValues([]Value{b1.val, b2.val})
+}
+
+// readableStreamTee implements ReadableStreamDefaultTee. The chunks are not cloned, both branches receive the same
+// values.
+func (r *Runtime) readableStreamTee(s *readableStreamObject) (*readableStreamObject, *readableStreamObject) {
+	reader := r.acquireReadableStreamReader(s, r.global.ReadableStreamDefaultReaderPrototype)
+	var reading, readAgain, canceled1, canceled2 bool
+	var reason1, reason2 Value = _undefined, _undefined
+	var branch1, branch2 *readableStreamObject
+	cancelPromise := r.newStreamPromise()
+
+	var pull func() *Object
+	pull = func() *Object {
+		if reading {
+			readAgain = true
+			return r.resolvedStreamPromise(_undefined)
+		}
+		reading = true
+		r.readableStreamReaderRead(reader, &readRequest{
+			chunk: func(chunk Value) {
+				r.enqueuePromiseJob(func() {
+					readAgain = false
+					if !canceled1 {
+						r.readableControllerEnqueue(branch1.controller, chunk)
+					}
+					if !canceled2 {
+						r.readableControllerEnqueue(branch2.controller, chunk)
+					}
+					reading = false
+					if readAgain {
+						pull()
+					}
+				})
+			},
+			close: func() {
+				reading = false
+				if !canceled1 {
+					r.readableControllerClose(branch1.controller)
+				}
+				if !canceled2 {
+					r.readableControllerClose(branch2.controller)
+				}
+				if !canceled1 || !canceled2 {
+					cancelPromise.resolve(_undefined)
+				}
+			},
+			error: func(Value) {
+				reading = false
+			},
+		})
+		return r.resolvedStreamPromise(_undefined)
+	}
+	cancel := func(reason Value, first bool) *Object {
+		if first {
+			canceled1, reason1 = true, reason
+		} else {
+			canceled2, reason2 = true, reason
+		}
+		if canceled1 && canceled2 {
+			cancelPromise.resolve(r.readableStreamCancel(s, r.newArrayValues([]Value{reason1, reason2})))
+		}
+		return cancelPromise.promise
+	}
+
+	branch1 = r.newReadableStreamObject(r.global.ReadableStreamPrototype)
+	r.setupReadableStreamController(branch1, nil, pull, func(reason Value) *Object {
+		return cancel(reason, true)
+	}, 1, nil)
+	branch2 = r.newReadableStreamObject(r.global.ReadableStreamPrototype)
+	r.setupReadableStreamController(branch2, nil, pull, func(reason Value) *Object {
+		return cancel(reason, false)
+	}, 1, nil)
+
+	r.awaitValue(reader.closed.promise, func(Value) {}, func(e Value) {
+		r.readableControllerError(branch1.controller, e)
+		r.readableControllerError(branch2.controller, e)
+		if !canceled1 || !canceled2 {
+			cancelPromise.resolve(_undefined)
+		}
+	})
+	return branch1, branch2
+}
+
+func (r *Runtime) readableStreamProto_pipeTo(call FunctionCall) Value {
+	s, ok := call.This.(*Object)
+	if ok {
+		_, ok = s.self.(*readableStreamObject)
+	}
+	if !ok {
+		return r.rejectedStreamPromise(r.NewTypeError("Method ReadableStream.prototype.pipeTo called on incompatible receiver %s", r.objectproto_toString(FunctionCall{This: call.This})))
+	}
+	source := s.self.(*readableStreamObject)
+	var dest *writableStreamObject
+	if o, ok := call.Argument(0).(*Object); ok {
+		dest, _ = o.self.(*writableStreamObject)
+	}
+	if dest == nil {
+		return r.rejectedStreamPromise(r.NewTypeError("The destination is not of type 'WritableStream'"))
+	}
+	var preventClose, preventAbort, preventCancel bool
+	if ex := r.vm.try(func() {
+		preventClose, preventAbort, preventCancel = r.pipeOptions(call.Argument(1))
+	}); ex != nil {
+		return r.rejectedStreamPromise(ex.val)
+	}
+	if source.locked() {
+		return r.rejectedStreamPromise(r.NewTypeError("ReadableStream is locked"))
+	}
+	if dest.locked() {
+		return r.rejectedStreamPromise(r.NewTypeError("WritableStream is locked"))
+	}
+	return r.readableStreamPipeTo(source, dest, preventClose, preventAbort, preventCancel)
+}
+
+func (r *Runtime) pipeOptions(v Value) (preventClose, preventAbort, preventCancel bool) {
+	if opts := r.webIDLDictionary(v); opts != nil {
+		preventAbort = nilSafe(opts.self.getStr("preventAbort", nil)).ToBoolean()
+		preventCancel = nilSafe(opts.self.getStr("preventCancel", nil)).ToBoolean()
+		preventClose = nilSafe(opts.self.getStr("preventClose", nil)).ToBoolean()
+	}
+	return
+}
+
+// readableStreamPipeTo implements ReadableStreamPipeTo. The AbortSignal is not supported.
+func (r *Runtime) readableStreamPipeTo(source *readableStreamObject, dest *writableStreamObject, preventClose, preventAbort, preventCancel bool) *Object {
+	reader := r.acquireReadableStreamReader(source, r.global.ReadableStreamDefaultReaderPrototype)
+	writer := r.acquireWritableStreamWriter(dest, r.global.WritableStreamDefaultWriterPrototype)
+	source.disturbed = true
+	pcap := r.newStreamPromise()
+	shuttingDown := false
+	currentWrite := r.resolvedStreamPromise(_undefined)
+
+	finalize := func(err Value) {
+		r.writableStreamWriterRelease(writer)
+		r.readableStreamReaderRelease(reader)
+		if err != nil {
+			pcap.reject(err)
+		} else {
+			pcap.resolve(_undefined)
+		}
+	}
+	// waitForWrites calls f once the last pending write has completed if the destination is still writable.
+	waitForWrites := func(f func()) {
+		if dest.state == "writable" && !dest.closeQueuedOrInFlight() {
+			r.awaitValue(currentWrite, func(Value) { f() }, func(Value) { f() })
+		} else {
+			f()
+		}
+	}
+	shutdownWithAction := func(action func() *Object, originalError Value) {
+		if shuttingDown {
+			return
+		}
+		shuttingDown = true
+		waitForWrites(func() {
+			r.awaitValue(action(), func(Value) {
+				finalize(originalError)
+			}, func(e Value) {
+				finalize(e)
+			})
+		})
+	}
+	shutdown := func(err Value) {
+		if shuttingDown {
+			return
+		}
+		shuttingDown = true
+		waitForWrites(func() {
+			finalize(err)
+		})
+	}
+
+	sourceErrored := func(e Value) {
+		if !preventAbort {
+			shutdownWithAction(func() *Object {
+				return r.writableStreamAbort(dest, e)
+			}, e)
+		} else {
+			shutdown(e)
+		}
+	}
+	destErrored := func(e Value) {
+		if !preventCancel {
+			shutdownWithAction(func() *Object {
+				return r.readableStreamCancel(source, e)
+			}, e)
+		} else {
+			shutdown(e)
+		}
+	}
+	sourceClosed := func() {
+		if !preventClose {
+			shutdownWithAction(func() *Object {
+				return r.writableStreamWriterCloseWithErrorPropagation(writer)
+			}, nil)
+		} else {
+			shutdown(nil)
+		}
+	}
+	destClosed := func() {
+		e := r.NewTypeError("The destination stream is closed")
+		if !preventCancel {
+			shutdownWithAction(func() *Object {
+				return r.readableStreamCancel(source, e)
+			}, e)
+		} else {
+			shutdown(e)
+		}
+	}
+
+	// checkStates handles the error and closing conditions in the order defined by the specification, it returns
+	// true if the pipe is shutting down.
+	checkStates := func() bool {
+		switch {
+		case source.state == "errored":
+			sourceErrored(source.storedError)
+		case dest.state == "errored" || dest.state == "erroring":
+			destErrored(dest.storedError)
+		case source.state == "closed":
+			sourceClosed()
+		case dest.closeQueuedOrInFlight() || dest.state == "closed":
+			destClosed()
+		}
+		return shuttingDown
+	}
+
+	var step func()
+	step = func() {
+		if shuttingDown || checkStates() {
+			return
+		}
+		r.awaitValue(writer.ready.promise, func(Value) {
+			if shuttingDown || checkStates() {
+				return
+			}
+			r.readableStreamReaderRead(reader, &readRequest{
+				chunk: func(chunk Value) {
+					currentWrite = r.writableStreamWriterWrite(writer, chunk)
+					currentWrite.self.(*Promise).handled = true
+					step()
+				},
+				close: func() {
+					sourceClosed()
+				},
+				error: func(e Value) {
+					sourceErrored(e)
+				},
+			})
+		}, func(Value) {
+			checkStates()
+		})
+	}
+
+	r.awaitValue(reader.closed.promise, func(Value) {}, func(e Value) {
+		sourceErrored(e)
+	})
+	r.awaitValue(writer.closed.promise, func(Value) {}, func(e Value) {
+		destErrored(e)
+	})
+	step()
+	return pcap.promise
+}
+
+func (r *Runtime) readableStreamProto_pipeThrough(call FunctionCall) Value {
+	source := r.thisReadableStream(call.This, "pipeThrough")
+	transform := r.webIDLDictionary(call.Argument(0))
+	if transform == nil {
+		panic(r.NewTypeError("The transform stream is not an object"))
+	}
+	var dest *writableStreamObject
+	if o, ok := nilSafe(transform.self.getStr("writable", nil)).(*Object); ok {
+		dest, _ = o.self.(*writableStreamObject)
+	}
+	if dest == nil {
+		panic(r.NewTypeError("The 'writable' member is not of type 'WritableStream'"))
+	}
+	readable := r.toReadableStream(nilSafe(transform.self.getStr("readable", nil)))
+	preventClose, preventAbort, preventCancel := r.pipeOptions(call.Argument(1))
+	if source.locked() {
+		panic(r.NewTypeError("ReadableStream is locked"))
+	}
+	if dest.locked() {
+		panic(r.NewTypeError("WritableStream is locked"))
+	}
+	p := r.readableStreamPipeTo(source, dest, preventClose, preventAbort, preventCancel)
+	p.self.(*Promise).handled = true
+	return readable.val
+}
+
+func (r *Runtime) readableStreamProto_values(call FunctionCall) Value {
+	s := r.thisReadableStream(call.This, "values")
+	preventCancel := false
+	if opts := r.webIDLDictionary(call.Argument(0)); opts != nil {
+		preventCancel = nilSafe(opts.self.getStr("preventCancel", nil)).ToBoolean()
+	}
+	reader := r.acquireReadableStreamReader(s, r.global.ReadableStreamDefaultReaderPrototype)
+	it := &readableStreamIterObject{reader: reader, preventCancel: preventCancel}
+	it.class = classObject
+	it.val = &Object{runtime: r, self: it}
+	it.extensible = true
+	it.prototype = r.global.ReadableStreamAsyncIteratorPrototype
+	it.init()
+	return it.val
+}
+
+func (r *Runtime) thisReadableStreamIter(this Value, method string) *readableStreamIterObject {
+	if o, ok := this.(*Object); ok {
+		if it, ok := o.self.(*readableStreamIterObject); ok {
+			return it
+		}
+	}
+	panic(r.NewTypeError("Method ReadableStream AsyncIterator.prototype.%s called on incompatible receiver %s", method, r.objectproto_toString(FunctionCall{This: this})))
+}
+
+func (r *Runtime) readableStreamIterProto_next(call FunctionCall) Value {
+	it := r.thisReadableStreamIter(call.This, "next")
+	reader := it.reader
+	if reader.stream == nil {
+		return r.resolvedStreamPromise(r.createIterResultObject(_undefined, true))
+	}
+	pcap := r.newStreamPromise()
+	r.readableStreamReaderRead(reader, &readRequest{
+		chunk: func(chunk Value) {
+			pcap.resolve(r.createIterResultObject(chunk, false))
+		},
+		close: func() {
+			r.readableStreamReaderRelease(reader)
+			pcap.resolve(r.createIterResultObject(_undefined, true))
+		},
+		error: func(e Value) {
+			r.readableStreamReaderRelease(reader)
+			pcap.reject(e)
+		},
+	})
+	return pcap.promise
+}
+
+func (r *Runtime) readableStreamIterProto_return(call FunctionCall) Value {
+	it := r.thisReadableStreamIter(call.This, "return")
+	reader := it.reader
+	value := call.Argument(0)
+	if reader.stream == nil {
+		return r.resolvedStreamPromise(r.createIterResultObject(value, true))
+	}
+	if !it.preventCancel {
+		result := r.readableStreamCancel(reader.stream, value)
+		r.readableStreamReaderRelease(reader)
+		pcap := r.newStreamPromise()
+		r.awaitValue(result, func(Value) {
+			pcap.resolve(r.createIterResultObject(value, true))
+		}, func(e Value) {
+			pcap.reject(e)
+		})
+		return pcap.promise
+	}
+	r.readableStreamReaderRelease(reader)
+	return r.resolvedStreamPromise(r.createIterResultObject(value, true))
+}
+
+// WritableStream
+
+func (r *Runtime) newWritableStreamObject(proto *Object) *writableStreamObject {
+	s := &writableStreamObject{state: "writable", storedError: _undefined}
+	s.class = classObject
+	s.val = &Object{runtime: r, self: s}
+	s.extensible = true
+	s.prototype = proto
+	s.init()
+	return s
+}
+
+func (s *writableStreamObject) locked() bool {
+	return s.writer != nil
+}
+
+func (s *writableStreamObject) closeQueuedOrInFlight() bool {
+	return s.closeRequest != nil || s.inFlightCloseRequest != nil
+}
+
+func (s *writableStreamObject) hasOperationMarkedInFlight() bool {
+	return s.inFlightWriteRequest != nil || s.inFlightCloseRequest != nil
+}
+
+func (r *Runtime) setupWritableStreamController(s *writableStreamObject, start func(c *writableStreamControllerObject) Value,
+	write func(chunk Value) *Object, closeAlg func() *Object, abort func(reason Value) *Object, hwm float64, size func(FunctionCall) Value) {
+	c := &writableStreamControllerObject{
+		stream:         s,
+		highWaterMark:  hwm,
+		size:           size,
+		writeAlgorithm: write,
+		closeAlgorithm: closeAlg,
+		abortAlgorithm: abort,
+	}
+	c.class = classObject
+	c.val = &Object{runtime: r, self: c}
+	c.extensible = true
+	c.prototype = r.global.WritableStreamDefaultControllerPrototype
+	c.init()
+	s.controller = c
+	r.writableStreamUpdateBackpressure(s, c.backpressure())
+
+	var startResult Value = _undefined
+	if start != nil {
+		startResult = start(c)
+	}
+	r.awaitValue(startResult, func(Value) {
+		c.started = true
+		r.writableControllerAdvanceQueueIfNeeded(c)
+	}, func(e Value) {
+		c.started = true
+		r.writableStreamDealWithRejection(s, e)
+	})
+}
+
+func (r *Runtime) builtin_newWritableStream(args []Value, newTarget *Object) *Object {
+	if newTarget == nil {
+		panic(r.needNew("WritableStream"))
+	}
+	var sink *Object
+	if arg := argAt(args, 0); arg != _undefined && arg != _null {
+		sink = r.toObject(arg)
+	}
+	if sink != nil {
+		if typ := sink.self.getStr("type", nil); typ != nil && typ != _undefined {
+			panic(r.newError(r.global.RangeError, "Invalid type %s", typ.String()))
+		}
+	}
+	hwm, size := r.extractStrategy(argAt(args, 1), 1)
+	proto := r.getPrototypeFromCtor(newTarget, r.global.WritableStream, r.global.WritableStreamPrototype)
+	s := r.newWritableStreamObject(proto)
+
+	startFn := r.streamSourceMethod(sink, "start")
+	writeFn := r.streamSourceMethod(sink, "write")
+	closeFn := r.streamSourceMethod(sink, "close")
+	abortFn := r.streamSourceMethod(sink, "abort")
+	r.setupWritableStreamController(s, func(c *writableStreamControllerObject) Value {
+		if startFn == nil {
+			return _undefined
+		}
+		return startFn(FunctionCall{This: sink, Arguments: []Value{c.val}})
+	}, func(chunk Value) *Object {
+		return r.promiseCall(writeFn, sink, chunk, s.controller.val)
+	}, func() *Object {
+		return r.promiseCall(closeFn, sink)
+	}, func(reason Value) *Object {
+		return r.promiseCall(abortFn, sink, reason)
+	}, hwm, size)
+	return s.val
+}
+
+// NewWritableStream creates a WritableStream which writes the chunks to w. The chunks must be ArrayBuffers,
+// TypedArrays or DataViews, writing anything else errors the stream with a TypeError. The writes are performed one
+// at a time: if the Runtime has an event loop (see NewLoop) they run on a separate goroutine (with a copy of the
+// chunk) and their results are reported by the loop, otherwise they block the Runtime. If w implements io.Closer it
+// is closed the same way when the stream is closed or aborted.
+//
+// The stream classes do not have to be added to the global object (see EnableStreams) for this to work.
+func (r *Runtime) NewWritableStream(w io.Writer) *Object {
+	r.initStreams()
+	s := r.newWritableStreamObject(r.global.WritableStreamPrototype)
+	closeWriter := func() *Object {
+		closer, ok := w.(io.Closer)
+		if !ok {
+			return r.resolvedStreamPromise(_undefined)
+		}
+		return r.streamIOPromise(closer.Close)
+	}
+	r.setupWritableStreamController(s, nil, func(chunk Value) *Object {
+		b, ok := r.bufferSourceBytes(chunk)
+		if !ok {
+			return r.rejectedStreamPromise(r.NewTypeError("The chunk is not an ArrayBuffer or ArrayBufferView"))
+		}
+		if r.loop != nil {
+			// the script may modify the chunk while it's being written
+			b = append([]byte(nil), b...)
+		}
+		return r.streamIOPromise(func() error {
+			_, err := w.Write(b)
+			return err
+		})
+	}, closeWriter, func(Value) *Object {
+		return closeWriter()
+	}, 1, nil)
+	return s.val
+}
+
+func (r *Runtime) writableStreamAbort(s *writableStreamObject, reason Value) *Object {
+	if s.state == "closed" || s.state == "errored" {
+		return r.resolvedStreamPromise(_undefined)
+	}
+	if s.pendingAbortRequest != nil {
+		return s.pendingAbortRequest.promise.promise
+	}
+	wasAlreadyErroring := false
+	if s.state == "erroring" {
+		wasAlreadyErroring = true
+		reason = _undefined
+	}
+	pcap := r.newStreamPromise()
+	s.pendingAbortRequest = &pendingAbortRequest{
+		promise:            pcap,
+		reason:             reason,
+		wasAlreadyErroring: wasAlreadyErroring,
+	}
+	if !wasAlreadyErroring {
+		r.writableStreamStartErroring(s, reason)
+	}
+	return pcap.promise
+}
+
+func (r *Runtime) writableStreamClose(s *writableStreamObject) *Object {
+	if s.state == "closed" || s.state == "errored" {
+		return r.rejectedStreamPromise(r.NewTypeError("The stream is closed or errored"))
+	}
+	pcap := r.newStreamPromise()
+	s.closeRequest = pcap
+	if w := s.writer; w != nil && s.backpressure && s.state == "writable" {
+		w.ready.resolve(_undefined)
+	}
+	c := s.controller
+	c.queue.entries = append(c.queue.entries, streamQueueEntry{close: true})
+	r.writableControllerAdvanceQueueIfNeeded(c)
+	return pcap.promise
+}
+
+func (r *Runtime) writableStreamDealWithRejection(s *writableStreamObject, e Value) {
+	if s.state == "writable" {
+		r.writableStreamStartErroring(s, e)
+		return
+	}
+	r.writableStreamFinishErroring(s)
+}
+
+func (r *Runtime) writableStreamStartErroring(s *writableStreamObject, reason Value) {
+	s.state = "erroring"
+	s.storedError = reason
+	if w := s.writer; w != nil {
+		r.writableStreamWriterEnsureReadyPromiseRejected(w, reason)
+	}
+	if !s.hasOperationMarkedInFlight() && s.controller.started {
+		r.writableStreamFinishErroring(s)
+	}
+}
+
+func (r *Runtime) writableStreamFinishErroring(s *writableStreamObject) {
+	s.state = "errored"
+	s.controller.queue.reset()
+	storedError := s.storedError
+	requests := s.writeRequests
+	s.writeRequests = nil
+	for _, req := range requests {
+		req.reject(storedError)
+	}
+	abortRequest := s.pendingAbortRequest
+	if abortRequest == nil {
+		r.writableStreamRejectCloseAndClosedPromiseIfNeeded(s)
+		return
+	}
+	s.pendingAbortRequest = nil
+	if abortRequest.wasAlreadyErroring {
+		abortRequest.promise.reject(storedError)
+		r.writableStreamRejectCloseAndClosedPromiseIfNeeded(s)
+		return
+	}
+	abort := s.controller.abortAlgorithm
+	r.writableControllerClearAlgorithms(s.controller)
+	r.awaitValue(abort(abortRequest.reason), func(Value) {
+		abortRequest.promise.resolve(_undefined)
+		r.writableStreamRejectCloseAndClosedPromiseIfNeeded(s)
+	}, func(e Value) {
+		abortRequest.promise.reject(e)
+		r.writableStreamRejectCloseAndClosedPromiseIfNeeded(s)
+	})
+}
+
+func (r *Runtime) writableStreamRejectCloseAndClosedPromiseIfNeeded(s *writableStreamObject) {
+	if s.closeRequest != nil {
+		s.closeRequest.reject(s.storedError)
+		s.closeRequest = nil
+	}
+	if w := s.writer; w != nil {
+		rejectHandled(w.closed, s.storedError)
+	}
+}
+
+func (r *Runtime) writableStreamFinishInFlightWrite(s *writableStreamObject) {
+	s.inFlightWriteRequest.resolve(_undefined)
+	s.inFlightWriteRequest = nil
+}
+
+func (r *Runtime) writableStreamFinishInFlightWriteWithError(s *writableStreamObject, e Value) {
+	s.inFlightWriteRequest.reject(e)
+	s.inFlightWriteRequest = nil
+	r.writableStreamDealWithRejection(s, e)
+}
+
+func (r *Runtime) writableStreamFinishInFlightClose(s *writableStreamObject) {
+	s.inFlightCloseRequest.resolve(_undefined)
+	s.inFlightCloseRequest = nil
+	if s.state == "erroring" {
+		s.storedError = _undefined
+		if s.pendingAbortRequest != nil {
+			s.pendingAbortRequest.promise.resolve(_undefined)
+			s.pendingAbortRequest = nil
+		}
+	}
+	s.state = "closed"
+	if w := s.writer; w != nil {
+		w.closed.resolve(_undefined)
+	}
+}
+
+func (r *Runtime) writableStreamFinishInFlightCloseWithError(s *writableStreamObject, e Value) {
+	s.inFlightCloseRequest.reject(e)
+	s.inFlightCloseRequest = nil
+	if s.pendingAbortRequest != nil {
+		s.pendingAbortRequest.promise.reject(e)
+		s.pendingAbortRequest = nil
+	}
+	r.writableStreamDealWithRejection(s, e)
+}
+
+func (r *Runtime) writableStreamUpdateBackpressure(s *writableStreamObject, backpressure bool) {
+	if w := s.writer; w != nil && backpressure != s.backpressure {
+		if backpressure {
+			w.ready = r.newStreamPromise()
+		} else {
+			w.ready.resolve(_undefined)
+		}
+	}
+	s.backpressure = backpressure
+}
+
+// WritableStreamDefaultController
+
+func (c *writableStreamControllerObject) desiredSize() float64 {
+	return c.highWaterMark - c.queue.totalSize
+}
+
+func (c *writableStreamControllerObject) backpressure() bool {
+	return c.desiredSize() <= 0
+}
+
+func (r *Runtime) writableControllerClearAlgorithms(c *writableStreamControllerObject) {
+	c.writeAlgorithm = nil
+	c.closeAlgorithm = nil
+	c.abortAlgorithm = nil
+	c.size = nil
+}
+
+func (r *Runtime) writableControllerError(c *writableStreamControllerObject, e Value) {
+	r.writableControllerClearAlgorithms(c)
+	r.writableStreamStartErroring(c.stream, e)
+}
+
+func (r *Runtime) writableControllerErrorIfNeeded(c *writableStreamControllerObject, e Value) {
+	if c.stream.state == "writable" {
+		r.writableControllerError(c, e)
+	}
+}
+
+func (r *Runtime) writableControllerAdvanceQueueIfNeeded(c *writableStreamControllerObject) {
+	s := c.stream
+	if !c.started || s.inFlightWriteRequest != nil {
+		return
+	}
+	switch s.state {
+	case "closed", "errored":
+		return
+	case "erroring":
+		r.writableStreamFinishErroring(s)
+		return
+	}
+	if len(c.queue.entries) == 0 {
+		return
+	}
+	if c.queue.entries[0].close {
+		r.writableControllerProcessClose(c)
+	} else {
+		r.writableControllerProcessWrite(c, c.queue.entries[0].value)
+	}
+}
+
+func (r *Runtime) writableControllerProcessClose(c *writableStreamControllerObject) {
+	s := c.stream
+	s.inFlightCloseRequest = s.closeRequest
+	s.closeRequest = nil
+	c.queue.dequeue()
+	closeAlg := c.closeAlgorithm
+	r.writableControllerClearAlgorithms(c)
+	r.awaitValue(closeAlg(), func(Value) {
+		r.writableStreamFinishInFlightClose(s)
+	}, func(e Value) {
+		r.writableStreamFinishInFlightCloseWithError(s, e)
+	})
+}
+
+func (r *Runtime) writableControllerProcessWrite(c *writableStreamControllerObject, chunk Value) {
+	s := c.stream
+	s.inFlightWriteRequest = s.writeRequests[0]
+	s.writeRequests[0] = nil
+	s.writeRequests = s.writeRequests[1:]
+	r.awaitValue(c.writeAlgorithm(chunk), func(Value) {
+		r.writableStreamFinishInFlightWrite(s)
+		c.queue.dequeue()
+		if !s.closeQueuedOrInFlight() && s.state == "writable" {
+			r.writableStreamUpdateBackpressure(s, c.backpressure())
+		}
+		r.writableControllerAdvanceQueueIfNeeded(c)
+	}, func(e Value) {
+		if s.state == "writable" {
+			r.writableControllerClearAlgorithms(c)
+		}
+		r.writableStreamFinishInFlightWriteWithError(s, e)
+	})
+}
+
+func (r *Runtime) thisWritableStreamController(this Value, method string) *writableStreamControllerObject {
+	if o, ok := this.(*Object); ok {
+		if c, ok := o.self.(*writableStreamControllerObject); ok {
+			return c
+		}
+	}
+	panic(r.NewTypeError("Method WritableStreamDefaultController.prototype.%s called on incompatible receiver %s", method, r.objectproto_toString(FunctionCall{This: this})))
+}
+
+func (r *Runtime) writableStreamControllerProto_error(call FunctionCall) Value {
+	c := r.thisWritableStreamController(call.This, "error")
+	if c.stream.state == "writable" {
+		r.writableControllerError(c, call.Argument(0))
+	}
+	return _undefined
+}
+
+// WritableStreamDefaultWriter
+
+func (r *Runtime) acquireWritableStreamWriter(s *writableStreamObject, proto *Object) *writableStreamWriterObject {
+	if s.locked() {
+		panic(r.NewTypeError("WritableStream is locked"))
+	}
+	w := &writableStreamWriterObject{stream: s}
+	w.class = classObject
+	w.val = &Object{runtime: r, self: w}
+	w.extensible = true
+	w.prototype = proto
+	w.init()
+	s.writer = w
+	w.ready = r.newStreamPromise()
+	w.closed = r.newStreamPromise()
+	switch s.state {
+	case "writable":
+		if s.closeQueuedOrInFlight() || !s.backpressure {
+			w.ready.resolve(_undefined)
+		}
+	case "erroring":
+		rejectHandled(w.ready, s.storedError)
+	case "closed":
+		w.ready.resolve(_undefined)
+		w.closed.resolve(_undefined)
+	default:
+		rejectHandled(w.ready, s.storedError)
+		rejectHandled(w.closed, s.storedError)
+	}
+	return w
+}
+
+func (r *Runtime) writableStreamWriterEnsureReadyPromiseRejected(w *writableStreamWriterObject, e Value) {
+	if !isPendingPromise(w.ready) {
+		w.ready = r.newStreamPromise()
+	}
+	rejectHandled(w.ready, e)
+}
+
+func (r *Runtime) writableStreamWriterEnsureClosedPromiseRejected(w *writableStreamWriterObject, e Value) {
+	if !isPendingPromise(w.closed) {
+		w.closed = r.newStreamPromise()
+	}
+	rejectHandled(w.closed, e)
+}
+
+func (r *Runtime) writableStreamWriterRelease(w *writableStreamWriterObject) {
+	e := r.NewTypeError("Writer was released")
+	r.writableStreamWriterEnsureReadyPromiseRejected(w, e)
+	r.writableStreamWriterEnsureClosedPromiseRejected(w, e)
+	w.stream.writer = nil
+	w.stream = nil
+}
+
+func (r *Runtime) writableStreamWriterWrite(w *writableStreamWriterObject, chunk Value) *Object {
+	s := w.stream
+	c := s.controller
+	size := 1.0
+	if c.size != nil {
+		if ex := r.vm.try(func() {
+			size = r.streamChunkSize(c.size, chunk)
+		}); ex != nil {
+			r.writableControllerErrorIfNeeded(c, ex.val)
+			size = 1
+		}
+	}
+	if s != w.stream {
+		return r.rejectedStreamPromise(r.NewTypeError("Writer was released"))
+	}
+	switch {
+	case s.state == "errored":
+		return r.rejectedStreamPromise(s.storedError)
+	case s.closeQueuedOrInFlight() || s.state == "closed":
+		return r.rejectedStreamPromise(r.NewTypeError("The stream is closing or closed"))
+	case s.state == "erroring":
+		return r.rejectedStreamPromise(s.storedError)
+	}
+	pcap := r.newStreamPromise()
+	s.writeRequests = append(s.writeRequests, pcap)
+	c.queue.enqueue(chunk, size)
+	if !s.closeQueuedOrInFlight() && s.state == "writable" {
+		r.writableStreamUpdateBackpressure(s, c.backpressure())
+	}
+	r.writableControllerAdvanceQueueIfNeeded(c)
+	return pcap.promise
+}
+
+func (r *Runtime) writableStreamWriterCloseWithErrorPropagation(w *writableStreamWriterObject) *Object {
+	s := w.stream
+	switch {
+	case s.closeQueuedOrInFlight() || s.state == "closed":
+		return r.resolvedStreamPromise(_undefined)
+	case s.state == "errored":
+		return r.rejectedStreamPromise(s.storedError)
+	}
+	return r.writableStreamClose(s)
+}
+
+func (r *Runtime) builtin_newWritableStreamDefaultWriter(args []Value, newTarget *Object) *Object {
+	if newTarget == nil {
+		panic(r.needNew("WritableStreamDefaultWriter"))
+	}
+	s := r.toWritableStream(argAt(args, 0))
+	proto := r.getPrototypeFromCtor(newTarget, r.global.WritableStreamDefaultWriter, r.global.WritableStreamDefaultWriterPrototype)
+	return r.acquireWritableStreamWriter(s, proto).val
+}
+
+func (r *Runtime) thisWritableStreamWriter(this Value, method string) *writableStreamWriterObject {
+	if o, ok := this.(*Object); ok {
+		if w, ok := o.self.(*writableStreamWriterObject); ok {
+			return w
+		}
+	}
+	panic(r.NewTypeError("Method WritableStreamDefaultWriter.prototype.%s called on incompatible receiver %s", method, r.objectproto_toString(FunctionCall{This: this})))
+}
+
+func (r *Runtime) writableStreamWriterProto_getClosed(call FunctionCall) Value {
+	return r.thisWritableStreamWriter(call.This, "closed").closed.promise
+}
+
+func (r *Runtime) writableStreamWriterProto_getReady(call FunctionCall) Value {
+	return r.thisWritableStreamWriter(call.This, "ready").ready.promise
+}
+
+func (r *Runtime) writableStreamWriterProto_getDesiredSize(call FunctionCall) Value {
+	w := r.thisWritableStreamWriter(call.This, "desiredSize")
+	if w.stream == nil {
+		panic(r.NewTypeError("Writer was released"))
+	}
+	switch w.stream.state {
+	case "errored", "erroring":
+		return _null
+	case "closed":
+		return intToValue(0)
+	}
+	return floatToValue(w.stream.controller.desiredSize())
+}
+
+func (r *Runtime) writableStreamWriterProto_abort(call FunctionCall) Value {
+	w := r.thisWritableStreamWriter(call.This, "abort")
+	if w.stream == nil {
+		return r.rejectedStreamPromise(r.NewTypeError("Writer was released"))
+	}
+	return r.writableStreamAbort(w.stream, call.Argument(0))
+}
+
+func (r *Runtime) writableStreamWriterProto_close(call FunctionCall) Value {
+	w := r.thisWritableStreamWriter(call.This, "close")
+	if w.stream == nil {
+		return r.rejectedStreamPromise(r.NewTypeError("Writer was released"))
+	}
+	if w.stream.closeQueuedOrInFlight() {
+		return r.rejectedStreamPromise(r.NewTypeError("The stream is closing or closed"))
+	}
+	return r.writableStreamClose(w.stream)
+}
+
+func (r *Runtime) writableStreamWriterProto_write(call FunctionCall) Value {
+	w := r.thisWritableStreamWriter(call.This, "write")
+	if w.stream == nil {
+		return r.rejectedStreamPromise(r.NewTypeError("Writer was released"))
+	}
+	return r.writableStreamWriterWrite(w, call.Argument(0))
+}
+
+func (r *Runtime) writableStreamWriterProto_releaseLock(call FunctionCall) Value {
+	w := r.thisWritableStreamWriter(call.This, "releaseLock")
+	if w.stream != nil {
+		r.writableStreamWriterRelease(w)
+	}
+	return _undefined
+}
+
+// WritableStream.prototype
+
+func (r *Runtime) toWritableStream(v Value) *writableStreamObject {
+	if o, ok := v.(*Object); ok {
+		if s, ok := o.self.(*writableStreamObject); ok {
+			return s
+		}
+	}
+	panic(r.NewTypeError("The provided value is not of type 'WritableStream'"))
+}
+
+func (r *Runtime) thisWritableStream(this Value, method string) *writableStreamObject {
+	if o, ok := this.(*Object); ok {
+		if s, ok := o.self.(*writableStreamObject); ok {
+			return s
+		}
+	}
+	panic(r.NewTypeError("Method WritableStream.prototype.%s called on incompatible receiver %s", method, r.objectproto_toString(FunctionCall{This: this})))
+}
+
+func (r *Runtime) writableStreamProto_getLocked(call FunctionCall) Value {
+	return r.toBoolean(r.thisWritableStream(call.This, "locked").locked())
+}
+
+func (r *Runtime) writableStreamProto_abort(call FunctionCall) Value {
+	s := r.thisWritableStream(call.This, "abort")
+	if s.locked() {
+		return r.rejectedStreamPromise(r.NewTypeError("WritableStream is locked"))
+	}
+	return r.writableStreamAbort(s, call.Argument(0))
+}
+
+func (r *Runtime) writableStreamProto_close(call FunctionCall) Value {
+	s := r.thisWritableStream(call.This, "close")
+	if s.locked() {
+		return r.rejectedStreamPromise(r.NewTypeError("WritableStream is locked"))
+	}
+	if s.closeQueuedOrInFlight() {
+		return r.rejectedStreamPromise(r.NewTypeError("The stream is closing or closed"))
+	}
+	return r.writableStreamClose(s)
+}
+
+func (r *Runtime) writableStreamProto_getWriter(call FunctionCall) Value {
+	s := r.thisWritableStream(call.This, "getWriter")
+	return r.acquireWritableStreamWriter(s, r.global.WritableStreamDefaultWriterPrototype).val
+}
+
+func (r *Runtime) builtin_newStreamController(args []Value, newTarget *Object) *Object {
+	panic(r.NewTypeError("Illegal constructor"))
+}
+
+func (r *Runtime) createReadableStreamProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("constructor", r.global.ReadableStream, true, false, true)
+	r.putWebIDLAccessor(o, "locked", r.readableStreamProto_getLocked, nil)
+	o._putProp("cancel", r.newNativeFunc(r.readableStreamProto_cancel, nil, "cancel", nil, 0), true, true, true)
+	o._putProp("getReader", r.newNativeFunc(r.readableStreamProto_getReader, nil, "getReader", nil, 0), true, true, true)
+	o._putProp("pipeThrough", r.newNativeFunc(r.readableStreamProto_pipeThrough, nil, "pipeThrough", nil, 1), true, true, true)
+	o._putProp("pipeTo", r.newNativeFunc(r.readableStreamProto_pipeTo, nil, "pipeTo", nil, 1), true, true, true)
+	o._putProp("tee", r.newNativeFunc(r.readableStreamProto_tee, nil, "tee", nil, 0), true, true, true)
+	values := r.newNativeFunc(r.readableStreamProto_values, nil, "values", nil, 0)
+	o._putProp("values", values, true, true, true)
+	o._putSym(SymAsyncIterator, valueProp(values, true, false, true))
+	o._putSym(SymToStringTag, valueProp(asciiString("ReadableStream"), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) createReadableStream(val *Object) objectImpl {
+	return r.newNativeConstructOnly(val, r.builtin_newReadableStream, r.global.ReadableStreamPrototype, "ReadableStream", 0)
+}
+
+func (r *Runtime) createReadableStreamAsyncIterProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.AsyncIteratorPrototype, classObject)
+
+	o._putProp("next", r.newNativeFunc(r.readableStreamIterProto_next, nil, "next", nil, 0), true, true, true)
+	o._putProp("return", r.newNativeFunc(r.readableStreamIterProto_return, nil, "return", nil, 1), true, true, true)
+
+	return o
+}
+
+func (r *Runtime) createReadableStreamDefaultReaderProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("constructor", r.global.ReadableStreamDefaultReader, true, false, true)
+	r.putWebIDLAccessor(o, "closed", r.readableStreamReaderProto_getClosed, nil)
+	o._putProp("cancel", r.newNativeFunc(r.readableStreamReaderProto_cancel, nil, "cancel", nil, 0), true, true, true)
+	o._putProp("read", r.newNativeFunc(r.readableStreamReaderProto_read, nil, "read", nil, 0), true, true, true)
+	o._putProp("releaseLock", r.newNativeFunc(r.readableStreamReaderProto_releaseLock, nil, "releaseLock", nil, 0), true, true, true)
+	o._putSym(SymToStringTag, valueProp(asciiString("ReadableStreamDefaultReader"), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) createReadableStreamDefaultReader(val *Object) objectImpl {
+	return r.newNativeConstructOnly(val, r.builtin_newReadableStreamDefaultReader, r.global.ReadableStreamDefaultReaderPrototype, "ReadableStreamDefaultReader", 1)
+}
+
+func (r *Runtime) createReadableStreamDefaultControllerProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("constructor", r.global.ReadableStreamDefaultController, true, false, true)
+	r.putWebIDLAccessor(o, "desiredSize", r.readableStreamControllerProto_getDesiredSize, nil)
+	o._putProp("close", r.newNativeFunc(r.readableStreamControllerProto_close, nil, "close", nil, 0), true, true, true)
+	o._putProp("enqueue", r.newNativeFunc(r.readableStreamControllerProto_enqueue, nil, "enqueue", nil, 0), true, true, true)
+	o._putProp("error", r.newNativeFunc(r.readableStreamControllerProto_error, nil, "error", nil, 0), true, true, true)
+	o._putSym(SymToStringTag, valueProp(asciiString("ReadableStreamDefaultController"), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) createReadableStreamDefaultController(val *Object) objectImpl {
+	return r.newNativeConstructOnly(val, r.builtin_newStreamController, r.global.ReadableStreamDefaultControllerPrototype, "ReadableStreamDefaultController", 0)
+}
+
+func (r *Runtime) createWritableStreamProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("constructor", r.global.WritableStream, true, false, true)
+	r.putWebIDLAccessor(o, "locked", r.writableStreamProto_getLocked, nil)
+	o._putProp("abort", r.newNativeFunc(r.writableStreamProto_abort, nil, "abort", nil, 0), true, true, true)
+	o._putProp("close", r.newNativeFunc(r.writableStreamProto_close, nil, "close", nil, 0), true, true, true)
+	o._putProp("getWriter", r.newNativeFunc(r.writableStreamProto_getWriter, nil, "getWriter", nil, 0), true, true, true)
+	o._putSym(SymToStringTag, valueProp(asciiString("WritableStream"), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) createWritableStream(val *Object) objectImpl {
+	return r.newNativeConstructOnly(val, r.builtin_newWritableStream, r.global.WritableStreamPrototype, "WritableStream", 0)
+}
+
+func (r *Runtime) createWritableStreamDefaultWriterProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("constructor", r.global.WritableStreamDefaultWriter, true, false, true)
+	r.putWebIDLAccessor(o, "closed", r.writableStreamWriterProto_getClosed, nil)
+	r.putWebIDLAccessor(o, "desiredSize", r.writableStreamWriterProto_getDesiredSize, nil)
+	r.putWebIDLAccessor(o, "ready", r.writableStreamWriterProto_getReady, nil)
+	o._putProp("abort", r.newNativeFunc(r.writableStreamWriterProto_abort, nil, "abort", nil, 0), true, true, true)
+	o._putProp("close", r.newNativeFunc(r.writableStreamWriterProto_close, nil, "close", nil, 0), true, true, true)
+	o._putProp("releaseLock", r.newNativeFunc(r.writableStreamWriterProto_releaseLock, nil, "releaseLock", nil, 0), true, true, true)
+	o._putProp("write", r.newNativeFunc(r.writableStreamWriterProto_write, nil, "write", nil, 0), true, true, true)
+	o._putSym(SymToStringTag, valueProp(asciiString("WritableStreamDefaultWriter"), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) createWritableStreamDefaultWriter(val *Object) objectImpl {
+	return r.newNativeConstructOnly(val, r.builtin_newWritableStreamDefaultWriter, r.global.WritableStreamDefaultWriterPrototype, "WritableStreamDefaultWriter", 1)
+}
+
+func (r *Runtime) createWritableStreamDefaultControllerProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("constructor", r.global.WritableStreamDefaultController, true, false, true)
+	o._putProp("error", r.newNativeFunc(r.writableStreamControllerProto_error, nil, "error", nil, 0), true, true, true)
+	o._putSym(SymToStringTag, valueProp(asciiString("WritableStreamDefaultController"), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) createWritableStreamDefaultController(val *Object) objectImpl {
+	return r.newNativeConstructOnly(val, r.builtin_newStreamController, r.global.WritableStreamDefaultControllerPrototype, "WritableStreamDefaultController", 0)
+}
+
+func (r *Runtime) initStreams() {
+	if r.global.ReadableStream != nil {
+		return
+	}
+	r.global.ReadableStreamPrototype = r.newLazyObject(r.createReadableStreamProto)
+	r.global.ReadableStream = r.newLazyObject(r.createReadableStream)
+	r.global.ReadableStreamAsyncIteratorPrototype = r.newLazyObject(r.createReadableStreamAsyncIterProto)
+	r.global.ReadableStreamDefaultReaderPrototype = r.newLazyObject(r.createReadableStreamDefaultReaderProto)
+	r.global.ReadableStreamDefaultReader = r.newLazyObject(r.createReadableStreamDefaultReader)
+	r.global.ReadableStreamDefaultControllerPrototype = r.newLazyObject(r.createReadableStreamDefaultControllerProto)
+	r.global.ReadableStreamDefaultController = r.newLazyObject(r.createReadableStreamDefaultController)
+
+	r.global.WritableStreamPrototype = r.newLazyObject(r.createWritableStreamProto)
+	r.global.WritableStream = r.newLazyObject(r.createWritableStream)
+	r.global.WritableStreamDefaultWriterPrototype = r.newLazyObject(r.createWritableStreamDefaultWriterProto)
+	r.global.WritableStreamDefaultWriter = r.newLazyObject(r.createWritableStreamDefaultWriter)
+	r.global.WritableStreamDefaultControllerPrototype = r.newLazyObject(r.createWritableStreamDefaultControllerProto)
+	r.global.WritableStreamDefaultController = r.newLazyObject(r.createWritableStreamDefaultController)
+}
+
+// EnableStreams adds the ReadableStream and WritableStream globals along with their default readers, writers and
+// controllers as described in https://streams.spec.whatwg.org/. Readable byte streams, BYOB readers and the
+// AbortSignal option of pipeTo() are not supported, and tee() does not clone the chunks.
+//
+// Use NewReadableStream and NewWritableStream to create streams backed by an io.Reader or an io.Writer.
+func (r *Runtime) EnableStreams() {
+	if r.streamsEnabled {
+		return
+	}
+	r.streamsEnabled = true
+	r.initStreams()
+	r.addToGlobal("ReadableStream", r.global.ReadableStream)
+	r.addToGlobal("ReadableStreamDefaultReader", r.global.ReadableStreamDefaultReader)
+	r.addToGlobal("ReadableStreamDefaultController", r.global.ReadableStreamDefaultController)
+	r.addToGlobal("WritableStream", r.global.WritableStream)
+	r.addToGlobal("WritableStreamDefaultWriter", r.global.WritableStreamDefaultWriter)
+	r.addToGlobal("WritableStreamDefaultController", r.global.WritableStreamDefaultController)
+}
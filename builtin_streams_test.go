@@ -0,0 +1,247 @@
+package goja
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestStreamsDisabled(t *testing.T) {
+	testScript(`typeof ReadableStream + typeof WritableStream`, asciiString("undefinedundefined"), t)
+}
+
+func TestReadableStream(t *testing.T) {
+	const SCRIPT = `
+	var pulls = 0;
+	var rs = new ReadableStream({
+		start(c) {
+			c.enqueue("a");
+			c.enqueue("b");
+		},
+		pull(c) {
+			pulls++;
+			if (pulls === 1) {
+				c.enqueue("c");
+			} else {
+				c.close();
+			}
+		}
+	}, { highWaterMark: 2 });
+	var reader = rs.getReader();
+	assert.sameValue(rs.locked, true, "locked");
+	assert.throws(TypeError, () => rs.getReader(), "already locked");
+	var chunks = [];
+	for (;;) {
+		var { value, done } = await reader.read();
+		if (done) break;
+		chunks.push(value);
+	}
+	assert(compareArray(chunks, ["a", "b", "c"]), "chunks: " + chunks);
+	await reader.closed;
+	reader.releaseLock();
+	assert.sameValue(rs.locked, false, "released");
+
+	var errored = new ReadableStream({ start(c) { c.error(new RangeError("boom")) } });
+	await errored.getReader().read().then(() => { throw new Error("should fail") }, e => assert(e instanceof RangeError, "errored"));
+
+	var cancelReason;
+	var cancelled = new ReadableStream({ cancel(reason) { cancelReason = reason } });
+	await cancelled.cancel("why");
+	assert.sameValue(cancelReason, "why", "cancel reason");
+	assert((await cancelled.getReader().read()).done, "closed after cancel");
+
+	var iterated = [];
+	for await (var chunk of new ReadableStream({ start(c) { c.enqueue(1); c.enqueue(2); c.close() } })) {
+		iterated.push(chunk);
+	}
+	assert(compareArray(iterated, [1, 2]), "async iteration: " + iterated);
+
+	assert.throws(TypeError, () => ReadableStream(), "needs new");
+	assert.throws(RangeError, () => new ReadableStream({ type: "bytes" }), "bytes");
+	assert.throws(TypeError, () => new ReadableStreamDefaultController(), "controller");
+	assert.sameValue(Object.prototype.toString.call(rs), "[object ReadableStream]");
+	`
+	r := New()
+	r.EnableStreams()
+	runAsyncTestScript(r, SCRIPT, t)
+}
+
+func TestReadableStreamTee(t *testing.T) {
+	const SCRIPT = `
+	var rs = new ReadableStream({ start(c) { c.enqueue("x"); c.enqueue("y"); c.close() } });
+	var [a, b] = rs.tee();
+	assert.sameValue(rs.locked, true, "source is locked");
+	async function collect(s) {
+		var res = [];
+		for await (var chunk of s) res.push(chunk);
+		return res.join();
+	}
+	assert.sameValue(await collect(a), "x,y", "first branch");
+	assert.sameValue(await collect(b), "x,y", "second branch");
+
+	var reason;
+	var [c, d] = new ReadableStream({ cancel(r) { reason = r } }).tee();
+	c.cancel(1);
+	await d.cancel(2);
+	assert(compareArray(reason, [1, 2]), "composite reason");
+	`
+	r := New()
+	r.EnableStreams()
+	runAsyncTestScript(r, SCRIPT, t)
+}
+
+func TestWritableStream(t *testing.T) {
+	const SCRIPT = `
+	var written = [], closed = false;
+	var ws = new WritableStream({
+		write(chunk) {
+			return new Promise(resolve => { written.push(chunk); resolve() });
+		},
+		close() {
+			closed = true;
+		}
+	}, { highWaterMark: 2 });
+	var writer = ws.getWriter();
+	assert.sameValue(writer.desiredSize, 2, "desiredSize");
+	writer.write("a");
+	writer.write("b");
+	assert.sameValue(writer.desiredSize, 0, "backpressure");
+	await writer.ready;
+	await writer.close();
+	assert(compareArray(written, ["a", "b"]), "written: " + written);
+	assert(closed, "sink closed");
+	await writer.closed;
+	await writer.write("c").then(() => { throw new Error("should fail") }, e => assert(e instanceof TypeError, "write after close"));
+
+	var abortReason;
+	var aborted = new WritableStream({ abort(r) { abortReason = r } });
+	await aborted.abort("stop");
+	assert.sameValue(abortReason, "stop", "abort reason");
+	await aborted.getWriter().closed.then(() => { throw new Error("should fail") }, e => assert.sameValue(e, "stop"));
+
+	var failing = new WritableStream({ write() { throw new RangeError("no") } });
+	var w = failing.getWriter();
+	await w.write(1).then(() => { throw new Error("should fail") }, e => assert(e instanceof RangeError, "write error"));
+	await w.closed.then(() => { throw new Error("should fail") }, e => assert(e instanceof RangeError, "errored"));
+	`
+	r := New()
+	r.EnableStreams()
+	runAsyncTestScript(r, SCRIPT, t)
+}
+
+func TestReadableStreamPipeTo(t *testing.T) {
+	const SCRIPT = `
+	var written = [], closed = false;
+	var rs = new ReadableStream({ start(c) { ["a", "b", "c"].forEach(x => c.enqueue(x)); c.close() } });
+	var ws = new WritableStream({ write(chunk) { written.push(chunk) }, close() { closed = true } });
+	await rs.pipeTo(ws);
+	assert(compareArray(written, ["a", "b", "c"]), "written: " + written);
+	assert(closed, "closed");
+	assert.sameValue(rs.locked || ws.locked, false, "released");
+
+	var abortReason;
+	var failing = new ReadableStream({ start(c) { c.enqueue(1); c.error("bad") } });
+	await failing.pipeTo(new WritableStream({ abort(r) { abortReason = r } })).then(() => { throw new Error("should fail") }, e => assert.sameValue(e, "bad"));
+	assert.sameValue(abortReason, "bad", "destination aborted");
+
+	var cancelReason;
+	var source = new ReadableStream({ pull(c) { c.enqueue(0) }, cancel(r) { cancelReason = r } });
+	await source.pipeTo(new WritableStream({ write() { throw "full" } })).then(() => { throw new Error("should fail") }, e => assert.sameValue(e, "full"));
+	assert.sameValue(cancelReason, "full", "source cancelled");
+
+	written = [];
+	var out = new ReadableStream({ start(c) { c.enqueue("t"); c.close() } }).pipeThrough({
+		writable: new WritableStream({ write(chunk) { written.push(chunk) } }),
+		readable: new ReadableStream({ start(c) { c.close() } })
+	});
+	assert(out instanceof ReadableStream, "pipeThrough returns the readable side");
+	`
+	r := New()
+	r.EnableStreams()
+	runAsyncTestScript(r, SCRIPT, t)
+}
+
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+type errWriter struct{}
+
+func (errWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestGoStreams(t *testing.T) {
+	r := New()
+	var out bytes.Buffer
+	src := &closeRecorder{Reader: strings.NewReader("hello, world")}
+	r.Set("src", r.NewReadableStream(src))
+	r.Set("dst", r.NewWritableStream(&out))
+	r.Set("one", r.NewReadableStream(strings.NewReader("x")))
+	r.Set("bad", r.NewWritableStream(errWriter{}))
+	runAsyncTestScript(r, `
+	assert.sameValue(typeof ReadableStream, "undefined", "no globals");
+	await src.pipeTo(dst);
+	await one.pipeTo(bad).then(() => { throw new Error("should fail") }, e => assert(e instanceof GoError, "Go error: " + e));
+	`, t)
+	if out.String() != "hello, world" {
+		t.Fatal(out.String())
+	}
+
+	src = &closeRecorder{Reader: strings.NewReader("data")}
+	r.Set("src", r.NewReadableStream(src))
+	runAsyncTestScript(r, `
+	var reader = src.getReader();
+	var { value } = await reader.read();
+	assert(value instanceof Uint8Array, "Uint8Array chunks");
+	await reader.cancel();
+	`, t)
+	if !src.closed {
+		t.Fatal("reader was not closed")
+	}
+}
+
+func TestGoStreamsLoop(t *testing.T) {
+	pr, pw := io.Pipe()
+	var out bytes.Buffer
+	r := New()
+	r.Set("src", r.NewReadableStream(pr))
+	r.Set("dst", r.NewWritableStream(&out))
+	r.Set("produce", func() {
+		go func() {
+			_, _ = pw.Write([]byte("hello"))
+			_ = pw.Close()
+		}()
+	})
+	loop := NewLoop(r)
+	err := loop.Run(func(r *Runtime) {
+		_, err := r.RunString(`
+		var log = [];
+		src.pipeTo(dst).then(() => log.push("piped"), e => log.push(String(e)));
+		setTimeout(() => {
+			log.push("timer");
+			produce();
+		}, 0);
+		`)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if log := r.Get("log").String(); log != "timer,piped" {
+		t.Fatal(log)
+	}
+	if out.String() != "hello" {
+		t.Fatal(out.String())
+	}
+}
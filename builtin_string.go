@@ -8,6 +8,7 @@ import (
 	"unicode/utf8"
 
 	"github.com/dop251/goja/parser"
+	"golang.org/x/text/cases"
 	"golang.org/x/text/collate"
 	"golang.org/x/text/language"
 	"golang.org/x/text/unicode/norm"
@@ -141,7 +142,7 @@ func (r *Runtime) string_raw(call FunctionCall) Value {
 	if literalSegments <= 0 {
 		return stringEmpty
 	}
-	var stringElements valueStringBuilder
+	stringElements := limitedStringBuilder{r: r}
 	nextIndex := int64(0)
 	numberOfSubstitutions := int64(len(call.Arguments) - 1)
 	for {
@@ -239,6 +240,7 @@ func (r *Runtime) stringproto_concat(call FunctionCall) Value {
 		}
 	}
 
+	r.checkStringLength(int64(totalLen))
 	if allAscii {
 		var buf strings.Builder
 		buf.Grow(totalLen)
@@ -360,9 +362,15 @@ func (r *Runtime) stringproto_lastIndexOf(call FunctionCall) Value {
 
 func (r *Runtime) stringproto_localeCompare(call FunctionCall) Value {
 	r.checkObjectCoercible(call.This)
-	this := norm.NFD.String(call.This.toString().String())
-	that := norm.NFD.String(call.Argument(0).toString().String())
-	return intToValue(int64(r.collator().CompareString(this, that)))
+	this := call.This.toString()
+	that := call.Argument(0).toString()
+	collator := intlCollator{Collator: r.collator()}
+	if locales, options := call.Argument(1), call.Argument(2); locales != _undefined || options != _undefined {
+		c := r.newIntlCollatorObject(r.global.IntlCollatorPrototype)
+		c.initCollator(r, locales, options)
+		collator = c.collator
+	}
+	return intToValue(int64(collator.compare(this, that)))
 }
 
 func (r *Runtime) stringproto_match(call FunctionCall) Value {
@@ -429,6 +437,31 @@ func (r *Runtime) stringproto_matchAll(call FunctionCall) Value {
 	panic(r.NewTypeError("RegExp matcher is not a function"))
 }
 
+func (r *Runtime) stringproto_isWellFormed(call FunctionCall) Value {
+	r.checkObjectCoercible(call.This)
+	_, u := devirtualizeString(call.This.toString())
+	return r.toBoolean(u == nil || u.loneSurrogateIndex(0) == -1)
+}
+
+func (r *Runtime) stringproto_toWellFormed(call FunctionCall) Value {
+	r.checkObjectCoercible(call.This)
+	s := call.This.toString()
+	_, u := devirtualizeString(s)
+	if u == nil {
+		return s
+	}
+	idx := u.loneSurrogateIndex(0)
+	if idx == -1 {
+		return s
+	}
+	res := make(unicodeString, len(u))
+	copy(res, u)
+	for ; idx != -1; idx = u.loneSurrogateIndex(idx + 1) {
+		res[idx+1] = 0xFFFD
+	}
+	return res
+}
+
 func (r *Runtime) stringproto_normalize(call FunctionCall) Value {
 	r.checkObjectCoercible(call.This)
 	s := call.This.toString()
@@ -490,6 +523,7 @@ func (r *Runtime) _stringPad(call FunctionCall, start bool) Value {
 		fillerAscii = " "
 		filler = fillerAscii
 	}
+	r.checkStringLength(maxLength)
 	remaining := toIntStrict(maxLength - stringLength)
 	if fillerUnicode == nil && strUnicode == nil {
 		fl := fillerAscii.length()
@@ -552,6 +586,9 @@ func (r *Runtime) stringproto_repeat(call FunctionCall) Value {
 	if numInt == 0 || s.length() == 0 {
 		return stringEmpty
 	}
+	if r.maxStringLength > 0 && numInt > int64(r.maxStringLength/s.length()) {
+		panic(r.newError(r.global.RangeError, "Invalid string length"))
+	}
 	num := toIntStrict(numInt)
 	a, u := devirtualizeString(s)
 	if u == nil {
@@ -582,7 +619,9 @@ func getReplaceValue(replaceValue Value) (str valueString, rcall func(FunctionCa
 	return
 }
 
-func stringReplace(s valueString, found [][]int, newstring valueString, rcall func(FunctionCall) Value) Value {
+// stringReplace replaces the matches in found using either newstring or rcall. groupNames holds the
+// capturing group names of the regexp that produced the matches (nil if it has no named groups).
+func (r *Runtime) stringReplace(s valueString, found [][]int, newstring valueString, rcall func(FunctionCall) Value, groupNames []string) Value {
 	if len(found) == 0 {
 		return s
 	}
@@ -599,7 +638,7 @@ func stringReplace(s valueString, found [][]int, newstring valueString, rcall fu
 				buf.WriteSubstring(s, lastIndex, item[0])
 			}
 			matchCount := len(item) / 2
-			argumentList := make([]Value, matchCount+2)
+			argumentList := make([]Value, matchCount+2, matchCount+3)
 			for index := 0; index < matchCount; index++ {
 				offset := 2 * index
 				if item[offset] != -1 {
@@ -614,11 +653,15 @@ func stringReplace(s valueString, found [][]int, newstring valueString, rcall fu
 			}
 			argumentList[matchCount] = valueInt(item[0])
 			argumentList[matchCount+1] = s
+			if groupNames != nil {
+				argumentList = append(argumentList, r.newGroupsObject(groupNames, argumentList))
+			}
 			replacement := rcall(FunctionCall{
 				This:      _undefined,
 				Arguments: argumentList,
 			}).toString()
 			buf.WriteString(replacement)
+			r.checkStringLength(int64(buf.length()))
 			lastIndex = item[1]
 		}
 	} else {
@@ -627,7 +670,7 @@ func stringReplace(s valueString, found [][]int, newstring valueString, rcall fu
 				buf.WriteString(s.substring(lastIndex, item[0]))
 			}
 			matchCount := len(item) / 2
-			writeSubstitution(s, item[0], matchCount, func(idx int) valueString {
+			getCapture := func(idx int) valueString {
 				if item[idx*2] != -1 {
 					if u == nil {
 						return a[item[idx*2]:item[idx*2+1]]
@@ -635,13 +678,27 @@ func stringReplace(s valueString, found [][]int, newstring valueString, rcall fu
 					return u.substring(item[idx*2], item[idx*2+1])
 				}
 				return stringEmpty
-			}, newstring, &buf)
+			}
+			var getNamedCapture func(unistring.String) valueString
+			if groupNames != nil {
+				getNamedCapture = func(name unistring.String) valueString {
+					for i, groupName := range groupNames {
+						if groupName != "" && unistring.NewFromString(groupName) == name {
+							return getCapture(i + 1)
+						}
+					}
+					return stringEmpty
+				}
+			}
+			writeSubstitution(s, item[0], matchCount, getCapture, getNamedCapture, newstring, &buf)
+			r.checkStringLength(int64(buf.length()))
 			lastIndex = item[1]
 		}
 	}
 
 	if lastIndex != lengthS {
 		buf.WriteString(s.substring(lastIndex, lengthS))
+		r.checkStringLength(int64(buf.length()))
 	}
 
 	return buf.String()
@@ -669,7 +726,50 @@ func (r *Runtime) stringproto_replace(call FunctionCall) Value {
 	}
 
 	str, rcall := getReplaceValue(replaceValue)
-	return stringReplace(s, found, str, rcall)
+	return r.stringReplace(s, found, str, rcall, nil)
+}
+
+func (r *Runtime) stringproto_replaceAll(call FunctionCall) Value {
+	r.checkObjectCoercible(call.This)
+	searchValue := call.Argument(0)
+	replaceValue := call.Argument(1)
+	if searchValue != _undefined && searchValue != _null {
+		if isRegexp(searchValue) {
+			if o, ok := searchValue.(*Object); ok {
+				flags := nilSafe(o.self.getStr("flags", nil))
+				r.checkObjectCoercible(flags)
+				if !strings.Contains(flags.toString().String(), "g") {
+					panic(r.NewTypeError("replaceAll must be called with a global RegExp"))
+				}
+			}
+		}
+		if replacer := toMethod(r.getV(searchValue, SymReplace)); replacer != nil {
+			return replacer(FunctionCall{
+				This:      searchValue,
+				Arguments: []Value{call.This, replaceValue},
+			})
+		}
+	}
+
+	s := call.This.toString()
+	var found [][]int
+	searchStr := searchValue.toString()
+	searchLength := searchStr.length()
+	advanceBy := searchLength
+	if advanceBy == 0 {
+		advanceBy = 1
+	}
+	lengthS := s.length()
+	for pos := s.index(searchStr, 0); pos != -1; {
+		found = append(found, []int{pos, pos + searchLength})
+		if pos += advanceBy; pos > lengthS {
+			break
+		}
+		pos = s.index(searchStr, pos)
+	}
+
+	str, rcall := getReplaceValue(replaceValue)
+	return r.stringReplace(s, found, str, rcall, nil)
 }
 
 func (r *Runtime) stringproto_search(call FunctionCall) Value {
@@ -872,6 +972,59 @@ func (r *Runtime) stringproto_toUpperCase(call FunctionCall) Value {
 	return s.toUpper()
 }
 
+// caseMappingLanguages are the languages with language-sensitive case mappings (see
+// https://www.unicode.org/Public/UCD/latest/ucd/SpecialCasing.txt).
+var caseMappingLanguages = map[string]language.Tag{
+	"az": language.Azerbaijani,
+	"lt": language.Lithuanian,
+	"tr": language.Turkish,
+}
+
+// localeCaser implements the locale selection of https://tc39.es/ecma402/#sec-transform-case. It returns nil if
+// the locale has no special case mappings.
+func (r *Runtime) localeCaser(locales Value, upper bool) *cases.Caser {
+	var locale string
+	if requested := r.intlCanonicalizeLocaleList(locales); len(requested) > 0 {
+		locale = requested[0]
+	} else {
+		locale = r.locales().DefaultLocale()
+	}
+	t, err := language.Parse(parseIntlLocale(locale).base)
+	if err != nil {
+		return nil
+	}
+	base, _ := t.Base()
+	tag, ok := caseMappingLanguages[base.String()]
+	if !ok {
+		return nil
+	}
+	var c cases.Caser
+	if upper {
+		c = cases.Upper(tag)
+	} else {
+		c = cases.Lower(tag)
+	}
+	return &c
+}
+
+func (r *Runtime) stringproto_toLocaleLowerCase(call FunctionCall) Value {
+	r.checkObjectCoercible(call.This)
+	s := call.This.toString()
+	if c := r.localeCaser(call.Argument(0), false); c != nil {
+		return newStringValue(c.String(s.String()))
+	}
+	return s.toLower()
+}
+
+func (r *Runtime) stringproto_toLocaleUpperCase(call FunctionCall) Value {
+	r.checkObjectCoercible(call.This)
+	s := call.This.toString()
+	if c := r.localeCaser(call.Argument(0), true); c != nil {
+		return newStringValue(c.String(s.String()))
+	}
+	return s.toUpper()
+}
+
 func (r *Runtime) stringproto_trim(call FunctionCall) Value {
 	r.checkObjectCoercible(call.This)
 	s := call.This.toString()
@@ -950,6 +1103,7 @@ func (r *Runtime) initString() {
 	o._putProp("endsWith", r.newNativeFunc(r.stringproto_endsWith, nil, "endsWith", nil, 1), true, false, true)
 	o._putProp("includes", r.newNativeFunc(r.stringproto_includes, nil, "includes", nil, 1), true, false, true)
 	o._putProp("indexOf", r.newNativeFunc(r.stringproto_indexOf, nil, "indexOf", nil, 1), true, false, true)
+	o._putProp("isWellFormed", r.newNativeFunc(r.stringproto_isWellFormed, nil, "isWellFormed", nil, 0), true, false, true)
 	o._putProp("lastIndexOf", r.newNativeFunc(r.stringproto_lastIndexOf, nil, "lastIndexOf", nil, 1), true, false, true)
 	o._putProp("localeCompare", r.newNativeFunc(r.stringproto_localeCompare, nil, "localeCompare", nil, 1), true, false, true)
 	o._putProp("match", r.newNativeFunc(r.stringproto_match, nil, "match", nil, 1), true, false, true)
@@ -959,16 +1113,18 @@ func (r *Runtime) initString() {
 	o._putProp("padStart", r.newNativeFunc(r.stringproto_padStart, nil, "padStart", nil, 1), true, false, true)
 	o._putProp("repeat", r.newNativeFunc(r.stringproto_repeat, nil, "repeat", nil, 1), true, false, true)
 	o._putProp("replace", r.newNativeFunc(r.stringproto_replace, nil, "replace", nil, 2), true, false, true)
+	o._putProp("replaceAll", r.newNativeFunc(r.stringproto_replaceAll, nil, "replaceAll", nil, 2), true, false, true)
 	o._putProp("search", r.newNativeFunc(r.stringproto_search, nil, "search", nil, 1), true, false, true)
 	o._putProp("slice", r.newNativeFunc(r.stringproto_slice, nil, "slice", nil, 2), true, false, true)
 	o._putProp("split", r.newNativeFunc(r.stringproto_split, nil, "split", nil, 2), true, false, true)
 	o._putProp("startsWith", r.newNativeFunc(r.stringproto_startsWith, nil, "startsWith", nil, 1), true, false, true)
 	o._putProp("substring", r.newNativeFunc(r.stringproto_substring, nil, "substring", nil, 2), true, false, true)
-	o._putProp("toLocaleLowerCase", r.newNativeFunc(r.stringproto_toLowerCase, nil, "toLocaleLowerCase", nil, 0), true, false, true)
-	o._putProp("toLocaleUpperCase", r.newNativeFunc(r.stringproto_toUpperCase, nil, "toLocaleUpperCase", nil, 0), true, false, true)
+	o._putProp("toLocaleLowerCase", r.newNativeFunc(r.stringproto_toLocaleLowerCase, nil, "toLocaleLowerCase", nil, 0), true, false, true)
+	o._putProp("toLocaleUpperCase", r.newNativeFunc(r.stringproto_toLocaleUpperCase, nil, "toLocaleUpperCase", nil, 0), true, false, true)
 	o._putProp("toLowerCase", r.newNativeFunc(r.stringproto_toLowerCase, nil, "toLowerCase", nil, 0), true, false, true)
 	o._putProp("toString", r.newNativeFunc(r.stringproto_toString, nil, "toString", nil, 0), true, false, true)
 	o._putProp("toUpperCase", r.newNativeFunc(r.stringproto_toUpperCase, nil, "toUpperCase", nil, 0), true, false, true)
+	o._putProp("toWellFormed", r.newNativeFunc(r.stringproto_toWellFormed, nil, "toWellFormed", nil, 0), true, false, true)
 	o._putProp("trim", r.newNativeFunc(r.stringproto_trim, nil, "trim", nil, 0), true, false, true)
 	trimEnd := r.newNativeFunc(r.stringproto_trimEnd, nil, "trimEnd", nil, 0)
 	trimStart := r.newNativeFunc(r.stringproto_trimStart, nil, "trimStart", nil, 0)
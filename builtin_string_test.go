@@ -127,6 +127,30 @@ var prefix2 = new Prefix("def");
 	testScript(SCRIPT, valueTrue, t)
 }
 
+func TestStringReplaceAll(t *testing.T) {
+	const SCRIPT = `
+assert.sameValue("aaa".replaceAll("a", "b"), "bbb", "#1");
+assert.sameValue("aaaa".replaceAll("aa", "$&|"), "aa|aa|", "#2");
+assert.sameValue("abc".replaceAll("", "-"), "-a-b-c-", "empty search string");
+assert.sameValue("".replaceAll("", "x"), "x", "empty string");
+assert.sameValue("x.y.z".replaceAll(".", function(m, pos, s) {
+	return "[" + m + pos + s + "]";
+}), "x[.1x.y.z]y[.3x.y.z]z", "replacer function");
+assert.sameValue("a1b2".replaceAll(/\d/g, "#"), "a#b#", "global regexp");
+assert.sameValue("a1b2".replaceAll(/(?<d>\d)/g, "<$<d>>"), "a<1>b<2>", "named groups");
+assert.throws(TypeError, function() {
+	"a1b2".replaceAll(/\d/, "#");
+}, "non-global regexp");
+
+var searchValue = {};
+searchValue[Symbol.replace] = function(s, r) {
+	return s + "|" + r;
+};
+assert.sameValue("abc".replaceAll(searchValue, "x"), "abc|x", "Symbol.replace");
+`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
 func TestGenericSplitter(t *testing.T) {
 	const SCRIPT = `
 function MyRegexp(pattern, flags) {
@@ -187,6 +211,26 @@ if (result.value !== pair) {
 	testScript(SCRIPT, _undefined, t)
 }
 
+func TestStringWellFormed(t *testing.T) {
+	const SCRIPT = `
+	assert("abc".isWellFormed(), "ascii");
+	assert("юникод \uD834\uDF06".isWellFormed(), "pair");
+	assert(!"a\uD834".isWellFormed(), "lone lead");
+	assert(!"\uDF06a".isWellFormed(), "lone trail");
+	assert(!"\uDF06\uD834".isWellFormed(), "reversed pair");
+
+	assert.sameValue("abc".toWellFormed(), "abc");
+	assert.sameValue("\uD834\uDF06".toWellFormed(), "\uD834\uDF06");
+	assert.sameValue("a\uD834b\uDF06\uDF06\uD834".toWellFormed(), "a\uFFFDb\uFFFD\uFFFD\uFFFD");
+	assert.sameValue("\uD834\uD834\uDF06".toWellFormed(), "\uFFFD\uD834\uDF06");
+	assert.sameValue(String.prototype.toWellFormed.call(42), "42");
+	assert.throws(TypeError, function() {
+		String.prototype.isWellFormed.call(null);
+	});
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
 func TestValueStringBuilder(t *testing.T) {
 	t.Run("substringASCII", func(t *testing.T) {
 		t.Parallel()
@@ -276,3 +320,22 @@ func TestValueStringBuilder(t *testing.T) {
 	})
 
 }
+
+func TestStringLocaleCase(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue("I".toLocaleLowerCase("tr"), "ı", "tr dotless i");
+	assert.sameValue("İ".toLocaleLowerCase("tr-TR"), "i", "tr dotted I");
+	assert.sameValue("i".toLocaleUpperCase("tr"), "İ", "tr dotted i");
+	assert.sameValue("ı".toLocaleUpperCase(["az", "en"]), "I", "az dotless i");
+	assert.sameValue("I".toLocaleLowerCase("en"), "i", "en");
+	assert.sameValue("i".toLocaleUpperCase(), "I", "default locale");
+	assert.sameValue("I".toLocaleLowerCase("tr-u-co-search"), "ı", "extensions");
+	assert.sameValue("i̇".toLocaleUpperCase("lt"), "I", "lt removes the dot above");
+	assert.sameValue("Ì".toLocaleLowerCase("lt"), "i̇̀", "lt adds the dot above");
+	assert.sameValue("ß".toLocaleUpperCase("tr"), "SS", "special casing");
+	assert.throws(RangeError, () => "a".toLocaleUpperCase("x"), "invalid tag");
+	assert.throws(TypeError, () => String.prototype.toLocaleLowerCase.call(null), "coercible");
+	assert.sameValue(String.prototype.toLocaleUpperCase.length, 0, "length");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
@@ -3,6 +3,9 @@ package goja
 import "github.com/dop251/goja/unistring"
 
 var (
+	SymAsyncDispose       = newSymbol(asciiString("Symbol.asyncDispose"))
+	SymAsyncIterator      = newSymbol(asciiString("Symbol.asyncIterator"))
+	SymDispose            = newSymbol(asciiString("Symbol.dispose"))
 	SymHasInstance        = newSymbol(asciiString("Symbol.hasInstance"))
 	SymIsConcatSpreadable = newSymbol(asciiString("Symbol.isConcatSpreadable"))
 	SymIterator           = newSymbol(asciiString("Symbol.iterator"))
@@ -114,7 +117,10 @@ func (r *Runtime) createSymbolProto(val *Object) objectImpl {
 	o.setOwnStr("description", &valueProperty{
 		configurable: true,
 		getterFunc: r.newNativeFunc(func(call FunctionCall) Value {
-			return r.thisSymbolValue(call.This).desc
+			if desc := r.thisSymbolValue(call.This).desc; desc != nil {
+				return desc
+			}
+			return _undefined
 		}, nil, "get description", nil, 0),
 		accessor: true,
 	}, false)
@@ -135,6 +141,9 @@ func (r *Runtime) createSymbol(val *Object) objectImpl {
 	o._putProp("keyFor", r.newNativeFunc(r.symbol_keyfor, nil, "keyFor", nil, 1), true, false, true)
 
 	for _, s := range []*Symbol{
+		SymAsyncDispose,
+		SymAsyncIterator,
+		SymDispose,
 		SymHasInstance,
 		SymIsConcatSpreadable,
 		SymIterator,
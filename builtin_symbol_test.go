@@ -0,0 +1,25 @@
+package goja
+
+import "testing"
+
+func TestSymbolDescription(t *testing.T) {
+	const SCRIPT = `
+assert.sameValue(Symbol("foo").description, "foo", "#1");
+assert.sameValue(Symbol("").description, "", "empty");
+assert.sameValue(Symbol().description, undefined, "no description");
+assert.sameValue(Symbol(undefined).description, undefined, "undefined description");
+assert.sameValue(Symbol(null).description, "null", "null description");
+assert.sameValue(Symbol.iterator.description, "Symbol.iterator", "well-known");
+assert.sameValue(Symbol.for("bar").description, "bar", "registered");
+assert.sameValue(Object(Symbol("baz")).description, "baz", "wrapper");
+
+var desc = Object.getOwnPropertyDescriptor(Symbol.prototype, "description");
+assert.sameValue(desc.set, undefined, "setter");
+assert.sameValue(desc.enumerable, false, "enumerable");
+assert.sameValue(desc.configurable, true, "configurable");
+assert.throws(TypeError, function() {
+	desc.get.call({});
+}, "incompatible receiver");
+`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
@@ -0,0 +1,968 @@
+package goja
+
+import (
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/dop251/goja/unistring"
+)
+
+type temporalDurationObject struct {
+	baseObject
+	d temporalDuration
+}
+
+type temporalInstantObject struct {
+	baseObject
+	ns *big.Int
+}
+
+var temporalRoundingModes = []string{"ceil", "floor", "expand", "trunc", "halfCeil", "halfFloor", "halfExpand", "halfTrunc", "halfEven"}
+
+func (r *Runtime) initTemporalObject(self objectImpl, o *baseObject, proto, defProto *Object) *Object {
+	if proto == nil {
+		proto = defProto
+	}
+	v := &Object{runtime: r, self: self}
+	o.class = classObject
+	o.val = v
+	o.extensible = true
+	o.prototype = proto
+	o.init()
+	return v
+}
+
+func (r *Runtime) newTemporalDuration(d temporalDuration, proto *Object) *Object {
+	if !d.isValid() {
+		panic(r.newError(r.global.RangeError, "Invalid duration"))
+	}
+	o := &temporalDurationObject{d: d}
+	return r.initTemporalObject(o, &o.baseObject, proto, r.global.TemporalDurationPrototype)
+}
+
+func (r *Runtime) newTemporalInstant(ns *big.Int, proto *Object) *Object {
+	if !isValidEpochNs(ns) {
+		panic(r.newError(r.global.RangeError, "Instant is out of range"))
+	}
+	o := &temporalInstantObject{ns: ns}
+	return r.initTemporalObject(o, &o.baseObject, proto, r.global.TemporalInstantPrototype)
+}
+
+func (r *Runtime) temporalTypeError(typ, method string, this Value) *Object {
+	return r.NewTypeError("Method Temporal.%s.prototype.%s called on incompatible receiver %s", typ, method, r.objectproto_toString(FunctionCall{This: this}))
+}
+
+func (r *Runtime) thisTemporalDuration(this Value, method string) *temporalDurationObject {
+	if o, ok := this.(*Object); ok {
+		if d, ok := o.self.(*temporalDurationObject); ok {
+			return d
+		}
+	}
+	panic(r.temporalTypeError("Duration", method, this))
+}
+
+func (r *Runtime) thisTemporalInstant(this Value, method string) *temporalInstantObject {
+	if o, ok := this.(*Object); ok {
+		if i, ok := o.self.(*temporalInstantObject); ok {
+			return i
+		}
+	}
+	panic(r.temporalTypeError("Instant", method, this))
+}
+
+func (r *Runtime) putTemporalGetter(o *baseObject, name string, getter func(FunctionCall) Value) {
+	o.setOwnStr(unistring.NewFromString(name), &valueProperty{
+		getterFunc:   r.newNativeFunc(getter, nil, unistring.NewFromString("get "+name), nil, 0),
+		accessor:     true,
+		configurable: true,
+	}, true)
+}
+
+func (r *Runtime) temporalValueOf(call FunctionCall) Value {
+	panic(r.NewTypeError("Temporal objects cannot be converted to primitive values, use compare() or equals() instead"))
+}
+
+// temporalToIntegerWithTruncation implements https://tc39.es/proposal-temporal/#sec-tointegerwithtruncation
+func (r *Runtime) temporalToIntegerWithTruncation(v Value) float64 {
+	f := v.ToFloat()
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		panic(r.newError(r.global.RangeError, "%s is not a finite number", v.String()))
+	}
+	return math.Trunc(f) + 0
+}
+
+// temporalToIntegerIfIntegral implements https://tc39.es/proposal-temporal/#sec-tointegerifintegral
+func (r *Runtime) temporalToIntegerIfIntegral(v Value) float64 {
+	f := v.ToFloat()
+	if math.IsNaN(f) || math.IsInf(f, 0) || f != math.Trunc(f) {
+		panic(r.newError(r.global.RangeError, "%s is not an integer", v.String()))
+	}
+	return f + 0
+}
+
+func (r *Runtime) temporalOptions(v Value) *Object {
+	if v == nil || v == _undefined {
+		return nil
+	}
+	if o, ok := v.(*Object); ok {
+		return o
+	}
+	panic(r.NewTypeError("Options must be an object"))
+}
+
+func (r *Runtime) temporalGetOption(opts *Object, name unistring.String, allowed []string, def string) string {
+	if opts == nil {
+		return def
+	}
+	v := opts.self.getStr(name, nil)
+	if v == nil || v == _undefined {
+		return def
+	}
+	s := v.toString().String()
+	for _, a := range allowed {
+		if s == a {
+			return s
+		}
+	}
+	panic(r.newError(r.global.RangeError, "%s is not a valid value for %s", s, name))
+}
+
+func (r *Runtime) temporalGetOverflow(opts *Object) bool {
+	return r.temporalGetOption(opts, "overflow", []string{"constrain", "reject"}, "constrain") == "reject"
+}
+
+func parseTemporalUnit(s string) (temporalUnit, bool) {
+	for i, n := range temporalUnitNames {
+		if s == n || i > 0 && s == n+"s" {
+			return temporalUnit(i), true
+		}
+	}
+	return 0, false
+}
+
+// temporalGetUnitOption reads a unit option. The result is def if the option is not present.
+func (r *Runtime) temporalGetUnitOption(opts *Object, name unistring.String, def temporalUnit) temporalUnit {
+	if opts == nil {
+		return def
+	}
+	v := opts.self.getStr(name, nil)
+	if v == nil || v == _undefined {
+		return def
+	}
+	s := v.toString().String()
+	if u, ok := parseTemporalUnit(s); ok {
+		return u
+	}
+	panic(r.newError(r.global.RangeError, "%s is not a valid value for %s", s, name))
+}
+
+// temporalCheckUnit ensures the unit is between largest and smallest (inclusive).
+func (r *Runtime) temporalCheckUnit(name string, u, largest, smallest temporalUnit) {
+	if u < largest || u > smallest {
+		panic(r.newError(r.global.RangeError, "%s is not a valid value for %s", u, name))
+	}
+}
+
+func (r *Runtime) temporalGetRoundingIncrement(opts *Object) int64 {
+	if opts == nil {
+		return 1
+	}
+	v := opts.self.getStr("roundingIncrement", nil)
+	if v == nil || v == _undefined {
+		return 1
+	}
+	f := v.ToFloat()
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		panic(r.newError(r.global.RangeError, "roundingIncrement must be a finite number"))
+	}
+	f = math.Trunc(f)
+	if f < 1 || f > 1e9 {
+		panic(r.newError(r.global.RangeError, "roundingIncrement is out of range"))
+	}
+	return int64(f)
+}
+
+// temporalMaxIncrement returns the number of units in the next larger unit, or 0 if there is no fixed ratio.
+func temporalMaxIncrement(u temporalUnit) int64 {
+	switch u {
+	case temporalUnitHour:
+		return 24
+	case temporalUnitMinute, temporalUnitSecond:
+		return 60
+	case temporalUnitMillisecond, temporalUnitMicrosecond, temporalUnitNanosecond:
+		return 1000
+	}
+	return 0
+}
+
+func (r *Runtime) temporalValidateIncrement(increment, dividend int64, inclusive bool) {
+	max := dividend
+	if !inclusive {
+		max--
+	}
+	if increment > max || dividend%increment != 0 {
+		panic(r.newError(r.global.RangeError, "roundingIncrement %d is not valid for the unit", increment))
+	}
+}
+
+type temporalDiffSettings struct {
+	largest, smallest temporalUnit
+	increment         int64
+	mode              string
+}
+
+// temporalGetDifferenceSettings reads the options of until() and since()
+// (see https://tc39.es/proposal-temporal/#sec-temporal-getdifferencesettings). The units are restricted to the
+// range between minUnit and maxUnit.
+func (r *Runtime) temporalGetDifferenceSettings(opts *Object, minUnit, maxUnit, defLargest temporalUnit) temporalDiffSettings {
+	var s temporalDiffSettings
+	s.largest = r.temporalGetUnitOption(opts, "largestUnit", temporalUnitAuto)
+	s.increment = r.temporalGetRoundingIncrement(opts)
+	s.mode = r.temporalGetOption(opts, "roundingMode", temporalRoundingModes, "trunc")
+	s.smallest = r.temporalGetUnitOption(opts, "smallestUnit", maxUnit)
+	r.temporalCheckUnit("smallestUnit", s.smallest, minUnit, maxUnit)
+	if s.largest == temporalUnitAuto {
+		s.largest = largerTemporalUnit(defLargest, s.smallest)
+	} else {
+		r.temporalCheckUnit("largestUnit", s.largest, minUnit, maxUnit)
+	}
+	if s.largest > s.smallest {
+		panic(r.newError(r.global.RangeError, "largestUnit must not be smaller than smallestUnit"))
+	}
+	if max := temporalMaxIncrement(s.smallest); max != 0 {
+		r.temporalValidateIncrement(s.increment, max, false)
+	}
+	return s
+}
+
+// temporalGetPrecisionOptions reads the options of toString() that control the precision of the seconds. It
+// returns the precision and the rounding increment in nanoseconds.
+func (r *Runtime) temporalGetPrecisionOptions(opts *Object) (precision int, increment int64, mode string) {
+	precision = temporalPrecisionAuto
+	if opts != nil {
+		if v := opts.self.getStr("fractionalSecondDigits", nil); v != nil && v != _undefined {
+			if _, ok := v.(valueInt); !ok {
+				if _, ok := v.(valueFloat); !ok {
+					if v.toString().String() != "auto" {
+						panic(r.newError(r.global.RangeError, "%s is not a valid value for fractionalSecondDigits", v.String()))
+					}
+					v = nil
+				}
+			}
+			if v != nil {
+				f := math.Floor(v.ToFloat())
+				if math.IsNaN(f) || f < 0 || f > 9 {
+					panic(r.newError(r.global.RangeError, "%s is not a valid value for fractionalSecondDigits", v.String()))
+				}
+				precision = int(f)
+			}
+		}
+	}
+	mode = r.temporalGetOption(opts, "roundingMode", temporalRoundingModes, "trunc")
+	switch u := r.temporalGetUnitOption(opts, "smallestUnit", temporalUnitAuto); u {
+	case temporalUnitAuto:
+	case temporalUnitMinute:
+		precision = temporalPrecisionMinute
+	case temporalUnitSecond:
+		precision = 0
+	case temporalUnitMillisecond:
+		precision = 3
+	case temporalUnitMicrosecond:
+		precision = 6
+	case temporalUnitNanosecond:
+		precision = 9
+	default:
+		panic(r.newError(r.global.RangeError, "%s is not a valid value for smallestUnit", u))
+	}
+	switch precision {
+	case temporalPrecisionMinute:
+		increment = 60e9
+	case temporalPrecisionAuto:
+		increment = 1
+	default:
+		increment = int64(math.Pow10(9 - precision))
+	}
+	return
+}
+
+func (r *Runtime) temporalGetRoundTo(v Value, defMode string) (opts *Object, smallest temporalUnit, increment int64, mode string) {
+	if v == _undefined {
+		panic(r.NewTypeError("Options are required"))
+	}
+	if s, ok := v.(valueString); ok {
+		u, ok := parseTemporalUnit(s.String())
+		if !ok {
+			panic(r.newError(r.global.RangeError, "%s is not a valid value for smallestUnit", s.String()))
+		}
+		return nil, u, 1, defMode
+	}
+	opts = r.temporalOptions(v)
+	increment = r.temporalGetRoundingIncrement(opts)
+	mode = r.temporalGetOption(opts, "roundingMode", temporalRoundingModes, defMode)
+	smallest = r.temporalGetUnitOption(opts, "smallestUnit", temporalUnitAuto)
+	return
+}
+
+// temporalCheckCalendar validates a calendar identifier. Only "iso8601" is supported.
+func (r *Runtime) temporalCheckCalendar(v Value) {
+	if v == nil || v == _undefined {
+		return
+	}
+	s, ok := v.(valueString)
+	if !ok {
+		if o, ok := v.(*Object); ok {
+			switch o.self.(type) {
+			case *temporalPlainDateObject, *temporalPlainDateTimeObject, *temporalZonedDateTimeObject,
+				*temporalPlainYearMonthObject, *temporalPlainMonthDayObject:
+				return
+			}
+		}
+		panic(r.NewTypeError("Calendar must be a string"))
+	}
+	id := s.String()
+	if strings.EqualFold(id, "iso8601") {
+		return
+	}
+	if p, ok := parseTemporalDateTimeString(id); ok && (p.calendar == "" || strings.EqualFold(p.calendar, "iso8601")) {
+		return
+	}
+	panic(r.newError(r.global.RangeError, "Unsupported calendar: %s", id))
+}
+
+func (r *Runtime) temporalCheckParsedCalendar(p *temporalParseResult) {
+	if p.calendar != "" && !strings.EqualFold(p.calendar, "iso8601") {
+		panic(r.newError(r.global.RangeError, "Unsupported calendar: %s", p.calendar))
+	}
+}
+
+// temporalToTimeZone implements https://tc39.es/proposal-temporal/#sec-temporal-totemporaltimezoneidentifier
+func (r *Runtime) temporalToTimeZone(v Value) *temporalTimeZone {
+	if o, ok := v.(*Object); ok {
+		if z, ok := o.self.(*temporalZonedDateTimeObject); ok {
+			return z.tz
+		}
+	}
+	s, ok := v.(valueString)
+	if !ok {
+		panic(r.NewTypeError("Time zone must be a string"))
+	}
+	id := s.String()
+	if tz, ok := parseTemporalTimeZoneId(id); ok {
+		return tz
+	}
+	if p, ok := parseTemporalDateTimeString(id); ok {
+		switch {
+		case p.tz != "":
+			if tz, ok := parseTemporalTimeZoneId(p.tz); ok {
+				return tz
+			}
+		case p.z:
+			return temporalUTC
+		case p.hasOffset && !p.offsetPrecise:
+			return newOffsetTimeZone(p.offsetNs)
+		}
+	}
+	panic(r.newError(r.global.RangeError, "Invalid time zone: %s", id))
+}
+
+func (r *Runtime) temporalNowTimeZone(v Value) *temporalTimeZone {
+	if v == _undefined {
+		return localTemporalTimeZone(r.now())
+	}
+	return r.temporalToTimeZone(v)
+}
+
+func (r *Runtime) temporalNowNs() *big.Int {
+	return timeToEpochNs(r.now())
+}
+
+func (r *Runtime) temporalNow_timeZoneId(call FunctionCall) Value {
+	return newStringValue(localTemporalTimeZone(r.now()).id)
+}
+
+func (r *Runtime) temporalNow_instant(call FunctionCall) Value {
+	return r.newTemporalInstant(r.temporalNowNs(), nil)
+}
+
+func (r *Runtime) temporalNow_zonedDateTimeISO(call FunctionCall) Value {
+	tz := r.temporalNowTimeZone(call.Argument(0))
+	return r.newTemporalZonedDateTime(r.temporalNowNs(), tz, nil)
+}
+
+func (r *Runtime) temporalNow_plainDateTimeISO(call FunctionCall) Value {
+	tz := r.temporalNowTimeZone(call.Argument(0))
+	d, t := tz.isoDateTimeAt(r.temporalNowNs())
+	return r.newTemporalPlainDateTime(d, t, nil)
+}
+
+func (r *Runtime) temporalNow_plainDateISO(call FunctionCall) Value {
+	tz := r.temporalNowTimeZone(call.Argument(0))
+	d, _ := tz.isoDateTimeAt(r.temporalNowNs())
+	return r.newTemporalPlainDate(d, nil)
+}
+
+func (r *Runtime) temporalNow_plainTimeISO(call FunctionCall) Value {
+	tz := r.temporalNowTimeZone(call.Argument(0))
+	_, t := tz.isoDateTimeAt(r.temporalNowNs())
+	return r.newTemporalPlainTime(t, nil)
+}
+
+// toTemporalDuration implements https://tc39.es/proposal-temporal/#sec-temporal-totemporalduration
+func (r *Runtime) toTemporalDuration(v Value) temporalDuration {
+	o, ok := v.(*Object)
+	if !ok {
+		s, ok := v.(valueString)
+		if !ok {
+			panic(r.NewTypeError("Cannot convert %s to a Temporal.Duration", v))
+		}
+		d, ok := parseTemporalDuration(s.String())
+		if !ok {
+			panic(r.newError(r.global.RangeError, "Invalid duration string: %s", s.String()))
+		}
+		return d
+	}
+	if d, ok := o.self.(*temporalDurationObject); ok {
+		return d.d
+	}
+	var d temporalDuration
+	if !r.temporalReadDurationFields(o, &d) {
+		panic(r.NewTypeError("Duration object must have at least one duration property"))
+	}
+	if !d.isValid() {
+		panic(r.newError(r.global.RangeError, "Invalid duration"))
+	}
+	return d
+}
+
+// temporalReadDurationFields reads the duration properties of a property bag into d, returning false if none were
+// present.
+func (r *Runtime) temporalReadDurationFields(o *Object, d *temporalDuration) bool {
+	found := false
+	for _, i := range temporalDurationFieldsSorted {
+		if v := o.self.getStr(unistring.String(temporalDurationFields[i]), nil); v != nil && v != _undefined {
+			d[i] = r.temporalToIntegerIfIntegral(v)
+			found = true
+		}
+	}
+	return found
+}
+
+func (r *Runtime) builtin_newTemporalDuration(args []Value, newTarget *Object) *Object {
+	if newTarget == nil {
+		panic(r.needNew("Temporal.Duration"))
+	}
+	var d temporalDuration
+	for i := range d {
+		if i < len(args) && args[i] != _undefined {
+			d[i] = r.temporalToIntegerIfIntegral(args[i])
+		}
+	}
+	proto := r.getPrototypeFromCtor(newTarget, r.global.TemporalDuration, r.global.TemporalDurationPrototype)
+	return r.newTemporalDuration(d, proto)
+}
+
+func (r *Runtime) temporalDuration_from(call FunctionCall) Value {
+	return r.newTemporalDuration(r.toTemporalDuration(call.Argument(0)), nil)
+}
+
+func (r *Runtime) temporalCheckRelativeTo(opts *Object) {
+	if opts != nil {
+		if v := opts.self.getStr("relativeTo", nil); v != nil && v != _undefined {
+			panic(r.newError(r.global.RangeError, "relativeTo is not supported"))
+		}
+	}
+}
+
+func (r *Runtime) temporalDuration_compare(call FunctionCall) Value {
+	one := r.toTemporalDuration(call.Argument(0))
+	two := r.toTemporalDuration(call.Argument(1))
+	r.temporalCheckRelativeTo(r.temporalOptions(call.Argument(2)))
+	if one == two {
+		return intToValue(0)
+	}
+	if one.hasCalendarUnits() || two.hasCalendarUnits() {
+		panic(r.newError(r.global.RangeError, "A relativeTo date is required for comparing durations with years, months or weeks"))
+	}
+	return intToValue(int64(one.timeNs(true).Cmp(two.timeNs(true))))
+}
+
+func (r *Runtime) temporalDurationProto_getSign(call FunctionCall) Value {
+	d := r.thisTemporalDuration(call.This, "sign")
+	return intToValue(int64(d.d.sign()))
+}
+
+func (r *Runtime) temporalDurationProto_getBlank(call FunctionCall) Value {
+	d := r.thisTemporalDuration(call.This, "blank")
+	return r.toBoolean(d.d.sign() == 0)
+}
+
+func (r *Runtime) temporalDurationProto_with(call FunctionCall) Value {
+	d := r.thisTemporalDuration(call.This, "with")
+	o, ok := call.Argument(0).(*Object)
+	if !ok {
+		panic(r.NewTypeError("Argument must be an object"))
+	}
+	res := d.d
+	if !r.temporalReadDurationFields(o, &res) {
+		panic(r.NewTypeError("Duration object must have at least one duration property"))
+	}
+	return r.newTemporalDuration(res, nil)
+}
+
+func (r *Runtime) temporalDurationProto_negated(call FunctionCall) Value {
+	d := r.thisTemporalDuration(call.This, "negated")
+	return r.newTemporalDuration(d.d.negated(), nil)
+}
+
+func (r *Runtime) temporalDurationProto_abs(call FunctionCall) Value {
+	d := r.thisTemporalDuration(call.This, "abs")
+	res := d.d
+	for i, f := range res {
+		res[i] = math.Abs(f)
+	}
+	return r.newTemporalDuration(res, nil)
+}
+
+func (r *Runtime) temporalDurationAdd(call FunctionCall, method string, sign int) Value {
+	d := r.thisTemporalDuration(call.This, method)
+	other := r.toTemporalDuration(call.Argument(0))
+	if d.d.hasCalendarUnits() || other.hasCalendarUnits() {
+		panic(r.newError(r.global.RangeError, "Cannot add durations with years, months or weeks without a relativeTo date"))
+	}
+	largest := largerTemporalUnit(d.d.largestUnit(), other.largestUnit())
+	ns := other.timeNs(true)
+	if sign < 0 {
+		ns.Neg(ns)
+	}
+	ns.Add(ns, d.d.timeNs(true))
+	return r.newTemporalDuration(balanceTimeDuration(ns, largest), nil)
+}
+
+func (r *Runtime) temporalDurationProto_add(call FunctionCall) Value {
+	return r.temporalDurationAdd(call, "add", 1)
+}
+
+func (r *Runtime) temporalDurationProto_subtract(call FunctionCall) Value {
+	return r.temporalDurationAdd(call, "subtract", -1)
+}
+
+func (r *Runtime) temporalDurationProto_round(call FunctionCall) Value {
+	d := r.thisTemporalDuration(call.This, "round")
+	var largest temporalUnit
+	var smallest temporalUnit
+	increment, mode := int64(1), "halfExpand"
+	if roundTo := call.Argument(0); roundTo == _undefined {
+		panic(r.NewTypeError("Options are required"))
+	} else if str, ok := roundTo.(valueString); ok {
+		u, ok := parseTemporalUnit(str.String())
+		if !ok {
+			panic(r.newError(r.global.RangeError, "%s is not a valid value for smallestUnit", str.String()))
+		}
+		smallest = u
+	} else {
+		opts := r.temporalOptions(roundTo)
+		largest = r.temporalGetUnitOption(opts, "largestUnit", temporalUnitAuto)
+		r.temporalCheckRelativeTo(opts)
+		increment = r.temporalGetRoundingIncrement(opts)
+		mode = r.temporalGetOption(opts, "roundingMode", temporalRoundingModes, "halfExpand")
+		smallest = r.temporalGetUnitOption(opts, "smallestUnit", temporalUnitAuto)
+		if smallest == temporalUnitAuto && largest == temporalUnitAuto {
+			panic(r.newError(r.global.RangeError, "At least one of smallestUnit or largestUnit is required"))
+		}
+	}
+	if smallest == temporalUnitAuto {
+		smallest = temporalUnitNanosecond
+	}
+	if largest == temporalUnitAuto {
+		largest = largerTemporalUnit(d.d.largestUnit(), smallest)
+	}
+	if largest > smallest {
+		panic(r.newError(r.global.RangeError, "largestUnit must not be smaller than smallestUnit"))
+	}
+	if d.d.hasCalendarUnits() || largest.isCalendarUnit() || smallest.isCalendarUnit() {
+		panic(r.newError(r.global.RangeError, "Rounding durations with years, months or weeks requires a relativeTo date"))
+	}
+	if max := temporalMaxIncrement(smallest); max != 0 {
+		r.temporalValidateIncrement(increment, max, false)
+	} else if increment != 1 && largest != smallest {
+		panic(r.newError(r.global.RangeError, "roundingIncrement %d is not valid for the unit", increment))
+	}
+	unitNs := big.NewInt(increment * temporalUnitNs[smallest])
+	return r.newTemporalDuration(balanceTimeDuration(temporalRound(d.d.timeNs(true), unitNs, mode), largest), nil)
+}
+
+func (r *Runtime) temporalDurationProto_total(call FunctionCall) Value {
+	d := r.thisTemporalDuration(call.This, "total")
+	var unit temporalUnit
+	switch arg := call.Argument(0).(type) {
+	case valueString:
+		u, ok := parseTemporalUnit(arg.String())
+		if !ok {
+			panic(r.newError(r.global.RangeError, "%s is not a valid value for unit", arg.String()))
+		}
+		unit = u
+	default:
+		if arg == _undefined {
+			panic(r.NewTypeError("Options are required"))
+		}
+		opts := r.temporalOptions(arg)
+		r.temporalCheckRelativeTo(opts)
+		unit = r.temporalGetUnitOption(opts, "unit", temporalUnitAuto)
+		if unit == temporalUnitAuto {
+			panic(r.newError(r.global.RangeError, "unit is required"))
+		}
+	}
+	if unit == temporalUnitAuto || d.d.hasCalendarUnits() || unit.isCalendarUnit() {
+		panic(r.newError(r.global.RangeError, "Totalling durations with years, months or weeks requires a relativeTo date"))
+	}
+	f, _ := new(big.Rat).SetFrac(d.d.timeNs(true), big.NewInt(temporalUnitNs[unit])).Float64()
+	return floatToValue(f)
+}
+
+func (r *Runtime) temporalDurationProto_toString(call FunctionCall) Value {
+	d := r.thisTemporalDuration(call.This, "toString")
+	precision, increment, mode := r.temporalGetPrecisionOptions(r.temporalOptions(call.Argument(0)))
+	if precision == temporalPrecisionMinute {
+		panic(r.newError(r.global.RangeError, "smallestUnit must be seconds or smaller"))
+	}
+	res := d.d
+	if precision != temporalPrecisionAuto && increment != 1 {
+		timeLargest := temporalUnitSecond
+		if res[4] != 0 {
+			timeLargest = temporalUnitHour
+		} else if res[5] != 0 {
+			timeLargest = temporalUnitMinute
+		}
+		t := balanceTimeDuration(temporalRound(res.timeNs(false), big.NewInt(increment), mode), timeLargest)
+		copy(res[4:], t[4:])
+		if !res.isValid() {
+			panic(r.newError(r.global.RangeError, "Invalid duration"))
+		}
+	}
+	return asciiString(res.format(precision))
+}
+
+func (r *Runtime) temporalDurationProto_toJSON(call FunctionCall) Value {
+	d := r.thisTemporalDuration(call.This, "toJSON")
+	return asciiString(d.d.format(temporalPrecisionAuto))
+}
+
+func (r *Runtime) temporalDurationProto_toLocaleString(call FunctionCall) Value {
+	d := r.thisTemporalDuration(call.This, "toLocaleString")
+	return asciiString(d.d.format(temporalPrecisionAuto))
+}
+
+// toTemporalInstant implements https://tc39.es/proposal-temporal/#sec-temporal-totemporalinstant
+func (r *Runtime) toTemporalInstant(v Value) *big.Int {
+	if o, ok := v.(*Object); ok {
+		switch t := o.self.(type) {
+		case *temporalInstantObject:
+			return t.ns
+		case *temporalZonedDateTimeObject:
+			return t.ns
+		}
+		v = toPrimitive(v)
+	}
+	s, ok := v.(valueString)
+	if !ok {
+		panic(r.NewTypeError("Cannot convert %s to a Temporal.Instant", v))
+	}
+	p, ok := parseTemporalDateTimeString(s.String())
+	if !ok || !p.hasTime || !p.z && !p.hasOffset {
+		panic(r.newError(r.global.RangeError, "Invalid instant string: %s", s.String()))
+	}
+	r.temporalCheckParsedCalendar(&p)
+	if !isoDateTimeWithinLimits(p.date, p.time) {
+		panic(r.newError(r.global.RangeError, "Instant is out of range"))
+	}
+	ns := isoDateTimeToEpochNs(p.date, p.time)
+	ns.Sub(ns, big.NewInt(p.offsetNs))
+	if !isValidEpochNs(ns) {
+		panic(r.newError(r.global.RangeError, "Instant is out of range"))
+	}
+	return ns
+}
+
+func (r *Runtime) builtin_newTemporalInstant(args []Value, newTarget *Object) *Object {
+	if newTarget == nil {
+		panic(r.needNew("Temporal.Instant"))
+	}
+	var arg Value = _undefined
+	if len(args) > 0 {
+		arg = args[0]
+	}
+	ns := new(big.Int).Set((*big.Int)(toBigInt(arg)))
+	proto := r.getPrototypeFromCtor(newTarget, r.global.TemporalInstant, r.global.TemporalInstantPrototype)
+	return r.newTemporalInstant(ns, proto)
+}
+
+func (r *Runtime) temporalInstant_from(call FunctionCall) Value {
+	return r.newTemporalInstant(r.toTemporalInstant(call.Argument(0)), nil)
+}
+
+func (r *Runtime) temporalInstant_fromEpochMilliseconds(call FunctionCall) Value {
+	ms := call.Argument(0).ToNumber().ToFloat()
+	if math.IsNaN(ms) || math.IsInf(ms, 0) || ms != math.Trunc(ms) {
+		panic(r.newError(r.global.RangeError, "Epoch milliseconds must be an integer"))
+	}
+	ns := bigIntFromFloat(ms)
+	return r.newTemporalInstant(ns.Mul(ns, bigNsPerMs), nil)
+}
+
+func (r *Runtime) temporalInstant_fromEpochNanoseconds(call FunctionCall) Value {
+	return r.newTemporalInstant(new(big.Int).Set((*big.Int)(toBigInt(call.Argument(0)))), nil)
+}
+
+func (r *Runtime) temporalInstant_compare(call FunctionCall) Value {
+	one := r.toTemporalInstant(call.Argument(0))
+	two := r.toTemporalInstant(call.Argument(1))
+	return intToValue(int64(one.Cmp(two)))
+}
+
+func (r *Runtime) temporalInstantProto_getEpochMilliseconds(call FunctionCall) Value {
+	i := r.thisTemporalInstant(call.This, "epochMilliseconds")
+	return intToValue(epochMsFromNs(i.ns))
+}
+
+func (r *Runtime) temporalInstantProto_getEpochNanoseconds(call FunctionCall) Value {
+	i := r.thisTemporalInstant(call.This, "epochNanoseconds")
+	return (*valueBigInt)(new(big.Int).Set(i.ns))
+}
+
+// temporalAddTimeDuration adds the time portion of a duration which must not have any date units.
+func (r *Runtime) temporalAddTimeDuration(ns *big.Int, d temporalDuration, sign int) *big.Int {
+	if d.hasCalendarUnits() || d[3] != 0 {
+		panic(r.newError(r.global.RangeError, "Durations with years, months, weeks or days cannot be added to an exact time"))
+	}
+	t := d.timeNs(false)
+	if sign < 0 {
+		t.Neg(t)
+	}
+	return t.Add(t, ns)
+}
+
+func (r *Runtime) temporalInstantProto_add(call FunctionCall) Value {
+	i := r.thisTemporalInstant(call.This, "add")
+	return r.newTemporalInstant(r.temporalAddTimeDuration(i.ns, r.toTemporalDuration(call.Argument(0)), 1), nil)
+}
+
+func (r *Runtime) temporalInstantProto_subtract(call FunctionCall) Value {
+	i := r.thisTemporalInstant(call.This, "subtract")
+	return r.newTemporalInstant(r.temporalAddTimeDuration(i.ns, r.toTemporalDuration(call.Argument(0)), -1), nil)
+}
+
+// temporalDiffExact computes the difference between two exact times rounded and balanced according to the
+// settings.
+func temporalDiffExact(ns1, ns2 *big.Int, s temporalDiffSettings) temporalDuration {
+	diff := new(big.Int).Sub(ns2, ns1)
+	diff = temporalRound(diff, big.NewInt(s.increment*temporalUnitNs[s.smallest]), s.mode)
+	return balanceTimeDuration(diff, s.largest)
+}
+
+func (r *Runtime) temporalInstantDifference(call FunctionCall, method string, since bool) Value {
+	i := r.thisTemporalInstant(call.This, method)
+	other := r.toTemporalInstant(call.Argument(0))
+	s := r.temporalGetDifferenceSettings(r.temporalOptions(call.Argument(1)), temporalUnitHour, temporalUnitNanosecond, temporalUnitSecond)
+	if since {
+		s.mode = temporalNegateRoundingMode(s.mode)
+		d := temporalDiffExact(i.ns, other, s)
+		return r.newTemporalDuration(d.negated(), nil)
+	}
+	return r.newTemporalDuration(temporalDiffExact(i.ns, other, s), nil)
+}
+
+func (r *Runtime) temporalInstantProto_until(call FunctionCall) Value {
+	return r.temporalInstantDifference(call, "until", false)
+}
+
+func (r *Runtime) temporalInstantProto_since(call FunctionCall) Value {
+	return r.temporalInstantDifference(call, "since", true)
+}
+
+func (r *Runtime) temporalInstantProto_round(call FunctionCall) Value {
+	i := r.thisTemporalInstant(call.This, "round")
+	_, smallest, increment, mode := r.temporalGetRoundTo(call.Argument(0), "halfExpand")
+	if smallest == temporalUnitAuto {
+		panic(r.newError(r.global.RangeError, "smallestUnit is required"))
+	}
+	r.temporalCheckUnit("smallestUnit", smallest, temporalUnitHour, temporalUnitNanosecond)
+	r.temporalValidateIncrement(increment, nsPerDay/temporalUnitNs[smallest], true)
+	return r.newTemporalInstant(temporalRound(i.ns, big.NewInt(increment*temporalUnitNs[smallest]), mode), nil)
+}
+
+func (r *Runtime) temporalInstantProto_equals(call FunctionCall) Value {
+	i := r.thisTemporalInstant(call.This, "equals")
+	other := r.toTemporalInstant(call.Argument(0))
+	return r.toBoolean(i.ns.Cmp(other) == 0)
+}
+
+func formatTemporalInstant(ns *big.Int, tz *temporalTimeZone, precision int) string {
+	var b strings.Builder
+	zone := tz
+	if zone == nil {
+		zone = temporalUTC
+	}
+	d, t := zone.isoDateTimeAt(ns)
+	writeISODate(&b, d)
+	b.WriteByte('T')
+	writeISOTime(&b, t, precision)
+	if tz == nil {
+		b.WriteByte('Z')
+	} else {
+		b.WriteString(formatTemporalOffset(tz.offsetNsAt(ns), false))
+	}
+	return b.String()
+}
+
+func (r *Runtime) temporalInstantProto_toString(call FunctionCall) Value {
+	i := r.thisTemporalInstant(call.This, "toString")
+	opts := r.temporalOptions(call.Argument(0))
+	precision, increment, mode := r.temporalGetPrecisionOptions(opts)
+	var tz *temporalTimeZone
+	if opts != nil {
+		if v := opts.self.getStr("timeZone", nil); v != nil && v != _undefined {
+			tz = r.temporalToTimeZone(v)
+		}
+	}
+	ns := temporalRound(i.ns, big.NewInt(increment), mode)
+	if !isValidEpochNs(ns) {
+		panic(r.newError(r.global.RangeError, "Instant is out of range"))
+	}
+	return asciiString(formatTemporalInstant(ns, tz, precision))
+}
+
+func (r *Runtime) temporalInstantProto_toJSON(call FunctionCall) Value {
+	i := r.thisTemporalInstant(call.This, "toJSON")
+	return asciiString(formatTemporalInstant(i.ns, nil, temporalPrecisionAuto))
+}
+
+func (r *Runtime) temporalInstantProto_toLocaleString(call FunctionCall) Value {
+	i := r.thisTemporalInstant(call.This, "toLocaleString")
+	return asciiString(formatTemporalInstant(i.ns, nil, temporalPrecisionAuto))
+}
+
+func (r *Runtime) temporalInstantProto_toZonedDateTimeISO(call FunctionCall) Value {
+	i := r.thisTemporalInstant(call.This, "toZonedDateTimeISO")
+	return r.newTemporalZonedDateTime(i.ns, r.temporalToTimeZone(call.Argument(0)), nil)
+}
+
+func (r *Runtime) createTemporalDurationProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("constructor", r.global.TemporalDuration, true, false, true)
+	for i, name := range temporalDurationFields {
+		i, name := i, name
+		r.putTemporalGetter(o, name, func(call FunctionCall) Value {
+			return floatToValue(r.thisTemporalDuration(call.This, name).d[i])
+		})
+	}
+	r.putTemporalGetter(o, "sign", r.temporalDurationProto_getSign)
+	r.putTemporalGetter(o, "blank", r.temporalDurationProto_getBlank)
+	o._putProp("with", r.newNativeFunc(r.temporalDurationProto_with, nil, "with", nil, 1), true, false, true)
+	o._putProp("negated", r.newNativeFunc(r.temporalDurationProto_negated, nil, "negated", nil, 0), true, false, true)
+	o._putProp("abs", r.newNativeFunc(r.temporalDurationProto_abs, nil, "abs", nil, 0), true, false, true)
+	o._putProp("add", r.newNativeFunc(r.temporalDurationProto_add, nil, "add", nil, 1), true, false, true)
+	o._putProp("subtract", r.newNativeFunc(r.temporalDurationProto_subtract, nil, "subtract", nil, 1), true, false, true)
+	o._putProp("round", r.newNativeFunc(r.temporalDurationProto_round, nil, "round", nil, 1), true, false, true)
+	o._putProp("total", r.newNativeFunc(r.temporalDurationProto_total, nil, "total", nil, 1), true, false, true)
+	o._putProp("toString", r.newNativeFunc(r.temporalDurationProto_toString, nil, "toString", nil, 0), true, false, true)
+	o._putProp("toJSON", r.newNativeFunc(r.temporalDurationProto_toJSON, nil, "toJSON", nil, 0), true, false, true)
+	o._putProp("toLocaleString", r.newNativeFunc(r.temporalDurationProto_toLocaleString, nil, "toLocaleString", nil, 0), true, false, true)
+	o._putProp("valueOf", r.newNativeFunc(r.temporalValueOf, nil, "valueOf", nil, 0), true, false, true)
+	o._putSym(SymToStringTag, valueProp(asciiString("Temporal.Duration"), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) createTemporalDuration(val *Object) objectImpl {
+	o := r.newNativeConstructOnly(val, r.builtin_newTemporalDuration, r.global.TemporalDurationPrototype, "Duration", 0)
+	o._putProp("from", r.newNativeFunc(r.temporalDuration_from, nil, "from", nil, 1), true, false, true)
+	o._putProp("compare", r.newNativeFunc(r.temporalDuration_compare, nil, "compare", nil, 2), true, false, true)
+
+	return o
+}
+
+func (r *Runtime) createTemporalInstantProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("constructor", r.global.TemporalInstant, true, false, true)
+	r.putTemporalGetter(o, "epochMilliseconds", r.temporalInstantProto_getEpochMilliseconds)
+	r.putTemporalGetter(o, "epochNanoseconds", r.temporalInstantProto_getEpochNanoseconds)
+	o._putProp("add", r.newNativeFunc(r.temporalInstantProto_add, nil, "add", nil, 1), true, false, true)
+	o._putProp("subtract", r.newNativeFunc(r.temporalInstantProto_subtract, nil, "subtract", nil, 1), true, false, true)
+	o._putProp("until", r.newNativeFunc(r.temporalInstantProto_until, nil, "until", nil, 1), true, false, true)
+	o._putProp("since", r.newNativeFunc(r.temporalInstantProto_since, nil, "since", nil, 1), true, false, true)
+	o._putProp("round", r.newNativeFunc(r.temporalInstantProto_round, nil, "round", nil, 1), true, false, true)
+	o._putProp("equals", r.newNativeFunc(r.temporalInstantProto_equals, nil, "equals", nil, 1), true, false, true)
+	o._putProp("toString", r.newNativeFunc(r.temporalInstantProto_toString, nil, "toString", nil, 0), true, false, true)
+	o._putProp("toJSON", r.newNativeFunc(r.temporalInstantProto_toJSON, nil, "toJSON", nil, 0), true, false, true)
+	o._putProp("toLocaleString", r.newNativeFunc(r.temporalInstantProto_toLocaleString, nil, "toLocaleString", nil, 0), true, false, true)
+	o._putProp("valueOf", r.newNativeFunc(r.temporalValueOf, nil, "valueOf", nil, 0), true, false, true)
+	o._putProp("toZonedDateTimeISO", r.newNativeFunc(r.temporalInstantProto_toZonedDateTimeISO, nil, "toZonedDateTimeISO", nil, 1), true, false, true)
+	o._putSym(SymToStringTag, valueProp(asciiString("Temporal.Instant"), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) createTemporalInstant(val *Object) objectImpl {
+	o := r.newNativeConstructOnly(val, r.builtin_newTemporalInstant, r.global.TemporalInstantPrototype, "Instant", 1)
+	o._putProp("from", r.newNativeFunc(r.temporalInstant_from, nil, "from", nil, 1), true, false, true)
+	o._putProp("fromEpochMilliseconds", r.newNativeFunc(r.temporalInstant_fromEpochMilliseconds, nil, "fromEpochMilliseconds", nil, 1), true, false, true)
+	o._putProp("fromEpochNanoseconds", r.newNativeFunc(r.temporalInstant_fromEpochNanoseconds, nil, "fromEpochNanoseconds", nil, 1), true, false, true)
+	o._putProp("compare", r.newNativeFunc(r.temporalInstant_compare, nil, "compare", nil, 2), true, false, true)
+
+	return o
+}
+
+func (r *Runtime) createTemporalNow(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("timeZoneId", r.newNativeFunc(r.temporalNow_timeZoneId, nil, "timeZoneId", nil, 0), true, false, true)
+	o._putProp("instant", r.newNativeFunc(r.temporalNow_instant, nil, "instant", nil, 0), true, false, true)
+	o._putProp("zonedDateTimeISO", r.newNativeFunc(r.temporalNow_zonedDateTimeISO, nil, "zonedDateTimeISO", nil, 0), true, false, true)
+	o._putProp("plainDateTimeISO", r.newNativeFunc(r.temporalNow_plainDateTimeISO, nil, "plainDateTimeISO", nil, 0), true, false, true)
+	o._putProp("plainDateISO", r.newNativeFunc(r.temporalNow_plainDateISO, nil, "plainDateISO", nil, 0), true, false, true)
+	o._putProp("plainTimeISO", r.newNativeFunc(r.temporalNow_plainTimeISO, nil, "plainTimeISO", nil, 0), true, false, true)
+	o._putSym(SymToStringTag, valueProp(asciiString("Temporal.Now"), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) createTemporal(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("Now", r.newLazyObject(r.createTemporalNow), true, false, true)
+	o._putProp("Duration", r.global.TemporalDuration, true, false, true)
+	o._putProp("Instant", r.global.TemporalInstant, true, false, true)
+	o._putProp("PlainDate", r.global.TemporalPlainDate, true, false, true)
+	o._putProp("PlainTime", r.global.TemporalPlainTime, true, false, true)
+	o._putProp("PlainDateTime", r.global.TemporalPlainDateTime, true, false, true)
+	o._putProp("PlainYearMonth", r.global.TemporalPlainYearMonth, true, false, true)
+	o._putProp("PlainMonthDay", r.global.TemporalPlainMonthDay, true, false, true)
+	o._putProp("ZonedDateTime", r.global.TemporalZonedDateTime, true, false, true)
+	o._putSym(SymToStringTag, valueProp(asciiString("Temporal"), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) initTemporal() {
+	r.global.TemporalDurationPrototype = r.newLazyObject(r.createTemporalDurationProto)
+	r.global.TemporalDuration = r.newLazyObject(r.createTemporalDuration)
+	r.global.TemporalInstantPrototype = r.newLazyObject(r.createTemporalInstantProto)
+	r.global.TemporalInstant = r.newLazyObject(r.createTemporalInstant)
+	r.global.TemporalPlainDatePrototype = r.newLazyObject(r.createTemporalPlainDateProto)
+	r.global.TemporalPlainDate = r.newLazyObject(r.createTemporalPlainDate)
+	r.global.TemporalPlainTimePrototype = r.newLazyObject(r.createTemporalPlainTimeProto)
+	r.global.TemporalPlainTime = r.newLazyObject(r.createTemporalPlainTime)
+	r.global.TemporalPlainDateTimePrototype = r.newLazyObject(r.createTemporalPlainDateTimeProto)
+	r.global.TemporalPlainDateTime = r.newLazyObject(r.createTemporalPlainDateTime)
+	r.global.TemporalPlainYearMonthPrototype = r.newLazyObject(r.createTemporalPlainYearMonthProto)
+	r.global.TemporalPlainYearMonth = r.newLazyObject(r.createTemporalPlainYearMonth)
+	r.global.TemporalPlainMonthDayPrototype = r.newLazyObject(r.createTemporalPlainMonthDayProto)
+	r.global.TemporalPlainMonthDay = r.newLazyObject(r.createTemporalPlainMonthDay)
+	r.global.TemporalZonedDateTimePrototype = r.newLazyObject(r.createTemporalZonedDateTimeProto)
+	r.global.TemporalZonedDateTime = r.newLazyObject(r.createTemporalZonedDateTime)
+
+	r.addToGlobal("Temporal", r.newLazyObject(r.createTemporal))
+}
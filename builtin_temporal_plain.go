@@ -0,0 +1,1508 @@
+package goja
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/dop251/goja/unistring"
+)
+
+type temporalPlainDateObject struct {
+	baseObject
+	date isoDate
+}
+
+type temporalPlainTimeObject struct {
+	baseObject
+	time isoTime
+}
+
+type temporalPlainDateTimeObject struct {
+	baseObject
+	date isoDate
+	time isoTime
+}
+
+type temporalPlainYearMonthObject struct {
+	baseObject
+	date isoDate // the day is the reference day
+}
+
+type temporalPlainMonthDayObject struct {
+	baseObject
+	date isoDate // the year is temporalReferenceYear unless specified in the constructor
+}
+
+type temporalFieldMask uint
+
+const (
+	temporalFieldCalendar temporalFieldMask = 1 << iota
+	temporalFieldDay
+	temporalFieldHour
+	temporalFieldMicrosecond
+	temporalFieldMillisecond
+	temporalFieldMinute
+	temporalFieldMonth
+	temporalFieldMonthCode
+	temporalFieldNanosecond
+	temporalFieldOffset
+	temporalFieldSecond
+	temporalFieldTimeZone
+	temporalFieldYear
+
+	temporalDateFields      = temporalFieldDay | temporalFieldMonth | temporalFieldMonthCode | temporalFieldYear
+	temporalYearMonthFields = temporalFieldMonth | temporalFieldMonthCode | temporalFieldYear
+	temporalTimeFields      = temporalFieldHour | temporalFieldMinute | temporalFieldSecond | temporalFieldMillisecond |
+		temporalFieldMicrosecond | temporalFieldNanosecond
+)
+
+// temporalFieldNames are in the alphabetical order in which the property bags are read.
+var temporalFieldNames = [...]unistring.String{"calendar", "day", "hour", "microsecond", "millisecond", "minute", "month", "monthCode", "nanosecond", "offset", "second", "timeZone", "year"}
+
+// temporalFields holds the values read from a property bag.
+type temporalFields struct {
+	has       temporalFieldMask
+	year      float64
+	month     float64
+	day       float64
+	monthCode string
+	time      [6]float64 // hour to nanosecond
+	offsetNs  int64
+	tz        *temporalTimeZone
+}
+
+func temporalTimeFieldIndex(f temporalFieldMask) int {
+	switch f {
+	case temporalFieldHour:
+		return 0
+	case temporalFieldMinute:
+		return 1
+	case temporalFieldSecond:
+		return 2
+	case temporalFieldMillisecond:
+		return 3
+	case temporalFieldMicrosecond:
+		return 4
+	}
+	return 5
+}
+
+func formatMonthCode(m int) string {
+	if m < 10 {
+		return "M0" + strconv.Itoa(m)
+	}
+	return "M" + strconv.Itoa(m)
+}
+
+// temporalReadFields reads the specified fields from a property bag
+// (see https://tc39.es/proposal-temporal/#sec-temporal-preparecalendarfields).
+func (r *Runtime) temporalReadFields(o *Object, mask temporalFieldMask) *temporalFields {
+	f := &temporalFields{}
+	for i, name := range temporalFieldNames {
+		bit := temporalFieldMask(1) << uint(i)
+		if mask&bit == 0 {
+			continue
+		}
+		v := o.self.getStr(name, nil)
+		if v == nil || v == _undefined {
+			continue
+		}
+		f.has |= bit
+		switch bit {
+		case temporalFieldCalendar:
+			r.temporalCheckCalendar(v)
+		case temporalFieldMonthCode, temporalFieldOffset:
+			s, ok := toPrimitive(v).(valueString)
+			if !ok {
+				panic(r.NewTypeError("%s must be a string", name))
+			}
+			if bit == temporalFieldMonthCode {
+				f.monthCode = s.String()
+			} else {
+				ns, _, ok := parseTemporalOffset(s.String())
+				if !ok {
+					panic(r.newError(r.global.RangeError, "Invalid offset: %s", s.String()))
+				}
+				f.offsetNs = ns
+			}
+		case temporalFieldTimeZone:
+			f.tz = r.temporalToTimeZone(v)
+		case temporalFieldYear:
+			f.year = r.temporalToIntegerWithTruncation(v)
+		case temporalFieldMonth, temporalFieldDay:
+			n := r.temporalToIntegerWithTruncation(v)
+			if n < 1 {
+				panic(r.newError(r.global.RangeError, "%s must be a positive integer", name))
+			}
+			if bit == temporalFieldMonth {
+				f.month = n
+			} else {
+				f.day = n
+			}
+		default:
+			f.time[temporalTimeFieldIndex(bit)] = r.temporalToIntegerWithTruncation(v)
+		}
+	}
+	return f
+}
+
+// temporalReadPartialFields reads the fields for with(). The argument must be a plain object that has at least one
+// of the fields.
+func (r *Runtime) temporalReadPartialFields(v Value, mask temporalFieldMask) *temporalFields {
+	o, ok := v.(*Object)
+	if !ok {
+		panic(r.NewTypeError("Argument must be an object"))
+	}
+	switch o.self.(type) {
+	case *temporalPlainDateObject, *temporalPlainDateTimeObject, *temporalPlainTimeObject, *temporalZonedDateTimeObject,
+		*temporalPlainYearMonthObject, *temporalPlainMonthDayObject:
+		panic(r.NewTypeError("Argument must be a plain object"))
+	}
+	for _, name := range []unistring.String{"calendar", "timeZone"} {
+		if p := o.self.getStr(name, nil); p != nil && p != _undefined {
+			panic(r.NewTypeError("%s is not allowed", name))
+		}
+	}
+	f := r.temporalReadFields(o, mask)
+	if f.has == 0 {
+		panic(r.NewTypeError("Argument must have at least one recognised property"))
+	}
+	return f
+}
+
+func (f *temporalFields) setDate(d isoDate) {
+	f.has |= temporalDateFields
+	f.year, f.month, f.day = float64(d.year), float64(d.month), float64(d.day)
+	f.monthCode = formatMonthCode(d.month)
+}
+
+func (f *temporalFields) setYearMonth(d isoDate) {
+	f.has |= temporalYearMonthFields
+	f.year, f.month = float64(d.year), float64(d.month)
+	f.monthCode = formatMonthCode(d.month)
+}
+
+func (f *temporalFields) setMonthDay(d isoDate) {
+	f.has |= temporalFieldMonthCode | temporalFieldDay
+	f.day = float64(d.day)
+	f.monthCode = formatMonthCode(d.month)
+}
+
+func (f *temporalFields) setTime(t isoTime) {
+	f.has |= temporalTimeFields
+	f.time = [6]float64{float64(t.hour), float64(t.minute), float64(t.second), float64(t.millisecond), float64(t.microsecond), float64(t.nanosecond)}
+}
+
+// merge applies the partial fields over f.
+func (f *temporalFields) merge(partial *temporalFields) {
+	if partial.has&temporalFieldMonth != 0 && partial.has&temporalFieldMonthCode == 0 {
+		f.has &^= temporalFieldMonthCode
+	}
+	if partial.has&temporalFieldMonthCode != 0 && partial.has&temporalFieldMonth == 0 {
+		f.has &^= temporalFieldMonth
+	}
+	f.has |= partial.has
+	if partial.has&temporalFieldYear != 0 {
+		f.year = partial.year
+	}
+	if partial.has&temporalFieldMonth != 0 {
+		f.month = partial.month
+	}
+	if partial.has&temporalFieldMonthCode != 0 {
+		f.monthCode = partial.monthCode
+	}
+	if partial.has&temporalFieldDay != 0 {
+		f.day = partial.day
+	}
+	for i, bit := range [...]temporalFieldMask{temporalFieldHour, temporalFieldMinute, temporalFieldSecond, temporalFieldMillisecond, temporalFieldMicrosecond, temporalFieldNanosecond} {
+		if partial.has&bit != 0 {
+			f.time[i] = partial.time[i]
+		}
+	}
+	if partial.has&temporalFieldOffset != 0 {
+		f.offsetNs = partial.offsetNs
+	}
+}
+
+// temporalResolveDate implements https://tc39.es/proposal-temporal/#sec-temporal-calendardatefromfields for the ISO
+// calendar.
+func (r *Runtime) temporalResolveDate(f *temporalFields, reject bool) isoDate {
+	d := r.temporalRegulateDate(f, reject)
+	if !d.withinLimits() {
+		panic(r.newError(r.global.RangeError, "Date is out of range"))
+	}
+	return d
+}
+
+// temporalResolveYearMonth implements https://tc39.es/proposal-temporal/#sec-temporal-calendaryearmonthfromfields
+// for the ISO calendar. The day of the result is 1.
+func (r *Runtime) temporalResolveYearMonth(f *temporalFields, reject bool) isoDate {
+	g := *f
+	g.has |= temporalFieldDay
+	g.day = 1
+	d := r.temporalRegulateDate(&g, reject)
+	if !isoYearMonthWithinLimits(d.year, d.month) {
+		panic(r.newError(r.global.RangeError, "Date is out of range"))
+	}
+	return d
+}
+
+// temporalResolveMonthDay implements https://tc39.es/proposal-temporal/#sec-temporal-calendarmonthdayfromfields for
+// the ISO calendar. If the year is specified, the day is regulated within the month of that year, but the year of
+// the result is always temporalReferenceYear.
+func (r *Runtime) temporalResolveMonthDay(f *temporalFields, reject bool) isoDate {
+	g := *f
+	if g.has&temporalFieldYear == 0 {
+		g.has |= temporalFieldYear
+		g.year = temporalReferenceYear
+	}
+	d := r.temporalRegulateDate(&g, reject)
+	d.year = temporalReferenceYear
+	return d
+}
+
+func (r *Runtime) temporalRegulateDate(f *temporalFields, reject bool) isoDate {
+	switch {
+	case f.has&temporalFieldYear == 0:
+		panic(r.NewTypeError("year is required"))
+	case f.has&temporalFieldDay == 0:
+		panic(r.NewTypeError("day is required"))
+	case f.has&(temporalFieldMonth|temporalFieldMonthCode) == 0:
+		panic(r.NewTypeError("month or monthCode is required"))
+	}
+	month := f.month
+	if f.has&temporalFieldMonthCode != 0 {
+		mc := f.monthCode
+		n, err := strconv.Atoi(strings.TrimPrefix(mc, "M"))
+		if len(mc) != 3 || mc[0] != 'M' || err != nil || n < 1 || n > 12 {
+			panic(r.newError(r.global.RangeError, "Invalid monthCode: %s", mc))
+		}
+		if f.has&temporalFieldMonth != 0 && f.month != float64(n) {
+			panic(r.newError(r.global.RangeError, "month and monthCode do not match"))
+		}
+		month = float64(n)
+	}
+	if math.Abs(f.year) > 1e6 {
+		panic(r.newError(r.global.RangeError, "Date is out of range"))
+	}
+	day := f.day
+	if reject {
+		if !isValidISODate(f.year, month, day) {
+			panic(r.newError(r.global.RangeError, "Invalid date"))
+		}
+	} else {
+		month = math.Min(month, 12)
+		day = math.Min(day, float64(isoDaysInMonth(int(f.year), int(month))))
+	}
+	return isoDate{year: int(f.year), month: int(month), day: int(day)}
+}
+
+// temporalResolveTime implements https://tc39.es/proposal-temporal/#sec-temporal-regulatetime
+func (r *Runtime) temporalResolveTime(f *temporalFields, reject bool) isoTime {
+	v := f.time
+	if reject {
+		if !isValidISOTime(v[0], v[1], v[2], v[3], v[4], v[5]) {
+			panic(r.newError(r.global.RangeError, "Invalid time"))
+		}
+	} else {
+		for i, max := range [...]float64{23, 59, 59, 999, 999, 999} {
+			v[i] = math.Max(0, math.Min(v[i], max))
+		}
+	}
+	return isoTime{hour: int(v[0]), minute: int(v[1]), second: int(v[2]), millisecond: int(v[3]), microsecond: int(v[4]), nanosecond: int(v[5])}
+}
+
+func (r *Runtime) newTemporalPlainDate(d isoDate, proto *Object) *Object {
+	if !d.withinLimits() {
+		panic(r.newError(r.global.RangeError, "Date is out of range"))
+	}
+	o := &temporalPlainDateObject{date: d}
+	return r.initTemporalObject(o, &o.baseObject, proto, r.global.TemporalPlainDatePrototype)
+}
+
+func (r *Runtime) newTemporalPlainTime(t isoTime, proto *Object) *Object {
+	o := &temporalPlainTimeObject{time: t}
+	return r.initTemporalObject(o, &o.baseObject, proto, r.global.TemporalPlainTimePrototype)
+}
+
+func (r *Runtime) newTemporalPlainDateTime(d isoDate, t isoTime, proto *Object) *Object {
+	if !isoDateTimeWithinLimits(d, t) {
+		panic(r.newError(r.global.RangeError, "Date-time is out of range"))
+	}
+	o := &temporalPlainDateTimeObject{date: d, time: t}
+	return r.initTemporalObject(o, &o.baseObject, proto, r.global.TemporalPlainDateTimePrototype)
+}
+
+func (r *Runtime) newTemporalPlainYearMonth(d isoDate, proto *Object) *Object {
+	if !isoYearMonthWithinLimits(d.year, d.month) {
+		panic(r.newError(r.global.RangeError, "Date is out of range"))
+	}
+	o := &temporalPlainYearMonthObject{date: d}
+	return r.initTemporalObject(o, &o.baseObject, proto, r.global.TemporalPlainYearMonthPrototype)
+}
+
+func (r *Runtime) newTemporalPlainMonthDay(d isoDate, proto *Object) *Object {
+	o := &temporalPlainMonthDayObject{date: d}
+	return r.initTemporalObject(o, &o.baseObject, proto, r.global.TemporalPlainMonthDayPrototype)
+}
+
+func (r *Runtime) thisTemporalPlainDate(this Value, method string) *temporalPlainDateObject {
+	if o, ok := this.(*Object); ok {
+		if d, ok := o.self.(*temporalPlainDateObject); ok {
+			return d
+		}
+	}
+	panic(r.temporalTypeError("PlainDate", method, this))
+}
+
+func (r *Runtime) thisTemporalPlainTime(this Value, method string) *temporalPlainTimeObject {
+	if o, ok := this.(*Object); ok {
+		if t, ok := o.self.(*temporalPlainTimeObject); ok {
+			return t
+		}
+	}
+	panic(r.temporalTypeError("PlainTime", method, this))
+}
+
+func (r *Runtime) thisTemporalPlainDateTime(this Value, method string) *temporalPlainDateTimeObject {
+	if o, ok := this.(*Object); ok {
+		if dt, ok := o.self.(*temporalPlainDateTimeObject); ok {
+			return dt
+		}
+	}
+	panic(r.temporalTypeError("PlainDateTime", method, this))
+}
+
+func (r *Runtime) thisTemporalPlainYearMonth(this Value, method string) *temporalPlainYearMonthObject {
+	if o, ok := this.(*Object); ok {
+		if ym, ok := o.self.(*temporalPlainYearMonthObject); ok {
+			return ym
+		}
+	}
+	panic(r.temporalTypeError("PlainYearMonth", method, this))
+}
+
+func (r *Runtime) thisTemporalPlainMonthDay(this Value, method string) *temporalPlainMonthDayObject {
+	if o, ok := this.(*Object); ok {
+		if md, ok := o.self.(*temporalPlainMonthDayObject); ok {
+			return md
+		}
+	}
+	panic(r.temporalTypeError("PlainMonthDay", method, this))
+}
+
+var temporalDateGetters = [...]struct {
+	name string
+	get  func(d isoDate) Value
+}{
+	{"calendarId", func(isoDate) Value { return asciiString("iso8601") }},
+	{"era", func(isoDate) Value { return _undefined }},
+	{"eraYear", func(isoDate) Value { return _undefined }},
+	{"year", func(d isoDate) Value { return intToValue(int64(d.year)) }},
+	{"month", func(d isoDate) Value { return intToValue(int64(d.month)) }},
+	{"monthCode", func(d isoDate) Value { return asciiString(formatMonthCode(d.month)) }},
+	{"day", func(d isoDate) Value { return intToValue(int64(d.day)) }},
+	{"dayOfWeek", func(d isoDate) Value { return intToValue(int64(d.dayOfWeek())) }},
+	{"dayOfYear", func(d isoDate) Value { return intToValue(int64(d.dayOfYear())) }},
+	{"weekOfYear", func(d isoDate) Value { w, _ := d.weekOfYear(); return intToValue(int64(w)) }},
+	{"yearOfWeek", func(d isoDate) Value { _, y := d.weekOfYear(); return intToValue(int64(y)) }},
+	{"daysInWeek", func(isoDate) Value { return intToValue(7) }},
+	{"daysInMonth", func(d isoDate) Value { return intToValue(int64(isoDaysInMonth(d.year, d.month))) }},
+	{"daysInYear", func(d isoDate) Value { return intToValue(int64(isoDaysInYear(d.year))) }},
+	{"monthsInYear", func(isoDate) Value { return intToValue(12) }},
+	{"inLeapYear", func(d isoDate) Value { return valueBool(isoIsLeapYear(d.year)) }},
+}
+
+var temporalTimeGetters = [...]struct {
+	name string
+	get  func(t isoTime) Value
+}{
+	{"hour", func(t isoTime) Value { return intToValue(int64(t.hour)) }},
+	{"minute", func(t isoTime) Value { return intToValue(int64(t.minute)) }},
+	{"second", func(t isoTime) Value { return intToValue(int64(t.second)) }},
+	{"millisecond", func(t isoTime) Value { return intToValue(int64(t.millisecond)) }},
+	{"microsecond", func(t isoTime) Value { return intToValue(int64(t.microsecond)) }},
+	{"nanosecond", func(t isoTime) Value { return intToValue(int64(t.nanosecond)) }},
+}
+
+// putTemporalDateGetters defines the date getters on a prototype. If names are specified, only those getters are
+// defined.
+func (r *Runtime) putTemporalDateGetters(o *baseObject, date func(this Value, method string) isoDate, names ...string) {
+	var only map[string]bool
+	if names != nil {
+		only = make(map[string]bool, len(names))
+		for _, name := range names {
+			only[name] = true
+		}
+	}
+	for _, g := range temporalDateGetters {
+		if only != nil && !only[g.name] {
+			continue
+		}
+		g := g
+		r.putTemporalGetter(o, g.name, func(call FunctionCall) Value {
+			return g.get(date(call.This, g.name))
+		})
+	}
+}
+
+func (r *Runtime) putTemporalTimeGetters(o *baseObject, time func(this Value, method string) isoTime) {
+	for _, g := range temporalTimeGetters {
+		g := g
+		r.putTemporalGetter(o, g.name, func(call FunctionCall) Value {
+			return g.get(time(call.This, g.name))
+		})
+	}
+}
+
+func (r *Runtime) temporalGetCalendarNameOption(opts *Object) string {
+	switch r.temporalGetOption(opts, "calendarName", []string{"auto", "always", "never", "critical"}, "auto") {
+	case "always":
+		return "[u-ca=iso8601]"
+	case "critical":
+		return "[!u-ca=iso8601]"
+	}
+	return ""
+}
+
+func (r *Runtime) temporalArgs(args []Value, n int) []float64 {
+	res := make([]float64, n)
+	for i := range res {
+		if i < len(args) && args[i] != _undefined {
+			res[i] = r.temporalToIntegerWithTruncation(args[i])
+		}
+	}
+	return res
+}
+
+func (r *Runtime) temporalTimeFromArgs(a []float64) isoTime {
+	if !isValidISOTime(a[0], a[1], a[2], a[3], a[4], a[5]) {
+		panic(r.newError(r.global.RangeError, "Invalid time"))
+	}
+	return isoTime{hour: int(a[0]), minute: int(a[1]), second: int(a[2]), millisecond: int(a[3]), microsecond: int(a[4]), nanosecond: int(a[5])}
+}
+
+// toTemporalDate implements https://tc39.es/proposal-temporal/#sec-temporal-totemporaldate
+func (r *Runtime) toTemporalDate(v Value, options Value) isoDate {
+	if o, ok := v.(*Object); ok {
+		var d isoDate
+		switch t := o.self.(type) {
+		case *temporalPlainDateObject:
+			d = t.date
+		case *temporalPlainDateTimeObject:
+			d = t.date
+		case *temporalZonedDateTimeObject:
+			d, _ = t.tz.isoDateTimeAt(t.ns)
+		default:
+			f := r.temporalReadFields(o, temporalFieldCalendar|temporalDateFields)
+			return r.temporalResolveDate(f, r.temporalGetOverflow(r.temporalOptions(options)))
+		}
+		r.temporalGetOverflow(r.temporalOptions(options))
+		return d
+	}
+	s, ok := v.(valueString)
+	if !ok {
+		panic(r.NewTypeError("Cannot convert %s to a Temporal.PlainDate", v))
+	}
+	p, ok := parseTemporalDateTimeString(s.String())
+	if !ok || p.z {
+		panic(r.newError(r.global.RangeError, "Invalid date string: %s", s.String()))
+	}
+	r.temporalCheckParsedCalendar(&p)
+	r.temporalGetOverflow(r.temporalOptions(options))
+	return p.date
+}
+
+// toTemporalYearMonth implements https://tc39.es/proposal-temporal/#sec-temporal-totemporalyearmonth
+func (r *Runtime) toTemporalYearMonth(v Value, options Value) isoDate {
+	if o, ok := v.(*Object); ok {
+		if ym, ok := o.self.(*temporalPlainYearMonthObject); ok {
+			r.temporalGetOverflow(r.temporalOptions(options))
+			return ym.date
+		}
+		f := r.temporalReadFields(o, temporalFieldCalendar|temporalYearMonthFields)
+		return r.temporalResolveYearMonth(f, r.temporalGetOverflow(r.temporalOptions(options)))
+	}
+	s, ok := v.(valueString)
+	if !ok {
+		panic(r.NewTypeError("Cannot convert %s to a Temporal.PlainYearMonth", v))
+	}
+	p, ok := parseTemporalYearMonthString(s.String())
+	if !ok || p.z {
+		panic(r.newError(r.global.RangeError, "Invalid year-month string: %s", s.String()))
+	}
+	r.temporalCheckParsedCalendar(&p)
+	r.temporalGetOverflow(r.temporalOptions(options))
+	return isoDate{year: p.date.year, month: p.date.month, day: 1}
+}
+
+// toTemporalMonthDay implements https://tc39.es/proposal-temporal/#sec-temporal-totemporalmonthday
+func (r *Runtime) toTemporalMonthDay(v Value, options Value) isoDate {
+	if o, ok := v.(*Object); ok {
+		if md, ok := o.self.(*temporalPlainMonthDayObject); ok {
+			r.temporalGetOverflow(r.temporalOptions(options))
+			return md.date
+		}
+		f := r.temporalReadFields(o, temporalFieldCalendar|temporalDateFields)
+		return r.temporalResolveMonthDay(f, r.temporalGetOverflow(r.temporalOptions(options)))
+	}
+	s, ok := v.(valueString)
+	if !ok {
+		panic(r.NewTypeError("Cannot convert %s to a Temporal.PlainMonthDay", v))
+	}
+	p, ok := parseTemporalMonthDayString(s.String())
+	if !ok || p.z {
+		panic(r.newError(r.global.RangeError, "Invalid month-day string: %s", s.String()))
+	}
+	r.temporalCheckParsedCalendar(&p)
+	r.temporalGetOverflow(r.temporalOptions(options))
+	return isoDate{year: temporalReferenceYear, month: p.date.month, day: p.date.day}
+}
+
+// toTemporalTime implements https://tc39.es/proposal-temporal/#sec-temporal-totemporaltime
+func (r *Runtime) toTemporalTime(v Value, options Value) isoTime {
+	if o, ok := v.(*Object); ok {
+		var t isoTime
+		switch tt := o.self.(type) {
+		case *temporalPlainTimeObject:
+			t = tt.time
+		case *temporalPlainDateTimeObject:
+			t = tt.time
+		case *temporalZonedDateTimeObject:
+			_, t = tt.tz.isoDateTimeAt(tt.ns)
+		default:
+			f := r.temporalReadFields(o, temporalTimeFields)
+			if f.has == 0 {
+				panic(r.NewTypeError("Time object must have at least one time property"))
+			}
+			return r.temporalResolveTime(f, r.temporalGetOverflow(r.temporalOptions(options)))
+		}
+		r.temporalGetOverflow(r.temporalOptions(options))
+		return t
+	}
+	s, ok := v.(valueString)
+	if !ok {
+		panic(r.NewTypeError("Cannot convert %s to a Temporal.PlainTime", v))
+	}
+	p, ok := parseTemporalTimeString(s.String())
+	if !ok || p.z {
+		panic(r.newError(r.global.RangeError, "Invalid time string: %s", s.String()))
+	}
+	r.temporalCheckParsedCalendar(&p)
+	r.temporalGetOverflow(r.temporalOptions(options))
+	return p.time
+}
+
+// toTemporalDateTime implements https://tc39.es/proposal-temporal/#sec-temporal-totemporaldatetime
+func (r *Runtime) toTemporalDateTime(v Value, options Value) (isoDate, isoTime) {
+	if o, ok := v.(*Object); ok {
+		var d isoDate
+		var t isoTime
+		switch tt := o.self.(type) {
+		case *temporalPlainDateTimeObject:
+			d, t = tt.date, tt.time
+		case *temporalPlainDateObject:
+			d = tt.date
+		case *temporalZonedDateTimeObject:
+			d, t = tt.tz.isoDateTimeAt(tt.ns)
+		default:
+			f := r.temporalReadFields(o, temporalFieldCalendar|temporalDateFields|temporalTimeFields)
+			reject := r.temporalGetOverflow(r.temporalOptions(options))
+			return r.temporalResolveDate(f, reject), r.temporalResolveTime(f, reject)
+		}
+		r.temporalGetOverflow(r.temporalOptions(options))
+		return d, t
+	}
+	s, ok := v.(valueString)
+	if !ok {
+		panic(r.NewTypeError("Cannot convert %s to a Temporal.PlainDateTime", v))
+	}
+	p, ok := parseTemporalDateTimeString(s.String())
+	if !ok || p.z {
+		panic(r.newError(r.global.RangeError, "Invalid date-time string: %s", s.String()))
+	}
+	r.temporalCheckParsedCalendar(&p)
+	r.temporalGetOverflow(r.temporalOptions(options))
+	return p.date, p.time
+}
+
+// temporalAddToDate adds a duration to a date, the time portion of the duration is balanced into days.
+func (r *Runtime) temporalAddToDate(date isoDate, d temporalDuration, reject bool) isoDate {
+	days, _ := new(big.Float).SetInt(new(big.Int).Quo(d.timeNs(false), bigNsPerDay)).Float64()
+	res, ok := date.addDate(d[0], d[1], d[2], d[3]+days, reject)
+	if !ok {
+		panic(r.newError(r.global.RangeError, "Invalid date"))
+	}
+	if !res.withinLimits() {
+		panic(r.newError(r.global.RangeError, "Date is out of range"))
+	}
+	return res
+}
+
+// temporalAddToDateTime adds a duration to a date and time
+// (see https://tc39.es/proposal-temporal/#sec-temporal-adddatetime).
+func (r *Runtime) temporalAddToDateTime(date isoDate, t isoTime, d temporalDuration, reject bool) (isoDate, isoTime) {
+	total := d.timeNs(false)
+	total.Add(total, big.NewInt(t.ns()))
+	days, rem := new(big.Int).DivMod(total, bigNsPerDay, new(big.Int))
+	newTime, _ := isoTimeFromNs(rem.Int64())
+	extraDays, _ := new(big.Float).SetInt(days).Float64()
+	res, ok := date.addDate(d[0], d[1], d[2], d[3]+extraDays, reject)
+	if !ok {
+		panic(r.newError(r.global.RangeError, "Invalid date"))
+	}
+	if !isoDateTimeWithinLimits(res, newTime) {
+		panic(r.newError(r.global.RangeError, "Date-time is out of range"))
+	}
+	return res, newTime
+}
+
+// temporalRoundTimeDifference rounds the time portion of a difference and merges it into the date portion.
+func temporalRoundTimeDifference(d temporalDuration, timeNs *big.Int, s temporalDiffSettings, dayNs *big.Int) temporalDuration {
+	if s.smallest != temporalUnitNanosecond || s.increment != 1 {
+		timeNs = temporalRound(timeNs, big.NewInt(s.increment*temporalUnitNs[s.smallest]), s.mode)
+	}
+	if !s.largest.isTimeUnit() && dayNs != nil && timeNs.CmpAbs(dayNs) >= 0 {
+		timeNs = new(big.Int).Sub(timeNs, new(big.Int).Mul(dayNs, big.NewInt(int64(timeNs.Sign()))))
+		d[3] += float64(timeNs.Sign())
+		if timeNs.Sign() == 0 {
+			d[3] += float64(d.sign())
+		}
+	}
+	largest := s.largest
+	if !largest.isTimeUnit() {
+		largest = temporalUnitHour
+	}
+	t := balanceTimeDuration(timeNs, largest)
+	copy(d[4:], t[4:])
+	return d
+}
+
+func (r *Runtime) builtin_newTemporalPlainDate(args []Value, newTarget *Object) *Object {
+	if newTarget == nil {
+		panic(r.needNew("Temporal.PlainDate"))
+	}
+	a := r.temporalArgs(args, 3)
+	if len(args) > 3 {
+		r.temporalCheckCalendar(args[3])
+	}
+	if !isValidISODate(a[0], a[1], a[2]) {
+		panic(r.newError(r.global.RangeError, "Invalid date"))
+	}
+	proto := r.getPrototypeFromCtor(newTarget, r.global.TemporalPlainDate, r.global.TemporalPlainDatePrototype)
+	return r.newTemporalPlainDate(isoDate{year: int(a[0]), month: int(a[1]), day: int(a[2])}, proto)
+}
+
+func (r *Runtime) temporalPlainDate_from(call FunctionCall) Value {
+	return r.newTemporalPlainDate(r.toTemporalDate(call.Argument(0), call.Argument(1)), nil)
+}
+
+func (r *Runtime) temporalPlainDate_compare(call FunctionCall) Value {
+	one := r.toTemporalDate(call.Argument(0), _undefined)
+	two := r.toTemporalDate(call.Argument(1), _undefined)
+	return intToValue(int64(one.compare(two)))
+}
+
+func (r *Runtime) temporalPlainDateProto_with(call FunctionCall) Value {
+	d := r.thisTemporalPlainDate(call.This, "with")
+	partial := r.temporalReadPartialFields(call.Argument(0), temporalDateFields)
+	var f temporalFields
+	f.setDate(d.date)
+	f.merge(partial)
+	return r.newTemporalPlainDate(r.temporalResolveDate(&f, r.temporalGetOverflow(r.temporalOptions(call.Argument(1)))), nil)
+}
+
+func (r *Runtime) temporalPlainDateAdd(call FunctionCall, method string, sign int) Value {
+	d := r.thisTemporalPlainDate(call.This, method)
+	dur := r.toTemporalDuration(call.Argument(0))
+	if sign < 0 {
+		dur = dur.negated()
+	}
+	reject := r.temporalGetOverflow(r.temporalOptions(call.Argument(1)))
+	return r.newTemporalPlainDate(r.temporalAddToDate(d.date, dur, reject), nil)
+}
+
+func (r *Runtime) temporalPlainDateProto_add(call FunctionCall) Value {
+	return r.temporalPlainDateAdd(call, "add", 1)
+}
+
+func (r *Runtime) temporalPlainDateProto_subtract(call FunctionCall) Value {
+	return r.temporalPlainDateAdd(call, "subtract", -1)
+}
+
+func (r *Runtime) temporalPlainDateDifference(call FunctionCall, method string, since bool) Value {
+	d := r.thisTemporalPlainDate(call.This, method)
+	other := r.toTemporalDate(call.Argument(0), _undefined)
+	s := r.temporalGetDifferenceSettings(r.temporalOptions(call.Argument(1)), temporalUnitYear, temporalUnitDay, temporalUnitDay)
+	if since {
+		s.mode = temporalNegateRoundingMode(s.mode)
+	}
+	res := differenceISODate(d.date, other, s.largest)
+	if sign := res.sign(); sign != 0 && (s.smallest != temporalUnitDay || s.increment != 1) {
+		toNs := func(date isoDate) *big.Int {
+			return new(big.Int).Mul(big.NewInt(date.epochDays()), bigNsPerDay)
+		}
+		res = roundCalendarDuration(res, sign, d.date, toNs, toNs(other), s.largest, s.smallest, s.increment, s.mode)
+	}
+	if since {
+		res = res.negated()
+	}
+	return r.newTemporalDuration(res, nil)
+}
+
+func (r *Runtime) temporalPlainDateProto_until(call FunctionCall) Value {
+	return r.temporalPlainDateDifference(call, "until", false)
+}
+
+func (r *Runtime) temporalPlainDateProto_since(call FunctionCall) Value {
+	return r.temporalPlainDateDifference(call, "since", true)
+}
+
+func (r *Runtime) temporalPlainDateProto_equals(call FunctionCall) Value {
+	d := r.thisTemporalPlainDate(call.This, "equals")
+	return r.toBoolean(d.date == r.toTemporalDate(call.Argument(0), _undefined))
+}
+
+func (r *Runtime) temporalPlainDateProto_toString(call FunctionCall) Value {
+	d := r.thisTemporalPlainDate(call.This, "toString")
+	cal := r.temporalGetCalendarNameOption(r.temporalOptions(call.Argument(0)))
+	return asciiString(d.date.String() + cal)
+}
+
+func (r *Runtime) temporalPlainDateProto_toJSON(call FunctionCall) Value {
+	return asciiString(r.thisTemporalPlainDate(call.This, "toJSON").date.String())
+}
+
+func (r *Runtime) temporalPlainDateProto_toLocaleString(call FunctionCall) Value {
+	return asciiString(r.thisTemporalPlainDate(call.This, "toLocaleString").date.String())
+}
+
+func (r *Runtime) temporalPlainDateProto_toPlainDateTime(call FunctionCall) Value {
+	d := r.thisTemporalPlainDate(call.This, "toPlainDateTime")
+	var t isoTime
+	if arg := call.Argument(0); arg != _undefined {
+		t = r.toTemporalTime(arg, _undefined)
+	}
+	return r.newTemporalPlainDateTime(d.date, t, nil)
+}
+
+func (r *Runtime) temporalPlainDateProto_toPlainYearMonth(call FunctionCall) Value {
+	d := r.thisTemporalPlainDate(call.This, "toPlainYearMonth")
+	return r.newTemporalPlainYearMonth(isoDate{year: d.date.year, month: d.date.month, day: 1}, nil)
+}
+
+func (r *Runtime) temporalPlainDateProto_toPlainMonthDay(call FunctionCall) Value {
+	d := r.thisTemporalPlainDate(call.This, "toPlainMonthDay")
+	return r.newTemporalPlainMonthDay(isoDate{year: temporalReferenceYear, month: d.date.month, day: d.date.day}, nil)
+}
+
+// temporalStartOfDay returns the first exact time of the day in the time zone.
+func temporalStartOfDay(tz *temporalTimeZone, d isoDate) *big.Int {
+	if possible := tz.possibleEpochNs(d, isoTime{}); len(possible) > 0 {
+		return possible[0]
+	}
+	return tz.epochNsFor(d, isoTime{}, "compatible")
+}
+
+func (r *Runtime) temporalPlainDateProto_toZonedDateTime(call FunctionCall) Value {
+	d := r.thisTemporalPlainDate(call.This, "toZonedDateTime")
+	item := call.Argument(0)
+	var tz *temporalTimeZone
+	var t Value = _undefined
+	if o, ok := item.(*Object); ok {
+		if _, ok := o.self.(*temporalZonedDateTimeObject); !ok {
+			if tzLike := o.self.getStr("timeZone", nil); tzLike != nil && tzLike != _undefined {
+				tz = r.temporalToTimeZone(tzLike)
+				t = nilSafe(o.self.getStr("plainTime", nil))
+			}
+		}
+	}
+	if tz == nil {
+		tz = r.temporalToTimeZone(item)
+	}
+	var ns *big.Int
+	if t == _undefined {
+		ns = temporalStartOfDay(tz, d.date)
+	} else {
+		ns = tz.epochNsFor(d.date, r.toTemporalTime(t, _undefined), "compatible")
+	}
+	return r.newTemporalZonedDateTime(ns, tz, nil)
+}
+
+func (r *Runtime) builtin_newTemporalPlainTime(args []Value, newTarget *Object) *Object {
+	if newTarget == nil {
+		panic(r.needNew("Temporal.PlainTime"))
+	}
+	t := r.temporalTimeFromArgs(r.temporalArgs(args, 6))
+	proto := r.getPrototypeFromCtor(newTarget, r.global.TemporalPlainTime, r.global.TemporalPlainTimePrototype)
+	return r.newTemporalPlainTime(t, proto)
+}
+
+func (r *Runtime) temporalPlainTime_from(call FunctionCall) Value {
+	return r.newTemporalPlainTime(r.toTemporalTime(call.Argument(0), call.Argument(1)), nil)
+}
+
+func (r *Runtime) temporalPlainTime_compare(call FunctionCall) Value {
+	one := r.toTemporalTime(call.Argument(0), _undefined)
+	two := r.toTemporalTime(call.Argument(1), _undefined)
+	return intToValue(int64(one.compare(two)))
+}
+
+func (r *Runtime) temporalPlainTimeProto_with(call FunctionCall) Value {
+	t := r.thisTemporalPlainTime(call.This, "with")
+	partial := r.temporalReadPartialFields(call.Argument(0), temporalTimeFields)
+	var f temporalFields
+	f.setTime(t.time)
+	f.merge(partial)
+	return r.newTemporalPlainTime(r.temporalResolveTime(&f, r.temporalGetOverflow(r.temporalOptions(call.Argument(1)))), nil)
+}
+
+func (r *Runtime) temporalPlainTimeAdd(call FunctionCall, method string, sign int) Value {
+	t := r.thisTemporalPlainTime(call.This, method)
+	d := r.toTemporalDuration(call.Argument(0))
+	ns := d.timeNs(false)
+	if sign < 0 {
+		ns.Neg(ns)
+	}
+	ns.Add(ns, big.NewInt(t.time.ns()))
+	ns.Mod(ns, bigNsPerDay)
+	res, _ := isoTimeFromNs(ns.Int64())
+	return r.newTemporalPlainTime(res, nil)
+}
+
+func (r *Runtime) temporalPlainTimeProto_add(call FunctionCall) Value {
+	return r.temporalPlainTimeAdd(call, "add", 1)
+}
+
+func (r *Runtime) temporalPlainTimeProto_subtract(call FunctionCall) Value {
+	return r.temporalPlainTimeAdd(call, "subtract", -1)
+}
+
+func (r *Runtime) temporalPlainTimeDifference(call FunctionCall, method string, since bool) Value {
+	t := r.thisTemporalPlainTime(call.This, method)
+	other := r.toTemporalTime(call.Argument(0), _undefined)
+	s := r.temporalGetDifferenceSettings(r.temporalOptions(call.Argument(1)), temporalUnitHour, temporalUnitNanosecond, temporalUnitHour)
+	if since {
+		s.mode = temporalNegateRoundingMode(s.mode)
+	}
+	res := temporalDiffExact(big.NewInt(t.time.ns()), big.NewInt(other.ns()), s)
+	if since {
+		res = res.negated()
+	}
+	return r.newTemporalDuration(res, nil)
+}
+
+func (r *Runtime) temporalPlainTimeProto_until(call FunctionCall) Value {
+	return r.temporalPlainTimeDifference(call, "until", false)
+}
+
+func (r *Runtime) temporalPlainTimeProto_since(call FunctionCall) Value {
+	return r.temporalPlainTimeDifference(call, "since", true)
+}
+
+func (r *Runtime) temporalPlainTimeProto_round(call FunctionCall) Value {
+	t := r.thisTemporalPlainTime(call.This, "round")
+	_, smallest, increment, mode := r.temporalGetRoundTo(call.Argument(0), "halfExpand")
+	if smallest == temporalUnitAuto {
+		panic(r.newError(r.global.RangeError, "smallestUnit is required"))
+	}
+	r.temporalCheckUnit("smallestUnit", smallest, temporalUnitHour, temporalUnitNanosecond)
+	r.temporalValidateIncrement(increment, temporalMaxIncrement(smallest), false)
+	ns := temporalRound(big.NewInt(t.time.ns()), big.NewInt(increment*temporalUnitNs[smallest]), mode)
+	res, _ := isoTimeFromNs(ns.Int64())
+	return r.newTemporalPlainTime(res, nil)
+}
+
+func (r *Runtime) temporalPlainTimeProto_equals(call FunctionCall) Value {
+	t := r.thisTemporalPlainTime(call.This, "equals")
+	return r.toBoolean(t.time == r.toTemporalTime(call.Argument(0), _undefined))
+}
+
+func formatTemporalTime(t isoTime, precision int) string {
+	var b strings.Builder
+	writeISOTime(&b, t, precision)
+	return b.String()
+}
+
+func (r *Runtime) temporalPlainTimeProto_toString(call FunctionCall) Value {
+	t := r.thisTemporalPlainTime(call.This, "toString")
+	precision, increment, mode := r.temporalGetPrecisionOptions(r.temporalOptions(call.Argument(0)))
+	ns := temporalRound(big.NewInt(t.time.ns()), big.NewInt(increment), mode)
+	res, _ := isoTimeFromNs(ns.Int64())
+	return asciiString(formatTemporalTime(res, precision))
+}
+
+func (r *Runtime) temporalPlainTimeProto_toJSON(call FunctionCall) Value {
+	return asciiString(formatTemporalTime(r.thisTemporalPlainTime(call.This, "toJSON").time, temporalPrecisionAuto))
+}
+
+func (r *Runtime) temporalPlainTimeProto_toLocaleString(call FunctionCall) Value {
+	return asciiString(formatTemporalTime(r.thisTemporalPlainTime(call.This, "toLocaleString").time, temporalPrecisionAuto))
+}
+
+func (r *Runtime) builtin_newTemporalPlainDateTime(args []Value, newTarget *Object) *Object {
+	if newTarget == nil {
+		panic(r.needNew("Temporal.PlainDateTime"))
+	}
+	a := r.temporalArgs(args, 9)
+	if len(args) > 9 {
+		r.temporalCheckCalendar(args[9])
+	}
+	if !isValidISODate(a[0], a[1], a[2]) {
+		panic(r.newError(r.global.RangeError, "Invalid date"))
+	}
+	t := r.temporalTimeFromArgs(a[3:])
+	proto := r.getPrototypeFromCtor(newTarget, r.global.TemporalPlainDateTime, r.global.TemporalPlainDateTimePrototype)
+	return r.newTemporalPlainDateTime(isoDate{year: int(a[0]), month: int(a[1]), day: int(a[2])}, t, proto)
+}
+
+func (r *Runtime) temporalPlainDateTime_from(call FunctionCall) Value {
+	d, t := r.toTemporalDateTime(call.Argument(0), call.Argument(1))
+	return r.newTemporalPlainDateTime(d, t, nil)
+}
+
+func compareISODateTime(d1 isoDate, t1 isoTime, d2 isoDate, t2 isoTime) int {
+	if c := d1.compare(d2); c != 0 {
+		return c
+	}
+	return t1.compare(t2)
+}
+
+func (r *Runtime) temporalPlainDateTime_compare(call FunctionCall) Value {
+	d1, t1 := r.toTemporalDateTime(call.Argument(0), _undefined)
+	d2, t2 := r.toTemporalDateTime(call.Argument(1), _undefined)
+	return intToValue(int64(compareISODateTime(d1, t1, d2, t2)))
+}
+
+func (r *Runtime) temporalPlainDateTimeProto_with(call FunctionCall) Value {
+	dt := r.thisTemporalPlainDateTime(call.This, "with")
+	partial := r.temporalReadPartialFields(call.Argument(0), temporalDateFields|temporalTimeFields)
+	var f temporalFields
+	f.setDate(dt.date)
+	f.setTime(dt.time)
+	f.merge(partial)
+	reject := r.temporalGetOverflow(r.temporalOptions(call.Argument(1)))
+	return r.newTemporalPlainDateTime(r.temporalResolveDate(&f, reject), r.temporalResolveTime(&f, reject), nil)
+}
+
+func (r *Runtime) temporalPlainDateTimeProto_withPlainTime(call FunctionCall) Value {
+	dt := r.thisTemporalPlainDateTime(call.This, "withPlainTime")
+	var t isoTime
+	if arg := call.Argument(0); arg != _undefined {
+		t = r.toTemporalTime(arg, _undefined)
+	}
+	return r.newTemporalPlainDateTime(dt.date, t, nil)
+}
+
+func (r *Runtime) temporalPlainDateTimeAdd(call FunctionCall, method string, sign int) Value {
+	dt := r.thisTemporalPlainDateTime(call.This, method)
+	d := r.toTemporalDuration(call.Argument(0))
+	if sign < 0 {
+		d = d.negated()
+	}
+	reject := r.temporalGetOverflow(r.temporalOptions(call.Argument(1)))
+	date, t := r.temporalAddToDateTime(dt.date, dt.time, d, reject)
+	return r.newTemporalPlainDateTime(date, t, nil)
+}
+
+func (r *Runtime) temporalPlainDateTimeProto_add(call FunctionCall) Value {
+	return r.temporalPlainDateTimeAdd(call, "add", 1)
+}
+
+func (r *Runtime) temporalPlainDateTimeProto_subtract(call FunctionCall) Value {
+	return r.temporalPlainDateTimeAdd(call, "subtract", -1)
+}
+
+func (r *Runtime) temporalPlainDateTimeDifference(call FunctionCall, method string, since bool) Value {
+	dt := r.thisTemporalPlainDateTime(call.This, method)
+	d2, t2 := r.toTemporalDateTime(call.Argument(0), _undefined)
+	s := r.temporalGetDifferenceSettings(r.temporalOptions(call.Argument(1)), temporalUnitYear, temporalUnitNanosecond, temporalUnitDay)
+	if since {
+		s.mode = temporalNegateRoundingMode(s.mode)
+	}
+	res, timeNs := differenceISODateTime(dt.date, dt.time, d2, t2, s.largest)
+	sign := compareISODateTime(d2, t2, dt.date, dt.time)
+	if s.smallest.isTimeUnit() || sign == 0 {
+		res = temporalRoundTimeDifference(res, timeNs, s, bigNsPerDay)
+	} else {
+		toNs := func(date isoDate) *big.Int {
+			return isoDateTimeToEpochNs(date, dt.time)
+		}
+		res = roundCalendarDuration(res, sign, dt.date, toNs, isoDateTimeToEpochNs(d2, t2), s.largest, s.smallest, s.increment, s.mode)
+	}
+	if since {
+		res = res.negated()
+	}
+	return r.newTemporalDuration(res, nil)
+}
+
+func (r *Runtime) temporalPlainDateTimeProto_until(call FunctionCall) Value {
+	return r.temporalPlainDateTimeDifference(call, "until", false)
+}
+
+func (r *Runtime) temporalPlainDateTimeProto_since(call FunctionCall) Value {
+	return r.temporalPlainDateTimeDifference(call, "since", true)
+}
+
+// temporalGetDateTimeRoundTo reads the options of round() for the types that include a date.
+func (r *Runtime) temporalGetDateTimeRoundTo(v Value) (smallest temporalUnit, increment int64, mode string) {
+	_, smallest, increment, mode = r.temporalGetRoundTo(v, "halfExpand")
+	if smallest == temporalUnitAuto {
+		panic(r.newError(r.global.RangeError, "smallestUnit is required"))
+	}
+	r.temporalCheckUnit("smallestUnit", smallest, temporalUnitDay, temporalUnitNanosecond)
+	if smallest == temporalUnitDay {
+		r.temporalValidateIncrement(increment, 1, true)
+	} else {
+		r.temporalValidateIncrement(increment, temporalMaxIncrement(smallest), false)
+	}
+	return
+}
+
+func (r *Runtime) temporalPlainDateTimeProto_round(call FunctionCall) Value {
+	dt := r.thisTemporalPlainDateTime(call.This, "round")
+	smallest, increment, mode := r.temporalGetDateTimeRoundTo(call.Argument(0))
+	ns := temporalRound(big.NewInt(dt.time.ns()), big.NewInt(increment*temporalUnitNs[smallest]), mode)
+	t, days := isoTimeFromNs(ns.Int64())
+	return r.newTemporalPlainDateTime(dt.date.addDays(days), t, nil)
+}
+
+func (r *Runtime) temporalPlainDateTimeProto_equals(call FunctionCall) Value {
+	dt := r.thisTemporalPlainDateTime(call.This, "equals")
+	d, t := r.toTemporalDateTime(call.Argument(0), _undefined)
+	return r.toBoolean(dt.date == d && dt.time == t)
+}
+
+func formatTemporalDateTime(d isoDate, t isoTime, precision int) string {
+	var b strings.Builder
+	writeISODate(&b, d)
+	b.WriteByte('T')
+	writeISOTime(&b, t, precision)
+	return b.String()
+}
+
+func (r *Runtime) temporalPlainDateTimeProto_toString(call FunctionCall) Value {
+	dt := r.thisTemporalPlainDateTime(call.This, "toString")
+	opts := r.temporalOptions(call.Argument(0))
+	cal := r.temporalGetCalendarNameOption(opts)
+	precision, increment, mode := r.temporalGetPrecisionOptions(opts)
+	ns := temporalRound(big.NewInt(dt.time.ns()), big.NewInt(increment), mode)
+	t, days := isoTimeFromNs(ns.Int64())
+	d := dt.date.addDays(days)
+	if !isoDateTimeWithinLimits(d, t) {
+		panic(r.newError(r.global.RangeError, "Date-time is out of range"))
+	}
+	return asciiString(formatTemporalDateTime(d, t, precision) + cal)
+}
+
+func (r *Runtime) temporalPlainDateTimeProto_toJSON(call FunctionCall) Value {
+	dt := r.thisTemporalPlainDateTime(call.This, "toJSON")
+	return asciiString(formatTemporalDateTime(dt.date, dt.time, temporalPrecisionAuto))
+}
+
+func (r *Runtime) temporalPlainDateTimeProto_toLocaleString(call FunctionCall) Value {
+	dt := r.thisTemporalPlainDateTime(call.This, "toLocaleString")
+	return asciiString(formatTemporalDateTime(dt.date, dt.time, temporalPrecisionAuto))
+}
+
+func (r *Runtime) temporalPlainDateTimeProto_toPlainDate(call FunctionCall) Value {
+	return r.newTemporalPlainDate(r.thisTemporalPlainDateTime(call.This, "toPlainDate").date, nil)
+}
+
+func (r *Runtime) temporalPlainDateTimeProto_toPlainTime(call FunctionCall) Value {
+	return r.newTemporalPlainTime(r.thisTemporalPlainDateTime(call.This, "toPlainTime").time, nil)
+}
+
+func (r *Runtime) temporalGetDisambiguation(opts *Object) string {
+	return r.temporalGetOption(opts, "disambiguation", []string{"compatible", "earlier", "later", "reject"}, "compatible")
+}
+
+func (r *Runtime) temporalPlainDateTimeProto_toZonedDateTime(call FunctionCall) Value {
+	dt := r.thisTemporalPlainDateTime(call.This, "toZonedDateTime")
+	tz := r.temporalToTimeZone(call.Argument(0))
+	disambiguation := r.temporalGetDisambiguation(r.temporalOptions(call.Argument(1)))
+	ns := tz.epochNsFor(dt.date, dt.time, disambiguation)
+	if ns == nil {
+		panic(r.newError(r.global.RangeError, "The date-time is ambiguous or does not exist in the time zone %s", tz.id))
+	}
+	return r.newTemporalZonedDateTime(ns, tz, nil)
+}
+
+func (r *Runtime) builtin_newTemporalPlainYearMonth(args []Value, newTarget *Object) *Object {
+	if newTarget == nil {
+		panic(r.needNew("Temporal.PlainYearMonth"))
+	}
+	a := r.temporalArgs(args, 2)
+	if len(args) > 2 {
+		r.temporalCheckCalendar(args[2])
+	}
+	day := 1.0
+	if len(args) > 3 && args[3] != _undefined {
+		day = r.temporalToIntegerWithTruncation(args[3])
+	}
+	if !isValidISODate(a[0], a[1], day) {
+		panic(r.newError(r.global.RangeError, "Invalid date"))
+	}
+	proto := r.getPrototypeFromCtor(newTarget, r.global.TemporalPlainYearMonth, r.global.TemporalPlainYearMonthPrototype)
+	return r.newTemporalPlainYearMonth(isoDate{year: int(a[0]), month: int(a[1]), day: int(day)}, proto)
+}
+
+func (r *Runtime) temporalPlainYearMonth_from(call FunctionCall) Value {
+	return r.newTemporalPlainYearMonth(r.toTemporalYearMonth(call.Argument(0), call.Argument(1)), nil)
+}
+
+func (r *Runtime) temporalPlainYearMonth_compare(call FunctionCall) Value {
+	one := r.toTemporalYearMonth(call.Argument(0), _undefined)
+	two := r.toTemporalYearMonth(call.Argument(1), _undefined)
+	return intToValue(int64(one.compare(two)))
+}
+
+func (r *Runtime) temporalPlainYearMonthProto_with(call FunctionCall) Value {
+	ym := r.thisTemporalPlainYearMonth(call.This, "with")
+	partial := r.temporalReadPartialFields(call.Argument(0), temporalYearMonthFields)
+	var f temporalFields
+	f.setYearMonth(ym.date)
+	f.merge(partial)
+	return r.newTemporalPlainYearMonth(r.temporalResolveYearMonth(&f, r.temporalGetOverflow(r.temporalOptions(call.Argument(1)))), nil)
+}
+
+// temporalPlainYearMonthAdd implements https://tc39.es/proposal-temporal/#sec-temporal-adddurationtoyearmonth. The
+// duration is added to the first day of the month, or to the last one if the duration is negative.
+func (r *Runtime) temporalPlainYearMonthAdd(call FunctionCall, method string, sign int) Value {
+	ym := r.thisTemporalPlainYearMonth(call.This, method)
+	dur := r.toTemporalDuration(call.Argument(0))
+	if sign < 0 {
+		dur = dur.negated()
+	}
+	reject := r.temporalGetOverflow(r.temporalOptions(call.Argument(1)))
+	date := isoDate{year: ym.date.year, month: ym.date.month, day: 1}
+	if dur.sign() < 0 {
+		date.day = isoDaysInMonth(date.year, date.month)
+	}
+	res := r.temporalAddToDate(date, dur, reject)
+	return r.newTemporalPlainYearMonth(isoDate{year: res.year, month: res.month, day: 1}, nil)
+}
+
+func (r *Runtime) temporalPlainYearMonthProto_add(call FunctionCall) Value {
+	return r.temporalPlainYearMonthAdd(call, "add", 1)
+}
+
+func (r *Runtime) temporalPlainYearMonthProto_subtract(call FunctionCall) Value {
+	return r.temporalPlainYearMonthAdd(call, "subtract", -1)
+}
+
+func (r *Runtime) temporalPlainYearMonthDifference(call FunctionCall, method string, since bool) Value {
+	ym := r.thisTemporalPlainYearMonth(call.This, method)
+	other := r.toTemporalYearMonth(call.Argument(0), _undefined)
+	s := r.temporalGetDifferenceSettings(r.temporalOptions(call.Argument(1)), temporalUnitYear, temporalUnitMonth, temporalUnitYear)
+	if since {
+		s.mode = temporalNegateRoundingMode(s.mode)
+	}
+	one := isoDate{year: ym.date.year, month: ym.date.month, day: 1}
+	two := isoDate{year: other.year, month: other.month, day: 1}
+	res := differenceISODate(one, two, s.largest)
+	if sign := res.sign(); sign != 0 && (s.smallest != temporalUnitMonth || s.increment != 1) {
+		toNs := func(date isoDate) *big.Int {
+			return new(big.Int).Mul(big.NewInt(date.epochDays()), bigNsPerDay)
+		}
+		res = roundCalendarDuration(res, sign, one, toNs, toNs(two), s.largest, s.smallest, s.increment, s.mode)
+	}
+	if since {
+		res = res.negated()
+	}
+	return r.newTemporalDuration(res, nil)
+}
+
+func (r *Runtime) temporalPlainYearMonthProto_until(call FunctionCall) Value {
+	return r.temporalPlainYearMonthDifference(call, "until", false)
+}
+
+func (r *Runtime) temporalPlainYearMonthProto_since(call FunctionCall) Value {
+	return r.temporalPlainYearMonthDifference(call, "since", true)
+}
+
+func (r *Runtime) temporalPlainYearMonthProto_equals(call FunctionCall) Value {
+	ym := r.thisTemporalPlainYearMonth(call.This, "equals")
+	return r.toBoolean(ym.date == r.toTemporalYearMonth(call.Argument(0), _undefined))
+}
+
+// formatTemporalYearMonth formats the year and month, or the full reference date if the calendar is shown.
+func formatTemporalYearMonth(d isoDate, cal string) string {
+	if cal != "" {
+		return d.String() + cal
+	}
+	var b strings.Builder
+	writeISOYear(&b, d.year)
+	b.WriteByte('-')
+	writePadded(&b, d.month, 2)
+	return b.String()
+}
+
+func (r *Runtime) temporalPlainYearMonthProto_toString(call FunctionCall) Value {
+	ym := r.thisTemporalPlainYearMonth(call.This, "toString")
+	cal := r.temporalGetCalendarNameOption(r.temporalOptions(call.Argument(0)))
+	return asciiString(formatTemporalYearMonth(ym.date, cal))
+}
+
+func (r *Runtime) temporalPlainYearMonthProto_toJSON(call FunctionCall) Value {
+	return asciiString(formatTemporalYearMonth(r.thisTemporalPlainYearMonth(call.This, "toJSON").date, ""))
+}
+
+func (r *Runtime) temporalPlainYearMonthProto_toLocaleString(call FunctionCall) Value {
+	return asciiString(formatTemporalYearMonth(r.thisTemporalPlainYearMonth(call.This, "toLocaleString").date, ""))
+}
+
+func (r *Runtime) temporalPlainYearMonthProto_toPlainDate(call FunctionCall) Value {
+	ym := r.thisTemporalPlainYearMonth(call.This, "toPlainDate")
+	item, ok := call.Argument(0).(*Object)
+	if !ok {
+		panic(r.NewTypeError("Argument must be an object"))
+	}
+	partial := r.temporalReadFields(item, temporalFieldDay)
+	var f temporalFields
+	f.setYearMonth(ym.date)
+	f.merge(partial)
+	return r.newTemporalPlainDate(r.temporalResolveDate(&f, false), nil)
+}
+
+func (r *Runtime) builtin_newTemporalPlainMonthDay(args []Value, newTarget *Object) *Object {
+	if newTarget == nil {
+		panic(r.needNew("Temporal.PlainMonthDay"))
+	}
+	a := r.temporalArgs(args, 2)
+	if len(args) > 2 {
+		r.temporalCheckCalendar(args[2])
+	}
+	year := float64(temporalReferenceYear)
+	if len(args) > 3 && args[3] != _undefined {
+		year = r.temporalToIntegerWithTruncation(args[3])
+	}
+	if !isValidISODate(year, a[0], a[1]) {
+		panic(r.newError(r.global.RangeError, "Invalid date"))
+	}
+	d := isoDate{year: int(year), month: int(a[0]), day: int(a[1])}
+	if !d.withinLimits() {
+		panic(r.newError(r.global.RangeError, "Date is out of range"))
+	}
+	proto := r.getPrototypeFromCtor(newTarget, r.global.TemporalPlainMonthDay, r.global.TemporalPlainMonthDayPrototype)
+	return r.newTemporalPlainMonthDay(d, proto)
+}
+
+func (r *Runtime) temporalPlainMonthDay_from(call FunctionCall) Value {
+	return r.newTemporalPlainMonthDay(r.toTemporalMonthDay(call.Argument(0), call.Argument(1)), nil)
+}
+
+func (r *Runtime) temporalPlainMonthDayProto_with(call FunctionCall) Value {
+	md := r.thisTemporalPlainMonthDay(call.This, "with")
+	partial := r.temporalReadPartialFields(call.Argument(0), temporalDateFields)
+	var f temporalFields
+	f.setMonthDay(md.date)
+	f.merge(partial)
+	return r.newTemporalPlainMonthDay(r.temporalResolveMonthDay(&f, r.temporalGetOverflow(r.temporalOptions(call.Argument(1)))), nil)
+}
+
+func (r *Runtime) temporalPlainMonthDayProto_equals(call FunctionCall) Value {
+	md := r.thisTemporalPlainMonthDay(call.This, "equals")
+	return r.toBoolean(md.date == r.toTemporalMonthDay(call.Argument(0), _undefined))
+}
+
+// formatTemporalMonthDay formats the month and day, or the full reference date if the calendar is shown.
+func formatTemporalMonthDay(d isoDate, cal string) string {
+	if cal != "" {
+		return d.String() + cal
+	}
+	var b strings.Builder
+	writePadded(&b, d.month, 2)
+	b.WriteByte('-')
+	writePadded(&b, d.day, 2)
+	return b.String()
+}
+
+func (r *Runtime) temporalPlainMonthDayProto_toString(call FunctionCall) Value {
+	md := r.thisTemporalPlainMonthDay(call.This, "toString")
+	cal := r.temporalGetCalendarNameOption(r.temporalOptions(call.Argument(0)))
+	return asciiString(formatTemporalMonthDay(md.date, cal))
+}
+
+func (r *Runtime) temporalPlainMonthDayProto_toJSON(call FunctionCall) Value {
+	return asciiString(formatTemporalMonthDay(r.thisTemporalPlainMonthDay(call.This, "toJSON").date, ""))
+}
+
+func (r *Runtime) temporalPlainMonthDayProto_toLocaleString(call FunctionCall) Value {
+	return asciiString(formatTemporalMonthDay(r.thisTemporalPlainMonthDay(call.This, "toLocaleString").date, ""))
+}
+
+func (r *Runtime) temporalPlainMonthDayProto_toPlainDate(call FunctionCall) Value {
+	md := r.thisTemporalPlainMonthDay(call.This, "toPlainDate")
+	item, ok := call.Argument(0).(*Object)
+	if !ok {
+		panic(r.NewTypeError("Argument must be an object"))
+	}
+	partial := r.temporalReadFields(item, temporalFieldYear)
+	var f temporalFields
+	f.setMonthDay(md.date)
+	f.merge(partial)
+	return r.newTemporalPlainDate(r.temporalResolveDate(&f, false), nil)
+}
+
+func (r *Runtime) createTemporalPlainDateProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("constructor", r.global.TemporalPlainDate, true, false, true)
+	r.putTemporalDateGetters(o, func(this Value, method string) isoDate {
+		return r.thisTemporalPlainDate(this, method).date
+	})
+	o._putProp("with", r.newNativeFunc(r.temporalPlainDateProto_with, nil, "with", nil, 1), true, false, true)
+	o._putProp("add", r.newNativeFunc(r.temporalPlainDateProto_add, nil, "add", nil, 1), true, false, true)
+	o._putProp("subtract", r.newNativeFunc(r.temporalPlainDateProto_subtract, nil, "subtract", nil, 1), true, false, true)
+	o._putProp("until", r.newNativeFunc(r.temporalPlainDateProto_until, nil, "until", nil, 1), true, false, true)
+	o._putProp("since", r.newNativeFunc(r.temporalPlainDateProto_since, nil, "since", nil, 1), true, false, true)
+	o._putProp("equals", r.newNativeFunc(r.temporalPlainDateProto_equals, nil, "equals", nil, 1), true, false, true)
+	o._putProp("toString", r.newNativeFunc(r.temporalPlainDateProto_toString, nil, "toString", nil, 0), true, false, true)
+	o._putProp("toJSON", r.newNativeFunc(r.temporalPlainDateProto_toJSON, nil, "toJSON", nil, 0), true, false, true)
+	o._putProp("toLocaleString", r.newNativeFunc(r.temporalPlainDateProto_toLocaleString, nil, "toLocaleString", nil, 0), true, false, true)
+	o._putProp("valueOf", r.newNativeFunc(r.temporalValueOf, nil, "valueOf", nil, 0), true, false, true)
+	o._putProp("toPlainDateTime", r.newNativeFunc(r.temporalPlainDateProto_toPlainDateTime, nil, "toPlainDateTime", nil, 0), true, false, true)
+	o._putProp("toZonedDateTime", r.newNativeFunc(r.temporalPlainDateProto_toZonedDateTime, nil, "toZonedDateTime", nil, 1), true, false, true)
+	o._putProp("toPlainYearMonth", r.newNativeFunc(r.temporalPlainDateProto_toPlainYearMonth, nil, "toPlainYearMonth", nil, 0), true, false, true)
+	o._putProp("toPlainMonthDay", r.newNativeFunc(r.temporalPlainDateProto_toPlainMonthDay, nil, "toPlainMonthDay", nil, 0), true, false, true)
+	o._putSym(SymToStringTag, valueProp(asciiString("Temporal.PlainDate"), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) createTemporalPlainDate(val *Object) objectImpl {
+	o := r.newNativeConstructOnly(val, r.builtin_newTemporalPlainDate, r.global.TemporalPlainDatePrototype, "PlainDate", 3)
+	o._putProp("from", r.newNativeFunc(r.temporalPlainDate_from, nil, "from", nil, 1), true, false, true)
+	o._putProp("compare", r.newNativeFunc(r.temporalPlainDate_compare, nil, "compare", nil, 2), true, false, true)
+
+	return o
+}
+
+func (r *Runtime) createTemporalPlainTimeProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("constructor", r.global.TemporalPlainTime, true, false, true)
+	r.putTemporalTimeGetters(o, func(this Value, method string) isoTime {
+		return r.thisTemporalPlainTime(this, method).time
+	})
+	o._putProp("with", r.newNativeFunc(r.temporalPlainTimeProto_with, nil, "with", nil, 1), true, false, true)
+	o._putProp("add", r.newNativeFunc(r.temporalPlainTimeProto_add, nil, "add", nil, 1), true, false, true)
+	o._putProp("subtract", r.newNativeFunc(r.temporalPlainTimeProto_subtract, nil, "subtract", nil, 1), true, false, true)
+	o._putProp("until", r.newNativeFunc(r.temporalPlainTimeProto_until, nil, "until", nil, 1), true, false, true)
+	o._putProp("since", r.newNativeFunc(r.temporalPlainTimeProto_since, nil, "since", nil, 1), true, false, true)
+	o._putProp("round", r.newNativeFunc(r.temporalPlainTimeProto_round, nil, "round", nil, 1), true, false, true)
+	o._putProp("equals", r.newNativeFunc(r.temporalPlainTimeProto_equals, nil, "equals", nil, 1), true, false, true)
+	o._putProp("toString", r.newNativeFunc(r.temporalPlainTimeProto_toString, nil, "toString", nil, 0), true, false, true)
+	o._putProp("toJSON", r.newNativeFunc(r.temporalPlainTimeProto_toJSON, nil, "toJSON", nil, 0), true, false, true)
+	o._putProp("toLocaleString", r.newNativeFunc(r.temporalPlainTimeProto_toLocaleString, nil, "toLocaleString", nil, 0), true, false, true)
+	o._putProp("valueOf", r.newNativeFunc(r.temporalValueOf, nil, "valueOf", nil, 0), true, false, true)
+	o._putSym(SymToStringTag, valueProp(asciiString("Temporal.PlainTime"), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) createTemporalPlainTime(val *Object) objectImpl {
+	o := r.newNativeConstructOnly(val, r.builtin_newTemporalPlainTime, r.global.TemporalPlainTimePrototype, "PlainTime", 0)
+	o._putProp("from", r.newNativeFunc(r.temporalPlainTime_from, nil, "from", nil, 1), true, false, true)
+	o._putProp("compare", r.newNativeFunc(r.temporalPlainTime_compare, nil, "compare", nil, 2), true, false, true)
+
+	return o
+}
+
+func (r *Runtime) createTemporalPlainDateTimeProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("constructor", r.global.TemporalPlainDateTime, true, false, true)
+	r.putTemporalDateGetters(o, func(this Value, method string) isoDate {
+		return r.thisTemporalPlainDateTime(this, method).date
+	})
+	r.putTemporalTimeGetters(o, func(this Value, method string) isoTime {
+		return r.thisTemporalPlainDateTime(this, method).time
+	})
+	o._putProp("with", r.newNativeFunc(r.temporalPlainDateTimeProto_with, nil, "with", nil, 1), true, false, true)
+	o._putProp("withPlainTime", r.newNativeFunc(r.temporalPlainDateTimeProto_withPlainTime, nil, "withPlainTime", nil, 0), true, false, true)
+	o._putProp("add", r.newNativeFunc(r.temporalPlainDateTimeProto_add, nil, "add", nil, 1), true, false, true)
+	o._putProp("subtract", r.newNativeFunc(r.temporalPlainDateTimeProto_subtract, nil, "subtract", nil, 1), true, false, true)
+	o._putProp("until", r.newNativeFunc(r.temporalPlainDateTimeProto_until, nil, "until", nil, 1), true, false, true)
+	o._putProp("since", r.newNativeFunc(r.temporalPlainDateTimeProto_since, nil, "since", nil, 1), true, false, true)
+	o._putProp("round", r.newNativeFunc(r.temporalPlainDateTimeProto_round, nil, "round", nil, 1), true, false, true)
+	o._putProp("equals", r.newNativeFunc(r.temporalPlainDateTimeProto_equals, nil, "equals", nil, 1), true, false, true)
+	o._putProp("toString", r.newNativeFunc(r.temporalPlainDateTimeProto_toString, nil, "toString", nil, 0), true, false, true)
+	o._putProp("toJSON", r.newNativeFunc(r.temporalPlainDateTimeProto_toJSON, nil, "toJSON", nil, 0), true, false, true)
+	o._putProp("toLocaleString", r.newNativeFunc(r.temporalPlainDateTimeProto_toLocaleString, nil, "toLocaleString", nil, 0), true, false, true)
+	o._putProp("valueOf", r.newNativeFunc(r.temporalValueOf, nil, "valueOf", nil, 0), true, false, true)
+	o._putProp("toPlainDate", r.newNativeFunc(r.temporalPlainDateTimeProto_toPlainDate, nil, "toPlainDate", nil, 0), true, false, true)
+	o._putProp("toPlainTime", r.newNativeFunc(r.temporalPlainDateTimeProto_toPlainTime, nil, "toPlainTime", nil, 0), true, false, true)
+	o._putProp("toZonedDateTime", r.newNativeFunc(r.temporalPlainDateTimeProto_toZonedDateTime, nil, "toZonedDateTime", nil, 1), true, false, true)
+	o._putSym(SymToStringTag, valueProp(asciiString("Temporal.PlainDateTime"), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) createTemporalPlainDateTime(val *Object) objectImpl {
+	o := r.newNativeConstructOnly(val, r.builtin_newTemporalPlainDateTime, r.global.TemporalPlainDateTimePrototype, "PlainDateTime", 3)
+	o._putProp("from", r.newNativeFunc(r.temporalPlainDateTime_from, nil, "from", nil, 1), true, false, true)
+	o._putProp("compare", r.newNativeFunc(r.temporalPlainDateTime_compare, nil, "compare", nil, 2), true, false, true)
+
+	return o
+}
+
+func (r *Runtime) createTemporalPlainYearMonthProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("constructor", r.global.TemporalPlainYearMonth, true, false, true)
+	r.putTemporalDateGetters(o, func(this Value, method string) isoDate {
+		return r.thisTemporalPlainYearMonth(this, method).date
+	}, "calendarId", "era", "eraYear", "year", "month", "monthCode", "daysInMonth", "daysInYear", "monthsInYear", "inLeapYear")
+	o._putProp("with", r.newNativeFunc(r.temporalPlainYearMonthProto_with, nil, "with", nil, 1), true, false, true)
+	o._putProp("add", r.newNativeFunc(r.temporalPlainYearMonthProto_add, nil, "add", nil, 1), true, false, true)
+	o._putProp("subtract", r.newNativeFunc(r.temporalPlainYearMonthProto_subtract, nil, "subtract", nil, 1), true, false, true)
+	o._putProp("until", r.newNativeFunc(r.temporalPlainYearMonthProto_until, nil, "until", nil, 1), true, false, true)
+	o._putProp("since", r.newNativeFunc(r.temporalPlainYearMonthProto_since, nil, "since", nil, 1), true, false, true)
+	o._putProp("equals", r.newNativeFunc(r.temporalPlainYearMonthProto_equals, nil, "equals", nil, 1), true, false, true)
+	o._putProp("toString", r.newNativeFunc(r.temporalPlainYearMonthProto_toString, nil, "toString", nil, 0), true, false, true)
+	o._putProp("toJSON", r.newNativeFunc(r.temporalPlainYearMonthProto_toJSON, nil, "toJSON", nil, 0), true, false, true)
+	o._putProp("toLocaleString", r.newNativeFunc(r.temporalPlainYearMonthProto_toLocaleString, nil, "toLocaleString", nil, 0), true, false, true)
+	o._putProp("valueOf", r.newNativeFunc(r.temporalValueOf, nil, "valueOf", nil, 0), true, false, true)
+	o._putProp("toPlainDate", r.newNativeFunc(r.temporalPlainYearMonthProto_toPlainDate, nil, "toPlainDate", nil, 1), true, false, true)
+	o._putSym(SymToStringTag, valueProp(asciiString("Temporal.PlainYearMonth"), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) createTemporalPlainYearMonth(val *Object) objectImpl {
+	o := r.newNativeConstructOnly(val, r.builtin_newTemporalPlainYearMonth, r.global.TemporalPlainYearMonthPrototype, "PlainYearMonth", 2)
+	o._putProp("from", r.newNativeFunc(r.temporalPlainYearMonth_from, nil, "from", nil, 1), true, false, true)
+	o._putProp("compare", r.newNativeFunc(r.temporalPlainYearMonth_compare, nil, "compare", nil, 2), true, false, true)
+
+	return o
+}
+
+func (r *Runtime) createTemporalPlainMonthDayProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("constructor", r.global.TemporalPlainMonthDay, true, false, true)
+	r.putTemporalDateGetters(o, func(this Value, method string) isoDate {
+		return r.thisTemporalPlainMonthDay(this, method).date
+	}, "calendarId", "monthCode", "day")
+	o._putProp("with", r.newNativeFunc(r.temporalPlainMonthDayProto_with, nil, "with", nil, 1), true, false, true)
+	o._putProp("equals", r.newNativeFunc(r.temporalPlainMonthDayProto_equals, nil, "equals", nil, 1), true, false, true)
+	o._putProp("toString", r.newNativeFunc(r.temporalPlainMonthDayProto_toString, nil, "toString", nil, 0), true, false, true)
+	o._putProp("toJSON", r.newNativeFunc(r.temporalPlainMonthDayProto_toJSON, nil, "toJSON", nil, 0), true, false, true)
+	o._putProp("toLocaleString", r.newNativeFunc(r.temporalPlainMonthDayProto_toLocaleString, nil, "toLocaleString", nil, 0), true, false, true)
+	o._putProp("valueOf", r.newNativeFunc(r.temporalValueOf, nil, "valueOf", nil, 0), true, false, true)
+	o._putProp("toPlainDate", r.newNativeFunc(r.temporalPlainMonthDayProto_toPlainDate, nil, "toPlainDate", nil, 1), true, false, true)
+	o._putSym(SymToStringTag, valueProp(asciiString("Temporal.PlainMonthDay"), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) createTemporalPlainMonthDay(val *Object) objectImpl {
+	o := r.newNativeConstructOnly(val, r.builtin_newTemporalPlainMonthDay, r.global.TemporalPlainMonthDayPrototype, "PlainMonthDay", 2)
+	o._putProp("from", r.newNativeFunc(r.temporalPlainMonthDay_from, nil, "from", nil, 1), true, false, true)
+
+	return o
+}
@@ -0,0 +1,234 @@
+package goja
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTemporalDuration(t *testing.T) {
+	const SCRIPT = `
+	const d = new Temporal.Duration(1, 2, 0, 3, 4, 5, 6, 7, 8, 9);
+	assert.sameValue(d.toString(), "P1Y2M3DT4H5M6.007008009S", "toString");
+	assert.sameValue(d.years, 1, "years");
+	assert.sameValue(d.nanoseconds, 9, "nanoseconds");
+	assert.sameValue(d.sign, 1, "sign");
+	assert.sameValue(d.negated().toString(), "-P1Y2M3DT4H5M6.007008009S", "negated");
+	assert.sameValue(new Temporal.Duration().toString(), "PT0S", "blank toString");
+	assert(new Temporal.Duration().blank, "blank");
+
+	const p = Temporal.Duration.from("PT1H30M");
+	assert.sameValue(p.minutes, 30, "from string");
+	assert.sameValue(Temporal.Duration.from({hours: 2}).add({minutes: 90}).toString(), "PT3H30M", "add");
+	assert.sameValue(Temporal.Duration.from("PT90M").round({largestUnit: "hour"}).toString(), "PT1H30M", "round");
+	assert.sameValue(Temporal.Duration.from("PT1H30M").total("minute"), 90, "total");
+	assert.sameValue(Temporal.Duration.compare("PT1H", "PT60M"), 0, "compare");
+	assert.sameValue(Temporal.Duration.from("PT1.5S").toString({smallestUnit: "second"}), "PT1S", "toString precision");
+
+	assert.throws(RangeError, () => new Temporal.Duration(1, -1), "mixed signs");
+	assert.throws(RangeError, () => Temporal.Duration.from("P1Y").total("day"), "relativeTo");
+	assert.throws(TypeError, () => Temporal.Duration.prototype.toString.call({}), "receiver");
+	assert.throws(TypeError, () => d + 1, "valueOf");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestTemporalInstant(t *testing.T) {
+	const SCRIPT = `
+	const i = Temporal.Instant.from("2020-01-01T00:00:00+01:00");
+	assert.sameValue(i.toString(), "2019-12-31T23:00:00Z", "toString");
+	assert.sameValue(i.epochMilliseconds, 1577833200000, "epochMilliseconds");
+	assert.sameValue(i.epochNanoseconds, 1577833200000000000n, "epochNanoseconds");
+	assert.sameValue(Temporal.Instant.fromEpochMilliseconds(1500).toString(), "1970-01-01T00:00:01.5Z", "fromEpochMilliseconds");
+
+	const j = i.add({hours: 1, nanoseconds: 1});
+	assert.sameValue(j.toString(), "2020-01-01T00:00:00.000000001Z", "add");
+	assert.sameValue(i.until(j).toString(), "PT3600.000000001S", "until");
+	assert.sameValue(j.since(i, {largestUnit: "hour", smallestUnit: "minute"}).toString(), "PT1H", "since");
+	assert.sameValue(j.round("second").toString(), "2020-01-01T00:00:00Z", "round");
+	assert.sameValue(i.toString({timeZone: "+05:30"}), "2020-01-01T04:30:00+05:30", "toString with time zone");
+	assert.sameValue(Temporal.Instant.compare(i, j), -1, "compare");
+	assert(i.equals("2019-12-31T23:00Z"), "equals");
+	assert.sameValue(JSON.stringify({i}), '{"i":"2019-12-31T23:00:00Z"}', "toJSON");
+
+	assert.throws(RangeError, () => Temporal.Instant.from("2020-01-01T00:00:00"), "no offset");
+	assert.throws(RangeError, () => i.add({days: 1}), "calendar units");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestTemporalPlainDate(t *testing.T) {
+	const SCRIPT = `
+	const d = Temporal.PlainDate.from("2024-01-31");
+	assert.sameValue(d.add({months: 1}).toString(), "2024-02-29", "constrain");
+	assert.throws(RangeError, () => d.add({months: 1}, {overflow: "reject"}), "reject");
+	assert.sameValue(d.dayOfWeek, 3, "dayOfWeek");
+	assert.sameValue(d.dayOfYear, 31, "dayOfYear");
+	assert.sameValue(d.weekOfYear, 5, "weekOfYear");
+	assert.sameValue(d.inLeapYear, true, "inLeapYear");
+	assert.sameValue(d.monthCode, "M01", "monthCode");
+	assert.sameValue(d.with({day: 1}).toString(), "2024-01-01", "with");
+	assert.sameValue(d.with({monthCode: "M02"}).toString(), "2024-02-29", "with monthCode");
+	assert.sameValue(d.until("2025-03-01", {largestUnit: "year"}).toString(), "P1Y1M1D", "until");
+	assert.sameValue(d.until("2025-03-01", {smallestUnit: "month", roundingMode: "halfExpand"}).toString(), "P13M", "until rounded");
+	assert.sameValue(d.since("2024-01-01").toString(), "P30D", "since");
+	assert.sameValue(Temporal.PlainDate.compare("2024-01-01", {year: 2024, month: 1, day: 2}), -1, "compare");
+	assert.sameValue(d.toString({calendarName: "always"}), "2024-01-31[u-ca=iso8601]", "calendarName");
+	assert.sameValue(d.toPlainDateTime("12:30").toString(), "2024-01-31T12:30:00", "toPlainDateTime");
+	assert.sameValue(Temporal.PlainDate.from("+010000-01-01").year, 10000, "extended year");
+	assert.sameValue(new Temporal.PlainDate(-1, 1, 1).toString(), "-000001-01-01", "negative year");
+	assert.throws(RangeError, () => new Temporal.PlainDate(2023, 2, 29), "invalid date");
+	assert.throws(RangeError, () => new Temporal.PlainDate(2023, 1, 1, "gregory"), "calendar");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestTemporalPlainTime(t *testing.T) {
+	const SCRIPT = `
+	const t = new Temporal.PlainTime(23, 59, 30, 500);
+	assert.sameValue(t.toString(), "23:59:30.5", "toString");
+	assert.sameValue(t.add({seconds: 30}).toString(), "00:00:00.5", "add wraps");
+	assert.sameValue(t.round({smallestUnit: "minute"}).toString(), "00:00:00", "round");
+	assert.sameValue(t.toString({fractionalSecondDigits: 3}), "23:59:30.500", "fractionalSecondDigits");
+	assert.sameValue(t.until("01:00").toString(), "-PT22H59M30.5S", "until");
+	assert.sameValue(Temporal.PlainTime.from({hour: 25}).hour, 23, "constrain");
+	assert.throws(RangeError, () => Temporal.PlainTime.from({hour: 25}, {overflow: "reject"}), "reject");
+	assert(Temporal.PlainTime.from("T12:00").equals("12:00:00"), "equals");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestTemporalPlainDateTime(t *testing.T) {
+	const SCRIPT = `
+	const dt = Temporal.PlainDateTime.from("2024-03-10T02:30:00");
+	assert.sameValue(dt.add({hours: 22}).toString(), "2024-03-11T00:30:00", "add");
+	assert.sameValue(dt.until("2024-03-11T03:00", {largestUnit: "day"}).toString(), "P1DT30M", "until");
+	assert.sameValue(dt.since("2024-03-09T03:00").toString(), "PT23H30M", "since");
+	assert.sameValue(dt.round({smallestUnit: "hour", roundingMode: "halfEven"}).toString(), "2024-03-10T02:00:00", "round halfEven");
+	assert.sameValue(dt.round({smallestUnit: "day"}).toString(), "2024-03-10T00:00:00", "round day");
+	assert.sameValue(dt.withPlainTime().toString(), "2024-03-10T00:00:00", "withPlainTime");
+	assert.sameValue(dt.with({minute: 45}).toString(), "2024-03-10T02:45:00", "with");
+	assert.sameValue(dt.toPlainDate().toString(), "2024-03-10", "toPlainDate");
+	assert.sameValue(dt.toZonedDateTime("America/New_York").toString(), "2024-03-10T03:30:00-04:00[America/New_York]", "toZonedDateTime gap");
+	assert.throws(RangeError, () => dt.toZonedDateTime("America/New_York", {disambiguation: "reject"}), "reject");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestTemporalPlainYearMonth(t *testing.T) {
+	const SCRIPT = `
+	const ym = Temporal.PlainYearMonth.from("2024-01");
+	assert.sameValue(ym.toString(), "2024-01", "toString");
+	assert.sameValue(ym.toString({calendarName: "always"}), "2024-01-01[u-ca=iso8601]", "calendarName");
+	assert.sameValue(ym.daysInMonth, 31, "daysInMonth");
+	assert.sameValue(ym.inLeapYear, true, "inLeapYear");
+	assert.sameValue(ym.monthCode, "M01", "monthCode");
+	assert.sameValue(ym.day, undefined, "no day");
+	assert.sameValue(ym.add({months: 13}).toString(), "2025-02", "add");
+	assert.sameValue(ym.subtract({days: 1}).toString(), "2024-01", "subtract days from the end of month");
+	assert.sameValue(ym.subtract({days: 31}).toString(), "2023-12", "subtract days");
+	assert.sameValue(ym.add({days: 30}).toString(), "2024-01", "add days within month");
+	assert.sameValue(ym.until("2025-03").toString(), "P1Y2M", "until");
+	assert.sameValue(ym.until("2025-03", {largestUnit: "month"}).toString(), "P14M", "until months");
+	assert.sameValue(ym.since("2023-06", {smallestUnit: "year", roundingMode: "halfExpand"}).toString(), "P1Y", "since rounded");
+	assert.sameValue(ym.with({month: 2}).toPlainDate({day: 30}).toString(), "2024-02-29", "toPlainDate constrains");
+	assert.sameValue(Temporal.PlainYearMonth.from("2024-05-20T10:00").toString(), "2024-05", "from date-time");
+	assert.sameValue(Temporal.PlainYearMonth.from({year: 2024, monthCode: "M12"}).month, 12, "from fields");
+	assert.sameValue(Temporal.PlainDate.from("2024-05-20").toPlainYearMonth().toString(), "2024-05", "PlainDate.toPlainYearMonth");
+	assert.sameValue(Temporal.PlainYearMonth.compare("2024-02", {year: 2024, month: 1}), 1, "compare");
+	assert(new Temporal.PlainYearMonth(2024, 1).equals(ym), "equals");
+	assert(!new Temporal.PlainYearMonth(2024, 1, "iso8601", 15).equals(ym), "equals reference day");
+	assert.sameValue(new Temporal.PlainYearMonth(-271821, 4).toString(), "-271821-04", "min");
+	assert.throws(RangeError, () => new Temporal.PlainYearMonth(-271821, 3), "out of range");
+	assert.throws(RangeError, () => Temporal.PlainYearMonth.from({year: 2024, month: 13}, {overflow: "reject"}), "reject");
+	assert.throws(RangeError, () => Temporal.PlainYearMonth.from("2024-05Z"), "Z");
+	assert.throws(TypeError, () => ym.toPlainDate({}), "day required");
+	assert.throws(TypeError, () => Temporal.PlainYearMonth.prototype.toString.call(Temporal.PlainDate.from("2024-01-01")), "receiver");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestTemporalPlainMonthDay(t *testing.T) {
+	const SCRIPT = `
+	const md = Temporal.PlainMonthDay.from("--02-29");
+	assert.sameValue(md.toString(), "02-29", "toString");
+	assert.sameValue(md.toString({calendarName: "always"}), "1972-02-29[u-ca=iso8601]", "calendarName");
+	assert.sameValue(md.monthCode, "M02", "monthCode");
+	assert.sameValue(md.day, 29, "day");
+	assert.sameValue(md.month, undefined, "no month");
+	assert.sameValue(md.toPlainDate({year: 2023}).toString(), "2023-02-28", "toPlainDate constrains");
+	assert.sameValue(md.toPlainDate({year: 2024}).toString(), "2024-02-29", "toPlainDate");
+	assert.sameValue(md.with({monthCode: "M04"}).toString(), "04-29", "with monthCode");
+	assert.sameValue(md.with({day: 31}).toString(), "02-29", "with constrains");
+	assert.sameValue(Temporal.PlainMonthDay.from("1205").toString(), "12-05", "basic format");
+	assert.sameValue(Temporal.PlainMonthDay.from("2023-07-14T12:00").toString(), "07-14", "from date-time");
+	assert.sameValue(Temporal.PlainMonthDay.from({month: 7, day: 14}).toString(), "07-14", "from fields");
+	assert.sameValue(Temporal.PlainMonthDay.from({year: 2023, month: 2, day: 29}).toString(), "02-28", "year constrains");
+	assert.sameValue(Temporal.PlainDate.from("2024-05-20").toPlainMonthDay().toString(), "05-20", "PlainDate.toPlainMonthDay");
+	assert(new Temporal.PlainMonthDay(2, 29).equals(md), "equals");
+	assert(!new Temporal.PlainMonthDay(2, 29, "iso8601", 2000).equals(md), "equals reference year");
+	assert.sameValue(JSON.stringify({md}), '{"md":"02-29"}', "toJSON");
+	assert.throws(RangeError, () => Temporal.PlainMonthDay.from({year: 2023, month: 2, day: 29}, {overflow: "reject"}), "reject");
+	assert.throws(RangeError, () => new Temporal.PlainMonthDay(2, 30), "invalid");
+	assert.throws(RangeError, () => Temporal.PlainMonthDay.from("02-30"), "invalid string");
+	assert.throws(TypeError, () => Temporal.PlainMonthDay.from({month: 2}), "day required");
+	assert.throws(TypeError, () => md.toPlainDate({}), "year required");
+	assert.throws(TypeError, () => md < md, "valueOf");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestTemporalZonedDateTime(t *testing.T) {
+	const SCRIPT = `
+	const z = Temporal.ZonedDateTime.from("2024-03-30T12:00[Europe/Berlin]");
+	assert.sameValue(z.offset, "+01:00", "offset");
+	assert.sameValue(z.hoursInDay, 24, "hoursInDay");
+	const next = z.add({days: 1});
+	assert.sameValue(next.toString(), "2024-03-31T12:00:00+02:00[Europe/Berlin]", "add day keeps wall-clock time");
+	assert.sameValue(next.hoursInDay, 23, "hoursInDay DST");
+	assert.sameValue(z.add({hours: 24}).toString(), "2024-03-31T13:00:00+02:00[Europe/Berlin]", "add hours is exact");
+	assert.sameValue(z.until(next).toString(), "PT23H", "until");
+	assert.sameValue(z.until(next, {largestUnit: "day"}).toString(), "P1D", "until days");
+	assert.sameValue(next.since(z, {largestUnit: "day"}).toString(), "P1D", "since days");
+	assert.sameValue(z.with({hour: 2, day: 31}).toString(), "2024-03-31T03:00:00+02:00[Europe/Berlin]", "with into gap");
+	assert.sameValue(z.withTimeZone("UTC").toString(), "2024-03-30T11:00:00+00:00[UTC]", "withTimeZone");
+	assert.sameValue(z.round("day").toString(), "2024-03-31T00:00:00+01:00[Europe/Berlin]", "round day");
+	assert.sameValue(z.startOfDay().toString({timeZoneName: "never", offset: "never"}), "2024-03-30T00:00:00", "startOfDay");
+	assert.sameValue(z.toInstant().toString(), "2024-03-30T11:00:00Z", "toInstant");
+	assert.sameValue(z.epochMilliseconds, Date.UTC(2024, 2, 30, 11), "epochMilliseconds");
+
+	const ambiguous = "2024-10-27T02:30[Europe/Berlin]";
+	assert.sameValue(Temporal.ZonedDateTime.from(ambiguous).offset, "+02:00", "compatible");
+	assert.sameValue(Temporal.ZonedDateTime.from(ambiguous, {disambiguation: "later"}).offset, "+01:00", "later");
+	assert.sameValue(Temporal.ZonedDateTime.from("2024-10-27T02:30+01:00[Europe/Berlin]").offset, "+01:00", "offset selects");
+	assert.throws(RangeError, () => Temporal.ZonedDateTime.from("2024-06-01T12:00+01:00[Europe/Berlin]"), "offset mismatch");
+	assert.sameValue(Temporal.ZonedDateTime.from("2024-06-01T12:00+01:00[Europe/Berlin]", {offset: "use"}).hour, 13, "offset use");
+	assert.throws(TypeError, () => Temporal.ZonedDateTime.from({year: 2024, month: 1, day: 1}), "timeZone required");
+
+	const zdt = new Temporal.ZonedDateTime(0n, "Europe/Berlin");
+	assert.sameValue(zdt.toString(), "1970-01-01T01:00:00+01:00[Europe/Berlin]", "constructor");
+	assert(zdt.equals("1970-01-01T00:00Z[Europe/Berlin]"), "equals");
+	assert(!zdt.equals("1970-01-01T00:00Z[UTC]"), "equals different time zone");
+	assert.sameValue(Temporal.ZonedDateTime.compare(zdt, z), -1, "compare");
+	assert.throws(RangeError, () => zdt.until(z.withTimeZone("UTC"), {largestUnit: "day"}), "different time zones");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestTemporalNow(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue(Temporal.Now.instant().toString(), "2021-05-06T07:08:09.01Z", "instant");
+	assert.sameValue(Temporal.Now.plainDateTimeISO("+02:00").toString(), "2021-05-06T09:08:09.01", "plainDateTimeISO");
+	assert.sameValue(Temporal.Now.zonedDateTimeISO("UTC").toString(), "2021-05-06T07:08:09.01+00:00[UTC]", "zonedDateTimeISO");
+	assert.sameValue(Temporal.Now.plainDateISO("-12:00").toString(), "2021-05-05", "plainDateISO");
+	assert.sameValue(typeof Temporal.Now.timeZoneId(), "string", "timeZoneId");
+	assert.sameValue(Object.prototype.toString.call(Temporal), "[object Temporal]", "toStringTag");
+	`
+	vm := New()
+	vm.SetTimeSource(func() time.Time {
+		return time.Date(2021, 5, 6, 7, 8, 9, 10e6, time.UTC)
+	})
+	vm.RunProgram(testLib())
+	if _, err := vm.RunString(SCRIPT); err != nil {
+		t.Fatal(err)
+	}
+}
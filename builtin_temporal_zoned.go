@@ -0,0 +1,467 @@
+package goja
+
+import (
+	"math/big"
+	"strings"
+)
+
+type temporalZonedDateTimeObject struct {
+	baseObject
+	ns *big.Int
+	tz *temporalTimeZone
+}
+
+// The ways the offset of a property bag or a string is used to resolve the exact time
+// (see https://tc39.es/proposal-temporal/#sec-temporal-interpretisodatetimeoffset).
+const (
+	temporalOffsetWall = iota
+	temporalOffsetExact
+	temporalOffsetOption
+)
+
+func (r *Runtime) newTemporalZonedDateTime(ns *big.Int, tz *temporalTimeZone, proto *Object) *Object {
+	if !isValidEpochNs(ns) {
+		panic(r.newError(r.global.RangeError, "ZonedDateTime is out of range"))
+	}
+	o := &temporalZonedDateTimeObject{ns: ns, tz: tz}
+	return r.initTemporalObject(o, &o.baseObject, proto, r.global.TemporalZonedDateTimePrototype)
+}
+
+func (r *Runtime) thisTemporalZonedDateTime(this Value, method string) *temporalZonedDateTimeObject {
+	if o, ok := this.(*Object); ok {
+		if z, ok := o.self.(*temporalZonedDateTimeObject); ok {
+			return z
+		}
+	}
+	panic(r.temporalTypeError("ZonedDateTime", method, this))
+}
+
+func (z *temporalZonedDateTimeObject) dateTime() (isoDate, isoTime) {
+	return z.tz.isoDateTimeAt(z.ns)
+}
+
+func (tz *temporalTimeZone) equals(other *temporalTimeZone) bool {
+	return tz == other || strings.EqualFold(tz.id, other.id)
+}
+
+func (r *Runtime) temporalGetOffsetOption(opts *Object, def string) string {
+	return r.temporalGetOption(opts, "offset", []string{"prefer", "use", "ignore", "reject"}, def)
+}
+
+// temporalInterpretOffset converts a wall-clock time with an optional UTC offset into an exact time. If matchMinute
+// is true the offset only has to match the time zone offset rounded to minutes.
+func (r *Runtime) temporalInterpretOffset(d isoDate, t isoTime, behaviour int, offsetNs int64, tz *temporalTimeZone,
+	disambiguation, offsetOption string, matchMinute bool) *big.Int {
+	if !isoDateTimeWithinLimits(d, t) {
+		panic(r.newError(r.global.RangeError, "Date-time is out of range"))
+	}
+	if behaviour == temporalOffsetWall || behaviour == temporalOffsetOption && offsetOption == "ignore" {
+		ns := tz.epochNsFor(d, t, disambiguation)
+		if ns == nil {
+			panic(r.newError(r.global.RangeError, "The date-time is ambiguous or does not exist in the time zone %s", tz.id))
+		}
+		return ns
+	}
+	if behaviour == temporalOffsetExact || offsetOption == "use" {
+		ns := isoDateTimeToEpochNs(d, t)
+		ns.Sub(ns, big.NewInt(offsetNs))
+		if !isValidEpochNs(ns) {
+			panic(r.newError(r.global.RangeError, "ZonedDateTime is out of range"))
+		}
+		return ns
+	}
+	for _, c := range tz.possibleEpochNs(d, t) {
+		off := tz.offsetNsAt(c)
+		if off == offsetNs || matchMinute && floorDivInt64(off+30e9, 60e9)*60e9 == offsetNs {
+			return c
+		}
+	}
+	if offsetOption == "reject" {
+		panic(r.newError(r.global.RangeError, "Offset %s is invalid for the date-time in the time zone %s", formatTemporalOffset(offsetNs, true), tz.id))
+	}
+	ns := tz.epochNsFor(d, t, disambiguation)
+	if ns == nil {
+		panic(r.newError(r.global.RangeError, "The date-time is ambiguous or does not exist in the time zone %s", tz.id))
+	}
+	return ns
+}
+
+// toTemporalZonedDateTime implements https://tc39.es/proposal-temporal/#sec-temporal-totemporalzoneddatetime
+func (r *Runtime) toTemporalZonedDateTime(v Value, options Value) (*big.Int, *temporalTimeZone) {
+	if o, ok := v.(*Object); ok {
+		if z, ok := o.self.(*temporalZonedDateTimeObject); ok {
+			opts := r.temporalOptions(options)
+			r.temporalGetDisambiguation(opts)
+			r.temporalGetOffsetOption(opts, "reject")
+			r.temporalGetOverflow(opts)
+			return z.ns, z.tz
+		}
+		f := r.temporalReadFields(o, temporalFieldCalendar|temporalDateFields|temporalTimeFields|temporalFieldOffset|temporalFieldTimeZone)
+		if f.has&temporalFieldTimeZone == 0 {
+			panic(r.NewTypeError("timeZone is required"))
+		}
+		opts := r.temporalOptions(options)
+		disambiguation := r.temporalGetDisambiguation(opts)
+		offsetOption := r.temporalGetOffsetOption(opts, "reject")
+		reject := r.temporalGetOverflow(opts)
+		d, t := r.temporalResolveDate(f, reject), r.temporalResolveTime(f, reject)
+		behaviour := temporalOffsetWall
+		if f.has&temporalFieldOffset != 0 {
+			behaviour = temporalOffsetOption
+		}
+		return r.temporalInterpretOffset(d, t, behaviour, f.offsetNs, f.tz, disambiguation, offsetOption, false), f.tz
+	}
+	s, ok := v.(valueString)
+	if !ok {
+		panic(r.NewTypeError("Cannot convert %s to a Temporal.ZonedDateTime", v))
+	}
+	p, ok := parseTemporalDateTimeString(s.String())
+	if !ok || p.tz == "" {
+		panic(r.newError(r.global.RangeError, "Invalid zoned date-time string: %s", s.String()))
+	}
+	tz, ok := parseTemporalTimeZoneId(p.tz)
+	if !ok {
+		panic(r.newError(r.global.RangeError, "Invalid time zone: %s", p.tz))
+	}
+	r.temporalCheckParsedCalendar(&p)
+	opts := r.temporalOptions(options)
+	disambiguation := r.temporalGetDisambiguation(opts)
+	offsetOption := r.temporalGetOffsetOption(opts, "reject")
+	r.temporalGetOverflow(opts)
+	behaviour := temporalOffsetWall
+	switch {
+	case p.z:
+		behaviour = temporalOffsetExact
+	case p.hasOffset:
+		behaviour = temporalOffsetOption
+	case !p.hasTime:
+		if !p.date.withinLimits() {
+			panic(r.newError(r.global.RangeError, "Date is out of range"))
+		}
+		return temporalStartOfDay(tz, p.date), tz
+	}
+	return r.temporalInterpretOffset(p.date, p.time, behaviour, p.offsetNs, tz, disambiguation, offsetOption, !p.offsetPrecise), tz
+}
+
+func (r *Runtime) builtin_newTemporalZonedDateTime(args []Value, newTarget *Object) *Object {
+	if newTarget == nil {
+		panic(r.needNew("Temporal.ZonedDateTime"))
+	}
+	var arg Value = _undefined
+	if len(args) > 0 {
+		arg = args[0]
+	}
+	ns := new(big.Int).Set((*big.Int)(toBigInt(arg)))
+	if !isValidEpochNs(ns) {
+		panic(r.newError(r.global.RangeError, "ZonedDateTime is out of range"))
+	}
+	var tzArg Value = _undefined
+	if len(args) > 1 {
+		tzArg = args[1]
+	}
+	s, ok := tzArg.(valueString)
+	if !ok {
+		panic(r.NewTypeError("Time zone must be a string"))
+	}
+	tz, ok := parseTemporalTimeZoneId(s.String())
+	if !ok {
+		panic(r.newError(r.global.RangeError, "Invalid time zone: %s", s.String()))
+	}
+	if len(args) > 2 {
+		r.temporalCheckCalendar(args[2])
+	}
+	proto := r.getPrototypeFromCtor(newTarget, r.global.TemporalZonedDateTime, r.global.TemporalZonedDateTimePrototype)
+	return r.newTemporalZonedDateTime(ns, tz, proto)
+}
+
+func (r *Runtime) temporalZonedDateTime_from(call FunctionCall) Value {
+	ns, tz := r.toTemporalZonedDateTime(call.Argument(0), call.Argument(1))
+	return r.newTemporalZonedDateTime(ns, tz, nil)
+}
+
+func (r *Runtime) temporalZonedDateTime_compare(call FunctionCall) Value {
+	one, _ := r.toTemporalZonedDateTime(call.Argument(0), _undefined)
+	two, _ := r.toTemporalZonedDateTime(call.Argument(1), _undefined)
+	return intToValue(int64(one.Cmp(two)))
+}
+
+func (r *Runtime) temporalZonedDateTimeProto_getTimeZoneId(call FunctionCall) Value {
+	return newStringValue(r.thisTemporalZonedDateTime(call.This, "timeZoneId").tz.id)
+}
+
+func (r *Runtime) temporalZonedDateTimeProto_getEpochMilliseconds(call FunctionCall) Value {
+	return intToValue(epochMsFromNs(r.thisTemporalZonedDateTime(call.This, "epochMilliseconds").ns))
+}
+
+func (r *Runtime) temporalZonedDateTimeProto_getEpochNanoseconds(call FunctionCall) Value {
+	z := r.thisTemporalZonedDateTime(call.This, "epochNanoseconds")
+	return (*valueBigInt)(new(big.Int).Set(z.ns))
+}
+
+func (r *Runtime) temporalZonedDateTimeProto_getOffsetNanoseconds(call FunctionCall) Value {
+	z := r.thisTemporalZonedDateTime(call.This, "offsetNanoseconds")
+	return intToValue(z.tz.offsetNsAt(z.ns))
+}
+
+func (r *Runtime) temporalZonedDateTimeProto_getOffset(call FunctionCall) Value {
+	z := r.thisTemporalZonedDateTime(call.This, "offset")
+	return asciiString(formatTemporalOffset(z.tz.offsetNsAt(z.ns), true))
+}
+
+func (r *Runtime) temporalZonedDateTimeProto_getHoursInDay(call FunctionCall) Value {
+	z := r.thisTemporalZonedDateTime(call.This, "hoursInDay")
+	d, _ := z.dateTime()
+	start := temporalStartOfDay(z.tz, d)
+	end := temporalStartOfDay(z.tz, d.addDays(1))
+	f, _ := new(big.Rat).SetFrac(end.Sub(end, start), big.NewInt(3600e9)).Float64()
+	return floatToValue(f)
+}
+
+func (r *Runtime) temporalZonedDateTimeProto_with(call FunctionCall) Value {
+	z := r.thisTemporalZonedDateTime(call.This, "with")
+	partial := r.temporalReadPartialFields(call.Argument(0), temporalDateFields|temporalTimeFields|temporalFieldOffset)
+	d, t := z.dateTime()
+	var f temporalFields
+	f.setDate(d)
+	f.setTime(t)
+	f.has |= temporalFieldOffset
+	f.offsetNs = z.tz.offsetNsAt(z.ns)
+	f.merge(partial)
+	opts := r.temporalOptions(call.Argument(1))
+	disambiguation := r.temporalGetDisambiguation(opts)
+	offsetOption := r.temporalGetOffsetOption(opts, "prefer")
+	reject := r.temporalGetOverflow(opts)
+	d, t = r.temporalResolveDate(&f, reject), r.temporalResolveTime(&f, reject)
+	ns := r.temporalInterpretOffset(d, t, temporalOffsetOption, f.offsetNs, z.tz, disambiguation, offsetOption, false)
+	return r.newTemporalZonedDateTime(ns, z.tz, nil)
+}
+
+func (r *Runtime) temporalZonedDateTimeProto_withPlainTime(call FunctionCall) Value {
+	z := r.thisTemporalZonedDateTime(call.This, "withPlainTime")
+	d, _ := z.dateTime()
+	var ns *big.Int
+	if arg := call.Argument(0); arg == _undefined {
+		ns = temporalStartOfDay(z.tz, d)
+	} else {
+		ns = z.tz.epochNsFor(d, r.toTemporalTime(arg, _undefined), "compatible")
+	}
+	return r.newTemporalZonedDateTime(ns, z.tz, nil)
+}
+
+func (r *Runtime) temporalZonedDateTimeProto_withTimeZone(call FunctionCall) Value {
+	z := r.thisTemporalZonedDateTime(call.This, "withTimeZone")
+	return r.newTemporalZonedDateTime(z.ns, r.temporalToTimeZone(call.Argument(0)), nil)
+}
+
+// temporalAddZoned implements https://tc39.es/proposal-temporal/#sec-temporal-addzoneddatetime. The date units are
+// added to the wall-clock time and the time units to the exact time.
+func (r *Runtime) temporalAddZoned(ns *big.Int, tz *temporalTimeZone, d temporalDuration, reject bool) *big.Int {
+	if d[0] == 0 && d[1] == 0 && d[2] == 0 && d[3] == 0 {
+		return r.temporalAddTimeDuration(ns, d, 1)
+	}
+	date, t := tz.isoDateTimeAt(ns)
+	var dateDuration temporalDuration
+	copy(dateDuration[:4], d[:4])
+	intermediate := tz.epochNsFor(r.temporalAddToDate(date, dateDuration, reject), t, "compatible")
+	return intermediate.Add(intermediate, d.timeNs(false))
+}
+
+func (r *Runtime) temporalZonedDateTimeAdd(call FunctionCall, method string, sign int) Value {
+	z := r.thisTemporalZonedDateTime(call.This, method)
+	d := r.toTemporalDuration(call.Argument(0))
+	if sign < 0 {
+		d = d.negated()
+	}
+	reject := r.temporalGetOverflow(r.temporalOptions(call.Argument(1)))
+	return r.newTemporalZonedDateTime(r.temporalAddZoned(z.ns, z.tz, d, reject), z.tz, nil)
+}
+
+func (r *Runtime) temporalZonedDateTimeProto_add(call FunctionCall) Value {
+	return r.temporalZonedDateTimeAdd(call, "add", 1)
+}
+
+func (r *Runtime) temporalZonedDateTimeProto_subtract(call FunctionCall) Value {
+	return r.temporalZonedDateTimeAdd(call, "subtract", -1)
+}
+
+func (r *Runtime) temporalZonedDateTimeDifference(call FunctionCall, method string, since bool) Value {
+	z := r.thisTemporalZonedDateTime(call.This, method)
+	other, otherTz := r.toTemporalZonedDateTime(call.Argument(0), _undefined)
+	s := r.temporalGetDifferenceSettings(r.temporalOptions(call.Argument(1)), temporalUnitYear, temporalUnitNanosecond, temporalUnitHour)
+	if since {
+		s.mode = temporalNegateRoundingMode(s.mode)
+	}
+	var res temporalDuration
+	if s.largest.isTimeUnit() {
+		res = temporalDiffExact(z.ns, other, s)
+	} else {
+		if !z.tz.equals(otherTz) {
+			panic(r.newError(r.global.RangeError, "Cannot compute a difference in days or larger units between different time zones"))
+		}
+		if sign := other.Cmp(z.ns); sign != 0 {
+			dateRes, timeNs := z.tz.differenceZoned(z.ns, other, s.largest)
+			d1, t1 := z.dateTime()
+			toNs := func(date isoDate) *big.Int {
+				return z.tz.epochNsFor(date, t1, "compatible")
+			}
+			if s.smallest.isTimeUnit() {
+				start, _ := d1.addDate(dateRes[0], dateRes[1], dateRes[2], dateRes[3], false)
+				dayNs := toNs(start.addDays(int64(sign)))
+				dayNs.Sub(dayNs, toNs(start)).Abs(dayNs)
+				res = temporalRoundTimeDifference(dateRes, timeNs, s, dayNs)
+			} else {
+				res = roundCalendarDuration(dateRes, sign, d1, toNs, other, s.largest, s.smallest, s.increment, s.mode)
+			}
+		}
+	}
+	if since {
+		res = res.negated()
+	}
+	return r.newTemporalDuration(res, nil)
+}
+
+func (r *Runtime) temporalZonedDateTimeProto_until(call FunctionCall) Value {
+	return r.temporalZonedDateTimeDifference(call, "until", false)
+}
+
+func (r *Runtime) temporalZonedDateTimeProto_since(call FunctionCall) Value {
+	return r.temporalZonedDateTimeDifference(call, "since", true)
+}
+
+func (r *Runtime) temporalZonedDateTimeProto_round(call FunctionCall) Value {
+	z := r.thisTemporalZonedDateTime(call.This, "round")
+	smallest, increment, mode := r.temporalGetDateTimeRoundTo(call.Argument(0))
+	d, t := z.dateTime()
+	if smallest == temporalUnitDay {
+		start := temporalStartOfDay(z.tz, d)
+		end := temporalStartOfDay(z.tz, d.addDays(1))
+		dayNs := new(big.Int).Sub(end, start)
+		ns := temporalRound(new(big.Int).Sub(z.ns, start), dayNs, mode)
+		return r.newTemporalZonedDateTime(ns.Add(ns, start), z.tz, nil)
+	}
+	rounded := temporalRound(big.NewInt(t.ns()), big.NewInt(increment*temporalUnitNs[smallest]), mode)
+	newTime, days := isoTimeFromNs(rounded.Int64())
+	ns := r.temporalInterpretOffset(d.addDays(days), newTime, temporalOffsetOption, z.tz.offsetNsAt(z.ns), z.tz, "compatible", "prefer", false)
+	return r.newTemporalZonedDateTime(ns, z.tz, nil)
+}
+
+func (r *Runtime) temporalZonedDateTimeProto_equals(call FunctionCall) Value {
+	z := r.thisTemporalZonedDateTime(call.This, "equals")
+	ns, tz := r.toTemporalZonedDateTime(call.Argument(0), _undefined)
+	return r.toBoolean(z.ns.Cmp(ns) == 0 && z.tz.equals(tz))
+}
+
+func formatTemporalZonedDateTime(ns *big.Int, tz *temporalTimeZone, precision int, showOffset bool, tzName, cal string) string {
+	d, t := tz.isoDateTimeAt(ns)
+	var b strings.Builder
+	b.WriteString(formatTemporalDateTime(d, t, precision))
+	if showOffset {
+		b.WriteString(formatTemporalOffset(tz.offsetNsAt(ns), false))
+	}
+	switch tzName {
+	case "auto":
+		b.WriteString("[" + tz.id + "]")
+	case "critical":
+		b.WriteString("[!" + tz.id + "]")
+	}
+	b.WriteString(cal)
+	return b.String()
+}
+
+func (r *Runtime) temporalZonedDateTimeProto_toString(call FunctionCall) Value {
+	z := r.thisTemporalZonedDateTime(call.This, "toString")
+	opts := r.temporalOptions(call.Argument(0))
+	cal := r.temporalGetCalendarNameOption(opts)
+	precision, increment, mode := r.temporalGetPrecisionOptions(opts)
+	showOffset := r.temporalGetOption(opts, "offset", []string{"auto", "never"}, "auto") == "auto"
+	tzName := r.temporalGetOption(opts, "timeZoneName", []string{"auto", "never", "critical"}, "auto")
+	ns := temporalRound(z.ns, big.NewInt(increment), mode)
+	if !isValidEpochNs(ns) {
+		panic(r.newError(r.global.RangeError, "ZonedDateTime is out of range"))
+	}
+	return newStringValue(formatTemporalZonedDateTime(ns, z.tz, precision, showOffset, tzName, cal))
+}
+
+func (r *Runtime) temporalZonedDateTimeProto_toJSON(call FunctionCall) Value {
+	z := r.thisTemporalZonedDateTime(call.This, "toJSON")
+	return newStringValue(formatTemporalZonedDateTime(z.ns, z.tz, temporalPrecisionAuto, true, "auto", ""))
+}
+
+func (r *Runtime) temporalZonedDateTimeProto_toLocaleString(call FunctionCall) Value {
+	z := r.thisTemporalZonedDateTime(call.This, "toLocaleString")
+	return newStringValue(formatTemporalZonedDateTime(z.ns, z.tz, temporalPrecisionAuto, true, "auto", ""))
+}
+
+func (r *Runtime) temporalZonedDateTimeProto_startOfDay(call FunctionCall) Value {
+	z := r.thisTemporalZonedDateTime(call.This, "startOfDay")
+	d, _ := z.dateTime()
+	return r.newTemporalZonedDateTime(temporalStartOfDay(z.tz, d), z.tz, nil)
+}
+
+func (r *Runtime) temporalZonedDateTimeProto_toInstant(call FunctionCall) Value {
+	return r.newTemporalInstant(r.thisTemporalZonedDateTime(call.This, "toInstant").ns, nil)
+}
+
+func (r *Runtime) temporalZonedDateTimeProto_toPlainDate(call FunctionCall) Value {
+	d, _ := r.thisTemporalZonedDateTime(call.This, "toPlainDate").dateTime()
+	return r.newTemporalPlainDate(d, nil)
+}
+
+func (r *Runtime) temporalZonedDateTimeProto_toPlainTime(call FunctionCall) Value {
+	_, t := r.thisTemporalZonedDateTime(call.This, "toPlainTime").dateTime()
+	return r.newTemporalPlainTime(t, nil)
+}
+
+func (r *Runtime) temporalZonedDateTimeProto_toPlainDateTime(call FunctionCall) Value {
+	d, t := r.thisTemporalZonedDateTime(call.This, "toPlainDateTime").dateTime()
+	return r.newTemporalPlainDateTime(d, t, nil)
+}
+
+func (r *Runtime) createTemporalZonedDateTimeProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("constructor", r.global.TemporalZonedDateTime, true, false, true)
+	r.putTemporalDateGetters(o, func(this Value, method string) isoDate {
+		d, _ := r.thisTemporalZonedDateTime(this, method).dateTime()
+		return d
+	})
+	r.putTemporalTimeGetters(o, func(this Value, method string) isoTime {
+		_, t := r.thisTemporalZonedDateTime(this, method).dateTime()
+		return t
+	})
+	r.putTemporalGetter(o, "timeZoneId", r.temporalZonedDateTimeProto_getTimeZoneId)
+	r.putTemporalGetter(o, "epochMilliseconds", r.temporalZonedDateTimeProto_getEpochMilliseconds)
+	r.putTemporalGetter(o, "epochNanoseconds", r.temporalZonedDateTimeProto_getEpochNanoseconds)
+	r.putTemporalGetter(o, "offsetNanoseconds", r.temporalZonedDateTimeProto_getOffsetNanoseconds)
+	r.putTemporalGetter(o, "offset", r.temporalZonedDateTimeProto_getOffset)
+	r.putTemporalGetter(o, "hoursInDay", r.temporalZonedDateTimeProto_getHoursInDay)
+	o._putProp("with", r.newNativeFunc(r.temporalZonedDateTimeProto_with, nil, "with", nil, 1), true, false, true)
+	o._putProp("withPlainTime", r.newNativeFunc(r.temporalZonedDateTimeProto_withPlainTime, nil, "withPlainTime", nil, 0), true, false, true)
+	o._putProp("withTimeZone", r.newNativeFunc(r.temporalZonedDateTimeProto_withTimeZone, nil, "withTimeZone", nil, 1), true, false, true)
+	o._putProp("add", r.newNativeFunc(r.temporalZonedDateTimeProto_add, nil, "add", nil, 1), true, false, true)
+	o._putProp("subtract", r.newNativeFunc(r.temporalZonedDateTimeProto_subtract, nil, "subtract", nil, 1), true, false, true)
+	o._putProp("until", r.newNativeFunc(r.temporalZonedDateTimeProto_until, nil, "until", nil, 1), true, false, true)
+	o._putProp("since", r.newNativeFunc(r.temporalZonedDateTimeProto_since, nil, "since", nil, 1), true, false, true)
+	o._putProp("round", r.newNativeFunc(r.temporalZonedDateTimeProto_round, nil, "round", nil, 1), true, false, true)
+	o._putProp("equals", r.newNativeFunc(r.temporalZonedDateTimeProto_equals, nil, "equals", nil, 1), true, false, true)
+	o._putProp("toString", r.newNativeFunc(r.temporalZonedDateTimeProto_toString, nil, "toString", nil, 0), true, false, true)
+	o._putProp("toJSON", r.newNativeFunc(r.temporalZonedDateTimeProto_toJSON, nil, "toJSON", nil, 0), true, false, true)
+	o._putProp("toLocaleString", r.newNativeFunc(r.temporalZonedDateTimeProto_toLocaleString, nil, "toLocaleString", nil, 0), true, false, true)
+	o._putProp("valueOf", r.newNativeFunc(r.temporalValueOf, nil, "valueOf", nil, 0), true, false, true)
+	o._putProp("startOfDay", r.newNativeFunc(r.temporalZonedDateTimeProto_startOfDay, nil, "startOfDay", nil, 0), true, false, true)
+	o._putProp("toInstant", r.newNativeFunc(r.temporalZonedDateTimeProto_toInstant, nil, "toInstant", nil, 0), true, false, true)
+	o._putProp("toPlainDate", r.newNativeFunc(r.temporalZonedDateTimeProto_toPlainDate, nil, "toPlainDate", nil, 0), true, false, true)
+	o._putProp("toPlainTime", r.newNativeFunc(r.temporalZonedDateTimeProto_toPlainTime, nil, "toPlainTime", nil, 0), true, false, true)
+	o._putProp("toPlainDateTime", r.newNativeFunc(r.temporalZonedDateTimeProto_toPlainDateTime, nil, "toPlainDateTime", nil, 0), true, false, true)
+	o._putSym(SymToStringTag, valueProp(asciiString("Temporal.ZonedDateTime"), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) createTemporalZonedDateTime(val *Object) objectImpl {
+	o := r.newNativeConstructOnly(val, r.builtin_newTemporalZonedDateTime, r.global.TemporalZonedDateTimePrototype, "ZonedDateTime", 2)
+	o._putProp("from", r.newNativeFunc(r.temporalZonedDateTime_from, nil, "from", nil, 1), true, false, true)
+	o._putProp("compare", r.newNativeFunc(r.temporalZonedDateTime_compare, nil, "compare", nil, 2), true, false, true)
+
+	return o
+}
@@ -0,0 +1,536 @@
+package goja
+
+import (
+	"encoding/base64"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/transform"
+)
+
+type textDecoderObject struct {
+	baseObject
+	encoding  string
+	fatal     bool
+	ignoreBOM bool
+
+	// decoder for the legacy encodings, nil for UTF-8 and UTF-16
+	decoder *encoding.Decoder
+
+	// streaming state
+	pending []byte
+	bomSeen bool
+}
+
+// decodeUTF8 implements the UTF-8 decoder from https://encoding.spec.whatwg.org/#utf-8-decoder. Each maximal
+// invalid subsequence is replaced with a single U+FFFD. If atEOF is false an incomplete sequence at the end of
+// the input is not consumed. The last result is false if the input is invalid and fatal is set.
+func decodeUTF8(b []byte, atEOF, fatal bool, sb *strings.Builder) (int, bool) {
+	i := 0
+	for i < len(b) {
+		c := b[i]
+		if c < utf8.RuneSelf {
+			sb.WriteByte(c)
+			i++
+			continue
+		}
+		var needed int
+		var cp rune
+		lower, upper := byte(0x80), byte(0xBF)
+		switch {
+		case c >= 0xC2 && c <= 0xDF:
+			needed, cp = 1, rune(c&0x1F)
+		case c >= 0xE0 && c <= 0xEF:
+			if c == 0xE0 {
+				lower = 0xA0
+			} else if c == 0xED {
+				upper = 0x9F
+			}
+			needed, cp = 2, rune(c&0xF)
+		case c >= 0xF0 && c <= 0xF4:
+			if c == 0xF0 {
+				lower = 0x90
+			} else if c == 0xF4 {
+				upper = 0x8F
+			}
+			needed, cp = 3, rune(c&0x7)
+		default:
+			if fatal {
+				return i, false
+			}
+			sb.WriteRune(utf8.RuneError)
+			i++
+			continue
+		}
+		j := i + 1
+		for ; needed > 0 && j < len(b); j++ {
+			if b[j] < lower || b[j] > upper {
+				break
+			}
+			lower, upper = 0x80, 0xBF
+			cp = cp<<6 | rune(b[j]&0x3F)
+			needed--
+		}
+		if needed == 0 {
+			sb.WriteRune(cp)
+		} else {
+			if j == len(b) && !atEOF {
+				return i, true
+			}
+			if fatal {
+				return i, false
+			}
+			sb.WriteRune(utf8.RuneError)
+		}
+		i = j
+	}
+	return i, true
+}
+
+// decodeUTF16 implements the shared UTF-16 decoder from https://encoding.spec.whatwg.org/#shared-utf-16-decoder.
+func decodeUTF16(b []byte, bigEndian, atEOF, fatal bool, sb *strings.Builder) (int, bool) {
+	unit := func(i int) uint16 {
+		if bigEndian {
+			return uint16(b[i])<<8 | uint16(b[i+1])
+		}
+		return uint16(b[i+1])<<8 | uint16(b[i])
+	}
+	i := 0
+	for i+1 < len(b) {
+		cu := unit(i)
+		switch {
+		case cu >= 0xD800 && cu <= 0xDBFF:
+			if i+3 < len(b) {
+				if next := unit(i + 2); next >= 0xDC00 && next <= 0xDFFF {
+					sb.WriteRune(utf16.DecodeRune(rune(cu), rune(next)))
+					i += 4
+					continue
+				}
+			} else if !atEOF {
+				return i, true
+			} else {
+				// the end of the input is reached while a trail surrogate is expected
+				i = len(b) - 2
+			}
+		case cu >= 0xDC00 && cu <= 0xDFFF:
+		default:
+			sb.WriteRune(rune(cu))
+			i += 2
+			continue
+		}
+		// unpaired surrogate
+		if fatal {
+			return i, false
+		}
+		sb.WriteRune(utf8.RuneError)
+		i += 2
+	}
+	if i < len(b) && atEOF {
+		// odd number of bytes
+		if fatal {
+			return i, false
+		}
+		sb.WriteRune(utf8.RuneError)
+		i = len(b)
+	}
+	return i, true
+}
+
+// decodeLegacy decodes b using one of the x/text decoders. The decoders replace invalid input with U+FFFD which
+// none of the legacy encodings can represent otherwise, so its presence in the output indicates an error.
+func (d *textDecoderObject) decodeLegacy(b []byte, atEOF bool, sb *strings.Builder) (int, bool) {
+	dst := make([]byte, 2*len(b)+utf8.UTFMax)
+	consumed := 0
+	for {
+		nDst, nSrc, err := d.decoder.Transform(dst, b[consumed:], atEOF)
+		out := dst[:nDst]
+		if d.fatal && strings.ContainsRune(string(out), utf8.RuneError) {
+			return consumed, false
+		}
+		sb.Write(out)
+		consumed += nSrc
+		if err != transform.ErrShortDst {
+			break
+		}
+	}
+	return consumed, true
+}
+
+func (d *textDecoderObject) decode(b []byte, stream bool) (string, bool) {
+	if len(d.pending) > 0 {
+		b = append(d.pending, b...)
+	}
+	var sb strings.Builder
+	var n int
+	var ok bool
+	switch d.encoding {
+	case "utf-8":
+		n, ok = decodeUTF8(b, !stream, d.fatal, &sb)
+	case "utf-16le":
+		n, ok = decodeUTF16(b, false, !stream, d.fatal, &sb)
+	case "utf-16be":
+		n, ok = decodeUTF16(b, true, !stream, d.fatal, &sb)
+	default:
+		n, ok = d.decodeLegacy(b, !stream, &sb)
+	}
+	if !ok {
+		d.reset()
+		return "", false
+	}
+	s := sb.String()
+	if !d.ignoreBOM && !d.bomSeen && s != "" && d.decoder == nil {
+		d.bomSeen = true
+		s = strings.TrimPrefix(s, "\uFEFF")
+	}
+	if stream {
+		d.pending = append([]byte(nil), b[n:]...)
+	} else {
+		d.reset()
+	}
+	return s, true
+}
+
+func (d *textDecoderObject) reset() {
+	d.pending = nil
+	d.bomSeen = false
+	if d.decoder != nil {
+		d.decoder.Reset()
+	}
+}
+
+// bufferSourceBytes returns the bytes of an ArrayBuffer, a TypedArray or a DataView.
+func (r *Runtime) bufferSourceBytes(v Value) ([]byte, bool) {
+	if o, ok := v.(*Object); ok {
+		switch o := o.self.(type) {
+		case *arrayBufferObject:
+			return o.data, true
+		case *typedArrayObject:
+			if o.viewedArrayBuf.detached {
+				return nil, true
+			}
+			return o.viewedArrayBuf.data[o.offset*o.elemSize : (o.offset+o.length)*o.elemSize], true
+		case *dataViewObject:
+			if o.viewedArrayBuf.detached {
+				return nil, true
+			}
+			return o.viewedArrayBuf.data[o.byteOffset : o.byteOffset+o.byteLen], true
+		}
+	}
+	return nil, false
+}
+
+type textEncoderObject struct {
+	baseObject
+}
+
+func (r *Runtime) builtin_newTextEncoder(args []Value, newTarget *Object) *Object {
+	if newTarget == nil {
+		panic(r.needNew("TextEncoder"))
+	}
+	proto := r.getPrototypeFromCtor(newTarget, r.global.TextEncoder, r.global.TextEncoderPrototype)
+	e := &textEncoderObject{}
+	e.class = classObject
+	e.val = &Object{runtime: r, self: e}
+	e.extensible = true
+	e.prototype = proto
+	e.init()
+	return e.val
+}
+
+func (r *Runtime) thisTextEncoder(this Value, method string) *textEncoderObject {
+	if o, ok := this.(*Object); ok {
+		if e, ok := o.self.(*textEncoderObject); ok {
+			return e
+		}
+	}
+	panic(r.NewTypeError("Method TextEncoder.prototype.%s called on incompatible receiver %s", method, r.objectproto_toString(FunctionCall{This: this})))
+}
+
+func (r *Runtime) textEncoderProto_getEncoding(call FunctionCall) Value {
+	r.thisTextEncoder(call.This, "encoding")
+	return asciiString("utf-8")
+}
+
+func (r *Runtime) textEncoderProto_encode(call FunctionCall) Value {
+	r.thisTextEncoder(call.This, "encode")
+	var s string
+	if arg := call.Argument(0); arg != _undefined {
+		// lone surrogates are replaced with U+FFFD
+		s = arg.toString().String()
+	}
+	return r.newUint8ArrayFromBytes([]byte(s))
+}
+
+// textEncoderProto_encodeInto implements https://encoding.spec.whatwg.org/#dom-textencoder-encodeinto. Only
+// complete characters are written, read is the number of UTF-16 code units of the source that have been encoded.
+func (r *Runtime) textEncoderProto_encodeInto(call FunctionCall) Value {
+	r.thisTextEncoder(call.This, "encodeInto")
+	src := call.Argument(0).toString()
+	var dst []byte
+	isUint8Array := false
+	if o, ok := call.Argument(1).(*Object); ok {
+		if ta, ok := o.self.(*typedArrayObject); ok {
+			if _, ok := ta.typedArray.(*uint8Array); ok {
+				dst, isUint8Array = r.bufferSourceBytes(o)
+			}
+		}
+	}
+	if !isUint8Array {
+		panic(r.NewTypeError("The destination must be a Uint8Array"))
+	}
+	read, written := 0, 0
+	for i, l := 0, src.length(); i < l; {
+		c := src.charAt(i)
+		units := 1
+		if isUTF16FirstSurrogate(c) && i+1 < l && isUTF16SecondSurrogate(src.charAt(i+1)) {
+			c = utf16.DecodeRune(c, src.charAt(i+1))
+			units = 2
+		} else if utf16.IsSurrogate(c) {
+			c = utf8.RuneError
+		}
+		size := utf8.RuneLen(c)
+		if written+size > len(dst) {
+			break
+		}
+		utf8.EncodeRune(dst[written:], c)
+		written += size
+		read += units
+		i += units
+	}
+	res := r.NewObject()
+	createDataPropertyOrThrow(res, asciiString("read"), intToValue(int64(read)))
+	createDataPropertyOrThrow(res, asciiString("written"), intToValue(int64(written)))
+	return res
+}
+
+func (r *Runtime) builtin_newTextDecoder(args []Value, newTarget *Object) *Object {
+	if newTarget == nil {
+		panic(r.needNew("TextDecoder"))
+	}
+	proto := r.getPrototypeFromCtor(newTarget, r.global.TextDecoder, r.global.TextDecoderPrototype)
+	d := &textDecoderObject{}
+	d.class = classObject
+	d.val = &Object{runtime: r, self: d}
+	d.extensible = true
+	d.prototype = proto
+	d.init()
+
+	label := "utf-8"
+	if len(args) > 0 && args[0] != _undefined {
+		label = args[0].toString().String()
+	}
+	var opts *Object
+	if len(args) > 1 {
+		opts = r.webIDLDictionary(args[1])
+	}
+	enc, err := htmlindex.Get(strings.Trim(label, "\t\n\f\r "))
+	if err == nil {
+		d.encoding, err = htmlindex.Name(enc)
+	}
+	if err != nil || d.encoding == "replacement" {
+		panic(r.newError(r.global.RangeError, "The \"%s\" encoding is not supported", label))
+	}
+	switch d.encoding {
+	case "utf-8", "utf-16le", "utf-16be":
+	default:
+		d.decoder = enc.NewDecoder()
+	}
+	if opts != nil {
+		d.fatal = nilSafe(opts.self.getStr("fatal", nil)).ToBoolean()
+		d.ignoreBOM = nilSafe(opts.self.getStr("ignoreBOM", nil)).ToBoolean()
+	}
+	return d.val
+}
+
+// webIDLDictionary converts a value to a WebIDL dictionary. It returns nil if the value is undefined or null, in
+// which case all members take their default values.
+func (r *Runtime) webIDLDictionary(v Value) *Object {
+	switch v := v.(type) {
+	case valueUndefined, valueNull:
+		return nil
+	case *Object:
+		return v
+	}
+	panic(r.NewTypeError("The provided value is not of type 'object'"))
+}
+
+func (r *Runtime) thisTextDecoder(this Value, method string) *textDecoderObject {
+	if o, ok := this.(*Object); ok {
+		if d, ok := o.self.(*textDecoderObject); ok {
+			return d
+		}
+	}
+	panic(r.NewTypeError("Method TextDecoder.prototype.%s called on incompatible receiver %s", method, r.objectproto_toString(FunctionCall{This: this})))
+}
+
+func (r *Runtime) textDecoderProto_getEncoding(call FunctionCall) Value {
+	return asciiString(r.thisTextDecoder(call.This, "encoding").encoding)
+}
+
+func (r *Runtime) textDecoderProto_getFatal(call FunctionCall) Value {
+	return r.toBoolean(r.thisTextDecoder(call.This, "fatal").fatal)
+}
+
+func (r *Runtime) textDecoderProto_getIgnoreBOM(call FunctionCall) Value {
+	return r.toBoolean(r.thisTextDecoder(call.This, "ignoreBOM").ignoreBOM)
+}
+
+func (r *Runtime) textDecoderProto_decode(call FunctionCall) Value {
+	d := r.thisTextDecoder(call.This, "decode")
+	var b []byte
+	if input := call.Argument(0); input != _undefined {
+		var ok bool
+		if b, ok = r.bufferSourceBytes(input); !ok {
+			panic(r.NewTypeError("The provided value is not of type '(ArrayBuffer or ArrayBufferView)'"))
+		}
+	}
+	stream := false
+	if opts := r.webIDLDictionary(call.Argument(1)); opts != nil {
+		stream = nilSafe(opts.self.getStr("stream", nil)).ToBoolean()
+	}
+	s, ok := d.decode(b, stream)
+	if !ok {
+		panic(r.NewTypeError("The encoded data was not valid for encoding %s", d.encoding))
+	}
+	return newStringValue(s)
+}
+
+func (r *Runtime) createTextEncoderProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("constructor", r.global.TextEncoder, true, false, true)
+	o._put("encoding", &valueProperty{
+		accessor:     true,
+		configurable: true,
+		enumerable:   true,
+		getterFunc:   r.newNativeFunc(r.textEncoderProto_getEncoding, nil, "get encoding", nil, 0),
+	})
+	o._putProp("encode", r.newNativeFunc(r.textEncoderProto_encode, nil, "encode", nil, 0), true, true, true)
+	o._putProp("encodeInto", r.newNativeFunc(r.textEncoderProto_encodeInto, nil, "encodeInto", nil, 2), true, true, true)
+	o._putSym(SymToStringTag, valueProp(asciiString("TextEncoder"), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) createTextEncoder(val *Object) objectImpl {
+	return r.newNativeConstructOnly(val, r.builtin_newTextEncoder, r.global.TextEncoderPrototype, "TextEncoder", 0)
+}
+
+func (r *Runtime) createTextDecoderProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("constructor", r.global.TextDecoder, true, false, true)
+	o._put("encoding", &valueProperty{
+		accessor:     true,
+		configurable: true,
+		enumerable:   true,
+		getterFunc:   r.newNativeFunc(r.textDecoderProto_getEncoding, nil, "get encoding", nil, 0),
+	})
+	o._put("fatal", &valueProperty{
+		accessor:     true,
+		configurable: true,
+		enumerable:   true,
+		getterFunc:   r.newNativeFunc(r.textDecoderProto_getFatal, nil, "get fatal", nil, 0),
+	})
+	o._put("ignoreBOM", &valueProperty{
+		accessor:     true,
+		configurable: true,
+		enumerable:   true,
+		getterFunc:   r.newNativeFunc(r.textDecoderProto_getIgnoreBOM, nil, "get ignoreBOM", nil, 0),
+	})
+	o._putProp("decode", r.newNativeFunc(r.textDecoderProto_decode, nil, "decode", nil, 0), true, true, true)
+	o._putSym(SymToStringTag, valueProp(asciiString("TextDecoder"), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) createTextDecoder(val *Object) objectImpl {
+	return r.newNativeConstructOnly(val, r.builtin_newTextDecoder, r.global.TextDecoderPrototype, "TextDecoder", 0)
+}
+
+func (r *Runtime) newInvalidCharacterError(format string, args ...interface{}) *Object {
+	e := r.newError(r.global.Error, format, args...).(*Object)
+	e.self._putProp("name", asciiString("InvalidCharacterError"), true, false, true)
+	return e
+}
+
+func isASCIIWhitespace(c byte) bool {
+	return c == '\t' || c == '\n' || c == '\f' || c == '\r' || c == ' '
+}
+
+func isBase64Char(c byte) bool {
+	return c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '+' || c == '/'
+}
+
+func (r *Runtime) builtin_btoa(call FunctionCall) Value {
+	if len(call.Arguments) == 0 {
+		panic(r.NewTypeError("Failed to execute 'btoa': 1 argument required, but only 0 present."))
+	}
+	s := call.Argument(0).toString().String()
+	i := 0
+	for _, c := range s {
+		if c > 0xFF {
+			panic(r.newInvalidCharacterError("The string to be encoded contains characters outside of the Latin1 range (at index %d)", i))
+		}
+		i++
+	}
+	return asciiString(base64.StdEncoding.EncodeToString([]byte(latin1Bytes(s))))
+}
+
+// builtin_atob implements the forgiving-base64 decode algorithm from https://infra.spec.whatwg.org/#forgiving-base64.
+func (r *Runtime) builtin_atob(call FunctionCall) Value {
+	if len(call.Arguments) == 0 {
+		panic(r.NewTypeError("Failed to execute 'atob': 1 argument required, but only 0 present."))
+	}
+	s := call.Argument(0).toString().String()
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; !isASCIIWhitespace(c) {
+			b = append(b, c)
+		}
+	}
+	if len(b)%4 == 0 {
+		for i := 0; i < 2 && len(b) > 0 && b[len(b)-1] == '='; i++ {
+			b = b[:len(b)-1]
+		}
+	}
+	if len(b)%4 == 1 {
+		panic(r.newInvalidCharacterError("The string to be decoded is not correctly encoded"))
+	}
+	for _, c := range b {
+		if !isBase64Char(c) {
+			panic(r.newInvalidCharacterError("The string to be decoded is not correctly encoded"))
+		}
+	}
+	data, err := base64.RawStdEncoding.DecodeString(string(b))
+	if err != nil {
+		panic(r.newInvalidCharacterError("The string to be decoded is not correctly encoded"))
+	}
+	return newStringValue(latin1String(string(data)))
+}
+
+// EnableTextEncoding adds the TextEncoder and TextDecoder globals as described in
+// https://encoding.spec.whatwg.org/. TextEncoder always uses UTF-8, TextDecoder supports all the encodings and
+// labels defined by the standard (the legacy ones are backed by golang.org/x/text). Both operate directly on the
+// memory of the supplied buffers. The streaming variants (TextEncoderStream and TextDecoderStream) are not included.
+//
+// It also adds the btoa and atob functions from https://html.spec.whatwg.org/multipage/webappapis.html#atob. The
+// errors they throw for invalid input are Error instances with the name 'InvalidCharacterError'.
+func (r *Runtime) EnableTextEncoding() {
+	if r.global.TextEncoder != nil {
+		return
+	}
+	r.global.TextEncoderPrototype = r.newLazyObject(r.createTextEncoderProto)
+	r.global.TextEncoder = r.newLazyObject(r.createTextEncoder)
+	r.addToGlobal("TextEncoder", r.global.TextEncoder)
+
+	r.global.TextDecoderPrototype = r.newLazyObject(r.createTextDecoderProto)
+	r.global.TextDecoder = r.newLazyObject(r.createTextDecoder)
+	r.addToGlobal("TextDecoder", r.global.TextDecoder)
+
+	r.addToGlobal("btoa", r.newNativeFunc(r.builtin_btoa, nil, "btoa", nil, 1))
+	r.addToGlobal("atob", r.newNativeFunc(r.builtin_atob, nil, "atob", nil, 1))
+}
@@ -0,0 +1,130 @@
+package goja
+
+import (
+	"testing"
+)
+
+func TestTextEncodingDisabled(t *testing.T) {
+	testScript(`typeof TextEncoder === "undefined" && typeof TextDecoder === "undefined" && typeof btoa === "undefined"`, valueTrue, t)
+}
+
+func TestTextEncoder(t *testing.T) {
+	const SCRIPT = `
+	var enc = new TextEncoder();
+	assert.sameValue(enc.encoding, "utf-8");
+	assert(compareArray(enc.encode("aé€😀"), [0x61, 0xc3, 0xa9, 0xe2, 0x82, 0xac, 0xf0, 0x9f, 0x98, 0x80]), "encode");
+	assert(compareArray(enc.encode("\ud800x"), [0xef, 0xbf, 0xbd, 0x78]), "lone surrogate");
+	assert.sameValue(enc.encode().length, 0, "no argument");
+	assert(enc.encode("") instanceof Uint8Array, "Uint8Array");
+
+	var buf = new Uint8Array(8);
+	var res = enc.encodeInto("ab😀é", buf.subarray(1, 7));
+	assert.sameValue(res.read, 4, "read");
+	assert.sameValue(res.written, 6, "written");
+	assert(compareArray(buf, [0, 0x61, 0x62, 0xf0, 0x9f, 0x98, 0x80, 0]), "encodeInto");
+	res = enc.encodeInto("abc", new Uint8Array(0));
+	assert.sameValue(res.read, 0, "read empty");
+	assert.throws(TypeError, function() { enc.encodeInto("a", new Uint16Array(1)) }, "destination");
+	assert.throws(TypeError, function() { TextEncoder() }, "new");
+	assert.throws(TypeError, function() { TextEncoder.prototype.encode.call({}, "") }, "receiver");
+	assert.sameValue(Object.prototype.toString.call(enc), "[object TextEncoder]");
+	`
+	r := New()
+	r.EnableTextEncoding()
+	r.testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestTextDecoder(t *testing.T) {
+	const SCRIPT = `
+	var dec = new TextDecoder();
+	assert.sameValue(dec.encoding, "utf-8");
+	assert.sameValue(dec.fatal, false);
+	assert.sameValue(dec.ignoreBOM, false);
+	assert.sameValue(dec.decode(new Uint8Array([0xef, 0xbb, 0xbf, 0x61, 0xc3, 0xa9])), "aé", "BOM is stripped");
+	assert.sameValue(new TextDecoder("utf-8", {ignoreBOM: true}).decode(new Uint8Array([0xef, 0xbb, 0xbf, 0x61])), "\ufeffa", "ignoreBOM");
+	assert.sameValue(dec.decode(new Uint8Array([0x61, 0xe2, 0x82, 0x62, 0xff, 0xf0, 0x9f])), "a\ufffdb\ufffd\ufffd", "replacement");
+	assert.sameValue(dec.decode(new Uint8Array([0xed, 0xa0, 0x80])), "\ufffd\ufffd\ufffd", "encoded surrogate");
+	assert.sameValue(dec.decode(), "", "no input");
+	assert.sameValue(dec.decode(new DataView(new Uint8Array([0x78, 0x79]).buffer, 1)), "y", "DataView");
+	assert.sameValue(dec.decode(new Uint8Array([0x7a]).buffer), "z", "ArrayBuffer");
+
+	var bytes = [0xf0, 0x9f, 0x98, 0x80, 0xe2, 0x82, 0xac];
+	var s = "";
+	for (var i = 0; i < bytes.length; i++) {
+		s += dec.decode(new Uint8Array([bytes[i]]), {stream: true});
+	}
+	s += dec.decode();
+	assert.sameValue(s, "😀€", "stream");
+	assert.sameValue(dec.decode(new Uint8Array([0xe2, 0x82]), {stream: true}), "", "incomplete");
+	assert.sameValue(dec.decode(), "\ufffd", "flush");
+
+	var fatal = new TextDecoder("UTF8 ", {fatal: true});
+	assert.sameValue(fatal.encoding, "utf-8", "label");
+	assert.throws(TypeError, function() { fatal.decode(new Uint8Array([0xc3])) }, "fatal");
+	assert.sameValue(fatal.decode(new Uint8Array([0xc3, 0xa9])), "é", "fatal after error");
+
+	var utf16 = new TextDecoder("utf-16");
+	assert.sameValue(utf16.encoding, "utf-16le");
+	assert.sameValue(utf16.decode(new Uint8Array([0xff, 0xfe, 0x3d, 0xd8, 0x00, 0xde, 0x00, 0xd8])), "😀\ufffd", "utf-16le");
+	assert.sameValue(new TextDecoder("utf-16be").decode(new Uint8Array([0x00, 0x61, 0x00])), "a\ufffd", "utf-16be odd");
+	assert.sameValue(new TextDecoder("utf-16le").decode(new Uint8Array([0x3d, 0xd8, 0x61])), "\ufffd", "utf-16le trailing");
+
+	var latin1 = new TextDecoder("latin1");
+	assert.sameValue(latin1.encoding, "windows-1252");
+	assert.sameValue(latin1.decode(new Uint8Array([0x80, 0xe9])), "€é", "windows-1252");
+	var sjis = new TextDecoder("shift_jis");
+	assert.sameValue(sjis.decode(new Uint8Array([0x82, 0xa0]), {stream: true}) + sjis.decode(new Uint8Array([0x82, 0xa2])), "あい", "shift_jis");
+	assert.throws(TypeError, function() { new TextDecoder("shift_jis", {fatal: true}).decode(new Uint8Array([0x82])) }, "shift_jis fatal");
+
+	assert.throws(RangeError, function() { new TextDecoder("nope") }, "unknown");
+	assert.throws(RangeError, function() { new TextDecoder("iso-2022-kr") }, "replacement");
+	assert.throws(TypeError, function() { new TextDecoder("utf-8", 1) }, "options");
+	assert.throws(TypeError, function() { dec.decode("abc") }, "input");
+	`
+	r := New()
+	r.EnableTextEncoding()
+	r.testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestBase64(t *testing.T) {
+	const SCRIPT = `
+	function assertInvalid(f, msg) {
+		try {
+			f();
+		} catch (e) {
+			assert.sameValue(e.name, "InvalidCharacterError", msg);
+			return;
+		}
+		throw new Test262Error(msg + ": no exception");
+	}
+	assert.sameValue(btoa("hello"), "aGVsbG8=");
+	assert.sameValue(btoa(""), "");
+	assert.sameValue(btoa("\xff\xfe\x00"), "//4A", "latin1");
+	assert.sameValue(btoa(123), "MTIz", "ToString");
+	assertInvalid(() => btoa("€"), "outside of latin1");
+	assertInvalid(() => btoa("\ud800"), "lone surrogate");
+	assert.throws(TypeError, () => btoa(), "no argument");
+	assert.throws(TypeError, () => btoa(Symbol()), "symbol");
+
+	assert.sameValue(atob("aGVsbG8="), "hello");
+	assert.sameValue(atob(" aGV sbG8\n"), "hello", "whitespace");
+	assert.sameValue(atob("aGVsbG8"), "hello", "no padding");
+	assert.sameValue(atob("//4A"), "\xff\xfe\x00", "latin1");
+	assert.sameValue(atob("YQ"), "a");
+	assert.sameValue(atob("YR=="), "a", "non-zero trailing bits");
+	assert.sameValue(atob(""), "");
+	assert.sameValue(atob(null), "\x9e\xe9e", "ToString");
+	assertInvalid(() => atob(undefined), "undefined");
+	assertInvalid(() => atob("a"), "length");
+	assertInvalid(() => atob("YQ="), "partial padding");
+	assertInvalid(() => atob("Y=Q="), "padding in the middle");
+	assertInvalid(() => atob("aGVsbG8==="), "too much padding");
+	assertInvalid(() => atob("a-b_"), "url alphabet");
+	assertInvalid(() => atob("é"), "non-ascii");
+	assert.throws(TypeError, () => atob(), "no argument");
+	assert.sameValue(atob(btoa("\x00\x80\xff")), "\x00\x80\xff", "round trip");
+	`
+	r := New()
+	r.EnableTextEncoding()
+	r.testScriptWithTestLib(SCRIPT, _undefined, t)
+}
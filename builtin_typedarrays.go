@@ -1,64 +1,87 @@
 package goja
 
 import (
+	"encoding/base64"
 	"fmt"
 	"math"
-	"sort"
+	"math/big"
 	"unsafe"
 
 	"github.com/dop251/goja/unistring"
 )
 
+// typedArraySortCtx sorts the elements of a TypedArray with a comparator. The elements are copied into a list
+// which is sorted with a stable merge sort and then written back, so every element is converted to a Value only once.
 type typedArraySortCtx struct {
-	ta           *typedArrayObject
-	compare      func(FunctionCall) Value
-	needValidate bool
+	ta      *typedArrayObject
+	compare func(FunctionCall) Value
 }
 
-func (ctx *typedArraySortCtx) Len() int {
-	return ctx.ta.length
+func (ctx *typedArraySortCtx) less(x, y Value) bool {
+	res := ctx.compare(FunctionCall{
+		This:      _undefined,
+		Arguments: []Value{x, y},
+	}).ToNumber()
+	ctx.ta.viewedArrayBuf.ensureNotDetached(true)
+	if i, ok := res.(valueInt); ok {
+		return i < 0
+	}
+	f := res.ToFloat()
+	if f < 0 {
+		return true
+	}
+	if f > 0 {
+		return false
+	}
+	if math.Signbit(f) {
+		return true
+	}
+	return false
 }
 
-func (ctx *typedArraySortCtx) Less(i, j int) bool {
-	if ctx.needValidate {
-		ctx.ta.viewedArrayBuf.ensureNotDetached(true)
-		ctx.needValidate = false
-	}
-	offset := ctx.ta.offset
-	if ctx.compare != nil {
-		x := ctx.ta.typedArray.get(offset + i)
-		y := ctx.ta.typedArray.get(offset + j)
-		res := ctx.compare(FunctionCall{
-			This:      _undefined,
-			Arguments: []Value{x, y},
-		}).ToNumber()
-		ctx.needValidate = true
-		if i, ok := res.(valueInt); ok {
-			return i < 0
-		}
-		f := res.ToFloat()
-		if f < 0 {
-			return true
-		}
-		if f > 0 {
-			return false
+// mergeSort sorts a using buf (which must be of the same length) as the temporary storage.
+func (ctx *typedArraySortCtx) mergeSort(a, buf []Value) {
+	if len(a) <= 8 {
+		// insertion sort
+		for i := 1; i < len(a); i++ {
+			for j := i; j > 0 && ctx.less(a[j], a[j-1]); j-- {
+				a[j], a[j-1] = a[j-1], a[j]
+			}
 		}
-		if math.Signbit(f) {
-			return true
+		return
+	}
+	mid := len(a) / 2
+	ctx.mergeSort(a[:mid], buf[:mid])
+	ctx.mergeSort(a[mid:], buf[mid:])
+	if !ctx.less(a[mid], a[mid-1]) {
+		return
+	}
+	copy(buf, a)
+	i, j, k := 0, mid, 0
+	for i < mid && j < len(a) {
+		if ctx.less(buf[j], buf[i]) {
+			a[k] = buf[j]
+			j++
+		} else {
+			a[k] = buf[i]
+			i++
 		}
-		return false
+		k++
 	}
-
-	return ctx.ta.typedArray.less(offset+i, offset+j)
+	k += copy(a[k:], buf[i:mid])
+	copy(a[k:], buf[j:])
 }
 
-func (ctx *typedArraySortCtx) Swap(i, j int) {
-	if ctx.needValidate {
-		ctx.ta.viewedArrayBuf.ensureNotDetached(true)
-		ctx.needValidate = false
+func (ctx *typedArraySortCtx) sort() {
+	ta := ctx.ta
+	values := make([]Value, ta.length)
+	for i := range values {
+		values[i] = ta.typedArray.get(ta.offset + i)
+	}
+	ctx.mergeSort(values, make([]Value, len(values)))
+	for i, v := range values {
+		ta.typedArray.set(ta.offset+i, v)
 	}
-	offset := ctx.ta.offset
-	ctx.ta.typedArray.swap(offset+i, offset+j)
 }
 
 func allocByteSlice(size int) (b []byte) {
@@ -216,6 +239,20 @@ func (r *Runtime) dataViewProto_getByteOffset(call FunctionCall) Value {
 	panic(r.NewTypeError("Method get DataView.prototype.byteOffset called on incompatible receiver %s", r.objectproto_toString(FunctionCall{This: call.This})))
 }
 
+func (r *Runtime) dataViewProto_getBigInt64(call FunctionCall) Value {
+	if dv, ok := r.toObject(call.This).self.(*dataViewObject); ok {
+		return (*valueBigInt)(big.NewInt(int64(dv.viewedArrayBuf.getUint64(dv.getIdxAndByteOrder(r.toIndex(call.Argument(0)), call.Argument(1), 8)))))
+	}
+	panic(r.NewTypeError("Method DataView.prototype.getBigInt64 called on incompatible receiver %s", r.objectproto_toString(FunctionCall{This: call.This})))
+}
+
+func (r *Runtime) dataViewProto_getBigUint64(call FunctionCall) Value {
+	if dv, ok := r.toObject(call.This).self.(*dataViewObject); ok {
+		return (*valueBigInt)(new(big.Int).SetUint64(dv.viewedArrayBuf.getUint64(dv.getIdxAndByteOrder(r.toIndex(call.Argument(0)), call.Argument(1), 8))))
+	}
+	panic(r.NewTypeError("Method DataView.prototype.getBigUint64 called on incompatible receiver %s", r.objectproto_toString(FunctionCall{This: call.This})))
+}
+
 func (r *Runtime) dataViewProto_getFloat32(call FunctionCall) Value {
 	if dv, ok := r.toObject(call.This).self.(*dataViewObject); ok {
 		return floatToValue(float64(dv.viewedArrayBuf.getFloat32(dv.getIdxAndByteOrder(r.toIndex(call.Argument(0)), call.Argument(1), 4))))
@@ -274,6 +311,28 @@ func (r *Runtime) dataViewProto_getUint32(call FunctionCall) Value {
 	panic(r.NewTypeError("Method DataView.prototype.getUint32 called on incompatible receiver %s", r.objectproto_toString(FunctionCall{This: call.This})))
 }
 
+func (r *Runtime) dataViewProto_setBigInt64(call FunctionCall) Value {
+	if dv, ok := r.toObject(call.This).self.(*dataViewObject); ok {
+		idxVal := r.toIndex(call.Argument(0))
+		val := toBigInt64(call.Argument(1))
+		idx, bo := dv.getIdxAndByteOrder(idxVal, call.Argument(2), 8)
+		dv.viewedArrayBuf.setUint64(idx, uint64(val), bo)
+		return _undefined
+	}
+	panic(r.NewTypeError("Method DataView.prototype.setBigInt64 called on incompatible receiver %s", r.objectproto_toString(FunctionCall{This: call.This})))
+}
+
+func (r *Runtime) dataViewProto_setBigUint64(call FunctionCall) Value {
+	if dv, ok := r.toObject(call.This).self.(*dataViewObject); ok {
+		idxVal := r.toIndex(call.Argument(0))
+		val := toBigUint64(call.Argument(1))
+		idx, bo := dv.getIdxAndByteOrder(idxVal, call.Argument(2), 8)
+		dv.viewedArrayBuf.setUint64(idx, val, bo)
+		return _undefined
+	}
+	panic(r.NewTypeError("Method DataView.prototype.setBigUint64 called on incompatible receiver %s", r.objectproto_toString(FunctionCall{This: call.This})))
+}
+
 func (r *Runtime) dataViewProto_setFloat32(call FunctionCall) Value {
 	if dv, ok := r.toObject(call.This).self.(*dataViewObject); ok {
 		idxVal := r.toIndex(call.Argument(0))
@@ -535,7 +594,7 @@ func (r *Runtime) typedArrayProto_find(call FunctionCall) Value {
 			Arguments: []Value{nil, nil, call.This},
 		}
 		for k := 0; k < ta.length; k++ {
-			var val Value
+			val := _undefined
 			if ta.isValidIntegerIndex(k) {
 				val = ta.typedArray.get(ta.offset + k)
 			}
@@ -755,7 +814,7 @@ func (r *Runtime) typedArrayProto_join(call FunctionCall) Value {
 			return stringEmpty
 		}
 
-		var buf valueStringBuilder
+		buf := limitedStringBuilder{r: r}
 
 		var element0 Value
 		if ta.isValidIntegerIndex(0) {
@@ -955,6 +1014,9 @@ func (r *Runtime) typedArrayProto_set(call FunctionCall) Value {
 			if x := srcLen + targetOffset; x < 0 || x > targetLen {
 				panic(r.newError(r.global.RangeError, "Source is too large"))
 			}
+			if src.isBigInt() != ta.isBigInt() {
+				panic(errMixBigIntType)
+			}
 			if src.defaultCtor == ta.defaultCtor {
 				copy(ta.viewedArrayBuf.data[(ta.offset+targetOffset)*ta.elemSize:],
 					src.viewedArrayBuf.data[src.offset*src.elemSize:(src.offset+srcLen)*src.elemSize])
@@ -1080,18 +1142,15 @@ func (r *Runtime) typedArrayProto_some(call FunctionCall) Value {
 func (r *Runtime) typedArrayProto_sort(call FunctionCall) Value {
 	if ta, ok := r.toObject(call.This).self.(*typedArrayObject); ok {
 		ta.viewedArrayBuf.ensureNotDetached(true)
-		var compareFn func(FunctionCall) Value
-
 		if arg := call.Argument(0); arg != _undefined {
-			compareFn = r.toCallable(arg)
-		}
-
-		ctx := typedArraySortCtx{
-			ta:      ta,
-			compare: compareFn,
+			ctx := typedArraySortCtx{
+				ta:      ta,
+				compare: r.toCallable(arg),
+			}
+			ctx.sort()
+		} else {
+			ta.typedArray.sort(ta.offset, ta.offset+ta.length)
 		}
-
-		sort.Stable(&ctx)
 		return call.This
 	}
 	panic(r.NewTypeError("Method TypedArray.prototype.sort called on incompatible receiver %s", r.objectproto_toString(FunctionCall{This: call.This})))
@@ -1120,7 +1179,7 @@ func (r *Runtime) typedArrayProto_subarray(call FunctionCall) Value {
 func (r *Runtime) typedArrayProto_toLocaleString(call FunctionCall) Value {
 	if ta, ok := r.toObject(call.This).self.(*typedArrayObject); ok {
 		length := ta.length
-		var buf valueStringBuilder
+		buf := limitedStringBuilder{r: r}
 		for i := 0; i < length; i++ {
 			ta.viewedArrayBuf.ensureNotDetached(true)
 			if i > 0 {
@@ -1223,7 +1282,11 @@ func (r *Runtime) allocateTypedArray(newTarget *Object, length int, taCtor typed
 }
 
 func (r *Runtime) typedArraySpeciesCreate(ta *typedArrayObject, args []Value) *typedArrayObject {
-	return r.typedArrayCreate(r.speciesConstructorObj(ta.val, ta.defaultCtor), args...)
+	ret := r.typedArrayCreate(r.speciesConstructorObj(ta.val, ta.defaultCtor), args...)
+	if ret.isBigInt() != ta.isBigInt() {
+		panic(r.NewTypeError("TypedArray species constructor returned an array with a different content type"))
+	}
+	return ret
 }
 
 func (r *Runtime) typedArrayCreate(ctor *Object, args ...Value) *typedArrayObject {
@@ -1325,6 +1388,9 @@ func (r *Runtime) _newTypedArrayFromArrayBuffer(ab *arrayBufferObject, args []Va
 func (r *Runtime) _newTypedArrayFromTypedArray(src *typedArrayObject, newTarget *Object, taCtor typedArrayObjectCtor, proto *Object) *Object {
 	dst := r.allocateTypedArray(newTarget, 0, taCtor, proto)
 	src.viewedArrayBuf.ensureNotDetached(true)
+	if src.isBigInt() != dst.isBigInt() {
+		panic(errMixBigIntType)
+	}
 	l := src.length
 
 	dst.viewedArrayBuf.prototype = r.getPrototypeFromCtor(r.speciesConstructorObj(src.viewedArrayBuf.val, r.global.ArrayBuffer), r.global.ArrayBuffer, r.global.ArrayBufferPrototype)
@@ -1403,6 +1469,346 @@ func (r *Runtime) newFloat64Array(args []Value, newTarget, proto *Object) *Objec
 	return r._newTypedArray(args, newTarget, r.newFloat64ArrayObject, proto)
 }
 
+func (r *Runtime) newBigInt64Array(args []Value, newTarget, proto *Object) *Object {
+	return r._newTypedArray(args, newTarget, r.newBigInt64ArrayObject, proto)
+}
+
+func (r *Runtime) newBigUint64Array(args []Value, newTarget, proto *Object) *Object {
+	return r._newTypedArray(args, newTarget, r.newBigUint64ArrayObject, proto)
+}
+
+// base64Result is the result of the FromBase64 and FromHex abstract operations
+// (https://tc39.es/proposal-arraybuffer-base64/spec/#sec-frombase64). read is the number of input characters consumed
+// and err is set if the input was invalid, in which case bytes contains what has been decoded before the error.
+type base64Result struct {
+	read  int
+	bytes []byte
+	err   bool
+}
+
+func isBase64Space(c byte) bool {
+	return c == '\t' || c == '\n' || c == '\f' || c == '\r' || c == ' '
+}
+
+func skipBase64Space(s string, index int) int {
+	for index < len(s) && isBase64Space(s[index]) {
+		index++
+	}
+	return index
+}
+
+func base64Digit(c byte) (byte, bool) {
+	switch {
+	case c >= 'A' && c <= 'Z':
+		return c - 'A', true
+	case c >= 'a' && c <= 'z':
+		return c - 'a' + 26, true
+	case c >= '0' && c <= '9':
+		return c - '0' + 52, true
+	case c == '+':
+		return 62, true
+	case c == '/':
+		return 63, true
+	}
+	return 0, false
+}
+
+// decodeBase64Chunk decodes a chunk of 2 to 4 base64 digits. It returns false if throwOnExtraBits is set and the
+// padding bits of an incomplete chunk are not zero.
+func decodeBase64Chunk(chunk []byte, throwOnExtraBits bool) ([]byte, bool) {
+	var n uint32
+	for i := 0; i < 4; i++ {
+		n <<= 6
+		if i < len(chunk) {
+			n |= uint32(chunk[i])
+		}
+	}
+	b := []byte{byte(n >> 16), byte(n >> 8), byte(n)}
+	size := len(chunk) - 1
+	if throwOnExtraBits && size < 3 && b[size] != 0 {
+		return nil, false
+	}
+	return b[:size], true
+}
+
+func fromBase64(s string, url bool, lastChunkHandling string, maxLength int) (res base64Result) {
+	if maxLength == 0 {
+		return
+	}
+	chunk := make([]byte, 0, 4)
+	index := 0
+	for {
+		index = skipBase64Space(s, index)
+		if index == len(s) {
+			if len(chunk) > 0 {
+				switch lastChunkHandling {
+				case "stop-before-partial":
+					return
+				case "loose":
+					if len(chunk) == 1 {
+						res.err = true
+						return
+					}
+					b, _ := decodeBase64Chunk(chunk, false)
+					res.bytes = append(res.bytes, b...)
+				default:
+					res.err = true
+					return
+				}
+			}
+			res.read = len(s)
+			return
+		}
+		c := s[index]
+		index++
+		if c == '=' {
+			if len(chunk) < 2 {
+				res.err = true
+				return
+			}
+			index = skipBase64Space(s, index)
+			if len(chunk) == 2 {
+				if index == len(s) {
+					if lastChunkHandling != "stop-before-partial" {
+						res.err = true
+					}
+					return
+				}
+				if s[index] == '=' {
+					index = skipBase64Space(s, index+1)
+				}
+			}
+			if index < len(s) {
+				res.err = true
+				return
+			}
+			b, ok := decodeBase64Chunk(chunk, lastChunkHandling == "strict")
+			if !ok {
+				res.err = true
+				return
+			}
+			res.bytes = append(res.bytes, b...)
+			res.read = len(s)
+			return
+		}
+		if url {
+			switch c {
+			case '+', '/':
+				res.err = true
+				return
+			case '-':
+				c = '+'
+			case '_':
+				c = '/'
+			}
+		}
+		d, ok := base64Digit(c)
+		if !ok {
+			res.err = true
+			return
+		}
+		remaining := maxLength - len(res.bytes)
+		if remaining == 1 && len(chunk) == 2 || remaining == 2 && len(chunk) == 3 {
+			return
+		}
+		chunk = append(chunk, d)
+		if len(chunk) == 4 {
+			b, _ := decodeBase64Chunk(chunk, false)
+			res.bytes = append(res.bytes, b...)
+			chunk = chunk[:0]
+			res.read = index
+			if len(res.bytes) == maxLength {
+				return
+			}
+		}
+	}
+}
+
+func hexDigit(c byte) (byte, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
+
+func fromHex(s string, maxLength int) (res base64Result) {
+	if len(s)%2 != 0 {
+		res.err = true
+		return
+	}
+	for res.read < len(s) && len(res.bytes) < maxLength {
+		hi, ok1 := hexDigit(s[res.read])
+		lo, ok2 := hexDigit(s[res.read+1])
+		if !ok1 || !ok2 {
+			res.err = true
+			return
+		}
+		res.read += 2
+		res.bytes = append(res.bytes, hi<<4|lo)
+	}
+	return
+}
+
+func (r *Runtime) base64StringArg(v Value) string {
+	if s, ok := v.(valueString); ok {
+		return s.String()
+	}
+	panic(r.NewTypeError("Argument must be a string"))
+}
+
+func (r *Runtime) base64OptionsObject(options Value) *Object {
+	if options == _undefined {
+		return nil
+	}
+	if o, ok := options.(*Object); ok {
+		return o
+	}
+	panic(r.NewTypeError("Options must be an object"))
+}
+
+func (r *Runtime) base64StringOption(opts *Object, name unistring.String, allowed ...string) string {
+	if opts == nil {
+		return allowed[0]
+	}
+	v := nilSafe(opts.self.getStr(name, nil))
+	if v == _undefined {
+		return allowed[0]
+	}
+	if s, ok := v.(valueString); ok {
+		str := s.String()
+		for _, a := range allowed {
+			if str == a {
+				return str
+			}
+		}
+	}
+	panic(r.NewTypeError("Invalid %s option: %s", name, v.String()))
+}
+
+func (r *Runtime) base64Alphabet(opts *Object) bool {
+	return r.base64StringOption(opts, "alphabet", "base64", "base64url") == "base64url"
+}
+
+func (r *Runtime) base64LastChunkHandling(opts *Object) string {
+	return r.base64StringOption(opts, "lastChunkHandling", "loose", "strict", "stop-before-partial")
+}
+
+func (r *Runtime) toUint8Array(v Value, method string) *typedArrayObject {
+	if o, ok := v.(*Object); ok {
+		if ta, ok := o.self.(*typedArrayObject); ok {
+			if _, ok := ta.typedArray.(*uint8Array); ok {
+				return ta
+			}
+		}
+	}
+	panic(r.NewTypeError("Method Uint8Array.prototype.%s called on incompatible receiver %s", method, r.objectproto_toString(FunctionCall{This: v})))
+}
+
+func (r *Runtime) newUint8ArrayFromBytes(b []byte) *Object {
+	ta := r.allocateTypedArray(r.global.Uint8Array, len(b), r.newUint8ArrayObject, nil)
+	copy(ta.viewedArrayBuf.data, b)
+	return ta.val
+}
+
+func (r *Runtime) uint8Array_fromBase64(call FunctionCall) Value {
+	s := r.base64StringArg(call.Argument(0))
+	opts := r.base64OptionsObject(call.Argument(1))
+	url := r.base64Alphabet(opts)
+	lastChunkHandling := r.base64LastChunkHandling(opts)
+	res := fromBase64(s, url, lastChunkHandling, math.MaxInt32)
+	if res.err {
+		panic(r.newError(r.global.SyntaxError, "Invalid base64 string"))
+	}
+	return r.newUint8ArrayFromBytes(res.bytes)
+}
+
+func (r *Runtime) uint8Array_fromHex(call FunctionCall) Value {
+	s := r.base64StringArg(call.Argument(0))
+	res := fromHex(s, math.MaxInt32)
+	if res.err {
+		panic(r.newError(r.global.SyntaxError, "Invalid hex string"))
+	}
+	return r.newUint8ArrayFromBytes(res.bytes)
+}
+
+func (r *Runtime) uint8ArraySetFromResult(ta *typedArrayObject, res base64Result, kind string) Value {
+	copy(ta.viewedArrayBuf.data[ta.offset:ta.offset+ta.length], res.bytes)
+	if res.err {
+		panic(r.newError(r.global.SyntaxError, "Invalid %s string", kind))
+	}
+	ret := r.NewObject()
+	createDataPropertyOrThrow(ret, asciiString("read"), intToValue(int64(res.read)))
+	createDataPropertyOrThrow(ret, asciiString("written"), intToValue(int64(len(res.bytes))))
+	return ret
+}
+
+func (r *Runtime) uint8ArrayProto_setFromBase64(call FunctionCall) Value {
+	ta := r.toUint8Array(call.This, "setFromBase64")
+	s := r.base64StringArg(call.Argument(0))
+	opts := r.base64OptionsObject(call.Argument(1))
+	url := r.base64Alphabet(opts)
+	lastChunkHandling := r.base64LastChunkHandling(opts)
+	ta.viewedArrayBuf.ensureNotDetached(true)
+	return r.uint8ArraySetFromResult(ta, fromBase64(s, url, lastChunkHandling, ta.length), "base64")
+}
+
+func (r *Runtime) uint8ArrayProto_setFromHex(call FunctionCall) Value {
+	ta := r.toUint8Array(call.This, "setFromHex")
+	s := r.base64StringArg(call.Argument(0))
+	ta.viewedArrayBuf.ensureNotDetached(true)
+	return r.uint8ArraySetFromResult(ta, fromHex(s, ta.length), "hex")
+}
+
+func (r *Runtime) uint8ArrayProto_toBase64(call FunctionCall) Value {
+	ta := r.toUint8Array(call.This, "toBase64")
+	opts := r.base64OptionsObject(call.Argument(0))
+	url := r.base64Alphabet(opts)
+	omitPadding := false
+	if opts != nil {
+		omitPadding = nilSafe(opts.self.getStr("omitPadding", nil)).ToBoolean()
+	}
+	ta.viewedArrayBuf.ensureNotDetached(true)
+	var enc *base64.Encoding
+	if url {
+		enc = base64.URLEncoding
+	} else {
+		enc = base64.StdEncoding
+	}
+	if omitPadding {
+		enc = enc.WithPadding(base64.NoPadding)
+	}
+	return asciiString(enc.EncodeToString(ta.viewedArrayBuf.data[ta.offset : ta.offset+ta.length]))
+}
+
+func (r *Runtime) uint8ArrayProto_toHex(call FunctionCall) Value {
+	ta := r.toUint8Array(call.This, "toHex")
+	ta.viewedArrayBuf.ensureNotDetached(true)
+	data := ta.viewedArrayBuf.data[ta.offset : ta.offset+ta.length]
+	buf := make([]byte, 0, len(data)*2)
+	for _, b := range data {
+		buf = append(buf, hex[b>>4], hex[b&0xF])
+	}
+	return asciiString(buf)
+}
+
+func (r *Runtime) createUint8Array(val *Object) objectImpl {
+	o := r.typedArrayCreator(r.newUint8Array, "Uint8Array", 1)(val).(*nativeFuncObject)
+	o._putProp("fromBase64", r.newNativeFunc(r.uint8Array_fromBase64, nil, "fromBase64", nil, 1), true, false, true)
+	o._putProp("fromHex", r.newNativeFunc(r.uint8Array_fromHex, nil, "fromHex", nil, 1), true, false, true)
+
+	p := o.getStr("prototype", nil).(*Object).self
+	p._putProp("setFromBase64", r.newNativeFunc(r.uint8ArrayProto_setFromBase64, nil, "setFromBase64", nil, 1), true, false, true)
+	p._putProp("setFromHex", r.newNativeFunc(r.uint8ArrayProto_setFromHex, nil, "setFromHex", nil, 1), true, false, true)
+	p._putProp("toBase64", r.newNativeFunc(r.uint8ArrayProto_toBase64, nil, "toBase64", nil, 0), true, false, true)
+	p._putProp("toHex", r.newNativeFunc(r.uint8ArrayProto_toHex, nil, "toHex", nil, 0), true, false, true)
+	return o
+}
+
 func (r *Runtime) createArrayBufferProto(val *Object) objectImpl {
 	b := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
 	byteLengthProp := &valueProperty{
@@ -1443,6 +1849,8 @@ func (r *Runtime) createDataViewProto(val *Object) objectImpl {
 		getterFunc:   r.newNativeFunc(r.dataViewProto_getByteOffset, nil, "get byteOffset", nil, 0),
 	})
 	b._putProp("constructor", r.global.DataView, true, false, true)
+	b._putProp("getBigInt64", r.newNativeFunc(r.dataViewProto_getBigInt64, nil, "getBigInt64", nil, 1), true, false, true)
+	b._putProp("getBigUint64", r.newNativeFunc(r.dataViewProto_getBigUint64, nil, "getBigUint64", nil, 1), true, false, true)
 	b._putProp("getFloat32", r.newNativeFunc(r.dataViewProto_getFloat32, nil, "getFloat32", nil, 1), true, false, true)
 	b._putProp("getFloat64", r.newNativeFunc(r.dataViewProto_getFloat64, nil, "getFloat64", nil, 1), true, false, true)
 	b._putProp("getInt8", r.newNativeFunc(r.dataViewProto_getInt8, nil, "getInt8", nil, 1), true, false, true)
@@ -1451,6 +1859,8 @@ func (r *Runtime) createDataViewProto(val *Object) objectImpl {
 	b._putProp("getUint8", r.newNativeFunc(r.dataViewProto_getUint8, nil, "getUint8", nil, 1), true, false, true)
 	b._putProp("getUint16", r.newNativeFunc(r.dataViewProto_getUint16, nil, "getUint16", nil, 1), true, false, true)
 	b._putProp("getUint32", r.newNativeFunc(r.dataViewProto_getUint32, nil, "getUint32", nil, 1), true, false, true)
+	b._putProp("setBigInt64", r.newNativeFunc(r.dataViewProto_setBigInt64, nil, "setBigInt64", nil, 2), true, false, true)
+	b._putProp("setBigUint64", r.newNativeFunc(r.dataViewProto_setBigUint64, nil, "setBigUint64", nil, 2), true, false, true)
 	b._putProp("setFloat32", r.newNativeFunc(r.dataViewProto_setFloat32, nil, "setFloat32", nil, 2), true, false, true)
 	b._putProp("setFloat64", r.newNativeFunc(r.dataViewProto_setFloat64, nil, "setFloat64", nil, 2), true, false, true)
 	b._putProp("setInt8", r.newNativeFunc(r.dataViewProto_setInt8, nil, "setInt8", nil, 2), true, false, true)
@@ -1570,7 +1980,7 @@ func (r *Runtime) initTypedArrays() {
 	r.global.TypedArrayPrototype = r.newLazyObject(r.createTypedArrayProto)
 	r.global.TypedArray = r.newLazyObject(r.createTypedArray)
 
-	r.global.Uint8Array = r.newLazyObject(r.typedArrayCreator(r.newUint8Array, "Uint8Array", 1))
+	r.global.Uint8Array = r.newLazyObject(r.createUint8Array)
 	r.addToGlobal("Uint8Array", r.global.Uint8Array)
 
 	r.global.Uint8ClampedArray = r.newLazyObject(r.typedArrayCreator(r.newUint8ClampedArray, "Uint8ClampedArray", 1))
@@ -1596,4 +2006,10 @@ func (r *Runtime) initTypedArrays() {
 
 	r.global.Float64Array = r.newLazyObject(r.typedArrayCreator(r.newFloat64Array, "Float64Array", 8))
 	r.addToGlobal("Float64Array", r.global.Float64Array)
+
+	r.global.BigInt64Array = r.newLazyObject(r.typedArrayCreator(r.newBigInt64Array, "BigInt64Array", 8))
+	r.addToGlobal("BigInt64Array", r.global.BigInt64Array)
+
+	r.global.BigUint64Array = r.newLazyObject(r.typedArrayCreator(r.newBigUint64Array, "BigUint64Array", 8))
+	r.addToGlobal("BigUint64Array", r.global.BigUint64Array)
 }
@@ -324,3 +324,78 @@ func TestTypedArrayDeleteUnconfigurable(t *testing.T) {
 
 	testScript(SCRIPT, _undefined, t)
 }
+
+func TestTypedArrayFindLast(t *testing.T) {
+	const SCRIPT = `
+	var ta = new Int16Array([1, 2, 3, 4]);
+	assert.sameValue(ta.findLast(function(v) { return v % 2 === 1 }), 3, "#1");
+	assert.sameValue(ta.findLastIndex(function(v) { return v < 3 }), 1, "#2");
+	assert.sameValue(ta.findLast(function() { return false }), undefined, "#3");
+	assert.sameValue(ta.findLastIndex(function() { return false }), -1, "#4");
+	assert.throws(TypeError, function() { Int16Array.prototype.findLast.call([1], function() { return true }) }, "incompatible receiver");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestUint8ArrayBase64(t *testing.T) {
+	const SCRIPT = `
+	var bytes = new Uint8Array([72, 101, 108, 108, 111, 251, 255]);
+	assert.sameValue(bytes.toBase64(), "SGVsbG/7/w==", "toBase64");
+	assert.sameValue(bytes.toBase64({alphabet: "base64url", omitPadding: true}), "SGVsbG_7_w", "toBase64 url");
+	assert.sameValue(bytes.subarray(1, 3).toHex(), "656c", "toHex subarray");
+
+	assert(compareArray(Uint8Array.fromBase64("SGVs bG/7\n/w=="), [72, 101, 108, 108, 111, 251, 255]), "fromBase64");
+	assert(compareArray(Uint8Array.fromBase64("SGVsbG_7_w", {alphabet: "base64url"}), [72, 101, 108, 108, 111, 251, 255]), "fromBase64 url");
+	assert(compareArray(Uint8Array.fromBase64("SGVsbG8", {lastChunkHandling: "stop-before-partial"}), [72, 101, 108]), "stop-before-partial");
+	assert(compareArray(Uint8Array.fromHex("00fFa0"), [0, 255, 160]), "fromHex");
+	assert(Object.getPrototypeOf(Uint8Array.fromHex("")) === Uint8Array.prototype, "prototype");
+
+	assert.throws(SyntaxError, function() { Uint8Array.fromBase64("SGVsbG8", {lastChunkHandling: "strict"}) }, "strict partial");
+	assert.throws(SyntaxError, function() { Uint8Array.fromBase64("SGVsbG9=", {lastChunkHandling: "strict"}) }, "strict extra bits");
+	assert.throws(SyntaxError, function() { Uint8Array.fromBase64("SGVsbG_7") }, "url char in base64");
+	assert.throws(SyntaxError, function() { Uint8Array.fromBase64("S") }, "single char");
+	assert.throws(SyntaxError, function() { Uint8Array.fromHex("abc") }, "odd hex");
+	assert.throws(SyntaxError, function() { Uint8Array.fromHex("zz") }, "bad hex");
+	assert.throws(TypeError, function() { Uint8Array.fromBase64(new String("AA==")) }, "string object");
+	assert.throws(TypeError, function() { Uint8Array.fromBase64("AA==", {alphabet: "other"}) }, "alphabet");
+	assert.throws(TypeError, function() { Uint8Array.fromBase64("AA==", 1) }, "options");
+	assert.throws(TypeError, function() { Uint8Array.prototype.toHex.call(new Uint8ClampedArray(1)) }, "receiver");
+
+	var target = new Uint8Array(4);
+	var res = target.setFromBase64("SGVsbG8=");
+	assert.sameValue(res.read, 4, "read");
+	assert.sameValue(res.written, 3, "written");
+	assert(compareArray(target, [72, 101, 108, 0]), "setFromBase64 partial");
+
+	target = new Uint8Array(4);
+	assert.throws(SyntaxError, function() { target.setFromBase64("SGVs!") }, "setFromBase64 error");
+	assert(compareArray(target, [72, 101, 108, 0]), "bytes written before error");
+
+	target = new Uint8Array(8);
+	res = target.subarray(2).setFromHex("0102ff");
+	assert.sameValue(res.read, 6, "hex read");
+	assert.sameValue(res.written, 3, "hex written");
+	assert(compareArray(target, [0, 0, 1, 2, 255, 0, 0, 0]), "setFromHex");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestDataViewBigInt(t *testing.T) {
+	const SCRIPT = `
+	var dv = new DataView(new ArrayBuffer(16));
+	dv.setBigInt64(0, -2n);
+	assert.sameValue(dv.getBigInt64(0), -2n, "getBigInt64");
+	assert.sameValue(dv.getBigUint64(0), 2n ** 64n - 2n, "getBigUint64");
+	assert.sameValue(dv.getUint8(7), 0xfe, "big endian by default");
+
+	dv.setBigUint64(8, 2n ** 64n + 0x0102n, true);
+	assert.sameValue(dv.getBigUint64(8, true), 0x0102n, "wraps modulo 2^64");
+	assert.sameValue(dv.getUint8(8), 2, "little endian");
+	assert.sameValue(new BigInt64Array(dv.buffer)[0], dv.getBigInt64(0, true), "BigInt64Array view");
+
+	assert.throws(TypeError, function() { dv.setBigInt64(0, 1) }, "number value");
+	assert.throws(RangeError, function() { dv.getBigInt64(9) }, "out of range");
+	assert.throws(TypeError, function() { DataView.prototype.getBigUint64.call({}, 0) }, "incompatible receiver");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
@@ -0,0 +1,636 @@
+package goja
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/dop251/goja/unistring"
+)
+
+type urlObject struct {
+	baseObject
+	url          *urlRecord
+	searchParams *urlSearchParamsObject
+}
+
+type urlSearchParamsObject struct {
+	baseObject
+	list []urlQueryPair
+	url  *urlObject // the URL object the instance belongs to, if any
+}
+
+type urlSearchParamsIterObject struct {
+	baseObject
+	params *urlSearchParamsObject
+	idx    int
+	kind   iterationKind
+}
+
+// update implements https://url.spec.whatwg.org/#concept-urlsearchparams-update
+func (p *urlSearchParamsObject) update() {
+	if p.url == nil {
+		return
+	}
+	serialized := serializeURLEncoded(p.list)
+	p.url.url.query, p.url.url.hasQuery = serialized, serialized != ""
+}
+
+func (r *Runtime) parseURLArgs(urlArg, baseArg Value) (*urlRecord, bool) {
+	var base *urlRecord
+	if baseArg != _undefined {
+		var ok bool
+		if base, ok = parseURL(baseArg.toString().String(), nil); !ok {
+			return nil, false
+		}
+	}
+	return parseURL(urlArg.toString().String(), base)
+}
+
+func (r *Runtime) newURLObject(u *urlRecord, proto *Object) *Object {
+	o := &urlObject{url: u}
+	o.class = classObject
+	o.val = &Object{runtime: r, self: o}
+	o.extensible = true
+	o.prototype = proto
+	o.init()
+
+	sp := r.newURLSearchParamsObject(r.global.URLSearchParamsPrototype)
+	sp.url = o
+	if u.hasQuery {
+		sp.list = parseURLEncoded(u.query)
+	}
+	o.searchParams = sp
+	return o.val
+}
+
+func (r *Runtime) builtin_newURL(args []Value, newTarget *Object) *Object {
+	if newTarget == nil {
+		panic(r.needNew("URL"))
+	}
+	var urlArg, baseArg Value = _undefined, _undefined
+	if len(args) > 0 {
+		urlArg = args[0]
+	}
+	if len(args) > 1 {
+		baseArg = args[1]
+	}
+	proto := r.getPrototypeFromCtor(newTarget, r.global.URL, r.global.URLPrototype)
+	u, ok := r.parseURLArgs(urlArg, baseArg)
+	if !ok {
+		panic(r.NewTypeError("Invalid URL: %s", urlArg.String()))
+	}
+	return r.newURLObject(u, proto)
+}
+
+func (r *Runtime) url_parse(call FunctionCall) Value {
+	if u, ok := r.parseURLArgs(call.Argument(0), call.Argument(1)); ok {
+		return r.newURLObject(u, r.global.URLPrototype)
+	}
+	return _null
+}
+
+func (r *Runtime) url_canParse(call FunctionCall) Value {
+	_, ok := r.parseURLArgs(call.Argument(0), call.Argument(1))
+	return r.toBoolean(ok)
+}
+
+func (r *Runtime) thisURL(this Value, method string) *urlObject {
+	if o, ok := this.(*Object); ok {
+		if u, ok := o.self.(*urlObject); ok {
+			return u
+		}
+	}
+	panic(r.NewTypeError("Method URL.prototype.%s called on incompatible receiver %s", method, r.objectproto_toString(FunctionCall{This: this})))
+}
+
+func (r *Runtime) urlProto_getHref(call FunctionCall) Value {
+	return newStringValue(r.thisURL(call.This, "href").url.serialize(false))
+}
+
+func (r *Runtime) urlProto_setHref(call FunctionCall) Value {
+	o := r.thisURL(call.This, "href")
+	value := call.Argument(0).toString().String()
+	u, ok := parseURL(value, nil)
+	if !ok {
+		panic(r.NewTypeError("Invalid URL: %s", value))
+	}
+	o.url = u
+	o.searchParams.list = nil
+	if u.hasQuery {
+		o.searchParams.list = parseURLEncoded(u.query)
+	}
+	return _undefined
+}
+
+func (r *Runtime) urlProto_getOrigin(call FunctionCall) Value {
+	return newStringValue(r.thisURL(call.This, "origin").url.origin())
+}
+
+func (r *Runtime) urlProto_getProtocol(call FunctionCall) Value {
+	return newStringValue(r.thisURL(call.This, "protocol").url.scheme + ":")
+}
+
+func (r *Runtime) urlProto_setProtocol(call FunctionCall) Value {
+	o := r.thisURL(call.This, "protocol")
+	r.urlSetComponent(o, call.Argument(0).toString().String()+":", urlStateSchemeStart)
+	return _undefined
+}
+
+// urlSetComponent runs the basic URL parser with a state override on a copy of the URL so that a failure leaves
+// the URL unchanged.
+func (r *Runtime) urlSetComponent(o *urlObject, value string, override urlState) {
+	u := o.url.clone()
+	if u.parse(value, nil, override) {
+		o.url = u
+	}
+}
+
+func (r *Runtime) urlProto_getUsername(call FunctionCall) Value {
+	return newStringValue(r.thisURL(call.This, "username").url.username)
+}
+
+func (r *Runtime) urlProto_setUsername(call FunctionCall) Value {
+	o := r.thisURL(call.This, "username")
+	value := call.Argument(0).toString().String()
+	if !o.url.cannotHaveUsernamePasswordPort() {
+		o.url.username = urlPercentEncodeString(value, urlUserinfoSet)
+	}
+	return _undefined
+}
+
+func (r *Runtime) urlProto_getPassword(call FunctionCall) Value {
+	return newStringValue(r.thisURL(call.This, "password").url.password)
+}
+
+func (r *Runtime) urlProto_setPassword(call FunctionCall) Value {
+	o := r.thisURL(call.This, "password")
+	value := call.Argument(0).toString().String()
+	if !o.url.cannotHaveUsernamePasswordPort() {
+		o.url.password = urlPercentEncodeString(value, urlUserinfoSet)
+	}
+	return _undefined
+}
+
+func (r *Runtime) urlProto_getHost(call FunctionCall) Value {
+	u := r.thisURL(call.This, "host").url
+	if !u.hasHost {
+		return stringEmpty
+	}
+	if u.port == -1 {
+		return newStringValue(u.host)
+	}
+	return newStringValue(u.host + ":" + strconv.Itoa(u.port))
+}
+
+func (r *Runtime) urlProto_setHost(call FunctionCall) Value {
+	o := r.thisURL(call.This, "host")
+	value := call.Argument(0).toString().String()
+	if !o.url.isOpaque {
+		r.urlSetComponent(o, value, urlStateHost)
+	}
+	return _undefined
+}
+
+func (r *Runtime) urlProto_getHostname(call FunctionCall) Value {
+	return newStringValue(r.thisURL(call.This, "hostname").url.host)
+}
+
+func (r *Runtime) urlProto_setHostname(call FunctionCall) Value {
+	o := r.thisURL(call.This, "hostname")
+	value := call.Argument(0).toString().String()
+	if !o.url.isOpaque {
+		r.urlSetComponent(o, value, urlStateHostname)
+	}
+	return _undefined
+}
+
+func (r *Runtime) urlProto_getPort(call FunctionCall) Value {
+	u := r.thisURL(call.This, "port").url
+	if u.port == -1 {
+		return stringEmpty
+	}
+	return asciiString(strconv.Itoa(u.port))
+}
+
+func (r *Runtime) urlProto_setPort(call FunctionCall) Value {
+	o := r.thisURL(call.This, "port")
+	value := call.Argument(0).toString().String()
+	if o.url.cannotHaveUsernamePasswordPort() {
+		return _undefined
+	}
+	if value == "" {
+		o.url.port = -1
+	} else {
+		r.urlSetComponent(o, value, urlStatePort)
+	}
+	return _undefined
+}
+
+func (r *Runtime) urlProto_getPathname(call FunctionCall) Value {
+	return newStringValue(r.thisURL(call.This, "pathname").url.pathname())
+}
+
+func (r *Runtime) urlProto_setPathname(call FunctionCall) Value {
+	o := r.thisURL(call.This, "pathname")
+	value := call.Argument(0).toString().String()
+	if !o.url.isOpaque {
+		u := o.url.clone()
+		u.path = nil
+		if u.parse(value, nil, urlStatePathStart) {
+			o.url = u
+		}
+	}
+	return _undefined
+}
+
+func (r *Runtime) urlProto_getSearch(call FunctionCall) Value {
+	u := r.thisURL(call.This, "search").url
+	if !u.hasQuery || u.query == "" {
+		return stringEmpty
+	}
+	return newStringValue("?" + u.query)
+}
+
+func (r *Runtime) urlProto_setSearch(call FunctionCall) Value {
+	o := r.thisURL(call.This, "search")
+	value := call.Argument(0).toString().String()
+	if value == "" {
+		o.url.query, o.url.hasQuery = "", false
+		o.searchParams.list = nil
+		return _undefined
+	}
+	u := o.url.clone()
+	u.query, u.hasQuery = "", true
+	if u.parse(strings.TrimPrefix(value, "?"), nil, urlStateQuery) {
+		o.url = u
+		o.searchParams.list = parseURLEncoded(u.query)
+	}
+	return _undefined
+}
+
+func (r *Runtime) urlProto_getSearchParams(call FunctionCall) Value {
+	return r.thisURL(call.This, "searchParams").searchParams.val
+}
+
+func (r *Runtime) urlProto_getHash(call FunctionCall) Value {
+	u := r.thisURL(call.This, "hash").url
+	if !u.hasFragment || u.fragment == "" {
+		return stringEmpty
+	}
+	return newStringValue("#" + u.fragment)
+}
+
+func (r *Runtime) urlProto_setHash(call FunctionCall) Value {
+	o := r.thisURL(call.This, "hash")
+	value := call.Argument(0).toString().String()
+	if value == "" {
+		o.url.fragment, o.url.hasFragment = "", false
+		return _undefined
+	}
+	u := o.url.clone()
+	u.fragment, u.hasFragment = "", true
+	if u.parse(strings.TrimPrefix(value, "#"), nil, urlStateFragment) {
+		o.url = u
+	}
+	return _undefined
+}
+
+func (r *Runtime) urlProto_toString(call FunctionCall) Value {
+	return newStringValue(r.thisURL(call.This, "toString").url.serialize(false))
+}
+
+func (r *Runtime) urlProto_toJSON(call FunctionCall) Value {
+	return newStringValue(r.thisURL(call.This, "toJSON").url.serialize(false))
+}
+
+func (r *Runtime) newURLSearchParamsObject(proto *Object) *urlSearchParamsObject {
+	o := &urlSearchParamsObject{}
+	o.class = classObject
+	o.val = &Object{runtime: r, self: o}
+	o.extensible = true
+	o.prototype = proto
+	o.init()
+	return o
+}
+
+func (r *Runtime) builtin_newURLSearchParams(args []Value, newTarget *Object) *Object {
+	if newTarget == nil {
+		panic(r.needNew("URLSearchParams"))
+	}
+	proto := r.getPrototypeFromCtor(newTarget, r.global.URLSearchParams, r.global.URLSearchParamsPrototype)
+	o := r.newURLSearchParamsObject(proto)
+	if len(args) == 0 {
+		return o.val
+	}
+	switch init := args[0].(type) {
+	case valueUndefined:
+	case *Object:
+		if method := toMethod(init.self.getSym(SymIterator, nil)); method != nil {
+			r.getIterator(init, method).iterate(func(item Value) {
+				var pair []Value
+				if obj, ok := item.(*Object); ok {
+					pair = r.iterableToList(obj, nil)
+				}
+				if len(pair) != 2 {
+					panic(r.NewTypeError("Each query pair must be an iterable [name, value] tuple"))
+				}
+				o.list = append(o.list, urlQueryPair{name: pair[0].toString().String(), value: pair[1].toString().String()})
+			})
+		} else {
+			for item, next := iterateEnumerableStringProperties(init)(); next != nil; item, next = next() {
+				o.list = append(o.list, urlQueryPair{name: item.name.String(), value: nilSafe(item.value).toString().String()})
+			}
+		}
+	default:
+		o.list = parseURLEncoded(strings.TrimPrefix(init.toString().String(), "?"))
+	}
+	return o.val
+}
+
+func (r *Runtime) thisURLSearchParams(this Value, method string) *urlSearchParamsObject {
+	if o, ok := this.(*Object); ok {
+		if p, ok := o.self.(*urlSearchParamsObject); ok {
+			return p
+		}
+	}
+	panic(r.NewTypeError("Method URLSearchParams.prototype.%s called on incompatible receiver %s", method, r.objectproto_toString(FunctionCall{This: this})))
+}
+
+func (r *Runtime) urlSearchParamsProto_getSize(call FunctionCall) Value {
+	return intToValue(int64(len(r.thisURLSearchParams(call.This, "size").list)))
+}
+
+func (r *Runtime) urlSearchParamsProto_append(call FunctionCall) Value {
+	p := r.thisURLSearchParams(call.This, "append")
+	p.list = append(p.list, urlQueryPair{name: call.Argument(0).toString().String(), value: call.Argument(1).toString().String()})
+	p.update()
+	return _undefined
+}
+
+func (r *Runtime) urlSearchParamsProto_delete(call FunctionCall) Value {
+	p := r.thisURLSearchParams(call.This, "delete")
+	name := call.Argument(0).toString().String()
+	var value string
+	hasValue := false
+	if v := call.Argument(1); v != _undefined {
+		value, hasValue = v.toString().String(), true
+	}
+	list := p.list[:0]
+	for _, pair := range p.list {
+		if pair.name == name && (!hasValue || pair.value == value) {
+			continue
+		}
+		list = append(list, pair)
+	}
+	p.list = list
+	p.update()
+	return _undefined
+}
+
+func (r *Runtime) urlSearchParamsProto_get(call FunctionCall) Value {
+	p := r.thisURLSearchParams(call.This, "get")
+	name := call.Argument(0).toString().String()
+	for _, pair := range p.list {
+		if pair.name == name {
+			return newStringValue(pair.value)
+		}
+	}
+	return _null
+}
+
+func (r *Runtime) urlSearchParamsProto_getAll(call FunctionCall) Value {
+	p := r.thisURLSearchParams(call.This, "getAll")
+	name := call.Argument(0).toString().String()
+	var values []Value
+	for _, pair := range p.list {
+		if pair.name == name {
+			values = append(values, newStringValue(pair.value))
+		}
+	}
+	return r.newArrayValues(values)
+}
+
+func (r *Runtime) urlSearchParamsProto_has(call FunctionCall) Value {
+	p := r.thisURLSearchParams(call.This, "has")
+	name := call.Argument(0).toString().String()
+	var value string
+	hasValue := false
+	if v := call.Argument(1); v != _undefined {
+		value, hasValue = v.toString().String(), true
+	}
+	for _, pair := range p.list {
+		if pair.name == name && (!hasValue || pair.value == value) {
+			return valueTrue
+		}
+	}
+	return valueFalse
+}
+
+func (r *Runtime) urlSearchParamsProto_set(call FunctionCall) Value {
+	p := r.thisURLSearchParams(call.This, "set")
+	name := call.Argument(0).toString().String()
+	value := call.Argument(1).toString().String()
+	found := false
+	list := p.list[:0]
+	for _, pair := range p.list {
+		if pair.name == name {
+			if found {
+				continue
+			}
+			found = true
+			pair.value = value
+		}
+		list = append(list, pair)
+	}
+	if !found {
+		list = append(list, urlQueryPair{name: name, value: value})
+	}
+	p.list = list
+	p.update()
+	return _undefined
+}
+
+func compareUTF16(a, b string) int {
+	ua, ub := utf16.Encode([]rune(a)), utf16.Encode([]rune(b))
+	for i := 0; i < len(ua) && i < len(ub); i++ {
+		if ua[i] != ub[i] {
+			if ua[i] < ub[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(ua) - len(ub)
+}
+
+func (r *Runtime) urlSearchParamsProto_sort(call FunctionCall) Value {
+	p := r.thisURLSearchParams(call.This, "sort")
+	sort.SliceStable(p.list, func(i, j int) bool {
+		return compareUTF16(p.list[i].name, p.list[j].name) < 0
+	})
+	p.update()
+	return _undefined
+}
+
+func (r *Runtime) urlSearchParamsProto_toString(call FunctionCall) Value {
+	return newStringValue(serializeURLEncoded(r.thisURLSearchParams(call.This, "toString").list))
+}
+
+func (r *Runtime) urlSearchParamsProto_forEach(call FunctionCall) Value {
+	p := r.thisURLSearchParams(call.This, "forEach")
+	callback := r.toCallable(call.Argument(0))
+	thisArg := call.Argument(1)
+	for i := 0; i < len(p.list); i++ {
+		pair := p.list[i]
+		callback(FunctionCall{This: thisArg, Arguments: []Value{newStringValue(pair.value), newStringValue(pair.name), p.val}})
+	}
+	return _undefined
+}
+
+func (r *Runtime) createURLSearchParamsIterator(this Value, kind iterationKind, method string) Value {
+	p := r.thisURLSearchParams(this, method)
+	it := &urlSearchParamsIterObject{params: p, kind: kind}
+	it.class = classObject
+	it.val = &Object{runtime: r, self: it}
+	it.extensible = true
+	it.prototype = r.global.URLSearchParamsIteratorPrototype
+	it.init()
+	return it.val
+}
+
+func (r *Runtime) urlSearchParamsProto_entries(call FunctionCall) Value {
+	return r.createURLSearchParamsIterator(call.This, iterationKindKeyValue, "entries")
+}
+
+func (r *Runtime) urlSearchParamsProto_keys(call FunctionCall) Value {
+	return r.createURLSearchParamsIterator(call.This, iterationKindKey, "keys")
+}
+
+func (r *Runtime) urlSearchParamsProto_values(call FunctionCall) Value {
+	return r.createURLSearchParamsIterator(call.This, iterationKindValue, "values")
+}
+
+func (r *Runtime) urlSearchParamsIterProto_next(call FunctionCall) Value {
+	if o, ok := call.This.(*Object); ok {
+		if it, ok := o.self.(*urlSearchParamsIterObject); ok {
+			if it.params == nil || it.idx >= len(it.params.list) {
+				it.params = nil
+				return r.createIterResultObject(_undefined, true)
+			}
+			pair := it.params.list[it.idx]
+			it.idx++
+			var res Value
+			switch it.kind {
+			case iterationKindKey:
+				res = newStringValue(pair.name)
+			case iterationKindValue:
+				res = newStringValue(pair.value)
+			default:
+				res = r.newArrayValues([]Value{newStringValue(pair.name), newStringValue(pair.value)})
+			}
+			return r.createIterResultObject(res, false)
+		}
+	}
+	panic(r.NewTypeError("Method URLSearchParams Iterator.prototype.next called on incompatible receiver %s", r.objectproto_toString(FunctionCall{This: call.This})))
+}
+
+func (r *Runtime) putWebIDLAccessor(o *baseObject, name unistring.String, getter, setter func(FunctionCall) Value) {
+	prop := &valueProperty{
+		accessor:     true,
+		configurable: true,
+		enumerable:   true,
+		getterFunc:   r.newNativeFunc(getter, nil, "get "+name, nil, 0),
+	}
+	if setter != nil {
+		prop.setterFunc = r.newNativeFunc(setter, nil, "set "+name, nil, 1)
+	}
+	o._put(name, prop)
+}
+
+func (r *Runtime) createURLProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("constructor", r.global.URL, true, false, true)
+	r.putWebIDLAccessor(o, "href", r.urlProto_getHref, r.urlProto_setHref)
+	r.putWebIDLAccessor(o, "origin", r.urlProto_getOrigin, nil)
+	r.putWebIDLAccessor(o, "protocol", r.urlProto_getProtocol, r.urlProto_setProtocol)
+	r.putWebIDLAccessor(o, "username", r.urlProto_getUsername, r.urlProto_setUsername)
+	r.putWebIDLAccessor(o, "password", r.urlProto_getPassword, r.urlProto_setPassword)
+	r.putWebIDLAccessor(o, "host", r.urlProto_getHost, r.urlProto_setHost)
+	r.putWebIDLAccessor(o, "hostname", r.urlProto_getHostname, r.urlProto_setHostname)
+	r.putWebIDLAccessor(o, "port", r.urlProto_getPort, r.urlProto_setPort)
+	r.putWebIDLAccessor(o, "pathname", r.urlProto_getPathname, r.urlProto_setPathname)
+	r.putWebIDLAccessor(o, "search", r.urlProto_getSearch, r.urlProto_setSearch)
+	r.putWebIDLAccessor(o, "searchParams", r.urlProto_getSearchParams, nil)
+	r.putWebIDLAccessor(o, "hash", r.urlProto_getHash, r.urlProto_setHash)
+	o._putProp("toString", r.newNativeFunc(r.urlProto_toString, nil, "toString", nil, 0), true, true, true)
+	o._putProp("toJSON", r.newNativeFunc(r.urlProto_toJSON, nil, "toJSON", nil, 0), true, true, true)
+	o._putSym(SymToStringTag, valueProp(asciiString("URL"), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) createURL(val *Object) objectImpl {
+	o := r.newNativeConstructOnly(val, r.builtin_newURL, r.global.URLPrototype, "URL", 1)
+	o._putProp("parse", r.newNativeFunc(r.url_parse, nil, "parse", nil, 1), true, true, true)
+	o._putProp("canParse", r.newNativeFunc(r.url_canParse, nil, "canParse", nil, 1), true, true, true)
+
+	return o
+}
+
+func (r *Runtime) createURLSearchParamsProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("constructor", r.global.URLSearchParams, true, false, true)
+	r.putWebIDLAccessor(o, "size", r.urlSearchParamsProto_getSize, nil)
+	o._putProp("append", r.newNativeFunc(r.urlSearchParamsProto_append, nil, "append", nil, 2), true, true, true)
+	o._putProp("delete", r.newNativeFunc(r.urlSearchParamsProto_delete, nil, "delete", nil, 1), true, true, true)
+	o._putProp("get", r.newNativeFunc(r.urlSearchParamsProto_get, nil, "get", nil, 1), true, true, true)
+	o._putProp("getAll", r.newNativeFunc(r.urlSearchParamsProto_getAll, nil, "getAll", nil, 1), true, true, true)
+	o._putProp("has", r.newNativeFunc(r.urlSearchParamsProto_has, nil, "has", nil, 1), true, true, true)
+	o._putProp("set", r.newNativeFunc(r.urlSearchParamsProto_set, nil, "set", nil, 2), true, true, true)
+	o._putProp("sort", r.newNativeFunc(r.urlSearchParamsProto_sort, nil, "sort", nil, 0), true, true, true)
+	o._putProp("toString", r.newNativeFunc(r.urlSearchParamsProto_toString, nil, "toString", nil, 0), true, true, true)
+	o._putProp("forEach", r.newNativeFunc(r.urlSearchParamsProto_forEach, nil, "forEach", nil, 1), true, true, true)
+	o._putProp("keys", r.newNativeFunc(r.urlSearchParamsProto_keys, nil, "keys", nil, 0), true, true, true)
+	o._putProp("values", r.newNativeFunc(r.urlSearchParamsProto_values, nil, "values", nil, 0), true, true, true)
+	entries := r.newNativeFunc(r.urlSearchParamsProto_entries, nil, "entries", nil, 0)
+	o._putProp("entries", entries, true, true, true)
+	o._putSym(SymIterator, valueProp(entries, true, false, true))
+	o._putSym(SymToStringTag, valueProp(asciiString("URLSearchParams"), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) createURLSearchParams(val *Object) objectImpl {
+	return r.newNativeConstructOnly(val, r.builtin_newURLSearchParams, r.global.URLSearchParamsPrototype, "URLSearchParams", 0)
+}
+
+func (r *Runtime) createURLSearchParamsIterProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.IteratorPrototype, classObject)
+
+	o._putProp("next", r.newNativeFunc(r.urlSearchParamsIterProto_next, nil, "next", nil, 0), true, true, true)
+	o._putSym(SymToStringTag, valueProp(asciiString("URLSearchParams Iterator"), false, false, true))
+
+	return o
+}
+
+// EnableURL adds the URL and URLSearchParams globals as described in https://url.spec.whatwg.org/.
+//
+// Internationalised domain names are converted to Punycode, however the IDNA mapping is approximated by
+// lower-casing and NFKC normalisation and the IDNA validity criteria (such as the Bidi rules) are not enforced.
+func (r *Runtime) EnableURL() {
+	if r.global.URL != nil {
+		return
+	}
+	r.global.URLPrototype = r.newLazyObject(r.createURLProto)
+	r.global.URL = r.newLazyObject(r.createURL)
+	r.addToGlobal("URL", r.global.URL)
+
+	r.global.URLSearchParamsIteratorPrototype = r.newLazyObject(r.createURLSearchParamsIterProto)
+	r.global.URLSearchParamsPrototype = r.newLazyObject(r.createURLSearchParamsProto)
+	r.global.URLSearchParams = r.newLazyObject(r.createURLSearchParams)
+	r.addToGlobal("URLSearchParams", r.global.URLSearchParams)
+}
@@ -2,34 +2,47 @@ package goja
 
 type weakMap uint64
 
+// weakCollection holds the state of a WeakMap or a WeakSet. The entries are stored in the keys (see Object.weakRefs),
+// so they are collected together with the keys. When the collection itself is collected its entries are removed
+// from the keys which are still alive (see Runtime.cleanupWeakCollections).
+type weakCollection struct {
+	id   weakMap
+	keys weakCollectionKeys
+}
+
 type weakMapObject struct {
 	baseObject
-	m weakMap
+	m *weakCollection
 }
 
 func (wmo *weakMapObject) init() {
 	wmo.baseObject.init()
-	wmo.m = weakMap(wmo.val.runtime.genId())
+	wmo.m = wmo.val.runtime.newWeakCollection(wmo.val)
 }
 
-func (wm weakMap) set(key *Object, value Value) {
-	key.getWeakRefs()[wm] = value
+func (wm *weakCollection) set(key *Object, value Value) {
+	refs := key.getWeakRefs()
+	if _, exists := refs[wm.id]; !exists {
+		wm.keys.add(key)
+	}
+	refs[wm.id] = value
 }
 
-func (wm weakMap) get(key *Object) Value {
-	return key.weakRefs[wm]
+func (wm *weakCollection) get(key *Object) Value {
+	return key.weakRefs[wm.id]
 }
 
-func (wm weakMap) remove(key *Object) bool {
-	if _, exists := key.weakRefs[wm]; exists {
-		delete(key.weakRefs, wm)
+func (wm *weakCollection) remove(key *Object) bool {
+	if _, exists := key.weakRefs[wm.id]; exists {
+		delete(key.weakRefs, wm.id)
+		wm.keys.remove(key)
 		return true
 	}
 	return false
 }
 
-func (wm weakMap) has(key *Object) bool {
-	_, exists := key.weakRefs[wm]
+func (wm *weakCollection) has(key *Object) bool {
+	_, exists := key.weakRefs[wm.id]
 	return exists
 }
 
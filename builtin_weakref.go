@@ -0,0 +1,75 @@
+package goja
+
+type weakRefObject struct {
+	baseObject
+	target weakObjectRef
+}
+
+func (r *Runtime) weakRefProto_deref(call FunctionCall) Value {
+	thisObj := r.toObject(call.This)
+	wro, ok := thisObj.self.(*weakRefObject)
+	if !ok {
+		panic(r.NewTypeError("Method WeakRef.prototype.deref called on incompatible receiver %s", r.objectproto_toString(FunctionCall{This: thisObj})))
+	}
+	if target := wro.target.get(); target != nil {
+		r.keepDuringJob(target)
+		return target
+	}
+	return _undefined
+}
+
+// keepDuringJob makes sure the object is not collected until the current job is finished
+// (see AddToKeptObjects in the specification).
+func (r *Runtime) keepDuringJob(o *Object) {
+	r.keptObjects = append(r.keptObjects, o)
+}
+
+func (r *Runtime) builtin_newWeakRef(args []Value, newTarget *Object) *Object {
+	if newTarget == nil {
+		panic(r.needNew("WeakRef"))
+	}
+	var target *Object
+	if len(args) > 0 {
+		target, _ = args[0].(*Object)
+	}
+	if target == nil {
+		panic(r.NewTypeError("WeakRef: target must be an object"))
+	}
+	proto := r.getPrototypeFromCtor(newTarget, r.global.WeakRef, r.global.WeakRefPrototype)
+	o := &Object{runtime: r}
+
+	wro := &weakRefObject{}
+	wro.class = classWeakRef
+	wro.val = o
+	wro.extensible = true
+	o.self = wro
+	wro.prototype = proto
+	wro.init()
+	wro.target = newWeakObjectRef(target)
+	r.keepDuringJob(target)
+	return o
+}
+
+func (r *Runtime) createWeakRefProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("constructor", r.global.WeakRef, true, false, true)
+	o._putProp("deref", r.newNativeFunc(r.weakRefProto_deref, nil, "deref", nil, 0), true, false, true)
+
+	o._putSym(SymToStringTag, valueProp(asciiString(classWeakRef), false, false, true))
+
+	return o
+}
+
+func (r *Runtime) createWeakRef(val *Object) objectImpl {
+	o := r.newNativeConstructOnly(val, r.builtin_newWeakRef, r.global.WeakRefPrototype, "WeakRef", 1)
+
+	return o
+}
+
+func (r *Runtime) initWeakRef() {
+	r.global.WeakRefPrototype = r.newLazyObject(r.createWeakRefProto)
+	r.global.WeakRef = r.newLazyObject(r.createWeakRef)
+
+	r.addToGlobal("WeakRef", r.global.WeakRef)
+}
@@ -0,0 +1,27 @@
+package goja
+
+import (
+	"testing"
+)
+
+func TestWeakRef(t *testing.T) {
+	const SCRIPT = `
+	var target = {};
+	var ref = new WeakRef(target);
+	assert.sameValue(ref.deref(), target, "deref");
+	assert.sameValue(Object.prototype.toString.call(ref), "[object WeakRef]", "toStringTag");
+	assert.sameValue(WeakRef.length, 1, "length");
+	assert.sameValue(Object.getPrototypeOf(ref), WeakRef.prototype, "prototype");
+
+	class MyRef extends WeakRef {}
+	var myRef = new MyRef(target);
+	assert(myRef instanceof MyRef, "subclass");
+	assert.sameValue(myRef.deref(), target, "subclass deref");
+
+	assert.throws(TypeError, function() { WeakRef(target) }, "call without new");
+	assert.throws(TypeError, function() { new WeakRef() }, "no target");
+	assert.throws(TypeError, function() { new WeakRef(1) }, "primitive target");
+	assert.throws(TypeError, function() { WeakRef.prototype.deref.call({}) }, "incompatible receiver");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
@@ -2,12 +2,12 @@ package goja
 
 type weakSetObject struct {
 	baseObject
-	s weakMap
+	s *weakCollection
 }
 
 func (ws *weakSetObject) init() {
 	ws.baseObject.init()
-	ws.s = weakMap(ws.val.runtime.genId())
+	ws.s = ws.val.runtime.newWeakCollection(ws.val)
 }
 
 func (r *Runtime) weakSetProto_add(call FunctionCall) Value {
@@ -0,0 +1,282 @@
+package goja
+
+import (
+	"sync"
+)
+
+// WorkerOptions configures the Worker global added by Loop.EnableWorkers.
+type WorkerOptions struct {
+	// Load returns the script of the worker identified by the first argument of the Worker constructor. It is
+	// called on the goroutine running the parent loop. An error is thrown by the constructor as a GoError.
+	Load func(name string) (*Program, error)
+
+	// Init, if not nil, is called on the worker goroutine with the Runtime and the Loop of a new worker before its
+	// script runs. It can be used to add the host APIs (console, fetch, nested workers, etc.) to the worker.
+	Init func(r *Runtime, l *Loop)
+}
+
+type workerObject struct {
+	baseObject
+
+	parent, child *Loop
+
+	// finished is set on the parent goroutine when the worker has stopped or has been terminated, after that
+	// the parent loop is no longer kept alive by the worker.
+	finished bool
+
+	mu sync.Mutex
+	// closed is set when the worker goroutine has stopped or terminate() has been called, no messages are
+	// delivered to the worker after that.
+	closed bool
+}
+
+// workerError describes an exception which stopped a worker. It is created on the worker goroutine and reported
+// on the parent one.
+type workerError struct {
+	message string
+	err     clonedValue
+	cloned  bool
+}
+
+func (w *workerObject) isClosed() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.closed
+}
+
+// close marks the worker as closed and returns true if it had not been closed before.
+func (w *workerObject) close() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return false
+	}
+	w.closed = true
+	return true
+}
+
+func (w *workerObject) finish() {
+	if !w.finished {
+		w.finished = true
+		w.parent.refs--
+	}
+}
+
+func (r *Runtime) newMessageEvent(data Value, ports []Value) *Object {
+	e := r.NewObject()
+	e.self._putProp("type", asciiString("message"), true, true, true)
+	e.self._putProp("data", data, true, true, true)
+	e.self._putProp("ports", r.newArrayValues(ports), true, true, true)
+	return e
+}
+
+// postMessageTask serializes the message in the sending Runtime and returns a task that delivers it to the
+// onmessage handler of target in the receiving loop. The message is dropped if target returns nil.
+func (r *Runtime) postMessageTask(call FunctionCall, to *Loop, target func() *Object) func() error {
+	m := r.structuredSerialize(call.Argument(0), r.transferList(call.Argument(1)))
+	return func() error {
+		t := target()
+		if t == nil {
+			m.discard()
+			return nil
+		}
+		data, ports := to.deserializeMessage(m)
+		fn, ok := AssertFunction(nilSafe(t.self.getStr("onmessage", nil)))
+		if !ok {
+			return nil
+		}
+		_, err := fn(t, to.r.newMessageEvent(data, ports))
+		return err
+	}
+}
+
+func (l *Loop) builtin_newWorker(args []Value, newTarget *Object) *Object {
+	r := l.r
+	if newTarget == nil {
+		panic(r.needNew("Worker"))
+	}
+	name := argAt(args, 0).String()
+	if l.workerOptions.Load == nil {
+		panic(r.NewTypeError("Cannot load worker %s", name))
+	}
+	prg, err := l.workerOptions.Load(name)
+	if err != nil {
+		panic(r.NewGoError(err))
+	}
+	w := &workerObject{parent: l}
+	w.class = classObject
+	w.val = &Object{runtime: r, self: w}
+	w.extensible = true
+	w.prototype = r.getPrototypeFromCtor(newTarget, r.global.Worker, r.global.WorkerPrototype)
+	w.init()
+	w._putProp("onmessage", _null, true, true, true)
+	w._putProp("onerror", _null, true, true, true)
+
+	childRuntime := New()
+	child := NewLoop(childRuntime)
+	w.child = child
+	global := childRuntime.globalObject
+	childRuntime.addToGlobal("self", global)
+	global.self._putProp("onmessage", _null, true, true, true)
+	childRuntime.addToGlobal("postMessage", childRuntime.newNativeFunc(func(call FunctionCall) Value {
+		if w.isClosed() {
+			return _undefined
+		}
+		l.post(childRuntime.postMessageTask(call, l, func() *Object {
+			if w.finished {
+				return nil
+			}
+			return w.val
+		}))
+		return _undefined
+	}, nil, "postMessage", nil, 1))
+	childRuntime.addToGlobal("close", childRuntime.newNativeFunc(func(FunctionCall) Value {
+		w.close()
+		child.stopped = true
+		return _undefined
+	}, nil, "close", nil, 0))
+	child.keepAlive = func() bool {
+		_, ok := AssertFunction(nilSafe(global.self.getStr("onmessage", nil)))
+		return ok
+	}
+
+	l.refs++
+	go w.run(prg, l.workerOptions.Init)
+	return w.val
+}
+
+func (w *workerObject) run(prg *Program, init func(*Runtime, *Loop)) {
+	child := w.child
+	var scriptErr error
+	err := child.Run(func(r *Runtime) {
+		if init != nil {
+			init(r, child)
+		}
+		if _, scriptErr = r.RunProgram(prg); scriptErr != nil {
+			child.stopped = true
+		}
+	})
+	if scriptErr != nil {
+		err = scriptErr
+	}
+	terminated := !w.close()
+	var we *workerError
+	if err != nil && !terminated {
+		we = &workerError{message: err.Error()}
+		if ex, ok := err.(*Exception); ok {
+			if child.r.vm.try(func() {
+				we.err = child.r.structuredSerialize(ex.val, transferables{}).data
+			}) == nil {
+				we.cloned = true
+			}
+		}
+	}
+	w.parent.post(func() error {
+		if w.finished {
+			return nil
+		}
+		w.finish()
+		if we != nil {
+			return w.reportError(we)
+		}
+		return nil
+	})
+}
+
+// reportError calls the onerror handler of the Worker. If there is no handler the error is returned, so it
+// stops the parent loop.
+func (w *workerObject) reportError(we *workerError) error {
+	r := w.val.runtime
+	var errValue Value = _undefined
+	if we.cloned {
+		errValue = r.structuredDeserialize(we.err)
+	}
+	if fn, ok := AssertFunction(nilSafe(w.getStr("onerror", nil))); ok {
+		e := r.NewObject()
+		e.self._putProp("type", asciiString("error"), true, true, true)
+		e.self._putProp("message", newStringValue(we.message), true, true, true)
+		e.self._putProp("error", errValue, true, true, true)
+		_, err := fn(w.val, e)
+		return err
+	}
+	if errValue == _undefined {
+		errValue = r.newError(r.global.Error, "%s", we.message)
+	}
+	return &Exception{val: errValue}
+}
+
+func (l *Loop) thisWorker(this Value, method string) *workerObject {
+	if o, ok := this.(*Object); ok {
+		if w, ok := o.self.(*workerObject); ok {
+			return w
+		}
+	}
+	panic(l.r.NewTypeError("Method Worker.prototype.%s called on incompatible receiver %s", method, l.r.objectproto_toString(FunctionCall{This: this})))
+}
+
+func (l *Loop) workerProto_postMessage(call FunctionCall) Value {
+	w := l.thisWorker(call.This, "postMessage")
+	if w.isClosed() {
+		return _undefined
+	}
+	child := w.child
+	global := child.r.globalObject
+	child.post(l.r.postMessageTask(call, child, func() *Object {
+		return global
+	}))
+	return _undefined
+}
+
+func (l *Loop) workerProto_terminate(call FunctionCall) Value {
+	w := l.thisWorker(call.This, "terminate")
+	if w.close() {
+		child := w.child
+		child.r.Interrupt("terminated")
+		child.post(func() error {
+			child.stopped = true
+			return nil
+		})
+	}
+	w.finish()
+	return _undefined
+}
+
+func (l *Loop) createWorkerProto(val *Object) objectImpl {
+	r := l.r
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("constructor", r.global.Worker, true, false, true)
+	o._putProp("postMessage", r.newNativeFunc(l.workerProto_postMessage, nil, "postMessage", nil, 1), true, true, true)
+	o._putProp("terminate", r.newNativeFunc(l.workerProto_terminate, nil, "terminate", nil, 0), true, true, true)
+	o._putSym(SymToStringTag, valueProp(asciiString("Worker"), false, false, true))
+
+	return o
+}
+
+func (l *Loop) createWorker(val *Object) objectImpl {
+	return l.r.newNativeConstructOnly(val, l.builtin_newWorker, l.r.global.WorkerPrototype, "Worker", 1)
+}
+
+// EnableWorkers adds the Worker global to the Runtime of the loop. Each Worker runs its script in a separate
+// Runtime with its own Loop on a new goroutine. The worker's global object has the self, postMessage, close and
+// onmessage properties, the Worker object has postMessage, terminate, onmessage and onerror. The messages are
+// copied using the structured clone algorithm, ArrayBuffers listed in the transfer argument of postMessage() are
+// moved without copying and detached in the sender, MessagePorts (see EnableMessageChannels) are moved to the
+// receiving Runtime. The handlers receive a plain event object with the 'type', 'data' and 'ports' properties.
+//
+// A worker stops when its loop has nothing to do and its onmessage handler is not a function, when it calls
+// close() or when terminate() is called. An exception which is not caught by the worker stops it and is passed
+// to the onerror handler of the Worker object as the 'error' property of the event. If there is no handler,
+// the exception stops the parent loop.
+//
+// While a worker is running the parent loop does not stop: Run waits for the worker and delivers its messages.
+func (l *Loop) EnableWorkers(options WorkerOptions) {
+	l.workerOptions = options
+	r := l.r
+	if r.global.Worker != nil {
+		return
+	}
+	r.global.WorkerPrototype = r.newLazyObject(l.createWorkerProto)
+	r.global.Worker = r.newLazyObject(l.createWorker)
+	r.addToGlobal("Worker", r.global.Worker)
+}
@@ -0,0 +1,151 @@
+package goja
+
+import (
+	"errors"
+	"testing"
+)
+
+func newWorkerTestLoop(t *testing.T, scripts map[string]string) *Loop {
+	t.Helper()
+	r := New()
+	l := NewLoop(r)
+	l.EnableWorkers(WorkerOptions{
+		Load: func(name string) (*Program, error) {
+			src, ok := scripts[name]
+			if !ok {
+				return nil, errors.New("no such worker")
+			}
+			return Compile(name, src, false)
+		},
+	})
+	return l
+}
+
+func TestWorkerPostMessage(t *testing.T) {
+	l := newWorkerTestLoop(t, map[string]string{
+		"echo.js": `
+		onmessage = function(e) {
+			var data = e.data;
+			if (data === "stop") {
+				close();
+				return;
+			}
+			data.seen = true;
+			postMessage(data, data.buf ? [data.buf] : undefined);
+		};
+		`,
+	})
+	var res Value
+	err := l.Run(func(r *Runtime) {
+		_, err := r.RunString(`
+		var received = [];
+		var w = new Worker("echo.js");
+		var obj = { a: [1, 2], d: new Date(5), m: new Map([["k", new Set([1])]]), re: /x/gi, e: new RangeError("bad") };
+		obj.self = obj;
+		var buf = new Uint8Array([1, 2, 3]).buffer;
+		w.onmessage = function(e) {
+			received.push(e.data);
+			if (received.length === 2) {
+				w.postMessage("stop");
+			}
+		};
+		w.postMessage(obj);
+		w.postMessage({ buf: buf }, [buf]);
+		var detached = buf.byteLength;
+		`)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err = l.r.RunString(`
+	var [first, second] = received;
+	first !== obj && first.seen && first.self === first && first.a[1] === 2 && first.d.getTime() === 5 &&
+		first.m.get("k").has(1) && first.re.flags === "gi" && first.e instanceof RangeError && first.e.message === "bad" &&
+		second.buf.byteLength === 3 && new Uint8Array(second.buf)[2] === 3 && detached === 0;
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.ToBoolean() {
+		t.Fatal("unexpected messages")
+	}
+}
+
+func TestWorkerError(t *testing.T) {
+	l := newWorkerTestLoop(t, map[string]string{
+		"throw.js": `setTimeout(function() { throw new TypeError("oops") }, 1);`,
+	})
+	err := l.Run(func(r *Runtime) {
+		r.RunString(`
+		var reported;
+		var w = new Worker("throw.js");
+		w.onerror = function(e) { reported = e.error };
+		`)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res, _ := l.r.RunString(`reported instanceof TypeError && reported.message === "oops"`); !res.ToBoolean() {
+		t.Fatal(l.r.Get("reported"))
+	}
+
+	err = l.Run(func(r *Runtime) {
+		r.RunString(`new Worker("throw.js")`)
+	})
+	if ex, ok := err.(*Exception); !ok || ex.Value().String() != "TypeError: oops" {
+		t.Fatal(err)
+	}
+
+	_, err = l.r.RunString(`new Worker("missing.js")`)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestWorkerTerminate(t *testing.T) {
+	l := newWorkerTestLoop(t, map[string]string{
+		"busy.js": `onmessage = function() {}; for (;;) {}`,
+	})
+	err := l.Run(func(r *Runtime) {
+		r.RunString(`
+		var w = new Worker("busy.js");
+		setTimeout(function() { w.terminate(); w.postMessage("ignored") }, 10);
+		`)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStructuredCloneErrors(t *testing.T) {
+	l := newWorkerTestLoop(t, map[string]string{"idle.js": ``})
+	_, err := l.r.RunString(`
+	var w = new Worker("idle.js");
+	var errs = [];
+	for (var v of [function() {}, Symbol(), { s: Symbol() }, new WeakMap()]) {
+		try {
+			w.postMessage(v);
+		} catch (e) {
+			errs.push(e.name);
+		}
+	}
+	var buf = new ArrayBuffer(1);
+	try {
+		w.postMessage(buf, [buf, buf]);
+	} catch (e) {
+		errs.push(e.name);
+	}
+	if (errs.join() !== "DataCloneError,DataCloneError,DataCloneError,DataCloneError,DataCloneError") {
+		throw new Error(errs.join());
+	}
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Run(func(*Runtime) {}); err != nil {
+		t.Fatal(err)
+	}
+}
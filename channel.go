@@ -0,0 +1,253 @@
+package goja
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// chanExport feeds a Go channel with the values produced by an async iterator. See Loop.exportToChan.
+type chanExport struct {
+	loop    *Loop
+	iter    *iteratorRecord
+	ch      reflect.Value
+	elemTyp reflect.Type
+}
+
+// exportToChan creates a channel of type typ, stores it into dst and starts driving the async iterator of o. Each
+// value is converted into the element type of the channel on the loop goroutine and sent from a separate one, so
+// the loop is not blocked by a slow receiver. The next value is requested once the previous one is received.
+func (l *Loop) exportToChan(o *Object, dst reflect.Value, typ reflect.Type) error {
+	if typ.ChanDir()&reflect.RecvDir == 0 {
+		return fmt.Errorf("could not convert %v to %v: the channel is send-only", o, typ)
+	}
+	r := l.r
+	var iter *iteratorRecord
+	if ex := r.vm.try(func() {
+		iter = r.getAsyncIterator(o)
+	}); ex != nil {
+		return fmt.Errorf("could not convert %v to %v: %w", o, typ, ex)
+	}
+	e := &chanExport{
+		loop:    l,
+		iter:    iter,
+		ch:      reflect.MakeChan(reflect.ChanOf(reflect.BothDir, typ.Elem()), 0),
+		elemTyp: typ.Elem(),
+	}
+	dst.Set(e.ch.Convert(typ))
+	l.refs++
+	l.post(e.step)
+	return nil
+}
+
+func (e *chanExport) step() error {
+	r := e.loop.r
+	var res Value
+	if ex := r.vm.try(func() {
+		if e.iter.next == nil {
+			panic(r.NewTypeError("iterator.next is missing or not a function"))
+		}
+		res = e.iter.next(FunctionCall{This: e.iter.iterator})
+	}); ex != nil {
+		e.finish(ex)
+		return nil
+	}
+	r.awaitValue(res, e.onResult, func(reason Value) {
+		e.finish(&Exception{val: reason})
+	})
+	return nil
+}
+
+func (e *chanExport) onResult(v Value) {
+	r := e.loop.r
+	var done bool
+	var value Value
+	if ex := r.vm.try(func() {
+		res := r.toObject(v)
+		if done = nilSafe(res.self.getStr("done", nil)).ToBoolean(); !done {
+			value = nilSafe(res.self.getStr("value", nil))
+		}
+	}); ex != nil {
+		e.finish(ex)
+		return
+	}
+	if done {
+		e.finish(nil)
+		return
+	}
+	elem := reflect.New(e.elemTyp).Elem()
+	var err error
+	ex := r.vm.try(func() {
+		err = r.toReflectValue(value, elem, &objectExportCtx{})
+	})
+	if ex == nil && err != nil {
+		ex = &Exception{val: r.NewGoError(err)}
+	}
+	if ex != nil {
+		r.vm.try(e.iter.returnIter)
+		e.finish(ex)
+		return
+	}
+	go func() {
+		e.ch.Send(elem)
+		e.loop.post(e.step)
+	}()
+}
+
+// finish closes the channel and reports ex, if not nil, either to the error channel set by SetExportErrors, or,
+// if there is none, by stopping the loop.
+func (e *chanExport) finish(ex *Exception) {
+	l := e.loop
+	l.refs--
+	if ex == nil {
+		e.ch.Close()
+		return
+	}
+	if errs := l.exportErrors; errs != nil {
+		go func() {
+			errs <- ex
+			e.ch.Close()
+		}()
+		return
+	}
+	e.ch.Close()
+	if l.uncaught == nil {
+		l.uncaught = ex
+	}
+}
+
+// SetExportErrors sets the channel that receives the errors which stop the iteration of the async iterables
+// exported into Go channels (see Runtime.ExportTo). The error, an *Exception, is sent before the exported channel
+// is closed, so if errs is buffered a receiver can check it as soon as the channel is closed.
+//
+// If errs is nil (the default) such error stops the loop in the same way an exception thrown by a timer callback
+// does, and Run returns it.
+func (l *Loop) SetExportErrors(errs chan<- error) {
+	l.exportErrors = errs
+}
+
+// chanIterObject is the async iterator ToValue creates for a receive-only channel. It receives at most one value
+// at a time, on a separate goroutine, for the oldest pending next() call.
+type chanIterObject struct {
+	baseObject
+	loop *Loop
+	ch   reflect.Value
+
+	// closed by return() to abort the receive in progress
+	cancel    chan struct{}
+	pending   []*promiseCapability
+	receiving bool
+	done      bool
+}
+
+func (l *Loop) newChanIterator(ch reflect.Value) *Object {
+	r := l.r
+	if r.global.ChanIteratorPrototype == nil {
+		r.global.ChanIteratorPrototype = r.newLazyObject(r.createChanIterProto)
+	}
+	o := &Object{runtime: r}
+	ci := &chanIterObject{
+		loop:   l,
+		ch:     ch,
+		cancel: make(chan struct{}),
+	}
+	ci.class = classObject
+	ci.val = o
+	ci.extensible = true
+	o.self = ci
+	ci.prototype = r.global.ChanIteratorPrototype
+	ci.init()
+	return o
+}
+
+func (ci *chanIterObject) exportType() reflect.Type {
+	return ci.ch.Type()
+}
+
+func (ci *chanIterObject) export(*objectExportCtx) interface{} {
+	return ci.ch.Interface()
+}
+
+// receive starts receiving a value for the oldest pending next() call unless it's already in progress. The loop
+// is kept alive until the value is received.
+func (ci *chanIterObject) receive() {
+	if ci.receiving || len(ci.pending) == 0 {
+		return
+	}
+	ci.receiving = true
+	l := ci.loop
+	l.refs++
+	go func() {
+		chosen, v, ok := reflect.Select([]reflect.SelectCase{
+			{Dir: reflect.SelectRecv, Chan: ci.ch},
+			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ci.cancel)},
+		})
+		l.post(func() error {
+			l.refs--
+			ci.receiving = false
+			if !ok && chosen == 0 {
+				ci.finish()
+			}
+			if ci.done {
+				return nil
+			}
+			r := l.r
+			pcap := ci.pending[0]
+			ci.pending = ci.pending[1:]
+			pcap.resolve(r.createIterResultObject(r.ToValue(v.Interface()), false))
+			ci.receive()
+			return nil
+		})
+	}()
+}
+
+// finish resolves all the pending next() calls with a completed result.
+func (ci *chanIterObject) finish() {
+	if !ci.done {
+		ci.done = true
+		close(ci.cancel)
+	}
+	r := ci.loop.r
+	pending := ci.pending
+	ci.pending = nil
+	for _, pcap := range pending {
+		pcap.resolve(r.createIterResultObject(_undefined, true))
+	}
+}
+
+func (r *Runtime) thisChanIter(this Value, method string) *chanIterObject {
+	if o, ok := this.(*Object); ok {
+		if ci, ok := o.self.(*chanIterObject); ok {
+			return ci
+		}
+	}
+	panic(r.NewTypeError("Method channel AsyncIterator.prototype.%s called on incompatible receiver %s", method, r.objectproto_toString(FunctionCall{This: this})))
+}
+
+func (r *Runtime) chanIterProto_next(call FunctionCall) Value {
+	ci := r.thisChanIter(call.This, "next")
+	pcap := r.newPromiseCapability(r.global.Promise)
+	if ci.done {
+		pcap.resolve(r.createIterResultObject(_undefined, true))
+		return pcap.promise
+	}
+	ci.pending = append(ci.pending, pcap)
+	ci.receive()
+	return pcap.promise
+}
+
+func (r *Runtime) chanIterProto_return(call FunctionCall) Value {
+	ci := r.thisChanIter(call.This, "return")
+	ci.finish()
+	pcap := r.newPromiseCapability(r.global.Promise)
+	pcap.resolve(r.createIterResultObject(call.Argument(0), true))
+	return pcap.promise
+}
+
+func (r *Runtime) createChanIterProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.AsyncIteratorPrototype, classObject)
+
+	o._putProp("next", r.newNativeFunc(r.chanIterProto_next, nil, "next", nil, 0), true, true, true)
+	o._putProp("return", r.newNativeFunc(r.chanIterProto_return, nil, "return", nil, 1), true, true, true)
+
+	return o
+}
@@ -0,0 +1,206 @@
+package goja
+
+import (
+	"testing"
+)
+
+func collectChan(ch <-chan int) chan []int {
+	res := make(chan []int, 1)
+	go func() {
+		var a []int
+		for v := range ch {
+			a = append(a, v)
+		}
+		res <- a
+	}()
+	return res
+}
+
+func TestExportToChan(t *testing.T) {
+	r := New()
+	l := NewLoop(r)
+	var received chan []int
+	err := l.Run(func(r *Runtime) {
+		v, err := r.RunString(`
+		var values = [1, "2", 3];
+		({
+			[Symbol.asyncIterator]() {
+				return {
+					next() {
+						return new Promise(resolve => setTimeout(() => {
+							resolve(values.length > 0 ? { value: values.shift() } : { done: true });
+						}, 1));
+					}
+				};
+			}
+		});
+		`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ch <-chan int
+		if err := r.ExportTo(v, &ch); err != nil {
+			t.Fatal(err)
+		}
+		received = collectChan(ch)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a := <-received; len(a) != 3 || a[0] != 1 || a[1] != 2 || a[2] != 3 {
+		t.Fatal(a)
+	}
+}
+
+func TestExportToChanErrors(t *testing.T) {
+	r := New()
+	l := NewLoop(r)
+	errs := make(chan error, 1)
+	l.SetExportErrors(errs)
+	var received chan []int
+	err := l.Run(func(r *Runtime) {
+		v, err := r.RunString(`
+		var closed = false;
+		var values = [1, Symbol(), 2];
+		({
+			[Symbol.iterator]() {
+				return {
+					next() {
+						return values.length > 0 ? { value: values.shift() } : { done: true };
+					},
+					return() {
+						closed = true;
+						return {};
+					}
+				};
+			}
+		});
+		`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ch chan int
+		if err := r.ExportTo(v, &ch); err != nil {
+			t.Fatal(err)
+		}
+		received = collectChan(ch)
+		var sendOnly chan<- int
+		if err := r.ExportTo(v, &sendOnly); err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a := <-received; len(a) != 1 || a[0] != 1 {
+		t.Fatal(a)
+	}
+	if err := <-errs; err == nil {
+		t.Fatal("expected an error")
+	}
+	if !r.Get("closed").ToBoolean() {
+		t.Fatal("the iterator has not been closed")
+	}
+
+	l.SetExportErrors(nil)
+	err = l.Run(func(r *Runtime) {
+		v, err := r.RunString(`
+		var n = 0;
+		({
+			[Symbol.asyncIterator]() {
+				return {
+					async next() {
+						if (n++ > 0) throw new Error("boom");
+						return { value: n };
+					}
+				};
+			}
+		});
+		`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ch <-chan int
+		if err := r.ExportTo(v, &ch); err != nil {
+			t.Fatal(err)
+		}
+		received = collectChan(ch)
+	})
+	if ex, ok := err.(*Exception); !ok || ex.Value().String() != "Error: boom" {
+		t.Fatal(err)
+	}
+	if a := <-received; len(a) != 1 {
+		t.Fatal(a)
+	}
+}
+
+func TestChanToValue(t *testing.T) {
+	r := New()
+	l := NewLoop(r)
+	ch := make(chan int)
+	go func() {
+		for i := 1; i <= 3; i++ {
+			ch <- i
+		}
+		close(ch)
+	}()
+	err := l.Run(func(r *Runtime) {
+		r.Set("ch", (<-chan int)(ch))
+		_, err := r.RunString(`
+		var received = [];
+		(async function() {
+			for await (const v of ch) {
+				received.push(v);
+			}
+			received.push(await ch.next());
+		})();
+		`)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := r.RunString(`received.length === 4 && received.slice(0, 3).join() === "1,2,3" && received[3].done`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.ToBoolean() {
+		t.Fatal(r.Get("received"))
+	}
+	if exp, ok := r.Get("ch").Export().(<-chan int); !ok || exp != ch {
+		t.Fatal(exp)
+	}
+}
+
+func TestChanToValueReturn(t *testing.T) {
+	r := New()
+	l := NewLoop(r)
+	ch := make(chan string, 1)
+	ch <- "first"
+	err := l.Run(func(r *Runtime) {
+		r.Set("ch", (<-chan string)(ch))
+		_, err := r.RunString(`
+		var received = [];
+		(async function() {
+			for await (const v of ch) {
+				received.push(v);
+				break;
+			}
+		})();
+		`)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := r.Get("received").String(); s != "first" {
+		t.Fatal(s)
+	}
+	if _, ok := r.ToValue(ch).(*Object).self.(*chanIterObject); ok {
+		t.Fatal("a bidirectional channel has been converted into an iterator")
+	}
+}
@@ -40,9 +40,13 @@ const (
 	varTypeLet
 	varTypeStrictConst
 	varTypeConst
+	varTypeImport
 )
 
-const thisBindingName = " this" // must not be a valid identifier
+const (
+	thisBindingName          = " this"     // must not be a valid identifier
+	defaultExportBindingName = "*default*" // must not be a valid identifier
+)
 
 type CompilerError struct {
 	Message string
@@ -84,6 +88,10 @@ type compiler struct {
 	evalVM *vm // VM used to evaluate constant expressions
 	ctxVM  *vm // VM in which an eval() code is compiled
 
+	module *SourceTextModuleRecord // the module being compiled, nil for scripts
+
+	tailCalls bool // emit tail calls for calls in a tail position
+
 	codeScratchpad []instruction
 }
 
@@ -95,6 +103,7 @@ type binding struct {
 	isStrict     bool
 	isArg        bool
 	isVar        bool
+	isImport     bool
 	inStash      bool
 }
 
@@ -130,7 +139,9 @@ func (b *binding) markAccessPoint() {
 
 func (b *binding) emitGet() {
 	b.markAccessPoint()
-	if b.isVar && !b.isArg {
+	if b.isImport {
+		b.scope.c.emit(loadImport(0))
+	} else if b.isVar && !b.isArg {
 		b.scope.c.emit(loadStack(0))
 	} else {
 		b.scope.c.emit(loadStackLex(0))
@@ -139,7 +150,9 @@ func (b *binding) emitGet() {
 
 func (b *binding) emitGetAt(pos int) {
 	b.markAccessPointAt(pos)
-	if b.isVar && !b.isArg {
+	if b.isImport {
+		b.scope.c.p.code[pos] = loadImport(0)
+	} else if b.isVar && !b.isArg {
 		b.scope.c.p.code[pos] = loadStack(0)
 	} else {
 		b.scope.c.p.code[pos] = loadStackLex(0)
@@ -152,7 +165,11 @@ func (b *binding) emitGetP() {
 	} else {
 		// make sure TDZ is checked
 		b.markAccessPoint()
-		b.scope.c.emit(loadStackLex(0), pop)
+		if b.isImport {
+			b.scope.c.emit(loadImport(0), pop)
+		} else {
+			b.scope.c.emit(loadStackLex(0), pop)
+		}
 	}
 }
 
@@ -246,7 +263,9 @@ func (b *binding) emitResolveVar(strict bool) {
 		b.scope.c.emit(&resolveMixed{name: b.name, strict: strict, typ: varTypeVar})
 	} else {
 		var typ varType
-		if b.isConst {
+		if b.isImport {
+			typ = varTypeImport
+		} else if b.isConst {
 			if b.isStrict {
 				typ = varTypeStrictConst
 			} else {
@@ -305,6 +324,8 @@ type scope struct {
 	argsInStash bool
 	// need 'arguments' object (functions only)
 	argsNeeded bool
+	// an async function (functions only)
+	async bool
 }
 
 type block struct {
@@ -316,6 +337,8 @@ type block struct {
 	outer      *block
 	breaking   *block // set when the 'finally' block is an empty break statement sequence
 	needResult bool
+	async      bool     // set for 'for await' loops
+	dispose    *binding // the dispose capability of a block with 'using' declarations
 }
 
 func (c *compiler) leaveScopeBlock(enter *enterBlock) {
@@ -488,7 +511,7 @@ func (s *scope) lookupName(name unistring.String) (binding *binding, noDynamics
 		if curScope.dynamic {
 			noDynamics = false
 		}
-		if name == "arguments" && curScope.funcType != funcNone && curScope.funcType != funcArrow {
+		if name == "arguments" && curScope.funcType != funcNone && curScope.funcType != funcArrow && curScope.funcType != funcModule {
 			if curScope.funcType == funcClsInit {
 				s.c.throwSyntaxError(0, "'arguments' is not allowed in class field initializer or static initialization block")
 			}
@@ -685,6 +708,8 @@ func (s *scope) finaliseVarAlloc(stackOffset int) (stashSize, stackSize int) {
 							*ap = initStashP(idx)
 						case initStack:
 							*ap = initStash(idx)
+						case loadImport:
+							*ap = loadImport(idx)
 						case *loadMixed:
 							i.idx = idx
 						case *loadMixedLex:
@@ -901,6 +926,7 @@ func (c *compiler) compile(in *ast.Program, strict, inGlobal bool, evalVm *vm) {
 
 	eval := evalVm != nil
 	c.p.src = in.File
+	c.checkNoUsingDeclarations(in.Body, "Using declaration is not allowed at the top level of a script")
 	c.newScope()
 	scope := c.scope
 	scope.dynamic = true
@@ -991,6 +1017,279 @@ func (c *compiler) compile(in *ast.Program, strict, inGlobal bool, evalVm *vm) {
 	scope.finaliseVarAlloc(0)
 }
 
+func (c *compiler) compileModule(in *ast.Program, m *SourceTextModuleRecord) {
+	c.module = m
+	c.p.src = in.File
+	c.newScope()
+	c.newScope()
+	scope := c.scope
+	scope.funcType = funcModule
+	scope.strict = true
+	scope.variable = true
+	scope.dynLookup = true // all module bindings are accessible by the importing modules, so they go to the stash
+	scope.createThisBinding()
+
+	var funcs []*ast.FunctionDeclaration
+	var lexicals []ast.Statement
+	var defaultFunc *ast.FunctionLiteral
+	defaultOffset := -1
+	for _, st := range in.Body {
+		switch st := st.(type) {
+		case *ast.ImportDeclaration:
+			c.createImportBindings(st)
+		case *ast.ExportDeclaration:
+			c.createExportEntries(st)
+			switch decl := st.Declaration.(type) {
+			case *ast.FunctionDeclaration:
+				if decl.Function.Name == nil {
+					defaultFunc = decl.Function
+					defaultOffset = int(decl.Idx0()) - 1
+				} else {
+					funcs = append(funcs, decl)
+				}
+			case *ast.ClassDeclaration:
+				if decl.Class.Name == nil {
+					defaultOffset = int(decl.Idx0()) - 1
+				} else {
+					lexicals = append(lexicals, decl)
+				}
+			case *ast.LexicalDeclaration:
+				lexicals = append(lexicals, decl)
+			default:
+				if st.Expression != nil {
+					defaultOffset = int(st.Expression.Idx0()) - 1
+				}
+			}
+		case *ast.FunctionDeclaration:
+			funcs = append(funcs, st)
+		default:
+			lexicals = append(lexicals, st)
+		}
+	}
+	for _, decl := range funcs {
+		c.createLexicalIdBinding(decl.Function.Name.Name, false, int(decl.Function.Name.Idx)-1)
+	}
+	if defaultOffset >= 0 {
+		scope.bindNameLexical(defaultExportBindingName, true, defaultOffset)
+	}
+	c.compileLexicalDeclarations(lexicals, true)
+	for _, decl := range in.DeclarationList {
+		for _, item := range decl.List {
+			c.createBindings(item.Target, func(name unistring.String, offset int) {
+				if b := scope.boundNames[name]; b != nil && !b.isVar {
+					c.throwSyntaxError(offset, "Identifier '%s' has already been declared", name)
+				}
+				c.createVarIdBinding(name, offset, false)
+			})
+		}
+	}
+	c.resolveLocalExports()
+
+	c.compileFunctions(funcs)
+	if defaultFunc != nil {
+		c.emitNamed(c.compileFunctionLiteral(defaultFunc, false), "default")
+		scope.boundNames[defaultExportBindingName].emitInitP()
+	}
+	// The code above instantiates the module environment and is run during linking, the module body
+	// is run during evaluation.
+	initEnd := len(c.p.code)
+	c.emit(nil)
+	c.compileStatements(in.Body, false)
+	c.p.code[initEnd] = jump(len(c.p.code) - initEnd)
+
+	m.stashSize, _ = scope.finaliseVarAlloc(0)
+	m.names = scope.makeNamesMap()
+	m.bodyStart = initEnd + 1
+}
+
+func (c *compiler) moduleRequest(specifier *ast.StringLiteral, with *ast.WithClause) ModuleRequest {
+	req := ModuleRequest{
+		Specifier: specifier.Value.String(),
+	}
+	if with != nil {
+		for _, attr := range with.Attributes {
+			key := attr.Key.Value.String()
+			if _, exists := req.Attribute(key); exists {
+				c.throwSyntaxError(int(attr.Key.Idx)-1, "Duplicate import attribute '%s'", key)
+			}
+			if !isSupportedImportAttribute(key) {
+				c.throwSyntaxError(int(attr.Key.Idx)-1, "Import attribute '%s' is not supported", key)
+			}
+			req.Attributes = append(req.Attributes, ImportAttribute{
+				Key:   key,
+				Value: attr.Value.Value.String(),
+			})
+		}
+		sort.Slice(req.Attributes, func(i, j int) bool {
+			return req.Attributes[i].Key < req.Attributes[j].Key
+		})
+	}
+	return req
+}
+
+func (c *compiler) addRequestedModule(req ModuleRequest) {
+	m := c.module
+	key := req.key()
+	for _, s := range m.requestedModules {
+		if s.key() == key {
+			return
+		}
+	}
+	m.requestedModules = append(m.requestedModules, req)
+}
+
+func (c *compiler) createImportBinding(moduleRequest ModuleRequest, importName string, namespace bool, id *ast.Identifier) {
+	b := c.createLexicalIdBinding(id.Name, true, int(id.Idx)-1)
+	if !namespace {
+		b.isImport = true
+	}
+	c.module.importEntries = append(c.module.importEntries, importEntry{
+		moduleRequest: moduleRequest,
+		importName:    importName,
+		namespace:     namespace,
+		localName:     id.Name,
+	})
+}
+
+func (c *compiler) createImportBindings(v *ast.ImportDeclaration) {
+	specifier := c.moduleRequest(v.ModuleSpecifier, v.With)
+	c.addRequestedModule(specifier)
+	if v.DefaultBinding != nil {
+		c.createImportBinding(specifier, "default", false, v.DefaultBinding)
+	}
+	if v.NamespaceBinding != nil {
+		c.createImportBinding(specifier, "", true, v.NamespaceBinding)
+	}
+	for _, spec := range v.NamedImports {
+		c.createImportBinding(specifier, spec.ImportName.Value.String(), false, spec.Alias)
+	}
+}
+
+func (c *compiler) addExportEntry(list *[]exportEntry, e exportEntry, offset int) {
+	e.offset = offset
+	*list = append(*list, e)
+}
+
+func (c *compiler) createExportEntries(v *ast.ExportDeclaration) {
+	m := c.module
+	offset := int(v.Idx0()) - 1
+	if v.ModuleSpecifier != nil {
+		specifier := c.moduleRequest(v.ModuleSpecifier, v.With)
+		c.addRequestedModule(specifier)
+		if v.Star {
+			if len(v.Specifiers) > 0 {
+				c.addExportEntry(&m.indirectExportEntries, exportEntry{
+					exportName:    v.Specifiers[0].ExportName.Value.String(),
+					moduleRequest: specifier,
+					namespace:     true,
+				}, offset)
+			} else {
+				m.starExportEntries = append(m.starExportEntries, exportEntry{
+					moduleRequest: specifier,
+					offset:        offset,
+				})
+			}
+			return
+		}
+		for _, spec := range v.Specifiers {
+			c.addExportEntry(&m.indirectExportEntries, exportEntry{
+				exportName:    spec.ExportName.Value.String(),
+				moduleRequest: specifier,
+				importName:    spec.LocalName.Value.String(),
+			}, int(spec.Idx0())-1)
+		}
+		return
+	}
+	if v.Specifiers != nil {
+		for _, spec := range v.Specifiers {
+			c.addExportEntry(&m.localExportEntries, exportEntry{
+				exportName: spec.ExportName.Value.String(),
+				localName:  spec.LocalName.Value,
+			}, int(spec.Idx0())-1)
+		}
+		return
+	}
+	if v.Expression != nil {
+		c.addExportEntry(&m.localExportEntries, exportEntry{
+			exportName: "default",
+			localName:  defaultExportBindingName,
+		}, offset)
+		return
+	}
+	addLocal := func(name unistring.String, offset int) {
+		exportName := name.String()
+		if v.Default {
+			exportName = "default"
+		}
+		c.addExportEntry(&m.localExportEntries, exportEntry{
+			exportName: exportName,
+			localName:  name,
+		}, offset)
+	}
+	switch decl := v.Declaration.(type) {
+	case *ast.VariableStatement:
+		for _, item := range decl.List {
+			c.createBindings(item.Target, addLocal)
+		}
+	case *ast.LexicalDeclaration:
+		for _, item := range decl.List {
+			c.createBindings(item.Target, addLocal)
+		}
+	case *ast.FunctionDeclaration:
+		if name := decl.Function.Name; name != nil {
+			addLocal(name.Name, int(name.Idx)-1)
+		} else {
+			addLocal(defaultExportBindingName, offset)
+		}
+	case *ast.ClassDeclaration:
+		if name := decl.Class.Name; name != nil {
+			addLocal(name.Name, int(name.Idx)-1)
+		} else {
+			addLocal(defaultExportBindingName, offset)
+		}
+	}
+}
+
+// resolveLocalExports makes sure all local exports refer to existing bindings and there are no duplicate
+// export names. Exports of imported bindings are turned into indirect exports.
+func (c *compiler) resolveLocalExports() {
+	m := c.module
+	names := make(map[string]struct{}, len(m.localExportEntries)+len(m.indirectExportEntries))
+	checkDup := func(list []exportEntry) {
+		for _, e := range list {
+			if _, exists := names[e.exportName]; exists {
+				c.throwSyntaxError(e.offset, "Duplicate export of '%s'", e.exportName)
+			}
+			names[e.exportName] = struct{}{}
+		}
+	}
+	checkDup(m.localExportEntries)
+	checkDup(m.indirectExportEntries)
+	local := m.localExportEntries[:0]
+	for _, e := range m.localExportEntries {
+		b := c.scope.boundNames[e.localName]
+		if b == nil || e.localName == thisBindingName {
+			c.throwSyntaxError(e.offset, "Export '%s' is not defined in module", e.localName)
+		}
+		if b.isImport {
+			for _, ie := range m.importEntries {
+				if ie.localName == e.localName {
+					m.indirectExportEntries = append(m.indirectExportEntries, exportEntry{
+						exportName:    e.exportName,
+						moduleRequest: ie.moduleRequest,
+						importName:    ie.importName,
+						offset:        e.offset,
+					})
+					break
+				}
+			}
+			continue
+		}
+		local = append(local, e)
+	}
+	m.localExportEntries = local
+}
+
 func (c *compiler) compileDeclList(v []*ast.VariableDeclaration, inFunc bool) {
 	for _, value := range v {
 		c.createVarBindings(value, inFunc)
@@ -1192,7 +1491,7 @@ func (c *compiler) createLexicalBinding(target ast.Expression, isConst bool) {
 
 func (c *compiler) createLexicalBindings(lex *ast.LexicalDeclaration) {
 	for _, d := range lex.List {
-		c.createLexicalBinding(d.Target, lex.Token == token.CONST)
+		c.createLexicalBinding(d.Target, lex.Token == token.CONST || lex.Token == token.USING)
 	}
 }
 
@@ -1218,7 +1517,7 @@ func (c *compiler) compileLexicalDeclarations(list []ast.Statement, scopeDeclare
 func (c *compiler) compileLexicalDeclarationsFuncBody(list []ast.Statement, calleeBinding *binding) {
 	for _, st := range list {
 		if lex, ok := st.(*ast.LexicalDeclaration); ok {
-			isConst := lex.Token == token.CONST
+			isConst := lex.Token == token.CONST || lex.Token == token.USING
 			for _, d := range lex.List {
 				c.createBindings(d.Target, func(name unistring.String, offset int) {
 					c.createLexicalIdBindingFuncBody(name, isConst, offset, calleeBinding)
@@ -1,6 +1,8 @@
 package goja
 
 import (
+	"math/big"
+
 	"github.com/dop251/goja/ast"
 	"github.com/dop251/goja/file"
 	"github.com/dop251/goja/token"
@@ -128,6 +130,7 @@ const (
 	funcClsInit
 	funcCtor
 	funcDerivedCtor
+	funcModule
 )
 
 type compiledFunctionLiteral struct {
@@ -162,6 +165,15 @@ type compiledNewTarget struct {
 	baseCompiledExpr
 }
 
+type compiledImportMeta struct {
+	baseCompiledExpr
+}
+
+type compiledImportCall struct {
+	baseCompiledExpr
+	arg, options compiledExpr
+}
+
 type compiledSequenceExpr struct {
 	baseCompiledExpr
 	sequence []compiledExpr
@@ -293,6 +305,8 @@ func (c *compiler) compileExpression(v ast.Expression) compiledExpr {
 		return c.compileNewExpression(v)
 	case *ast.MetaProperty:
 		return c.compileMetaProperty(v)
+	case *ast.ImportCall:
+		return c.compileImportCall(v)
 	case *ast.ObjectPattern:
 		return c.compileObjectAssignmentPattern(v)
 	case *ast.ArrayPattern:
@@ -1238,12 +1252,44 @@ func (e *compiledAssignExpr) emitGetter(putOnStack bool) {
 			e.right.emitGetter(true)
 			e.c.emit(shr)
 		}, false, putOnStack)
+	case token.LOGICAL_AND, token.LOGICAL_OR, token.COALESCE:
+		e.emitLogical(putOnStack)
 	default:
 		e.c.assert(false, e.offset, "Unknown assign operator: %s", e.operator.String())
 		panic("unreachable")
 	}
 }
 
+// emitLogical emits the code for the logical assignment operators (&&=, ||=, ??=). The reference is evaluated
+// once and, if the current value short-circuits the operator, neither the right side nor the assignment
+// (and therefore no setter) is executed.
+func (e *compiledAssignExpr) emitLogical(putOnStack bool) {
+	e.left.emitRef()
+	e.c.emit(getValue)
+	j := len(e.c.p.code)
+	e.addSrcMap()
+	e.c.emit(nil)
+	if id, ok := e.left.(*compiledIdentifierExpr); ok {
+		e.c.emitNamedOrConst(e.right, id.name)
+	} else {
+		e.c.emitExpr(e.right, true)
+	}
+	e.c.emit(putValue)
+	e.c.emit(jump(2))
+	switch e.operator {
+	case token.LOGICAL_AND:
+		e.c.p.code[j] = jneq1(len(e.c.p.code) - j)
+	case token.LOGICAL_OR:
+		e.c.p.code[j] = jeq1(len(e.c.p.code) - j)
+	default:
+		e.c.p.code[j] = jcoalesc(len(e.c.p.code) - j)
+	}
+	e.c.emit(popRef)
+	if !putOnStack {
+		e.c.emit(pop)
+	}
+}
+
 func (e *compiledLiteral) emitGetter(putOnStack bool) {
 	if putOnStack {
 		e.c.emit(loadVal(e.c.p.defineLiteralValue(e.val)))
@@ -1364,6 +1410,7 @@ func (e *compiledFunctionLiteral) compile() (prg *Program, name unistring.String
 	e.c.newScope()
 	s := e.c.scope
 	s.funcType = e.typ
+	s.async = e.isAsync
 
 	if e.name != nil {
 		name = e.name.Name
@@ -1813,7 +1860,7 @@ func (e *compiledClassLiteral) processClassKey(expr ast.Expression) (privateName
 type clsElement struct {
 	key         unistring.String
 	privateName *privateName
-	initializer compiledExpr
+	initializer ast.Expression
 	body        *compiledFunctionLiteral
 	computed    bool
 }
@@ -1959,9 +2006,9 @@ func (e *compiledClassLiteral) emitGetter(putOnStack bool) {
 		case *ast.FieldDefinition:
 			privateName, key, computed := e.processClassKey(elt.Key)
 			var el clsElement
-			if elt.Initializer != nil {
-				el.initializer = e.c.compileExpression(elt.Initializer)
-			}
+			// The initializer is compiled as part of the fields initialisation function,
+			// so that 'this' and 'super' resolve correctly.
+			el.initializer = elt.Initializer
 			el.computed = computed
 			if computed {
 				if elt.Static {
@@ -2187,7 +2234,8 @@ func (e *compiledClassLiteral) compileFieldsAndStaticBlocks(elements []clsElemen
 				e.c.emit(loadComputedKey(valIdx))
 				valIdx++
 			}
-			if init := elt.initializer; init != nil {
+			if elt.initializer != nil {
+				init := e.c.compileExpression(elt.initializer)
 				if !elt.computed {
 					e.c.emitNamedOrConst(init, elt.key)
 				} else {
@@ -2365,7 +2413,7 @@ func (c *compiler) compileNewExpression(v *ast.NewExpression) compiledExpr {
 }
 
 func (e *compiledNewTarget) emitGetter(putOnStack bool) {
-	if s := e.c.scope.nearestThis(); s == nil || s.funcType == funcNone {
+	if s := e.c.scope.nearestThis(); s == nil || s.funcType == funcNone || s.funcType == funcModule {
 		e.c.throwSyntaxError(e.offset, "new.target expression is not allowed here")
 	}
 	if putOnStack {
@@ -2374,7 +2422,22 @@ func (e *compiledNewTarget) emitGetter(putOnStack bool) {
 	}
 }
 
+func (e *compiledImportMeta) emitGetter(putOnStack bool) {
+	if putOnStack {
+		e.addSrcMap()
+		e.c.emit(&loadImportMeta{module: e.c.module})
+	}
+}
+
 func (c *compiler) compileMetaProperty(v *ast.MetaProperty) compiledExpr {
+	if v.Meta.Name == "import" && v.Property.Name == "meta" {
+		if c.module == nil {
+			c.throwSyntaxError(int(v.Idx0())-1, "Cannot use 'import.meta' outside a module")
+		}
+		r := &compiledImportMeta{}
+		r.init(c, v.Idx0())
+		return r
+	}
 	if v.Meta.Name == "new" || v.Property.Name != "target" {
 		r := &compiledNewTarget{}
 		r.init(c, v.Idx0())
@@ -2384,6 +2447,29 @@ func (c *compiler) compileMetaProperty(v *ast.MetaProperty) compiledExpr {
 	return nil
 }
 
+func (c *compiler) compileImportCall(v *ast.ImportCall) compiledExpr {
+	r := &compiledImportCall{
+		arg: c.compileExpression(v.Argument),
+	}
+	if v.Options != nil {
+		r.options = c.compileExpression(v.Options)
+	}
+	r.init(c, v.Idx0())
+	return r
+}
+
+func (e *compiledImportCall) emitGetter(putOnStack bool) {
+	e.arg.emitGetter(true)
+	if e.options != nil {
+		e.options.emitGetter(true)
+	}
+	e.addSrcMap()
+	e.c.emit(&importCall{referrer: e.c.module, hasOptions: e.options != nil})
+	if !putOnStack {
+		e.c.emit(pop)
+	}
+}
+
 func (e *compiledSequenceExpr) emitGetter(putOnStack bool) {
 	if len(e.sequence) > 0 {
 		for i := 0; i < len(e.sequence)-1; i++ {
@@ -2413,7 +2499,7 @@ func (c *compiler) emitThrow(v Value) {
 	if o, ok := v.(*Object); ok {
 		t := nilSafe(o.self.getStr("name", nil)).toString().String()
 		switch t {
-		case "TypeError":
+		case "TypeError", "RangeError":
 			c.emit(loadDynamic(t))
 			msg := o.self.getStr("message", nil)
 			if msg != nil {
@@ -3193,6 +3279,8 @@ func (c *compiler) compileNumberLiteral(v *ast.NumberLiteral) compiledExpr {
 		val = intToValue(num)
 	case float64:
 		val = floatToValue(num)
+	case *big.Int:
+		val = (*valueBigInt)(num)
 	default:
 		c.assert(false, int(v.Idx)-1, "Unsupported number literal type: %T", v.Value)
 		panic("unreachable")
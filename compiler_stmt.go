@@ -52,6 +52,10 @@ func (c *compiler) compileStatement(v ast.Statement, needResult bool) {
 	case *ast.WithStatement:
 		c.compileWithStatement(v, needResult)
 	case *ast.DebuggerStatement:
+	case *ast.ImportDeclaration:
+		// bindings are created and initialised during module linking
+	case *ast.ExportDeclaration:
+		c.compileExportDeclaration(v)
 	default:
 		c.assert(false, int(v.Idx0())-1, "Unknown statement type: %T", v)
 		panic("unreachable")
@@ -250,6 +254,17 @@ func (c *compiler) compileForHeadLexDecl(decl *ast.LexicalDeclaration, needResul
 }
 
 func (c *compiler) compileLabeledForStatement(v *ast.ForStatement, needResult bool, label unistring.String) {
+	if init, ok := v.Initializer.(*ast.ForLoopInitializerLexicalDecl); ok && init.LexicalDeclaration.Token == token.USING {
+		// the resources are disposed when the loop completes
+		c.compileDisposableBlock(init.LexicalDeclaration.Await, int(v.For)-1, func() {
+			c.compileLabeledForStatementNoDispose(v, needResult, label)
+		})
+		return
+	}
+	c.compileLabeledForStatementNoDispose(v, needResult, label)
+}
+
+func (c *compiler) compileLabeledForStatementNoDispose(v *ast.ForStatement, needResult bool, label unistring.String) {
 	loopBlock := &block{
 		typ:        blockLoop,
 		outer:      c.block,
@@ -404,12 +419,13 @@ func (c *compiler) compileForInto(into ast.ForInto, needResult bool) (enter *ent
 	return
 }
 
-func (c *compiler) compileLabeledForInOfStatement(into ast.ForInto, source ast.Expression, body ast.Statement, iter, needResult bool, label unistring.String) {
+func (c *compiler) compileLabeledForInOfStatement(into ast.ForInto, source ast.Expression, body ast.Statement, iter, async, needResult bool, label unistring.String) {
 	c.block = &block{
 		typ:        blockLoopEnum,
 		outer:      c.block,
 		label:      label,
 		needResult: needResult,
+		async:      async,
 	}
 	enterPos := -1
 	if forDecl, ok := into.(*ast.ForDeclaration); ok {
@@ -449,7 +465,9 @@ func (c *compiler) compileLabeledForInOfStatement(into ast.ForInto, source ast.E
 		}
 		c.popScope()
 	}
-	if iter {
+	if async {
+		c.emit(iterateAsyncP)
+	} else if iter {
 		c.emit(iterateP)
 	} else {
 		c.emit(enumerate)
@@ -459,29 +477,62 @@ func (c *compiler) compileLabeledForInOfStatement(into ast.ForInto, source ast.E
 	}
 	start := len(c.p.code)
 	c.block.cont = start
+	if async {
+		c.emit(iterNextAsync, await{})
+	}
+	next := len(c.p.code)
 	c.emit(nil)
 	enterIterBlock := c.compileForInto(into, needResult)
 	if needResult {
 		c.emit(clearResult)
 	}
-	c.compileStatement(body, needResult)
+	if forDecl, ok := into.(*ast.ForDeclaration); ok && forDecl.IsUsing {
+		// the resource is disposed at the end of each iteration
+		id := forDecl.Target.(*ast.Identifier)
+		b := c.scope.boundNames[id.Name]
+		c.compileDisposableBlock(forDecl.IsAwaitUsing, int(forDecl.Idx)-1, func() {
+			b.emitGet()
+			c.disposeCapability(int(id.Idx) - 1).emitGet()
+			c.emit(addDisposableResource(forDecl.IsAwaitUsing), pop)
+			c.compileStatement(body, needResult)
+		})
+	} else {
+		c.compileStatement(body, needResult)
+	}
 	if enterIterBlock != nil {
 		c.leaveScopeBlock(enterIterBlock)
 		c.popScope()
 	}
 	c.emit(jump(start - len(c.p.code)))
-	if iter {
-		c.p.code[start] = iterNext(len(c.p.code) - start)
+	if async {
+		c.p.code[next] = iterNextAsyncResult(len(c.p.code) - next)
+	} else if iter {
+		c.p.code[next] = iterNext(len(c.p.code) - next)
 	} else {
-		c.p.code[start] = enumNext(len(c.p.code) - start)
+		c.p.code[next] = enumNext(len(c.p.code) - next)
 	}
-	c.emit(enumPop, jump(2))
+	if async {
+		c.emit(enumPop, jump(4))
+	} else {
+		c.emit(enumPop, jump(2))
+	}
+	lb := c.block
 	c.leaveBlock()
-	c.emit(enumPopClose)
+	c.emitEnumPopClose(lb)
+}
+
+// emitEnumPopClose emits the code that removes the iterator of a for-in or a for-of loop from the iterator stack
+// closing it if necessary. For 'for await' loops the result of return() is awaited.
+func (c *compiler) emitEnumPopClose(b *block) {
+	if b.async {
+		c.emit(iterCloseAsync(3), await{}, iterCloseAsyncResult)
+	} else {
+		c.emit(enumPopClose)
+	}
 }
 
 func (c *compiler) compileLabeledForInStatement(v *ast.ForInStatement, needResult bool, label unistring.String) {
-	c.compileLabeledForInOfStatement(v.Into, v.Source, v.Body, false, needResult, label)
+	c.compileLabeledForInOfStatement(v.Into, v.Source, v.Body, false, false, needResult, label)
 }
 
 func (c *compiler) compileForOfStatement(v *ast.ForOfStatement, needResult bool) {
@@ -489,7 +540,7 @@ func (c *compiler) compileForOfStatement(v *ast.ForOfStatement, needResult bool)
 }
 
 func (c *compiler) compileLabeledForOfStatement(v *ast.ForOfStatement, needResult bool, label unistring.String) {
-	c.compileLabeledForInOfStatement(v.Into, v.Source, v.Body, true, needResult, label)
+	c.compileLabeledForInOfStatement(v.Into, v.Source, v.Body, true, v.Await, needResult, label)
 }
 
 func (c *compiler) compileWhileStatement(v *ast.WhileStatement, needResult bool) {
@@ -640,7 +691,7 @@ L:
 		case blockWith:
 			c.emit(leaveWith)
 		case blockLoopEnum:
-			c.emit(enumPopClose)
+			c.emitEnumPopClose(b)
 		}
 	}
 	return block
@@ -733,6 +784,13 @@ func (c *compiler) compileReturnStatement(v *ast.ReturnStatement) {
 	}
 	if v.Argument != nil {
 		c.emitExpr(c.compileExpression(v.Argument), true)
+		if c.canEmitTailCall() {
+			// The result of the expression is the result of the last call, no further processing is needed.
+			// Note, the ret is still emitted below because other branches of the expression may jump to it.
+			if n, ok := c.p.code[len(c.p.code)-1].(call); ok {
+				c.p.code[len(c.p.code)-1] = tailCall(n)
+			}
+		}
 	} else {
 		c.emit(loadUndef)
 	}
@@ -741,7 +799,7 @@ func (c *compiler) compileReturnStatement(v *ast.ReturnStatement) {
 		case blockTry:
 			c.emit(leaveTry{})
 		case blockLoopEnum:
-			c.emit(enumPopClose)
+			c.emitEnumPopClose(b)
 		}
 	}
 	if s := c.scope.nearestFunction(); s != nil && s.funcType == funcDerivedCtor {
@@ -752,6 +810,25 @@ func (c *compiler) compileReturnStatement(v *ast.ReturnStatement) {
 	c.emit(ret)
 }
 
+// canEmitTailCall returns true if tail calls are enabled and a return statement at the current position
+// does not need to do anything other than leaving the function.
+func (c *compiler) canEmitTailCall() bool {
+	if !c.tailCalls {
+		return false
+	}
+	s := c.scope.nearestFunction()
+	if s == nil || s.async || s.funcType == funcDerivedCtor {
+		return false
+	}
+	for b := c.block; b != nil; b = b.outer {
+		switch b.typ {
+		case blockTry, blockLoopEnum:
+			return false
+		}
+	}
+	return true
+}
+
 func (c *compiler) checkVarConflict(name unistring.String, offset int) {
 	for sc := c.scope; sc != nil; sc = sc.outer {
 		if b, exists := sc.boundNames[name]; exists && !b.isVar && !(b.isArg && sc != c.scope) {
@@ -856,15 +933,127 @@ func (c *compiler) compileVariableStatement(v *ast.VariableStatement) {
 }
 
 func (c *compiler) compileLexicalDeclaration(v *ast.LexicalDeclaration) {
+	if v.Token == token.USING {
+		for _, e := range v.List {
+			c.compileUsingBinding(e, v.Await)
+		}
+		return
+	}
 	for _, e := range v.List {
 		c.compileLexicalBinding(e)
 	}
 }
 
+func (c *compiler) compileUsingBinding(expr *ast.Binding, async bool) {
+	target, ok := expr.Target.(*ast.Identifier)
+	c.assert(ok, int(expr.Target.Idx0())-1, "unsupported using binding target: %T", expr.Target)
+	// the binding belongs to the scope outside of the disposable block
+	var b *binding
+	for s := c.scope; s != nil && b == nil; s = s.outer {
+		b = s.boundNames[target.Name]
+	}
+	c.assert(b != nil, int(target.Idx)-1, "Lexical declaration for an unbound name")
+	c.emitNamedOrConst(c.compileExpression(expr.Initializer), target.Name)
+	c.p.addSrcMap(int(target.Idx) - 1)
+	c.disposeCapability(int(target.Idx) - 1).emitGet()
+	c.emit(addDisposableResource(async))
+	b.emitInitP()
+}
+
+// disposeCapability returns the binding that holds the resources of the innermost block with 'using' declarations.
+func (c *compiler) disposeCapability(offset int) *binding {
+	for b := c.block; b != nil; b = b.outer {
+		if b.dispose != nil {
+			return b.dispose
+		}
+	}
+	c.assert(false, offset, "using declaration outside of a disposable block")
+	panic("unreachable")
+}
+
+// hasUsingDeclarations returns true if the list contains 'using' declarations. async is set if any of them is
+// an 'await using' declaration.
+func hasUsingDeclarations(list []ast.Statement) (found, async bool) {
+	for _, st := range list {
+		if lex, ok := st.(*ast.LexicalDeclaration); ok && lex.Token == token.USING {
+			found = true
+			if lex.Await {
+				async = true
+			}
+		}
+	}
+	return
+}
+
+func (c *compiler) checkNoUsingDeclarations(list []ast.Statement, msg string) {
+	for _, st := range list {
+		if lex, ok := st.(*ast.LexicalDeclaration); ok && lex.Token == token.USING {
+			c.throwSyntaxError(int(lex.Idx)-1, msg)
+		}
+	}
+}
+
+// compileDisposableBlock compiles the code emitted by body so that the resources added by the 'using'
+// declarations within it are disposed when the control leaves it, whether normally, by a break, continue,
+// return or by an exception. If async is set, the results of the disposal of the 'await using' resources
+// are awaited.
+func (c *compiler) compileDisposableBlock(async bool, offset int, body func()) {
+	c.block = &block{
+		typ:   blockScope,
+		outer: c.block,
+	}
+	c.newBlockScope()
+	capBinding := c.scope.addBinding(offset)
+	enter := &enterBlock{}
+	c.emit(enter)
+	c.emit(newDisposeCapability)
+	capBinding.emitInitP()
+
+	c.block = &block{
+		typ:     blockTry,
+		outer:   c.block,
+		dispose: capBinding,
+	}
+	lbl := len(c.p.code)
+	c.emit(nil)
+	body()
+	// leaveTry runs the 'finally' block and returns to the jump over the rest of the code
+	c.emit(leaveTry{})
+	lbl2 := len(c.p.code)
+	c.emit(nil)
+	catchOffset := len(c.p.code) - lbl
+	capBinding.emitGet()
+	c.emit(disposeError)
+	c.emit(enterFinally{})
+	finallyOffset := len(c.p.code) - lbl
+	if async {
+		start := len(c.p.code)
+		capBinding.emitGet()
+		next := len(c.p.code)
+		c.emit(nil)
+		// try { await result } catch (e) { record e }
+		c.emit(try{catchOffset: 4}, await{}, pop, jump(3))
+		capBinding.emitGet()
+		c.emit(disposeError, leaveTry{})
+		c.emit(jump(start - len(c.p.code)))
+		c.p.code[next] = disposeNextAsync(len(c.p.code) - next)
+	}
+	capBinding.emitGet()
+	c.emit(disposeResources)
+	c.emit(leaveFinally{})
+	c.p.code[lbl] = try{catchOffset: int32(catchOffset), finallyOffset: int32(finallyOffset)}
+	c.p.code[lbl2] = jump(len(c.p.code) - lbl2)
+	c.leaveBlock()
+
+	c.leaveScopeBlock(enter)
+	c.popScope()
+}
+
 func (c *compiler) isEmptyResult(st ast.Statement) bool {
 	switch st := st.(type) {
 	case *ast.EmptyStatement, *ast.VariableStatement, *ast.LexicalDeclaration, *ast.FunctionDeclaration,
-		*ast.ClassDeclaration, *ast.BranchStatement, *ast.DebuggerStatement:
+		*ast.ClassDeclaration, *ast.BranchStatement, *ast.DebuggerStatement, *ast.ImportDeclaration,
+		*ast.ExportDeclaration:
 		return true
 	case *ast.LabelledStatement:
 		return c.isEmptyResult(st.Statement)
@@ -928,6 +1117,16 @@ func (c *compiler) compileStatementsNeedResult(list []ast.Statement, lastProduci
 }
 
 func (c *compiler) compileStatements(list []ast.Statement, needResult bool) {
+	if found, async := hasUsingDeclarations(list); found {
+		c.compileDisposableBlock(async, int(list[0].Idx0())-1, func() {
+			c.compileStatementsNoDispose(list, needResult)
+		})
+		return
+	}
+	c.compileStatementsNoDispose(list, needResult)
+}
+
+func (c *compiler) compileStatementsNoDispose(list []ast.Statement, needResult bool) {
 	lastProducingIdx, blk := c.scanStatements(list)
 	if blk != nil {
 		needResult = blk.needResult
@@ -1108,6 +1307,7 @@ func (c *compiler) compileSwitchStatement(v *ast.SwitchStatement, needResult boo
 		if s.Test != nil || i != 0 {
 			c.p.code[jumps[i]] = jump(len(c.p.code) - jumps[i])
 		}
+		c.checkNoUsingDeclarations(s.Consequent, "Using declaration is not allowed directly in a case clause")
 		c.compileStatements(s.Consequent, needResult)
 	}
 
@@ -1122,6 +1322,29 @@ func (c *compiler) compileSwitchStatement(v *ast.SwitchStatement, needResult boo
 	c.leaveBlock()
 }
 
+func (c *compiler) compileExportDeclaration(v *ast.ExportDeclaration) {
+	switch {
+	case v.Expression != nil:
+		c.emitNamedOrConst(c.compileExpression(v.Expression), "default")
+		c.p.addSrcMap(int(v.Expression.Idx0()) - 1)
+		c.scope.boundNames[defaultExportBindingName].emitInitP()
+	case v.Declaration != nil:
+		switch decl := v.Declaration.(type) {
+		case *ast.FunctionDeclaration:
+			// functions are initialised when the module is linked
+		case *ast.ClassDeclaration:
+			if decl.Class.Name == nil {
+				c.emitNamed(c.compileClassLiteral(decl.Class, false), "default")
+				c.scope.boundNames[defaultExportBindingName].emitInitP()
+			} else {
+				c.compileClassDeclaration(decl)
+			}
+		default:
+			c.compileStatement(decl, false)
+		}
+	}
+}
+
 func (c *compiler) compileClassDeclaration(v *ast.ClassDeclaration) {
 	c.emitLexicalAssign(v.Class.Name.Name, int(v.Class.Class)-1, c.compileClassLiteral(v.Class, false))
 }
@@ -4527,6 +4527,58 @@ func TestTaggedTemplate(t *testing.T) {
 	testScript(SCRIPT, valueTrue, t)
 }
 
+func TestTaggedTemplateObjectCaching(t *testing.T) {
+	const SCRIPT = `
+	function tag(strings) {
+		return strings;
+	}
+	function f() {
+		return tag` + "`a${1}b`" + `;
+	}
+	const s1 = f(), s2 = f();
+	assert.sameValue(s1, s2, "same call site");
+	assert(s1 !== tag` + "`a${1}b`" + `, "different call site");
+	assert(Object.isFrozen(s1), "strings are frozen");
+	assert(Object.isFrozen(s1.raw), "raw is frozen");
+	assert(compareArray(s1, ["a", "b"]), "cooked");
+	assert(compareArray(s1.raw, ["a", "b"]), "raw");
+
+	const wm = new WeakMap();
+	wm.set(s1, 42);
+	assert.sameValue(wm.get(f()), 42, "WeakMap key");
+	const seen = new Set();
+	for (let i = 0; i < 3; i++) {
+		seen.add(tag` + "`x`" + `);
+	}
+	assert.sameValue(seen.size, 1, "loop");
+	`
+	r := New()
+	r.testScriptWithTestLib(SCRIPT, _undefined, t)
+
+	// the same Program in a different Runtime produces a different object
+	prg := MustCompile("", "function tag(s) { return s; } function f() { return tag`a`; } f()", false)
+	r1 := New()
+	v1, err := r1.RunProgram(prg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v2, err := r1.RunString("f()")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v1 != v2 {
+		t.Fatal("Template objects are not the same in the same runtime")
+	}
+	r2 := New()
+	v3, err := r2.RunProgram(prg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v3 == v1 {
+		t.Fatal("Template objects are shared between runtimes")
+	}
+}
+
 func TestDuplicateGlobalFunc(t *testing.T) {
 	const SCRIPT = `
 	function a(){}
@@ -4790,6 +4842,102 @@ func TestOptChainCallee(t *testing.T) {
 	testScriptWithTestLib(SCRIPT, _undefined, t)
 }
 
+func TestOptChainShortCircuit(t *testing.T) {
+	const SCRIPT = `
+	var a = null;
+	var o = {b: {c() { return this.x; }, x: 5}};
+	var i = 0;
+	assert.sameValue(a?.b.c.d, undefined, "whole chain short-circuits");
+	assert.sameValue(a?.[i++], undefined, "bracket");
+	assert.sameValue(i, 0, "key expression is not evaluated");
+	assert.sameValue(o?.b?.c(), 5, "this is preserved");
+	assert.sameValue((o?.b).x, 5, "parenthesised chain");
+	assert.sameValue(o.b.z?.(), undefined, "optional call");
+	assert.sameValue(delete a?.b, true, "delete");
+	assert.sameValue(true?.5:1, 0.5, "conditional with decimal");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestNullishCoalescing(t *testing.T) {
+	const SCRIPT = `
+	var opts = {timeout: 0, name: ""};
+	var calls = 0;
+	function dflt() {
+		calls++;
+		return 30;
+	}
+	assert.sameValue(opts.timeout ?? dflt(), 0, "0 is not nullish");
+	assert.sameValue(opts.name ?? dflt(), "", "empty string is not nullish");
+	assert.sameValue(calls, 0, "right side is not evaluated");
+	assert.sameValue(opts.retries ?? dflt(), 30, "undefined");
+	assert.sameValue(null ?? undefined ?? 5, 5, "chained");
+	assert.sameValue((false || null) ?? 7, 7, "parenthesised logical expression");
+	assert.sameValue(calls, 1);
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestLogicalAssignment(t *testing.T) {
+	const SCRIPT = `
+	var a = 0, b = 1, c = null, d = "x";
+	a ||= 5;
+	b &&= 7;
+	c ??= 9;
+	d ??= 10;
+	assert.sameValue(a, 5, "||=");
+	assert.sameValue(b, 7, "&&=");
+	assert.sameValue(c, 9, "??=");
+	assert.sameValue(d, "x", "??= with a non-nullish value");
+
+	var sets = 0, gets = 0;
+	var o = {
+		get x() { gets++; return 1; },
+		set x(v) { sets++; }
+	};
+	assert.sameValue(o.x ||= 2, 1, "result of short-circuited ||=");
+	o.x ??= 3;
+	assert.sameValue(sets, 0, "setter is not called when short-circuited");
+	assert.sameValue(gets, 2);
+	o.x &&= 4;
+	assert.sameValue(sets, 1, "setter is called");
+	assert.sameValue(gets, 3, "getter is called once");
+
+	var keys = 0;
+	function key() {
+		keys++;
+		return "p";
+	}
+	var p = {};
+	p[key()] ??= 1;
+	p[key()] ??= 2;
+	assert.sameValue(p.p, 1);
+	assert.sameValue(keys, 2, "key is evaluated once per assignment");
+
+	var s = Symbol();
+	p[s] ||= "sym";
+	assert.sameValue(p[s], "sym", "symbol key");
+	assert.sameValue(Object.keys(p).length, 1, "symbol key is not converted to a string");
+
+	var f;
+	f ||= function() {};
+	assert.sameValue(f.name, "f", "anonymous function is named");
+
+	const k = 1;
+	k ||= 2;
+	assert.throws(TypeError, function() {
+		"use strict";
+		const k1 = 0;
+		k1 ||= 2;
+	}, "assignment to const");
+	assert.throws(ReferenceError, function() {
+		"use strict";
+		undeclared ??= 1;
+	}, "unresolvable reference");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
 func TestObjectLiteralSuper(t *testing.T) {
 	const SCRIPT = `
 	const proto = {
@@ -4861,6 +5009,55 @@ func TestClassDynCaptureThisInStaticFieldInit(t *testing.T) {
 	testScript(SCRIPT, valueTrue, t)
 }
 
+func TestClassSuperInFieldInit(t *testing.T) {
+	const SCRIPT = `
+	class P {
+		get v() {
+			return "p";
+		}
+	}
+
+	class C extends P {
+		a = super.v;
+		b = () => super.v;
+		static s = super.name;
+	}
+
+	let c = new C();
+	assert.sameValue(c.a, "p", "instance field");
+	assert.sameValue(c.b(), "p", "arrow function in instance field");
+	assert.sameValue(C.s, "P", "static field");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestClassFieldInitOrder(t *testing.T) {
+	const SCRIPT = `
+	const order = [];
+
+	class A {
+		a = order.push("a");
+		[(order.push("key"), "k")] = order.push("k");
+		static s = order.push("static");
+	}
+
+	class B extends A {
+		b = order.push("b");
+		constructor() {
+			order.push("before super");
+			super();
+			order.push("after super");
+		}
+	}
+
+	order.push("new");
+	const b = new B();
+	assert.sameValue(order.join(), "key,static,new,before super,a,k,b,after super");
+	assert.sameValue(Object.keys(b).join(), "a,k,b");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
 func TestCompileClass(t *testing.T) {
 	const SCRIPT = `
 	class C extends Error {
@@ -4891,6 +5088,35 @@ func TestCompileClass(t *testing.T) {
 	testScript(SCRIPT, valueTrue, t)
 }
 
+func TestClassStaticBlocks(t *testing.T) {
+	const SCRIPT = `
+	const order = [];
+	var v = "outer";
+	class A {
+		static a = order.push("a");
+		static {
+			var v = "inner";
+			order.push("block1:" + (this === A));
+		}
+		static b = order.push("b");
+		static #p = 7;
+		static {
+			order.push("block2:" + this.#p + ":" + v);
+		}
+		static {
+			function f() {
+				return arguments.length;
+			}
+			this.n = f(1, 2);
+		}
+	}
+	assert.sameValue(order.join(), "a,block1:true,b,block2:7:outer");
+	assert.sameValue(v, "outer", "var declarations are scoped to the block");
+	assert.sameValue(A.n, 2, "arguments in a nested function");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
 func TestSuperInEval(t *testing.T) {
 	const SCRIPT = `
 	class C extends Error {
@@ -5531,6 +5757,120 @@ func TestPrivateIn(t *testing.T) {
 	testScript(SCRIPT, valueTrue, t)
 }
 
+func TestPrivateFieldBrandCheck(t *testing.T) {
+	const SCRIPT = `
+	class Base {
+		constructor(o) {
+			return o;
+		}
+	}
+	class Stamp extends Base {
+		#tag = "stamped";
+		static tag(o) {
+			return o.#tag;
+		}
+		static has(o) {
+			return #tag in o;
+		}
+	}
+	const plain = {};
+	new Stamp(plain);
+	assert.sameValue(Stamp.tag(plain), "stamped", "field added to a returned object");
+	assert(Stamp.has(plain), "#tag in plain");
+	assert(!Stamp.has({}), "#tag in {}");
+	assert(!Stamp.has(new Proxy(new Stamp({}), {})), "proxies do not forward private names");
+	assert.throws(TypeError, function() {
+		new Stamp(plain);
+	}, "field is added twice");
+	assert.throws(TypeError, function() {
+		Stamp.tag({});
+	}, "read from an object without the field");
+	assert.throws(TypeError, function() {
+		Stamp.has(1);
+	}, "'in' with a primitive");
+
+	const frozen = Object.freeze({});
+	new Stamp(frozen);
+	assert.sameValue(Stamp.tag(frozen), "stamped", "field added to a frozen object");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestPrivateMethodsAndStaticMembers(t *testing.T) {
+	const SCRIPT = `
+	class A {
+		static #x = 1;
+		static get #y() {
+			return 2;
+		}
+		static #m() {
+			return 3;
+		}
+		static get() {
+			return this.#x;
+		}
+		static sum() {
+			return this.#y + this.#m();
+		}
+	}
+	class B extends A {}
+	assert.sameValue(A.get(), 1, "static private field");
+	assert.sameValue(A.sum(), 5, "static private accessor and method");
+	assert.throws(TypeError, function() {
+		B.get();
+	}, "static private field via subclass");
+	assert.throws(TypeError, function() {
+		B.sum();
+	}, "static private method via subclass");
+
+	class C {
+		#v = 0;
+		#m() {
+			return this;
+		}
+		get #acc() {
+			return this.#v;
+		}
+		set #acc(v) {
+			this.#v = v;
+		}
+		get #ro() {
+			return 1;
+		}
+		static call(o) {
+			return o.#m();
+		}
+		static ref(o) {
+			return o.#m;
+		}
+		static inc(o) {
+			o.#acc++;
+			return o.#acc;
+		}
+		static assignMethod(o) {
+			o.#m = null;
+		}
+		static assignRo(o) {
+			o.#ro = 2;
+		}
+	}
+	const c = new C();
+	assert.sameValue(C.call(c), c, "private method 'this'");
+	assert.sameValue(C.ref(c), C.ref(new C()), "private methods are shared");
+	assert.sameValue(C.inc(c), 1, "private accessor");
+	assert.throws(TypeError, function() {
+		C.call({});
+	}, "brand check");
+	assert.throws(TypeError, function() {
+		C.assignMethod(c);
+	}, "private methods are not writable");
+	assert.throws(TypeError, function() {
+		C.assignRo(c);
+	}, "private getter without a setter");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
 func TestDeletePropOfNonObject(t *testing.T) {
 	const SCRIPT = `
 	delete 'Test262'[100] && delete 'Test262'.a && delete 'Test262'['@'];
@@ -5727,6 +6067,165 @@ func TestAsyncFunc(t *testing.T) {
 	testAsyncFunc(SCRIPT, valueTrue, t)
 }
 
+func TestForAwaitOf(t *testing.T) {
+	const SCRIPT = `
+	function makeAsyncIterable(n, log) {
+		var o = {};
+		o[Symbol.asyncIterator] = function() {
+			var i = 0;
+			return {
+				next: function() {
+					i++;
+					return Promise.resolve({value: i, done: i > n});
+				},
+				return: function() {
+					log.push("return");
+					return Promise.resolve({});
+				}
+			};
+		};
+		return o;
+	}
+
+	var out = [];
+	for await (const x of [1, Promise.resolve(2), 3]) {
+		out.push(x);
+	}
+	assert(compareArray(out, [1, 2, 3]), "sync iterable");
+
+	out = [];
+	for await (var x of makeAsyncIterable(3, out)) {
+		out.push(x);
+	}
+	assert(compareArray(out, [1, 2, 3]), "async iterable");
+
+	out = [];
+	for await (let x of makeAsyncIterable(3, out)) {
+		out.push(x);
+		if (x === 2) {
+			break;
+		}
+	}
+	assert(compareArray(out, [1, 2, "return"]), "break");
+
+	out = [];
+	outer: for (var i = 0; i < 2; i++) {
+		for await (const x of makeAsyncIterable(3, out)) {
+			out.push(x);
+			continue outer;
+		}
+	}
+	assert(compareArray(out, [1, "return", 1, "return"]), "continue outer");
+
+	out = [];
+	async function f() {
+		for await (const x of makeAsyncIterable(3, out)) {
+			return x + 41;
+		}
+	}
+	assert.sameValue(await f(), 42, "return value");
+	assert(compareArray(out, ["return"]), "return");
+
+	out = [];
+	try {
+		for await (const x of makeAsyncIterable(3, out)) {
+			throw new Error("boom");
+		}
+	} catch (e) {
+		out.push(e.message);
+	}
+	assert(compareArray(out, ["return", "boom"]), "throw");
+
+	var obj = {};
+	obj[Symbol.asyncIterator] = function() {
+		return {
+			next: function() {
+				return 1;
+			}
+		};
+	};
+	try {
+		for await (const x of obj) {}
+		throw new Error("should have thrown");
+	} catch (e) {
+		assert(e instanceof TypeError, "non-object result");
+	}
+
+	try {
+		for await (const x of [Promise.reject(new Error("rejected"))]) {}
+		throw new Error("should have thrown");
+	} catch (e) {
+		assert.sameValue(e.message, "rejected", "rejected value");
+	}
+	`
+	testAsyncFuncWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestForAwaitOfSyntax(t *testing.T) {
+	for _, src := range []string{
+		"function f() { for await (const x of []) {} }",
+		"async function f() { for await (const x in {}) {} }",
+		"async function f() { for await (var i = 0; i < 1; i++) {} }",
+		"for await (const x of []) {}",
+	} {
+		if _, err := Compile("", src, false); err == nil {
+			t.Errorf("Expected a syntax error for %q", src)
+		}
+	}
+}
+
+func TestArrayFromAsync(t *testing.T) {
+	const SCRIPT = `
+	var asyncIterable = {};
+	asyncIterable[Symbol.asyncIterator] = function() {
+		var i = 0;
+		return {
+			next: function() {
+				i++;
+				return Promise.resolve({value: i, done: i > 3});
+			}
+		};
+	};
+
+	assert(compareArray(await Array.fromAsync(asyncIterable), [1, 2, 3]), "async iterable");
+	assert(compareArray(await Array.fromAsync([Promise.resolve(1), 2]), [1, 2]), "sync iterable");
+	assert(compareArray(await Array.fromAsync({length: 2, 0: "a", 1: Promise.resolve("b")}), ["a", "b"]), "array-like");
+	assert(compareArray(await Array.fromAsync([1, 2], async function(v, k) {
+		return v * 10 + k;
+	}), [10, 21]), "mapping function");
+
+	var thisArg = {};
+	await Array.fromAsync([1], function() {
+		assert.sameValue(this, thisArg, "thisArg");
+	}, thisArg);
+
+	class MyArray {}
+	var res = await Array.fromAsync.call(MyArray, [1, 2]);
+	assert(res instanceof MyArray, "constructor");
+	assert.sameValue(res.length, 2, "length");
+
+	var p = Array.fromAsync([], 1);
+	assert(p instanceof Promise, "returns a Promise");
+	try {
+		await p;
+		throw new Error("should have thrown");
+	} catch (e) {
+		assert(e instanceof TypeError, "non-callable mapping function");
+	}
+	`
+	testAsyncFuncWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestSymbolAsyncIterator(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue(typeof Symbol.asyncIterator, "symbol", "typeof");
+	assert.sameValue(Symbol.asyncIterator.toString(), "Symbol(Symbol.asyncIterator)", "toString");
+	var desc = Object.getOwnPropertyDescriptor(Symbol, "asyncIterator");
+	assert(!desc.writable && !desc.enumerable && !desc.configurable, "descriptor");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
 func TestObjectLiteralComputedMethodKeys(t *testing.T) {
 	_, err := Compile("", `
 		({
@@ -5749,6 +6248,259 @@ func TestObjectLiteralComputedMethodKeys(t *testing.T) {
 	}
 }
 
+func TestUsingDeclaration(t *testing.T) {
+	const SCRIPT = `
+	var log = [];
+	function res(name) {
+		return {
+			[Symbol.dispose]() {
+				log.push("dispose " + name);
+			}
+		};
+	}
+	function f() {
+		using a = res("a"), b = res("b");
+		using n = null;
+		{
+			using c = res("c");
+			log.push("block");
+		}
+		log.push("body");
+		return "ret";
+	}
+	log.push(f());
+	assert(compareArray(log, ["block", "dispose c", "body", "dispose b", "dispose a", "ret"]), "block: " + log);
+
+	log = [];
+	L: for (using x of [res(1), res(2), res(3)]) {
+		log.push("iter");
+		if (log.length > 4) {
+			break L;
+		}
+		continue;
+	}
+	assert(compareArray(log, ["iter", "dispose 1", "iter", "dispose 2", "iter", "dispose 3"]), "for-of: " + log);
+
+	log = [];
+	for (using y = res("y"), i = null; ; ) {
+		log.push("loop");
+		break;
+	}
+	assert(compareArray(log, ["loop", "dispose y"]), "for: " + log);
+
+	assert.throws(TypeError, function() {
+		using x = {};
+	});
+	assert.throws(TypeError, function() {
+		using x = 1;
+	});
+
+	var using = 1;
+	using = using + 1;
+	assert.sameValue(using, 2, "using as an identifier");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestUsingDeclarationSuppressedError(t *testing.T) {
+	const SCRIPT = `
+	function thrower(msg) {
+		return {
+			[Symbol.dispose]() {
+				throw new Error(msg);
+			}
+		};
+	}
+	try {
+		(function() {
+			using a = thrower("a");
+			using b = thrower("b");
+			throw new Error("body");
+		})();
+		throw new Error("should not reach");
+	} catch (e) {
+		assert(e instanceof SuppressedError, "e instanceof SuppressedError");
+		assert.sameValue(e.error.message, "a", "e.error");
+		assert(e.suppressed instanceof SuppressedError, "e.suppressed instanceof SuppressedError");
+		assert.sameValue(e.suppressed.error.message, "b", "e.suppressed.error");
+		assert.sameValue(e.suppressed.suppressed.message, "body", "e.suppressed.suppressed");
+	}
+
+	var disposed = false;
+	assert.throws(Error, function() {
+		using a = {
+			[Symbol.dispose]() {
+				disposed = true;
+			}
+		};
+		throw new Error();
+	});
+	assert(disposed, "disposed on throw");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestAwaitUsingDeclaration(t *testing.T) {
+	const SCRIPT = `
+	var log = [];
+	async function f() {
+		await using a = {
+			async [Symbol.asyncDispose]() {
+				await null;
+				log.push("async dispose a");
+			}
+		}, n = null;
+		await using b = {
+			[Symbol.dispose]() {
+				log.push("dispose b");
+			}
+		};
+		using c = {
+			[Symbol.dispose]() {
+				log.push("dispose c");
+			}
+		};
+		log.push("body");
+	}
+	await f();
+	assert(compareArray(log, ["body", "dispose c", "dispose b", "async dispose a"]), "log: " + log);
+
+	var err;
+	try {
+		await using x = {
+			[Symbol.asyncDispose]() {
+				return Promise.reject(new Error("rejected"));
+			}
+		};
+	} catch (e) {
+		err = e;
+	}
+	assert.sameValue(err.message, "rejected");
+	`
+	testAsyncFuncWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestUsingDeclarationSyntaxErrors(t *testing.T) {
+	for _, src := range []string{
+		"using x = {};",
+		"function f() { using x; }",
+		"function f() { using {a} = {}; }",
+		"function f() { switch (1) { case 1: using x = null; } }",
+		"function f() { for (using x in {}); }",
+		"function f() { await using x = null; }",
+	} {
+		if _, err := Compile("", src, false); err == nil {
+			t.Fatalf("expected error for %q", src)
+		}
+	}
+	for _, src := range []string{
+		"var using; using\nx = 1;",
+		"var using = []; for (using of []);",
+		"function f() { using\nx = 1; } var x;",
+	} {
+		if _, err := Compile("", src, false); err != nil {
+			t.Fatalf("%q: %v", src, err)
+		}
+	}
+}
+
+func TestTailCalls(t *testing.T) {
+	const SCRIPT = `
+	function sum(n, acc) {
+		if (n === 0) {
+			return acc;
+		}
+		return sum(n - 1, acc + n);
+	}
+	function even(n) {
+		return n === 0 ? true : odd(n - 1);
+	}
+	function odd(n) {
+		return n === 0 ? false : even(n - 1);
+	}
+	const fact = (n, acc = 1) => n <= 1 ? acc : fact(n - 1, acc * n);
+	var o = {
+		m(n) {
+			if (n > 0) {
+				return this.m(n - 1);
+			}
+			return this === o;
+		}
+	};
+	function thrower() {
+		throw new Error("thrown");
+	}
+	function callThrower() {
+		return thrower();
+	}
+	function notCallable() {
+		var x = 1;
+		return x();
+	}
+	function max(n) {
+		return Math.max(n, 1);
+	}
+
+	assert.sameValue(sum(100000, 0), 5000050000);
+	assert.sameValue(even(100001), false);
+	assert.sameValue(fact(20), 2432902008176640000);
+	assert(o.m(100000), "method");
+	assert.sameValue(max(5), 5);
+	assert.throws(TypeError, notCallable);
+	try {
+		callThrower();
+		throw new Error("should not reach");
+	} catch (e) {
+		assert.sameValue(e.message, "thrown");
+	}
+	`
+	r := New()
+	r.SetTailCalls(true)
+	r.SetMaxCallStackSize(100)
+	// testScriptWithTestLib() uses Compile() which ignores the setting
+	if _, err := r.RunProgram(testLib()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.RunString(SCRIPT); err != nil {
+		t.Fatal(err)
+	}
+
+	fn, ok := AssertFunction(r.Get("sum"))
+	if !ok {
+		t.Fatal("sum is not a function")
+	}
+	res, err := fn(nil, r.ToValue(100000), r.ToValue(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.ToInteger() != 5000050000 {
+		t.Fatalf("Unexpected result: %v", res)
+	}
+}
+
+func TestTailCallsNotInTailPosition(t *testing.T) {
+	for _, src := range []string{
+		"function f(n) { return n > 0 ? f(n - 1) + 0 : 0; } f(1000)",
+		"function f(n) { try { return n > 0 ? f(n - 1) : 0; } finally {} } f(1000)",
+		"function f(n) { for (var x of [1]) { return n > 0 ? f(n - 1) : 0; } } f(1000)",
+	} {
+		r := New()
+		r.SetTailCalls(true)
+		r.SetMaxCallStackSize(100)
+		_, err := r.RunString(src)
+		if _, ok := err.(*StackOverflowError); !ok {
+			t.Fatalf("%q: expected StackOverflowError, got %v", src, err)
+		}
+	}
+
+	r := New()
+	r.SetMaxCallStackSize(100)
+	_, err := r.RunString("function f(n) { return n > 0 ? f(n - 1) : 0; } f(1000)")
+	if _, ok := err.(*StackOverflowError); !ok {
+		t.Fatalf("expected StackOverflowError when tail calls are disabled, got %v", err)
+	}
+}
+
 /*
 func TestBabel(t *testing.T) {
 	src, err := os.ReadFile("babel7.js")
@@ -23,6 +23,10 @@ const (
 type dateObject struct {
 	baseObject
 	msec int64
+
+	// the time.Time the Date has been created from by ToValue (see SetTimeToDate), it is exported instead of the
+	// local time while the Date keeps the same value
+	goTime *time.Time
 }
 
 type dateLayoutDesc struct {
@@ -53,6 +57,26 @@ var (
 
 		{layout: "2006T15:04:05Z0700"},
 		{layout: "2006-01T15:04:05Z0700"},
+
+		// non-ISO formats accepted by browsers, the values without an offset are in local time
+		{layout: "2006-01-02 15:04"},
+		{layout: "2006-1-2"},
+		{layout: "2006-1-2 15:04"},
+		{layout: "2006-1-2 15:04:05"},
+		{layout: "2006/1/2"},
+		{layout: "2006/1/2 15:04"},
+		{layout: "2006/1/2 15:04:05"},
+		{layout: "1/2/2006"},
+		{layout: "1/2/2006 15:04"},
+		{layout: "1/2/2006 15:04:05"},
+		{layout: "1/2/2006, 15:04:05"},
+		{layout: "2 Jan 2006"},
+		{layout: "2 Jan 2006 15:04"},
+		{layout: "2 Jan 2006 15:04:05"},
+		{layout: "2 Jan 2006 15:04 -0700"},
+		{layout: "2 Jan 2006 15:04:05 -0700"},
+		{layout: "2 Jan 2006 15:04 MST"},
+		{layout: "2 Jan 2006 15:04:05 MST"},
 	}
 
 	dateLayoutsAlpha = []dateLayoutDesc{
@@ -65,6 +89,16 @@ var (
 		{layout: "Mon, _2 Jan 2006 15:04:05 GMT-0700 (MST)"},
 		{layout: "Mon, _2 Jan 2006 15:04:05 -0700 (MST)"},
 		{layout: "Jan _2, 2006", dateOnly: true},
+
+		{layout: "Mon, 2 Jan 2006 15:04 -0700"},
+		{layout: "Mon, 2 Jan 2006 15:04 MST"},
+		{layout: "Mon Jan 02 2006 15:04:05 GMT-0700"},
+		{layout: "Mon Jan 02 2006 15:04:05"},
+		{layout: "Mon Jan 02 2006"},
+		{layout: "Jan 2, 2006 15:04"},
+		{layout: "Jan 2, 2006 15:04:05"},
+		{layout: "Jan 2 2006"},
+		{layout: "Jan 2 2006 15:04:05"},
 	}
 )
 
@@ -101,6 +135,20 @@ func dateParse(date string) (time.Time, bool) {
 	return t, unix >= -maxTime && unix <= maxTime
 }
 
+// dateParse parses the date using the built-in layouts and then the ones set by Runtime.SetDateParseLayouts.
+func (r *Runtime) dateParse(date string) (time.Time, bool) {
+	if t, ok := dateParse(date); ok || len(r.dateParseLayouts) == 0 {
+		return t, ok
+	}
+	for _, layout := range r.dateParseLayouts {
+		if t, err := parseDate(layout, date, time.Local); err == nil {
+			unix := timeToMsec(t)
+			return t, unix >= -maxTime && unix <= maxTime
+		}
+	}
+	return time.Time{}, false
+}
+
 func (r *Runtime) newDateObject(t time.Time, isSet bool, proto *Object) *Object {
 	v := &Object{runtime: r}
 	d := &dateObject{}
@@ -118,6 +166,18 @@ func (r *Runtime) newDateObject(t time.Time, isSet bool, proto *Object) *Object
 	return v
 }
 
+// newDateFromGoTime creates a Date which exports to t while its value is not changed. The Date is invalid if t is
+// out of the range of Date.
+func (r *Runtime) newDateFromGoTime(t time.Time) *Object {
+	t = t.Round(0)
+	// checking the seconds first, so that timeToMsec does not overflow
+	sec, msec := t.Unix(), timeToMsec(t)
+	isSet := sec >= -maxTime/1000-1 && sec <= maxTime/1000+1 && msec >= -maxTime && msec <= maxTime
+	v := r.newDateObject(t, isSet, r.global.DatePrototype)
+	v.self.(*dateObject).goTime = &t
+	return v
+}
+
 func dateFormat(t time.Time) string {
 	return t.Local().Format(dateTimeLayout)
 }
@@ -137,16 +197,32 @@ func (d *dateObject) toPrimitive() Value {
 }
 
 func (d *dateObject) exportType() reflect.Type {
-	return typeTime
+	if d.isSet() {
+		return typeTime
+	}
+	return nil
 }
 
 func (d *dateObject) export(*objectExportCtx) interface{} {
 	if d.isSet() {
-		return d.time()
+		return d.exportedTime()
 	}
 	return nil
 }
 
+// exportedTime returns the value of a valid Date as time.Time. If the Date has been created from a time.Time and
+// has not been modified since, that time.Time is returned, otherwise the result is in its location or in the local
+// time zone.
+func (d *dateObject) exportedTime() time.Time {
+	if t := d.goTime; t != nil {
+		if timeToMsec(*t) == d.msec {
+			return *t
+		}
+		return d.time().In(t.Location())
+	}
+	return d.time()
+}
+
 func (d *dateObject) setTimeMs(ms int64) Value {
 	if ms >= 0 && ms <= maxTime || ms < 0 && ms >= -maxTime {
 		d.msec = ms
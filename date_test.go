@@ -307,3 +307,191 @@ func TestDateExportType(t *testing.T) {
 		t.Fatal(typ)
 	}
 }
+
+func TestDateToLocaleStringTimeZone(t *testing.T) {
+	const SCRIPT = `
+	var d = new Date(Date.UTC(2021, 6, 1, 12, 30, 15));
+	assert.sameValue(d.toLocaleString("en-US", { timeZone: "UTC" }), "07/01/2021, 12:30:15");
+	assert.sameValue(d.toLocaleString(undefined, { timeZone: "Asia/Tokyo" }), "07/01/2021, 21:30:15");
+	assert.sameValue(d.toLocaleString(undefined, { timeZone: "America/New_York" }), "07/01/2021, 08:30:15");
+	assert.sameValue(d.toLocaleDateString(undefined, { timeZone: "Pacific/Kiritimati" }), "07/02/2021");
+	assert.sameValue(d.toLocaleTimeString(undefined, { timeZone: "Europe/London" }), "13:30:15");
+	assert.sameValue(new Date(Date.UTC(2021, 0, 1, 12)).toLocaleTimeString(undefined, { timeZone: "Europe/London" }), "12:00:00");
+	assert.sameValue(d.toLocaleString(undefined, { timeZone: "etc/utc" }), "07/01/2021, 12:30:15");
+	assert.throws(RangeError, function() {
+		d.toLocaleString(undefined, { timeZone: "Mars/Olympus_Mons" });
+	});
+	assert.throws(RangeError, function() {
+		d.toLocaleString("not a locale!");
+	});
+	assert.throws(TypeError, function() {
+		d.toLocaleString(undefined, 1);
+	});
+	assert.sameValue(new Date(NaN).toLocaleString(undefined, { timeZone: "Nowhere" }), "Invalid Date");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestDateParseExtended(t *testing.T) {
+	const SCRIPT = `
+	function testParse(str, expected) {
+		assert.sameValue(Date.parse(str), expected, str);
+	}
+
+	testParse("2021/7/1",							new Date(2021, 6, 1).getTime());
+	testParse("2021/07/01 9:05",					new Date(2021, 6, 1, 9, 5).getTime());
+	testParse("2021/07/01 09:05:10",				new Date(2021, 6, 1, 9, 5, 10).getTime());
+	testParse("7/1/2021",							new Date(2021, 6, 1).getTime());
+	testParse("07/01/2021 13:45:10",				new Date(2021, 6, 1, 13, 45, 10).getTime());
+	testParse("2021-07-01 13:45",					new Date(2021, 6, 1, 13, 45).getTime());
+	testParse("2024-1-2",							new Date(2024, 0, 2).getTime());
+	testParse("2024-1-2 9:05",						new Date(2024, 0, 2, 9, 5).getTime());
+	testParse("2024-10-2 09:05:10",					new Date(2024, 9, 2, 9, 5, 10).getTime());
+	testParse("1 Jul 2021",							new Date(2021, 6, 1).getTime());
+	testParse("1 Jul 2021 13:45:10 +0200",			Date.UTC(2021, 6, 1, 11, 45, 10));
+	testParse("01 Jul 2021 13:45 GMT",				Date.UTC(2021, 6, 1, 13, 45));
+	testParse("Thu, 1 Jul 2021 13:45 +0000",		Date.UTC(2021, 6, 1, 13, 45));
+	testParse("Thu Jul 01 2021 13:45:10 GMT+0200",	Date.UTC(2021, 6, 1, 11, 45, 10));
+	testParse("Thu Jul 01 2021",					new Date(2021, 6, 1).getTime());
+	testParse("July 1, 2021 10:00",					new Date(2021, 6, 1, 10).getTime());
+	testParse("Jul 1 2021",							new Date(2021, 6, 1).getTime());
+
+	var d = new Date(2021, 6, 1, 13, 45, 10);
+	assert.sameValue(Date.parse(d.toDateString()), new Date(2021, 6, 1).getTime(), "toDateString()");
+	assert.sameValue(Date.parse(d.toLocaleString()), d.getTime(), "toLocaleString()");
+
+	testParse("2021/13/01", NaN);
+	testParse("01.07.2021", NaN);
+	`
+
+	l := time.Local
+	defer func() {
+		time.Local = l
+	}()
+	var err error
+	time.Local, err = time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+
+	r := New()
+	r.SetDateParseLayouts("02.01.2006", "20060102T1504Z0700")
+	v, err := r.RunString(`
+	Date.parse("01.07.2021") === new Date(2021, 6, 1).getTime() &&
+	Date.parse("20210701T1345+0100") === Date.UTC(2021, 6, 1, 12, 45) &&
+	new Date("01.07.2021").getDate() === 1 &&
+	isNaN(Date.parse("01-07-2021x"));
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !v.ToBoolean() {
+		t.Fatal("Unexpected result")
+	}
+}
+
+func TestDateExportToTime(t *testing.T) {
+	vm := New()
+	v, err := vm.RunString(`new Date(Date.UTC(2021, 0, 2, 3, 4, 5, 6))`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := time.Date(2021, 1, 2, 3, 4, 5, 6e6, time.UTC)
+
+	var tm time.Time
+	if err := vm.ExportTo(v, &tm); err != nil || !tm.Equal(expected) {
+		t.Fatal(tm, err)
+	}
+	var ptr *time.Time
+	if err := vm.ExportTo(v, &ptr); err != nil || ptr == nil || !ptr.Equal(expected) {
+		t.Fatal(ptr, err)
+	}
+	type myTime time.Time
+	var mt myTime
+	if err := vm.ExportTo(v, &mt); err != nil || !time.Time(mt).Equal(expected) {
+		t.Fatal(time.Time(mt), err)
+	}
+
+	var s struct {
+		T time.Time
+		P *time.Time
+		N time.Time
+		U time.Time
+	}
+	obj, err := vm.RunString(`({ T: new Date(1000), P: new Date(2000), N: 3000, U: undefined })`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.ExportTo(obj, &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.T.UnixNano() != 1e9 || s.P == nil || s.P.UnixNano() != 2e9 || s.N.UnixNano() != 3e9 || !s.U.IsZero() {
+		t.Fatal(s)
+	}
+
+	invalid, err := vm.RunString(`new Date(NaN)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.ExportTo(invalid, &tm); err == nil {
+		t.Fatal("expected an error")
+	}
+	var i interface{}
+	if err := vm.ExportTo(invalid, &i); err != nil || i != nil {
+		t.Fatal(i, err)
+	}
+	if err := vm.ExportTo(vm.ToValue("2021-01-02T03:04:05.006Z"), &tm); err != nil || !tm.Equal(expected) {
+		t.Fatal(tm, err)
+	}
+	if err := vm.ExportTo(vm.ToValue("not a date"), &tm); err == nil {
+		t.Fatal("expected an error")
+	}
+	if err := vm.ExportTo(vm.ToValue(expected), &tm); err != nil || !tm.Equal(expected) {
+		t.Fatal(tm, err)
+	}
+}
+
+func TestDateFromGoTime(t *testing.T) {
+	vm := New()
+	loc := time.FixedZone("X", 3*3600)
+	tm := time.Date(2021, 1, 2, 3, 4, 5, 6007, loc)
+	type S struct {
+		T time.Time
+		P *time.Time
+		N *time.Time
+	}
+	vm.Set("reflected", tm)
+	vm.SetTimeToDate(true)
+	vm.Set("t", tm)
+	vm.Set("s", &S{T: tm, P: &tm})
+	vm.Set("far", time.Date(300000, 1, 1, 0, 0, 0, 0, time.UTC))
+	res, err := vm.RunString(`
+	!(reflected instanceof Date) && t instanceof Date && t.toISOString() === "2021-01-02T00:04:05.000Z" &&
+		s.T instanceof Date && s.P instanceof Date && s.N === null && isNaN(far.getTime());
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.ToBoolean() {
+		t.Fatal("unexpected values")
+	}
+
+	if exp, ok := vm.Get("t").Export().(time.Time); !ok || exp != tm {
+		t.Fatal(exp)
+	}
+	var s S
+	if err := vm.ExportTo(vm.Get("s"), &s); err != nil || s.T != tm || *s.P != tm {
+		t.Fatal(s, err)
+	}
+
+	v, err := vm.RunString(`t.setUTCHours(10); t`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := v.Export().(time.Time)
+	if exp.Location() != loc || !exp.Equal(time.Date(2021, 1, 2, 10, 4, 5, 0, time.UTC)) {
+		t.Fatal(exp)
+	}
+}
@@ -0,0 +1,199 @@
+package goja
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// DecimalArithmetic implements the arithmetic of a Go decimal type (such as shopspring/decimal's Decimal) for the
+// JavaScript operators, see RegisterDecimal. The operands and the results are values of the decimal type.
+type DecimalArithmetic interface {
+	// FromValue converts the other operand of an operator applied to a decimal into the decimal type. v is a
+	// primitive value: a Number, a BigInt, a String, a Boolean, null or undefined. An error results in a TypeError.
+	FromValue(v Value) (interface{}, error)
+
+	Add(a, b interface{}) (interface{}, error)
+	Sub(a, b interface{}) (interface{}, error)
+	Mul(a, b interface{}) (interface{}, error)
+	Div(a, b interface{}) (interface{}, error)
+	Mod(a, b interface{}) (interface{}, error)
+	Neg(a interface{}) (interface{}, error)
+
+	// Cmp returns -1, 0 or +1 depending on whether a is less than, equal to or greater than b.
+	Cmp(a, b interface{}) int
+}
+
+type decimalOp int
+
+const (
+	decimalAdd decimalOp = iota
+	decimalSub
+	decimalMul
+	decimalDiv
+	decimalMod
+)
+
+type decimalType struct {
+	typ   reflect.Type
+	arith DecimalArithmetic
+}
+
+// RegisterDecimal makes the values of the Go type typ, converted by ToValue() into reflect based host objects,
+// participate in the arithmetic operators: when either operand of +, -, *, /, % or of a relational operator
+// (<, <=, >, >=) is such a value, or the operand of unary -, the operation is performed by arith instead of
+// converting the operands to Numbers. The other operand is converted to a primitive (with the 'number' hint) and
+// then into the decimal type using arith.FromValue. The results are converted with ToValue(). This lets scripts do
+// exact decimal calculations, e.g.:
+//
+//	r.RegisterDecimal(reflect.TypeOf(decimal.Decimal{}), decimalArithmetic{})
+//	r.Set("price", decimal.RequireFromString("19.99"))
+//	r.RunString(`price * 3 + 0.03`) // 60.00, not 60.00000000000001
+//
+// Adding a decimal and a String concatenates them (the decimal is converted with its toString() method). The
+// equality operators are not affected, they compare the wrappers, so a.Equal(b) or Cmp based comparisons should
+// be used instead. Operators that are not listed above (such as ** or ++) convert decimals to Numbers.
+//
+// Only one decimal type can be registered, registering another one replaces it. Calling RegisterDecimal with a nil
+// arith removes the registration. Errors returned by the arithmetic methods are thrown as GoErrors.
+func (r *Runtime) RegisterDecimal(typ reflect.Type, arith DecimalArithmetic) {
+	if arith == nil {
+		r.decimal = nil
+		return
+	}
+	r.decimal = &decimalType{typ: typ, arith: arith}
+}
+
+// operand returns the Go value wrapped by v if it's a value of the registered decimal type.
+func (d *decimalType) operand(v Value) (interface{}, bool) {
+	if o, ok := v.(*Object); ok {
+		if w, ok := o.self.(*objectGoReflect); ok && w.origValue.Type() == d.typ {
+			return w.origValue.Interface(), true
+		}
+	}
+	return nil, false
+}
+
+func (r *Runtime) toDecimal(v Value) interface{} {
+	res, err := r.decimal.arith.FromValue(toPrimitiveNumber(v))
+	if err != nil {
+		panic(r.NewTypeError("Cannot convert %s to %v: %v", v.String(), r.decimal.typ, err))
+	}
+	return res
+}
+
+func (r *Runtime) decimalResult(res interface{}, err error) Value {
+	if err != nil {
+		panic(r.NewGoError(err))
+	}
+	return r.ToValue(res)
+}
+
+// decimalBinary performs op if either of the operands is a decimal. The second result is false otherwise.
+func (r *Runtime) decimalBinary(op decimalOp, left, right Value) (Value, bool) {
+	d := r.decimal
+	a, leftOk := d.operand(left)
+	b, rightOk := d.operand(right)
+	if !leftOk && !rightOk {
+		return nil, false
+	}
+	if op == decimalAdd {
+		// the concatenation takes precedence, same as for Numbers
+		if !leftOk {
+			left = toPrimitive(left)
+		}
+		if !rightOk {
+			right = toPrimitive(right)
+		}
+		_, leftString := left.(valueString)
+		_, rightString := right.(valueString)
+		if leftString || rightString {
+			return left.toString().concat(right.toString()), true
+		}
+	}
+	if !leftOk {
+		a = r.toDecimal(left)
+	}
+	if !rightOk {
+		b = r.toDecimal(right)
+	}
+	switch op {
+	case decimalAdd:
+		return r.decimalResult(d.arith.Add(a, b)), true
+	case decimalSub:
+		return r.decimalResult(d.arith.Sub(a, b)), true
+	case decimalMul:
+		return r.decimalResult(d.arith.Mul(a, b)), true
+	case decimalDiv:
+		return r.decimalResult(d.arith.Div(a, b)), true
+	case decimalMod:
+		return r.decimalResult(d.arith.Mod(a, b)), true
+	}
+	panic(fmt.Errorf("unknown decimal operation %d", op))
+}
+
+// decimalCmp compares the operands if either of them is a decimal. The second result is false otherwise.
+func (r *Runtime) decimalCmp(left, right Value) (int, bool) {
+	d := r.decimal
+	a, leftOk := d.operand(left)
+	b, rightOk := d.operand(right)
+	if !leftOk && !rightOk {
+		return 0, false
+	}
+	if !leftOk {
+		a = r.toDecimal(left)
+	}
+	if !rightOk {
+		b = r.toDecimal(right)
+	}
+	return d.arith.Cmp(a, b), true
+}
+
+// decimalBinaryOp performs op on the two topmost stack values if either of them is a decimal (see
+// Runtime.RegisterDecimal). It returns false if the operation has not been performed.
+func (vm *vm) decimalBinaryOp(op decimalOp) bool {
+	if vm.r.decimal == nil {
+		return false
+	}
+	res, ok := vm.r.decimalBinary(op, vm.stack[vm.sp-2], vm.stack[vm.sp-1])
+	if !ok {
+		return false
+	}
+	vm.sp--
+	vm.stack[vm.sp-1] = res
+	vm.pc++
+	return true
+}
+
+// decimalCmpOp is like decimalBinaryOp for the relational operators, test converts the result of the comparison.
+func (vm *vm) decimalCmpOp(test func(c int) bool) bool {
+	if vm.r.decimal == nil {
+		return false
+	}
+	c, ok := vm.r.decimalCmp(vm.stack[vm.sp-2], vm.stack[vm.sp-1])
+	if !ok {
+		return false
+	}
+	vm.sp--
+	if test(c) {
+		vm.stack[vm.sp-1] = valueTrue
+	} else {
+		vm.stack[vm.sp-1] = valueFalse
+	}
+	vm.pc++
+	return true
+}
+
+// decimalNegOp negates the topmost stack value if it's a decimal.
+func (vm *vm) decimalNegOp() bool {
+	d := vm.r.decimal
+	if d == nil {
+		return false
+	}
+	a, ok := d.operand(vm.stack[vm.sp-1])
+	if !ok {
+		return false
+	}
+	vm.stack[vm.sp-1] = vm.r.decimalResult(d.arith.Neg(a))
+	vm.pc++
+	return true
+}
@@ -0,0 +1,120 @@
+package goja
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// testCents is a fixed-point decimal with two fractional digits.
+type testCents int64
+
+func (c testCents) String() string {
+	sign := ""
+	if c < 0 {
+		sign, c = "-", -c
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, c/100, c%100)
+}
+
+type testCentsArithmetic struct{}
+
+func (testCentsArithmetic) FromValue(v Value) (interface{}, error) {
+	switch v := v.(type) {
+	case valueInt, valueFloat:
+		return testCents(math.Round(v.ToFloat() * 100)), nil
+	case valueString:
+		f, err := strconv.ParseFloat(v.String(), 64)
+		if err != nil {
+			return nil, err
+		}
+		return testCents(math.Round(f * 100)), nil
+	}
+	return nil, errors.New("unsupported value")
+}
+
+func (testCentsArithmetic) Add(a, b interface{}) (interface{}, error) {
+	return a.(testCents) + b.(testCents), nil
+}
+
+func (testCentsArithmetic) Sub(a, b interface{}) (interface{}, error) {
+	return a.(testCents) - b.(testCents), nil
+}
+
+func (testCentsArithmetic) Mul(a, b interface{}) (interface{}, error) {
+	return a.(testCents) * b.(testCents) / 100, nil
+}
+
+func (testCentsArithmetic) Div(a, b interface{}) (interface{}, error) {
+	if b.(testCents) == 0 {
+		return nil, errors.New("division by zero")
+	}
+	return a.(testCents) * 100 / b.(testCents), nil
+}
+
+func (testCentsArithmetic) Mod(a, b interface{}) (interface{}, error) {
+	if b.(testCents) == 0 {
+		return nil, errors.New("division by zero")
+	}
+	return a.(testCents) % b.(testCents), nil
+}
+
+func (testCentsArithmetic) Neg(a interface{}) (interface{}, error) {
+	return -a.(testCents), nil
+}
+
+func (testCentsArithmetic) Cmp(a, b interface{}) int {
+	switch {
+	case a.(testCents) < b.(testCents):
+		return -1
+	case a.(testCents) > b.(testCents):
+		return 1
+	}
+	return 0
+}
+
+func TestRegisterDecimal(t *testing.T) {
+	vm := New()
+	vm.RegisterDecimal(reflect.TypeOf(testCents(0)), testCentsArithmetic{})
+	vm.Set("price", testCents(1999))
+	const SCRIPT = `
+	var total = price * 3 + 0.03;
+	assert.sameValue(String(total), "60.00", "total");
+	assert.sameValue(String(0.1 + price - "0.09"), "20.00", "sub");
+	assert.sameValue(String(-price), "-19.99", "neg");
+	assert.sameValue(String(total / 4), "15.00", "div");
+	assert.sameValue(String(total % 7), "4.00", "mod");
+	assert.sameValue("$" + price, "$19.99", "concat");
+	assert.sameValue(price + "!", "19.99!", "concat right");
+	assert(price < 20 && price <= 19.99 && price > "19.98" && price >= price && !(price > 20), "compare");
+	var x = price;
+	x += 1;
+	assert.sameValue(String(x), "20.99", "compound assignment");
+	assert.throws(TypeError, function() { price * {} }, "unsupported operand");
+	try {
+		price / 0;
+		throw new Error("no exception");
+	} catch (e) {
+		assert(e instanceof GoError, "division by zero");
+	}
+	assert.sameValue(1 + 2, 3, "numbers");
+	`
+	_, err := vm.RunProgram(testLib())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vm.RunString(SCRIPT); err != nil {
+		t.Fatal(err)
+	}
+	if v := vm.Get("total").Export(); v != testCents(6000) {
+		t.Fatal(v)
+	}
+
+	vm.RegisterDecimal(nil, nil)
+	if res, err := vm.RunString(`price * 1`); err != nil || res.ToInteger() != 1999 {
+		t.Fatal(res, err)
+	}
+}
@@ -0,0 +1,673 @@
+package goja
+
+import (
+	"container/heap"
+	gocontext "context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Loop is an event loop for a Runtime. It runs the callbacks of the timers created with setTimeout(),
+// setInterval() and setImmediate() which are added to the global object by NewLoop().
+//
+// The loop also provides queueMicrotask() which adds a callback to the promise job queue. An exception thrown by
+// such callback does not prevent the remaining jobs from running, it is reported when the current task completes
+// and stops the loop in the same way an exception thrown by a timer callback does.
+//
+// Each iteration of the loop first runs the callbacks of all the expired timers in the order of their expiration
+// (the timers that expire at the same time run in the order of their creation), then all the callbacks scheduled
+// with setImmediate() before the iteration started. The promise jobs (microtasks) are run after each callback,
+// so they always take precedence over the timers.
+//
+// The loop runs either on the calling goroutine (see Run) or in the background (see Start). Like the Runtime
+// itself, the Loop is not goroutine-safe, except for RunOnLoop which can be used to run code on the loop from
+// any goroutine.
+type Loop struct {
+	r *Runtime
+
+	timers     loopTimerQueue
+	immediates []*loopTimer
+	active     map[int64]*loopTimer
+	lastId     int64
+	seq        uint64
+
+	// the first exception thrown by a microtask callback that has not been reported yet
+	uncaught *Exception
+
+	// the tasks submitted from other goroutines by post()
+	tasksMu sync.Mutex
+	tasks   []func() error
+	wakeup  chan struct{}
+
+	// the number of sources (such as running workers) which may still post tasks, the loop keeps running
+	// while it is positive
+	refs int
+	// if set, the loop keeps running while it returns true
+	keepAlive func() bool
+	stopped   bool
+
+	// the result of the loop started by Start is sent to it
+	background chan error
+	// set by Stop, protected by tasksMu
+	stopRequested bool
+
+	// the promises rejected without a handler by the current task, and the ones reported by an
+	// unhandledrejection event which have not been handled since
+	rejected    []*Promise
+	outstanding map[*Promise]struct{}
+
+	// the virtual clock set by UseFakeClock and the time sources of the Runtime it has replaced
+	clock           *FakeClock
+	savedNow        Now
+	savedPerfClock  PerformanceClock
+	savedTimeOrigin float64
+
+	// the channel set by SetExportErrors
+	exportErrors chan<- error
+
+	workerOptions WorkerOptions
+	// the MessagePort objects bound to the loop
+	ports map[*messagePortObject]struct{}
+}
+
+type loopTimer struct {
+	id    int64
+	fn    Callable
+	args  []Value
+	when  time.Time
+	delay time.Duration
+
+	repeat    bool
+	cancelled bool
+
+	seq   uint64
+	index int
+}
+
+type loopTimerQueue []*loopTimer
+
+func (q loopTimerQueue) Len() int {
+	return len(q)
+}
+
+func (q loopTimerQueue) Less(i, j int) bool {
+	if q[i].when.Equal(q[j].when) {
+		return q[i].seq < q[j].seq
+	}
+	return q[i].when.Before(q[j].when)
+}
+
+func (q loopTimerQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *loopTimerQueue) Push(x interface{}) {
+	t := x.(*loopTimer)
+	t.index = len(*q)
+	*q = append(*q, t)
+}
+
+func (q *loopTimerQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	t := old[n-1]
+	old[n-1] = nil
+	t.index = -1
+	*q = old[:n-1]
+	return t
+}
+
+// NewLoop creates an event loop for the Runtime and adds the setTimeout, clearTimeout, setInterval,
+// clearInterval, setImmediate and clearImmediate functions to its global object.
+//
+// If the global object is an event target (see Runtime.EnableEvents), the loop dispatches the unhandledrejection
+// event to it after each task for every promise rejected without a handler during the task, and the
+// rejectionhandled event when a handler is added to such promise later, as described in
+// https://html.spec.whatwg.org/multipage/webappapis.html#unhandled-promise-rejections. The events have the
+// promise and reason properties. Cancelling unhandledrejection has no effect as the loop does not report the
+// unhandled rejections otherwise.
+func NewLoop(r *Runtime) *Loop {
+	l := &Loop{
+		r:      r,
+		active: make(map[int64]*loopTimer),
+		wakeup: make(chan struct{}, 1),
+		ports:  make(map[*messagePortObject]struct{}),
+
+		outstanding: make(map[*Promise]struct{}),
+	}
+	r.loopRejectionTracker = l.trackRejection
+	r.loop = l
+	r.addToGlobal("setTimeout", r.newNativeFunc(l.setTimeout, nil, "setTimeout", nil, 2))
+	r.addToGlobal("clearTimeout", r.newNativeFunc(l.clearTimer, nil, "clearTimeout", nil, 1))
+	r.addToGlobal("setInterval", r.newNativeFunc(l.setInterval, nil, "setInterval", nil, 2))
+	r.addToGlobal("clearInterval", r.newNativeFunc(l.clearTimer, nil, "clearInterval", nil, 1))
+	r.addToGlobal("setImmediate", r.newNativeFunc(l.setImmediate, nil, "setImmediate", nil, 1))
+	r.addToGlobal("clearImmediate", r.newNativeFunc(l.clearTimer, nil, "clearImmediate", nil, 1))
+	r.addToGlobal("queueMicrotask", r.newNativeFunc(l.queueMicrotask, nil, "queueMicrotask", nil, 1))
+	return l
+}
+
+func (l *Loop) newTimer(call FunctionCall, method string, argsStart int) *loopTimer {
+	fn, ok := AssertFunction(call.Argument(0))
+	if !ok {
+		panic(l.r.NewTypeError("The callback argument of %s must be a function", method))
+	}
+	l.lastId++
+	l.seq++
+	t := &loopTimer{
+		id:    l.lastId,
+		fn:    fn,
+		seq:   l.seq,
+		index: -1,
+	}
+	if len(call.Arguments) > argsStart {
+		t.args = append([]Value(nil), call.Arguments[argsStart:]...)
+	}
+	l.active[t.id] = t
+	return t
+}
+
+// timerDelay converts the delay argument the same way Node.js does: values that are not in the range
+// [1, 2^31-1] become 1.
+func timerDelay(v Value) time.Duration {
+	delay := v.ToFloat()
+	if !(delay >= 1 && delay <= 1<<31-1) {
+		delay = 1
+	}
+	return time.Duration(delay * float64(time.Millisecond))
+}
+
+func (l *Loop) now() time.Time {
+	if l.clock != nil {
+		return l.clock.now
+	}
+	return time.Now()
+}
+
+func (l *Loop) schedule(t *loopTimer) {
+	t.when = l.now().Add(t.delay)
+	heap.Push(&l.timers, t)
+}
+
+func (l *Loop) setTimeout(call FunctionCall) Value {
+	t := l.newTimer(call, "setTimeout", 2)
+	t.delay = timerDelay(call.Argument(1))
+	l.schedule(t)
+	return intToValue(t.id)
+}
+
+func (l *Loop) setInterval(call FunctionCall) Value {
+	t := l.newTimer(call, "setInterval", 2)
+	t.delay = timerDelay(call.Argument(1))
+	t.repeat = true
+	l.schedule(t)
+	return intToValue(t.id)
+}
+
+func (l *Loop) setImmediate(call FunctionCall) Value {
+	t := l.newTimer(call, "setImmediate", 1)
+	l.immediates = append(l.immediates, t)
+	return intToValue(t.id)
+}
+
+func (l *Loop) clearTimer(call FunctionCall) Value {
+	switch id := call.Argument(0).(type) {
+	case valueInt, valueFloat:
+		if t := l.active[id.ToInteger()]; t != nil {
+			l.cancel(t)
+		}
+	}
+	return _undefined
+}
+
+func (l *Loop) queueMicrotask(call FunctionCall) Value {
+	var callback func(FunctionCall) Value
+	if fn, ok := call.Argument(0).(*Object); ok {
+		callback, _ = fn.self.assertCallable()
+	}
+	if callback == nil {
+		panic(l.r.NewTypeError("The callback argument of queueMicrotask must be a function"))
+	}
+	job := &jobCallback{callback: callback}
+	l.r.enqueuePromiseJob(func() {
+		if ex := l.r.vm.try(func() {
+			l.r.callJobCallback(job, _undefined)
+		}); ex != nil && l.uncaught == nil {
+			l.uncaught = ex
+		}
+	})
+	return _undefined
+}
+
+// takeUncaught returns and clears the exception thrown by a microtask callback, if any.
+func (l *Loop) takeUncaught() error {
+	if ex := l.uncaught; ex != nil {
+		l.uncaught = nil
+		return ex
+	}
+	return nil
+}
+
+// trackRejection implements the HostPromiseRejectionTracker of HTML. The promises are only tracked if the global
+// object is an event target.
+func (l *Loop) trackRejection(p *Promise, operation PromiseRejectionOperation) {
+	if l.r.globalEvents == nil {
+		return
+	}
+	switch operation {
+	case PromiseRejectionReject:
+		l.rejected = append(l.rejected, p)
+	case PromiseRejectionHandle:
+		for i, q := range l.rejected {
+			if q == p {
+				copy(l.rejected[i:], l.rejected[i+1:])
+				l.rejected[len(l.rejected)-1] = nil
+				l.rejected = l.rejected[:len(l.rejected)-1]
+				return
+			}
+		}
+		if _, ok := l.outstanding[p]; ok {
+			delete(l.outstanding, p)
+			l.post(func() error {
+				l.r.dispatchPromiseRejectionEvent("rejectionhandled", p)
+				return nil
+			})
+		}
+	}
+}
+
+// notifyRejections dispatches the unhandledrejection events for the promises rejected during the last task
+// which still have no handlers.
+func (l *Loop) notifyRejections() error {
+	if len(l.rejected) == 0 {
+		return nil
+	}
+	list := l.rejected
+	l.rejected = nil
+	if err := l.r.runWrapped(func() {
+		for _, p := range list {
+			if p.handled {
+				continue
+			}
+			l.r.dispatchPromiseRejectionEvent("unhandledrejection", p)
+			if !p.handled {
+				l.outstanding[p] = struct{}{}
+			}
+		}
+	}); err != nil {
+		return err
+	}
+	return l.takeUncaught()
+}
+
+func (l *Loop) cancel(t *loopTimer) {
+	t.cancelled = true
+	delete(l.active, t.id)
+	if t.index >= 0 {
+		heap.Remove(&l.timers, t.index)
+	}
+}
+
+func (l *Loop) fire(t *loopTimer) error {
+	if !t.repeat {
+		delete(l.active, t.id)
+	}
+	_, err := t.fn(_undefined, t.args...)
+	if t.repeat && !t.cancelled {
+		l.schedule(t)
+	}
+	if err != nil {
+		l.uncaught = nil
+		return err
+	}
+	if err := l.takeUncaught(); err != nil {
+		return err
+	}
+	return l.notifyRejections()
+}
+
+// post adds a task to be run by the loop. Unlike the rest of the Loop methods it is goroutine-safe. The task is
+// run by the loop goroutine and an error returned by it stops the loop in the same way an exception thrown by a
+// timer callback does.
+func (l *Loop) post(task func() error) {
+	l.tasksMu.Lock()
+	l.tasks = append(l.tasks, task)
+	l.tasksMu.Unlock()
+	select {
+	case l.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+func (l *Loop) hasTasks() bool {
+	l.tasksMu.Lock()
+	defer l.tasksMu.Unlock()
+	return len(l.tasks) > 0
+}
+
+func (l *Loop) runTasks() error {
+	l.tasksMu.Lock()
+	tasks := l.tasks
+	l.tasks = nil
+	l.tasksMu.Unlock()
+	for i, task := range tasks {
+		var err error
+		if ex := l.r.runWrapped(func() {
+			err = task()
+		}); ex != nil {
+			err = ex
+		}
+		if err == nil {
+			err = l.takeUncaught()
+		}
+		if err == nil {
+			err = l.notifyRejections()
+		}
+		if err != nil || l.stopped {
+			l.tasksMu.Lock()
+			l.tasks = append(tasks[i+1:], l.tasks...)
+			l.tasksMu.Unlock()
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *Loop) alive() bool {
+	return len(l.timers) > 0 && l.clock == nil || len(l.immediates) > 0 || l.refs > 0 || l.background != nil || l.hasTasks() || l.keepAlive != nil && l.keepAlive() ||
+		l.portsAlive()
+}
+
+// wait blocks until the first timer expires or a task is posted. With a fake clock the timers never expire by
+// themselves.
+func (l *Loop) wait() {
+	if len(l.timers) == 0 || l.clock != nil {
+		<-l.wakeup
+		return
+	}
+	if d := time.Until(l.timers[0].when); d > 0 {
+		timer := time.NewTimer(d)
+		select {
+		case <-timer.C:
+		case <-l.wakeup:
+			timer.Stop()
+		}
+	}
+}
+
+// Run calls fn and then runs the loop until there are no more timers. If a callback throws an exception the loop
+// stops and the exception is returned. The remaining timers stay scheduled and run by the next call to Run.
+//
+// If there are running workers (see EnableWorkers) or MessagePorts expecting messages from other loops (see
+// EnableMessageChannels) the loop also waits for them and runs the callbacks for the messages they post.
+func (l *Loop) Run(fn func(*Runtime)) error {
+	fn(l.r)
+	if err := l.takeUncaught(); err != nil {
+		return err
+	}
+	if err := l.notifyRejections(); err != nil {
+		return err
+	}
+	return l.run(nil)
+}
+
+// RunOnLoop schedules fn to be run on the loop goroutine, after the current task (if any). Unlike the rest of the
+// Loop methods it is goroutine-safe, so it's the way to run code on a loop started with Start. fn runs as a task of
+// its own: the promise jobs it creates run when it returns, before any timer. A panic with an *Exception (such as
+// the ones caused by Runtime.NewTypeError) stops the loop in the same way an exception thrown by a timer callback
+// does.
+func (l *Loop) RunOnLoop(fn func(*Runtime)) {
+	l.post(func() error {
+		fn(l.r)
+		return nil
+	})
+}
+
+// Start runs the loop on a new goroutine until Stop is called, the code is submitted to it with RunOnLoop. Unlike
+// Run, the loop keeps waiting for tasks when there is nothing to do. It also stops if a callback throws an
+// exception, the exception is returned by Stop. Calling Start when the loop has already been started does nothing.
+//
+// While the loop is running in the background the Runtime must only be used from the callbacks and the functions
+// submitted with RunOnLoop.
+func (l *Loop) Start() {
+	if l.background != nil {
+		return
+	}
+	l.background = make(chan error, 1)
+	go func() {
+		l.background <- l.run(func() bool {
+			l.tasksMu.Lock()
+			defer l.tasksMu.Unlock()
+			return l.stopRequested
+		})
+	}()
+}
+
+// Stop stops the loop started by Start and waits for it to finish the current task. It returns the exception that
+// has stopped the loop before, if any. The timers and the tasks submitted with RunOnLoop that have not run yet are
+// kept, they run when the loop is started or run again. Stop must not be called from the loop goroutine (i.e. from
+// a callback) and it does nothing if the loop has not been started.
+func (l *Loop) Stop() error {
+	if l.background == nil {
+		return nil
+	}
+	l.tasksMu.Lock()
+	l.stopRequested = true
+	l.tasksMu.Unlock()
+	select {
+	case l.wakeup <- struct{}{}:
+	default:
+	}
+	err := <-l.background
+	l.background = nil
+	l.tasksMu.Lock()
+	l.stopRequested = false
+	l.tasksMu.Unlock()
+	return err
+}
+
+// run runs the loop until there is nothing to do, it's stopped or done (if not nil) returns true.
+func (l *Loop) run(done func() bool) error {
+	for !l.stopped && (done == nil || !done()) {
+		if err := l.runTasks(); err != nil {
+			return err
+		}
+		if l.stopped || done != nil && done() || !l.alive() {
+			break
+		}
+		if len(l.immediates) == 0 && !l.hasTasks() {
+			l.wait()
+		}
+		now := l.now()
+		for len(l.timers) > 0 && !l.timers[0].when.After(now) {
+			if err := l.fire(heap.Pop(&l.timers).(*loopTimer)); err != nil {
+				return err
+			}
+		}
+		immediates := l.immediates
+		l.immediates = nil
+		for i, t := range immediates {
+			if t.cancelled {
+				continue
+			}
+			if err := l.fire(t); err != nil {
+				l.immediates = append(immediates[i+1:], l.immediates...)
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// NewPromiseFromGoFunc calls fn on a new goroutine and returns a promise which is settled on the loop when fn
+// returns: it's fulfilled with the result converted by ToValue() or rejected with the error converted by
+// Runtime.NewGoError(). fn receives the context of the Runtime at the time of the call (see Runtime.Context), it
+// must not use the Runtime. While fn is running the loop does not stop.
+func (l *Loop) NewPromiseFromGoFunc(fn func(ctx gocontext.Context) (interface{}, error)) *Promise {
+	r := l.r
+	p, resolve, reject := r.NewPromise()
+	ctx := r.Context()
+	l.refs++
+	go func() {
+		res, err := fn(ctx)
+		l.post(func() error {
+			l.refs--
+			if err != nil {
+				reject(r.NewGoError(err))
+			} else {
+				resolve(res)
+			}
+			return nil
+		})
+	}()
+	return p
+}
+
+// AwaitPromise runs the loop until the promise is settled and returns its result. If the promise is rejected, the
+// reason is returned as *Exception and the rejection is considered handled. An error is also returned if the loop
+// stops, e.g. because a callback throws an exception or there is nothing left to do while the promise is still
+// pending.
+//
+// AwaitPromise must be called on the goroutine that owns the loop, either from the function passed to Run or when
+// the loop is not running. The callbacks run by it are the same ones Run would run, so calling it from such a
+// callback (e.g. from a native function called by a timer) runs the other callbacks re-entrantly.
+func (l *Loop) AwaitPromise(p *Promise) (Value, error) {
+	if err := l.run(func() bool {
+		return p.state != PromiseStatePending
+	}); err != nil {
+		return nil, err
+	}
+	switch p.state {
+	case PromiseStateFulfilled:
+		return p.result, nil
+	case PromiseStateRejected:
+		if !p.handled {
+			l.r.trackPromiseRejection(p, PromiseRejectionHandle)
+			p.handled = true
+		}
+		return nil, &Exception{val: p.result}
+	}
+	if l.stopped {
+		return nil, errors.New("the loop has been stopped before the promise was settled")
+	}
+	return nil, errors.New("the loop has nothing to do but the promise is still pending")
+}
+
+// maxFakeClockTimers is the number of timers RunAllTimers runs before assuming the timers are rescheduled
+// indefinitely.
+const maxFakeClockTimers = 1000
+
+// FakeClock is a virtual clock for testing time-based scripts deterministically. It only moves forward when
+// Advance or RunAllTimers is called. See Loop.UseFakeClock.
+//
+// Like the Loop, the FakeClock is not goroutine-safe.
+type FakeClock struct {
+	now  time.Time
+	loop *Loop
+}
+
+// NewFakeClock creates a FakeClock showing the specified time.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the current virtual time.
+func (c *FakeClock) Now() time.Time {
+	return c.now
+}
+
+// runTimers runs the timers which expire no later than until, in the order of their expiration. Before each
+// callback the clock is set to the expiration time of its timer.
+func (c *FakeClock) runTimers(until func(*loopTimer) bool) (int, error) {
+	l := c.loop
+	n := 0
+	for l != nil && len(l.timers) > 0 && until(l.timers[0]) {
+		t := heap.Pop(&l.timers).(*loopTimer)
+		if t.when.After(c.now) {
+			c.now = t.when
+		}
+		n++
+		if err := l.fire(t); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// Advance moves the clock forward by d and runs the callbacks of the timers which expire in the meantime,
+// including the timers created by these callbacks. If a callback throws an exception, Advance returns it and
+// the clock stays at the expiration time of the failed timer.
+func (c *FakeClock) Advance(d time.Duration) error {
+	target := c.now.Add(d)
+	if _, err := c.runTimers(func(t *loopTimer) bool {
+		return !t.when.After(target)
+	}); err != nil {
+		return err
+	}
+	c.now = target
+	return nil
+}
+
+// RunAllTimers moves the clock forward running the timers one by one until there are none left. It returns an
+// error if there are still timers after running 1000 of them, which usually means there is an active interval.
+func (c *FakeClock) RunAllTimers() error {
+	n := 0
+	_, err := c.runTimers(func(*loopTimer) bool {
+		n++
+		return n <= maxFakeClockTimers
+	})
+	if err == nil && n > maxFakeClockTimers {
+		err = fmt.Errorf("aborting after running %d timers, assuming an infinite loop", maxFakeClockTimers)
+	}
+	return err
+}
+
+// UseFakeClock makes the loop and its Runtime use the virtual time of the clock: the timers created with
+// setTimeout() and setInterval() expire only when the clock is advanced, and Date, performance.now() (see
+// EnablePerformance) and the rest of the Runtime time source (see Runtime.SetTimeSource) show the virtual time.
+// Run does not wait for the timers, it only runs the callbacks which are due. The already scheduled timers keep
+// their remaining delay. A nil clock restores the time sources the Runtime had before the first clock was set.
+//
+// The clock should not be shared between loops. performance.now() counts from the time of the clock when it is
+// set, which becomes the performance time origin, so if EnablePerformance is called later it should be called
+// before the clock is advanced.
+func (l *Loop) UseFakeClock(clock *FakeClock) {
+	now := l.now()
+	r := l.r
+	if l.clock != nil {
+		l.clock.loop = nil
+	} else if clock != nil {
+		l.savedNow = r.now
+		if r.performance != nil {
+			l.savedPerfClock, l.savedTimeOrigin = r.performance.clock, r.performance.timeOrigin
+		}
+	}
+	if clock == nil && l.clock != nil {
+		r.SetTimeSource(l.savedNow)
+		r.SetPerformanceClock(l.savedPerfClock)
+		if l.savedPerfClock != nil {
+			r.performance.timeOrigin = l.savedTimeOrigin
+		} else {
+			r.performance.timeOrigin = float64(r.now().UnixNano()) / float64(time.Millisecond)
+		}
+		l.savedNow, l.savedPerfClock = nil, nil
+	}
+	l.clock = clock
+	if clock != nil {
+		clock.loop = l
+		r.SetTimeSource(clock.Now)
+		origin := clock.now
+		r.SetPerformanceClock(func() time.Duration {
+			return clock.now.Sub(origin)
+		})
+		r.performance.timeOrigin = float64(origin.UnixNano()) / float64(time.Millisecond)
+	}
+	newNow := l.now()
+	for _, t := range l.timers {
+		t.when = newNow.Add(t.when.Sub(now))
+	}
+}
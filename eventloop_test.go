@@ -0,0 +1,400 @@
+package goja
+
+import (
+	gocontext "context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoopTimers(t *testing.T) {
+	const SCRIPT = `
+	var log = [];
+	setTimeout(function(a, b) { log.push("timeout 100 " + a + b) }, 100, "x", "y");
+	setTimeout(function() {
+		log.push("timeout 50");
+		Promise.resolve().then(function() { log.push("microtask after timeout 50") });
+	}, 50);
+	setTimeout(function() { log.push("timeout 50 (2)") }, 50);
+	var cancelled = setTimeout(function() { log.push("cancelled") }, 5);
+	clearTimeout(cancelled);
+	var n = 0;
+	var interval = setInterval(function() {
+		log.push("interval " + (++n));
+		if (n === 3) {
+			clearInterval(interval);
+		}
+	}, 1);
+	Promise.resolve().then(function() { log.push("microtask") });
+	log.push("script");
+	`
+	r := New()
+	loop := NewLoop(r)
+	err := loop.Run(func(r *Runtime) {
+		if _, err := r.RunString(SCRIPT); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.testScriptWithTestLib(`
+	assert(compareArray(log, ["script", "microtask", "interval 1", "interval 2", "interval 3",
+		"timeout 50", "microtask after timeout 50", "timeout 50 (2)", "timeout 100 xy"]), log.join());
+	`, _undefined, t)
+}
+
+func TestLoopImmediate(t *testing.T) {
+	const SCRIPT = `
+	var log = [];
+	setImmediate(function(a) {
+		log.push("immediate 1" + a);
+		setImmediate(function() { log.push("immediate 3") });
+		Promise.resolve().then(function() { log.push("microtask") });
+	}, "!");
+	setImmediate(function() { log.push("immediate 2") });
+	clearImmediate(setImmediate(function() { log.push("cleared") }));
+	`
+	r := New()
+	err := NewLoop(r).Run(func(r *Runtime) {
+		if _, err := r.RunString(SCRIPT); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.testScriptWithTestLib(`
+	assert(compareArray(log, ["immediate 1!", "microtask", "immediate 2", "immediate 3"]), log.join());
+	assert.throws(TypeError, function() { setTimeout("code") });
+	`, _undefined, t)
+}
+
+func TestLoopError(t *testing.T) {
+	r := New()
+	loop := NewLoop(r)
+	start := time.Now()
+	err := loop.Run(func(r *Runtime) {
+		_, err := r.RunString(`
+		var done = false;
+		setTimeout(function() { throw new Error("boom") }, 1);
+		setTimeout(function() { done = true }, 2);
+		`)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+	if ex, ok := err.(*Exception); !ok || ex.Value().String() != "Error: boom" {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if r.Get("done").ToBoolean() {
+		t.Fatal("The loop did not stop")
+	}
+	if err := loop.Run(func(*Runtime) {}); err != nil {
+		t.Fatal(err)
+	}
+	if !r.Get("done").ToBoolean() {
+		t.Fatal("The remaining timer did not run")
+	}
+	if time.Since(start) < 2*time.Millisecond {
+		t.Fatal("The timers fired too early")
+	}
+}
+
+func TestLoopQueueMicrotask(t *testing.T) {
+	const SCRIPT = `
+	var log = [];
+	setTimeout(function() { log.push("timeout") }, 1);
+	Promise.resolve().then(function() { log.push("promise 1") });
+	queueMicrotask(function() {
+		log.push("microtask 1");
+		queueMicrotask(function() { log.push("nested microtask") });
+	});
+	Promise.resolve().then(function() { log.push("promise 2") });
+	log.push("script");
+	`
+	r := New()
+	loop := NewLoop(r)
+	err := loop.Run(func(r *Runtime) {
+		if _, err := r.RunString(SCRIPT); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.testScriptWithTestLib(`
+	assert(compareArray(log, ["script", "promise 1", "microtask 1", "promise 2", "nested microtask", "timeout"]), log.join());
+	assert.throws(TypeError, function() { queueMicrotask({}) });
+	`, _undefined, t)
+
+	err = loop.Run(func(r *Runtime) {
+		_, err := r.RunString(`
+		var after = [];
+		queueMicrotask(function() { throw new Error("first") });
+		queueMicrotask(function() { throw new Error("second") });
+		queueMicrotask(function() { after.push("still runs") });
+		setTimeout(function() { after.push("timeout") }, 1);
+		`)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+	if ex, ok := err.(*Exception); !ok || ex.Value().String() != "Error: first" {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if s := r.Get("after").String(); s != "still runs" {
+		t.Fatal(s)
+	}
+	if err := loop.Run(func(*Runtime) {}); err != nil {
+		t.Fatal(err)
+	}
+	if s := r.Get("after").String(); s != "still runs,timeout" {
+		t.Fatal(s)
+	}
+}
+
+func TestLoopFakeClock(t *testing.T) {
+	r := New()
+	r.EnablePerformance()
+	loop := NewLoop(r)
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	loop.UseFakeClock(clock)
+	err := loop.Run(func(r *Runtime) {
+		_, err := r.RunString(`
+		var log = [];
+		var t0 = Date.now();
+		setTimeout(function() {
+			log.push("timeout 100 at " + (Date.now() - t0) + " " + performance.now());
+			setTimeout(function() { log.push("nested at " + (Date.now() - t0)) }, 50);
+		}, 100);
+		var n = 0;
+		var interval = setInterval(function() {
+			log.push("interval at " + (Date.now() - t0));
+			if (++n === 3) clearInterval(interval);
+		}, 60);
+		setTimeout(function() { log.push("timeout 1000") }, 1000);
+		setImmediate(function() { log.push("immediate") });
+		`)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := clock.Advance(150 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if err := clock.Advance(0); err != nil {
+		t.Fatal(err)
+	}
+	if d := clock.Now().Sub(start); d != 150*time.Millisecond {
+		t.Fatal(d)
+	}
+	if err := clock.Advance(30 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	r.testScriptWithTestLib(`
+	assert(compareArray(log, ["immediate", "interval at 60", "timeout 100 at 100 100", "interval at 120",
+		"nested at 150", "interval at 180"]), log.join());
+	assert.sameValue(new Date().getTime(), Date.UTC(2020, 0, 1, 0, 0, 0, 180), "Date");
+	`, _undefined, t)
+
+	if err := clock.RunAllTimers(); err != nil {
+		t.Fatal(err)
+	}
+	if d := clock.Now().Sub(start); d != time.Second {
+		t.Fatal(d)
+	}
+	r.testScriptWithTestLib(`
+	assert.sameValue(log[log.length - 1], "timeout 1000");
+	var forever = setInterval(function() {}, 10);
+	`, _undefined, t)
+	if err := clock.RunAllTimers(); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	loop.UseFakeClock(nil)
+	r.testScriptWithTestLib(`
+	assert(Date.now() > Date.UTC(2021, 0, 1), "real time");
+	`, _undefined, t)
+}
+
+func TestLoopFakeClockRestore(t *testing.T) {
+	r := New()
+	embedderTime := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	r.SetTimeSource(func() time.Time {
+		return embedderTime
+	})
+	r.EnablePerformance()
+	r.SetPerformanceClock(func() time.Duration {
+		return 42 * time.Millisecond
+	})
+	loop := NewLoop(r)
+
+	loop.UseFakeClock(nil)
+	loop.UseFakeClock(NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)))
+	loop.UseFakeClock(NewFakeClock(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)))
+	r.testScriptWithTestLib(`
+	assert.sameValue(Date.now(), Date.UTC(2030, 0, 1), "fake time");
+	assert.sameValue(performance.now(), 0, "fake performance clock");
+	`, _undefined, t)
+
+	loop.UseFakeClock(nil)
+	r.testScriptWithTestLib(`
+	assert.sameValue(Date.now(), Date.UTC(2000, 0, 1), "embedder time");
+	assert.sameValue(performance.now(), 42, "embedder performance clock");
+	assert.sameValue(performance.timeOrigin, Date.UTC(2000, 0, 1), "time origin");
+	`, _undefined, t)
+}
+
+func TestLoopRejectionEvents(t *testing.T) {
+	r := New()
+	r.EnableEvents()
+	loop := NewLoop(r)
+	err := loop.Run(func(r *Runtime) {
+		_, err := r.RunString(`
+		var log = [];
+		addEventListener("unhandledrejection", function(e) {
+			log.push("unhandled " + e.reason + " " + (e.promise instanceof Promise) + " " + e.cancelable);
+			e.preventDefault();
+		});
+		globalThis.addEventListener("rejectionhandled", function(e) { log.push("handled " + e.reason) });
+		var p = Promise.reject(1);
+		Promise.reject(2).catch(function() {});
+		setTimeout(function() { p.catch(function() {}) }, 1);
+		(async function() { throw 3 })();
+		var custom = 0;
+		function listener() { custom++ }
+		addEventListener("custom", listener);
+		dispatchEvent(new Event("custom"));
+		removeEventListener("custom", listener);
+		globalThis.dispatchEvent(new Event("custom"));
+		`)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.testScriptWithTestLib(`
+	assert(compareArray(log, ["unhandled 1 true true", "unhandled 3 true true", "handled 1"]), log.join());
+	assert.sameValue(custom, 1, "custom event");
+	`, _undefined, t)
+
+	err = loop.Run(func(r *Runtime) {
+		_, err := r.RunString(`
+		addEventListener("unhandledrejection", function() { throw new Error("listener") });
+		Promise.reject(4);
+		`)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+	if ex, ok := err.(*Exception); !ok || ex.Value().String() != "Error: listener" {
+		t.Fatal(err)
+	}
+}
+
+func TestLoopPromiseFromGoFunc(t *testing.T) {
+	r := New()
+	l := NewLoop(r)
+	release := make(chan struct{})
+	err := l.Run(func(r *Runtime) {
+		r.Set("slow", func() *Promise {
+			return l.NewPromiseFromGoFunc(func(ctx gocontext.Context) (interface{}, error) {
+				<-release
+				return map[string]interface{}{"n": 42}, nil
+			})
+		})
+		r.Set("fail", func() *Promise {
+			return l.NewPromiseFromGoFunc(func(ctx gocontext.Context) (interface{}, error) {
+				return nil, errors.New("failed")
+			})
+		})
+		v, err := r.RunString(`
+		var out = [];
+		fail().catch(e => out.push(e.value.Error()));
+		slow().then(res => out.push(res.n));
+		new Promise(resolve => setTimeout(() => resolve("awaited"), 1));
+		`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := l.AwaitPromise(v.Export().(*Promise))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.String() != "awaited" {
+			t.Fatal(res)
+		}
+		close(release)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := r.Get("out").String(); s != "failed,42" && s != "42,failed" {
+		t.Fatal(s)
+	}
+
+	v, err := r.RunString(`Promise.reject(new TypeError("rejected"))`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.AwaitPromise(v.Export().(*Promise)); err == nil || !strings.Contains(err.Error(), "rejected") {
+		t.Fatal(err)
+	}
+	v, err = r.RunString(`new Promise(() => {})`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.AwaitPromise(v.Export().(*Promise)); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestLoopStartStop(t *testing.T) {
+	r := New()
+	loop := NewLoop(r)
+	results := make(chan string, 1)
+	r.Set("report", func(s string) {
+		results <- s
+	})
+	loop.Start()
+	loop.Start()
+	go loop.RunOnLoop(func(r *Runtime) {
+		if _, err := r.RunString(`
+		var order = [];
+		setTimeout(() => report(order.join()), 10);
+		setImmediate(() => order.push("immediate"));
+		Promise.resolve().then(() => order.push("promise"));
+		queueMicrotask(() => order.push("microtask"));
+		order.push("sync");
+		`); err != nil {
+			t.Error(err)
+		}
+	})
+	if s := <-results; s != "sync,promise,microtask,immediate" {
+		t.Fatal(s)
+	}
+	if err := loop.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if err := loop.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	loop.RunOnLoop(func(r *Runtime) {
+		results <- "failing"
+		panic(r.NewTypeError("failed"))
+	})
+	loop.Start()
+	<-results
+	if err := loop.Stop(); err == nil || !strings.Contains(err.Error(), "failed") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+//go:build go1.18
+// +build go1.18
+
+package goja
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Export converts v into a value of type T following the rules of Runtime.ExportTo, e.g.:
+//
+//	opts, err := goja.Export[Options](r, v)
+//
+// On error the zero value of T is returned.
+func Export[T any](r *Runtime, v Value) (T, error) {
+	var res T
+	if err := r.ExportTo(v, &res); err != nil {
+		var zero T
+		return zero, err
+	}
+	return res, nil
+}
+
+// Bind wraps the JavaScript function v into a Go function of type F, e.g.:
+//
+//	add, err := goja.Bind[func(a, b int) (int, error)](r, r.Get("add"))
+//
+// The arguments and the results are converted as described in the Functions section of Runtime.ExportTo, in
+// particular an exception thrown by the function is returned if the last result of F is an error and results in a
+// panic otherwise. An error is returned if F is not a func type or v is not a function.
+func Bind[F any](r *Runtime, v Value) (F, error) {
+	var fn F
+	if typ := reflect.TypeOf(&fn).Elem(); typ.Kind() != reflect.Func {
+		return fn, fmt.Errorf("%v is not a func type", typ)
+	}
+	if _, ok := AssertFunction(v); !ok {
+		return fn, fmt.Errorf("%v is not a function", v)
+	}
+	if err := r.ExportTo(v, &fn); err != nil {
+		var zero F
+		return zero, err
+	}
+	return fn, nil
+}
@@ -0,0 +1,68 @@
+//go:build go1.18
+// +build go1.18
+
+package goja
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenericExport(t *testing.T) {
+	r := New()
+	type options struct {
+		Name  string
+		Sizes []int
+	}
+	opts, err := Export[options](r, r.ToValue(map[string]interface{}{"Name": "a", "Sizes": []interface{}{1, 2}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.Name != "a" || len(opts.Sizes) != 2 || opts.Sizes[1] != 2 {
+		t.Fatalf("%+v", opts)
+	}
+	v, err := r.RunString(`(function(n) { return n * 2 })`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	double, err := Export[func(int) int](r, v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res := double(21); res != 42 {
+		t.Fatal(res)
+	}
+	s, err := Export[[]int](r, r.ToValue(1))
+	if err == nil || s != nil {
+		t.Fatal(s, err)
+	}
+}
+
+func TestBind(t *testing.T) {
+	r := New()
+	_, err := r.RunString(`
+	function add(a, b) {
+		if (a < 0) throw new RangeError("negative");
+		return a + b;
+	}
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	add, err := Bind[func(a, b int) (int, error)](r, r.Get("add"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res, err := add(40, 2); err != nil || res != 42 {
+		t.Fatal(res, err)
+	}
+	if _, err := add(-1, 2); err == nil || !strings.Contains(err.Error(), "negative") {
+		t.Fatal(err)
+	}
+	if _, err := Bind[func()](r, r.ToValue(1)); err == nil {
+		t.Fatal("expected an error")
+	}
+	if _, err := Bind[int](r, r.Get("add")); err == nil {
+		t.Fatal("expected an error")
+	}
+}
@@ -0,0 +1,298 @@
+package goja
+
+import (
+	"io"
+	"math"
+	"reflect"
+)
+
+// defaultReadSize is the maximum number of bytes read() returns if the size is not specified.
+const defaultReadSize = 32 * 1024
+
+// maxReadSize is the maximum number of bytes a single read() returns, larger sizes are reduced to it.
+const maxReadSize = 1024 * 1024
+
+// goIOObject is the object returned by WrapReader and WrapWriter.
+type goIOObject struct {
+	baseObject
+	reader io.Reader
+	writer io.Writer
+
+	// the error returned by the last Read, reported by the next read() if the last one returned data
+	readErr error
+	// the buffer Read is called with, the data is copied from it into an array of the exact size
+	readBuf []byte
+
+	// the asynchronous operations run one at a time, in the order they are requested
+	queue []func()
+	busy  bool
+}
+
+// wrapBytes returns a Uint8Array backed by b, without copying it.
+func (r *Runtime) wrapBytes(b []byte) *Object {
+	buf := r._newArrayBuffer(r.global.ArrayBufferPrototype, nil)
+	buf.data = b
+	return r.newUint8ArrayObject(buf, 0, len(b), r.getPrototypeFromCtor(r.global.Uint8Array, nil, nil)).val
+}
+
+func (r *Runtime) newGoIO(proto *Object) *goIOObject {
+	o := &Object{runtime: r}
+	g := &goIOObject{}
+	g.class = classObject
+	g.val = o
+	g.extensible = true
+	g.prototype = proto
+	o.self = g
+	g.init()
+	return g
+}
+
+// WrapReader returns an object which reads from rd. Its read(size) method returns a Uint8Array with at most size
+// (32768 if not specified, at most 1048576) bytes, or null at the end of the stream. The length of the array is the
+// number of bytes actually read. An error returned together with data is thrown by the next call, errors are
+// thrown as GoError.
+//
+// If the Runtime has a Loop (see NewLoop), readAsync(size) does the same on a separate goroutine and returns a
+// promise of the result. Asynchronous operations run one at a time in the order they are requested, and no
+// synchronous one is allowed while any of them is pending.
+//
+// close() and closeAsync() call the Close method of rd if it implements io.Closer. Exporting the object
+// returns rd.
+func (r *Runtime) WrapReader(rd io.Reader) *Object {
+	if r.global.GoReaderPrototype == nil {
+		r.global.GoReaderPrototype = r.newLazyObject(r.createGoReaderProto)
+	}
+	g := r.newGoIO(r.global.GoReaderPrototype)
+	g.reader = rd
+	return g.val
+}
+
+// WrapWriter returns an object which writes to w. Its write(data) method writes the bytes of an ArrayBuffer, a
+// TypedArray or a DataView, or a string encoded as UTF-8, and returns the number of bytes written. Errors are
+// thrown as GoError.
+//
+// If the Runtime has a Loop (see NewLoop), writeAsync(data) does the same on a separate goroutine and returns
+// a promise of the result. The data is copied when the method is called, so the buffer can be reused
+// immediately. See WrapReader for the rules of the asynchronous operations.
+//
+// close() and closeAsync() call the Close method of w if it implements io.Closer. Exporting the object
+// returns w.
+func (r *Runtime) WrapWriter(w io.Writer) *Object {
+	if r.global.GoWriterPrototype == nil {
+		r.global.GoWriterPrototype = r.newLazyObject(r.createGoWriterProto)
+	}
+	g := r.newGoIO(r.global.GoWriterPrototype)
+	g.writer = w
+	return g.val
+}
+
+func (g *goIOObject) exportType() reflect.Type {
+	if g.reader != nil {
+		return reflect.TypeOf(g.reader)
+	}
+	return reflect.TypeOf(g.writer)
+}
+
+func (g *goIOObject) export(*objectExportCtx) interface{} {
+	if g.reader != nil {
+		return g.reader
+	}
+	return g.writer
+}
+
+// read reads up to n bytes. It returns nil at the end of the stream.
+func (g *goIOObject) read(n int) ([]byte, error) {
+	if g.readErr == nil {
+		if n > maxReadSize {
+			n = maxReadSize
+		}
+		if len(g.readBuf) < n {
+			g.readBuf = make([]byte, n)
+		}
+		k, err := g.reader.Read(g.readBuf[:n])
+		g.readErr = err
+		if k > 0 || err == nil {
+			return append(make([]byte, 0, k), g.readBuf[:k]...), nil
+		}
+	}
+	if g.readErr == io.EOF {
+		return nil, nil
+	}
+	return nil, g.readErr
+}
+
+func (g *goIOObject) close() error {
+	var c interface{} = g.writer
+	if g.reader != nil {
+		c = g.reader
+	}
+	if c, ok := c.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+func (r *Runtime) thisGoIO(this Value, class, method string, sync bool) *goIOObject {
+	if o, ok := this.(*Object); ok {
+		if g, ok := o.self.(*goIOObject); ok && (g.reader != nil) == (class == "reader") {
+			if sync && g.busy {
+				panic(r.NewTypeError("Cannot call %s while an asynchronous operation is in progress", method))
+			}
+			return g
+		}
+	}
+	panic(r.NewTypeError("Method Go %s.%s called on incompatible receiver %s", class, method, r.objectproto_toString(FunctionCall{This: this})))
+}
+
+func (r *Runtime) readSizeArg(v Value) int {
+	if v == _undefined {
+		return defaultReadSize
+	}
+	n := v.ToInteger()
+	if n <= 0 || n > math.MaxInt32 {
+		panic(r.newError(r.global.RangeError, "Invalid read size: %s", v.String()))
+	}
+	return int(n)
+}
+
+func (r *Runtime) writeDataArg(v Value) []byte {
+	if b, ok := r.bufferSourceBytes(v); ok {
+		return b
+	}
+	if s, ok := v.(valueString); ok {
+		return []byte(s.String())
+	}
+	panic(r.NewTypeError("The data must be an ArrayBuffer, a TypedArray, a DataView or a string"))
+}
+
+func (r *Runtime) bytesOrNull(b []byte) Value {
+	if b == nil {
+		return _null
+	}
+	return r.wrapBytes(b)
+}
+
+func (r *Runtime) throwGoError(err error) {
+	if err != nil {
+		panic(r.NewGoError(err))
+	}
+}
+
+// async runs op on a separate goroutine once the previous asynchronous operations have completed and returns
+// a promise resolved with the result of the function op returns, which is called on the loop goroutine.
+func (g *goIOObject) async(method string, op func() func() Value) Value {
+	r := g.val.runtime
+	l := r.loop
+	if l == nil {
+		panic(r.NewTypeError("%s requires an event loop", method))
+	}
+	pcap := r.newPromiseCapability(r.global.Promise)
+	g.queue = append(g.queue, func() {
+		l.refs++
+		go func() {
+			complete := op()
+			l.post(func() error {
+				l.refs--
+				pcap.try(func() {
+					pcap.resolve(complete())
+				})
+				g.busy = false
+				g.next()
+				return nil
+			})
+		}()
+	})
+	g.next()
+	return pcap.promise
+}
+
+func (g *goIOObject) next() {
+	if g.busy || len(g.queue) == 0 {
+		return
+	}
+	op := g.queue[0]
+	g.queue = g.queue[1:]
+	g.busy = true
+	op()
+}
+
+func (r *Runtime) goReaderProto_read(call FunctionCall) Value {
+	g := r.thisGoIO(call.This, "reader", "read", true)
+	b, err := g.read(r.readSizeArg(call.Argument(0)))
+	r.throwGoError(err)
+	return r.bytesOrNull(b)
+}
+
+func (r *Runtime) goReaderProto_readAsync(call FunctionCall) Value {
+	g := r.thisGoIO(call.This, "reader", "readAsync", false)
+	n := r.readSizeArg(call.Argument(0))
+	return g.async("readAsync", func() func() Value {
+		b, err := g.read(n)
+		return func() Value {
+			r.throwGoError(err)
+			return r.bytesOrNull(b)
+		}
+	})
+}
+
+func (r *Runtime) goWriterProto_write(call FunctionCall) Value {
+	g := r.thisGoIO(call.This, "writer", "write", true)
+	n, err := g.writer.Write(r.writeDataArg(call.Argument(0)))
+	r.throwGoError(err)
+	return intToValue(int64(n))
+}
+
+func (r *Runtime) goWriterProto_writeAsync(call FunctionCall) Value {
+	g := r.thisGoIO(call.This, "writer", "writeAsync", false)
+	data := append([]byte(nil), r.writeDataArg(call.Argument(0))...)
+	return g.async("writeAsync", func() func() Value {
+		n, err := g.writer.Write(data)
+		return func() Value {
+			r.throwGoError(err)
+			return intToValue(int64(n))
+		}
+	})
+}
+
+func (r *Runtime) goIOClose(class string) func(FunctionCall) Value {
+	return func(call FunctionCall) Value {
+		g := r.thisGoIO(call.This, class, "close", true)
+		r.throwGoError(g.close())
+		return _undefined
+	}
+}
+
+func (r *Runtime) goIOCloseAsync(class string) func(FunctionCall) Value {
+	return func(call FunctionCall) Value {
+		g := r.thisGoIO(call.This, class, "closeAsync", false)
+		return g.async("closeAsync", func() func() Value {
+			err := g.close()
+			return func() Value {
+				r.throwGoError(err)
+				return _undefined
+			}
+		})
+	}
+}
+
+func (r *Runtime) createGoReaderProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("read", r.newNativeFunc(r.goReaderProto_read, nil, "read", nil, 0), true, true, true)
+	o._putProp("readAsync", r.newNativeFunc(r.goReaderProto_readAsync, nil, "readAsync", nil, 0), true, true, true)
+	o._putProp("close", r.newNativeFunc(r.goIOClose("reader"), nil, "close", nil, 0), true, true, true)
+	o._putProp("closeAsync", r.newNativeFunc(r.goIOCloseAsync("reader"), nil, "closeAsync", nil, 0), true, true, true)
+
+	return o
+}
+
+func (r *Runtime) createGoWriterProto(val *Object) objectImpl {
+	o := newBaseObjectObj(val, r.global.ObjectPrototype, classObject)
+
+	o._putProp("write", r.newNativeFunc(r.goWriterProto_write, nil, "write", nil, 1), true, true, true)
+	o._putProp("writeAsync", r.newNativeFunc(r.goWriterProto_writeAsync, nil, "writeAsync", nil, 1), true, true, true)
+	o._putProp("close", r.newNativeFunc(r.goIOClose("writer"), nil, "close", nil, 0), true, true, true)
+	o._putProp("closeAsync", r.newNativeFunc(r.goIOCloseAsync("writer"), nil, "closeAsync", nil, 0), true, true, true)
+
+	return o
+}
@@ -0,0 +1,122 @@
+package goja
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+type testCloser struct {
+	io.Writer
+	closed bool
+}
+
+func (c *testCloser) Close() error {
+	if c.closed {
+		return errors.New("already closed")
+	}
+	c.closed = true
+	return nil
+}
+
+func TestWrapReaderWriter(t *testing.T) {
+	vm := New()
+	var buf bytes.Buffer
+	w := &testCloser{Writer: &buf}
+	vm.Set("r", vm.WrapReader(strings.NewReader("hello, world")))
+	vm.Set("w", vm.WrapWriter(w))
+	res, err := vm.RunString(`
+	var chunks = [], chunk;
+	while ((chunk = r.read(5)) !== null) {
+		if (!(chunk instanceof Uint8Array)) throw new Error("not a Uint8Array");
+		chunks.push(chunk.length);
+		w.write(chunk);
+	}
+	w.write("!");
+	w.close();
+	try {
+		w.close();
+		throw new Error("no exception");
+	} catch (e) {
+		if (e.value.Error() !== "already closed") throw e;
+	}
+	chunks.join();
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := res.String(); s != "5,5,2" {
+		t.Fatal(s)
+	}
+	if s := buf.String(); s != "hello, world!" || !w.closed {
+		t.Fatal(s)
+	}
+	if vm.Get("w").Export() != w {
+		t.Fatal("export")
+	}
+}
+
+func TestWrapReaderWriterAsync(t *testing.T) {
+	vm := New()
+	l := NewLoop(vm)
+	var buf bytes.Buffer
+	err := l.Run(func(r *Runtime) {
+		r.Set("r", r.WrapReader(strings.NewReader("abcdef")))
+		r.Set("w", r.WrapWriter(&buf))
+		_, err := r.RunString(`
+		var total = 0;
+		(async function() {
+			var p1 = r.readAsync(4), p2 = r.readAsync(4);
+			try {
+				r.read();
+				throw new Error("no exception");
+			} catch (e) {
+				if (!(e instanceof TypeError)) throw e;
+			}
+			var data = new Uint8Array(2);
+			data[0] = 49;
+			data[1] = 50;
+			var p3 = w.writeAsync(data);
+			data[0] = 0;
+			total += await w.writeAsync(await p1);
+			total += await w.writeAsync(await p2);
+			total += await p3;
+			if (await r.readAsync() !== null) throw new Error("no EOF");
+		})().catch(e => { error = e });
+		var error;
+		`)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := vm.Get("error"); e != nil && e != _undefined {
+		t.Fatal(e)
+	}
+	if s := buf.String(); s != "12abcdef" {
+		t.Fatal(s)
+	}
+	if n := vm.Get("total").ToInteger(); n != 8 {
+		t.Fatal(n)
+	}
+}
+
+func TestWrapReaderLargeSize(t *testing.T) {
+	vm := New()
+	vm.Set("r", vm.WrapReader(io.MultiReader(strings.NewReader("abc"), bytes.NewReader(make([]byte, 2*maxReadSize)))))
+	res, err := vm.RunString(`
+	var a = r.read(1e9), b = r.read(1e9);
+	a.length + "," + a.buffer.byteLength + "," + b.length + "," + b.buffer.byteLength;
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, exp := res.String(), fmt.Sprintf("3,3,%d,%d", maxReadSize, maxReadSize); s != exp {
+		t.Fatal(s)
+	}
+}
@@ -0,0 +1,58 @@
+package goja
+
+// RuntimeInitializer describes how to initialise new Runtimes: a Go setup function installing the APIs and the
+// prelude scripts evaluated before any other code. New creates a Runtime and runs the initialisation on it, e.g. one
+// per request.
+//
+// This is not a snapshot of an initialised Runtime, and there is no way to fork one: the objects of a Runtime are
+// bound to it (the built-in functions and the Go functions converted by ToValue refer to their Runtime), so they
+// can be neither shared with nor copied into another Runtime. Every call of New performs the whole initialisation,
+// i.e. its cost is the cost of New() plus running the setup function and the prelude programs. Only the compilation
+// of the prelude scripts is done once (see Compile). To keep the initialisation off the path of the requests, use a
+// RuntimePool, which re-initialises the Runtimes when they are returned to it.
+type RuntimeInitializer struct {
+	setup   func(r *Runtime) error
+	prelude []*Program
+}
+
+// NewRuntimeInitializer creates a RuntimeInitializer which calls setup (if it's not nil) followed by running the
+// prelude programs.
+//
+//	prelude := goja.MustCompile("prelude.js", preludeSrc, false)
+//	initializer := goja.NewRuntimeInitializer(func(r *goja.Runtime) error {
+//		r.SetFieldNameMapper(goja.TagFieldNameMapper("json", true))
+//		return r.Set("db", db)
+//	}, prelude)
+//	// ...
+//	r, err := initializer.New()
+func NewRuntimeInitializer(setup func(r *Runtime) error, prelude ...*Program) *RuntimeInitializer {
+	return &RuntimeInitializer{
+		setup:   setup,
+		prelude: prelude,
+	}
+}
+
+// New returns a new Runtime initialised as described by the RuntimeInitializer. An error is returned if the setup
+// function or a prelude program fails. New can be called concurrently from multiple goroutines, provided the setup
+// function is safe for concurrent use.
+func (ri *RuntimeInitializer) New() (*Runtime, error) {
+	r := New()
+	if err := ri.init(r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (ri *RuntimeInitializer) init(r *Runtime) error {
+	if ri.setup != nil {
+		if err := ri.setup(r); err != nil {
+			return err
+		}
+	}
+	for _, p := range ri.prelude {
+		if _, err := r.RunProgram(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package goja
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestRuntimeInitializer(t *testing.T) {
+	prelude := MustCompile("prelude.js", `
+	const counter = { n: 0 };
+	function next() {
+		return ++counter.n + offset;
+	}
+	Object.prototype.shared = true;
+	`, false)
+	initializer := NewRuntimeInitializer(func(r *Runtime) error {
+		return r.Set("offset", 10)
+	}, prelude)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r, err := initializer.New()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			res, err := r.RunString(`next(); next(); delete Object.prototype.shared; next()`)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if res.ToInteger() != 13 {
+				t.Errorf("unexpected result: %v", res)
+			}
+		}()
+	}
+	wg.Wait()
+
+	r, err := initializer.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := r.RunString(`[next(), ({}).shared].join()`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := res.String(); s != "11,true" {
+		t.Fatal(s)
+	}
+
+	if _, err := NewRuntimeInitializer(func(*Runtime) error {
+		return errors.New("setup failed")
+	}).New(); err == nil || err.Error() != "setup failed" {
+		t.Fatal(err)
+	}
+	if _, err := NewRuntimeInitializer(nil, MustCompile("fail.js", "throw new Error('prelude failed')", false)).New(); err == nil {
+		t.Fatal("expected an error")
+	}
+}
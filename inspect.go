@@ -0,0 +1,702 @@
+package goja
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// This file contains a formatter that produces the same output as util.inspect() in Node.js with the default
+// options (no colours, compact: 3, breakLength: 80). It is used by the console builtin.
+
+const (
+	inspectBreakLength    = 80
+	inspectMaxArrayLength = 100
+	inspectCompact        = 3
+)
+
+type inspector struct {
+	r *Runtime
+
+	// maximum nesting depth, negative if unlimited
+	depth       int
+	breakLength int
+
+	indent       int
+	currentDepth int
+	seen         []*Object
+	circular     map[*Object]int
+}
+
+// inspect formats v for human consumption. Nested objects deeper than depth are abbreviated, a negative depth
+// means unlimited.
+func (r *Runtime) inspect(v Value, depth int) string {
+	ins := &inspector{r: r, depth: depth, breakLength: inspectBreakLength}
+	return ins.format(v, 0)
+}
+
+func (ins *inspector) format(v Value, level int) string {
+	switch v := v.(type) {
+	case nil:
+		return "undefined"
+	case valueString:
+		return inspectQuote(v.String())
+	case *valueBigInt:
+		return v.String() + "n"
+	case *Symbol:
+		return v.descriptiveString().String()
+	case valueFloat:
+		if v == 0 && math.Signbit(float64(v)) {
+			return "-0"
+		}
+	case *Object:
+		return ins.formatObject(v, level)
+	}
+	return v.String()
+}
+
+// inspectQuote quotes a string using single quotes unless the string contains them, in which case double quotes
+// or backticks are used if possible.
+func inspectQuote(s string) string {
+	quote := byte('\'')
+	if strings.IndexByte(s, '\'') >= 0 {
+		if strings.IndexByte(s, '"') < 0 {
+			quote = '"'
+		} else if strings.IndexByte(s, '`') < 0 && !strings.Contains(s, "${") {
+			quote = '`'
+		}
+	}
+	var sb strings.Builder
+	sb.WriteByte(quote)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == quote || c == '\\':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		case c == '\b':
+			sb.WriteString(`\b`)
+		case c == '\t':
+			sb.WriteString(`\t`)
+		case c == '\n':
+			sb.WriteString(`\n`)
+		case c == '\f':
+			sb.WriteString(`\f`)
+		case c == '\r':
+			sb.WriteString(`\r`)
+		case c < 0x20 || c == 0x7F:
+			sb.WriteString(`\x`)
+			sb.WriteByte(upperHex[c>>4])
+			sb.WriteByte(upperHex[c&0xF])
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	sb.WriteByte(quote)
+	return sb.String()
+}
+
+func isInspectIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || i > 0 && c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// constructorName returns the name of the first constructor found in the prototype chain of o, the second result
+// is false if o has a null prototype.
+func (ins *inspector) constructorName(o *Object) (string, bool) {
+	if o.self.proto() == nil {
+		return "", false
+	}
+	for obj := o; obj != nil; obj = obj.self.proto() {
+		if _, ok := obj.self.(*proxyObject); ok {
+			break
+		}
+		prop := obj.self.getOwnPropStr("constructor")
+		if p, ok := prop.(*valueProperty); ok && !p.accessor {
+			prop = p.value
+		}
+		if prop, ok := prop.(*Object); ok {
+			if _, ok := prop.self.assertCallable(); ok {
+				if name, ok := prop.self.getStr("name", nil).(valueString); ok && name.length() > 0 {
+					return name.String(), true
+				}
+			}
+		}
+	}
+	return "Object", true
+}
+
+func (ins *inspector) toStringTag(o *Object) string {
+	if _, ok := o.self.(*proxyObject); ok {
+		return ""
+	}
+	var tag Value
+	ins.r.try(func() {
+		tag = o.self.getSym(SymToStringTag, nil)
+	})
+	if s, ok := tag.(valueString); ok {
+		return s.String()
+	}
+	return ""
+}
+
+// prefix implements getPrefix() from Node.js, the result includes the trailing space.
+func inspectPrefix(ctor string, hasProto bool, tag, fallback, size string) string {
+	if !hasProto {
+		if tag != "" && tag != fallback {
+			return "[" + fallback + size + ": null prototype] [" + tag + "] "
+		}
+		return "[" + fallback + size + ": null prototype] "
+	}
+	if tag != "" && ctor != tag {
+		return ctor + size + " [" + tag + "] "
+	}
+	return ctor + size + " "
+}
+
+func (ins *inspector) propertyName(key Value) string {
+	if sym, ok := key.(*Symbol); ok {
+		return "[" + sym.descriptiveString().String() + "]"
+	}
+	s := key.String()
+	if isInspectIdentifier(s) {
+		return s
+	}
+	return inspectQuote(s)
+}
+
+// ownKeys returns the own enumerable string and symbol keys of o. If skipIndices is set, the array indices are
+// omitted.
+func (ins *inspector) ownKeys(o *Object, skipIndices bool) []Value {
+	var keys []Value
+	for _, key := range o.self.stringKeys(false, nil) {
+		if skipIndices && strToArrayIdx(key.string()) != math.MaxUint32 {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return o.self.symbols(false, keys)
+}
+
+func (ins *inspector) formatProperty(o *Object, key Value, level int) string {
+	var prop Value
+	if sym, ok := key.(*Symbol); ok {
+		prop = o.self.getOwnPropSym(sym)
+	} else {
+		prop = o.self.getOwnPropStr(key.string())
+	}
+	var value string
+	if p, ok := prop.(*valueProperty); ok && p.accessor {
+		switch {
+		case p.getterFunc != nil && p.setterFunc != nil:
+			value = "[Getter/Setter]"
+		case p.getterFunc != nil:
+			value = "[Getter]"
+		default:
+			value = "[Setter]"
+		}
+	} else {
+		if p, ok := prop.(*valueProperty); ok {
+			prop = p.get(o)
+		}
+		ins.indent += 2
+		value = ins.format(prop, level)
+		ins.indent -= 2
+	}
+	return ins.propertyName(key) + ": " + value
+}
+
+func (ins *inspector) functionBase(o *Object, ctor string, hasProto bool, tag string) string {
+	name := ""
+	if n, ok := o.self.getOwnPropStr("name").(valueString); ok {
+		name = n.String()
+	} else if p, ok := o.self.getOwnPropStr("name").(*valueProperty); ok && !p.accessor {
+		if n, ok := p.value.(valueString); ok {
+			name = n.String()
+		}
+	}
+	if c, ok := o.self.(*classFuncObject); ok {
+		base := "[class"
+		if name != "" {
+			base += " " + name
+		} else {
+			base += " (anonymous)"
+		}
+		if c.derived {
+			if super := o.self.proto(); super != nil {
+				if n, ok := super.self.getStr("name", nil).(valueString); ok && n.length() > 0 {
+					base += " extends " + n.String()
+				}
+			}
+		}
+		return base + "]"
+	}
+	typ := "Function"
+	switch o.self.(type) {
+	case *asyncFuncObject, *asyncMethodFuncObject, *asyncArrowFuncObject:
+		typ = "AsyncFunction"
+	}
+	base := "[" + typ
+	if !hasProto {
+		base += " (null prototype)"
+	}
+	if name == "" {
+		base += " (anonymous)"
+	} else {
+		base += ": " + name
+	}
+	base += "]"
+	if hasProto && ctor != typ {
+		base += " " + ctor
+	}
+	if tag != "" && ctor != tag {
+		base += " [" + tag + "]"
+	}
+	return base
+}
+
+func (ins *inspector) errorBase(o *Object) string {
+	if stack, ok := nilSafe(o.self.getStr("stack", nil)).(valueString); ok && stack.length() > 0 {
+		s := strings.TrimRight(stack.String(), "\n")
+		if ins.indent > 0 {
+			s = strings.Replace(s, "\n", "\n"+strings.Repeat(" ", ins.indent), -1)
+		}
+		return s
+	}
+	var s string
+	ins.r.try(func() {
+		s = o.String()
+	})
+	return "[" + s + "]"
+}
+
+func (ins *inspector) formatObject(o *Object, level int) string {
+	for {
+		if p, ok := o.self.(*proxyObject); ok && p.target != nil {
+			o = p.target
+			continue
+		}
+		break
+	}
+	for _, s := range ins.seen {
+		if s == o {
+			if ins.circular == nil {
+				ins.circular = make(map[*Object]int)
+			}
+			idx, exists := ins.circular[o]
+			if !exists {
+				idx = len(ins.circular) + 1
+				ins.circular[o] = idx
+			}
+			return "[Circular *" + strconv.Itoa(idx) + "]"
+		}
+	}
+
+	ctor, hasProto := ins.constructorName(o)
+	tag := ins.toStringTag(o)
+	if tag == ctor {
+		tag = ""
+	}
+	var base string
+	braces := [2]string{"{", "}"}
+	isArrayLike, isNumeric := false, false
+	var entries []string
+	var formatEntries func()
+	var keys []Value
+
+	switch obj := o.self.(type) {
+	case *typedArrayObject:
+		isArrayLike = true
+		keys = ins.ownKeys(o, true)
+		braces[0] = inspectPrefix(ctor, hasProto, tag, "TypedArray", "("+strconv.Itoa(obj.length)+")") + "["
+		braces[1] = "]"
+		if obj.length == 0 && len(keys) == 0 {
+			return braces[0] + "]"
+		}
+		isNumeric = true
+		formatEntries = func() {
+			l := obj.length
+			if l > inspectMaxArrayLength {
+				l = inspectMaxArrayLength
+			}
+			for i := 0; i < l; i++ {
+				if obj.viewedArrayBuf.detached {
+					break
+				}
+				entries = append(entries, ins.format(obj.typedArray.get(obj.offset+i), level+1))
+			}
+			if obj.length > l {
+				entries = append(entries, "... "+strconv.Itoa(obj.length-l)+" more item"+inspectPlural(obj.length-l))
+			}
+		}
+	case *mapObject:
+		keys = ins.ownKeys(o, false)
+		braces[0] = inspectPrefix(ctor, hasProto, tag, "Map", "("+strconv.Itoa(obj.m.size)+")") + "{"
+		if obj.m.size == 0 && len(keys) == 0 {
+			return braces[0] + "}"
+		}
+		formatEntries = func() {
+			ins.indent += 2
+			for iter := obj.m.newIter(); ; {
+				entry := iter.next()
+				if entry == nil {
+					break
+				}
+				entries = append(entries, ins.format(entry.key, level+1)+" => "+ins.format(entry.value, level+1))
+			}
+			ins.indent -= 2
+		}
+	case *setObject:
+		keys = ins.ownKeys(o, false)
+		braces[0] = inspectPrefix(ctor, hasProto, tag, "Set", "("+strconv.Itoa(obj.m.size)+")") + "{"
+		if obj.m.size == 0 && len(keys) == 0 {
+			return braces[0] + "}"
+		}
+		formatEntries = func() {
+			ins.indent += 2
+			for iter := obj.m.newIter(); ; {
+				entry := iter.next()
+				if entry == nil {
+					break
+				}
+				entries = append(entries, ins.format(entry.key, level+1))
+			}
+			ins.indent -= 2
+		}
+	case *weakMapObject, *weakSetObject:
+		fallback := "WeakMap"
+		if _, ok := obj.(*weakSetObject); ok {
+			fallback = "WeakSet"
+		}
+		return inspectPrefix(ctor, hasProto, tag, fallback, "") + "{ <items unknown> }"
+	case *arrayBufferObject:
+		keys = ins.ownKeys(o, false)
+		braces[0] = inspectPrefix(ctor, hasProto, tag, "ArrayBuffer", "") + "{"
+		formatEntries = func() {
+			if obj.detached {
+				entries = append(entries, "(detached)")
+				return
+			}
+			var sb strings.Builder
+			sb.WriteString("[Uint8Contents]: <")
+			for i, b := range obj.data {
+				if i == inspectMaxArrayLength {
+					sb.WriteString(" ... " + strconv.Itoa(len(obj.data)-i) + " more byte" + inspectPlural(len(obj.data)-i))
+					break
+				}
+				if i > 0 {
+					sb.WriteByte(' ')
+				}
+				sb.WriteByte(hex[b>>4])
+				sb.WriteByte(hex[b&0xF])
+			}
+			sb.WriteByte('>')
+			entries = append(entries, sb.String(), "byteLength: "+strconv.Itoa(len(obj.data)))
+		}
+	case *Promise:
+		keys = ins.ownKeys(o, false)
+		braces[0] = inspectPrefix(ctor, hasProto, tag, "Promise", "") + "{"
+		formatEntries = func() {
+			switch obj.state {
+			case PromiseStatePending:
+				entries = append(entries, "<pending>")
+			case PromiseStateRejected:
+				ins.indent += 2
+				entries = append(entries, "<rejected> "+ins.format(obj.result, level+1))
+				ins.indent -= 2
+			default:
+				ins.indent += 2
+				entries = append(entries, ins.format(obj.result, level+1))
+				ins.indent -= 2
+			}
+		}
+	case *primitiveValueObject, *stringObject:
+		var prim Value
+		typ := "String"
+		if p, ok := obj.(*primitiveValueObject); ok {
+			prim = p.pValue
+			switch prim.(type) {
+			case valueBool:
+				typ = "Boolean"
+			case *valueBigInt:
+				typ = "BigInt"
+			case *Symbol:
+				typ = "Symbol"
+			default:
+				typ = "Number"
+			}
+		} else {
+			prim = obj.(*stringObject).value
+		}
+		keys = ins.ownKeys(o, typ == "String")
+		base = "[" + typ
+		if ctor != typ {
+			if hasProto {
+				base += " (" + ctor + ")"
+			} else {
+				base += " (null prototype)"
+			}
+		}
+		base += ": " + ins.format(prim, level) + "]"
+		if tag != "" {
+			base += " [" + tag + "]"
+		}
+		if len(keys) == 0 {
+			return base
+		}
+	default:
+		if _, ok := o.self.assertCallable(); ok {
+			keys = ins.ownKeys(o, false)
+			base = ins.functionBase(o, ctor, hasProto, tag)
+			if len(keys) == 0 {
+				return base
+			}
+			break
+		}
+		if isArray(o) {
+			isArrayLike = true
+			keys = ins.ownKeys(o, true)
+			length := toLength(o.self.getStr("length", nil))
+			braces[0] = "["
+			if ctor != "Array" || tag != "" {
+				braces[0] = inspectPrefix(ctor, hasProto, tag, "Array", "("+strconv.FormatInt(length, 10)+")") + "["
+			}
+			braces[1] = "]"
+			if length == 0 && len(keys) == 0 {
+				return braces[0] + "]"
+			}
+			isNumeric = true
+			formatEntries = func() {
+				isNumeric = ins.formatArray(o, length, level, &entries)
+			}
+			break
+		}
+		switch o.self.(type) {
+		case *errorObject:
+			keys = ins.ownKeys(o, false)
+			base = ins.errorBase(o)
+			if len(keys) == 0 {
+				return base
+			}
+		case *dateObject:
+			keys = ins.ownKeys(o, false)
+			d := o.self.(*dateObject)
+			if d.isSet() {
+				base = ins.r.dateproto_toISOString(FunctionCall{This: o}).String()
+			} else {
+				base = "Invalid Date"
+			}
+			if len(keys) == 0 {
+				return base
+			}
+		case *regexpObject:
+			keys = ins.ownKeys(o, false)
+			ins.r.try(func() {
+				base = ins.r.regexpproto_toString(FunctionCall{This: o}).String()
+			})
+			if len(keys) == 0 {
+				return base
+			}
+		default:
+			keys = ins.ownKeys(o, false)
+			if ctor != "Object" || !hasProto || tag != "" {
+				braces[0] = inspectPrefix(ctor, hasProto, tag, "Object", "") + "{"
+			}
+			if len(keys) == 0 {
+				return braces[0] + "}"
+			}
+		}
+	}
+
+	if ins.depth >= 0 && level > ins.depth {
+		name := ctor
+		if !hasProto {
+			name = "Object: null prototype"
+		}
+		if isArrayLike && name == "" {
+			name = "Array"
+		}
+		return "[" + name + "]"
+	}
+
+	ins.seen = append(ins.seen, o)
+	ins.currentDepth = level
+	if formatEntries != nil {
+		formatEntries()
+	}
+	for _, key := range keys {
+		entries = append(entries, ins.formatProperty(o, key, level+1))
+	}
+	ins.seen = ins.seen[:len(ins.seen)-1]
+
+	res := ins.reduceToSingleString(entries, base, braces, isArrayLike, isNumeric, level)
+	if idx, exists := ins.circular[o]; exists {
+		res = "<ref *" + strconv.Itoa(idx) + "> " + res
+	}
+	return res
+}
+
+// formatArray appends the formatted elements of an array to entries, consecutive holes are combined. The result
+// indicates whether all elements are numbers.
+func (ins *inspector) formatArray(o *Object, length int64, level int, entries *[]string) bool {
+	numeric := true
+	holes := int64(0)
+	flushHoles := func() {
+		if holes > 0 {
+			*entries = append(*entries, "<"+strconv.FormatInt(holes, 10)+" empty item"+inspectPlural(int(holes))+">")
+			holes = 0
+		}
+	}
+	ins.indent += 2
+	i := int64(0)
+	for ; i < length && len(*entries) < inspectMaxArrayLength; i++ {
+		idx := valueInt(i)
+		if !o.self.hasOwnPropertyIdx(idx) {
+			holes++
+			numeric = false
+			continue
+		}
+		flushHoles()
+		v := o.self.getIdx(idx, nil)
+		switch v.(type) {
+		case valueInt, valueFloat, *valueBigInt:
+		default:
+			numeric = false
+		}
+		*entries = append(*entries, ins.format(v, level+1))
+	}
+	flushHoles()
+	ins.indent -= 2
+	if remaining := length - i; remaining > 0 {
+		*entries = append(*entries, "... "+strconv.FormatInt(remaining, 10)+" more item"+inspectPlural(int(remaining)))
+	}
+	return numeric
+}
+
+func inspectPlural(n int) string {
+	if n > 1 {
+		return "s"
+	}
+	return ""
+}
+
+func inspectWidth(s string) int {
+	return utf8.RuneCountInString(s)
+}
+
+// reduceToSingleString combines the entries into a single line if they fit, otherwise each entry is written on
+// a separate line (arrays of short elements are grouped into columns).
+func (ins *inspector) reduceToSingleString(entries []string, base string, braces [2]string, isArrayLike, isNumeric bool, level int) string {
+	if base != "" {
+		base += " "
+	}
+	count := len(entries)
+	if isArrayLike && count > 6 {
+		entries = ins.groupArrayElements(entries, isNumeric)
+	}
+	if ins.currentDepth-level < inspectCompact && count == len(entries) {
+		start := len(entries) + ins.indent + inspectWidth(braces[0]) + inspectWidth(base) + 10
+		totalLength := len(entries) + start
+		for _, e := range entries {
+			totalLength += inspectWidth(e)
+		}
+		if totalLength+len(entries) <= ins.breakLength && !strings.Contains(base, "\n") {
+			joined := strings.Join(entries, ", ")
+			if !strings.Contains(joined, "\n") {
+				return base + braces[0] + " " + joined + " " + braces[1]
+			}
+		}
+	}
+	indentation := "\n" + strings.Repeat(" ", ins.indent)
+	return base + braces[0] + indentation + "  " + strings.Join(entries, ","+indentation+"  ") + indentation + braces[1]
+}
+
+// groupArrayElements arranges the entries of an array in columns, see groupArrayElements() in Node.js.
+func (ins *inspector) groupArrayElements(output []string, isNumeric bool) []string {
+	totalLength, maxLength := 0, 0
+	outputLength := len(output)
+	if strings.HasPrefix(output[outputLength-1], "... ") {
+		outputLength--
+	}
+	const separatorSpace = 2
+	dataLen := make([]int, outputLength)
+	for i := 0; i < outputLength; i++ {
+		l := inspectWidth(output[i])
+		dataLen[i] = l
+		totalLength += l + separatorSpace
+		if maxLength < l {
+			maxLength = l
+		}
+	}
+	actualMax := maxLength + separatorSpace
+	if actualMax*3+ins.indent < ins.breakLength && (float64(totalLength)/float64(actualMax) > 5 || maxLength <= 6) {
+		averageBias := math.Sqrt(float64(actualMax) - float64(totalLength)/float64(len(output)))
+		biasedMax := math.Max(float64(actualMax)-3-averageBias, 1)
+		columns := int(math.Min(math.Min(
+			math.Round(math.Sqrt(2.5*biasedMax*float64(outputLength))/biasedMax),
+			math.Floor(float64(ins.breakLength-ins.indent)/float64(actualMax))),
+			math.Min(inspectCompact*4, 15)))
+		if columns <= 1 {
+			return output
+		}
+		var maxLineLength []int
+		for i := 0; i < columns; i++ {
+			lineLength := 0
+			for j := i; j < outputLength; j += columns {
+				if dataLen[j] > lineLength {
+					lineLength = dataLen[j]
+				}
+			}
+			maxLineLength = append(maxLineLength, lineLength+separatorSpace)
+		}
+		pad := func(s string, width int) string {
+			if n := width - inspectWidth(s); n > 0 {
+				if isNumeric {
+					return strings.Repeat(" ", n) + s
+				}
+				return s + strings.Repeat(" ", n)
+			}
+			return s
+		}
+		var tmp []string
+		for i := 0; i < outputLength; i += columns {
+			end := i + columns
+			if end > outputLength {
+				end = outputLength
+			}
+			var sb strings.Builder
+			j := i
+			for ; j < end-1; j++ {
+				sb.WriteString(pad(output[j]+", ", maxLineLength[j-i]))
+			}
+			if isNumeric {
+				sb.WriteString(pad(output[j], maxLineLength[j-i]-separatorSpace))
+			} else {
+				sb.WriteString(output[j])
+			}
+			tmp = append(tmp, sb.String())
+		}
+		if outputLength < len(output) {
+			tmp = append(tmp, output[outputLength])
+		}
+		return tmp
+	}
+	return output
+}
+
+// inspectKeyName is used to format the index column of console.table().
+func inspectKeyName(key Value) string {
+	if sym, ok := key.(*Symbol); ok {
+		return sym.descriptiveString().String()
+	}
+	return key.String()
+}
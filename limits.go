@@ -0,0 +1,82 @@
+package goja
+
+import "time"
+
+// SetMaxStringLength limits the length (in UTF-16 code units) of the strings created by the string concatenation
+// (the + operator and the template literals), String.prototype.concat, padStart, padEnd, repeat, replace and
+// replaceAll (including the RegExp replacements), String.raw, Array.prototype.join and toLocaleString (and those of the
+// typed arrays), Intl.ListFormat.prototype.format and JSON.stringify, a non-positive n removes the limit. When the
+// result would exceed the limit, a RangeError is thrown instead of allocating it, so a script building a string in a
+// loop fails early rather than exhausting the memory.
+func (r *Runtime) SetMaxStringLength(n int) {
+	r.maxStringLength = n
+}
+
+// SetMaxArrayLength limits the length of the arrays, a non-positive n removes the limit. Setting the length of an
+// array (directly or by adding an element) beyond the limit, and creating a longer array (e.g. with an array
+// literal, Array.from or Array.prototype.concat), throws a RangeError.
+func (r *Runtime) SetMaxArrayLength(n int) {
+	r.maxArrayLength = n
+}
+
+// SetMaxArguments limits the number of arguments passed by a spread call (f(...args)), Function.prototype.apply,
+// Reflect.apply and Reflect.construct, a non-positive n removes the limit. When exceeded, a RangeError is thrown.
+func (r *Runtime) SetMaxArguments(n int) {
+	r.maxArguments = n
+}
+
+func (r *Runtime) checkStringLength(l int64) {
+	if r.maxStringLength > 0 && l > int64(r.maxStringLength) {
+		panic(r.newError(r.global.RangeError, "Invalid string length"))
+	}
+}
+
+// limitedStringBuilder is a valueStringBuilder which checks the length of the result against the limit set by
+// SetMaxStringLength before each write.
+type limitedStringBuilder struct {
+	valueStringBuilder
+	r *Runtime
+}
+
+func (b *limitedStringBuilder) WriteString(s valueString) {
+	if b.r.maxStringLength > 0 {
+		b.r.checkStringLength(int64(b.length()) + int64(s.length()))
+	}
+	b.valueStringBuilder.WriteString(s)
+}
+
+func (b *limitedStringBuilder) WriteRune(r rune) {
+	if b.r.maxStringLength > 0 {
+		n := int64(1)
+		if r > 0xFFFF {
+			n = 2
+		}
+		b.r.checkStringLength(int64(b.length()) + n)
+	}
+	b.valueStringBuilder.WriteRune(r)
+}
+
+func (r *Runtime) checkArrayLength(l int64) {
+	if r.maxArrayLength > 0 && l > int64(r.maxArrayLength) {
+		panic(r.newError(r.global.RangeError, "Invalid array length"))
+	}
+}
+
+func (r *Runtime) checkArguments(n int64) {
+	if r.maxArguments > 0 && n > int64(r.maxArguments) {
+		panic(r.newError(r.global.RangeError, "Too many arguments in function call (only %d allowed)", r.maxArguments))
+	}
+}
+
+// SetRegExpTimeout limits the time a single match of a regular expression may take, a non-positive d removes the
+// limit. The regular expressions which need backtracking (e.g. with backreferences or lookarounds) are matched by a
+// backtracking engine, so a pattern such as /(a+)+\1b/ may take exponential time on a short input. When the limit is
+// exceeded, a RangeError is thrown, which the script can catch. The limit applies to the RegExp objects created after
+// the call.
+func (r *Runtime) SetRegExpTimeout(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	r.regexpTimeout = d
+	r.timedRegexps = nil
+}
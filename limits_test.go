@@ -0,0 +1,190 @@
+package goja
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMaxStringLength(t *testing.T) {
+	vm := New()
+	vm.SetMaxStringLength(100)
+	vm.testScriptWithTestLib(`
+	let s = "";
+	assert.throws(RangeError, () => { for (;;) s += "xx"; });
+	assert.sameValue(s.length, 100);
+	assert.throws(RangeError, () => "x".repeat(101));
+	assert.throws(RangeError, () => "x".padStart(101));
+	assert.throws(RangeError, () => "x".padEnd(1e10, "ab"));
+	assert.throws(RangeError, () => s.concat("x"));
+	assert.throws(RangeError, () => `+"`${s}x`"+`);
+	assert.throws(RangeError, () => [s, ""].join());
+	assert.throws(RangeError, () => new Array(60).join("ab"));
+	assert.sameValue("x".repeat(100).length, 100);
+	assert.sameValue(`+"`${s.slice(1)}!`"+`.length, 100);
+	`, _undefined, t)
+
+	vm.SetMaxStringLength(0)
+	if _, err := vm.RunString(`"x".repeat(1000)`); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMaxStringLengthReplace(t *testing.T) {
+	vm := New()
+	vm.SetMaxStringLength(1000)
+	vm.testScriptWithTestLib(`
+	let s = "aa";
+	assert.throws(RangeError, () => { for (;;) s = s.replace("a", s); });
+	assert.sameValue(s.length, 513);
+	assert.throws(RangeError, () => s.replace("a", () => s + s));
+	assert.throws(RangeError, () => s.replace(/a/, "$'$'"));
+	assert.sameValue(s.replace("a", "b").length, 513);
+	`, _undefined, t)
+}
+
+func TestMaxStringLengthReplaceAll(t *testing.T) {
+	vm := New()
+	vm.SetMaxStringLength(1000)
+	vm.testScriptWithTestLib(`
+	let s = "aa";
+	assert.throws(RangeError, () => { for (;;) s = s.replaceAll("a", s); });
+	assert.sameValue(s.length, 256);
+	assert.throws(RangeError, () => s.replaceAll(/a/g, "$&".repeat(100)));
+	assert.throws(RangeError, () => s.replaceAll("a", () => "x".repeat(100)));
+	assert.sameValue(s.replaceAll("a", "bb").length, 512);
+	`, _undefined, t)
+}
+
+func TestMaxStringLengthJSONStringify(t *testing.T) {
+	vm := New()
+	vm.SetMaxStringLength(1000)
+	vm.testScriptWithTestLib(`
+	let o = [];
+	assert.throws(RangeError, () => { for (;;) { o = [o, o]; JSON.stringify(o); } });
+	assert.throws(RangeError, () => JSON.stringify(new Array(500).fill("\u00e9")));
+	assert.throws(RangeError, () => JSON.stringify({a: "x".repeat(999)}));
+	assert.sameValue(JSON.stringify({a: "x".repeat(992)}).length, 1000);
+	`, _undefined, t)
+}
+
+func TestMaxStringLengthTemplate(t *testing.T) {
+	vm := New()
+	vm.SetMaxStringLength(1000)
+	vm.testScriptWithTestLib(`
+	let s = "x";
+	assert.throws(RangeError, () => { for (;;) s = `+"`${s}-${s}`"+`; });
+	assert.sameValue(s.length, 511);
+	assert.sameValue(`+"`${s}${s.slice(22)}`"+`.length, 1000);
+	`, _undefined, t)
+}
+
+func TestMaxArrayLength(t *testing.T) {
+	vm := New()
+	vm.SetMaxArrayLength(10)
+	vm.testScriptWithTestLib(`
+	const a = [];
+	assert.throws(RangeError, () => { for (;;) a.push(1); });
+	assert.sameValue(a.length, 10);
+	assert.throws(RangeError, () => { a[10] = 1; });
+	assert.throws(RangeError, () => { a.length = 11; });
+	assert.throws(RangeError, () => { [][1e9] = 1; });
+	assert.throws(RangeError, () => new Array(11));
+	assert.throws(RangeError, () => [...a, 1]);
+	assert.throws(RangeError, () => [0,1,2,3,4,5,6,7,8,9,10]);
+	assert.throws(RangeError, () => a.concat(a));
+	assert.throws(RangeError, () => Array.from({length: 11}));
+	a.length = 5;
+	assert.sameValue(a.concat(a).length, 10);
+	`, _undefined, t)
+}
+
+func TestMaxArguments(t *testing.T) {
+	vm := New()
+	vm.SetMaxArguments(5)
+	vm.testScriptWithTestLib(`
+	function f() { return arguments.length; }
+	const args = [1, 2, 3, 4, 5, 6];
+	assert.throws(RangeError, () => f(...args));
+	assert.throws(RangeError, () => f.apply(null, args));
+	assert.throws(RangeError, () => f.apply(null, {length: 1e9}));
+	assert.throws(RangeError, () => Reflect.apply(f, null, args));
+	assert.throws(RangeError, () => Reflect.construct(f, args));
+	assert.sameValue(f(...args.slice(1)), 5);
+	assert.sameValue(f.apply(null, args.slice(1)), 5);
+	`, _undefined, t)
+}
+
+func TestRegExpTimeout(t *testing.T) {
+	prg := MustCompile("test.js", `
+	function run(input) {
+		return /(a+)+\1b/.test(input);
+	}
+	`, false)
+	vm := New()
+	vm.SetRegExpTimeout(50 * time.Millisecond)
+	if _, err := vm.RunProgram(prg); err != nil {
+		t.Fatal(err)
+	}
+	vm.testScriptWithTestLib(`
+	const input = "a".repeat(30) + "!";
+	assert.throws(RangeError, () => run(input));
+	assert.throws(RangeError, () => new RegExp(/(a+)+\1b/).exec(input));
+	assert.throws(RangeError, () => input.replace(/(a+)+\1b/g, ""));
+	assert.sameValue(run("aab"), true);
+	let matches = 0;
+	for (let i = 0; i < 100; i++) {
+		if (run("aab")) matches++;
+	}
+	assert.sameValue(matches, 100);
+	`, _undefined, t)
+	if n := len(vm.timedRegexps); n != 1 {
+		t.Fatalf("the pattern has been compiled %d times", n)
+	}
+
+	vm1 := New()
+	if _, err := vm1.RunProgram(prg); err != nil {
+		t.Fatal(err)
+	}
+	res, err := vm1.RunString(`run("aaab")`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.ToBoolean() {
+		t.Fatal(res)
+	}
+}
+
+func TestMaxStringLengthRaw(t *testing.T) {
+	vm := New()
+	vm.SetMaxStringLength(1000)
+	vm.testScriptWithTestLib(`
+	const s = "x".repeat(400);
+	assert.throws(RangeError, () => String.raw({raw: [s, s, s]}, s, s));
+	assert.throws(RangeError, () => String.raw({raw: {length: 1e6}}, s));
+	assert.sameValue(String.raw({raw: [s, s]}, "-".repeat(200)).length, 1000);
+	`, _undefined, t)
+}
+
+func TestMaxStringLengthToLocaleString(t *testing.T) {
+	vm := New()
+	vm.SetMaxStringLength(1000)
+	vm.testScriptWithTestLib(`
+	const s = "x".repeat(400);
+	const item = {toLocaleString() { return s; }};
+	assert.throws(RangeError, () => [item, item, item].toLocaleString());
+	assert.sameValue([item, item].toLocaleString().length, 801);
+	assert.throws(RangeError, () => new Uint8Array(600).toLocaleString());
+	assert.throws(RangeError, () => new Uint8Array(10).join(s));
+	`, _undefined, t)
+}
+
+func TestMaxStringLengthListFormat(t *testing.T) {
+	vm := New()
+	vm.SetMaxStringLength(1000)
+	vm.testScriptWithTestLib(`
+	const s = "x".repeat(400);
+	const lf = new Intl.ListFormat("en");
+	assert.throws(RangeError, () => lf.format([s, s, s]));
+	assert.sameValue(lf.format([s, s]).length, 805);
+	`, _undefined, t)
+}
@@ -0,0 +1,116 @@
+package goja
+
+import "golang.org/x/text/language"
+
+// LocaleProvider supplies the locale data used by Number.prototype.toLocaleString(), Date.prototype.toLocaleString(),
+// toLocaleDateString(), toLocaleTimeString() and the Intl objects.
+//
+// The locale arguments are canonical language tags without extensions. When a requested locale is not supported
+// the less specific tags are tried (e.g. "de-CH" after "de-CH-1996", then "de"), and if none of the requested locales
+// is supported, DefaultLocale() is used.
+//
+// The Intl objects use the provider for their default locale and Intl.RelativeTimeFormat also formats the numbers
+// with its symbols. The collation, plural rules, list patterns and relative time patterns come from the built-in data.
+//
+// See SetLocaleProvider.
+type LocaleProvider interface {
+	// DefaultLocale returns the language tag of the locale used when none of the requested locales is supported.
+	DefaultLocale() string
+
+	// NumberSymbols returns the symbols used to format numbers in the locale. The second result is false if the
+	// locale is not supported.
+	NumberSymbols(locale string) (NumberSymbols, bool)
+
+	// DateTimeLayouts returns the layouts used to format dates in the locale. The second result is false if the
+	// locale is not supported.
+	DateTimeLayouts(locale string) (DateTimeLayouts, bool)
+}
+
+// NumberSymbols are the locale specific symbols used to format numbers.
+type NumberSymbols struct {
+	Group, Decimal string
+
+	// MinimumGroupingDigits is the minimum number of digits in the most significant group for the grouping to be
+	// used, e.g. with 2 the number 1234 is formatted without the grouping separator, but 12345 is.
+	MinimumGroupingDigits int
+}
+
+// DateTimeLayouts are the layouts (in the format accepted by time.Time.Format) used to format dates.
+type DateTimeLayouts struct {
+	// DateTime is used by Date.prototype.toLocaleString(), Date by toLocaleDateString() and Time by
+	// toLocaleTimeString().
+	DateTime, Date, Time string
+}
+
+var defaultNumberSymbols = NumberSymbols{Group: ",", Decimal: ".", MinimumGroupingDigits: 1}
+
+var defaultNumberSymbolsData = map[string]NumberSymbols{
+	"de": {Group: ".", Decimal: ",", MinimumGroupingDigits: 1},
+	"es": {Group: ".", Decimal: ",", MinimumGroupingDigits: 2},
+	"fr": {Group: "\u202f", Decimal: ",", MinimumGroupingDigits: 1},
+}
+
+var defaultDateTimeLayouts = DateTimeLayouts{
+	DateTime: datetimeLayout_en_GB,
+	Date:     dateLayout_en_GB,
+	Time:     timeLayout_en_GB,
+}
+
+type defaultLocaleProvider struct{}
+
+// DefaultLocaleProvider returns the LocaleProvider used by a Runtime unless SetLocaleProvider is called. It supports
+// all locales: the numbers are formatted with the symbols of the locale's language (the "en" ones if there is no data
+// for the language), the dates are formatted the same way for all locales, as "MM/DD/YYYY, hh:mm:ss".
+// The default locale is "en-US".
+func DefaultLocaleProvider() LocaleProvider {
+	return defaultLocaleProvider{}
+}
+
+func (defaultLocaleProvider) DefaultLocale() string {
+	return intlDefaultLocale
+}
+
+func (defaultLocaleProvider) NumberSymbols(locale string) (NumberSymbols, bool) {
+	base, _ := language.Make(locale).Base()
+	if s, ok := defaultNumberSymbolsData[base.String()]; ok {
+		return s, true
+	}
+	return defaultNumberSymbols, true
+}
+
+func (defaultLocaleProvider) DateTimeLayouts(string) (DateTimeLayouts, bool) {
+	return defaultDateTimeLayouts, true
+}
+
+func (r *Runtime) locales() LocaleProvider {
+	if r.localeProvider != nil {
+		return r.localeProvider
+	}
+	return defaultLocaleProvider{}
+}
+
+// localeNumberSymbols returns the number symbols of the best available locale for the requested ones.
+func (r *Runtime) localeNumberSymbols(requested []string) (string, NumberSymbols) {
+	p := r.locales()
+	l := r.intlLookupLocale(func(locale string) bool {
+		_, ok := p.NumberSymbols(locale)
+		return ok
+	}, requested)
+	if s, ok := p.NumberSymbols(l.base); ok {
+		return l.base, s
+	}
+	return l.base, defaultNumberSymbols
+}
+
+// localeDateTimeLayouts returns the date layouts of the best available locale for the requested ones.
+func (r *Runtime) localeDateTimeLayouts(requested []string) DateTimeLayouts {
+	p := r.locales()
+	l := r.intlLookupLocale(func(locale string) bool {
+		_, ok := p.DateTimeLayouts(locale)
+		return ok
+	}, requested)
+	if layouts, ok := p.DateTimeLayouts(l.base); ok {
+		return layouts
+	}
+	return defaultDateTimeLayouts
+}
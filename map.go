@@ -28,7 +28,7 @@ func (m *orderedMap) lookup(key Value) (h uint64, entry, hPrev *mapEntry) {
 		key = intToValue(0)
 	}
 	h = key.hash(m.hash)
-	for entry = m.hashTable[h]; entry != nil && !entry.key.SameAs(key); hPrev, entry = entry, entry.hNext {
+	for entry = m.hashTable[h]; entry != nil && !sameValueZero(entry.key, key); hPrev, entry = entry, entry.hNext {
 	}
 	return
 }
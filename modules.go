@@ -0,0 +1,997 @@
+package goja
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/dop251/goja/parser"
+	"github.com/dop251/goja/unistring"
+)
+
+// ModuleRecord is the interface of a module as defined in https://tc39.es/ecma262/#sec-abstract-module-records.
+// It is implemented by SourceTextModuleRecord (see Runtime.CompileModule). A host can provide its own
+// implementations, for example to expose native Go values as a module.
+type ModuleRecord interface {
+	// GetExportedNames returns the list of all names that are either directly or indirectly exported
+	// from this module. The exportStarSet is used to detect 'export *' cycles and should be omitted
+	// when called from outside.
+	GetExportedNames(exportStarSet ...ModuleRecord) []string
+
+	// ResolveExport returns the binding for the given exported name. It returns nil if the name cannot be
+	// resolved, in which case the second return value indicates whether the name is ambiguous (i.e. it is
+	// provided by more than one 'export *' declaration). The resolveSet is used to detect circular
+	// import/export relationships and should be omitted when called from outside.
+	ResolveExport(exportName string, resolveSet ...ResolveSetElement) (*ResolvedBinding, bool)
+
+	// Link prepares the module for evaluation by resolving all its dependencies (transitively) and
+	// creating the module environment.
+	Link() error
+
+	// Evaluate evaluates the module and all its dependencies. The module must be linked. The result of
+	// the evaluation is remembered, i.e. subsequent calls do nothing and return the same error (if any).
+	Evaluate() error
+
+	// GetBindingValue returns the current value of the binding with the given name (as returned in
+	// ResolvedBinding.BindingName). It returns nil if the binding has not been initialised yet.
+	GetBindingValue(name string) Value
+}
+
+// ResolveSetElement is an item of the resolveSet used by ModuleRecord.ResolveExport.
+type ResolveSetElement struct {
+	Module     ModuleRecord
+	ExportName string
+}
+
+// ResolvedBinding is the result of ModuleRecord.ResolveExport. If BindingName is empty the binding refers
+// to the namespace object of the Module.
+type ResolvedBinding struct {
+	Module      ModuleRecord
+	BindingName string
+}
+
+// ResolveModuleFunc is the host hook that resolves a module specifier found in an import declaration, an
+// export declaration or an import() call. The referrer is the importing module, it's nil if import() is
+// called from a script. The function must return the same ModuleRecord for the same (referrer, specifier)
+// pair.
+type ResolveModuleFunc func(referrer ModuleRecord, specifier string) (ModuleRecord, error)
+
+// ImportAttribute is an attribute of a module request, e.g. type: "json" in
+// 'import data from "./data.json" with { type: "json" }'.
+type ImportAttribute struct {
+	Key   string
+	Value string
+}
+
+// ModuleRequest is a module specifier together with the import attributes it has been requested with.
+// The attributes are sorted by key.
+type ModuleRequest struct {
+	Specifier  string
+	Attributes []ImportAttribute
+}
+
+// Attribute returns the value of the import attribute with the given key.
+func (req ModuleRequest) Attribute(key string) (string, bool) {
+	for _, a := range req.Attributes {
+		if a.Key == key {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+func (req ModuleRequest) key() string {
+	if len(req.Attributes) == 0 {
+		return req.Specifier
+	}
+	var b strings.Builder
+	b.WriteString(req.Specifier)
+	for _, a := range req.Attributes {
+		b.WriteByte(0)
+		b.WriteString(a.Key)
+		b.WriteByte(0)
+		b.WriteString(a.Value)
+	}
+	return b.String()
+}
+
+// ResolveModuleRequestFunc is like ResolveModuleFunc, but it also receives the import attributes so that the
+// host can implement different module types (e.g. JSON or CSS modules). The function must return the same
+// ModuleRecord for the same (referrer, request) pair.
+type ResolveModuleRequestFunc func(referrer ModuleRecord, request ModuleRequest) (ModuleRecord, error)
+
+// isSupportedImportAttribute returns true if the import attribute key is supported
+// (https://tc39.es/ecma262/#sec-hostgetsupportedimportattributes).
+func isSupportedImportAttribute(key string) bool {
+	return key == "type"
+}
+
+// MetaProperty is a property of the import.meta object, see Runtime.SetGetImportMetaProperties.
+type MetaProperty struct {
+	Key   string
+	Value Value
+}
+
+type moduleStatus uint8
+
+const (
+	moduleUnlinked moduleStatus = iota
+	moduleLinking
+	moduleLinked
+	moduleEvaluating
+	moduleEvaluated
+)
+
+type importEntry struct {
+	moduleRequest ModuleRequest
+	importName    string
+	localName     unistring.String
+	namespace     bool // import * as ns from "m"
+}
+
+type exportEntry struct {
+	exportName    string
+	moduleRequest ModuleRequest
+	importName    string
+	localName     unistring.String
+	namespace     bool // export * as ns from "m"
+	offset        int
+}
+
+// SourceTextModuleRecord is a module compiled from JavaScript source code (see Runtime.CompileModule).
+type SourceTextModuleRecord struct {
+	r    *Runtime
+	name string
+	p    *Program
+
+	bodyStart int
+	stashSize int
+	names     map[unistring.String]uint32
+
+	requestedModules      []ModuleRequest
+	importEntries         []importEntry
+	localExportEntries    []exportEntry
+	indirectExportEntries []exportEntry
+	starExportEntries     []exportEntry
+
+	loadedModules map[string]ModuleRecord // keyed by ModuleRequest.key()
+
+	status                     moduleStatus
+	evaluationError            error
+	dfsIndex, dfsAncestorIndex int
+
+	env        *stash
+	importMeta *Object
+}
+
+// importedBinding is placed into the module environment for each imported binding. It is dereferenced on
+// every access so that the imported bindings are live.
+type importedBinding struct {
+	valueUnresolved
+	module ModuleRecord
+	name   string
+
+	target *SourceTextModuleRecord
+	idx    uint32
+}
+
+func (b *importedBinding) get() Value {
+	if m := b.target; m != nil {
+		if m.env == nil {
+			return nil
+		}
+		return m.env.values[b.idx]
+	}
+	return b.module.GetBindingValue(b.name)
+}
+
+type namespaceObject struct {
+	baseObject
+	module  ModuleRecord
+	exports []string
+}
+
+// SetResolveModule sets the host hook used to resolve module specifiers. Without it (or the one set by
+// SetResolveModuleRequest) any attempt to link a module that has dependencies, or to call import(), will fail.
+// Requests with a 'type' import attribute other than "json" cannot be resolved with this hook.
+func (r *Runtime) SetResolveModule(fn ResolveModuleFunc) {
+	r.resolveModuleFunc = fn
+}
+
+// SetResolveModuleRequest sets the host hook used to resolve module requests, including their import attributes.
+// If set, it takes precedence over the hook set by SetResolveModule.
+//
+// Requests with the 'type: "json"' attribute must be resolved to a module created by CompileJSONModule,
+// and such modules cannot be imported without this attribute.
+func (r *Runtime) SetResolveModuleRequest(fn ResolveModuleRequestFunc) {
+	r.resolveModuleRequestFunc = fn
+}
+
+// SetGetImportMetaProperties sets the host hook that provides the properties of the import.meta object
+// for the given module (for example 'url' or 'resolve'). The hook is called once per module, when
+// import.meta is accessed for the first time. Without it import.meta is an empty object.
+func (r *Runtime) SetGetImportMetaProperties(fn func(ModuleRecord) []MetaProperty) {
+	r.getImportMetaProperties = fn
+}
+
+func (r *Runtime) getImportMeta(m *SourceTextModuleRecord) *Object {
+	if m.importMeta == nil {
+		o := r.newBaseObject(nil, classObject)
+		if fn := r.getImportMetaProperties; fn != nil {
+			for _, p := range fn(m) {
+				o._putProp(unistring.NewFromString(p.Key), p.Value, true, true, true)
+			}
+		}
+		m.importMeta = o.val
+	}
+	return m.importMeta
+}
+
+func (r *Runtime) resolveModule(referrer ModuleRecord, req ModuleRequest) (ModuleRecord, error) {
+	specifier := req.Specifier
+	typ, hasType := req.Attribute("type")
+	var m ModuleRecord
+	var err error
+	switch {
+	case r.resolveModuleRequestFunc != nil:
+		m, err = r.resolveModuleRequestFunc(referrer, req)
+	case r.resolveModuleFunc == nil:
+		return nil, &Exception{
+			val: r.NewTypeError("Cannot resolve module '%s': no module resolver has been set", specifier),
+		}
+	case hasType && typ != "json":
+		return nil, &Exception{
+			val: r.NewTypeError("Cannot resolve module '%s': unsupported module type '%s'", specifier, typ),
+		}
+	default:
+		m, err = r.resolveModuleFunc(referrer, specifier)
+	}
+	if err == nil {
+		if m == nil {
+			err = &Exception{
+				val: r.NewTypeError("Cannot find module '%s'", specifier),
+			}
+		} else if _, isJSON := m.(*JSONModuleRecord); isJSON != (hasType && typ == "json") {
+			if isJSON {
+				err = &Exception{
+					val: r.NewTypeError("Module '%s' is a JSON module and must be imported with type: \"json\"", specifier),
+				}
+			} else {
+				err = &Exception{
+					val: r.NewTypeError("Module '%s' is not a JSON module", specifier),
+				}
+			}
+		}
+	}
+	return m, err
+}
+
+// CompileModule parses and compiles the given source code as an ES module. The returned module is bound
+// to the Runtime, it has to be linked and evaluated before its exports can be accessed.
+func (r *Runtime) CompileModule(name, src string) (*SourceTextModuleRecord, error) {
+	m, err := compileModule(name, src, r.tailCalls, r.parserOptions...)
+	if err != nil {
+		return nil, r.wrapCompilerError(err)
+	}
+	m.r = r
+	return m, nil
+}
+
+func compileModule(name, src string, tailCalls bool, parserOptions ...parser.Option) (m *SourceTextModuleRecord, err error) {
+	prg, err1 := parser.ParseModule(nil, name, src, 0, parserOptions...)
+	if err1 != nil {
+		return nil, &CompilerSyntaxError{
+			CompilerError: CompilerError{
+				Message: err1.Error(),
+			},
+		}
+	}
+
+	c := newCompiler()
+	c.tailCalls = tailCalls
+
+	defer func() {
+		if x := recover(); x != nil {
+			m = nil
+			switch x1 := x.(type) {
+			case *CompilerSyntaxError:
+				err = x1
+			default:
+				panic(x)
+			}
+		}
+	}()
+
+	m = &SourceTextModuleRecord{
+		name: name,
+	}
+	c.compileModule(prg, m)
+	m.p = c.p
+	return
+}
+
+// JSONModuleRecord is a module created from JSON source (see Runtime.CompileJSONModule). Its only export
+// is 'default' which is the parsed value (https://tc39.es/ecma262/#sec-parse-json-module).
+type JSONModuleRecord struct {
+	name  string
+	value Value
+}
+
+// CompileJSONModule parses the given JSON source and creates a module that can be returned by the module
+// resolver for requests with the 'type: "json"' import attribute. The returned error is a SyntaxError
+// exception if the source is not valid JSON.
+func (r *Runtime) CompileJSONModule(name, src string) (*JSONModuleRecord, error) {
+	m := &JSONModuleRecord{
+		name: name,
+	}
+	err := r.try(func() {
+		m.value = r.builtinJSON_parse(FunctionCall{Arguments: []Value{newStringValue(src)}})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Name returns the name the module has been compiled with.
+func (m *JSONModuleRecord) Name() string {
+	return m.name
+}
+
+func (m *JSONModuleRecord) GetExportedNames(...ModuleRecord) []string {
+	return []string{"default"}
+}
+
+func (m *JSONModuleRecord) ResolveExport(exportName string, _ ...ResolveSetElement) (*ResolvedBinding, bool) {
+	if exportName != "default" {
+		return nil, false
+	}
+	return &ResolvedBinding{
+		Module:      m,
+		BindingName: "default",
+	}, false
+}
+
+func (m *JSONModuleRecord) Link() error {
+	return nil
+}
+
+func (m *JSONModuleRecord) Evaluate() error {
+	return nil
+}
+
+func (m *JSONModuleRecord) GetBindingValue(name string) Value {
+	if name != "default" {
+		return nil
+	}
+	return m.value
+}
+
+// ModuleNamespace returns the namespace object of the module (https://tc39.es/ecma262/#sec-getmodulenamespace).
+func (r *Runtime) ModuleNamespace(m ModuleRecord) *Object {
+	return r.getModuleNamespace(m)
+}
+
+func (r *Runtime) getModuleNamespace(m ModuleRecord) *Object {
+	if ns := r.moduleNamespaces[m]; ns != nil {
+		return ns
+	}
+	var exports []string
+	for _, name := range m.GetExportedNames() {
+		if resolution, _ := m.ResolveExport(name); resolution != nil {
+			exports = append(exports, name)
+		}
+	}
+	sort.Strings(exports)
+
+	o := &Object{runtime: r}
+	ns := &namespaceObject{
+		module:  m,
+		exports: exports,
+	}
+	ns.class = classObject
+	ns.val = o
+	o.self = ns
+	ns.init()
+	ns._putSym(SymToStringTag, valueProp(asciiString("Module"), false, false, false))
+
+	if r.moduleNamespaces == nil {
+		r.moduleNamespaces = make(map[ModuleRecord]*Object)
+	}
+	r.moduleNamespaces[m] = o
+	return o
+}
+
+// Name returns the name the module has been compiled with.
+func (m *SourceTextModuleRecord) Name() string {
+	return m.name
+}
+
+func (m *SourceTextModuleRecord) GetExportedNames(exportStarSet ...ModuleRecord) []string {
+	for _, s := range exportStarSet {
+		if s == m {
+			// circular 'export *'
+			return nil
+		}
+	}
+	exportStarSet = append(exportStarSet, m)
+	var names []string
+	for _, e := range m.localExportEntries {
+		names = append(names, e.exportName)
+	}
+	for _, e := range m.indirectExportEntries {
+		names = append(names, e.exportName)
+	}
+	for _, e := range m.starExportEntries {
+		requestedModule := m.loadedModules[e.moduleRequest.key()]
+		if requestedModule == nil {
+			continue
+		}
+	outer:
+		for _, n := range requestedModule.GetExportedNames(exportStarSet...) {
+			if n == "default" {
+				continue
+			}
+			for _, existing := range names {
+				if existing == n {
+					continue outer
+				}
+			}
+			names = append(names, n)
+		}
+	}
+	return names
+}
+
+func (m *SourceTextModuleRecord) ResolveExport(exportName string, resolveSet ...ResolveSetElement) (*ResolvedBinding, bool) {
+	for _, r := range resolveSet {
+		if r.Module == m && r.ExportName == exportName {
+			// circular import request
+			return nil, false
+		}
+	}
+	resolveSet = append(resolveSet, ResolveSetElement{Module: m, ExportName: exportName})
+	for _, e := range m.localExportEntries {
+		if e.exportName == exportName {
+			return &ResolvedBinding{
+				Module:      m,
+				BindingName: e.localName.String(),
+			}, false
+		}
+	}
+	for _, e := range m.indirectExportEntries {
+		if e.exportName == exportName {
+			importedModule := m.loadedModules[e.moduleRequest.key()]
+			if importedModule == nil {
+				return nil, false
+			}
+			if e.namespace {
+				return &ResolvedBinding{
+					Module: importedModule,
+				}, false
+			}
+			return importedModule.ResolveExport(e.importName, resolveSet...)
+		}
+	}
+	if exportName == "default" {
+		// A default export cannot be provided by export *
+		return nil, false
+	}
+	var starResolution *ResolvedBinding
+	for _, e := range m.starExportEntries {
+		importedModule := m.loadedModules[e.moduleRequest.key()]
+		if importedModule == nil {
+			continue
+		}
+		resolution, ambiguous := importedModule.ResolveExport(exportName, resolveSet...)
+		if ambiguous {
+			return nil, true
+		}
+		if resolution != nil {
+			if starResolution == nil {
+				starResolution = resolution
+			} else if resolution.Module != starResolution.Module || resolution.BindingName != starResolution.BindingName {
+				return nil, true
+			}
+		}
+	}
+	return starResolution, false
+}
+
+func (m *SourceTextModuleRecord) GetBindingValue(name string) Value {
+	if m.env == nil {
+		return nil
+	}
+	idx, exists := m.names[unistring.NewFromString(name)]
+	if !exists {
+		return nil
+	}
+	v := m.env.values[idx&^maskTyp]
+	if b, ok := v.(*importedBinding); ok {
+		return b.get()
+	}
+	return v
+}
+
+// Link resolves all dependencies of the module (transitively) using the resolver set by
+// Runtime.SetResolveModule and creates the module environment (https://tc39.es/ecma262/#sec-moduledeclarationlinking).
+func (m *SourceTextModuleRecord) Link() error {
+	err := m.link()
+	m.r.leaveModuleOp(err)
+	return err
+}
+
+func (m *SourceTextModuleRecord) link() error {
+	switch m.status {
+	case moduleLinking, moduleEvaluating:
+		return errors.New("module is being linked or evaluated")
+	}
+	var stack []*SourceTextModuleRecord
+	if _, err := m.innerModuleLinking(&stack, 0); err != nil {
+		for _, mod := range stack {
+			mod.status = moduleUnlinked
+			mod.env = nil
+		}
+		return err
+	}
+	return nil
+}
+
+func (m *SourceTextModuleRecord) innerModuleLinking(stack *[]*SourceTextModuleRecord, index int) (int, error) {
+	if m.status != moduleUnlinked {
+		return index, nil
+	}
+	m.status = moduleLinking
+	m.dfsIndex = index
+	m.dfsAncestorIndex = index
+	index++
+	*stack = append(*stack, m)
+	for _, required := range m.requestedModules {
+		requiredModule, err := m.resolveImportedModule(required)
+		if err != nil {
+			return index, err
+		}
+		if rm, ok := requiredModule.(*SourceTextModuleRecord); ok {
+			index, err = rm.innerModuleLinking(stack, index)
+			if err != nil {
+				return index, err
+			}
+			if rm.status == moduleLinking && rm.dfsAncestorIndex < m.dfsAncestorIndex {
+				m.dfsAncestorIndex = rm.dfsAncestorIndex
+			}
+		} else if err := requiredModule.Link(); err != nil {
+			return index, err
+		}
+	}
+	if err := m.initializeEnvironment(); err != nil {
+		return index, err
+	}
+	if m.dfsAncestorIndex == m.dfsIndex {
+		for {
+			l := len(*stack) - 1
+			mod := (*stack)[l]
+			*stack = (*stack)[:l]
+			mod.status = moduleLinked
+			if mod == m {
+				break
+			}
+		}
+	}
+	return index, nil
+}
+
+func (m *SourceTextModuleRecord) resolveImportedModule(req ModuleRequest) (ModuleRecord, error) {
+	key := req.key()
+	if mod := m.loadedModules[key]; mod != nil {
+		return mod, nil
+	}
+	mod, err := m.r.resolveModule(m, req)
+	if err != nil {
+		return nil, err
+	}
+	if m.loadedModules == nil {
+		m.loadedModules = make(map[string]ModuleRecord)
+	}
+	m.loadedModules[key] = mod
+	return mod, nil
+}
+
+func (m *SourceTextModuleRecord) resolutionError(moduleRequest, importName string, ambiguous bool) error {
+	r := m.r
+	if ambiguous {
+		return &Exception{
+			val: r.newError(r.global.SyntaxError, "The requested module '%s' contains conflicting star exports for name '%s'", moduleRequest, importName),
+		}
+	}
+	return &Exception{
+		val: r.newError(r.global.SyntaxError, "The requested module '%s' does not provide an export named '%s'", moduleRequest, importName),
+	}
+}
+
+func (m *SourceTextModuleRecord) initializeEnvironment() error {
+	r := m.r
+	for _, e := range m.indirectExportEntries {
+		if resolution, ambiguous := m.ResolveExport(e.exportName); resolution == nil {
+			return m.resolutionError(e.moduleRequest.Specifier, e.importName, ambiguous)
+		}
+	}
+
+	env := &stash{
+		outer:    &r.global.stash,
+		values:   make([]Value, m.stashSize),
+		names:    m.names,
+		funcType: funcModule,
+	}
+	for _, idx := range m.names {
+		if idx&maskVar != 0 {
+			env.values[idx&^maskTyp] = _undefined
+		}
+	}
+
+	for _, in := range m.importEntries {
+		importedModule := m.loadedModules[in.moduleRequest.key()]
+		idx := m.names[in.localName] &^ maskTyp
+		if in.namespace {
+			env.values[idx] = r.getModuleNamespace(importedModule)
+			continue
+		}
+		resolution, ambiguous := importedModule.ResolveExport(in.importName)
+		if resolution == nil {
+			return m.resolutionError(in.moduleRequest.Specifier, in.importName, ambiguous)
+		}
+		if resolution.BindingName == "" {
+			env.values[idx] = r.getModuleNamespace(resolution.Module)
+			continue
+		}
+		b := &importedBinding{
+			module: resolution.Module,
+			name:   resolution.BindingName,
+		}
+		if target, ok := resolution.Module.(*SourceTextModuleRecord); ok {
+			b.target = target
+			b.idx = target.names[unistring.NewFromString(resolution.BindingName)] &^ maskTyp
+		}
+		env.values[idx] = b
+	}
+
+	m.env = env
+	return r.runModuleCode(m, 0)
+}
+
+// Evaluate evaluates the module and all its dependencies (https://tc39.es/ecma262/#sec-moduleevaluation).
+// The module must be linked first.
+func (m *SourceTextModuleRecord) Evaluate() error {
+	err := m.evaluate()
+	m.r.leaveModuleOp(err)
+	return err
+}
+
+func (m *SourceTextModuleRecord) evaluate() error {
+	switch m.status {
+	case moduleUnlinked, moduleLinking:
+		return errors.New("module is not linked")
+	}
+	var stack []*SourceTextModuleRecord
+	_, err := m.innerModuleEvaluation(&stack, 0)
+	if err != nil {
+		for _, mod := range stack {
+			mod.status = moduleEvaluated
+			mod.evaluationError = err
+		}
+	}
+	return err
+}
+
+func (m *SourceTextModuleRecord) innerModuleEvaluation(stack *[]*SourceTextModuleRecord, index int) (int, error) {
+	switch m.status {
+	case moduleEvaluated:
+		return index, m.evaluationError
+	case moduleEvaluating:
+		return index, nil
+	}
+	m.status = moduleEvaluating
+	m.dfsIndex = index
+	m.dfsAncestorIndex = index
+	index++
+	*stack = append(*stack, m)
+	for _, required := range m.requestedModules {
+		requiredModule := m.loadedModules[required.key()]
+		if rm, ok := requiredModule.(*SourceTextModuleRecord); ok {
+			var err error
+			index, err = rm.innerModuleEvaluation(stack, index)
+			if err != nil {
+				return index, err
+			}
+			if rm.status == moduleEvaluating && rm.dfsAncestorIndex < m.dfsAncestorIndex {
+				m.dfsAncestorIndex = rm.dfsAncestorIndex
+			}
+		} else if err := requiredModule.Evaluate(); err != nil {
+			return index, err
+		}
+	}
+	if err := m.r.runModuleCode(m, m.bodyStart); err != nil {
+		return index, err
+	}
+	if m.dfsAncestorIndex == m.dfsIndex {
+		for {
+			l := len(*stack) - 1
+			mod := (*stack)[l]
+			*stack = (*stack)[:l]
+			mod.status = moduleEvaluated
+			if mod == m {
+				break
+			}
+		}
+	}
+	return index, nil
+}
+
+// runModuleCode runs the module code starting at the given pc in the module environment.
+func (r *Runtime) runModuleCode(m *SourceTextModuleRecord, pc int) (err error) {
+	vm := r.vm
+	defer func() {
+		vm.sp -= 2
+		vm.popCtx()
+		if x := recover(); x != nil {
+			if ex := asUncatchableException(x); ex != nil {
+				err = ex
+			} else {
+				panic(x)
+			}
+		}
+	}()
+	vm.pushCtx()
+	sp := vm.sp
+	vm.stack.expand(sp + 1)
+	vm.stack[sp] = _undefined // 'callee'
+	vm.stack[sp+1] = nil      // 'this'
+	vm.sb = sp + 1
+	vm.sp = sp + 2
+	vm.stash = m.env
+	vm.prg = m.p
+	vm.pc = pc
+	if ex := vm.runTry(); ex != nil {
+		err = ex
+	}
+	vm.clearStack()
+	return
+}
+
+// leaveModuleOp must be called after a module operation initiated from Go code.
+func (r *Runtime) leaveModuleOp(err error) {
+	if len(r.vm.callStack) == 0 {
+		if isUncatchableException(err) {
+			r.leaveAbrupt()
+		} else {
+			r.leave()
+		}
+	}
+}
+
+// importAttributesFromOptions returns the import attributes from the second argument of import().
+func (r *Runtime) importAttributesFromOptions(options Value) []ImportAttribute {
+	if options == _undefined {
+		return nil
+	}
+	optionsObj, ok := options.(*Object)
+	if !ok {
+		panic(r.NewTypeError("The second argument of import() must be an object"))
+	}
+	with := optionsObj.self.getStr("with", nil)
+	if with == nil || with == _undefined {
+		return nil
+	}
+	withObj, ok := with.(*Object)
+	if !ok {
+		panic(r.NewTypeError("The 'with' option of import() must be an object"))
+	}
+	var attributes []ImportAttribute
+	for item, next := iterateEnumerableStringProperties(withObj)(); next != nil; item, next = next() {
+		value, ok := item.value.(valueString)
+		if !ok {
+			panic(r.NewTypeError("Import attribute value must be a string"))
+		}
+		attributes = append(attributes, ImportAttribute{
+			Key:   item.name.String(),
+			Value: value.String(),
+		})
+	}
+	for _, a := range attributes {
+		if !isSupportedImportAttribute(a.Key) {
+			panic(r.NewTypeError("Import attribute '%s' is not supported", a.Key))
+		}
+	}
+	sort.Slice(attributes, func(i, j int) bool {
+		return attributes[i].Key < attributes[j].Key
+	})
+	return attributes
+}
+
+// importModuleDynamically implements import(): https://tc39.es/ecma262/#sec-import-call-runtime-semantics-evaluation
+func (r *Runtime) importModuleDynamically(referrer ModuleRecord, specifier, options Value) *Object {
+	p, resolve, reject := r.NewPromise()
+	var req ModuleRequest
+	if ex := r.vm.try(func() {
+		req.Specifier = specifier.String()
+		req.Attributes = r.importAttributesFromOptions(options)
+	}); ex != nil {
+		reject(ex.val)
+	} else {
+		r.enqueuePromiseJob(func() {
+			ns, err := r.importModule(referrer, req)
+			if err != nil {
+				if ex, ok := err.(*Exception); ok {
+					reject(ex.val)
+				} else {
+					reject(r.NewGoError(err))
+				}
+				return
+			}
+			resolve(ns)
+		})
+	}
+	return p.val
+}
+
+func (r *Runtime) importModule(referrer ModuleRecord, req ModuleRequest) (*Object, error) {
+	var m ModuleRecord
+	var err error
+	if sm, ok := referrer.(*SourceTextModuleRecord); ok {
+		m, err = sm.resolveImportedModule(req)
+	} else {
+		m, err = r.resolveModule(referrer, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if sm, ok := m.(*SourceTextModuleRecord); ok {
+		err = sm.link()
+		if err == nil {
+			err = sm.evaluate()
+		}
+	} else {
+		err = m.Link()
+		if err == nil {
+			err = m.Evaluate()
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.getModuleNamespace(m), nil
+}
+
+func (o *namespaceObject) init() {
+	o.baseObject.init()
+	o.extensible = false
+}
+
+func (o *namespaceObject) hasExport(name string) bool {
+	i := sort.SearchStrings(o.exports, name)
+	return i < len(o.exports) && o.exports[i] == name
+}
+
+func (o *namespaceObject) _getStr(name unistring.String) Value {
+	n := name.String()
+	if !o.hasExport(n) {
+		return nil
+	}
+	var v Value
+	if resolution, _ := o.module.ResolveExport(n); resolution != nil {
+		if resolution.BindingName == "" {
+			v = o.val.runtime.getModuleNamespace(resolution.Module)
+		} else {
+			v = resolution.Module.GetBindingValue(resolution.BindingName)
+		}
+	}
+	if v == nil {
+		// this may be called from Go (e.g. Object.Get()), so the error must be thrown as an *Exception
+		panic(o.val.runtime.vm.exceptionFromValue(errAccessBeforeInit))
+	}
+	return v
+}
+
+func (o *namespaceObject) getStr(name unistring.String, _ Value) Value {
+	return o._getStr(name)
+}
+
+func (o *namespaceObject) getOwnPropStr(name unistring.String) Value {
+	if v := o._getStr(name); v != nil {
+		return &valueProperty{
+			value:      v,
+			writable:   true,
+			enumerable: true,
+		}
+	}
+	return nil
+}
+
+func (o *namespaceObject) setOwnStr(name unistring.String, _ Value, throw bool) bool {
+	o.val.runtime.typeErrorResult(throw, "Cannot assign to read only property '%s' of module namespace object", name)
+	return false
+}
+
+func (o *namespaceObject) setForeignStr(name unistring.String, _, _ Value, throw bool) (bool, bool) {
+	if o.hasExport(name.String()) {
+		o.val.runtime.typeErrorResult(throw, "Cannot assign to read only property '%s' of module namespace object", name)
+		return false, true
+	}
+	return false, false
+}
+
+func (o *namespaceObject) setForeignIdx(idx valueInt, val, receiver Value, throw bool) (bool, bool) {
+	return o.setForeignStr(idx.string(), val, receiver, throw)
+}
+
+func (o *namespaceObject) hasPropertyStr(name unistring.String) bool {
+	return o.hasExport(name.String())
+}
+
+func (o *namespaceObject) hasOwnPropertyStr(name unistring.String) bool {
+	return o.hasExport(name.String())
+}
+
+func (o *namespaceObject) defineOwnPropertyStr(name unistring.String, desc PropertyDescriptor, throw bool) bool {
+	current := o._getStr(name)
+	if current == nil ||
+		desc.Configurable == FLAG_TRUE || desc.Enumerable == FLAG_FALSE || desc.IsAccessor() ||
+		desc.Writable == FLAG_FALSE || desc.Value != nil && !desc.Value.SameAs(current) {
+		o.val.runtime.typeErrorResult(throw, "Cannot redefine property: %s", name)
+		return false
+	}
+	return true
+}
+
+func (o *namespaceObject) deleteStr(name unistring.String, throw bool) bool {
+	if o.hasExport(name.String()) {
+		o.val.runtime.typeErrorResult(throw, "Cannot delete property '%s' of module namespace object", name)
+		return false
+	}
+	return true
+}
+
+func (o *namespaceObject) setProto(proto *Object, throw bool) bool {
+	if proto == nil {
+		return true
+	}
+	o.val.runtime.typeErrorResult(throw, "Cannot set prototype of module namespace object")
+	return false
+}
+
+func (o *namespaceObject) preventExtensions(bool) bool {
+	return true
+}
+
+type namespacePropIter struct {
+	o   *namespaceObject
+	idx int
+}
+
+func (i *namespacePropIter) next() (propIterItem, iterNextFunc) {
+	if i.idx < len(i.o.exports) {
+		name := i.o.exports[i.idx]
+		i.idx++
+		return propIterItem{name: newStringValue(name), enumerable: _ENUM_TRUE}, i.next
+	}
+	return propIterItem{}, nil
+}
+
+func (o *namespaceObject) iterateStringKeys() iterNextFunc {
+	return (&namespacePropIter{
+		o: o,
+	}).next
+}
+
+func (o *namespaceObject) stringKeys(_ bool, accum []Value) []Value {
+	for _, name := range o.exports {
+		accum = append(accum, newStringValue(name))
+	}
+	return accum
+}
+
+func (o *namespaceObject) equal(other objectImpl) bool {
+	if other, ok := other.(*namespaceObject); ok {
+		return o == other
+	}
+	return false
+}
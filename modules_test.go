@@ -0,0 +1,565 @@
+package goja
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type testModuleLoader struct {
+	r       *Runtime
+	sources map[string]string
+	modules map[string]ModuleRecord
+	loads   int
+}
+
+func newTestModuleLoader(r *Runtime, sources map[string]string) *testModuleLoader {
+	l := &testModuleLoader{
+		r:       r,
+		sources: sources,
+		modules: make(map[string]ModuleRecord),
+	}
+	r.SetResolveModule(l.resolve)
+	return l
+}
+
+func (l *testModuleLoader) resolve(_ ModuleRecord, specifier string) (ModuleRecord, error) {
+	if m, exists := l.modules[specifier]; exists {
+		return m, nil
+	}
+	src, exists := l.sources[specifier]
+	if !exists {
+		return nil, errors.New("module not found: " + specifier)
+	}
+	m, err := l.r.CompileModule(specifier, src)
+	if err != nil {
+		return nil, err
+	}
+	l.loads++
+	l.modules[specifier] = m
+	return m, nil
+}
+
+func (l *testModuleLoader) run(t *testing.T, specifier string) ModuleRecord {
+	m, err := l.resolve(nil, specifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Link(); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Evaluate(); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestModuleBasic(t *testing.T) {
+	r := New()
+	l := newTestModuleLoader(r, map[string]string{
+		"a.js": `
+			import def, { b as bb, inc } from "b.js";
+			import * as ns from "b.js";
+			export let result = def + bb;
+			inc();
+			export const live = bb;
+			export const nsB = ns.b;
+			export const tag = Object.prototype.toString.call(ns);
+			export const thisVal = this;
+			export const viaEval = eval("bb + ns.b");
+		`,
+		"b.js": `
+			export let b = 1;
+			export function inc() {
+				b++;
+			}
+			export default 40 + 1;
+		`,
+	})
+	m := l.run(t, "a.js")
+	ns := r.ModuleNamespace(m)
+	if v := ns.Get("result"); v.ToInteger() != 42 {
+		t.Fatalf("result: %v", v)
+	}
+	if v := ns.Get("live"); v.ToInteger() != 2 {
+		t.Fatalf("live: %v", v)
+	}
+	if v := ns.Get("nsB"); v.ToInteger() != 2 {
+		t.Fatalf("nsB: %v", v)
+	}
+	if v := ns.Get("tag"); v.String() != "[object Module]" {
+		t.Fatalf("tag: %v", v)
+	}
+	if v := ns.Get("thisVal"); !IsUndefined(v) {
+		t.Fatalf("thisVal: %v", v)
+	}
+	if v := ns.Get("viaEval"); v.ToInteger() != 4 {
+		t.Fatalf("viaEval: %v", v)
+	}
+	if l.loads != 2 {
+		t.Fatalf("loads: %d", l.loads)
+	}
+}
+
+func TestModuleDefaultExports(t *testing.T) {
+	r := New()
+	l := newTestModuleLoader(r, map[string]string{
+		"main.js": `
+			import f from "f.js";
+			import c from "c.js";
+			import e from "e.js";
+			import g, { default as gg } from "g.js";
+			export const res = [f.name, f(), c.name, new c().v, e.name, g === gg, g()].join();
+		`,
+		"f.js": `export default function() { return "f" }`,
+		"c.js": `export default class { constructor() { this.v = "c" } }`,
+		"e.js": `export default (function() {});`,
+		"g.js": `export default function g() { return "g" }`,
+	})
+	m := l.run(t, "main.js")
+	if v := r.ModuleNamespace(m).Get("res"); v.String() != "default,f,default,c,default,true,g" {
+		t.Fatalf("res: %v", v)
+	}
+}
+
+func TestModuleCycle(t *testing.T) {
+	r := New()
+	var log []string
+	r.Set("log", func(s string) {
+		log = append(log, s)
+	})
+	l := newTestModuleLoader(r, map[string]string{
+		"a.js": `
+			import { b, getA } from "b.js";
+			log("a");
+			export function a() {
+				return "a";
+			}
+			export const fromB = b + getA();
+		`,
+		"b.js": `
+			import { a } from "a.js";
+			log("b");
+			export const b = "b";
+			export function getA() {
+				return a();
+			}
+		`,
+	})
+	m := l.run(t, "a.js")
+	if v := r.ModuleNamespace(m).Get("fromB"); v.String() != "ba" {
+		t.Fatalf("fromB: %v", v)
+	}
+	if s := strings.Join(log, ","); s != "b,a" {
+		t.Fatalf("log: %s", s)
+	}
+}
+
+func TestModuleCycleTDZ(t *testing.T) {
+	r := New()
+	l := newTestModuleLoader(r, map[string]string{
+		"a.js": `
+			import { b } from "b.js";
+			export const a = 1;
+		`,
+		"b.js": `
+			import { a } from "a.js";
+			export const b = a;
+		`,
+	})
+	m, err := l.resolve(nil, "a.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Link(); err != nil {
+		t.Fatal(err)
+	}
+	err = m.Evaluate()
+	if ex, ok := err.(*Exception); !ok || !strings.HasPrefix(ex.Value().String(), "ReferenceError") {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err1 := m.Evaluate(); err1 != err {
+		t.Fatalf("Unexpected error on the second call: %v", err1)
+	}
+}
+
+func TestModuleNamespaceGetBeforeInit(t *testing.T) {
+	r := New()
+	l := newTestModuleLoader(r, map[string]string{
+		"a.js": `export let a = 1;`,
+	})
+	m, err := l.resolve(nil, "a.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Link(); err != nil {
+		t.Fatal(err)
+	}
+	ns := r.ModuleNamespace(m)
+	func() {
+		defer func() {
+			x := recover()
+			if ex, ok := x.(*Exception); !ok || !strings.HasPrefix(ex.Value().String(), "ReferenceError") {
+				t.Fatalf("Unexpected panic: %v", x)
+			}
+		}()
+		ns.Get("a")
+		t.Fatal("no panic")
+	}()
+	if err := m.Evaluate(); err != nil {
+		t.Fatal(err)
+	}
+	if v := ns.Get("a"); v.ToInteger() != 1 {
+		t.Fatal(v)
+	}
+}
+
+func TestModuleReExports(t *testing.T) {
+	r := New()
+	l := newTestModuleLoader(r, map[string]string{
+		"main.js": `
+			import { x, y, z, ns, "string name" as s } from "re.js";
+			import * as all from "re.js";
+			export const res = [x, y, z, ns.x, s, Object.keys(all).join("|")].join();
+		`,
+		"re.js": `
+			import { x as ix } from "x.js";
+			export * from "x.js";
+			export { x as y } from "x.js";
+			export { ix as z };
+			export * as ns from "x.js";
+			const s = "s";
+			export { s as "string name" };
+		`,
+		"x.js": `
+			export const x = "x";
+			export default "d";
+		`,
+	})
+	m := l.run(t, "main.js")
+	if v := r.ModuleNamespace(m).Get("res"); v.String() != "x,x,x,x,s,ns|string name|x|y|z" {
+		t.Fatalf("res: %v", v)
+	}
+}
+
+func TestModuleNamespaceObject(t *testing.T) {
+	r := New()
+	l := newTestModuleLoader(r, map[string]string{
+		"main.js": `
+			import * as ns from "m.js";
+			const desc = Object.getOwnPropertyDescriptor(ns, "a");
+			assert.sameValue(desc.value, 1);
+			assert(desc.writable && desc.enumerable && !desc.configurable, "descriptor");
+			assert.sameValue(Object.getPrototypeOf(ns), null);
+			assert(!Object.isExtensible(ns), "extensible");
+			assert.throws(TypeError, () => { ns.a = 2 });
+			assert.throws(TypeError, () => { ns.c = 2 });
+			assert.throws(TypeError, () => { delete ns.a });
+			assert(delete ns.c, "delete non-existing");
+			assert("a" in ns, "in");
+			assert.sameValue(Reflect.ownKeys(ns).length, 3);
+		`,
+		"m.js": `
+			export const a = 1;
+			export function b() {}
+		`,
+	})
+	_, err := r.RunString(TESTLIB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.run(t, "main.js")
+}
+
+func TestModuleImportIsConst(t *testing.T) {
+	r := New()
+	l := newTestModuleLoader(r, map[string]string{
+		"main.js": `
+			import { a } from "m.js";
+			a = 2;
+		`,
+		"m.js": `export let a = 1;`,
+	})
+	m, err := l.resolve(nil, "main.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Link(); err != nil {
+		t.Fatal(err)
+	}
+	err = m.Evaluate()
+	if ex, ok := err.(*Exception); !ok || !strings.HasPrefix(ex.Value().String(), "TypeError") {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestModuleLinkErrors(t *testing.T) {
+	r := New()
+	l := newTestModuleLoader(r, map[string]string{
+		"missing.js":   `import { nope } from "m.js";`,
+		"ambiguous.js": `import { x } from "star.js";`,
+		"notfound.js":  `import "nope.js";`,
+		"star.js": `
+			export * from "x1.js";
+			export * from "x2.js";
+		`,
+		"x1.js": `export const x = 1;`,
+		"x2.js": `export const x = 2;`,
+		"m.js":  `export const a = 1;`,
+	})
+	for _, tc := range []struct {
+		name, expected string
+	}{
+		{"missing.js", "SyntaxError: The requested module 'm.js' does not provide an export named 'nope'"},
+		{"ambiguous.js", "SyntaxError: The requested module 'star.js' contains conflicting star exports for name 'x'"},
+		{"notfound.js", "module not found: nope.js"},
+	} {
+		m, err := l.resolve(nil, tc.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = m.Link()
+		if err == nil {
+			t.Fatalf("%s: expected an error", tc.name)
+		}
+		msg := err.Error()
+		if ex, ok := err.(*Exception); ok {
+			msg = ex.Value().String()
+		}
+		if msg != tc.expected {
+			t.Fatalf("%s: unexpected error: %s", tc.name, msg)
+		}
+		if err := m.Evaluate(); err == nil {
+			t.Fatalf("%s: expected an error evaluating unlinked module", tc.name)
+		}
+	}
+}
+
+func TestModuleCompileErrors(t *testing.T) {
+	r := New()
+	for _, src := range []string{
+		`export { x };`,
+		`export const a = 1; export { a };`,
+		`let a; var a;`,
+		`import { a } from "m"; let a;`,
+		`new.target`,
+		`with ({}) {}`,
+		`import x from "m" with { type: "json", type: "json" };`,
+		`import x from "m" with { integrity: "abc" };`,
+	} {
+		_, err := r.CompileModule("test.js", src)
+		if ex, ok := err.(*Exception); !ok || !strings.HasPrefix(ex.Value().String(), "SyntaxError") {
+			t.Fatalf("%q: unexpected result: %v", src, err)
+		}
+	}
+}
+
+func TestModuleDynamicImport(t *testing.T) {
+	r := New()
+	newTestModuleLoader(r, map[string]string{
+		"m.js": `
+			export const a = 1;
+			export function twice(x) {
+				return import("n.js").then(ns => ns.double(x));
+			}
+		`,
+		"n.js": `export const double = x => x * 2;`,
+	})
+	var result Value
+	r.Set("done", func(v Value) {
+		result = v
+	})
+	_, err := r.RunString(`
+	import("m.js").then(ns => ns.twice(ns.a + 20)).then(done);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result == nil || result.ToInteger() != 42 {
+		if result != nil {
+			t.Fatalf("Unexpected result: %v", result)
+		}
+		t.Fatal("The promise has not been resolved")
+	}
+}
+
+func TestModuleDynamicImportReject(t *testing.T) {
+	r := New()
+	newTestModuleLoader(r, map[string]string{
+		"throws.js": `throw new Error("boom");`,
+	})
+	var results []string
+	r.Set("done", func(s string) {
+		results = append(results, s)
+	})
+	_, err := r.RunString(`
+	import("throws.js").catch(e => done(e.message));
+	import("nope.js").catch(e => done(e.message));
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := strings.Join(results, ","); s != "boom,module not found: nope.js" {
+		t.Fatalf("Unexpected result: %s", s)
+	}
+}
+
+func TestModuleImportMeta(t *testing.T) {
+	r := New()
+	newTestModuleLoader(r, map[string]string{
+		"m.js": `
+			export const meta = import.meta;
+			export function getMeta() {
+				return import.meta;
+			}
+		`,
+	})
+	calls := 0
+	checked := false
+	r.SetGetImportMetaProperties(func(m ModuleRecord) []MetaProperty {
+		calls++
+		return []MetaProperty{
+			{Key: "url", Value: r.ToValue("file:///" + m.(*SourceTextModuleRecord).Name())},
+		}
+	})
+	r.Set("check", func(ns *Object) {
+		meta := ns.Get("meta").ToObject(r)
+		if v := meta.Get("url"); v.String() != "file:///m.js" {
+			t.Fatalf("url: %v", v)
+		}
+		if meta.Prototype() != nil {
+			t.Fatal("import.meta must have a null prototype")
+		}
+		getMeta, _ := AssertFunction(ns.Get("getMeta"))
+		v, err := getMeta(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != meta {
+			t.Fatal("import.meta must be the same object")
+		}
+		checked = true
+	})
+	_, err := r.RunString(`import("m.js").then(check, e => { throw e })`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !checked || calls != 1 {
+		t.Fatalf("checked: %v, calls: %d", checked, calls)
+	}
+
+	_, err = r.RunString("function f() { return import.meta }")
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+}
+
+func TestModuleImportAttributes(t *testing.T) {
+	r := New()
+	sources := map[string]string{
+		"m.js": `
+			import data from "data.json" with { type: "json" };
+			import * as ns from "data.json" with { type: "json" };
+			export { default as reexported } from "data.json" with { type: "json" };
+			export const value = data.a;
+			export const same = data === ns.default;
+			export function load(options) {
+				return import("data.json", options);
+			}
+		`,
+		"data.json": `{"a": [1, 2]}`,
+	}
+	var requests []ModuleRequest
+	modules := make(map[string]ModuleRecord)
+	r.SetResolveModuleRequest(func(_ ModuleRecord, req ModuleRequest) (ModuleRecord, error) {
+		requests = append(requests, req)
+		if m := modules[req.Specifier]; m != nil {
+			return m, nil
+		}
+		var m ModuleRecord
+		var err error
+		if typ, _ := req.Attribute("type"); typ == "json" {
+			m, err = r.CompileJSONModule(req.Specifier, sources[req.Specifier])
+		} else {
+			m, err = r.CompileModule(req.Specifier, sources[req.Specifier])
+		}
+		if err != nil {
+			return nil, err
+		}
+		modules[req.Specifier] = m
+		return m, nil
+	})
+	m, err := r.CompileModule("main.js", `
+		import { value, same, reexported, load } from "m.js";
+		if (!compareArray(value, [1, 2]) || !same || reexported.a !== value) {
+			throw new Error("Unexpected values");
+		}
+		load({ with: { type: "json" } }).then(ns => {
+			if (ns.default !== reexported) {
+				throw new Error("Unexpected dynamic import result");
+			}
+			return load();
+		}).catch(e => globalThis.loadErr = e);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.RunProgram(testLib()); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Link(); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Evaluate(); err != nil {
+		t.Fatal(err)
+	}
+	// the static imports and the first import() share the same request, the second import() has no attributes
+	if len(requests) != 3 || len(requests[2].Attributes) != 0 {
+		t.Fatalf("Unexpected requests: %#v", requests)
+	}
+	if req := requests[1]; req.Specifier != "data.json" || len(req.Attributes) != 1 || req.Attributes[0] != (ImportAttribute{Key: "type", Value: "json"}) {
+		t.Fatalf("Unexpected request: %#v", req)
+	}
+	loadErr := r.Get("loadErr")
+	if loadErr == nil || !strings.HasPrefix(loadErr.String(), "TypeError: Module 'data.json' is a JSON module") {
+		t.Fatalf("Unexpected error: %v", loadErr)
+	}
+}
+
+func TestModuleImportAttributesErrors(t *testing.T) {
+	r := New()
+	newTestModuleLoader(r, map[string]string{
+		"m.js": `export const a = 1;`,
+	})
+	var results []string
+	r.Set("done", func(s string) {
+		results = append(results, s)
+	})
+	_, err := r.RunString(`
+	import("m.js", { with: { type: "json" } }).catch(e => done(e.message));
+	import("m.js", { with: { type: "css" } }).catch(e => done(e.message));
+	import("m.js", { with: { foo: "bar" } }).catch(e => done(e.message));
+	import("m.js", { with: { type: 1 } }).catch(e => done(e.message));
+	import("m.js", 1).catch(e => done(e.message));
+	import("m.js", {}).then(ns => done("ok " + ns.a));
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"Import attribute 'foo' is not supported",
+		"Import attribute value must be a string",
+		"The second argument of import() must be an object",
+		"Module 'm.js' is not a JSON module",
+		"Cannot resolve module 'm.js': unsupported module type 'css'",
+		"ok 1",
+	}
+	if s := strings.Join(results, ","); s != strings.Join(expected, ",") {
+		t.Fatalf("Unexpected result: %s", s)
+	}
+
+	_, err = r.CompileJSONModule("bad.json", "{")
+	if ex, ok := err.(*Exception); !ok || !strings.HasPrefix(ex.Value().String(), "SyntaxError") {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
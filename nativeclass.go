@@ -0,0 +1,227 @@
+package goja
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/dop251/goja/unistring"
+)
+
+// NativeClass describes a JavaScript class backed by a Go type, see CreateNativeClass. T below is the type of the
+// Go values backing the instances, i.e. the type returned by the Constructor.
+type NativeClass struct {
+	// Constructor creates the Go value of a new instance, it's a function returning T, or T and an error which is
+	// thrown as a GoError. The arguments of 'new' are converted into its parameters like for any other wrapped Go
+	// function (see ToValue). T is usually a pointer type, so the methods can modify the value.
+	Constructor interface{}
+
+	// Methods are the methods of the prototype. Each one is a function taking T (or an interface type implemented
+	// by T) as the first parameter followed by the parameters the arguments are converted into, e.g. a method
+	// expression such as (*Counter).Add.
+	Methods map[string]interface{}
+
+	// Accessors are the accessor properties of the prototype.
+	Accessors map[string]NativeAccessor
+
+	// Static are the properties of the constructor, the values are converted by ToValue().
+	Static map[string]interface{}
+}
+
+// NativeAccessor describes an accessor property of a NativeClass.
+type NativeAccessor struct {
+	// Get is the getter, a function taking T and returning the value of the property (and optionally an error).
+	Get interface{}
+
+	// Set is the setter, a function taking T and the new value of the property (and optionally returning an error).
+	// If it's nil, the property is read-only.
+	Set interface{}
+}
+
+type nativeClass struct {
+	r     *Runtime
+	name  string
+	typ   reflect.Type
+	ctor  *Object
+	proto *Object
+}
+
+// nativeClassObject is an instance of a NativeClass.
+type nativeClassObject struct {
+	baseObject
+	class *nativeClass
+	value reflect.Value
+}
+
+// CreateNativeClass creates a JavaScript class backed by a Go type and returns its constructor, e.g.:
+//
+//	type Counter struct {
+//		n int
+//	}
+//
+//	func (c *Counter) Add(n int) int {
+//		c.n += n
+//		return c.n
+//	}
+//
+//	counter, err := r.CreateNativeClass("Counter", goja.NativeClass{
+//		Constructor: func(start int) *Counter {
+//			return &Counter{n: start}
+//		},
+//		Methods: map[string]interface{}{
+//			"add": (*Counter).Add,
+//		},
+//		Accessors: map[string]goja.NativeAccessor{
+//			"value": {Get: func(c *Counter) int { return c.n }},
+//		},
+//	})
+//	r.Set("Counter", counter)
+//	r.RunString(`const c = new Counter(1); c.add(2); c.value`) // 3
+//
+// Calling 'new' allocates an ordinary object with the prototype of the class and calls the Constructor to create
+// the Go value associated with it. The methods and the accessors of the prototype call the corresponding Go
+// functions with this value, a TypeError is thrown if they are called on an object which is not an instance of the
+// class. Like the built-in classes, the constructor throws a TypeError if it's called without 'new', and it can be
+// extended with 'class ... extends'. Export() of an instance returns the Go value, so the instances can be passed
+// to Go functions taking T.
+//
+// The methods and the static properties are writable, non-enumerable and configurable, the accessors are
+// non-enumerable and configurable. They are defined in the order of their names. An error is returned if the
+// functions do not have the described signatures.
+func (r *Runtime) CreateNativeClass(name string, class NativeClass) (*Object, error) {
+	ctor := reflect.ValueOf(class.Constructor)
+	if ctor.Kind() != reflect.Func || ctor.IsNil() {
+		return nil, fmt.Errorf("the constructor of %s is not a function", name)
+	}
+	ctorType := ctor.Type()
+	switch {
+	case ctorType.NumOut() == 1 && ctorType.Out(0) != reflectTypeError,
+		ctorType.NumOut() == 2 && ctorType.Out(1) == reflectTypeError:
+	default:
+		return nil, fmt.Errorf("the constructor of %s must return a value and optionally an error", name)
+	}
+
+	c := &nativeClass{
+		r:    r,
+		name: name,
+		typ:  ctorType.Out(0),
+	}
+	for _, n := range sortedNames(class.Methods) {
+		if err := c.checkFunc(n, class.Methods[n], 0); err != nil {
+			return nil, err
+		}
+	}
+	for _, n := range sortedNames(class.Accessors) {
+		a := class.Accessors[n]
+		if err := c.checkFunc("get "+n, a.Get, 1); err != nil {
+			return nil, err
+		}
+		if a.Set != nil {
+			if err := c.checkFunc("set "+n, a.Set, 2); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	c.proto = r.NewObject()
+	c.ctor = &Object{runtime: r}
+	r.newNativeFuncAndConstruct(c.ctor, func(FunctionCall) Value {
+		panic(r.needNew(name))
+	}, func(args []Value, newTarget *Object) *Object {
+		if newTarget == nil {
+			newTarget = c.ctor
+		}
+		o := &Object{runtime: r}
+		inst := &nativeClassObject{
+			class: c,
+			value: r.callReflectFunc(ctor, args)[0],
+		}
+		inst.val = o
+		inst.extensible = true
+		inst.prototype = r.getPrototypeFromCtor(newTarget, c.ctor, c.proto)
+		o.self = inst
+		inst.init()
+		return o
+	}, c.proto, unistring.NewFromString(name), intToValue(int64(ctorType.NumIn())))
+	c.proto.self._putProp("constructor", c.ctor, true, false, true)
+
+	for _, n := range sortedNames(class.Methods) {
+		fn := reflect.ValueOf(class.Methods[n])
+		c.proto.self._putProp(unistring.NewFromString(n), c.newMethod(n, fn), true, false, true)
+	}
+	for _, n := range sortedNames(class.Accessors) {
+		a := class.Accessors[n]
+		prop := &valueProperty{
+			accessor:     true,
+			configurable: true,
+		}
+		prop.getterFunc = c.newMethod("get "+n, reflect.ValueOf(a.Get))
+		if a.Set != nil {
+			prop.setterFunc = c.newMethod("set "+n, reflect.ValueOf(a.Set))
+		}
+		c.proto.self.setOwnStr(unistring.NewFromString(n), prop, true)
+	}
+	for _, n := range sortedNames(class.Static) {
+		c.ctor.self._putProp(unistring.NewFromString(n), r.ToValue(class.Static[n]), true, false, true)
+	}
+
+	return c.ctor, nil
+}
+
+// checkFunc checks that fn is a function taking the instance as the first parameter, nargs is the exact number of
+// parameters if it's not zero.
+func (c *nativeClass) checkFunc(name string, fn interface{}, nargs int) error {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return fmt.Errorf("%s.%s is not a function", c.name, name)
+	}
+	typ := v.Type()
+	if typ.NumIn() == 0 || !c.typ.AssignableTo(typ.In(0)) {
+		return fmt.Errorf("the first parameter of %s.%s must be %v", c.name, name, c.typ)
+	}
+	if nargs != 0 && (typ.NumIn() != nargs || typ.IsVariadic()) {
+		return fmt.Errorf("%s.%s must have %d parameters", c.name, name, nargs)
+	}
+	return nil
+}
+
+// newMethod returns a function that calls fn with the Go value of 'this' followed by the arguments.
+func (c *nativeClass) newMethod(name string, fn reflect.Value) *Object {
+	r := c.r
+	call := r.wrapReflectFunc(fn, nil)
+	return r.newNativeFunc(func(fc FunctionCall) Value {
+		this, ok := fc.This.(*Object)
+		if !ok || !c.isInstance(this) {
+			panic(r.NewTypeError("Method %s.%s called on incompatible receiver %s", c.name, name, r.objectproto_toString(FunctionCall{This: fc.This})))
+		}
+		args := make([]Value, 0, len(fc.Arguments)+1)
+		args = append(args, this)
+		args = append(args, fc.Arguments...)
+		return call(FunctionCall{This: this, Arguments: args})
+	}, nil, unistring.NewFromString(name), nil, fn.Type().NumIn()-1)
+}
+
+func (c *nativeClass) isInstance(o *Object) bool {
+	inst, ok := o.self.(*nativeClassObject)
+	return ok && inst.class == c
+}
+
+func (o *nativeClassObject) export(*objectExportCtx) interface{} {
+	return o.value.Interface()
+}
+
+func (o *nativeClassObject) exportType() reflect.Type {
+	return o.value.Type()
+}
+
+// sortedNames returns the sorted keys of the map m with string keys.
+func sortedNames(m interface{}) []string {
+	v := reflect.ValueOf(m)
+	names := make([]string, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		names = append(names, iter.Key().String())
+	}
+	sort.Strings(names)
+	return names
+}
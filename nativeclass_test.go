@@ -0,0 +1,115 @@
+package goja
+
+import (
+	"errors"
+	"testing"
+)
+
+type testCounter struct {
+	n int
+}
+
+func (c *testCounter) Add(n int) int {
+	c.n += n
+	return c.n
+}
+
+func TestCreateNativeClass(t *testing.T) {
+	r := New()
+	counter, err := r.CreateNativeClass("Counter", NativeClass{
+		Constructor: func(start int) (*testCounter, error) {
+			if start < 0 {
+				return nil, errors.New("negative start")
+			}
+			return &testCounter{n: start}, nil
+		},
+		Methods: map[string]interface{}{
+			"add": (*testCounter).Add,
+			"reset": func(c *testCounter) {
+				c.n = 0
+			},
+		},
+		Accessors: map[string]NativeAccessor{
+			"value": {
+				Get: func(c *testCounter) int { return c.n },
+				Set: func(c *testCounter, n int) { c.n = n },
+			},
+			"even": {
+				Get: func(c *testCounter) bool { return c.n%2 == 0 },
+			},
+		},
+		Static: map[string]interface{}{
+			"MAX": 100,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Set("Counter", counter)
+	r.Set("total", func(counters ...*testCounter) (res int) {
+		for _, c := range counters {
+			res += c.n
+		}
+		return
+	})
+	_, err = r.RunProgram(testLib())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = r.RunString(`
+	"use strict";
+	const c = new Counter(1);
+	assert.sameValue(c.add(2), 3, "add");
+	assert.sameValue(c.value, 3, "value");
+	c.value = 10;
+	assert.sameValue(c.add(1), 11, "set value");
+	assert.sameValue(c.even, false, "even");
+	assert.throws(TypeError, () => { c.even = true; }, "read-only accessor");
+	c.reset();
+	assert.sameValue(c.value, 0, "reset");
+
+	assert(c instanceof Counter, "instanceof");
+	assert.sameValue(Object.getPrototypeOf(c), Counter.prototype, "prototype");
+	assert.sameValue(Counter.prototype.constructor, Counter, "constructor");
+	assert.sameValue(Counter.name, "Counter", "name");
+	assert.sameValue(Counter.MAX, 100, "static");
+	assert.sameValue(Counter.prototype.add.length, 1, "length");
+	assert.sameValue(Object.keys(c).length, 0, "no own properties");
+	assert.sameValue(Object.keys(Counter.prototype).length, 0, "non-enumerable");
+	assert.throws(TypeError, () => Counter(1), "call without new");
+	assert.throws(TypeError, () => Counter.prototype.add.call({}, 1), "incompatible receiver");
+	assert.throws(TypeError, () => Object.getOwnPropertyDescriptor(Counter.prototype, "value").get.call(new Date()), "incompatible getter receiver");
+
+	class Doubler extends Counter {
+		add(n) {
+			return super.add(n * 2);
+		}
+	}
+	const d = new Doubler(1);
+	assert.sameValue(d.add(2), 5, "subclass");
+	assert(d instanceof Counter, "subclass instanceof");
+	assert.sameValue(total(c, d, new Counter(4)), 9, "export");
+
+	try {
+		new Counter(-1);
+		throw new Error("expected an error");
+	} catch (e) {
+		assert.sameValue(e.message, "negative start", "constructor error");
+	}
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, class := range []NativeClass{
+		{},
+		{Constructor: func() {}},
+		{Constructor: func() *testCounter { return nil }, Methods: map[string]interface{}{"add": func(n int) {}}},
+		{Constructor: func() *testCounter { return nil }, Accessors: map[string]NativeAccessor{"value": {Get: func(c *testCounter, n int) int { return n }}}},
+	} {
+		if _, err := r.CreateNativeClass("Invalid", class); err == nil {
+			t.Fatalf("expected an error for %#v", class)
+		}
+	}
+}
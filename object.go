@@ -10,25 +10,28 @@ import (
 )
 
 const (
-	classObject        = "Object"
-	classArray         = "Array"
-	classWeakSet       = "WeakSet"
-	classWeakMap       = "WeakMap"
-	classMap           = "Map"
-	classMath          = "Math"
-	classSet           = "Set"
-	classFunction      = "Function"
-	classAsyncFunction = "AsyncFunction"
-	classNumber        = "Number"
-	classString        = "String"
-	classBoolean       = "Boolean"
-	classError         = "Error"
-	classAggError      = "AggregateError"
-	classRegExp        = "RegExp"
-	classDate          = "Date"
-	classJSON          = "JSON"
-	classGlobal        = "global"
-	classPromise       = "Promise"
+	classObject          = "Object"
+	classArray           = "Array"
+	classWeakSet         = "WeakSet"
+	classWeakMap         = "WeakMap"
+	classWeakRef         = "WeakRef"
+	classMap             = "Map"
+	classMath            = "Math"
+	classSet             = "Set"
+	classFunction        = "Function"
+	classAsyncFunction   = "AsyncFunction"
+	classNumber          = "Number"
+	classBigInt          = "BigInt"
+	classString          = "String"
+	classBoolean         = "Boolean"
+	classError           = "Error"
+	classAggError        = "AggregateError"
+	classSuppressedError = "SuppressedError"
+	classRegExp          = "RegExp"
+	classDate            = "Date"
+	classJSON            = "JSON"
+	classGlobal          = "global"
+	classPromise         = "Promise"
 
 	classArrayIterator        = "Array Iterator"
 	classMapIterator          = "Map Iterator"
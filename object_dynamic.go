@@ -30,6 +30,24 @@ type DynamicObject interface {
 	Keys() []string
 }
 
+/*
+DynamicObjectWithSymbols is a DynamicObject which also handles the Symbol properties of the Object. If the handler
+passed to Runtime.NewDynamicObject() implements this interface, the own Symbol properties of the Object are handled
+by its methods, so it can implement Symbol.iterator, Symbol.toPrimitive, Symbol.toStringTag, etc. The same rules
+as for the string properties apply: all Symbol properties are Writable, Enumerable and Configurable data properties.
+*/
+type DynamicObjectWithSymbols interface {
+	DynamicObject
+	// GetSymbol returns a property value for the symbol. May return nil if the property does not exist.
+	GetSymbol(sym *Symbol) Value
+	// SetSymbol sets a property value for the symbol. Return true if success, false otherwise.
+	SetSymbol(sym *Symbol, val Value) bool
+	// DeleteSymbol deletes the property for the symbol. Returns true on success (note, that includes missing property).
+	DeleteSymbol(sym *Symbol) bool
+	// SymbolKeys returns a list of all existing Symbol property keys.
+	SymbolKeys() []*Symbol
+}
+
 /*
 DynamicArray is an interface representing a handler for a dynamic array Object. Such an object can be created
 using the Runtime.NewDynamicArray() method.
@@ -40,7 +58,8 @@ the regular ECMAScript arrays which only support positive indexes up to 2^32-1.
 
 DynamicArray cannot be sparse, i.e. hasOwnProperty(num) will return true for num >= 0 && num < Len(). Deleting
 such a property is equivalent to setting it to undefined. Note that this creates a slight peculiarity because
-hasOwnProperty() will still return true, even after deletion.
+hasOwnProperty() will still return true, even after deletion. See DynamicArrayWithDescriptors for a way to
+support holes and non-default property attributes.
 
 Note that Runtime.ToValue() does not have any special treatment for DynamicArray. The only way to create
 a dynamic array is by using the Runtime.NewDynamicArray() method. This is done deliberately to avoid
@@ -60,6 +79,33 @@ type DynamicArray interface {
 	SetLen(int) bool
 }
 
+/*
+DynamicArrayWithDescriptors is a DynamicArray which controls the attributes of its elements and may be sparse. If the
+handler passed to Runtime.NewDynamicArray() implements this interface:
+
+- the elements for which Has returns false are holes, i.e. hasOwnProperty() returns false for them, reading them
+falls through to the prototype and they are skipped by the enumeration;
+
+- the attributes of the existing elements are reported by Attributes. Assigning to a non-writable element fails
+(throws in strict mode), non-enumerable elements are skipped by for...in loops and Object.keys();
+
+- Object.defineProperty() and the delete operator on the elements are passed to DefineProperty and Delete instead
+of being converted to Set calls.
+*/
+type DynamicArrayWithDescriptors interface {
+	DynamicArray
+	// Has returns true if the element at index idx exists. It's only called for idx >= 0 && idx < Len().
+	Has(idx int) bool
+	// Attributes returns the attributes of the existing element at index idx.
+	Attributes(idx int) (writable, enumerable, configurable bool)
+	// DefineProperty defines or redefines the element at index idx. Note that idx may be any integer, like in Set.
+	// Accessor descriptors are rejected before it's called. Return true if success, false otherwise.
+	DefineProperty(idx int, desc PropertyDescriptor) bool
+	// Delete removes the element at index idx, making it a hole. Returns true on success (note, that includes
+	// missing element).
+	Delete(idx int) bool
+}
+
 type baseDynamicObject struct {
 	val       *Object
 	prototype *Object
@@ -68,11 +114,15 @@ type baseDynamicObject struct {
 type dynamicObject struct {
 	baseDynamicObject
 	d DynamicObject
+	// s is set if d implements DynamicObjectWithSymbols
+	s DynamicObjectWithSymbols
 }
 
 type dynamicArray struct {
 	baseDynamicObject
 	a DynamicArray
+	// d is set if a implements DynamicArrayWithDescriptors
+	d DynamicArrayWithDescriptors
 }
 
 /*
@@ -86,9 +136,10 @@ The Object is always extensible and cannot be made non-extensible. Object.preven
 The Object's prototype is initially set to Object.prototype, but can be changed using regular mechanisms
 (Object.SetPrototype() in Go or Object.setPrototypeOf() in JS).
 
-The Object cannot have own Symbol properties, however its prototype can. If you need an iterator support for
-example, you could create a regular object, set Symbol.iterator on that object and then use it as a
-prototype. See TestDynamicObjectCustomProto for more details.
+The Object cannot have own Symbol properties, unless d implements DynamicObjectWithSymbols, however its prototype
+can. If you need an iterator support for example, you could either implement DynamicObjectWithSymbols, or create a
+regular object, set Symbol.iterator on that object and then use it as a prototype. See TestDynamicObjectCustomProto
+for more details.
 
 Export() returns the original DynamicObject.
 
@@ -105,6 +156,7 @@ func (r *Runtime) NewDynamicObject(d DynamicObject) *Object {
 			prototype: r.global.ObjectPrototype,
 		},
 	}
+	o.s, _ = d.(DynamicObjectWithSymbols)
 	v.self = o
 	return v
 }
@@ -121,6 +173,7 @@ func NewSharedDynamicObject(d DynamicObject) *Object {
 			val: v,
 		},
 	}
+	o.s, _ = d.(DynamicObjectWithSymbols)
 	v.self = o
 	return v
 }
@@ -144,6 +197,7 @@ func (r *Runtime) NewDynamicArray(a DynamicArray) *Object {
 			prototype: r.global.ArrayPrototype,
 		},
 	}
+	o.d, _ = a.(DynamicArrayWithDescriptors)
 	v.self = o
 	return v
 }
@@ -161,6 +215,7 @@ func NewSharedDynamicArray(a DynamicArray) *Object {
 			val: v,
 		},
 	}
+	o.d, _ = a.(DynamicArrayWithDescriptors)
 	v.self = o
 	return v
 }
@@ -509,6 +564,12 @@ func (o *baseDynamicObject) iterateSymbols() iterNextFunc {
 }
 
 func (o *dynamicObject) iterateKeys() iterNextFunc {
+	if o.s != nil {
+		return (&objectAllPropIter{
+			o:      o.val,
+			curStr: o.iterateStringKeys(),
+		}).next
+	}
 	return o.iterateStringKeys()
 }
 
@@ -553,7 +614,7 @@ func (*baseDynamicObject) symbols(all bool, accum []Value) []Value {
 }
 
 func (o *dynamicObject) keys(all bool, accum []Value) []Value {
-	return o.stringKeys(all, accum)
+	return o.symbols(all, o.stringKeys(all, accum))
 }
 
 func (*baseDynamicObject) _putProp(name unistring.String, value Value, writable, enumerable, configurable bool) Value {
@@ -595,18 +656,46 @@ func (a *dynamicArray) getStr(p unistring.String, receiver Value) Value {
 		return intToValue(int64(a.a.Len()))
 	}
 	if idx, ok := strToInt(p); ok {
+		if a.d != nil && !a._has(idx) {
+			return a.getParentStr(p, receiver)
+		}
 		return a.a.Get(idx)
 	}
 	return a.getParentStr(p, receiver)
 }
 
 func (a *dynamicArray) getIdx(p valueInt, receiver Value) Value {
-	if val := a.getOwnPropIdx(p); val != nil {
+	idx := toIntStrict(int64(p))
+	if a.d != nil && !a._has(idx) {
+		return a.getParentIdx(p, receiver)
+	}
+	if val := a.a.Get(idx); val != nil {
 		return val
 	}
 	return a.getParentIdx(p, receiver)
 }
 
+// _getOwnProp returns the element at index idx, or its descriptor if it's not writable, enumerable and
+// configurable.
+func (a *dynamicArray) _getOwnProp(idx int) Value {
+	if a.d == nil {
+		return a.a.Get(idx)
+	}
+	if !a._has(idx) {
+		return nil
+	}
+	v := a.a.Get(idx)
+	if writable, enumerable, configurable := a.d.Attributes(idx); !(writable && enumerable && configurable) {
+		return &valueProperty{
+			value:        nilSafe(v),
+			writable:     writable,
+			enumerable:   enumerable,
+			configurable: configurable,
+		}
+	}
+	return v
+}
+
 func (a *dynamicArray) getOwnPropStr(u unistring.String) Value {
 	if u == "length" {
 		return &valueProperty{
@@ -615,13 +704,13 @@ func (a *dynamicArray) getOwnPropStr(u unistring.String) Value {
 		}
 	}
 	if idx, ok := strToInt(u); ok {
-		return a.a.Get(idx)
+		return a._getOwnProp(idx)
 	}
 	return nil
 }
 
 func (a *dynamicArray) getOwnPropIdx(v valueInt) Value {
-	return a.a.Get(toIntStrict(int64(v)))
+	return a._getOwnProp(toIntStrict(int64(v)))
 }
 
 func (a *dynamicArray) _setLen(v Value, throw bool) bool {
@@ -644,6 +733,12 @@ func (a *dynamicArray) setOwnStr(p unistring.String, v Value, throw bool) bool {
 }
 
 func (a *dynamicArray) _setIdx(idx int, v Value, throw bool) bool {
+	if a.d != nil && a._has(idx) {
+		if writable, _, _ := a.d.Attributes(idx); !writable {
+			typeErrorResult(throw, "Cannot assign to read only property '%d' of a dynamic array", idx)
+			return false
+		}
+	}
 	if a.a.Set(idx, v) {
 		return true
 	}
@@ -684,7 +779,7 @@ func (a *dynamicArray) hasPropertyIdx(idx valueInt) bool {
 }
 
 func (a *dynamicArray) _has(idx int) bool {
-	return idx >= 0 && idx < a.a.Len()
+	return idx >= 0 && idx < a.a.Len() && (a.d == nil || a.d.Has(idx))
 }
 
 func (a *dynamicArray) hasOwnPropertyStr(u unistring.String) bool {
@@ -701,24 +796,46 @@ func (a *dynamicArray) hasOwnPropertyIdx(v valueInt) bool {
 	return a._has(toIntStrict(int64(v)))
 }
 
-func (a *dynamicArray) defineOwnPropertyStr(name unistring.String, desc PropertyDescriptor, throw bool) bool {
-	if a.checkDynamicObjectPropertyDescr(name, desc, throw) {
-		if idx, ok := strToInt(name); ok {
-			return a._setIdx(idx, desc.Value, throw)
+func (a *dynamicArray) _defineIdx(idx int, name fmt.Stringer, desc PropertyDescriptor, throw bool) bool {
+	if a.d != nil {
+		if desc.Getter != nil || desc.Setter != nil {
+			typeErrorResult(throw, "Dynamic objects do not support accessor properties")
+			return false
 		}
-		typeErrorResult(throw, "Cannot define property %q on a dynamic array", name.String())
+		if a.d.DefineProperty(idx, desc) {
+			return true
+		}
+		typeErrorResult(throw, "'DefineProperty' on a dynamic array returned false")
+		return false
+	}
+	if a.checkDynamicObjectPropertyDescr(name, desc, throw) {
+		return a._setIdx(idx, desc.Value, throw)
 	}
 	return false
 }
 
-func (a *dynamicArray) defineOwnPropertyIdx(name valueInt, desc PropertyDescriptor, throw bool) bool {
+func (a *dynamicArray) defineOwnPropertyStr(name unistring.String, desc PropertyDescriptor, throw bool) bool {
+	if idx, ok := strToInt(name); ok {
+		return a._defineIdx(idx, name, desc, throw)
+	}
 	if a.checkDynamicObjectPropertyDescr(name, desc, throw) {
-		return a._setIdx(toIntStrict(int64(name)), desc.Value, throw)
+		typeErrorResult(throw, "Cannot define property %q on a dynamic array", name.String())
 	}
 	return false
 }
 
+func (a *dynamicArray) defineOwnPropertyIdx(name valueInt, desc PropertyDescriptor, throw bool) bool {
+	return a._defineIdx(toIntStrict(int64(name)), name, desc, throw)
+}
+
 func (a *dynamicArray) _delete(idx int, throw bool) bool {
+	if a.d != nil {
+		if a.d.Delete(idx) {
+			return true
+		}
+		typeErrorResult(throw, "Could not delete element %d of a dynamic array", idx)
+		return false
+	}
 	if a._has(idx) {
 		a._setIdx(idx, _undefined, throw)
 	}
@@ -741,15 +858,23 @@ func (a *dynamicArray) deleteIdx(idx valueInt, throw bool) bool {
 }
 
 type dynArrayPropIter struct {
-	a          DynamicArray
+	a          *dynamicArray
 	idx, limit int
 }
 
 func (i *dynArrayPropIter) next() (propIterItem, iterNextFunc) {
-	if i.idx < i.limit && i.idx < i.a.Len() {
-		name := strconv.Itoa(i.idx)
+	for i.idx < i.limit && i.idx < i.a.a.Len() {
+		idx := i.idx
 		i.idx++
-		return propIterItem{name: asciiString(name), enumerable: _ENUM_TRUE}, i.next
+		if d := i.a.d; d != nil {
+			if !d.Has(idx) {
+				continue
+			}
+			if _, enumerable, _ := d.Attributes(idx); !enumerable {
+				return propIterItem{name: asciiString(strconv.Itoa(idx)), enumerable: _ENUM_FALSE}, i.next
+			}
+		}
+		return propIterItem{name: asciiString(strconv.Itoa(idx)), enumerable: _ENUM_TRUE}, i.next
 	}
 
 	return propIterItem{}, nil
@@ -757,7 +882,7 @@ func (i *dynArrayPropIter) next() (propIterItem, iterNextFunc) {
 
 func (a *dynamicArray) iterateStringKeys() iterNextFunc {
 	return (&dynArrayPropIter{
-		a:     a.a,
+		a:     a,
 		limit: a.a.Len(),
 	}).next
 }
@@ -793,6 +918,14 @@ func (a *dynamicArray) stringKeys(all bool, accum []Value) []Value {
 		copy(accum, oldAccum)
 	}
 	for i := 0; i < al; i++ {
+		if d := a.d; d != nil {
+			if !d.Has(i) {
+				continue
+			}
+			if _, enumerable, _ := d.Attributes(i); !enumerable && !all {
+				continue
+			}
+		}
 		accum = append(accum, asciiString(strconv.Itoa(i)))
 	}
 	if all {
@@ -804,3 +937,116 @@ func (a *dynamicArray) stringKeys(all bool, accum []Value) []Value {
 func (a *dynamicArray) keys(all bool, accum []Value) []Value {
 	return a.stringKeys(all, accum)
 }
+
+func (o *dynamicObject) getSym(p *Symbol, receiver Value) Value {
+	if o.s != nil {
+		if v := o.s.GetSymbol(p); v != nil {
+			return v
+		}
+	}
+	return o.baseDynamicObject.getSym(p, receiver)
+}
+
+func (o *dynamicObject) getOwnPropSym(p *Symbol) Value {
+	if o.s != nil {
+		if v := o.s.GetSymbol(p); v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+func (o *dynamicObject) _setOwnSym(p *Symbol, v Value, throw bool) bool {
+	if o.s.SetSymbol(p, v) {
+		return true
+	}
+	typeErrorResult(throw, "'SetSymbol' on a dynamic object returned false")
+	return false
+}
+
+func (o *dynamicObject) setOwnSym(p *Symbol, v Value, throw bool) bool {
+	if o.s == nil {
+		return o.baseDynamicObject.setOwnSym(p, v, throw)
+	}
+	if !o.hasOwnPropertySym(p) {
+		if proto := o.prototype; proto != nil {
+			// we know it's foreign because prototype loops are not allowed
+			if res, handled := proto.self.setForeignSym(p, v, o.val, throw); handled {
+				return res
+			}
+		}
+	}
+	return o._setOwnSym(p, v, throw)
+}
+
+func (o *dynamicObject) setForeignSym(p *Symbol, v, receiver Value, throw bool) (res bool, handled bool) {
+	if o.hasOwnPropertySym(p) {
+		return false, false
+	}
+	return o.baseDynamicObject.setForeignSym(p, v, receiver, throw)
+}
+
+func (o *dynamicObject) hasPropertySym(p *Symbol) bool {
+	if o.hasOwnPropertySym(p) {
+		return true
+	}
+	return o.baseDynamicObject.hasPropertySym(p)
+}
+
+func (o *dynamicObject) hasOwnPropertySym(p *Symbol) bool {
+	return o.s != nil && o.s.GetSymbol(p) != nil
+}
+
+func (o *dynamicObject) defineOwnPropertySym(name *Symbol, desc PropertyDescriptor, throw bool) bool {
+	if o.s == nil {
+		return o.baseDynamicObject.defineOwnPropertySym(name, desc, throw)
+	}
+	if o.checkDynamicObjectPropertyDescr(name, desc, throw) {
+		return o._setOwnSym(name, desc.Value, throw)
+	}
+	return false
+}
+
+func (o *dynamicObject) deleteSym(p *Symbol, throw bool) bool {
+	if o.s == nil || o.s.DeleteSymbol(p) {
+		return true
+	}
+	typeErrorResult(throw, "Could not delete property %s of a dynamic object", p.descriptiveString())
+	return false
+}
+
+type dynamicObjectSymbolIter struct {
+	o    *dynamicObject
+	syms []*Symbol
+	idx  int
+}
+
+func (i *dynamicObjectSymbolIter) next() (propIterItem, iterNextFunc) {
+	for i.idx < len(i.syms) {
+		sym := i.syms[i.idx]
+		i.idx++
+		if i.o.hasOwnPropertySym(sym) {
+			return propIterItem{name: sym, enumerable: _ENUM_TRUE}, i.next
+		}
+	}
+	return propIterItem{}, nil
+}
+
+func (o *dynamicObject) iterateSymbols() iterNextFunc {
+	if o.s == nil {
+		return o.baseDynamicObject.iterateSymbols()
+	}
+	return (&dynamicObjectSymbolIter{
+		o:    o,
+		syms: o.s.SymbolKeys(),
+	}).next
+}
+
+func (o *dynamicObject) symbols(all bool, accum []Value) []Value {
+	if o.s != nil {
+		for _, sym := range o.s.SymbolKeys() {
+			accum = append(accum, sym)
+		}
+	}
+	return accum
+}
@@ -164,6 +164,82 @@ func TestDynamicObjectCustomProto(t *testing.T) {
 	}
 }
 
+type testDynObjectWithSymbols struct {
+	testDynObject
+	syms map[*Symbol]Value
+}
+
+func (t *testDynObjectWithSymbols) GetSymbol(sym *Symbol) Value {
+	return t.syms[sym]
+}
+
+func (t *testDynObjectWithSymbols) SetSymbol(sym *Symbol, val Value) bool {
+	t.syms[sym] = val
+	return true
+}
+
+func (t *testDynObjectWithSymbols) DeleteSymbol(sym *Symbol) bool {
+	delete(t.syms, sym)
+	return true
+}
+
+func (t *testDynObjectWithSymbols) SymbolKeys() []*Symbol {
+	keys := make([]*Symbol, 0, len(t.syms))
+	for k := range t.syms {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func TestDynamicObjectWithSymbols(t *testing.T) {
+	vm := New()
+	dynObj := &testDynObjectWithSymbols{
+		testDynObject: testDynObject{
+			r: vm,
+			m: make(map[string]Value),
+		},
+		syms: make(map[*Symbol]Value),
+	}
+	dynObj.syms[SymToStringTag] = asciiString("Dyn")
+	dynObj.syms[SymToPrimitive] = vm.ToValue(func(call FunctionCall) Value {
+		if call.Argument(0).String() == "number" {
+			return intToValue(42)
+		}
+		return asciiString("dyn")
+	})
+	o := vm.NewDynamicObject(dynObj)
+	vm.Set("o", o)
+	vm.testScriptWithTestLibX(`
+	assert.sameValue(Object.prototype.toString.call(o), "[object Dyn]", "toStringTag");
+	assert.sameValue(+o, 42, "toPrimitive number");
+	assert.sameValue("" + o, "dyn", "toPrimitive default");
+
+	o.items = [1, 2, 3];
+	o[Symbol.iterator] = function() {
+		return this.items[Symbol.iterator]();
+	};
+	assert(compareArray([...o], [1, 2, 3]), "iterator");
+	assert(Symbol.iterator in o && o.hasOwnProperty(Symbol.iterator), "has");
+	assert(deepEqual(Object.getOwnPropertyDescriptor(o, Symbol.toStringTag), {value: "Dyn", writable: true, enumerable: true, configurable: true}), "prop desc");
+	assert.sameValue(Object.getOwnPropertySymbols(o).length, 3, "getOwnPropertySymbols");
+	assert.sameValue(Reflect.ownKeys(o).length, 4, "ownKeys");
+
+	var sym = Symbol("s");
+	Object.defineProperty(o, sym, {value: 1, writable: true, enumerable: true, configurable: true});
+	assert.sameValue(o[sym], 1, "defineProperty");
+	assert.throws(TypeError, function() {
+		Object.defineProperty(o, sym, {value: 1, writable: false});
+	}, "define read-only");
+	assert(delete o[sym], "delete");
+	assert(!(sym in o), "deleted");
+	assert.sameValue(Object.assign({}, o)[Symbol.toStringTag], "Dyn", "assign");
+	`, _undefined, t)
+
+	if _, ok := dynObj.syms[SymIterator]; !ok {
+		t.Fatal("Symbol.iterator was not set on the handler")
+	}
+}
+
 func TestDynamicArray(t *testing.T) {
 	vm := New()
 	dynObj := &testDynArray{
@@ -287,6 +363,90 @@ func (t *testSharedDynObject) Keys() []string {
 	return keys
 }
 
+type testDynSparseArray struct {
+	testDynArray
+	readOnly, hidden map[int]bool
+}
+
+func (t *testDynSparseArray) Has(idx int) bool {
+	return t.a[idx] != nil
+}
+
+func (t *testDynSparseArray) Attributes(idx int) (writable, enumerable, configurable bool) {
+	return !t.readOnly[idx], !t.hidden[idx], !t.readOnly[idx]
+}
+
+func (t *testDynSparseArray) DefineProperty(idx int, desc PropertyDescriptor) bool {
+	if idx < 0 || t.readOnly[idx] {
+		return false
+	}
+	if !t.Set(idx, desc.Value) {
+		return false
+	}
+	t.readOnly[idx] = desc.Writable == FLAG_FALSE
+	t.hidden[idx] = desc.Enumerable == FLAG_FALSE
+	return true
+}
+
+func (t *testDynSparseArray) Delete(idx int) bool {
+	if t.readOnly[idx] {
+		return false
+	}
+	if idx >= 0 && idx < len(t.a) {
+		t.a[idx] = nil
+	}
+	return true
+}
+
+func TestDynamicArrayWithDescriptors(t *testing.T) {
+	vm := New()
+	dynArr := &testDynSparseArray{
+		testDynArray: testDynArray{r: vm},
+		readOnly:     make(map[int]bool),
+		hidden:       make(map[int]bool),
+	}
+	a := vm.NewDynamicArray(dynArr)
+	vm.Set("a", a)
+	vm.testScriptWithTestLibX(`
+	a[0] = "a";
+	a[3] = "d";
+	assert.sameValue(a.length, 4, "length");
+	assert(!a.hasOwnProperty(1) && !(2 in a), "holes");
+	assert(compareArray(Object.keys(a), ["0", "3"]), "keys");
+
+	Array.prototype[1] = "proto";
+	assert.sameValue(a[1], "proto", "hole falls through to the prototype");
+	delete Array.prototype[1];
+
+	Object.defineProperty(a, 1, {value: "b", writable: false, enumerable: false, configurable: false});
+	assert.sameValue(a[1], "b", "defined");
+	assert(deepEqual(Object.getOwnPropertyDescriptor(a, 1), {value: "b", writable: false, enumerable: false, configurable: false}), "prop desc");
+	assert(deepEqual(Object.getOwnPropertyDescriptor(a, 0), {value: "a", writable: true, enumerable: true, configurable: true}), "default prop desc");
+	var keys = [];
+	for (var k in a) keys.push(k);
+	assert(compareArray(keys, ["0", "3"]), "for-in skips non-enumerable");
+	assert(compareArray(Object.getOwnPropertyNames(a), ["0", "1", "3", "length"]), "getOwnPropertyNames");
+
+	a[1] = "x";
+	assert.sameValue(a[1], "b", "read-only");
+	assert.throws(TypeError, function() {
+		"use strict";
+		a[1] = "x";
+	}, "read-only in strict mode");
+	assert.throws(TypeError, function() {
+		"use strict";
+		delete a[1];
+	}, "non-configurable");
+	assert.throws(TypeError, function() {
+		Object.defineProperty(a, 2, {get: function() {}});
+	}, "accessor");
+
+	assert(delete a[0], "delete");
+	assert(!(0 in a), "deleted");
+	assert.sameValue(a.length, 4, "length after delete");
+	`, _undefined, t)
+}
+
 func TestSharedDynamicObject(t *testing.T) {
 	dynObj := &testSharedDynObject{m: make(map[string]Value, 10000)}
 	o := NewSharedDynamicObject(dynObj)
@@ -2,6 +2,7 @@ package goja
 
 import (
 	"reflect"
+	"sort"
 
 	"github.com/dop251/goja/unistring"
 )
@@ -145,6 +146,9 @@ func (o *objectGoMapSimple) iterateStringKeys() iterNextFunc {
 		propNames[i] = key
 		i++
 	}
+	if o.val.runtime.sortedMapKeys {
+		sort.Strings(propNames)
+	}
 
 	return (&gomapPropIter{
 		o:         o,
@@ -154,6 +158,17 @@ func (o *objectGoMapSimple) iterateStringKeys() iterNextFunc {
 
 func (o *objectGoMapSimple) stringKeys(_ bool, accum []Value) []Value {
 	// all own keys are enumerable
+	if o.val.runtime.sortedMapKeys {
+		keys := make([]string, 0, len(o.data))
+		for key := range o.data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			accum = append(accum, newStringValue(key))
+		}
+		return accum
+	}
 	for key := range o.data {
 		accum = append(accum, newStringValue(key))
 	}
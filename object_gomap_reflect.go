@@ -2,6 +2,7 @@ package goja
 
 import (
 	"reflect"
+	"sort"
 
 	"github.com/dop251/goja/unistring"
 )
@@ -241,24 +242,64 @@ func (i *gomapReflectPropIter) next() (propIterItem, iterNextFunc) {
 		v := i.o.fieldsValue.MapIndex(key)
 		i.idx++
 		if v.IsValid() {
-			return propIterItem{name: newStringValue(key.String()), enumerable: _ENUM_TRUE}, i.next
+			return propIterItem{name: i.o.keyName(key), enumerable: _ENUM_TRUE}, i.next
 		}
 	}
 
 	return propIterItem{}, nil
 }
 
+// keyName returns the property name of a map key.
+func (o *objectGoMapReflect) keyName(key reflect.Value) valueString {
+	if key.Kind() == reflect.String {
+		return newStringValue(key.String())
+	}
+	return o.val.runtime.toValue(key.Interface(), key).toString()
+}
+
+// mapKeys returns the keys of the map, sorted if the Runtime has SetSortedMapKeys enabled.
+func (o *objectGoMapReflect) mapKeys() []reflect.Value {
+	keys := o.fieldsValue.MapKeys()
+	if o.val.runtime.sortedMapKeys {
+		sortMapKeys(keys)
+	}
+	return keys
+}
+
+// sortMapKeys sorts the keys of a map with a string or a numeric key type in the ascending order.
+func sortMapKeys(keys []reflect.Value) {
+	if len(keys) == 0 {
+		return
+	}
+	var less func(a, b reflect.Value) bool
+	switch keys[0].Kind() {
+	case reflect.String:
+		less = func(a, b reflect.Value) bool { return a.String() < b.String() }
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		less = func(a, b reflect.Value) bool { return a.Int() < b.Int() }
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		less = func(a, b reflect.Value) bool { return a.Uint() < b.Uint() }
+	case reflect.Float32, reflect.Float64:
+		less = func(a, b reflect.Value) bool { return a.Float() < b.Float() }
+	default:
+		return
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return less(keys[i], keys[j])
+	})
+}
+
 func (o *objectGoMapReflect) iterateStringKeys() iterNextFunc {
 	return (&gomapReflectPropIter{
 		o:    o,
-		keys: o.fieldsValue.MapKeys(),
+		keys: o.mapKeys(),
 	}).next
 }
 
 func (o *objectGoMapReflect) stringKeys(_ bool, accum []Value) []Value {
 	// all own keys are enumerable
-	for _, key := range o.fieldsValue.MapKeys() {
-		accum = append(accum, newStringValue(key.String()))
+	for _, key := range o.mapKeys() {
+		accum = append(accum, o.keyName(key))
 	}
 
 	return accum
@@ -307,3 +307,22 @@ func TestGoMapReflectElt(t *testing.T) {
 
 	r.testScript(SCRIPT, valueTrue, t)
 }
+
+func TestGoMapSortedKeys(t *testing.T) {
+	vm := New()
+	vm.SetSortedMapKeys(true)
+	vm.Set("s", map[string]interface{}{"b": 1, "c": 2, "a": 3})
+	vm.Set("r", map[string]int{"y": 1, "z": 2, "x": 3})
+	vm.Set("n", map[int]string{20: "b", 3: "c", -1: "a"})
+	res, err := vm.RunString(`
+	var keys = [];
+	for (var k in r) keys.push(k);
+	[JSON.stringify(s), Object.keys(r).join(), keys.join(), JSON.stringify(n), Object.keys(n).join()].join(" ");
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := res.String(); s != `{"a":3,"b":1,"c":2} x,y,z x,y,z {"-1":"a","3":"c","20":"b"} -1,3,20` {
+		t.Fatal(s)
+	}
+}
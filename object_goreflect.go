@@ -5,6 +5,7 @@ import (
 	"go/ast"
 	"reflect"
 	"strings"
+	"sync"
 
 	"github.com/dop251/goja/parser"
 	"github.com/dop251/goja/unistring"
@@ -16,6 +17,11 @@ type JsonEncodable interface {
 	JsonEncodable() interface{}
 }
 
+// RawJSON is JSON text that JSON.stringify() outputs verbatim. It can be returned by JsonEncodable to serialise
+// a Go value as a raw JSON token, e.g. a big.Int or a decimal as a number rather than a string. The text is not
+// validated, it is the caller's responsibility to make sure it is valid JSON.
+type RawJSON string
+
 // FieldNameMapper provides custom mapping between Go and JavaScript property names.
 type FieldNameMapper interface {
 	// FieldName returns a JavaScript name for the given struct field in the given type.
@@ -78,6 +84,22 @@ type reflectFieldsInfo struct {
 type reflectMethodsInfo struct {
 	Methods map[string]int
 	Names   []string
+
+	Accessors     map[string]reflectAccessorInfo
+	AccessorNames []string
+
+	// the indexes of the methods that are mapped to the same name, see SetOverloadResolver
+	Overloads map[string][]int
+}
+
+// reflectAccessorInfo holds the indexes of the methods implementing an accessor property, see SetAccessorMethods.
+type reflectAccessorInfo struct {
+	Getter int
+	Setter int // -1 if there is no setter
+}
+
+func (i *reflectMethodsInfo) empty() bool {
+	return len(i.Names) == 0 && len(i.AccessorNames) == 0
 }
 
 type reflectValueWrapper interface {
@@ -163,7 +185,7 @@ func (o *objectGoReflect) init() {
 
 	// Container values and values that have at least one method defined on the pointer type
 	// need to be addressable.
-	if !o.origValue.CanAddr() && (isContainer(o.origValue.Kind()) || len(o.methodsInfo.Names) > 0) {
+	if !o.origValue.CanAddr() && (isContainer(o.origValue.Kind()) || !o.methodsInfo.empty()) {
 		value := reflect.New(o.origValue.Type()).Elem()
 		value.Set(o.origValue)
 		o.origValue = value
@@ -186,7 +208,7 @@ func (o *objectGoReflect) init() {
 		o.baseObject._putProp("valueOf", o.val.runtime.newNativeFunc(o.valueOfFunc, nil, "valueOf", nil, 0), true, false, true)
 	}
 
-	if len(o.methodsInfo.Names) > 0 && o.fieldsValue.Kind() != reflect.Interface {
+	if !o.methodsInfo.empty() && o.fieldsValue.Kind() != reflect.Interface {
 		o.methodsValue = o.fieldsValue.Addr()
 	} else {
 		o.methodsValue = o.fieldsValue
@@ -232,6 +254,19 @@ func (o *objectGoReflect) _getMethod(jsName string) reflect.Value {
 	return reflect.Value{}
 }
 
+func (o *objectGoReflect) _getAccessor(jsName string) (reflectAccessorInfo, bool) {
+	if o.methodsInfo != nil {
+		a, exists := o.methodsInfo.Accessors[jsName]
+		return a, exists
+	}
+	return reflectAccessorInfo{}, false
+}
+
+// callAccessor calls the getter or the setter method with the given index, args are the JavaScript arguments.
+func (o *objectGoReflect) callAccessor(idx int, args ...Value) Value {
+	return o.val.runtime.wrapReflectFunc(o.methodsValue.Method(idx), nil)(FunctionCall{This: o.val, Arguments: args})
+}
+
 func (o *objectGoReflect) elemToValue(ev reflect.Value) (Value, reflectValueWrapper) {
 	if isContainer(ev.Kind()) {
 		ret := o.val.runtime.toValue(ev.Interface(), ev)
@@ -248,7 +283,7 @@ func (o *objectGoReflect) elemToValue(ev reflect.Value) (Value, reflectValueWrap
 	}
 
 	if ev.Kind() == reflect.Invalid {
-		return _null, nil
+		return o.val.runtime.nilValue(), nil
 	}
 
 	return o.val.runtime.toValue(ev.Interface(), ev), nil
@@ -278,6 +313,20 @@ func (o *objectGoReflect) _get(name string) Value {
 		}
 	}
 
+	if a, exists := o._getAccessor(name); exists {
+		return o.callAccessor(a.Getter)
+	}
+
+	return o._getMethodValue(name)
+}
+
+func (o *objectGoReflect) _getMethodValue(name string) Value {
+	if o.val.runtime.overloadResolver != nil && o.methodsInfo != nil {
+		if indexes, exists := o.methodsInfo.Overloads[name]; exists {
+			return o.overloadedMethod(name, indexes)
+		}
+	}
+
 	if v := o._getMethod(name); v.IsValid() {
 		return o.val.runtime.toValue(v.Interface(), v)
 	}
@@ -297,9 +346,27 @@ func (o *objectGoReflect) getOwnPropStr(name unistring.String) Value {
 		}
 	}
 
-	if v := o._getMethod(n); v.IsValid() {
+	if a, exists := o._getAccessor(n); exists {
+		r := o.val.runtime
+		prop := &valueProperty{
+			accessor:   true,
+			enumerable: true,
+		}
+		prop.getterFunc = r.newNativeFunc(func(FunctionCall) Value {
+			return o.callAccessor(a.Getter)
+		}, nil, "get "+name, nil, 0)
+		if a.Setter >= 0 {
+			prop.setterFunc = r.newNativeFunc(func(call FunctionCall) Value {
+				o.callAccessor(a.Setter, call.Argument(0))
+				return _undefined
+			}, nil, "set "+name, nil, 1)
+		}
+		return prop
+	}
+
+	if v := o._getMethodValue(n); v != nil {
 		return &valueProperty{
-			value:      o.val.runtime.toValue(v.Interface(), v),
+			value:      v,
 			enumerable: true,
 		}
 	}
@@ -350,6 +417,14 @@ func (o *objectGoReflect) _put(name string, val Value, throw bool) (has, ok bool
 			return true, true
 		}
 	}
+	if a, exists := o._getAccessor(name); exists {
+		if a.Setter < 0 {
+			o.val.runtime.typeErrorResult(throw, "Cannot set property %s of a host object which has only a getter", name)
+			return true, false
+		}
+		o.callAccessor(a.Setter, val)
+		return true, true
+	}
 	return false, false
 }
 
@@ -395,6 +470,9 @@ func (o *objectGoReflect) _has(name string) bool {
 			return true
 		}
 	}
+	if _, exists := o._getAccessor(name); exists {
+		return true
+	}
 	if v := o._getMethod(name); v.IsValid() {
 		return true
 	}
@@ -498,6 +576,18 @@ func (i *goreflectPropIter) nextField() (propIterItem, iterNextFunc) {
 		return propIterItem{name: newStringValue(name), enumerable: _ENUM_TRUE}, i.nextField
 	}
 
+	i.idx = 0
+	return i.nextAccessor()
+}
+
+func (i *goreflectPropIter) nextAccessor() (propIterItem, iterNextFunc) {
+	names := i.o.methodsInfo.AccessorNames
+	if i.idx < len(names) {
+		name := names[i.idx]
+		i.idx++
+		return propIterItem{name: newStringValue(name), enumerable: _ENUM_TRUE}, i.nextAccessor
+	}
+
 	i.idx = 0
 	return i.nextMethod()
 }
@@ -521,7 +611,7 @@ func (o *objectGoReflect) iterateStringKeys() iterNextFunc {
 		return r.nextField
 	}
 
-	return r.nextMethod
+	return r.nextAccessor
 }
 
 func (o *objectGoReflect) stringKeys(_ bool, accum []Value) []Value {
@@ -532,6 +622,10 @@ func (o *objectGoReflect) stringKeys(_ bool, accum []Value) []Value {
 		}
 	}
 
+	for _, name := range o.methodsInfo.AccessorNames {
+		accum = append(accum, newStringValue(name))
+	}
+
 	for _, name := range o.methodsInfo.Names {
 		accum = append(accum, newStringValue(name))
 	}
@@ -578,12 +672,13 @@ func (r *Runtime) buildFieldInfo(t reflect.Type, index []int, info *reflectField
 	n := t.NumField()
 	for i := 0; i < n; i++ {
 		field := t.Field(i)
-		name := field.Name
-		if !ast.IsExported(name) {
+		promote := r.promotesEmbedded(field)
+		if !ast.IsExported(field.Name) && !promote {
 			continue
 		}
-		if r.fieldNameMapper != nil {
-			name = r.fieldNameMapper.FieldName(t, field)
+		name := r.fieldNames(t)[i]
+		if promote && r.embeddedStructMode == EmbeddedStructPromote {
+			name = ""
 		}
 
 		if name != "" {
@@ -607,19 +702,33 @@ func (r *Runtime) buildFieldInfo(t reflect.Type, index []int, info *reflectField
 					Anonymous: field.Anonymous,
 				}
 			}
-			if field.Anonymous {
-				typ := field.Type
-				for typ.Kind() == reflect.Ptr {
-					typ = typ.Elem()
-				}
-				if typ.Kind() == reflect.Struct {
-					r.buildFieldInfo(typ, idx, info)
-				}
+			if promote {
+				r.buildFieldInfo(embeddedStructType(field.Type), idx, info)
 			}
 		}
 	}
 }
 
+func embeddedStructType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// promotesEmbedded returns true if the fields of the given struct field are promoted to the wrapping object. Like in
+// encoding/json, the exported fields of unexported embedded structs are promoted as well, unless they are embedded
+// by pointer (such fields could not be set through reflection).
+func (r *Runtime) promotesEmbedded(field reflect.StructField) bool {
+	if !field.Anonymous || r.embeddedStructMode == EmbeddedStructNest {
+		return false
+	}
+	if !ast.IsExported(field.Name) {
+		return field.Type.Kind() == reflect.Struct
+	}
+	return embeddedStructType(field.Type).Kind() == reflect.Struct
+}
+
 var emptyMethodsInfo = reflectMethodsInfo{}
 
 func (r *Runtime) buildMethodsInfo(t reflect.Type) (info *reflectMethodsInfo) {
@@ -630,10 +739,14 @@ func (r *Runtime) buildMethodsInfo(t reflect.Type) (info *reflectMethodsInfo) {
 	info = new(reflectMethodsInfo)
 	info.Methods = make(map[string]int, n)
 	info.Names = make([]string, 0, n)
+	var accessorMethods map[int]bool
+	if r.accessorMethods {
+		accessorMethods = r.buildAccessorsInfo(t, info)
+	}
 	for i := 0; i < n; i++ {
 		method := t.Method(i)
 		name := method.Name
-		if !ast.IsExported(name) {
+		if !ast.IsExported(name) || accessorMethods[i] {
 			continue
 		}
 		if r.fieldNameMapper != nil {
@@ -643,8 +756,20 @@ func (r *Runtime) buildMethodsInfo(t reflect.Type) (info *reflectMethodsInfo) {
 			}
 		}
 
-		if _, exists := info.Methods[name]; !exists {
+		if _, exists := info.Accessors[name]; exists {
+			continue
+		}
+
+		if prev, exists := info.Methods[name]; !exists {
 			info.Names = append(info.Names, name)
+		} else {
+			if info.Overloads == nil {
+				info.Overloads = make(map[string][]int)
+			}
+			if len(info.Overloads[name]) == 0 {
+				info.Overloads[name] = []int{prev}
+			}
+			info.Overloads[name] = append(info.Overloads[name], i)
 		}
 
 		info.Methods[name] = i
@@ -652,6 +777,92 @@ func (r *Runtime) buildMethodsInfo(t reflect.Type) (info *reflectMethodsInfo) {
 	return
 }
 
+// buildAccessorsInfo finds the getter (GetX) and the setter (SetX) methods of the type t and adds the corresponding
+// accessor properties to info. It returns the indexes of the methods that are used by the accessors.
+func (r *Runtime) buildAccessorsInfo(t reflect.Type, info *reflectMethodsInfo) map[int]bool {
+	// the methods of non-interface types take the receiver as the first parameter
+	recv := 1
+	if t.Kind() == reflect.Interface {
+		recv = 0
+	}
+	var used map[int]bool
+	n := t.NumMethod()
+	for i := 0; i < n; i++ {
+		getter := t.Method(i)
+		typ, ok := getterType(getter, recv)
+		if !ok {
+			continue
+		}
+		name := getter.Name[len("Get"):]
+		acc := reflectAccessorInfo{Getter: i, Setter: -1}
+		if setter, exists := t.MethodByName("Set" + name); exists && isSetter(setter, recv, typ) {
+			acc.Setter = setter.Index
+		}
+		if r.fieldNameMapper != nil {
+			m := getter
+			m.Name = name
+			name = r.fieldNameMapper.MethodName(t, m)
+			if name == "" {
+				continue
+			}
+		}
+		if _, exists := info.Accessors[name]; exists {
+			continue
+		}
+		if info.Accessors == nil {
+			info.Accessors = make(map[string]reflectAccessorInfo)
+			used = make(map[int]bool)
+		}
+		info.Accessors[name] = acc
+		info.AccessorNames = append(info.AccessorNames, name)
+		used[acc.Getter] = true
+		if acc.Setter >= 0 {
+			used[acc.Setter] = true
+		}
+	}
+	return used
+}
+
+// getterType returns the type of the value returned by the getter method m, the second result is false if m is not
+// a getter, i.e. its name is not GetX or it does not have the signature of func() T or func() (T, error).
+func getterType(m reflect.Method, recv int) (reflect.Type, bool) {
+	if len(m.Name) <= len("Get") || !strings.HasPrefix(m.Name, "Get") || !ast.IsExported(m.Name[len("Get"):]) {
+		return nil, false
+	}
+	typ := m.Type
+	if typ.NumIn() != recv {
+		return nil, false
+	}
+	switch typ.NumOut() {
+	case 1:
+		if typ.Out(0) == reflectTypeError {
+			return nil, false
+		}
+	case 2:
+		if typ.Out(1) != reflectTypeError {
+			return nil, false
+		}
+	default:
+		return nil, false
+	}
+	return typ.Out(0), true
+}
+
+// isSetter returns true if m has the signature of func(T) or func(T) error.
+func isSetter(m reflect.Method, recv int, t reflect.Type) bool {
+	typ := m.Type
+	if typ.NumIn() != recv+1 || typ.In(recv) != t || typ.IsVariadic() {
+		return false
+	}
+	switch typ.NumOut() {
+	case 0:
+		return true
+	case 1:
+		return typ.Out(0) == reflectTypeError
+	}
+	return false
+}
+
 func (r *Runtime) buildFieldsInfo(t reflect.Type) (info *reflectFieldsInfo) {
 	info = new(reflectFieldsInfo)
 	n := t.NumField()
@@ -661,6 +872,32 @@ func (r *Runtime) buildFieldsInfo(t reflect.Type) (info *reflectFieldsInfo) {
 	return
 }
 
+// fieldNames returns the JavaScript names of the fields of the struct type t, in the order of the fields. The
+// names are computed once per type so that the mapper does not run on every conversion.
+func (r *Runtime) fieldNames(t reflect.Type) []string {
+	if names, exists := r.fieldNamesCache[t]; exists {
+		return names
+	}
+	n := t.NumField()
+	names := make([]string, n)
+	for i := 0; i < n; i++ {
+		field := t.Field(i)
+		if !ast.IsExported(field.Name) {
+			continue
+		}
+		if r.fieldNameMapper != nil {
+			names[i] = r.fieldNameMapper.FieldName(t, field)
+		} else {
+			names[i] = field.Name
+		}
+	}
+	if r.fieldNamesCache == nil {
+		r.fieldNamesCache = make(map[reflect.Type][]string)
+	}
+	r.fieldNamesCache[t] = names
+	return names
+}
+
 func (r *Runtime) fieldsInfo(t reflect.Type) (info *reflectFieldsInfo) {
 	var exists bool
 	if info, exists = r.fieldsInfoCache[t]; !exists {
@@ -695,6 +932,90 @@ func (r *Runtime) SetFieldNameMapper(mapper FieldNameMapper) {
 	r.fieldNameMapper = mapper
 	r.fieldsInfoCache = nil
 	r.methodsInfoCache = nil
+	r.fieldNamesCache = nil
+}
+
+// EmbeddedStructMode defines how the fields of embedded (anonymous) struct fields are exposed by ToValue() and
+// read by ExportTo(). See SetEmbeddedStructMode.
+type EmbeddedStructMode int
+
+const (
+	// EmbeddedStructPromoteAndNest promotes the fields of the embedded struct onto the wrapping object and also
+	// exposes the embedded struct itself as a property named after its type. This is the default.
+	EmbeddedStructPromoteAndNest EmbeddedStructMode = iota
+
+	// EmbeddedStructPromote promotes the fields of the embedded struct onto the wrapping object, the embedded
+	// struct itself is not exposed. This matches encoding/json.
+	EmbeddedStructPromote
+
+	// EmbeddedStructNest exposes the embedded struct as a nested property only, as if it was a named field.
+	EmbeddedStructNest
+)
+
+// SetEmbeddedStructMode sets how the fields of embedded structs are mapped. Like with SetFieldNameMapper, the
+// mapping for any given value is fixed at the point of creation. Embedded fields of non-struct types are always
+// exposed as properties. The exported fields of unexported embedded structs are promoted in both promoting modes
+// (but not if the struct is embedded by pointer), in EmbeddedStructNest mode they are not exposed at all.
+//
+// ExportTo() follows the same mode: in EmbeddedStructNest mode an embedded struct field is exported from the
+// property with its name, otherwise it's exported from the object itself.
+func (r *Runtime) SetEmbeddedStructMode(mode EmbeddedStructMode) {
+	r.embeddedStructMode = mode
+	r.fieldsInfoCache = nil
+}
+
+// SetAccessorMethods sets whether the getter and setter methods of the Go types converted by ToValue() are exposed
+// as accessor properties instead of methods. A method named GetX with the signature of func() T or func() (T, error)
+// becomes the getter of the property X, and if there is a method SetX with the signature of func(T) or func(T) error
+// it becomes the setter, otherwise the property is read-only. The name of the property is the result of the
+// MethodName() of the field name mapper (see SetFieldNameMapper) called with the method renamed to X, so with
+// UncapFieldNameMapper():
+//
+//	type Person struct {
+//		First, Last string
+//	}
+//
+//	func (p *Person) GetFullName() string {
+//		return p.First + " " + p.Last
+//	}
+//
+//	func (p *Person) SetFullName(name string) error {
+//		first, last, ok := strings.Cut(name, " ")
+//		if !ok {
+//			return errors.New("invalid name")
+//		}
+//		p.First, p.Last = first, last
+//		return nil
+//	}
+//
+//	r.SetAccessorMethods(true)
+//	r.SetFieldNameMapper(goja.UncapFieldNameMapper())
+//	r.Set("p", &Person{"John", "Doe"})
+//	r.RunString(`p.fullName = "Jane " + p.last`) // p.First == "Jane"
+//
+// The methods used by the accessors are not available as methods. The errors returned by them are thrown as
+// GoErrors. A field with the same name takes precedence over the accessor. Like with SetFieldNameMapper, the
+// mapping for any given value is fixed at the point of creation.
+func (r *Runtime) SetAccessorMethods(enabled bool) {
+	r.accessorMethods = enabled
+	r.methodsInfoCache = nil
+}
+
+// WarmTypeCache computes the property names of the given types and stores them in the cache of the Runtime, so
+// that the field name mapper does not run when the first value of each type is converted. Pointer types are
+// dereferenced, the fields of struct types and the methods of both the type and the pointer to it are cached.
+// The cache is dropped by SetFieldNameMapper.
+func (r *Runtime) WarmTypeCache(types ...reflect.Type) {
+	for _, t := range types {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() == reflect.Struct {
+			r.fieldsInfo(t)
+		}
+		r.methodsInfo(t)
+		r.methodsInfo(reflect.PtrTo(t))
+	}
 }
 
 // TagFieldNameMapper returns a FieldNameMapper that uses the given tagName for struct fields and optionally
@@ -713,3 +1034,111 @@ func TagFieldNameMapper(tagName string, uncapMethods bool) FieldNameMapper {
 func UncapFieldNameMapper() FieldNameMapper {
 	return uncapFieldNameMapper{}
 }
+
+type chainFieldNameMapper []FieldNameMapper
+
+func (c chainFieldNameMapper) FieldName(t reflect.Type, f reflect.StructField) string {
+	for _, m := range c {
+		name := m.FieldName(t, f)
+		if name == "" {
+			return ""
+		}
+		f.Name = name
+	}
+	return f.Name
+}
+
+func (c chainFieldNameMapper) MethodName(t reflect.Type, method reflect.Method) string {
+	for _, m := range c {
+		name := m.MethodName(t, method)
+		if name == "" {
+			return ""
+		}
+		method.Name = name
+	}
+	return method.Name
+}
+
+// ChainFieldNameMapper returns a FieldNameMapper that applies the given mappers in turn: each mapper receives
+// the field or the method with the Name set to the result of the previous one. If any of the mappers returns ""
+// the field or the method becomes hidden.
+func ChainFieldNameMapper(mappers ...FieldNameMapper) FieldNameMapper {
+	return chainFieldNameMapper(mappers)
+}
+
+type fallbackFieldNameMapper []FieldNameMapper
+
+func (fb fallbackFieldNameMapper) FieldName(t reflect.Type, f reflect.StructField) string {
+	for _, m := range fb {
+		if name := m.FieldName(t, f); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+func (fb fallbackFieldNameMapper) MethodName(t reflect.Type, method reflect.Method) string {
+	for _, m := range fb {
+		if name := m.MethodName(t, method); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// FallbackFieldNameMapper returns a FieldNameMapper that uses the first non-empty name returned by the given
+// mappers. The field or the method is hidden only if all of them return "". For example
+// FallbackFieldNameMapper(TagFieldNameMapper("json", true), UncapFieldNameMapper()) uses the json tag if it's
+// present and the uncapitalised field name otherwise.
+func FallbackFieldNameMapper(mappers ...FieldNameMapper) FieldNameMapper {
+	return fallbackFieldNameMapper(mappers)
+}
+
+type memoizedNameKey struct {
+	t      reflect.Type
+	name   string
+	method bool
+}
+
+type memoizedFieldNameMapper struct {
+	mapper FieldNameMapper
+	mu     sync.RWMutex
+	names  map[memoizedNameKey]string
+}
+
+func (m *memoizedFieldNameMapper) lookup(key memoizedNameKey, compute func() string) string {
+	m.mu.RLock()
+	name, exists := m.names[key]
+	m.mu.RUnlock()
+	if exists {
+		return name
+	}
+	name = compute()
+	m.mu.Lock()
+	m.names[key] = name
+	m.mu.Unlock()
+	return name
+}
+
+func (m *memoizedFieldNameMapper) FieldName(t reflect.Type, f reflect.StructField) string {
+	return m.lookup(memoizedNameKey{t: t, name: f.Name}, func() string {
+		return m.mapper.FieldName(t, f)
+	})
+}
+
+func (m *memoizedFieldNameMapper) MethodName(t reflect.Type, method reflect.Method) string {
+	return m.lookup(memoizedNameKey{t: t, name: method.Name, method: true}, func() string {
+		return m.mapper.MethodName(t, method)
+	})
+}
+
+// MemoizedFieldNameMapper returns a FieldNameMapper that remembers the names returned by mapper for each type
+// and field or method, so mapper runs at most once for each of them. Unlike the cache of a Runtime (see
+// WarmTypeCache) the returned mapper is safe for concurrent use and can be shared between Runtimes. The names
+// must depend only on the type and the field or the method.
+func MemoizedFieldNameMapper(mapper FieldNameMapper) FieldNameMapper {
+	return &memoizedFieldNameMapper{
+		mapper: mapper,
+		names:  make(map[memoizedNameKey]string),
+	}
+}
@@ -299,6 +299,32 @@ func TestGoReflectPtrMethodOnNonPtrValue(t *testing.T) {
 	}
 }
 
+func TestGoReflectPtrMethodOnNonAddressableValue(t *testing.T) {
+	o := testGoReflectMethod_O{field: "a"}
+	vm := New()
+	vm.Set("o", o)
+	vm.Set("m", map[string]testGoReflectMethod_O{"k": o})
+	vm.Set("i", []interface{}{o})
+	res, err := vm.RunString(`
+	o.Set("b");
+	m.k.Set("c");
+	i[0].Set("d");
+	[o.Get(), m.k.Get(), i[0].Get()].join();
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := res.String(); s != "b,a,a" {
+		t.Fatal(s)
+	}
+	if e := vm.Get("o").Export().(testGoReflectMethod_O); e.field != "b" {
+		t.Fatal(e)
+	}
+	if o.field != "a" {
+		t.Fatal(o)
+	}
+}
+
 func TestGoReflectStructField(t *testing.T) {
 	type S struct {
 		F testGoReflectMethod_O
@@ -553,6 +579,102 @@ func TestGoReflectEmbeddedStruct(t *testing.T) {
 	}
 }
 
+func TestGoReflectEmbeddedStructMode(t *testing.T) {
+	type Parent struct {
+		ParentField int
+	}
+	type Child struct {
+		*Parent
+		ChildField int
+	}
+
+	vm := New()
+	vm.SetEmbeddedStructMode(EmbeddedStructPromote)
+	vm.Set("o", &Child{Parent: &Parent{ParentField: 1}, ChildField: 2})
+	res, err := vm.RunString(`Object.keys(o).join() + ";" + o.ParentField`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := res.String(); s != "ParentField,ChildField;1" {
+		t.Fatal(s)
+	}
+	var c Child
+	if err := vm.ExportTo(vm.ToValue(map[string]interface{}{"ParentField": 3, "ChildField": 4}), &c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Parent == nil || c.ParentField != 3 || c.ChildField != 4 {
+		t.Fatal(c)
+	}
+
+	vm.SetEmbeddedStructMode(EmbeddedStructNest)
+	vm.Set("o", &Child{Parent: &Parent{ParentField: 1}, ChildField: 2})
+	res, err = vm.RunString(`Object.keys(o).join() + ";" + o.Parent.ParentField + ";" + o.ParentField`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := res.String(); s != "Parent,ChildField;1;undefined" {
+		t.Fatal(s)
+	}
+	c = Child{}
+	v, err := vm.RunString(`({Parent: {ParentField: 5}, ParentField: 6, ChildField: 7})`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.ExportTo(v, &c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Parent == nil || c.ParentField != 5 || c.ChildField != 7 {
+		t.Fatal(c)
+	}
+}
+
+func TestGoReflectUnexportedEmbeddedStruct(t *testing.T) {
+	type base struct {
+		ID   int
+		name string
+	}
+	type baseRef struct {
+		Ref int
+	}
+	type Item struct {
+		base
+		*baseRef
+		Title string
+	}
+
+	vm := New()
+	item := &Item{base: base{ID: 1, name: "x"}, baseRef: &baseRef{Ref: 2}, Title: "t"}
+	vm.Set("o", item)
+	res, err := vm.RunString(`o.ID = 3; Object.keys(o).join() + ";" + o.ID + ";" + o.name + ";" + o.Ref`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := res.String(); s != "ID,Title;3;undefined;undefined" {
+		t.Fatal(s)
+	}
+	if item.ID != 3 {
+		t.Fatal(item.ID)
+	}
+
+	var it Item
+	if err := vm.ExportTo(vm.ToValue(map[string]interface{}{"ID": 4, "Title": "u", "Ref": 5}), &it); err != nil {
+		t.Fatal(err)
+	}
+	if it.ID != 4 || it.Title != "u" || it.baseRef != nil {
+		t.Fatal(it)
+	}
+
+	vm.SetEmbeddedStructMode(EmbeddedStructNest)
+	vm.Set("o", item)
+	res, err = vm.RunString(`Object.keys(o).join()`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := res.String(); s != "Title" {
+		t.Fatal(s)
+	}
+}
+
 type jsonTagNamer struct{}
 
 func (jsonTagNamer) FieldName(_ reflect.Type, field reflect.StructField) string {
@@ -1031,6 +1153,65 @@ func TestTagFieldNameMapperInvalidId(t *testing.T) {
 	}
 }
 
+type countingFieldNameMapper struct {
+	FieldNameMapper
+	calls int
+}
+
+func (c *countingFieldNameMapper) FieldName(t reflect.Type, f reflect.StructField) string {
+	c.calls++
+	return c.FieldNameMapper.FieldName(t, f)
+}
+
+func TestFieldNameMapperCombinators(t *testing.T) {
+	type S struct {
+		Tagged   int `json:"tagged_field"`
+		Untagged int
+		Hidden   int `json:"-"`
+	}
+
+	vm := New()
+	counter := &countingFieldNameMapper{FieldNameMapper: UncapFieldNameMapper()}
+	vm.SetFieldNameMapper(MemoizedFieldNameMapper(FallbackFieldNameMapper(
+		ChainFieldNameMapper(TagFieldNameMapper("json", true), UncapFieldNameMapper()),
+		counter,
+	)))
+	vm.WarmTypeCache(reflect.TypeOf(&S{}))
+	warm := counter.calls
+	if warm == 0 {
+		t.Fatal("the cache has not been warmed")
+	}
+	vm.Set("s", S{Tagged: 1, Untagged: 2, Hidden: 3})
+	vm.Set("s1", &S{})
+	res, err := vm.RunString(`Object.keys(s).join() + ";" + s.tagged_field + s.untagged + s.hidden`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := res.String(); s != "tagged_field,untagged,hidden;123" {
+		t.Fatal(s)
+	}
+	var s S
+	if err := vm.ExportTo(vm.ToValue(map[string]interface{}{"tagged_field": 4, "untagged": 5}), &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Tagged != 4 || s.Untagged != 5 {
+		t.Fatal(s)
+	}
+	if counter.calls != warm {
+		t.Fatalf("mapper called %d times after warming the cache", counter.calls-warm)
+	}
+
+	vm.SetFieldNameMapper(ChainFieldNameMapper(TagFieldNameMapper("json", false), UncapFieldNameMapper()))
+	vm.Set("s", S{Tagged: 1})
+	res, err = vm.RunString(`Object.keys(s).join()`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := res.String(); s != "tagged_field" {
+		t.Fatal(s)
+	}
+}
+
 func TestPrimitivePtr(t *testing.T) {
 	vm := New()
 	s := "test"
@@ -1559,3 +1740,161 @@ func TestGoReflectFuncWithRuntime(t *testing.T) {
 		t.Fatal(res)
 	}
 }
+
+type testGoReflectAccessors struct {
+	First, Last string
+	count       int
+}
+
+func (p *testGoReflectAccessors) GetFullName() string {
+	return p.First + " " + p.Last
+}
+
+func (p *testGoReflectAccessors) SetFullName(name string) error {
+	parts := strings.Split(name, " ")
+	if len(parts) != 2 {
+		return errors.New("invalid name")
+	}
+	p.First, p.Last = parts[0], parts[1]
+	return nil
+}
+
+func (p *testGoReflectAccessors) GetCount() int {
+	p.count++
+	return p.count
+}
+
+func (p *testGoReflectAccessors) Greet() string {
+	return "Hello, " + p.First
+}
+
+func TestGoReflectAccessorMethods(t *testing.T) {
+	vm := New()
+	vm.SetAccessorMethods(true)
+	vm.SetFieldNameMapper(UncapFieldNameMapper())
+	p := &testGoReflectAccessors{First: "John", Last: "Doe"}
+	vm.Set("p", p)
+	_, err := vm.RunProgram(testLib())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = vm.RunString(`
+	"use strict";
+	assert.sameValue(p.fullName, "John Doe", "getter");
+	p.fullName = "Jane " + p.last;
+	assert.sameValue(p.first, "Jane", "setter");
+	assert.sameValue(p.greet(), "Hello, Jane", "method");
+	assert.sameValue(p.count, 1, "count");
+	assert.sameValue(p.count, 2, "count is computed on every access");
+	assert.sameValue(p.getFullName, undefined, "getFullName");
+	assert.sameValue(p.setFullName, undefined, "setFullName");
+	assert.sameValue(Object.keys(p).join(), "first,last,count,fullName,greet", "keys");
+
+	const desc = Object.getOwnPropertyDescriptor(p, "fullName");
+	assert.sameValue(typeof desc.get, "function", "desc.get");
+	assert.sameValue(typeof desc.set, "function", "desc.set");
+	assert.sameValue(desc.get(), "Jane Doe", "desc.get()");
+	assert.sameValue(Object.getOwnPropertyDescriptor(p, "count").set, undefined, "count setter");
+
+	assert.throws(TypeError, () => { p.count = 1; }, "read-only");
+	try {
+		p.fullName = "Jane";
+		throw new Error("expected an error");
+	} catch (e) {
+		assert.sameValue(e.message, "invalid name", "setter error");
+	}
+	assert.throws(TypeError, () => { delete p.fullName; }, "delete");
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.First != "Jane" || p.Last != "Doe" {
+		t.Fatal(p)
+	}
+
+	vm = New()
+	vm.Set("p", p)
+	res, err := vm.RunString(`typeof p.GetFullName + " " + p.FullName`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := res.String(); s != "function undefined" {
+		t.Fatal(s)
+	}
+}
+
+type testOverloadWriter struct {
+	log []string
+}
+
+func (w *testOverloadWriter) WriteString(s string) {
+	w.log = append(w.log, "string:"+s)
+}
+
+func (w *testOverloadWriter) WriteBytes(b []byte) {
+	w.log = append(w.log, fmt.Sprintf("bytes:%v", b))
+}
+
+func (w *testOverloadWriter) WriteInts(n ...int) {
+	w.log = append(w.log, fmt.Sprintf("ints:%v", n))
+}
+
+type testOverloadMapper struct{}
+
+func (testOverloadMapper) FieldName(_ reflect.Type, f reflect.StructField) string {
+	return f.Name
+}
+
+func (testOverloadMapper) MethodName(_ reflect.Type, m reflect.Method) string {
+	if strings.HasPrefix(m.Name, "Write") {
+		return "write"
+	}
+	return m.Name
+}
+
+func TestGoReflectOverloadResolver(t *testing.T) {
+	vm := New()
+	vm.SetFieldNameMapper(testOverloadMapper{})
+	w := &testOverloadWriter{}
+	vm.Set("w", w)
+	if _, err := vm.RunString(`w.write("a")`); err != nil || len(w.log) != 1 || w.log[0] != "string:a" {
+		t.Fatal("without a resolver the last method is called", w.log, err)
+	}
+
+	vm.SetOverloadResolver(MatchArgumentTypes)
+	w.log = nil
+	res, err := vm.RunString(`
+	w.write("a");
+	w.write(new Uint8Array([1, 2]));
+	w.write(1, 2);
+	w.write();
+	let err;
+	try {
+		w.write({});
+	} catch (e) {
+		err = e;
+	}
+	err instanceof TypeError && Object.keys(w).join();
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := res.String(); s != "write" {
+		t.Fatal(s)
+	}
+	if s := strings.Join(w.log, ","); s != "string:a,bytes:[1 2],ints:[1 2],ints:[]" {
+		t.Fatal(s)
+	}
+
+	vm.SetOverloadResolver(func(candidates []reflect.Type, args []Value) int {
+		return len(candidates) - 1
+	})
+	w.log = nil
+	if _, err := vm.RunString(`w.write(1)`); err != nil {
+		t.Fatal(err)
+	}
+	if s := strings.Join(w.log, ","); s != "string:1" {
+		t.Fatal(s)
+	}
+}
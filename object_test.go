@@ -138,6 +138,39 @@ func TestObjectAssign(t *testing.T) {
 	testScriptWithTestLib(SCRIPT, _undefined, t)
 }
 
+func TestObjectFromEntries(t *testing.T) {
+	const SCRIPT = `
+	var sym = Symbol("s");
+	var o = Object.fromEntries([["a", 1], ["b", 2], [sym, 3], [4, 5]]);
+	assert(compareArray(Object.keys(o), ["4", "a", "b"]), "keys");
+	assert.sameValue(o.a, 1, "a");
+	assert.sameValue(o[sym], 3, "symbol key");
+	assert.sameValue(Object.getPrototypeOf(o), Object.prototype, "prototype");
+
+	assert.sameValue(Object.fromEntries(new Map([["x", 1]])).x, 1, "Map");
+	assert.sameValue(Object.fromEntries(Object.entries({p: "q"})).p, "q", "round trip");
+
+	o = Object.fromEntries([[]]);
+	assert(o.hasOwnProperty("undefined"), "missing key");
+	assert.sameValue(o.undefined, undefined, "missing value");
+
+	assert.throws(TypeError, function() { Object.fromEntries() }, "undefined");
+	assert.throws(TypeError, function() { Object.fromEntries([1]) }, "non-object entry");
+
+	var closed = false;
+	var iterable = {};
+	iterable[Symbol.iterator] = function() {
+		return {
+			next: function() { return {done: false, value: "x"} },
+			return: function() { closed = true; return {} }
+		};
+	};
+	assert.throws(TypeError, function() { Object.fromEntries(iterable) }, "non-object entry from iterator");
+	assert(closed, "iterator is closed");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
 func TestExportCircular(t *testing.T) {
 	vm := New()
 	o := vm.NewObject()
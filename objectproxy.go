@@ -0,0 +1,164 @@
+package goja
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/dop251/goja/unistring"
+)
+
+// ObjectProxy calls the methods of a JavaScript object from Go. It's passed to the factories registered with
+// RegisterInterfaceProxy, which use it to implement Go interfaces with JavaScript objects.
+type ObjectProxy struct {
+	r   *Runtime
+	obj *Object
+}
+
+// InterfaceProxyFunc returns a value implementing an interface type by calling the methods of the object held by
+// p, see RegisterInterfaceProxy.
+type InterfaceProxyFunc func(p *ObjectProxy) interface{}
+
+// RegisterInterfaceProxy makes ExportTo() of an Object into the interface type iface call factory and use the
+// value it returns, which must implement iface. Go can't create method sets at run time, so the factory is
+// expected to return a small adapter which calls the methods of the object through the ObjectProxy, e.g.:
+//
+//	type Plugin interface {
+//		Name() string
+//		Handle(req *Request) (*Response, error)
+//	}
+//
+//	type pluginProxy struct {
+//		p *goja.ObjectProxy
+//	}
+//
+//	func (p pluginProxy) Name() (name string) {
+//		if err := p.p.Call("name", &name); err != nil {
+//			panic(err)
+//		}
+//		return
+//	}
+//
+//	func (p pluginProxy) Handle(req *Request) (res *Response, err error) {
+//		err = p.p.CallAsync("handle", &res, req)
+//		return
+//	}
+//
+//	r.RegisterInterfaceProxy(reflect.TypeOf((*Plugin)(nil)).Elem(), func(p *goja.ObjectProxy) interface{} {
+//		return pluginProxy{p}
+//	})
+//
+// Calling RegisterInterfaceProxy with a nil factory removes the registration. An error is returned if iface is not
+// an interface type with methods.
+func (r *Runtime) RegisterInterfaceProxy(iface reflect.Type, factory InterfaceProxyFunc) error {
+	if iface == nil || iface.Kind() != reflect.Interface || iface.NumMethod() == 0 {
+		return fmt.Errorf("%v is not an interface type with methods", iface)
+	}
+	if factory == nil {
+		delete(r.interfaceProxies, iface)
+		return nil
+	}
+	if r.interfaceProxies == nil {
+		r.interfaceProxies = make(map[reflect.Type]InterfaceProxyFunc)
+	}
+	r.interfaceProxies[iface] = factory
+	return nil
+}
+
+func (r *Runtime) exportToProxy(o *Object, dst reflect.Value, factory InterfaceProxyFunc) error {
+	typ := dst.Type()
+	res := factory(&ObjectProxy{r: r, obj: o})
+	if res == nil {
+		return fmt.Errorf("could not convert %v to %v: the proxy factory returned nil", o, typ)
+	}
+	rv := reflect.ValueOf(res)
+	if !rv.Type().Implements(typ) {
+		return fmt.Errorf("could not convert %v to %v: the proxy factory returned %v", o, typ, rv.Type())
+	}
+	dst.Set(rv)
+	return nil
+}
+
+// Object returns the proxied object.
+func (p *ObjectProxy) Object() *Object {
+	return p.obj
+}
+
+// Runtime returns the Runtime of the proxied object.
+func (p *ObjectProxy) Runtime() *Runtime {
+	return p.r
+}
+
+func (p *ObjectProxy) call(method string, args []interface{}) (Value, error) {
+	r := p.r
+	var fn Callable
+	jsArgs := make([]Value, len(args))
+	if ex := r.vm.try(func() {
+		m := nilSafe(p.obj.self.getStr(unistring.NewFromString(method), nil))
+		var ok bool
+		if fn, ok = AssertFunction(m); !ok {
+			panic(r.NewTypeError("%s is not a function", method))
+		}
+		for i, arg := range args {
+			jsArgs[i] = r.ToValue(arg)
+		}
+	}); ex != nil {
+		return nil, ex
+	}
+	return fn(p.obj, jsArgs...)
+}
+
+func (p *ObjectProxy) exportResult(v Value, result interface{}) error {
+	if result == nil {
+		return nil
+	}
+	return p.r.ExportTo(v, result)
+}
+
+// Call calls the method of the object with the arguments converted by ToValue() and the object as 'this', then
+// exports the value it returns into result (see ExportTo) unless result is nil. An exception thrown by the method
+// is returned as *Exception, except for the GoErrors and the errors mapped with RegisterErrorMapping, which are
+// unwrapped. A returned promise is not awaited, see CallAsync.
+//
+// Like the rest of the Runtime methods, Call is not goroutine-safe.
+func (p *ObjectProxy) Call(method string, result interface{}, args ...interface{}) error {
+	res, err := p.call(method, args)
+	if err != nil {
+		return p.r.unwrapGoError(err)
+	}
+	return p.exportResult(res, result)
+}
+
+// CallAsync is like Call, but it runs the method on the Loop of the Runtime (see NewLoop) and, if the method
+// returns a promise (e.g. it's an async function), waits for it to settle. The fulfillment value is exported into
+// result and the rejection reason is returned as an error. The arguments are converted and the result is exported
+// on the loop goroutine.
+//
+// CallAsync is goroutine-safe, but it blocks until the loop runs the method, so it must not be called from the
+// loop goroutine (e.g. from a native function called by a script) and it only returns while the loop is running.
+// An error is returned if the Runtime has no Loop.
+func (p *ObjectProxy) CallAsync(method string, result interface{}, args ...interface{}) error {
+	l := p.r.loop
+	if l == nil {
+		return errors.New("the Runtime has no Loop")
+	}
+	done := make(chan error, 1)
+	l.post(func() error {
+		r := p.r
+		res, err := p.call(method, args)
+		if err != nil {
+			done <- r.unwrapGoError(err)
+			return nil
+		}
+		l.refs++
+		r.awaitValue(res, func(v Value) {
+			l.refs--
+			done <- p.exportResult(v, result)
+		}, func(reason Value) {
+			l.refs--
+			done <- r.unwrapGoError(&Exception{val: reason})
+		})
+		return nil
+	})
+	return <-done
+}
@@ -0,0 +1,93 @@
+package goja
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testPlugin interface {
+	Name() string
+	Handle(n int) (int, error)
+}
+
+type testPluginProxy struct {
+	p *ObjectProxy
+}
+
+func (p testPluginProxy) Name() (name string) {
+	if err := p.p.Call("name", &name); err != nil {
+		panic(err)
+	}
+	return
+}
+
+func (p testPluginProxy) Handle(n int) (res int, err error) {
+	err = p.p.CallAsync("handle", &res, n)
+	return
+}
+
+func TestInterfaceProxy(t *testing.T) {
+	r := New()
+	typ := reflect.TypeOf((*testPlugin)(nil)).Elem()
+	if err := r.RegisterInterfaceProxy(reflect.TypeOf(0), nil); err == nil {
+		t.Fatal("expected an error")
+	}
+	if err := r.RegisterInterfaceProxy(typ, func(p *ObjectProxy) interface{} {
+		return testPluginProxy{p}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	r.Set("fail", func() error {
+		return errors.New("failed")
+	})
+	l := NewLoop(r)
+
+	var plugin testPlugin
+	err := l.Run(func(r *Runtime) {
+		v, err := r.RunString(`({
+			name() { return "doubler" },
+			async handle(n) {
+				if (n < 0) fail();
+				await null;
+				return n * 2;
+			}
+		})`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := r.ExportTo(v, &plugin); err != nil {
+			t.Fatal(err)
+		}
+		if name := plugin.Name(); name != "doubler" {
+			t.Fatal(name)
+		}
+		go func() {
+			if res, err := plugin.Handle(21); err != nil || res != 42 {
+				t.Errorf("%v, %v", res, err)
+			}
+			if _, err := plugin.Handle(-1); err == nil || err.Error() != "failed" {
+				t.Errorf("unexpected error: %v", err)
+			}
+			l.post(func() error {
+				l.refs--
+				return nil
+			})
+		}()
+		l.refs++
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var res int
+	if err := plugin.(testPluginProxy).p.Call("missing", &res); err == nil {
+		t.Fatal("expected an error")
+	}
+	if err := r.RegisterInterfaceProxy(typ, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.ExportTo(r.NewObject(), &plugin); err == nil {
+		t.Fatal("expected an error")
+	}
+}
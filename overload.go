@@ -0,0 +1,156 @@
+package goja
+
+import (
+	"math"
+	"reflect"
+
+	"github.com/dop251/goja/unistring"
+)
+
+// OverloadResolver picks the Go method to call when several methods of a Go type are mapped to the same JavaScript
+// name by the FieldNameMapper (e.g. WriteString and WriteBytes are both mapped to 'write'). candidates are the types
+// of the methods (without the receiver) in the order of their Go names, args are the arguments of the call. It
+// returns the index of the method to call, or -1 if none of them is suitable, in which case a TypeError is thrown.
+// See SetOverloadResolver.
+type OverloadResolver func(candidates []reflect.Type, args []Value) int
+
+// SetOverloadResolver sets the OverloadResolver used to call the Go methods that share the same JavaScript name.
+// Without a resolver (the default) only the last of these methods is available. For example:
+//
+//	type Writer struct {
+//		bytes.Buffer
+//	}
+//
+//	r.SetFieldNameMapper(writeMapper{}) // maps WriteString and Write to 'write'
+//	r.SetOverloadResolver(goja.MatchArgumentTypes)
+//	r.Set("w", &Writer{})
+//	r.RunString(`w.write("text"); w.write(new Uint8Array([1, 2]))`)
+//
+// The resolver is called on every call, so it applies to the values that have already been converted.
+func (r *Runtime) SetOverloadResolver(resolver OverloadResolver) {
+	r.overloadResolver = resolver
+}
+
+// MatchArgumentTypes is an OverloadResolver that picks the first candidate which takes the number of the arguments
+// (the parameters of a variadic function may take more) and whose parameters accept the corresponding arguments:
+//
+//   - interface{} and Value parameters accept any value;
+//   - numeric parameters accept Numbers and BigInts, the integer ones only accept the integral Numbers;
+//   - string and bool parameters accept Strings and Booleans respectively;
+//   - []byte parameters accept Uint8Arrays and the other slice parameters accept Arrays;
+//   - func parameters accept functions, map and struct parameters accept the other objects;
+//   - pointer, interface, slice, map and func parameters accept null and undefined;
+//   - any parameter accepts a value which exports (see Value.ExportType) to a type assignable to it.
+//
+// A leading context.Context parameter is not matched against the arguments, see ToValue.
+func MatchArgumentTypes(candidates []reflect.Type, args []Value) int {
+	for i, typ := range candidates {
+		if argumentsMatch(typ, args) {
+			return i
+		}
+	}
+	return -1
+}
+
+func argumentsMatch(typ reflect.Type, args []Value) bool {
+	nargs := typ.NumIn()
+	first := 0
+	if nargs > 0 && typ.In(0) == typeContext && !(nargs == 1 && typ.IsVariadic()) {
+		first = 1
+	}
+	n := nargs - first
+	if typ.IsVariadic() {
+		if len(args) < n-1 {
+			return false
+		}
+	} else if len(args) != n {
+		return false
+	}
+	for i, arg := range args {
+		var t reflect.Type
+		if typ.IsVariadic() && i+first >= nargs-1 {
+			t = typ.In(nargs - 1).Elem()
+		} else {
+			t = typ.In(i + first)
+		}
+		if !argumentMatches(arg, t) {
+			return false
+		}
+	}
+	return true
+}
+
+func argumentMatches(v Value, t reflect.Type) bool {
+	kind := t.Kind()
+	if t == typeValue || kind == reflect.Interface && t.NumMethod() == 0 {
+		return true
+	}
+	if et := v.ExportType(); et != nil && et != reflectTypeNil && et.AssignableTo(t) {
+		return true
+	}
+	switch v := v.(type) {
+	case valueInt, valueFloat:
+		switch kind {
+		case reflect.Float32, reflect.Float64:
+			return true
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			f := v.ToFloat()
+			return f == math.Trunc(f) && !math.IsInf(f, 0)
+		}
+	case *valueBigInt:
+		switch kind {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			return true
+		}
+	case valueString:
+		return kind == reflect.String
+	case valueBool:
+		return kind == reflect.Bool
+	case valueNull, valueUndefined:
+		switch kind {
+		case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map, reflect.Func:
+			return true
+		}
+	case *Object:
+		switch kind {
+		case reflect.Func:
+			_, ok := AssertFunction(v)
+			return ok
+		case reflect.Slice:
+			if t.Elem().Kind() == reflect.Uint8 {
+				_, ok, _ := v.runtime.uint8ArrayBytes(v)
+				return ok
+			}
+			return isArray(v)
+		case reflect.Map, reflect.Struct:
+			_, isFunc := AssertFunction(v)
+			return !isFunc && !isArray(v)
+		}
+	}
+	return false
+}
+
+// overloadedMethod returns a function that calls one of the methods with the given indexes chosen by the
+// OverloadResolver of the Runtime.
+func (o *objectGoReflect) overloadedMethod(name string, indexes []int) Value {
+	r := o.val.runtime
+	methods := make([]reflect.Value, len(indexes))
+	types := make([]reflect.Type, len(indexes))
+	for i, idx := range indexes {
+		methods[i] = o.methodsValue.Method(idx)
+		types[i] = methods[i].Type()
+	}
+	return r.newNativeFunc(func(call FunctionCall) Value {
+		idx := -1
+		if resolver := r.overloadResolver; resolver != nil {
+			idx = resolver(types, call.Arguments)
+		}
+		if idx < 0 || idx >= len(methods) {
+			panic(r.NewTypeError("No overload of %s matches the arguments", name))
+		}
+		return r.wrapReflectFunc(methods[idx], nil)(call)
+	}, nil, unistring.NewFromString(name), nil, 0)
+}
@@ -95,6 +95,13 @@ func (self *_parser) parsePrimaryExpression() ast.Expression {
 		return self.parseFunction(false, false, idx)
 	case token.CLASS:
 		return self.parseClass(false)
+	case token.IMPORT:
+		switch self.peek() {
+		case token.LEFT_PARENTHESIS:
+			return self.parseImportCall()
+		case token.PERIOD:
+			return self.parseImportMeta()
+		}
 	}
 
 	if self.isBindingId(self.token) {
@@ -110,6 +117,47 @@ func (self *_parser) parsePrimaryExpression() ast.Expression {
 	return &ast.BadExpression{From: idx, To: self.idx}
 }
 
+func (self *_parser) parseImportCall() ast.Expression {
+	idx := self.expect(token.IMPORT)
+	self.expect(token.LEFT_PARENTHESIS)
+	node := &ast.ImportCall{
+		Import:   idx,
+		Argument: self.parseAssignmentExpression(),
+	}
+	if self.token == token.COMMA {
+		self.next()
+		if self.token != token.RIGHT_PARENTHESIS {
+			node.Options = self.parseAssignmentExpression()
+			if self.token == token.COMMA {
+				self.next()
+			}
+		}
+	}
+	node.RightParenthesis = self.expect(token.RIGHT_PARENTHESIS)
+	return node
+}
+
+func (self *_parser) parseImportMeta() ast.Expression {
+	idx := self.expect(token.IMPORT)
+	self.expect(token.PERIOD)
+	if self.token != token.IDENTIFIER || self.literal != "meta" {
+		self.errorUnexpectedToken(self.token)
+		self.nextStatement()
+		return &ast.BadExpression{From: idx, To: self.idx}
+	}
+	if !self.module {
+		self.error(idx, "Cannot use 'import.meta' outside a module")
+	}
+	return &ast.MetaProperty{
+		Meta: &ast.Identifier{
+			Name: unistring.String(token.IMPORT.String()),
+			Idx:  idx,
+		},
+		Property: self.parseIdentifier(),
+		Idx:      idx,
+	}
+}
+
 func (self *_parser) parseSuperProperty() ast.Expression {
 	idx := self.idx
 	self.next()
@@ -682,11 +730,21 @@ func (self *_parser) parseNewExpression() ast.Expression {
 		}
 		self.errorUnexpectedToken(token.IDENTIFIER)
 	}
+	if self.token == token.IMPORT && self.peek() == token.LEFT_PARENTHESIS {
+		self.errorUnexpectedToken(token.IMPORT)
+		self.nextStatement()
+		return &ast.BadExpression{From: idx, To: self.idx}
+	}
 	callee := self.parseLeftHandSideExpression()
 	if bad, ok := callee.(*ast.BadExpression); ok {
 		bad.From = idx
 		return bad
 	}
+	if self.token == token.QUESTION_DOT {
+		self.error(self.idx, "Invalid optional chain from new expression")
+		self.nextStatement()
+		return &ast.BadExpression{From: idx, To: self.idx}
+	}
 	node := &ast.NewExpression{
 		New:    idx,
 		Callee: callee,
@@ -1250,6 +1308,12 @@ func (self *_parser) parseAssignmentExpression() ast.Expression {
 		operator = token.SHIFT_RIGHT
 	case token.UNSIGNED_SHIFT_RIGHT_ASSIGN:
 		operator = token.UNSIGNED_SHIFT_RIGHT
+	case token.LOGICAL_AND_ASSIGN:
+		operator = token.LOGICAL_AND
+	case token.LOGICAL_OR_ASSIGN:
+		operator = token.LOGICAL_OR
+	case token.COALESCE_ASSIGN:
+		operator = token.COALESCE
 	case token.ARROW:
 		var paramList *ast.ParameterList
 		if id, ok := left.(*ast.Identifier); ok {
@@ -3,6 +3,7 @@ package parser
 import (
 	"errors"
 	"fmt"
+	"math/big"
 	"strconv"
 	"strings"
 	"unicode"
@@ -406,9 +407,9 @@ func (self *_parser) scan() (tkn token.Token, literal string, parsedLiteral unis
 					tkn = token.STRICT_NOT_EQUAL
 				}
 			case '&':
-				tkn = self.switch3(token.AND, token.AND_ASSIGN, '&', token.LOGICAL_AND)
+				tkn = self.switch4(token.AND, token.AND_ASSIGN, '&', token.LOGICAL_AND, token.LOGICAL_AND_ASSIGN)
 			case '|':
-				tkn = self.switch3(token.OR, token.OR_ASSIGN, '|', token.LOGICAL_OR)
+				tkn = self.switch4(token.OR, token.OR_ASSIGN, '|', token.LOGICAL_OR, token.LOGICAL_OR_ASSIGN)
 			case '~':
 				tkn = token.BITWISE_NOT
 			case '?':
@@ -417,7 +418,7 @@ func (self *_parser) scan() (tkn token.Token, literal string, parsedLiteral unis
 					tkn = token.QUESTION_DOT
 				} else if self.chr == '?' {
 					self.read()
-					tkn = token.COALESCE
+					tkn = self.switch2(token.COALESCE, token.COALESCE_ASSIGN)
 				} else {
 					tkn = token.QUESTION_MARK
 				}
@@ -890,6 +891,12 @@ func hex2decimal(chr byte) (value rune, ok bool) {
 }
 
 func parseNumberLiteral(literal string) (value interface{}, err error) {
+	if l := len(literal); l > 1 && literal[l-1] == 'n' {
+		if b, ok := new(big.Int).SetString(literal[:l-1], 0); ok {
+			return b, nil
+		}
+		return nil, errors.New("Illegal numeric literal")
+	}
 	// TODO Is Uint okay? What about -MAX_UINT
 	value, err = strconv.ParseInt(literal, 0, 64)
 	if err == nil {
@@ -1117,6 +1124,7 @@ func (self *_parser) scanNumericLiteral(decimalPoint bool) (token.Token, string)
 
 	offset := self.chrOffset
 	tkn := token.NUMBER
+	bigIntAllowed := false
 
 	if decimalPoint {
 		offset--
@@ -1134,6 +1142,9 @@ func (self *_parser) scanNumericLiteral(decimalPoint bool) (token.Token, string)
 				base = 2
 			case '.', 'e', 'E':
 				// no-op
+			case 'n':
+				bigIntAllowed = true
+				goto end
 			default:
 				// legacy octal
 				self.scanMantissa(8)
@@ -1145,10 +1156,15 @@ func (self *_parser) scanNumericLiteral(decimalPoint bool) (token.Token, string)
 					return token.ILLEGAL, self.str[offset:self.chrOffset]
 				}
 				self.scanMantissa(base)
+				bigIntAllowed = true
 				goto end
 			}
 		} else {
 			self.scanMantissa(10)
+			if self.chr == 'n' {
+				bigIntAllowed = true
+				goto end
+			}
 		}
 		if self.chr == '.' {
 			self.read()
@@ -1169,6 +1185,9 @@ func (self *_parser) scanNumericLiteral(decimalPoint bool) (token.Token, string)
 		}
 	}
 end:
+	if bigIntAllowed && self.chr == 'n' {
+		self.read()
+	}
 	if isIdentifierStart(self.chr) || isDecimalDigit(self.chr) {
 		return token.ILLEGAL, self.str[offset:self.chrOffset]
 	}
@@ -363,8 +363,38 @@ Second line \
 			token.IDENTIFIER, "b", 5,
 		)
 
+		test("a &&= b ||= c ??= d",
+			token.IDENTIFIER, "a", 1,
+			token.LOGICAL_AND_ASSIGN, "", 3,
+			token.IDENTIFIER, "b", 7,
+			token.LOGICAL_OR_ASSIGN, "", 9,
+			token.IDENTIFIER, "c", 13,
+			token.COALESCE_ASSIGN, "", 15,
+			token.IDENTIFIER, "d", 19,
+			token.EOF, "", 20,
+		)
+
+		test("10n 0x1Fn 0n",
+			token.NUMBER, "10n", 1,
+			token.NUMBER, "0x1Fn", 5,
+			token.NUMBER, "0n", 11,
+			token.EOF, "", 13,
+		)
+
 		// ILLEGAL
 
+		test(`1.5n`,
+			token.ILLEGAL, "1.5", 1,
+			token.IDENTIFIER, "n", 4,
+			token.EOF, "", 5,
+		)
+
+		test(`01n`,
+			token.ILLEGAL, "01", 1,
+			token.IDENTIFIER, "n", 3,
+			token.EOF, "", 4,
+		)
+
 		test(`3ea`,
 			token.ILLEGAL, "3e", 1,
 			token.IDENTIFIER, "a", 3,
@@ -103,8 +103,9 @@ type _parser struct {
 		count int
 	}
 
-	mode Mode
-	opts options
+	mode   Mode
+	opts   options
+	module bool // parsing an ECMAScript module
 
 	file *file.File
 }
@@ -163,6 +164,16 @@ func ReadSource(filename string, src interface{}) ([]byte, error) {
 //	// Parse some JavaScript, yielding a *ast.Program and/or an ErrorList
 //	program, err := parser.ParseFile(nil, "", `if (abc > 1) {}`, 0)
 func ParseFile(fileSet *file.FileSet, filename string, src interface{}, mode Mode, options ...Option) (*ast.Program, error) {
+	return parseFile(fileSet, filename, src, mode, false, options...)
+}
+
+// ParseModule is like ParseFile, but parses the source as an ECMAScript module, i.e. import and export
+// declarations are allowed at the top level.
+func ParseModule(fileSet *file.FileSet, filename string, src interface{}, mode Mode, options ...Option) (*ast.Program, error) {
+	return parseFile(fileSet, filename, src, mode, true, options...)
+}
+
+func parseFile(fileSet *file.FileSet, filename string, src interface{}, mode Mode, module bool, options ...Option) (*ast.Program, error) {
 	str, err := ReadSource(filename, src)
 	if err != nil {
 		return nil, err
@@ -177,6 +188,7 @@ func ParseFile(fileSet *file.FileSet, filename string, src interface{}, mode Mod
 
 		parser := _newParser(filename, str, base, options...)
 		parser.mode = mode
+		parser.module = module
 		return parser.parse()
 	}
 }
@@ -55,6 +55,73 @@ func TestParseFile(t *testing.T) {
 	})
 }
 
+func TestParseModule(t *testing.T) {
+	tt(t, func() {
+		test := func(src string, expect interface{}) *ast.Program {
+			program, err := ParseModule(nil, "", src, 0)
+			is(firstErr(err), expect)
+			return program
+		}
+
+		program := test(`import a, { b, c as d, "e" as f } from "m"; export { a as default, d as "g" };`, nil)
+		{
+			decl := program.Body[0].(*ast.ImportDeclaration)
+			is(decl.DefaultBinding.Name, "a")
+			is(len(decl.NamedImports), 3)
+			is(decl.NamedImports[1].ImportName.Value, "c")
+			is(decl.NamedImports[1].Alias.Name, "d")
+			is(decl.NamedImports[2].Alias.Name, "f")
+			is(decl.ModuleSpecifier.Value, "m")
+			exp := program.Body[1].(*ast.ExportDeclaration)
+			is(len(exp.Specifiers), 2)
+			is(exp.Specifiers[0].ExportName.Value, "default")
+			is(exp.Specifiers[1].ExportName.Value, "g")
+		}
+
+		test(`import * as ns from "m"; import "n";`, nil)
+		test(`export * from "m"; export * as ns from "m"; export { x as y } from "m";`, nil)
+		test(`export var a = 1; export let b; export const c = 2; export function f() {} export class C {}`, nil)
+		test(`export default function () {}`, nil)
+		test(`export default class {}`, nil)
+		test(`export default async function () {}`, nil)
+		test(`export default 1 + 2;`, nil)
+		test(`import("m").then(); import.meta.url;`, nil)
+
+		program = test(`import data from "d.json" with { type: "json", "x-y": "z" }; import "m" with {};`, nil)
+		{
+			decl := program.Body[0].(*ast.ImportDeclaration)
+			is(len(decl.With.Attributes), 2)
+			is(decl.With.Attributes[0].Key.Value, "type")
+			is(decl.With.Attributes[0].Value.Value, "json")
+			is(decl.With.Attributes[1].Key.Value, "x-y")
+			is(len(program.Body[1].(*ast.ImportDeclaration).With.Attributes), 0)
+		}
+		test(`export * from "m" with { type: "json" }; export { x } from "m" with { type: "json", };`, nil)
+		program = test(`import("m", { with: { type: "json" } }); import("n",);`, nil)
+		{
+			call := program.Body[0].(*ast.ExpressionStatement).Expression.(*ast.ImportCall)
+			is(call.Options != nil, true)
+			call = program.Body[1].(*ast.ExpressionStatement).Expression.(*ast.ImportCall)
+			is(call.Options, nil)
+		}
+
+		test(`import { "e" } from "m";`, "(anonymous): Line 1:10 Unexpected token \"e\"")
+		test(`export { "e" };`, "(anonymous): Line 1:10 Unexpected string")
+		test(`import a from;`, "(anonymous): Line 1:14 Unexpected token ;")
+		test(`export let;`, "(anonymous): Line 1:11 Unexpected token ;")
+
+		test(`import.foo`, "(anonymous): Line 1:8 Unexpected identifier")
+		test(`import a from "m" with { type: json };`, "(anonymous): Line 1:32 Unexpected identifier")
+		test(`export { a } with { type: "json" };`, "(anonymous): Line 1:14 Unexpected token with")
+
+		_, err := ParseFile(nil, "", `import a from "m";`, 0)
+		is(err, "(anonymous): Line 1:1 Unexpected reserved word")
+
+		_, err = ParseFile(nil, "", `import.meta`, 0)
+		is(err, "(anonymous): Line 1:1 Cannot use 'import.meta' outside a module")
+	})
+}
+
 func TestParseFunction(t *testing.T) {
 	tt(t, func() {
 		test := func(prm, bdy string, expect interface{}) *ast.FunctionLiteral {
@@ -169,6 +236,20 @@ func TestParserErr(t *testing.T) {
 
 		test("--1", "(anonymous): Line 1:1 Invalid left-hand side in assignment")
 
+		test("a?.b = 1", "(anonymous): Line 1:1 Invalid left-hand side in assignment")
+
+		test("new a?.b()", "(anonymous): Line 1:6 Invalid optional chain from new expression")
+
+		test("[a] &&= b", "(anonymous): Line 1:1 Invalid left-hand side in assignment")
+
+		test("class A { static { await } }", "(anonymous): Line 1:26 Unexpected token await")
+
+		test("l: { class A { static { break l; } } }", "(anonymous): Line 1:25 Undefined label 'l'")
+
+		test("a?.b ??= c", "(anonymous): Line 1:1 Invalid left-hand side in assignment")
+
+		test("a?.b`c`", "(anonymous): Line 1:5 Invalid template literal on optional chain")
+
 		test("for((1 + 1) in abc) def();", "(anonymous): Line 1:1 Invalid left-hand side in for-in or for-of")
 
 		test("[", "(anonymous): Line 1:2 Unexpected end of input")
@@ -917,6 +998,13 @@ func TestParser(t *testing.T) {
 		is(len(program.Body), 1)
 		is(program.Body[0].(*ast.ExpressionStatement).Expression.(*ast.BinaryExpression).Right.(*ast.Identifier).Name, "c")
 
+		program = test(`a.b ??= c ||= d`, nil)
+		{
+			assign := program.Body[0].(*ast.ExpressionStatement).Expression.(*ast.AssignExpression)
+			is(assign.Operator, token.COALESCE)
+			is(assign.Right.(*ast.AssignExpression).Operator, token.LOGICAL_OR)
+		}
+
 		program = test(`
 		class C {
 			a
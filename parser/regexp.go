@@ -456,3 +456,154 @@ func (self *_RegExp_parser) error(fatal bool, msg string, msgValues ...interface
 	self.offset = self.length
 	self.chr = -1
 }
+
+// ExtractNamedGroups rewrites named capturing groups ((?<name>...)) and named backreferences (\k<name>)
+// in a JavaScript pattern into their unnamed equivalents so that the result can be passed to
+// TransformRegExp. It returns the rewritten pattern and the names of all capturing groups in order
+// ("" for unnamed ones). If the pattern does not contain any named groups it is returned unchanged
+// and names is nil.
+func ExtractNamedGroups(pattern string) (transformed string, names []string, err error) {
+	hasNamed := false
+	inClass := false
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '(':
+			if inClass {
+				break
+			}
+			if i+1 < len(pattern) && pattern[i+1] == '?' {
+				if i+2 < len(pattern) && pattern[i+2] == '<' && i+3 < len(pattern) && pattern[i+3] != '=' && pattern[i+3] != '!' {
+					name, end := scanGroupName(pattern, i+3)
+					if end == -1 {
+						return "", nil, RegexpSyntaxError{regexpParseError{offset: i, err: "Invalid capture group name"}}
+					}
+					for _, n := range names {
+						if n == name {
+							return "", nil, RegexpSyntaxError{regexpParseError{offset: i, err: "Duplicate capture group name"}}
+						}
+					}
+					names = append(names, name)
+					hasNamed = true
+					i = end
+				}
+				break
+			}
+			names = append(names, "")
+		}
+	}
+	if !hasNamed {
+		return pattern, nil, nil
+	}
+
+	var b strings.Builder
+	b.Grow(len(pattern))
+	inClass = false
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '\\':
+			if i+1 < len(pattern) && pattern[i+1] == 'k' && !inClass {
+				if i+2 >= len(pattern) || pattern[i+2] != '<' {
+					return "", nil, RegexpSyntaxError{regexpParseError{offset: i, err: "Invalid named reference"}}
+				}
+				name, end := scanGroupName(pattern, i+3)
+				idx := -1
+				if end != -1 {
+					for n, groupName := range names {
+						if groupName == name {
+							idx = n + 1
+							break
+						}
+					}
+				}
+				if idx == -1 {
+					return "", nil, RegexpSyntaxError{regexpParseError{offset: i, err: "Invalid named capture referenced"}}
+				}
+				b.WriteString(`(?:\`)
+				b.WriteString(strconv.Itoa(idx))
+				b.WriteByte(')')
+				i = end
+				continue
+			}
+			b.WriteByte(c)
+			if i+1 < len(pattern) {
+				i++
+				b.WriteByte(pattern[i])
+			}
+			continue
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '(':
+			if !inClass && strings.HasPrefix(pattern[i:], "(?<") && i+3 < len(pattern) && pattern[i+3] != '=' && pattern[i+3] != '!' {
+				_, end := scanGroupName(pattern, i+3)
+				b.WriteByte('(')
+				i = end
+				continue
+			}
+		}
+		b.WriteByte(c)
+	}
+	return b.String(), names, nil
+}
+
+// scanGroupName reads a group name starting at pos up to the closing '>'. It returns the name and the
+// position of the '>', or -1 if the name is not a valid identifier.
+func scanGroupName(pattern string, pos int) (string, int) {
+	end := strings.IndexByte(pattern[pos:], '>')
+	if end <= 0 {
+		return "", -1
+	}
+	name := pattern[pos : pos+end]
+	for i, r := range name {
+		if i == 0 {
+			if !isIdentifierStart(r) {
+				return "", -1
+			}
+		} else if !isIdentifierPart(r) {
+			return "", -1
+		}
+	}
+	return name, pos + end
+}
+
+// ExpandDotAll replaces every '.' outside of character classes in a JavaScript pattern with "[^]",
+// so that it matches any character including line terminators (the 's' flag).
+func ExpandDotAll(pattern string) string {
+	if strings.IndexByte(pattern, '.') == -1 {
+		return pattern
+	}
+	var b strings.Builder
+	b.Grow(len(pattern) + 8)
+	inClass := false
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '\\':
+			b.WriteByte(c)
+			if i+1 < len(pattern) {
+				i++
+				b.WriteByte(pattern[i])
+			}
+			continue
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '.':
+			if !inClass {
+				b.WriteString("[^]")
+				continue
+			}
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
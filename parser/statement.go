@@ -73,6 +73,14 @@ func (self *_parser) parseStatement() ast.Statement {
 		self.insertSemicolon = true
 	case token.CONST:
 		return self.parseLexicalDeclaration(self.token)
+	case token.IDENTIFIER:
+		if self.literal == "using" && self.scope.allowLet && self.isUsingDeclaration(false, false) {
+			return self.parseUsingDeclaration(false)
+		}
+	case token.AWAIT:
+		if self.scope.allowLet && self.scope.allowAwait && self.scope.inAsync && self.isUsingDeclaration(true, false) {
+			return self.parseUsingDeclaration(true)
+		}
 	case token.ASYNC:
 		if f := self.parseMaybeAsyncFunction(true); f != nil {
 			return &ast.FunctionDeclaration{
@@ -625,6 +633,14 @@ func (self *_parser) parseFor(idx file.Idx, initializer ast.ForLoopInitializer)
 
 func (self *_parser) parseForOrForInStatement() ast.Statement {
 	idx := self.expect(token.FOR)
+	await := false
+	if self.token == token.AWAIT {
+		if !self.scope.allowAwait || !self.scope.inAsync {
+			self.errorUnexpectedToken(token.AWAIT)
+		}
+		self.next()
+		await = true
+	}
 	self.expect(token.LEFT_PARENTHESIS)
 
 	var initializer ast.ForLoopInitializer
@@ -637,15 +653,29 @@ func (self *_parser) parseForOrForInStatement() ast.Statement {
 		allowIn := self.scope.allowIn
 		self.scope.allowIn = false
 		tok := self.token
-		if tok == token.LET {
+		usingAwait := false
+		switch {
+		case tok == token.LET:
 			switch self.peek() {
 			case token.IDENTIFIER, token.LEFT_BRACKET, token.LEFT_BRACE:
 			default:
 				tok = token.IDENTIFIER
 			}
+		case tok == token.IDENTIFIER && self.literal == "using":
+			if self.isUsingDeclaration(false, true) {
+				tok = token.USING
+			}
+		case tok == token.AWAIT:
+			if self.scope.allowAwait && self.scope.inAsync && self.isUsingDeclaration(true, true) {
+				tok = token.USING
+				usingAwait = true
+			}
 		}
-		if tok == token.VAR || tok == token.LET || tok == token.CONST {
+		if tok == token.VAR || tok == token.LET || tok == token.CONST || tok == token.USING {
 			idx := self.idx
+			if usingAwait {
+				self.next()
+			}
 			self.next()
 			var list []*ast.Binding
 			if tok == token.VAR {
@@ -662,6 +692,12 @@ func (self *_parser) parseForOrForInStatement() ast.Statement {
 					forOf = true
 				}
 			}
+			if tok == token.USING {
+				if forIn {
+					self.error(idx, "'using' declarations are not allowed in for-in loops")
+				}
+				self.checkUsingDeclarationList(list, !forOf)
+			}
 			if forIn || forOf {
 				if list[0].Initializer != nil {
 					self.error(list[0].Initializer.Idx0(), "for-in loop variable declaration may not have an initializer")
@@ -672,9 +708,11 @@ func (self *_parser) parseForOrForInStatement() ast.Statement {
 					}
 				} else {
 					into = &ast.ForDeclaration{
-						Idx:     idx,
-						IsConst: tok == token.CONST,
-						Target:  list[0].Target,
+						Idx:          idx,
+						IsConst:      tok == token.CONST || tok == token.USING,
+						IsUsing:      tok == token.USING,
+						IsAwaitUsing: usingAwait,
+						Target:       list[0].Target,
 					}
 				}
 			} else {
@@ -688,6 +726,7 @@ func (self *_parser) parseForOrForInStatement() ast.Statement {
 						LexicalDeclaration: ast.LexicalDeclaration{
 							Idx:   idx,
 							Token: tok,
+							Await: usingAwait,
 							List:  list,
 						},
 					}
@@ -727,11 +766,18 @@ func (self *_parser) parseForOrForInStatement() ast.Statement {
 		self.scope.allowIn = allowIn
 	}
 
+	if await && !forOf {
+		self.error(idx, "for await can only be used with for-of loops")
+		self.nextStatement()
+		return &ast.BadStatement{From: idx, To: self.idx}
+	}
 	if forIn {
 		return self.parseForIn(idx, into)
 	}
 	if forOf {
-		return self.parseForOf(idx, into)
+		stmt := self.parseForOf(idx, into)
+		stmt.Await = await
+		return stmt
 	}
 
 	self.expect(token.SEMICOLON)
@@ -780,6 +826,63 @@ func (self *_parser) parseLexicalDeclaration(tok token.Token) *ast.LexicalDeclar
 	}
 }
 
+// isUsingDeclaration returns true if the current token starts a 'using' declaration (or an 'await using' one if
+// await is set), i.e. it is followed by a binding identifier with no line terminator in between.
+func (self *_parser) isUsingDeclaration(await, forHead bool) bool {
+	idx := self.idx
+	state := self.mark(nil)
+	defer func() {
+		self.restore(state)
+		self.idx = idx
+	}()
+	if await {
+		end := self.idx + file.Idx(len(self.literal))
+		self.next()
+		if self.token != token.IDENTIFIER || self.literal != "using" || self.hasLineTerminator(end, self.idx) {
+			return false
+		}
+	}
+	end := self.idx + file.Idx(len(self.literal))
+	self.next()
+	if self.token != token.IDENTIFIER && !token.IsUnreservedWord(self.token) || self.hasLineTerminator(end, self.idx) {
+		return false
+	}
+	// 'for (using of ...' is a for-of loop over a variable named 'using'
+	return !forHead || self.token != token.IDENTIFIER || self.literal != "of"
+}
+
+func (self *_parser) hasLineTerminator(from, to file.Idx) bool {
+	return strings.ContainsAny(self.str[int(from)-self.base:int(to)-self.base], "\n\r\u2028\u2029")
+}
+
+func (self *_parser) parseUsingDeclaration(await bool) *ast.LexicalDeclaration {
+	idx := self.idx
+	if await {
+		self.next()
+	}
+	self.next()
+	list := self.parseVariableDeclarationList()
+	self.checkUsingDeclarationList(list, true)
+	self.semicolon()
+
+	return &ast.LexicalDeclaration{
+		Idx:   idx,
+		Token: token.USING,
+		Await: await,
+		List:  list,
+	}
+}
+
+func (self *_parser) checkUsingDeclarationList(list []*ast.Binding, needInit bool) {
+	for _, item := range list {
+		if _, ok := item.Target.(*ast.Identifier); !ok {
+			self.error(item.Target.Idx0(), "'using' declarations may not have binding patterns")
+		} else if needInit && item.Initializer == nil {
+			self.error(item.Idx1(), "Missing initializer in 'using' declaration")
+		}
+	}
+}
+
 func (self *_parser) parseDoWhileStatement() ast.Statement {
 	inIteration := self.scope.inIteration
 	self.scope.inIteration = true
@@ -846,12 +949,277 @@ func (self *_parser) parseIfStatement() ast.Statement {
 func (self *_parser) parseSourceElements() (body []ast.Statement) {
 	for self.token != token.EOF {
 		self.scope.allowLet = true
+		if self.module {
+			switch self.token {
+			case token.IMPORT:
+				if tok := self.peek(); tok != token.LEFT_PARENTHESIS && tok != token.PERIOD {
+					body = append(body, self.parseImportDeclaration())
+					continue
+				}
+			case token.EXPORT:
+				body = append(body, self.parseExportDeclaration())
+				continue
+			}
+		}
 		body = append(body, self.parseStatement())
 	}
 
 	return body
 }
 
+func (self *_parser) parseModuleSpecifier() *ast.StringLiteral {
+	if self.token != token.STRING {
+		self.errorUnexpectedToken(self.token)
+		self.nextStatement()
+		return &ast.StringLiteral{Idx: self.idx}
+	}
+	node := &ast.StringLiteral{
+		Idx:     self.idx,
+		Literal: self.literal,
+		Value:   self.parsedLiteral,
+	}
+	self.next()
+	return node
+}
+
+// parseModuleExportName parses an IdentifierName or a string literal.
+func (self *_parser) parseModuleExportName() *ast.StringLiteral {
+	if self.token != token.STRING && !token.IsId(self.token) {
+		self.errorUnexpectedToken(self.token)
+		self.nextStatement()
+		return &ast.StringLiteral{Idx: self.idx}
+	}
+	node := &ast.StringLiteral{
+		Idx:     self.idx,
+		Literal: self.literal,
+		Value:   self.parsedLiteral,
+	}
+	self.next()
+	return node
+}
+
+func (self *_parser) parseImportedBinding() *ast.Identifier {
+	self.tokenToBindingId()
+	if self.token != token.IDENTIFIER {
+		self.errorUnexpectedToken(self.token)
+		self.nextStatement()
+		return &ast.Identifier{Idx: self.idx}
+	}
+	return self.parseIdentifier()
+}
+
+func (self *_parser) expectContextual(name string) {
+	if self.token != token.IDENTIFIER || self.literal != name {
+		self.errorUnexpectedToken(self.token)
+		self.nextStatement()
+		return
+	}
+	self.next()
+}
+
+func (self *_parser) parseFromClause() *ast.StringLiteral {
+	self.expectContextual("from")
+	return self.parseModuleSpecifier()
+}
+
+// parseWithClause parses an optional import attributes clause: with { type: "json" }.
+func (self *_parser) parseWithClause() *ast.WithClause {
+	if self.token != token.WITH {
+		return nil
+	}
+	node := &ast.WithClause{
+		With: self.expect(token.WITH),
+	}
+	self.expect(token.LEFT_BRACE)
+	node.Attributes = []*ast.ImportAttribute{}
+	for self.token != token.RIGHT_BRACE && self.token != token.EOF {
+		attr := &ast.ImportAttribute{
+			Key: self.parseModuleExportName(),
+		}
+		self.expect(token.COLON)
+		attr.Value = self.parseModuleSpecifier()
+		node.Attributes = append(node.Attributes, attr)
+		if self.token != token.RIGHT_BRACE {
+			self.expect(token.COMMA)
+		}
+	}
+	node.RightBrace = self.expect(token.RIGHT_BRACE)
+	return node
+}
+
+func (self *_parser) parseImportDeclaration() *ast.ImportDeclaration {
+	node := &ast.ImportDeclaration{
+		Import: self.expect(token.IMPORT),
+	}
+
+	if self.token == token.STRING {
+		node.ModuleSpecifier = self.parseModuleSpecifier()
+		node.With = self.parseWithClause()
+		self.semicolon()
+		return node
+	}
+
+	namedOrNamespace := true
+	if self.token != token.MULTIPLY && self.token != token.LEFT_BRACE {
+		node.DefaultBinding = self.parseImportedBinding()
+		if self.token == token.COMMA {
+			self.next()
+		} else {
+			namedOrNamespace = false
+		}
+	}
+
+	if namedOrNamespace {
+		switch self.token {
+		case token.MULTIPLY:
+			self.next()
+			self.expectContextual("as")
+			node.NamespaceBinding = self.parseImportedBinding()
+		case token.LEFT_BRACE:
+			self.next()
+			node.NamedImports = []*ast.ImportSpecifier{}
+			for self.token != token.RIGHT_BRACE && self.token != token.EOF {
+				node.NamedImports = append(node.NamedImports, self.parseImportSpecifier())
+				if self.token != token.RIGHT_BRACE {
+					self.expect(token.COMMA)
+				}
+			}
+			self.expect(token.RIGHT_BRACE)
+		default:
+			self.errorUnexpectedToken(self.token)
+			self.nextStatement()
+		}
+	}
+
+	node.ModuleSpecifier = self.parseFromClause()
+	node.With = self.parseWithClause()
+	self.semicolon()
+	return node
+}
+
+func (self *_parser) parseImportSpecifier() *ast.ImportSpecifier {
+	tok := self.token
+	name := self.parseModuleExportName()
+	if self.token == token.IDENTIFIER && self.literal == "as" {
+		self.next()
+		return &ast.ImportSpecifier{
+			ImportName: name,
+			Alias:      self.parseImportedBinding(),
+		}
+	}
+	if !self.isBindingId(tok) {
+		self.error(name.Idx, "Unexpected token %s", name.Literal)
+	}
+	return &ast.ImportSpecifier{
+		ImportName: name,
+		Alias: &ast.Identifier{
+			Name: name.Value,
+			Idx:  name.Idx,
+		},
+	}
+}
+
+func (self *_parser) parseExportDeclaration() *ast.ExportDeclaration {
+	node := &ast.ExportDeclaration{
+		Export: self.expect(token.EXPORT),
+	}
+
+	switch self.token {
+	case token.MULTIPLY:
+		self.next()
+		node.Star = true
+		if self.token == token.IDENTIFIER && self.literal == "as" {
+			self.next()
+			node.Specifiers = []*ast.ExportSpecifier{{
+				ExportName: self.parseModuleExportName(),
+			}}
+		}
+		node.ModuleSpecifier = self.parseFromClause()
+		node.With = self.parseWithClause()
+		self.semicolon()
+	case token.LEFT_BRACE:
+		self.next()
+		node.Specifiers = []*ast.ExportSpecifier{}
+		var firstString *ast.StringLiteral
+		for self.token != token.RIGHT_BRACE && self.token != token.EOF {
+			if self.token == token.STRING && firstString == nil {
+				firstString = &ast.StringLiteral{Idx: self.idx}
+			}
+			spec := &ast.ExportSpecifier{
+				LocalName: self.parseModuleExportName(),
+			}
+			if self.token == token.IDENTIFIER && self.literal == "as" {
+				self.next()
+				spec.ExportName = self.parseModuleExportName()
+			} else {
+				spec.ExportName = spec.LocalName
+			}
+			node.Specifiers = append(node.Specifiers, spec)
+			if self.token != token.RIGHT_BRACE {
+				self.expect(token.COMMA)
+			}
+		}
+		node.RightBrace = self.expect(token.RIGHT_BRACE)
+		if self.token == token.IDENTIFIER && self.literal == "from" {
+			node.ModuleSpecifier = self.parseFromClause()
+			node.With = self.parseWithClause()
+		} else if firstString != nil {
+			self.error(firstString.Idx, "Unexpected string")
+		}
+		self.semicolon()
+	case token.VAR:
+		node.Declaration = self.parseVariableStatement()
+	case token.LET, token.CONST:
+		node.Declaration = self.parseLexicalDeclaration(self.token)
+	case token.FUNCTION:
+		node.Declaration = &ast.FunctionDeclaration{
+			Function: self.parseFunction(true, false, self.idx),
+		}
+	case token.ASYNC:
+		if f := self.parseMaybeAsyncFunction(true); f != nil {
+			node.Declaration = &ast.FunctionDeclaration{
+				Function: f,
+			}
+		} else {
+			self.errorUnexpectedToken(self.token)
+			self.nextStatement()
+		}
+	case token.CLASS:
+		node.Declaration = &ast.ClassDeclaration{
+			Class: self.parseClass(true),
+		}
+	case token.DEFAULT:
+		self.next()
+		node.Default = true
+		switch self.token {
+		case token.FUNCTION:
+			node.Declaration = &ast.FunctionDeclaration{
+				Function: self.parseFunction(false, false, self.idx),
+			}
+		case token.CLASS:
+			node.Declaration = &ast.ClassDeclaration{
+				Class: self.parseClass(false),
+			}
+		case token.ASYNC:
+			if f := self.parseMaybeAsyncFunction(false); f != nil {
+				node.Declaration = &ast.FunctionDeclaration{
+					Function: f,
+				}
+				break
+			}
+			fallthrough
+		default:
+			node.Expression = self.parseAssignmentExpression()
+			self.semicolon()
+		}
+	default:
+		self.errorUnexpectedToken(self.token)
+		self.nextStatement()
+	}
+
+	return node
+}
+
 func (self *_parser) parseProgram() *ast.Program {
 	prg := &ast.Program{
 		Body:            self.parseSourceElements(),
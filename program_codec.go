@@ -0,0 +1,996 @@
+package goja
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"hash/fnv"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"sync"
+	"unsafe"
+
+	"github.com/dop251/goja/file"
+)
+
+// The encoding of a Program is a header followed by the encoding of the *Program itself. The values are encoded
+// according to their Go types: integers as varints, strings and slices with their length, pointers as an index
+// into the table of the already decoded pointers (so shared pointers are decoded as such) and interfaces with the
+// index of their dynamic type in programCodecTypes. The header contains a fingerprint of the encoded types, so the
+// programs encoded by a different version of the package are rejected rather than misinterpreted, and a CRC-32
+// checksum of the rest of the data, so the damaged data is rejected as well.
+
+const programCodecVersion = 2
+
+var programCodecMagic = []byte("goja")
+
+// programCodecTypes are the types the values stored in interfaces may have: the instructions and the values.
+var programCodecTypes = []reflect.Type{
+	reflect.TypeOf((*_add)(nil)).Elem(),
+	reflect.TypeOf((*_and)(nil)).Elem(),
+	reflect.TypeOf((*_bnot)(nil)).Elem(),
+	reflect.TypeOf((*_boxThis)(nil)).Elem(),
+	reflect.TypeOf((*_callEvalVariadic)(nil)).Elem(),
+	reflect.TypeOf((*_callEvalVariadicStrict)(nil)).Elem(),
+	reflect.TypeOf((*_callVariadic)(nil)).Elem(),
+	reflect.TypeOf((*_checkObjectCoercible)(nil)).Elem(),
+	reflect.TypeOf((*_clearResult)(nil)).Elem(),
+	reflect.TypeOf((*_copyRest)(nil)).Elem(),
+	reflect.TypeOf((*_copySpread)(nil)).Elem(),
+	reflect.TypeOf((*_createArgsRestStash)(nil)).Elem(),
+	reflect.TypeOf((*_createDestructSrc)(nil)).Elem(),
+	reflect.TypeOf((*_dec)(nil)).Elem(),
+	reflect.TypeOf((*_deleteElem)(nil)).Elem(),
+	reflect.TypeOf((*_deleteElemStrict)(nil)).Elem(),
+	reflect.TypeOf((*_disposeError)(nil)).Elem(),
+	reflect.TypeOf((*_disposeResources)(nil)).Elem(),
+	reflect.TypeOf((*_div)(nil)).Elem(),
+	reflect.TypeOf((*_dup)(nil)).Elem(),
+	reflect.TypeOf((*_endVariadic)(nil)).Elem(),
+	reflect.TypeOf((*_enterWith)(nil)).Elem(),
+	reflect.TypeOf((*_enumGet)(nil)).Elem(),
+	reflect.TypeOf((*_enumPop)(nil)).Elem(),
+	reflect.TypeOf((*_enumPopClose)(nil)).Elem(),
+	reflect.TypeOf((*_enumerate)(nil)).Elem(),
+	reflect.TypeOf((*_exp)(nil)).Elem(),
+	reflect.TypeOf((*_getElem)(nil)).Elem(),
+	reflect.TypeOf((*_getElemCallee)(nil)).Elem(),
+	reflect.TypeOf((*_getElemRecv)(nil)).Elem(),
+	reflect.TypeOf((*_getElemRecvCallee)(nil)).Elem(),
+	reflect.TypeOf((*_getElemRef)(nil)).Elem(),
+	reflect.TypeOf((*_getElemRefRecv)(nil)).Elem(),
+	reflect.TypeOf((*_getElemRefRecvStrict)(nil)).Elem(),
+	reflect.TypeOf((*_getElemRefStrict)(nil)).Elem(),
+	reflect.TypeOf((*_getKey)(nil)).Elem(),
+	reflect.TypeOf((*_getValue)(nil)).Elem(),
+	reflect.TypeOf((*_inc)(nil)).Elem(),
+	reflect.TypeOf((*_initValueP)(nil)).Elem(),
+	reflect.TypeOf((*_iterCloseAsyncResult)(nil)).Elem(),
+	reflect.TypeOf((*_iterNextAsync)(nil)).Elem(),
+	reflect.TypeOf((*_iterate)(nil)).Elem(),
+	reflect.TypeOf((*_iterateAsyncP)(nil)).Elem(),
+	reflect.TypeOf((*_iterateP)(nil)).Elem(),
+	reflect.TypeOf((*_leaveWith)(nil)).Elem(),
+	reflect.TypeOf((*_loadCallee)(nil)).Elem(),
+	reflect.TypeOf((*_loadGlobalObject)(nil)).Elem(),
+	reflect.TypeOf((*_loadNewTarget)(nil)).Elem(),
+	reflect.TypeOf((*_loadNil)(nil)).Elem(),
+	reflect.TypeOf((*_loadSuper)(nil)).Elem(),
+	reflect.TypeOf((*_loadUndef)(nil)).Elem(),
+	reflect.TypeOf((*_mod)(nil)).Elem(),
+	reflect.TypeOf((*_mul)(nil)).Elem(),
+	reflect.TypeOf((*_neg)(nil)).Elem(),
+	reflect.TypeOf((*_new)(nil)).Elem(),
+	reflect.TypeOf((*_newArrayFromIter)(nil)).Elem(),
+	reflect.TypeOf((*_newDisposeCapability)(nil)).Elem(),
+	reflect.TypeOf((*_newObject)(nil)).Elem(),
+	reflect.TypeOf((*_newVariadic)(nil)).Elem(),
+	reflect.TypeOf((*_not)(nil)).Elem(),
+	reflect.TypeOf((*_op_eq)(nil)).Elem(),
+	reflect.TypeOf((*_op_gt)(nil)).Elem(),
+	reflect.TypeOf((*_op_gte)(nil)).Elem(),
+	reflect.TypeOf((*_op_in)(nil)).Elem(),
+	reflect.TypeOf((*_op_instanceof)(nil)).Elem(),
+	reflect.TypeOf((*_op_lt)(nil)).Elem(),
+	reflect.TypeOf((*_op_lte)(nil)).Elem(),
+	reflect.TypeOf((*_op_neq)(nil)).Elem(),
+	reflect.TypeOf((*_op_strict_eq)(nil)).Elem(),
+	reflect.TypeOf((*_op_strict_neq)(nil)).Elem(),
+	reflect.TypeOf((*_or)(nil)).Elem(),
+	reflect.TypeOf((*_plus)(nil)).Elem(),
+	reflect.TypeOf((*_pop)(nil)).Elem(),
+	reflect.TypeOf((*_popRef)(nil)).Elem(),
+	reflect.TypeOf((*_pushArrayItem)(nil)).Elem(),
+	reflect.TypeOf((*_pushArraySpread)(nil)).Elem(),
+	reflect.TypeOf((*_pushSpread)(nil)).Elem(),
+	reflect.TypeOf((*_putValue)(nil)).Elem(),
+	reflect.TypeOf((*_putValueP)(nil)).Elem(),
+	reflect.TypeOf((*_ret)(nil)).Elem(),
+	reflect.TypeOf((*_sal)(nil)).Elem(),
+	reflect.TypeOf((*_sar)(nil)).Elem(),
+	reflect.TypeOf((*_saveResult)(nil)).Elem(),
+	reflect.TypeOf((*_setElem)(nil)).Elem(),
+	reflect.TypeOf((*_setElem1)(nil)).Elem(),
+	reflect.TypeOf((*_setElem1Named)(nil)).Elem(),
+	reflect.TypeOf((*_setElemP)(nil)).Elem(),
+	reflect.TypeOf((*_setElemRecv)(nil)).Elem(),
+	reflect.TypeOf((*_setElemRecvP)(nil)).Elem(),
+	reflect.TypeOf((*_setElemRecvStrict)(nil)).Elem(),
+	reflect.TypeOf((*_setElemRecvStrictP)(nil)).Elem(),
+	reflect.TypeOf((*_setElemStrict)(nil)).Elem(),
+	reflect.TypeOf((*_setElemStrictP)(nil)).Elem(),
+	reflect.TypeOf((*_setProto)(nil)).Elem(),
+	reflect.TypeOf((*_shr)(nil)).Elem(),
+	reflect.TypeOf((*_startVariadic)(nil)).Elem(),
+	reflect.TypeOf((*_sub)(nil)).Elem(),
+	reflect.TypeOf((*_superCallVariadic)(nil)).Elem(),
+	reflect.TypeOf((*_throw)(nil)).Elem(),
+	reflect.TypeOf((*_throwAssignToConst)(nil)).Elem(),
+	reflect.TypeOf((*_toNumber)(nil)).Elem(),
+	reflect.TypeOf((*_toPropertyKey)(nil)).Elem(),
+	reflect.TypeOf((*_toString)(nil)).Elem(),
+	reflect.TypeOf((*_typeof)(nil)).Elem(),
+	reflect.TypeOf((*_xor)(nil)).Elem(),
+	reflect.TypeOf((*addDisposableResource)(nil)).Elem(),
+	reflect.TypeOf((*await)(nil)).Elem(),
+	reflect.TypeOf((*bindGlobal)(nil)).Elem(),
+	reflect.TypeOf((*bindVars)(nil)).Elem(),
+	reflect.TypeOf((*call)(nil)).Elem(),
+	reflect.TypeOf((*callEval)(nil)).Elem(),
+	reflect.TypeOf((*callEvalStrict)(nil)).Elem(),
+	reflect.TypeOf((*concatStrings)(nil)).Elem(),
+	reflect.TypeOf((*copyStash)(nil)).Elem(),
+	reflect.TypeOf((*createArgsMapped)(nil)).Elem(),
+	reflect.TypeOf((*createArgsRestStack)(nil)).Elem(),
+	reflect.TypeOf((*createArgsUnmapped)(nil)).Elem(),
+	reflect.TypeOf((*cret)(nil)).Elem(),
+	reflect.TypeOf((*defineComputedKey)(nil)).Elem(),
+	reflect.TypeOf((*defineGetter)(nil)).Elem(),
+	reflect.TypeOf((*defineGetterKeyed)(nil)).Elem(),
+	reflect.TypeOf((*defineMethod)(nil)).Elem(),
+	reflect.TypeOf((*defineMethodKeyed)(nil)).Elem(),
+	reflect.TypeOf((*definePrivateGetter)(nil)).Elem(),
+	reflect.TypeOf((*definePrivateMethod)(nil)).Elem(),
+	reflect.TypeOf((*definePrivateProp)(nil)).Elem(),
+	reflect.TypeOf((*definePrivateSetter)(nil)).Elem(),
+	reflect.TypeOf((*defineProp)(nil)).Elem(),
+	reflect.TypeOf((*definePropKeyed)(nil)).Elem(),
+	reflect.TypeOf((*defineSetter)(nil)).Elem(),
+	reflect.TypeOf((*defineSetterKeyed)(nil)).Elem(),
+	reflect.TypeOf((*deleteGlobal)(nil)).Elem(),
+	reflect.TypeOf((*deleteProp)(nil)).Elem(),
+	reflect.TypeOf((*deletePropStrict)(nil)).Elem(),
+	reflect.TypeOf((*deleteVar)(nil)).Elem(),
+	reflect.TypeOf((*disposeNextAsync)(nil)).Elem(),
+	reflect.TypeOf((*dupLast)(nil)).Elem(),
+	reflect.TypeOf((*dupN)(nil)).Elem(),
+	reflect.TypeOf((*enterBlock)(nil)).Elem(),
+	reflect.TypeOf((*enterCatchBlock)(nil)).Elem(),
+	reflect.TypeOf((*enterFinally)(nil)).Elem(),
+	reflect.TypeOf((*enterFunc)(nil)).Elem(),
+	reflect.TypeOf((*enterFunc1)(nil)).Elem(),
+	reflect.TypeOf((*enterFuncBody)(nil)).Elem(),
+	reflect.TypeOf((*enterFuncStashless)(nil)).Elem(),
+	reflect.TypeOf((*enumNext)(nil)).Elem(),
+	reflect.TypeOf((*getPrivatePropId)(nil)).Elem(),
+	reflect.TypeOf((*getPrivatePropIdCallee)(nil)).Elem(),
+	reflect.TypeOf((*getPrivatePropRes)(nil)).Elem(),
+	reflect.TypeOf((*getPrivatePropResCallee)(nil)).Elem(),
+	reflect.TypeOf((*getPrivateRefId)(nil)).Elem(),
+	reflect.TypeOf((*getPrivateRefRes)(nil)).Elem(),
+	reflect.TypeOf((*getProp)(nil)).Elem(),
+	reflect.TypeOf((*getPropCallee)(nil)).Elem(),
+	reflect.TypeOf((*getPropRecv)(nil)).Elem(),
+	reflect.TypeOf((*getPropRecvCallee)(nil)).Elem(),
+	reflect.TypeOf((*getPropRef)(nil)).Elem(),
+	reflect.TypeOf((*getPropRefRecv)(nil)).Elem(),
+	reflect.TypeOf((*getPropRefRecvStrict)(nil)).Elem(),
+	reflect.TypeOf((*getPropRefStrict)(nil)).Elem(),
+	reflect.TypeOf((*getTaggedTmplObject)(nil)).Elem(),
+	reflect.TypeOf((*getThisDynamic)(nil)).Elem(),
+	reflect.TypeOf((*importCall)(nil)).Elem(),
+	reflect.TypeOf((*initGlobal)(nil)).Elem(),
+	reflect.TypeOf((*initGlobalP)(nil)).Elem(),
+	reflect.TypeOf((*initStack)(nil)).Elem(),
+	reflect.TypeOf((*initStack1)(nil)).Elem(),
+	reflect.TypeOf((*initStack1P)(nil)).Elem(),
+	reflect.TypeOf((*initStackP)(nil)).Elem(),
+	reflect.TypeOf((*initStash)(nil)).Elem(),
+	reflect.TypeOf((*initStashP)(nil)).Elem(),
+	reflect.TypeOf((*initStaticElements)(nil)).Elem(),
+	reflect.TypeOf((*iterCloseAsync)(nil)).Elem(),
+	reflect.TypeOf((*iterGetNextOrUndef)(nil)).Elem(),
+	reflect.TypeOf((*iterNext)(nil)).Elem(),
+	reflect.TypeOf((*iterNextAsyncResult)(nil)).Elem(),
+	reflect.TypeOf((*jcoalesc)(nil)).Elem(),
+	reflect.TypeOf((*jdef)(nil)).Elem(),
+	reflect.TypeOf((*jdefP)(nil)).Elem(),
+	reflect.TypeOf((*jeq)(nil)).Elem(),
+	reflect.TypeOf((*jeq1)(nil)).Elem(),
+	reflect.TypeOf((*jne)(nil)).Elem(),
+	reflect.TypeOf((*jneq1)(nil)).Elem(),
+	reflect.TypeOf((*jopt)(nil)).Elem(),
+	reflect.TypeOf((*joptc)(nil)).Elem(),
+	reflect.TypeOf((*jump)(nil)).Elem(),
+	reflect.TypeOf((*leaveBlock)(nil)).Elem(),
+	reflect.TypeOf((*leaveFinally)(nil)).Elem(),
+	reflect.TypeOf((*leaveTry)(nil)).Elem(),
+	reflect.TypeOf((*loadComputedKey)(nil)).Elem(),
+	reflect.TypeOf((*loadDynamic)(nil)).Elem(),
+	reflect.TypeOf((*loadDynamicCallee)(nil)).Elem(),
+	reflect.TypeOf((*loadDynamicRef)(nil)).Elem(),
+	reflect.TypeOf((*loadImport)(nil)).Elem(),
+	reflect.TypeOf((*loadImportMeta)(nil)).Elem(),
+	reflect.TypeOf((*loadMixed)(nil)).Elem(),
+	reflect.TypeOf((*loadMixedLex)(nil)).Elem(),
+	reflect.TypeOf((*loadMixedStack)(nil)).Elem(),
+	reflect.TypeOf((*loadMixedStack1)(nil)).Elem(),
+	reflect.TypeOf((*loadMixedStack1Lex)(nil)).Elem(),
+	reflect.TypeOf((*loadMixedStackLex)(nil)).Elem(),
+	reflect.TypeOf((*loadStack)(nil)).Elem(),
+	reflect.TypeOf((*loadStack1)(nil)).Elem(),
+	reflect.TypeOf((*loadStack1Lex)(nil)).Elem(),
+	reflect.TypeOf((*loadStackLex)(nil)).Elem(),
+	reflect.TypeOf((*loadStash)(nil)).Elem(),
+	reflect.TypeOf((*loadStashLex)(nil)).Elem(),
+	reflect.TypeOf((*loadThisStack)(nil)).Elem(),
+	reflect.TypeOf((*loadThisStash)(nil)).Elem(),
+	reflect.TypeOf((*loadVal)(nil)).Elem(),
+	reflect.TypeOf((*newArray)(nil)).Elem(),
+	reflect.TypeOf((*newArrowFunc)(nil)).Elem(),
+	reflect.TypeOf((*newAsyncArrowFunc)(nil)).Elem(),
+	reflect.TypeOf((*newAsyncFunc)(nil)).Elem(),
+	reflect.TypeOf((*newAsyncMethod)(nil)).Elem(),
+	reflect.TypeOf((*newClass)(nil)).Elem(),
+	reflect.TypeOf((*newDerivedClass)(nil)).Elem(),
+	reflect.TypeOf((*newFunc)(nil)).Elem(),
+	reflect.TypeOf((*newMethod)(nil)).Elem(),
+	reflect.TypeOf((*newRegexp)(nil)).Elem(),
+	reflect.TypeOf((*newStaticFieldInit)(nil)).Elem(),
+	reflect.TypeOf((*popPrivateEnv)(nil)).Elem(),
+	reflect.TypeOf((*privateInId)(nil)).Elem(),
+	reflect.TypeOf((*privateInRes)(nil)).Elem(),
+	reflect.TypeOf((*putProp)(nil)).Elem(),
+	reflect.TypeOf((*rdupN)(nil)).Elem(),
+	reflect.TypeOf((*resolveMixed)(nil)).Elem(),
+	reflect.TypeOf((*resolveMixedStack)(nil)).Elem(),
+	reflect.TypeOf((*resolveMixedStack1)(nil)).Elem(),
+	reflect.TypeOf((*resolveThisDynamic)(nil)).Elem(),
+	reflect.TypeOf((*resolveThisStack)(nil)).Elem(),
+	reflect.TypeOf((*resolveThisStash)(nil)).Elem(),
+	reflect.TypeOf((*resolveVar1)(nil)).Elem(),
+	reflect.TypeOf((*resolveVar1Strict)(nil)).Elem(),
+	reflect.TypeOf((*setGlobal)(nil)).Elem(),
+	reflect.TypeOf((*setGlobalStrict)(nil)).Elem(),
+	reflect.TypeOf((*setPrivatePropId)(nil)).Elem(),
+	reflect.TypeOf((*setPrivatePropIdP)(nil)).Elem(),
+	reflect.TypeOf((*setPrivatePropRes)(nil)).Elem(),
+	reflect.TypeOf((*setPrivatePropResP)(nil)).Elem(),
+	reflect.TypeOf((*setProp)(nil)).Elem(),
+	reflect.TypeOf((*setPropP)(nil)).Elem(),
+	reflect.TypeOf((*setPropRecv)(nil)).Elem(),
+	reflect.TypeOf((*setPropRecvP)(nil)).Elem(),
+	reflect.TypeOf((*setPropRecvStrict)(nil)).Elem(),
+	reflect.TypeOf((*setPropRecvStrictP)(nil)).Elem(),
+	reflect.TypeOf((*setPropStrict)(nil)).Elem(),
+	reflect.TypeOf((*setPropStrictP)(nil)).Elem(),
+	reflect.TypeOf((*storeStack)(nil)).Elem(),
+	reflect.TypeOf((*storeStack1)(nil)).Elem(),
+	reflect.TypeOf((*storeStack1Lex)(nil)).Elem(),
+	reflect.TypeOf((*storeStack1LexP)(nil)).Elem(),
+	reflect.TypeOf((*storeStack1P)(nil)).Elem(),
+	reflect.TypeOf((*storeStackLex)(nil)).Elem(),
+	reflect.TypeOf((*storeStackLexP)(nil)).Elem(),
+	reflect.TypeOf((*storeStackP)(nil)).Elem(),
+	reflect.TypeOf((*storeStash)(nil)).Elem(),
+	reflect.TypeOf((*storeStashLex)(nil)).Elem(),
+	reflect.TypeOf((*storeStashLexP)(nil)).Elem(),
+	reflect.TypeOf((*storeStashP)(nil)).Elem(),
+	reflect.TypeOf((*superCall)(nil)).Elem(),
+	reflect.TypeOf((*tailCall)(nil)).Elem(),
+	reflect.TypeOf((*throwConst)(nil)).Elem(),
+	reflect.TypeOf((*try)(nil)).Elem(),
+
+	reflect.TypeOf((*asciiString)(nil)).Elem(),
+	reflect.TypeOf((*unicodeString)(nil)).Elem(),
+	reflect.TypeOf((*importedString)(nil)).Elem(),
+	reflect.TypeOf((*valueInt)(nil)).Elem(),
+	reflect.TypeOf((*valueFloat)(nil)).Elem(),
+	reflect.TypeOf((*valueBool)(nil)).Elem(),
+	reflect.TypeOf((*valueNull)(nil)).Elem(),
+	reflect.TypeOf((*valueUndefined)(nil)).Elem(),
+	reflect.TypeOf((*valueBigInt)(nil)).Elem(),
+	reflect.TypeOf((*referenceError)(nil)).Elem(),
+	reflect.TypeOf((*valueProperty)(nil)).Elem(),
+}
+
+// programCodecCustom encodes and decodes the values of a type that are not encoded field by field.
+type programCodecCustom struct {
+	encode func(e *programEncoder, v reflect.Value)
+	decode func(d *programDecoder, v reflect.Value)
+}
+
+var programCodecCustoms = map[reflect.Type]programCodecCustom{
+	// the source is kept, so the positions in the stack traces are preserved
+	reflect.TypeOf(file.File{}): {
+		encode: func(e *programEncoder, v reflect.Value) {
+			f := v.Addr().Interface().(*file.File)
+			e.string(f.Name())
+			e.string(f.Source())
+			e.varint(int64(f.Base()))
+		},
+		decode: func(d *programDecoder, v reflect.Value) {
+			name := d.string()
+			src := d.string()
+			base := int(d.varint())
+			v.Set(reflect.ValueOf(file.NewFile(name, src, base)).Elem())
+		},
+	},
+	// the compiled regexps are not encoded, they are compiled again
+	reflect.TypeOf(newRegexp{}): {
+		encode: func(e *programEncoder, v reflect.Value) {
+			n := v.Addr().Interface().(*newRegexp)
+			e.string(n.src.String())
+			e.string(n.pattern.flags())
+		},
+		decode: func(d *programDecoder, v reflect.Value) {
+			src := d.string()
+			pattern, err := compileRegexp(src, d.string())
+			if err != nil {
+				d.fail(err)
+			}
+			*v.Addr().Interface().(*newRegexp) = newRegexp{pattern: pattern, src: newStringValue(src)}
+		},
+	},
+	reflect.TypeOf(valueBigInt{}): {
+		encode: func(e *programEncoder, v reflect.Value) {
+			b, _ := (*big.Int)(v.Addr().Interface().(*valueBigInt)).GobEncode()
+			e.bytes(b)
+		},
+		decode: func(d *programDecoder, v reflect.Value) {
+			if err := (*big.Int)(v.Addr().Interface().(*valueBigInt)).GobDecode(d.bytes()); err != nil {
+				d.fail(err)
+			}
+		},
+	},
+	reflect.TypeOf(SourceTextModuleRecord{}): {
+		encode: func(e *programEncoder, v reflect.Value) {
+			e.fail(errors.New("the programs of modules cannot be encoded"))
+		},
+	},
+	// the compiled code is not linked to a runtime, so it should not contain objects
+	reflect.TypeOf(Object{}): {
+		encode: func(e *programEncoder, v reflect.Value) {
+			e.fail(errors.New("cannot encode an Object"))
+		},
+	},
+}
+
+var (
+	programCodecOnce        sync.Once
+	programCodecTypeIds     map[reflect.Type]int
+	programCodecFingerprint uint64
+)
+
+func initProgramCodec() {
+	programCodecOnce.Do(func() {
+		programCodecTypeIds = make(map[reflect.Type]int, len(programCodecTypes))
+		for i, t := range programCodecTypes {
+			programCodecTypeIds[t] = i
+		}
+		h := fnv.New64a()
+		seen := make(map[reflect.Type]bool)
+		var describe func(t reflect.Type)
+		describe = func(t reflect.Type) {
+			if seen[t] {
+				return
+			}
+			seen[t] = true
+			fmt.Fprintf(h, "%v:%v;", t, t.Kind())
+			if _, exists := programCodecCustoms[t]; exists {
+				return
+			}
+			switch t.Kind() {
+			case reflect.Struct:
+				for i := 0; i < t.NumField(); i++ {
+					f := t.Field(i)
+					fmt.Fprintf(h, "%s %v;", f.Name, f.Type)
+					describe(f.Type)
+				}
+			case reflect.Ptr, reflect.Slice, reflect.Array:
+				describe(t.Elem())
+			case reflect.Map:
+				describe(t.Key())
+				describe(t.Elem())
+			}
+		}
+		describe(reflect.TypeOf((*Program)(nil)))
+		for _, t := range programCodecTypes {
+			describe(t)
+		}
+		programCodecFingerprint = h.Sum64()
+	})
+}
+
+type programCodecError struct {
+	err error
+}
+
+type programPtrKey struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+type programEncoder struct {
+	buf  []byte
+	ptrs map[programPtrKey]uint64
+}
+
+type programDecoder struct {
+	data []byte
+	ptrs []reflect.Value
+	// the decoded Programs, i.e. the top-level one and those of the functions
+	programs []*Program
+}
+
+// MarshalBinary encodes the compiled code of the Program, so it can be stored or transferred and then decoded with
+// UnmarshalBinary, which is much faster than compiling the source again. It implements encoding.BinaryMarshaler.
+//
+// The encoding depends on the internal representation of the compiled code, so it can only be decoded by the same
+// version of this package. The source code is included in the encoding, so the positions in the stack traces are
+// preserved, however the source maps are not. The programs of modules cannot be encoded.
+func (p *Program) MarshalBinary() (data []byte, err error) {
+	initProgramCodec()
+	e := &programEncoder{
+		ptrs: make(map[programPtrKey]uint64),
+	}
+	defer func() {
+		if x := recover(); x != nil {
+			if ce, ok := x.(programCodecError); ok {
+				err = ce.err
+				return
+			}
+			panic(x)
+		}
+	}()
+	e.buf = append(e.buf, programCodecMagic...)
+	e.buf = append(e.buf, programCodecVersion)
+	e.uint64(programCodecFingerprint)
+	header := len(e.buf)
+	e.buf = append(e.buf, 0, 0, 0, 0) // the checksum
+	e.encode(reflect.ValueOf(p))
+	binary.LittleEndian.PutUint32(e.buf[header:], crc32.ChecksumIEEE(e.buf[header+4:]))
+	return e.buf, nil
+}
+
+// UnmarshalBinary decodes a Program encoded by MarshalBinary. The resulting Program can be run in any Runtime,
+// like the one returned by Compile. An error is returned if the data is malformed, damaged (the encoding includes a
+// checksum) or has been encoded by a different version of this package. It implements encoding.BinaryUnmarshaler.
+//
+// Only the data from a trusted source, such as a cache written by the same application, may be decoded. The
+// decoder checks that the operands of the instructions (the jump targets, the stack and stash indexes, etc.) are
+// within the ranges the compiler produces, however it cannot verify that the code is otherwise consistent, so a
+// crafted encoding may still cause a panic or an unexpected behaviour when run. The checksum only protects against
+// accidental damage.
+func (p *Program) UnmarshalBinary(data []byte) (err error) {
+	initProgramCodec()
+	header := len(programCodecMagic) + 1 + 8
+	if len(data) < header+4 || string(data[:len(programCodecMagic)]) != string(programCodecMagic) {
+		return errors.New("not an encoded Program")
+	}
+	if data[len(programCodecMagic)] != programCodecVersion ||
+		binary.LittleEndian.Uint64(data[len(programCodecMagic)+1:]) != programCodecFingerprint {
+		return errors.New("the Program has been encoded by a different version of goja")
+	}
+	if binary.LittleEndian.Uint32(data[header:]) != crc32.ChecksumIEEE(data[header+4:]) {
+		return errors.New("the Program encoding is damaged (checksum mismatch)")
+	}
+	d := &programDecoder{
+		data: data[header+4:],
+	}
+	defer func() {
+		if x := recover(); x != nil {
+			if ce, ok := x.(programCodecError); ok {
+				err = ce.err
+				return
+			}
+			panic(x)
+		}
+	}()
+	res := reflect.New(reflect.TypeOf(p))
+	d.decode(res.Elem())
+	if res.Elem().IsNil() || len(d.data) > 0 {
+		return errors.New("malformed Program encoding")
+	}
+	d.checkOperands()
+	*p = *res.Elem().Interface().(*Program)
+	return nil
+}
+
+func (e *programEncoder) fail(err error) {
+	panic(programCodecError{err: err})
+}
+
+func (e *programEncoder) uint64(n uint64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], n)
+	e.buf = append(e.buf, b[:]...)
+}
+
+func (e *programEncoder) uvarint(n uint64) {
+	var b [binary.MaxVarintLen64]byte
+	e.buf = append(e.buf, b[:binary.PutUvarint(b[:], n)]...)
+}
+
+func (e *programEncoder) varint(n int64) {
+	var b [binary.MaxVarintLen64]byte
+	e.buf = append(e.buf, b[:binary.PutVarint(b[:], n)]...)
+}
+
+func (e *programEncoder) string(s string) {
+	e.uvarint(uint64(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+func (e *programEncoder) bytes(b []byte) {
+	e.uvarint(uint64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+func (e *programEncoder) encode(v reflect.Value) {
+	t := v.Type()
+	if c, exists := programCodecCustoms[t]; exists {
+		if !v.CanAddr() {
+			// the custom types are only encoded through pointers
+			e.fail(fmt.Errorf("cannot encode a value of type %v", t))
+		}
+		c.encode(e, reflect.NewAt(t, unsafe.Pointer(v.UnsafeAddr())).Elem())
+		return
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			e.buf = append(e.buf, 1)
+		} else {
+			e.buf = append(e.buf, 0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.varint(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.uvarint(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		e.uint64(math.Float64bits(v.Float()))
+	case reflect.String:
+		e.string(v.String())
+	case reflect.Slice:
+		if v.IsNil() {
+			e.uvarint(0)
+			return
+		}
+		e.uvarint(uint64(v.Len()) + 1)
+		for i := 0; i < v.Len(); i++ {
+			e.encode(v.Index(i))
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			e.encode(v.Index(i))
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			e.uvarint(0)
+			return
+		}
+		if t.Key().Kind() != reflect.String {
+			e.fail(fmt.Errorf("cannot encode a value of type %v", t))
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return keys[i].String() < keys[j].String()
+		})
+		e.uvarint(uint64(len(keys)) + 1)
+		for _, k := range keys {
+			e.encode(k)
+			e.encode(v.MapIndex(k))
+		}
+	case reflect.Ptr:
+		if v.IsNil() {
+			e.uvarint(0)
+			return
+		}
+		key := programPtrKey{ptr: v.Pointer(), typ: t}
+		if id, exists := e.ptrs[key]; exists {
+			e.uvarint(id + 1)
+			return
+		}
+		id := uint64(len(e.ptrs))
+		e.ptrs[key] = id
+		e.uvarint(id + 1)
+		e.encode(reflect.NewAt(t.Elem(), unsafe.Pointer(v.Pointer())).Elem())
+	case reflect.Interface:
+		if v.IsNil() {
+			e.uvarint(0)
+			return
+		}
+		elem := v.Elem()
+		typ := elem.Type()
+		ptr := typ.Kind() == reflect.Ptr
+		if ptr {
+			typ = typ.Elem()
+		}
+		id, exists := programCodecTypeIds[typ]
+		if !exists {
+			e.fail(fmt.Errorf("cannot encode a value of type %v", elem.Type()))
+		}
+		if ptr {
+			e.uvarint(uint64(id)*2 + 2)
+		} else {
+			e.uvarint(uint64(id)*2 + 1)
+		}
+		e.encode(elem)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			e.encode(v.Field(i))
+		}
+	default:
+		e.fail(fmt.Errorf("cannot encode a value of type %v", t))
+	}
+}
+
+func (d *programDecoder) fail(err error) {
+	panic(programCodecError{err: err})
+}
+
+func (d *programDecoder) malformed() {
+	d.fail(errors.New("malformed Program encoding"))
+}
+
+func (d *programDecoder) uvarint() uint64 {
+	n, l := binary.Uvarint(d.data)
+	if l <= 0 {
+		d.malformed()
+	}
+	d.data = d.data[l:]
+	return n
+}
+
+func (d *programDecoder) varint() int64 {
+	n, l := binary.Varint(d.data)
+	if l <= 0 {
+		d.malformed()
+	}
+	d.data = d.data[l:]
+	return n
+}
+
+func (d *programDecoder) byte() byte {
+	if len(d.data) == 0 {
+		d.malformed()
+	}
+	b := d.data[0]
+	d.data = d.data[1:]
+	return b
+}
+
+// len reads a length prefix, each of the items takes at least one byte.
+func (d *programDecoder) len() int {
+	n := d.uvarint()
+	if n > uint64(len(d.data)) {
+		d.malformed()
+	}
+	return int(n)
+}
+
+func (d *programDecoder) bytes() []byte {
+	n := d.len()
+	b := d.data[:n:n]
+	d.data = d.data[n:]
+	return b
+}
+
+func (d *programDecoder) string() string {
+	return string(d.bytes())
+}
+
+// decode decodes a value into v, which must be addressable.
+func (d *programDecoder) decode(v reflect.Value) {
+	t := v.Type()
+	if !v.CanSet() {
+		v = reflect.NewAt(t, unsafe.Pointer(v.UnsafeAddr())).Elem()
+	}
+	if c, exists := programCodecCustoms[t]; exists {
+		if c.decode == nil {
+			d.malformed()
+		}
+		c.decode(d, v)
+		return
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		v.SetBool(d.byte() != 0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(d.varint())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v.SetUint(d.uvarint())
+	case reflect.Float32, reflect.Float64:
+		if len(d.data) < 8 {
+			d.malformed()
+		}
+		v.SetFloat(math.Float64frombits(binary.LittleEndian.Uint64(d.data)))
+		d.data = d.data[8:]
+	case reflect.String:
+		v.SetString(d.string())
+	case reflect.Slice:
+		n := d.len()
+		if n == 0 {
+			return
+		}
+		s := reflect.MakeSlice(t, n-1, n-1)
+		for i := 0; i < n-1; i++ {
+			d.decode(s.Index(i))
+		}
+		v.Set(s)
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			d.decode(v.Index(i))
+		}
+	case reflect.Map:
+		n := d.len()
+		if n == 0 {
+			return
+		}
+		m := reflect.MakeMapWithSize(t, n-1)
+		for i := 0; i < n-1; i++ {
+			k := reflect.New(t.Key()).Elem()
+			d.decode(k)
+			val := reflect.New(t.Elem()).Elem()
+			d.decode(val)
+			m.SetMapIndex(k, val)
+		}
+		v.Set(m)
+	case reflect.Ptr:
+		n := d.uvarint()
+		if n == 0 {
+			return
+		}
+		id := n - 1
+		if id < uint64(len(d.ptrs)) {
+			p := d.ptrs[id]
+			if p.Type() != t {
+				d.malformed()
+			}
+			v.Set(p)
+			return
+		}
+		if id != uint64(len(d.ptrs)) {
+			d.malformed()
+		}
+		p := reflect.New(t.Elem())
+		d.ptrs = append(d.ptrs, p)
+		d.decode(p.Elem())
+		if prg, ok := p.Interface().(*Program); ok {
+			d.programs = append(d.programs, prg)
+		}
+		v.Set(p)
+	case reflect.Interface:
+		n := d.uvarint()
+		if n == 0 {
+			return
+		}
+		id := (n - 1) / 2
+		if id >= uint64(len(programCodecTypes)) {
+			d.malformed()
+		}
+		typ := programCodecTypes[id]
+		if n%2 == 0 {
+			typ = reflect.PtrTo(typ)
+		}
+		if !typ.AssignableTo(t) {
+			d.malformed()
+		}
+		elem := reflect.New(typ).Elem()
+		d.decode(elem)
+		v.Set(elem)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			d.decode(v.Field(i))
+		}
+	default:
+		d.malformed()
+	}
+}
+
+// checkOperands checks that the operands of the decoded instructions are within the ranges the compiler produces:
+// the jump targets are within the code, the value indexes within the values of the Program, the stack indexes
+// within the frame declared by the enter* instructions of the Program and the stash indexes and levels within the
+// largest stash and the deepest stash nesting of all the decoded Programs.
+func (d *programDecoder) checkOperands() {
+	var maxStash uint32
+	stashSize := func(sizes ...uint32) {
+		for _, size := range sizes {
+			if size > maxStash {
+				maxStash = size
+			}
+		}
+	}
+	stashDepth := 1 // the global stash
+	for _, p := range d.programs {
+		for _, instr := range p.code {
+			switch instr := instr.(type) {
+			case *enterBlock:
+				stashSize(instr.stashSize)
+				stashDepth++
+			case *enterCatchBlock:
+				stashSize(instr.stashSize)
+				stashDepth++
+			case *enterFuncBody:
+				stashSize(instr.stashSize)
+				stashDepth++
+			case *enterFunc:
+				stashSize(instr.stashSize, instr.numArgs)
+				stashDepth++
+			case *enterFunc1:
+				stashSize(instr.stashSize, instr.numArgs)
+				stashDepth++
+			case _enterWith:
+				stashDepth++
+			}
+		}
+	}
+	checkStash := func(s uint32) bool {
+		return int(s>>24) <= stashDepth && s&0x00FFFFFF < maxStash
+	}
+	checkLevel := func(level uint8) bool {
+		return int(level) <= stashDepth
+	}
+
+	for _, p := range d.programs {
+		frame := 1 // 'this'
+		for _, instr := range p.code {
+			switch instr := instr.(type) {
+			case *enterBlock:
+				frame += int(instr.stackSize)
+			case *enterCatchBlock:
+				frame += int(instr.stackSize)
+			case *enterFuncBody:
+				frame += int(instr.stackSize)
+			case *enterFunc:
+				frame += int(instr.stackSize) + int(instr.numArgs)
+			case *enterFunc1:
+				frame += int(instr.numArgs)
+			case *enterFuncStashless:
+				frame += int(instr.stackSize) + int(instr.args)
+			case *leaveBlock:
+				// includes the exception values of the catch blocks
+				frame += int(instr.stackSize)
+			}
+		}
+		checkStack := func(idx int) bool {
+			return idx <= frame && idx >= -frame
+		}
+
+		for pc, instr := range p.code {
+			checkJump := func(offset int32) bool {
+				target := pc + int(offset)
+				return target >= 0 && target <= len(p.code)
+			}
+			ok := true
+			switch instr := instr.(type) {
+			case jump:
+				ok = checkJump(int32(instr))
+			case jne:
+				ok = checkJump(int32(instr))
+			case jeq:
+				ok = checkJump(int32(instr))
+			case jeq1:
+				ok = checkJump(int32(instr))
+			case jneq1:
+				ok = checkJump(int32(instr))
+			case jdef:
+				ok = checkJump(int32(instr))
+			case jdefP:
+				ok = checkJump(int32(instr))
+			case jopt:
+				ok = checkJump(int32(instr))
+			case joptc:
+				ok = checkJump(int32(instr))
+			case jcoalesc:
+				ok = checkJump(int32(instr))
+			case enumNext:
+				ok = checkJump(int32(instr))
+			case iterNext:
+				ok = checkJump(int32(instr))
+			case iterNextAsyncResult:
+				ok = checkJump(int32(instr))
+			case iterCloseAsync:
+				ok = checkJump(int32(instr))
+			case disposeNextAsync:
+				ok = checkJump(int32(instr))
+			case try:
+				ok = checkJump(instr.catchOffset) && checkJump(instr.finallyOffset)
+
+			case loadVal:
+				ok = int(instr) < len(p.values)
+
+			case loadStack:
+				ok = checkStack(int(instr))
+			case loadStack1:
+				ok = checkStack(int(instr))
+			case loadStackLex:
+				ok = checkStack(int(instr))
+			case loadStack1Lex:
+				ok = checkStack(int(instr))
+			case storeStack:
+				ok = checkStack(int(instr))
+			case storeStackP:
+				ok = checkStack(int(instr))
+			case storeStack1:
+				ok = checkStack(int(instr))
+			case storeStack1P:
+				ok = checkStack(int(instr))
+			case storeStackLex:
+				ok = checkStack(int(instr))
+			case storeStackLexP:
+				ok = checkStack(int(instr))
+			case storeStack1Lex:
+				ok = checkStack(int(instr))
+			case storeStack1LexP:
+				ok = checkStack(int(instr))
+			case initStack:
+				ok = checkStack(int(instr))
+			case initStackP:
+				ok = checkStack(int(instr))
+			case initStack1:
+				ok = checkStack(int(instr))
+			case initStack1P:
+				ok = checkStack(int(instr))
+			case *loadMixedStack:
+				ok = checkStack(instr.idx) && checkLevel(instr.level)
+			case *loadMixedStack1:
+				ok = checkStack(instr.idx) && checkLevel(instr.level)
+			case *loadMixedStackLex:
+				ok = checkStack(instr.idx) && checkLevel(instr.level)
+			case *loadMixedStack1Lex:
+				ok = checkStack(instr.idx) && checkLevel(instr.level)
+			case *resolveMixedStack:
+				ok = checkStack(instr.idx) && checkLevel(instr.level)
+			case *resolveMixedStack1:
+				ok = checkStack(instr.idx) && checkLevel(instr.level)
+
+			case loadStash:
+				ok = checkStash(uint32(instr))
+			case loadStashLex:
+				ok = checkStash(uint32(instr))
+			case storeStash:
+				ok = checkStash(uint32(instr))
+			case storeStashP:
+				ok = checkStash(uint32(instr))
+			case storeStashLex:
+				ok = checkStash(uint32(instr))
+			case storeStashLexP:
+				ok = checkStash(uint32(instr))
+			case initStash:
+				ok = checkStash(uint32(instr))
+			case initStashP:
+				ok = checkStash(uint32(instr))
+			case loadImport:
+				ok = checkStash(uint32(instr))
+			case loadThisStash:
+				ok = checkStash(uint32(instr))
+			case resolveThisStash:
+				ok = checkStash(uint32(instr))
+			case *loadMixed:
+				ok = checkStash(instr.idx)
+			case *loadMixedLex:
+				ok = checkStash(instr.idx)
+			case *resolveMixed:
+				ok = checkStash(instr.idx)
+			}
+			if !ok {
+				d.fail(fmt.Errorf("malformed Program encoding: invalid operand of %T at %d", instr, pc))
+			}
+		}
+	}
+}
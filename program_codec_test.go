@@ -0,0 +1,234 @@
+package goja
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestProgramMarshalBinary(t *testing.T) {
+	const SCRIPT = `
+	"use strict";
+	class Base {
+		#secret = 42;
+		static count = 0;
+		constructor(name) {
+			this.name = name;
+			Base.count++;
+		}
+		get secret() {
+			return this.#secret;
+		}
+		#hidden() {
+			return "hidden " + this.name;
+		}
+		reveal() {
+			return this.#hidden();
+		}
+	}
+	class Derived extends Base {
+		constructor() {
+			super("derived");
+		}
+		reveal() {
+			return super.reveal().toUpperCase();
+		}
+	}
+	function tag(strings, ...values) {
+		return strings.raw.join("|") + values.join(",");
+	}
+	const {a, b: [c, ...rest], ...others} = {a: 1, b: [2, 3, 4], d: 5, e: 6};
+	const results = [];
+	label: for (let i = 0; i < 10; i++) {
+		for (const j of [1, 2]) {
+			if (i * j > 4) break label;
+			results.push(() => i * j);
+		}
+	}
+	let caught;
+	try {
+		null.x;
+	} catch (e) {
+		caught = e instanceof TypeError;
+	} finally {
+		caught = caught && true;
+	}
+	async function asyncFn(x) {
+		return await x * 2;
+	}
+	let asyncResult;
+	asyncFn(21).then(v => { asyncResult = v; });
+	const d = new Derived();
+	[
+		d.secret, d.reveal(), Base.count, tag` + "`a${1}b${2}c`" + `,
+		a, c, rest.join(), Object.keys(others).join(),
+		results.map(f => f()).join(), caught,
+		/(?<year>\d{4})-(\d\d)/u.exec("2024-05").groups.year, /a.b/s.test("a\nb"),
+		2n ** 64n, 0.1 + 0.2, "é😀".length, typeof undefined, null ?? "default",
+		d?.missing?.deep, [1, 2, 3].map(x => x ** 2).reduce((a, b) => a + b)
+	].join(";") + ";" + asyncResult;
+	`
+	prg, err := Compile("test.js", SCRIPT, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := New().RunProgram(prg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := prg.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data1, err := prg.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != string(data1) {
+		t.Fatal("the encoding is not deterministic")
+	}
+	var decoded Program
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		res, err := New().RunProgram(&decoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !res.SameAs(expected) {
+			t.Fatalf("%s != %s", res, expected)
+		}
+	}
+
+	var p1 Program
+	if err := p1.UnmarshalBinary(data[:len(data)-1]); err == nil {
+		t.Fatal("expected an error for truncated data")
+	}
+	broken := append([]byte{}, data...)
+	broken[5]++
+	if err := p1.UnmarshalBinary(broken); err == nil || !strings.Contains(err.Error(), "different version") {
+		t.Fatal(err)
+	}
+	if err := p1.UnmarshalBinary([]byte("garbage")); err == nil {
+		t.Fatal("expected an error")
+	}
+	damaged := append([]byte{}, data...)
+	damaged[len(damaged)/2] ^= 0x40
+	if err := p1.UnmarshalBinary(damaged); err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Fatal(err)
+	}
+}
+
+func TestProgramUnmarshalBinaryOperands(t *testing.T) {
+	for _, tc := range []struct {
+		src    string
+		modify func(code []instruction)
+	}{
+		{"var x = 'a'; x", func(code []instruction) {
+			for i, instr := range code {
+				if v, ok := instr.(loadVal); ok {
+					code[i] = v + 1
+				}
+			}
+		}},
+		{"while (Math.random() > 2) {}", func(code []instruction) {
+			for i, instr := range code {
+				if j, ok := instr.(jump); ok {
+					code[i] = j - 100
+				}
+			}
+		}},
+		{"function f(a) { let x = a; return () => x; } f(1)()", func(code []instruction) {
+			var f *Program
+			for _, instr := range code {
+				if nf, ok := instr.(*newFunc); ok {
+					f = nf.prg
+				}
+			}
+			for i, instr := range f.code {
+				switch instr.(type) {
+				case loadStack, loadStackLex:
+					f.code[i] = loadStackLex(1000)
+				case initStash:
+					f.code[i] = initStash(1000)
+				}
+			}
+		}},
+	} {
+		prg := MustCompile("test.js", tc.src, false)
+		if _, err := New().RunProgram(prg); err != nil {
+			t.Fatal(err)
+		}
+		tc.modify(prg.code)
+		data, err := prg.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var p Program
+		if err := p.UnmarshalBinary(data); err == nil || !strings.Contains(err.Error(), "invalid operand") {
+			t.Fatalf("%s: %v", tc.src, err)
+		}
+	}
+}
+
+func TestProgramMarshalBinaryStackTrace(t *testing.T) {
+	prg := MustCompile("trace.js", "function f() {\n\tthrow new Error('boom');\n}\nf();", false)
+	data, err := prg.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Program
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	_, err = New().RunProgram(&decoded)
+	ex, ok := err.(*Exception)
+	if !ok {
+		t.Fatal(err)
+	}
+	if s := ex.String(); !strings.Contains(s, "at f (trace.js:2:8(3))") {
+		t.Fatal(s)
+	}
+}
+
+// TestProgramCodecTypes checks that all the instruction types are known to the Program codec.
+func TestProgramCodecTypes(t *testing.T) {
+	known := make(map[string]bool)
+	for _, typ := range programCodecTypes {
+		known[typ.Name()] = true
+	}
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, ".", func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range pkgs["goja"].Files {
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || fn.Name.Name != "exec" || len(fn.Type.Params.List) != 1 {
+				continue
+			}
+			if param, ok := fn.Type.Params.List[0].Type.(*ast.StarExpr); !ok || param.X.(*ast.Ident).Name != "vm" {
+				continue
+			}
+			recv := fn.Recv.List[0].Type
+			if star, ok := recv.(*ast.StarExpr); ok {
+				recv = star.X
+			}
+			if name := recv.(*ast.Ident).Name; !known[name] {
+				t.Errorf("the instruction type %s is missing from programCodecTypes", name)
+			}
+		}
+	}
+	if reflect.TypeOf(Program{}).NumField() != 5 {
+		t.Error("the fields of Program have changed, check the Program codec")
+	}
+}
@@ -0,0 +1,500 @@
+package goja
+
+import (
+	"hash/maphash"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/dop251/goja/unistring"
+)
+
+const (
+	classRecord = "Record"
+	classTuple  = "Tuple"
+)
+
+var (
+	errRecordToNumber = typeError("Cannot convert a Record to a number")
+	errTupleToNumber  = typeError("Cannot convert a Tuple to a number")
+)
+
+// valueRecord is an immutable primitive consisting of string keys mapped to primitive values (see
+// https://github.com/tc39/proposal-record-tuple). The keys are sorted in code unit order.
+type valueRecord struct {
+	keys   []valueString
+	values []Value
+}
+
+// valueTuple is an immutable primitive consisting of an ordered list of primitive values.
+type valueTuple struct {
+	values []Value
+}
+
+type recordObject struct {
+	baseObject
+	value *valueRecord
+}
+
+type tupleObject struct {
+	baseObject
+	value *valueTuple
+}
+
+type recordEntries valueRecord
+
+func (e *recordEntries) Len() int {
+	return len(e.keys)
+}
+
+func (e *recordEntries) Less(i, j int) bool {
+	return e.keys[i].compareTo(e.keys[j]) < 0
+}
+
+func (e *recordEntries) Swap(i, j int) {
+	e.keys[i], e.keys[j] = e.keys[j], e.keys[i]
+	e.values[i], e.values[j] = e.values[j], e.values[i]
+}
+
+// newRecord creates a Record from the parallel lists of keys and values. Duplicate keys are resolved in favour of
+// the later value.
+func newRecord(keys []valueString, values []Value) *valueRecord {
+	rec := &valueRecord{keys: keys, values: values}
+	sort.Stable((*recordEntries)(rec))
+	j := 0
+	for i := range rec.keys {
+		if j > 0 && rec.keys[j-1].SameAs(rec.keys[i]) {
+			rec.values[j-1] = rec.values[i]
+			continue
+		}
+		rec.keys[j], rec.values[j] = rec.keys[i], rec.values[i]
+		j++
+	}
+	rec.keys, rec.values = rec.keys[:j], rec.values[:j]
+	return rec
+}
+
+func (rec *valueRecord) index(name unistring.String) int {
+	key := stringValueFromRaw(name)
+	i := sort.Search(len(rec.keys), func(i int) bool {
+		return rec.keys[i].compareTo(key) >= 0
+	})
+	if i < len(rec.keys) && rec.keys[i].SameAs(key) {
+		return i
+	}
+	return -1
+}
+
+func sameValueZero(x, y Value) bool {
+	if x.StrictEquals(y) {
+		return true
+	}
+	if xf, ok := x.(valueFloat); ok {
+		if yf, ok := y.(valueFloat); ok {
+			return xf != xf && yf != yf
+		}
+	}
+	return false
+}
+
+func compositeValuesEqual(a, b []Value, eq func(x, y Value) bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i, v := range a {
+		if !eq(v, b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func compositeHash(hasher *maphash.Hash, hashes []uint64) uint64 {
+	var buf [8]byte
+	for _, h := range hashes {
+		for i := range buf {
+			buf[i] = byte(h >> (8 * i))
+		}
+		_, _ = hasher.Write(buf[:])
+	}
+	h := hasher.Sum64()
+	hasher.Reset()
+	return h
+}
+
+func (rec *valueRecord) equals(other *valueRecord, eq func(x, y Value) bool) bool {
+	if rec == other {
+		return true
+	}
+	if len(rec.keys) != len(other.keys) {
+		return false
+	}
+	for i, k := range rec.keys {
+		if !k.SameAs(other.keys[i]) {
+			return false
+		}
+	}
+	return compositeValuesEqual(rec.values, other.values, eq)
+}
+
+func (rec *valueRecord) ToInteger() int64 {
+	panic(errRecordToNumber)
+}
+
+func (rec *valueRecord) toString() valueString {
+	return asciiString("[object Record]")
+}
+
+func (rec *valueRecord) string() unistring.String {
+	return rec.toString().string()
+}
+
+func (rec *valueRecord) ToString() Value {
+	return rec.toString()
+}
+
+func (rec *valueRecord) String() string {
+	return rec.toString().String()
+}
+
+func (rec *valueRecord) ToFloat() float64 {
+	panic(errRecordToNumber)
+}
+
+func (rec *valueRecord) ToNumber() Value {
+	panic(errRecordToNumber)
+}
+
+func (rec *valueRecord) ToBoolean() bool {
+	return true
+}
+
+func (rec *valueRecord) ToObject(r *Runtime) *Object {
+	return rec.baseObject(r)
+}
+
+func (rec *valueRecord) SameAs(other Value) bool {
+	if o, ok := other.(*valueRecord); ok {
+		return rec.equals(o, Value.SameAs)
+	}
+	return false
+}
+
+func (rec *valueRecord) Equals(other Value) bool {
+	return rec.StrictEquals(other)
+}
+
+func (rec *valueRecord) StrictEquals(other Value) bool {
+	if o, ok := other.(*valueRecord); ok {
+		return rec.equals(o, sameValueZero)
+	}
+	return false
+}
+
+func (rec *valueRecord) Export() interface{} {
+	m := make(map[string]interface{}, len(rec.keys))
+	for i, k := range rec.keys {
+		m[k.String()] = rec.values[i].Export()
+	}
+	return m
+}
+
+func (rec *valueRecord) ExportType() reflect.Type {
+	return reflectTypeMap
+}
+
+func (rec *valueRecord) baseObject(r *Runtime) *Object {
+	v := &Object{runtime: r}
+	o := &recordObject{value: rec}
+	o.class = classRecord
+	o.val = v
+	v.self = o
+	o.init()
+	return v
+}
+
+func (rec *valueRecord) hash(hasher *maphash.Hash) uint64 {
+	hashes := make([]uint64, 0, 2*len(rec.keys)+1)
+	hashes = append(hashes, hashRecord)
+	for i, k := range rec.keys {
+		hashes = append(hashes, k.hash(hasher), rec.values[i].hash(hasher))
+	}
+	return compositeHash(hasher, hashes)
+}
+
+func (t *valueTuple) ToInteger() int64 {
+	panic(errTupleToNumber)
+}
+
+func (t *valueTuple) toString() valueString {
+	var b valueStringBuilder
+	for i, v := range t.values {
+		if i > 0 {
+			b.WriteRune(',')
+		}
+		if v != _undefined && v != _null {
+			b.WriteString(v.toString())
+		}
+	}
+	return b.String()
+}
+
+func (t *valueTuple) string() unistring.String {
+	return t.toString().string()
+}
+
+func (t *valueTuple) ToString() Value {
+	return t.toString()
+}
+
+func (t *valueTuple) String() string {
+	return t.toString().String()
+}
+
+func (t *valueTuple) ToFloat() float64 {
+	panic(errTupleToNumber)
+}
+
+func (t *valueTuple) ToNumber() Value {
+	panic(errTupleToNumber)
+}
+
+func (t *valueTuple) ToBoolean() bool {
+	return true
+}
+
+func (t *valueTuple) ToObject(r *Runtime) *Object {
+	return t.baseObject(r)
+}
+
+func (t *valueTuple) SameAs(other Value) bool {
+	if o, ok := other.(*valueTuple); ok {
+		return t == o || compositeValuesEqual(t.values, o.values, Value.SameAs)
+	}
+	return false
+}
+
+func (t *valueTuple) Equals(other Value) bool {
+	return t.StrictEquals(other)
+}
+
+func (t *valueTuple) StrictEquals(other Value) bool {
+	if o, ok := other.(*valueTuple); ok {
+		return t == o || compositeValuesEqual(t.values, o.values, sameValueZero)
+	}
+	return false
+}
+
+func (t *valueTuple) Export() interface{} {
+	a := make([]interface{}, len(t.values))
+	for i, v := range t.values {
+		a[i] = v.Export()
+	}
+	return a
+}
+
+func (t *valueTuple) ExportType() reflect.Type {
+	return reflectTypeArray
+}
+
+func (t *valueTuple) baseObject(r *Runtime) *Object {
+	v := &Object{runtime: r}
+	o := &tupleObject{value: t}
+	o.class = classTuple
+	o.val = v
+	v.self = o
+	o.prototype = r.global.TuplePrototype
+	o.init()
+	return v
+}
+
+func (t *valueTuple) hash(hasher *maphash.Hash) uint64 {
+	hashes := make([]uint64, 0, len(t.values)+1)
+	hashes = append(hashes, hashTuple)
+	for _, v := range t.values {
+		hashes = append(hashes, v.hash(hasher))
+	}
+	return compositeHash(hasher, hashes)
+}
+
+func (o *recordObject) getStr(name unistring.String, receiver Value) Value {
+	if i := o.value.index(name); i >= 0 {
+		return o.value.values[i]
+	}
+	return o.baseObject.getStr(name, receiver)
+}
+
+func (o *recordObject) getOwnPropStr(name unistring.String) Value {
+	if i := o.value.index(name); i >= 0 {
+		return &valueProperty{
+			value:      o.value.values[i],
+			enumerable: true,
+		}
+	}
+	return o.baseObject.getOwnPropStr(name)
+}
+
+func (o *recordObject) setOwnStr(name unistring.String, val Value, throw bool) bool {
+	if i := o.value.index(name); i >= 0 {
+		o.val.runtime.typeErrorResult(throw, "Cannot assign to read only property '%s' of a Record", name)
+		return false
+	}
+	return o.baseObject.setOwnStr(name, val, throw)
+}
+
+func (o *recordObject) setForeignStr(name unistring.String, val, receiver Value, throw bool) (bool, bool) {
+	return o._setForeignStr(name, o.getOwnPropStr(name), val, receiver, throw)
+}
+
+func (o *recordObject) setForeignIdx(idx valueInt, val, receiver Value, throw bool) (bool, bool) {
+	return o._setForeignIdx(idx, o.getOwnPropStr(idx.string()), val, receiver, throw)
+}
+
+func (o *recordObject) defineOwnPropertyStr(name unistring.String, descr PropertyDescriptor, throw bool) bool {
+	if i := o.value.index(name); i >= 0 {
+		_, ok := o._defineOwnProperty(name, &valueProperty{value: o.value.values[i], enumerable: true}, descr, throw)
+		return ok
+	}
+	return o.baseObject.defineOwnPropertyStr(name, descr, throw)
+}
+
+func (o *recordObject) deleteStr(name unistring.String, throw bool) bool {
+	if i := o.value.index(name); i >= 0 {
+		o.val.runtime.typeErrorResult(throw, "Cannot delete property '%s' of a Record", name)
+		return false
+	}
+	return o.baseObject.deleteStr(name, throw)
+}
+
+func (o *recordObject) hasOwnPropertyStr(name unistring.String) bool {
+	return o.value.index(name) >= 0 || o.baseObject.hasOwnPropertyStr(name)
+}
+
+type recordPropIter struct {
+	o   *recordObject
+	idx int
+}
+
+func (i *recordPropIter) next() (propIterItem, iterNextFunc) {
+	if i.idx < len(i.o.value.keys) {
+		name := i.o.value.keys[i.idx]
+		i.idx++
+		return propIterItem{name: name, enumerable: _ENUM_TRUE}, i.next
+	}
+	return i.o.baseObject.iterateStringKeys()()
+}
+
+func (o *recordObject) iterateStringKeys() iterNextFunc {
+	return (&recordPropIter{o: o}).next
+}
+
+func (o *recordObject) stringKeys(all bool, accum []Value) []Value {
+	for _, k := range o.value.keys {
+		accum = append(accum, k)
+	}
+	return o.baseObject.stringKeys(all, accum)
+}
+
+func (o *recordObject) export(*objectExportCtx) interface{} {
+	return o.value.Export()
+}
+
+func (o *recordObject) exportType() reflect.Type {
+	return o.value.ExportType()
+}
+
+func (o *tupleObject) getStr(name unistring.String, receiver Value) Value {
+	if i := strToGoIdx(name); i >= 0 && i < len(o.value.values) {
+		return o.value.values[i]
+	}
+	return o.baseObject.getStr(name, receiver)
+}
+
+func (o *tupleObject) getIdx(idx valueInt, receiver Value) Value {
+	if i := int64(idx); i >= 0 && i < int64(len(o.value.values)) {
+		return o.value.values[i]
+	}
+	return o.baseObject.getStr(idx.string(), receiver)
+}
+
+func (o *tupleObject) getOwnPropStr(name unistring.String) Value {
+	if i := strToGoIdx(name); i >= 0 && i < len(o.value.values) {
+		return &valueProperty{
+			value:      o.value.values[i],
+			enumerable: true,
+		}
+	}
+	return o.baseObject.getOwnPropStr(name)
+}
+
+func (o *tupleObject) setOwnStr(name unistring.String, val Value, throw bool) bool {
+	if i := strToGoIdx(name); i >= 0 && i < len(o.value.values) {
+		o.val.runtime.typeErrorResult(throw, "Cannot assign to read only property '%d' of a Tuple", i)
+		return false
+	}
+	return o.baseObject.setOwnStr(name, val, throw)
+}
+
+func (o *tupleObject) setForeignStr(name unistring.String, val, receiver Value, throw bool) (bool, bool) {
+	return o._setForeignStr(name, o.getOwnPropStr(name), val, receiver, throw)
+}
+
+func (o *tupleObject) setForeignIdx(idx valueInt, val, receiver Value, throw bool) (bool, bool) {
+	return o._setForeignIdx(idx, o.getOwnPropStr(idx.string()), val, receiver, throw)
+}
+
+func (o *tupleObject) defineOwnPropertyStr(name unistring.String, descr PropertyDescriptor, throw bool) bool {
+	if i := strToGoIdx(name); i >= 0 && i < len(o.value.values) {
+		_, ok := o._defineOwnProperty(name, &valueProperty{value: o.value.values[i], enumerable: true}, descr, throw)
+		return ok
+	}
+	return o.baseObject.defineOwnPropertyStr(name, descr, throw)
+}
+
+func (o *tupleObject) deleteStr(name unistring.String, throw bool) bool {
+	if i := strToGoIdx(name); i >= 0 && i < len(o.value.values) {
+		o.val.runtime.typeErrorResult(throw, "Cannot delete property '%d' of a Tuple", i)
+		return false
+	}
+	return o.baseObject.deleteStr(name, throw)
+}
+
+func (o *tupleObject) hasOwnPropertyStr(name unistring.String) bool {
+	if i := strToGoIdx(name); i >= 0 && i < len(o.value.values) {
+		return true
+	}
+	return o.baseObject.hasOwnPropertyStr(name)
+}
+
+type tuplePropIter struct {
+	o   *tupleObject
+	idx int
+}
+
+func (i *tuplePropIter) next() (propIterItem, iterNextFunc) {
+	if i.idx < len(i.o.value.values) {
+		name := strconv.Itoa(i.idx)
+		i.idx++
+		return propIterItem{name: asciiString(name), enumerable: _ENUM_TRUE}, i.next
+	}
+	return i.o.baseObject.iterateStringKeys()()
+}
+
+func (o *tupleObject) iterateStringKeys() iterNextFunc {
+	return (&tuplePropIter{o: o}).next
+}
+
+func (o *tupleObject) stringKeys(all bool, accum []Value) []Value {
+	for i := range o.value.values {
+		accum = append(accum, asciiString(strconv.Itoa(i)))
+	}
+	return o.baseObject.stringKeys(all, accum)
+}
+
+func (o *tupleObject) export(*objectExportCtx) interface{} {
+	return o.value.Export()
+}
+
+func (o *tupleObject) exportType() reflect.Type {
+	return o.value.ExportType()
+}
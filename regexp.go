@@ -8,6 +8,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"time"
 	"unicode/utf16"
 )
 
@@ -21,6 +22,10 @@ type regexp2MatchCache struct {
 type regexp2Wrapper struct {
 	rx    *regexp2.Regexp
 	cache *regexp2MatchCache
+
+	// the Runtime which throws the error if a match times out, set if rx has its own match timeout
+	// (see Runtime.SetRegExpTimeout)
+	timeoutRuntime *Runtime
 }
 
 type regexpWrapper regexp.Regexp
@@ -61,7 +66,14 @@ func (rd *arrayRuneReader) ReadRune() (r rune, size int, err error) {
 type regexpPattern struct {
 	src string
 
-	global, ignoreCase, multiline, sticky, unicode bool
+	global, ignoreCase, multiline, dotAll, sticky, unicode bool
+
+	// names of the capturing groups ("" for unnamed ones), nil if there are no named groups
+	groupNames []string
+
+	// the match timeout of the regexp2 engine and the Runtime that throws the error when it's exceeded
+	timeout        time.Duration
+	timeoutRuntime *Runtime
 
 	regexpWrapper  *regexpWrapper
 	regexp2Wrapper *regexp2Wrapper
@@ -87,6 +99,13 @@ func (p *regexpPattern) createRegexp2() {
 	if p.regexp2Wrapper != nil {
 		return
 	}
+	if p.timeout > 0 {
+		p.regexp2Wrapper = &regexp2Wrapper{
+			rx:             p.timeoutRuntime.timedRegexp2(p),
+			timeoutRuntime: p.timeoutRuntime,
+		}
+		return
+	}
 	rx, err := compileRegexp2(p.src, p.multiline, p.ignoreCase)
 	if err != nil {
 		// At this point the regexp should have been successfully converted to re2, if it fails now, it's a bug.
@@ -95,6 +114,49 @@ func (p *regexpPattern) createRegexp2() {
 	p.regexp2Wrapper = rx
 }
 
+// maxTimedRegexps is the number of the compiled regular expressions with a match timeout a Runtime keeps for reuse.
+const maxTimedRegexps = 1000
+
+type timedRegexpKey struct {
+	src                   string
+	multiline, ignoreCase bool
+}
+
+// timedRegexp2 returns the regexp2 program of the pattern with the match timeout set by SetRegExpTimeout. The
+// program compiled with the pattern is shared between its clones (and between Runtimes if it comes from a Program),
+// so it can't be modified. Instead, the programs with the timeout are compiled once per Runtime, e.g. a regular
+// expression literal evaluated in a loop is only compiled again on the first iteration.
+func (r *Runtime) timedRegexp2(p *regexpPattern) *regexp2.Regexp {
+	key := timedRegexpKey{src: p.src, multiline: p.multiline, ignoreCase: p.ignoreCase}
+	if rx := r.timedRegexps[key]; rx != nil {
+		return rx
+	}
+	w, err := compileRegexp2(p.src, p.multiline, p.ignoreCase)
+	if err != nil {
+		// At this point the regexp should have been successfully converted to re2, if it fails now, it's a bug.
+		panic(err)
+	}
+	w.rx.MatchTimeout = p.timeout
+	if r.timedRegexps == nil || len(r.timedRegexps) >= maxTimedRegexps {
+		r.timedRegexps = make(map[timedRegexpKey]*regexp2.Regexp)
+	}
+	r.timedRegexps[key] = w.rx
+	return w.rx
+}
+
+// setTimeout limits the time of each match performed by the regexp2 engine, see Runtime.timedRegexp2.
+func (p *regexpPattern) setTimeout(r *Runtime, timeout time.Duration) {
+	if p.timeout == timeout && p.timeoutRuntime == r {
+		return
+	}
+	p.timeout = timeout
+	p.timeoutRuntime = r
+	if p.regexp2Wrapper != nil {
+		p.regexp2Wrapper = nil
+		p.createRegexp2()
+	}
+}
+
 func buildUTF8PosMap(s unicodeString) (positionMap, string) {
 	pm := make(positionMap, 0, s.length())
 	rd := s.reader()
@@ -175,8 +237,13 @@ func (p *regexpPattern) clone() *regexpPattern {
 		global:     p.global,
 		ignoreCase: p.ignoreCase,
 		multiline:  p.multiline,
+		dotAll:     p.dotAll,
 		sticky:     p.sticky,
 		unicode:    p.unicode,
+		groupNames: p.groupNames,
+
+		timeout:        p.timeout,
+		timeoutRuntime: p.timeoutRuntime,
 	}
 	if p.regexpWrapper != nil {
 		ret.regexpWrapper = p.regexpWrapper.clone()
@@ -225,6 +292,7 @@ func (r *regexp2Wrapper) findUTF16Cached(s valueString, start int, doCache bool)
 	} else {
 		r.cache = nil
 	}
+	r.checkError(err)
 	return
 }
 
@@ -289,7 +357,7 @@ func (r *regexp2Wrapper) findUnicodeCached(s valueString, start int, doCache boo
 	} else {
 		r.cache = nil
 	}
-
+	r.checkError(err)
 	return
 }
 
@@ -351,6 +419,7 @@ func (r *regexp2Wrapper) findAllSubmatchIndexUTF16(s valueString, start, limit i
 		}
 		match, err = wrapped.FindNextMatch(match)
 		if err != nil {
+			r.checkError(err)
 			return nil
 		}
 	}
@@ -430,6 +499,7 @@ func (r *regexp2Wrapper) findAllSubmatchIndexUnicode(s unicodeString, start, lim
 		results = append(results, result)
 		match, err = wrapped.FindNextMatch(match)
 		if err != nil {
+			r.checkError(err)
 			return nil
 		}
 	}
@@ -447,9 +517,18 @@ func (r *regexp2Wrapper) findAllSubmatchIndex(s valueString, start, limit int, s
 	return r.findAllSubmatchIndexUTF16(a, start, limit, sticky)
 }
 
+// checkError throws a RangeError if a match has timed out, which is the only error returned by the regexp2 matcher.
+func (r *regexp2Wrapper) checkError(err error) {
+	if err != nil && r.timeoutRuntime != nil {
+		rt := r.timeoutRuntime
+		panic(rt.newError(rt.global.RangeError, "Regular expression execution exceeded the time limit of %v", r.rx.MatchTimeout))
+	}
+}
+
 func (r *regexp2Wrapper) clone() *regexp2Wrapper {
 	return &regexp2Wrapper{
-		rx: r.rx,
+		rx:             r.rx,
+		timeoutRuntime: r.timeoutRuntime,
 	}
 }
 
@@ -520,6 +599,7 @@ func (r *regexpObject) execResultToArray(target valueString, result []int) Value
 	match := r.val.runtime.newArrayValues(valueArray)
 	match.self.setOwnStr("input", target, false)
 	match.self.setOwnStr("index", intToValue(int64(matchIndex)), false)
+	match.self.setOwnStr("groups", r.val.runtime.newGroupsObject(r.pattern.groupNames, valueArray), false)
 	return match
 }
 
@@ -641,3 +721,27 @@ func (r *regexpObject) setOwnSym(name *Symbol, value Value, throw bool) bool {
 	}
 	return res
 }
+
+// flags returns the flags the pattern has been compiled with.
+func (p *regexpPattern) flags() string {
+	var sb strings.Builder
+	if p.global {
+		sb.WriteByte('g')
+	}
+	if p.ignoreCase {
+		sb.WriteByte('i')
+	}
+	if p.multiline {
+		sb.WriteByte('m')
+	}
+	if p.dotAll {
+		sb.WriteByte('s')
+	}
+	if p.unicode {
+		sb.WriteByte('u')
+	}
+	if p.sticky {
+		sb.WriteByte('y')
+	}
+	return sb.String()
+}
@@ -416,8 +416,10 @@ func TestRegexpConsecutiveMatchCache(t *testing.T) {
 		];
 		expectedMatches[0].index = 0;
 		expectedMatches[0].input = 'test1test2';
+		expectedMatches[0].groups = undefined;
 		expectedMatches[1].index = 5;
 		expectedMatches[1].input = 'test1test2';
+		expectedMatches[1].groups = undefined;
 
 		assert(deepEqual(matches, expectedMatches), "#1");
 
@@ -432,6 +434,7 @@ func TestRegexpConsecutiveMatchCache(t *testing.T) {
 		];
 		expectedMatch.index = 1;
 		expectedMatch.input = ' test5';
+		expectedMatch.groups = undefined;
 		assert(deepEqual(match, expectedMatch), "#2");
 		assert.sameValue(regex.lastIndex, 6, "#3");
 
@@ -445,6 +448,7 @@ func TestRegexpConsecutiveMatchCache(t *testing.T) {
 		];
 		expectedMatch.index = 6;
 		expectedMatch.input = ' test5test6';
+		expectedMatch.groups = undefined;
 		assert(deepEqual(match, expectedMatch), "#4");
 		assert.sameValue(regex.lastIndex, 11, "#5");
 
@@ -508,8 +512,10 @@ func TestRegexpMatchAll(t *testing.T) {
 		];
 		expectedMatches[0].index = 0;
 		expectedMatches[0].input = 'test1test2';
+		expectedMatches[0].groups = undefined;
 		expectedMatches[1].index = 5;
 		expectedMatches[1].input = 'test1test2';
+		expectedMatches[1].groups = undefined;
 
 		assert(deepEqual(matches, expectedMatches), "#1");
 		assert.sameValue(regex.lastIndex, 0, "#1 lastIndex");
@@ -530,6 +536,7 @@ func TestRegexpMatchAll(t *testing.T) {
 		];
 		expectedMatches[0].index = 1;
 		expectedMatches[0].input = ' test5';
+		expectedMatches[0].groups = undefined;
 		assert(deepEqual(matches, expectedMatches), "#2");
 		assert.sameValue(regex.lastIndex, 0, "#2 lastIndex");
 
@@ -555,8 +562,10 @@ func TestRegexpMatchAll(t *testing.T) {
 		];
 		expectedMatches[0].index = 1;
 		expectedMatches[0].input = ' test5test6';
+		expectedMatches[0].groups = undefined;
 		expectedMatches[1].index = 6;
 		expectedMatches[1].input = ' test5test6';
+		expectedMatches[1].groups = undefined;
 		assert(deepEqual(matches, expectedMatches), "#3");
 		assert.sameValue(regex.lastIndex, 0, "#3 lastindex");
 	});
@@ -689,6 +698,75 @@ func TestRegexpLookbehindAssertion(t *testing.T) {
 	testScriptWithTestLib(SCRIPT, _undefined, t)
 }
 
+func TestRegexpNamedGroups(t *testing.T) {
+	const SCRIPT = `
+	var m = /(?<year>\d{4})-(?<month>\d{2})(?<day>-\d{2})?/.exec("on 2020-05!");
+	assert.sameValue(m.length, 4, "length");
+	assert.sameValue(Object.getPrototypeOf(m.groups), null, "groups proto");
+	assert.sameValue(m.groups.year, "2020", "year");
+	assert.sameValue(m.groups.month, "05", "month");
+	assert(m.groups.hasOwnProperty === undefined && "day" in m.groups, "day is present");
+	assert.sameValue(m.groups.day, undefined, "day");
+	assert.sameValue(m[1], "2020", "numbered capture");
+
+	m = /(a)/.exec("a");
+	assert(m.hasOwnProperty("groups"), "groups without named groups");
+	assert.sameValue(m.groups, undefined, "groups value without named groups");
+
+	assert(/(?<c>.)\k<c>/.test("xx"), "backreference #1");
+	assert(!/(?<c>.)\k<c>/.test("xy"), "backreference #2");
+	assert(/(?<c>[(?<x>])x/.test("<x"), "group syntax inside a class");
+
+	assert.throws(SyntaxError, function() { new RegExp("(?<a>x)(?<a>y)") }, "duplicate name");
+	assert.throws(SyntaxError, function() { new RegExp("(?<a>x)\\k<b>") }, "unknown reference");
+	assert.throws(SyntaxError, function() { new RegExp("(?<1a>x)") }, "invalid name");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestRegexpNamedGroupsReplace(t *testing.T) {
+	const SCRIPT = `
+	var re = /(?<y>\d+)-(?<m>\d+)/;
+	assert.sameValue("2020-05".replace(re, "$<m>/$<y>"), "05/2020", "fast path");
+	assert.sameValue("2020-05".replace(re, "$<zz>|$<m"), "|$<m", "unknown and unterminated names");
+	assert.sameValue("2020-05".replace(/(\d+)-(\d+)/, "$<m>"), "$<m>", "no named groups");
+	assert.sameValue("2020-05".replace(re, function(match, y, m, pos, s, groups) {
+		return groups.m + "|" + groups.y + "|" + pos + "|" + s;
+	}), "05|2020|0|2020-05", "replacer function");
+
+	class R extends RegExp { exec(s) { return super.exec(s); } }
+	assert.sameValue("2020-05".replace(new R(re.source), "$<m>/$<y>"), "05/2020", "generic path");
+	assert.sameValue("2020-05".replace(new R(re.source), function() {
+		var groups = arguments[arguments.length - 1];
+		return groups.m;
+	}), "05", "generic path replacer function");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func TestRegexpDotAll(t *testing.T) {
+	const SCRIPT = `
+	assert(!/^.$/.test("\n"), "#1");
+	assert(/^.$/s.test("\n"), "#2");
+	assert(/^.$/s.test("\r") && /^.$/s.test("\u2028") && /^.$/s.test("\u2029"), "#3");
+	assert(/^(?=\n).\.[.]$/s.test("\n.."), "regexp2");
+	assert(!/^[.]$/s.test("\n"), "dot in a class");
+	assert(!/^\.$/s.test("\n"), "escaped dot");
+
+	var re = /a/s;
+	assert.sameValue(re.dotAll, true, "dotAll");
+	assert.sameValue(/a/.dotAll, false, "dotAll without the flag");
+	assert.sameValue(RegExp.prototype.dotAll, undefined, "dotAll on the prototype");
+	assert.sameValue(new RegExp("a", "ysumgi").flags, "gimsuy", "flags");
+	assert.sameValue(String(new RegExp("a", "ysumgi")), "/a/gimsuy", "toString");
+	assert.throws(SyntaxError, function() { new RegExp("a", "ss") }, "duplicate flag");
+	assert.throws(TypeError, function() {
+		Object.getOwnPropertyDescriptor(RegExp.prototype, "dotAll").get.call({});
+	}, "incompatible receiver");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
 func TestRegexpInvalidUTF8(t *testing.T) {
 	vm := New()
 	// Note that normally vm.ToValue() would replace invalid UTF-8 sequences with RuneError
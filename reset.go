@@ -0,0 +1,49 @@
+package goja
+
+import "sync"
+
+// Reset returns the Runtime to the state of a Runtime created by New(): the built-in objects are re-created, the
+// global variables, the pending jobs, the interrupt flag and the symbol registry are cleared, and so is everything
+// configured by the Set*, Enable* and Register* methods. This makes it possible to reuse a Runtime (and the
+// references to it held by Go code) without any state of the previous scripts being visible to the next ones.
+//
+// The objects created before the call must not be used afterwards, neither must the Loop created by NewLoop, it's
+// detached from the Runtime (a new one can be created). Reset must not be called while the Runtime is running, and
+// no other goroutine may be using it (including calling Interrupt) during the call.
+func (r *Runtime) Reset() {
+	*r = Runtime{}
+	r.init()
+}
+
+// RuntimePool keeps Runtimes initialised by a RuntimeInitializer for reuse, e.g. by the handlers of a server that
+// runs the scripts of different tenants. Runtimes are reset (see Runtime.Reset) and re-initialised when they are
+// returned to the pool, so the state of a script never leaks into the next one running on the same Runtime. A
+// RuntimePool is safe for concurrent use.
+type RuntimePool struct {
+	initializer *RuntimeInitializer
+	pool        sync.Pool
+}
+
+// NewRuntimePool creates a RuntimePool of the Runtimes initialised by ri.
+func NewRuntimePool(ri *RuntimeInitializer) *RuntimePool {
+	return &RuntimePool{
+		initializer: ri,
+	}
+}
+
+// Get returns a Runtime from the pool, or a new one if the pool is empty.
+func (p *RuntimePool) Get() (*Runtime, error) {
+	if r, ok := p.pool.Get().(*Runtime); ok {
+		return r, nil
+	}
+	return p.initializer.New()
+}
+
+// Put resets r, re-initialises it and returns it to the pool. r must not be used by the caller afterwards. If the
+// initialisation fails, r is discarded.
+func (p *RuntimePool) Put(r *Runtime) {
+	r.Reset()
+	if p.initializer.init(r) == nil {
+		p.pool.Put(r)
+	}
+}
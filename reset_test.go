@@ -0,0 +1,60 @@
+package goja
+
+import "testing"
+
+func TestRuntimeReset(t *testing.T) {
+	r := New()
+	r.SetFieldNameMapper(UncapFieldNameMapper())
+	_, err := r.RunString(`
+	var leaked = 1;
+	Array.prototype.leaked = true;
+	Symbol.for("leaked");
+	Promise.resolve().then(() => { globalThis.fromJob = true; });
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Interrupt("halt")
+
+	r.Reset()
+	res, err := r.RunString(`[typeof leaked, [].leaked, typeof fromJob].join()`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := res.String(); s != "undefined,,undefined" {
+		t.Fatal(s)
+	}
+	if len(r.symbolRegistry) != 0 {
+		t.Fatal("the symbol registry has not been cleared")
+	}
+	r.Set("s", struct{ Field int }{1})
+	res, err = r.RunString(`s.Field`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.ToInteger() != 1 {
+		t.Fatal(res)
+	}
+}
+
+func TestRuntimePool(t *testing.T) {
+	prelude := MustCompile("prelude.js", `var visits = 0;`, false)
+	pool := NewRuntimePool(NewRuntimeInitializer(func(r *Runtime) error {
+		return r.Set("tenant", "none")
+	}, prelude))
+	for i := 0; i < 3; i++ {
+		r, err := pool.Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := r.RunString(`visits++; Object.prototype.secret = 42; [tenant, visits].join()`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := res.String(); s != "none,1" {
+			t.Fatal(s)
+		}
+		r.Set("tenant", "other")
+		pool.Put(r)
+	}
+}
@@ -2,18 +2,23 @@ package goja
 
 import (
 	"bytes"
+	gocontext "context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"go/ast"
 	"hash/maphash"
 	"math"
+	"math/big"
 	"math/bits"
 	"math/rand"
+	"net/http"
 	"reflect"
 	"runtime"
 	"strconv"
 	"time"
 
+	"github.com/dlclark/regexp2"
 	"golang.org/x/text/collate"
 
 	js_ast "github.com/dop251/goja/ast"
@@ -33,7 +38,10 @@ var (
 	typeValue    = reflect.TypeOf((*Value)(nil)).Elem()
 	typeObject   = reflect.TypeOf((*Object)(nil))
 	typeTime     = reflect.TypeOf(time.Time{})
+	typeDuration = reflect.TypeOf(time.Duration(0))
 	typeBytes    = reflect.TypeOf(([]byte)(nil))
+	typeContext  = reflect.TypeOf((*gocontext.Context)(nil)).Elem()
+	typeBigFloat = reflect.TypeOf((*big.Float)(nil))
 )
 
 type iterationKind int
@@ -53,6 +61,7 @@ type global struct {
 	Function *Object
 	String   *Object
 	Number   *Object
+	BigInt   *Object
 	Boolean  *Object
 	RegExp   *Object
 	Date     *Object
@@ -74,26 +83,83 @@ type global struct {
 	Int32Array        *Object
 	Float32Array      *Object
 	Float64Array      *Object
+	BigInt64Array     *Object
+	BigUint64Array    *Object
 
 	WeakSet *Object
 	WeakMap *Object
+	WeakRef *Object
 	Map     *Object
 	Set     *Object
 
-	Error          *Object
-	AggregateError *Object
-	TypeError      *Object
-	ReferenceError *Object
-	SyntaxError    *Object
-	RangeError     *Object
-	EvalError      *Object
-	URIError       *Object
+	DisposableStack      *Object
+	AsyncDisposableStack *Object
+
+	Record *Object
+	Tuple  *Object
+
+	TextEncoder *Object
+	TextDecoder *Object
+
+	URL             *Object
+	URLSearchParams *Object
+
+	Performance        *Object
+	PerformanceEntry   *Object
+	PerformanceMark    *Object
+	PerformanceMeasure *Object
+
+	Crypto *Object
+
+	Event       *Object
+	CustomEvent *Object
+	EventTarget *Object
+
+	Headers  *Object
+	Request  *Object
+	Response *Object
+
+	ReadableStream                  *Object
+	ReadableStreamDefaultReader     *Object
+	ReadableStreamDefaultController *Object
+	WritableStream                  *Object
+	WritableStreamDefaultWriter     *Object
+	WritableStreamDefaultController *Object
+
+	Worker         *Object
+	MessageChannel *Object
+	MessagePort    *Object
+
+	TemporalDuration       *Object
+	TemporalInstant        *Object
+	TemporalPlainDate      *Object
+	TemporalPlainTime      *Object
+	TemporalPlainDateTime  *Object
+	TemporalPlainYearMonth *Object
+	TemporalPlainMonthDay  *Object
+	TemporalZonedDateTime  *Object
+
+	IntlCollator           *Object
+	IntlListFormat         *Object
+	IntlPluralRules        *Object
+	IntlRelativeTimeFormat *Object
+
+	Error           *Object
+	AggregateError  *Object
+	SuppressedError *Object
+	TypeError       *Object
+	ReferenceError  *Object
+	SyntaxError     *Object
+	RangeError      *Object
+	EvalError       *Object
+	URIError        *Object
 
 	GoError *Object
 
 	ObjectPrototype   *Object
 	ArrayPrototype    *Object
 	NumberPrototype   *Object
+	BigIntPrototype   *Object
 	StringPrototype   *Object
 	BooleanPrototype  *Object
 	FunctionPrototype *Object
@@ -106,27 +172,85 @@ type global struct {
 	TypedArrayPrototype  *Object
 	WeakSetPrototype     *Object
 	WeakMapPrototype     *Object
+	WeakRefPrototype     *Object
 	MapPrototype         *Object
 	SetPrototype         *Object
 	PromisePrototype     *Object
 
+	DisposableStackPrototype      *Object
+	AsyncDisposableStackPrototype *Object
+
+	TuplePrototype *Object
+
+	TextEncoderPrototype *Object
+	TextDecoderPrototype *Object
+
+	URLPrototype                     *Object
+	URLSearchParamsPrototype         *Object
+	URLSearchParamsIteratorPrototype *Object
+
+	PerformanceEntryPrototype   *Object
+	PerformanceMarkPrototype    *Object
+	PerformanceMeasurePrototype *Object
+
+	EventPrototype       *Object
+	CustomEventPrototype *Object
+	EventTargetPrototype *Object
+
+	HeadersPrototype         *Object
+	HeadersIteratorPrototype *Object
+	RequestPrototype         *Object
+	ResponsePrototype        *Object
+
+	ReadableStreamPrototype                  *Object
+	ReadableStreamAsyncIteratorPrototype     *Object
+	ReadableStreamDefaultReaderPrototype     *Object
+	ReadableStreamDefaultControllerPrototype *Object
+	WritableStreamPrototype                  *Object
+	WritableStreamDefaultWriterPrototype     *Object
+	WritableStreamDefaultControllerPrototype *Object
+
+	WorkerPrototype         *Object
+	MessageChannelPrototype *Object
+	MessagePortPrototype    *Object
+
 	AsyncFunctionPrototype *Object
 
-	IteratorPrototype             *Object
-	ArrayIteratorPrototype        *Object
-	MapIteratorPrototype          *Object
-	SetIteratorPrototype          *Object
-	StringIteratorPrototype       *Object
-	RegExpStringIteratorPrototype *Object
-
-	ErrorPrototype          *Object
-	AggregateErrorPrototype *Object
-	TypeErrorPrototype      *Object
-	SyntaxErrorPrototype    *Object
-	RangeErrorPrototype     *Object
-	ReferenceErrorPrototype *Object
-	EvalErrorPrototype      *Object
-	URIErrorPrototype       *Object
+	TemporalDurationPrototype       *Object
+	TemporalInstantPrototype        *Object
+	TemporalPlainDatePrototype      *Object
+	TemporalPlainTimePrototype      *Object
+	TemporalPlainDateTimePrototype  *Object
+	TemporalPlainYearMonthPrototype *Object
+	TemporalPlainMonthDayPrototype  *Object
+	TemporalZonedDateTimePrototype  *Object
+
+	IntlCollatorPrototype           *Object
+	IntlListFormatPrototype         *Object
+	IntlPluralRulesPrototype        *Object
+	IntlRelativeTimeFormatPrototype *Object
+
+	IteratorPrototype              *Object
+	AsyncIteratorPrototype         *Object
+	AsyncFromSyncIteratorPrototype *Object
+	ChanIteratorPrototype          *Object
+	GoReaderPrototype              *Object
+	GoWriterPrototype              *Object
+	ArrayIteratorPrototype         *Object
+	MapIteratorPrototype           *Object
+	SetIteratorPrototype           *Object
+	StringIteratorPrototype        *Object
+	RegExpStringIteratorPrototype  *Object
+
+	ErrorPrototype           *Object
+	AggregateErrorPrototype  *Object
+	SuppressedErrorPrototype *Object
+	TypeErrorPrototype       *Object
+	SyntaxErrorPrototype     *Object
+	RangeErrorPrototype      *Object
+	ReferenceErrorPrototype  *Object
+	EvalErrorPrototype       *Object
+	URIErrorPrototype        *Object
 
 	GoErrorPrototype *Object
 
@@ -168,6 +292,23 @@ type RandSource func() float64
 
 type Now func() time.Time
 
+// StackTraceFormat selects the format of the 'stack' property of Error objects, see Runtime.SetStackTraceFormat.
+type StackTraceFormat int
+
+const (
+	// StackTraceFormatDefault is the native format: every frame is on its own line starting with "\tat ",
+	// positions include the program counter, i.e. "fn (file.js:1:2(3))", and the trace ends with a newline.
+	StackTraceFormatDefault StackTraceFormat = iota
+	// StackTraceFormatV8 is the format used by V8 (and therefore Node.js and Chrome): every frame is on its own
+	// line starting with "    at ", i.e. "    at fn (file.js:1:2)" or "    at file.js:1:2" for anonymous
+	// functions, and there is no trailing newline.
+	StackTraceFormatV8
+)
+
+// PrepareStackTraceFunc computes the value of the 'stack' property of the Error object err. The frames are those
+// captured when err was created, the innermost first. If the function returns nil the formatted stack trace is used.
+type PrepareStackTraceFunc func(err *Object, frames []StackFrame) Value
+
 type Runtime struct {
 	global          global
 	globalObject    *Object
@@ -176,22 +317,99 @@ type Runtime struct {
 	now             Now
 	_collator       *collate.Collator
 	parserOptions   []parser.Option
+	tailCalls       bool
+
+	dateParseLayouts []string
 
-	symbolRegistry map[unistring.String]*Symbol
+	symbolRegistry   map[unistring.String]*Symbol
+	templateRegistry map[*getTaggedTmplObject]*Object
 
 	fieldsInfoCache  map[reflect.Type]*reflectFieldsInfo
 	methodsInfoCache map[reflect.Type]*reflectMethodsInfo
-
-	fieldNameMapper FieldNameMapper
+	fieldNamesCache  map[reflect.Type][]string
+
+	fieldNameMapper    FieldNameMapper
+	embeddedStructMode EmbeddedStructMode
+	// picks the Go method to call if several of them have the same name
+	overloadResolver OverloadResolver
+	// if set, the GetX and SetX methods are exposed as accessor properties
+	accessorMethods bool
+	// the unit of the numbers time.Duration is converted from and to, zero if the conversion is disabled
+	durationUnit time.Duration
+	timeToDate   bool
+	// if set, ToValue converts json.Marshaler values using their JSON representation
+	jsonMarshalers bool
+	// if set, ToValue converts the nullable database/sql types to their values or null
+	sqlNulls bool
+	// if set, ToValue converts *big.Float to Number
+	bigFloatToNumber bool
+	// how ToValue converts nil values
+	nilConversion NilConversion
+	// if set, exporting undefined into a pointer is an error
+	strictPointerExport bool
+	// if set, the wrapped Go maps enumerate their keys in the sorted order
+	sortedMapKeys bool
+	// if set, exporting a Uint8Array into []byte shares its buffer
+	unsafeBytesExport bool
+	// the mappings registered with RegisterErrorMapping
+	errorMappings []errorMapping
+	// the converters registered with RegisterConverter
+	converters map[reflect.Type]typeConverter
+	// the factories registered with RegisterInterfaceProxy
+	interfaceProxies map[reflect.Type]InterfaceProxyFunc
+	// the type registered with RegisterDecimal
+	decimal *decimalType
+
+	// the limits set by SetMaxStringLength, SetMaxArrayLength and SetMaxArguments, zero if not limited
+	maxStringLength int
+	maxArrayLength  int
+	maxArguments    int
+	// the match timeout set by SetRegExpTimeout, zero if not limited
+	regexpTimeout time.Duration
+	// the regular expressions compiled with regexpTimeout, see timedRegexp2
+	timedRegexps map[timedRegexpKey]*regexp2.Regexp
 
 	vm    *vm
 	hash  *maphash.Hash
 	idSeq uint64
 
 	jobQueue []func()
+	// if set, the jobs are only run by DrainJobs
+	manualJobs bool
+
+	// objects that must not be collected until control leaves the Runtime (see WeakRef)
+	keptObjects []*Object
+
+	// WeakMaps and WeakSets that have been garbage collected and whose entries must be removed from their keys
+	weakCollectionCleanup *weakCollectionCleanupQueue
+
+	console     *consoleState
+	performance *performanceState
+	fetchClient *http.Client
+
+	streamsEnabled bool
+
+	// the listeners of the global object, see EnableEvents
+	globalEvents *eventTargetObject
+
+	localeProvider LocaleProvider
+
+	stackTraceFormat  StackTraceFormat
+	prepareStackTrace PrepareStackTraceFunc
 
 	promiseRejectionTracker PromiseRejectionTracker
-	asyncContextTracker     AsyncContextTracker
+	// the tracker of the Loop, called in addition to the one set by SetPromiseRejectionTracker
+	loopRejectionTracker PromiseRejectionTracker
+	// the Loop created for the Runtime by NewLoop, if any
+	loop *Loop
+	// the context of the current RunProgramContext call, if any
+	ctx                 gocontext.Context
+	asyncContextTracker AsyncContextTracker
+
+	resolveModuleFunc        ResolveModuleFunc
+	resolveModuleRequestFunc ResolveModuleRequestFunc
+	moduleNamespaces         map[ModuleRecord]*Object
+	getImportMetaProperties  func(ModuleRecord) []MetaProperty
 }
 
 type StackFrame struct {
@@ -258,6 +476,37 @@ func (f *StackFrame) WriteToValueBuilder(b *valueStringBuilder) {
 	}
 }
 
+// writeV8ToValueBuilder writes the frame in the format used by V8, i.e. "fn (file:line:col)" or "file:line:col"
+// for anonymous functions, without the program counter.
+func (f *StackFrame) writeV8ToValueBuilder(b *valueStringBuilder) {
+	if f.prg != nil {
+		if n := f.prg.funcName; n != "" {
+			b.WriteString(stringValueFromRaw(n))
+			b.WriteASCII(" (")
+		}
+		p := f.Position()
+		if p.Filename != "" {
+			b.WriteASCII(p.Filename)
+		} else {
+			b.WriteASCII("<anonymous>")
+		}
+		b.WriteRune(':')
+		b.WriteASCII(strconv.Itoa(p.Line))
+		b.WriteRune(':')
+		b.WriteASCII(strconv.Itoa(p.Column))
+		if f.prg.funcName != "" {
+			b.WriteRune(')')
+		}
+	} else {
+		if f.funcName != "" {
+			b.WriteString(stringValueFromRaw(f.funcName))
+			b.WriteASCII(" (native)")
+		} else {
+			b.WriteASCII("native")
+		}
+	}
+}
+
 func (f *StackFrame) Write(b *bytes.Buffer) {
 	if f.prg != nil {
 		if n := f.prg.funcName; n != "" {
@@ -326,6 +575,16 @@ type StackOverflowError struct {
 	baseUncatchableException
 }
 
+// TimeLimitError is the value of the *InterruptedError returned when a run exceeds the time limit set by
+// SetTimeLimit. It can be retrieved with errors.As.
+type TimeLimitError struct {
+	Limit time.Duration
+}
+
+func (e *TimeLimitError) Error() string {
+	return fmt.Sprintf("time limit of %v exceeded", e.Limit)
+}
+
 func (e *InterruptedError) Value() interface{} {
 	return e.iface
 }
@@ -424,6 +683,7 @@ func (r *Runtime) init() {
 	funcProtoObj := funcProto.self.(*nativeFuncObject)
 
 	r.global.IteratorPrototype = r.newLazyObject(r.createIterProto)
+	r.initAsyncIterators()
 
 	r.initObject()
 	r.initFunction()
@@ -432,6 +692,7 @@ func (r *Runtime) init() {
 	r.initString()
 	r.initGlobalObject()
 	r.initNumber()
+	r.initBigInt()
 	r.initRegExp()
 	r.initDate()
 	r.initBoolean()
@@ -447,12 +708,17 @@ func (r *Runtime) init() {
 	r.initJSON()
 
 	r.initTypedArrays()
+	r.initAtomics()
 	r.initSymbol()
 	r.initWeakSet()
 	r.initWeakMap()
+	r.initWeakRef()
 	r.initMap()
 	r.initSet()
 	r.initPromise()
+	r.initDisposableStack()
+	r.initTemporal()
+	r.initIntl()
 
 	r.global.thrower = r.newNativeFunc(r.builtin_thrower, nil, "", nil, 0)
 	r.global.throwerProperty = &valueProperty{
@@ -540,6 +806,15 @@ func (r *Runtime) newGuardedObject(proto *Object, class string) (o *guardedObjec
 	return
 }
 
+// NewRawJSON creates an object equivalent to the one returned by JSON.rawJSON(text). It returns a SyntaxError
+// if text is not a valid JSON primitive value.
+func (r *Runtime) NewRawJSON(text string) (o *Object, err error) {
+	err = r.try(func() {
+		o = r.newRawJSON(newStringValue(text))
+	})
+	return
+}
+
 func (r *Runtime) NewObject() (v *Object) {
 	return r.newBaseObject(r.global.ObjectPrototype, classObject).val
 }
@@ -566,12 +841,101 @@ func (r *Runtime) NewTypeError(args ...interface{}) *Object {
 	return r.builtin_new(r.global.TypeError, []Value{newStringValue(msg)})
 }
 
+// NewGoError creates a GoError with the message of err and the 'value' property holding err. If err, or any error
+// in its chain (see errors.Unwrap), matches a mapping registered with RegisterErrorMapping, an instance of the
+// mapped constructor is created instead.
 func (r *Runtime) NewGoError(err error) *Object {
+	if e := r.mapGoError(err); e != nil {
+		return e
+	}
 	e := r.newError(r.global.GoError, err.Error()).(*Object)
 	e.Set("value", err)
 	return e
 }
 
+type errorMapping struct {
+	typ  reflect.Type
+	ctor *Object
+}
+
+// RegisterErrorMapping makes the Go errors of type errType thrown from native functions (i.e. the errors returned
+// by the functions wrapped by ToValue and the ones passed to NewGoError) surface as instances of the JavaScript
+// constructor ctor (e.g. a TimeoutError class) instead of GoError. The constructor is called with the message of
+// the error and an options object with the 'cause' property set to the original error (converted with ToValue),
+// as the standard Error constructors expect. If the constructed object has no own 'cause' property, it's added. If
+// the constructor throws, a GoError is created as if there was no mapping.
+//
+// errType is matched against the type of each error in the chain, starting from the outermost one. If errType is
+// an interface type, any error implementing it matches. The mappings are checked in the order of registration,
+// registering a mapping for the same type again replaces the constructor.
+//
+// An error is returned if errType is neither an interface type nor an error type or if ctor is not a constructor.
+func (r *Runtime) RegisterErrorMapping(errType reflect.Type, ctor Value) error {
+	if errType == nil || errType.Kind() != reflect.Interface && !errType.Implements(reflectTypeError) {
+		return fmt.Errorf("%v is not an error type", errType)
+	}
+	c, ok := ctor.(*Object)
+	if !ok || c.self.assertConstructor() == nil {
+		return fmt.Errorf("%v is not a constructor", ctor)
+	}
+	for i, m := range r.errorMappings {
+		if m.typ == errType {
+			r.errorMappings[i].ctor = c
+			return nil
+		}
+	}
+	r.errorMappings = append(r.errorMappings, errorMapping{typ: errType, ctor: c})
+	return nil
+}
+
+// isMappedGoError returns true if o is an instance of a constructor registered with RegisterErrorMapping whose
+// 'cause' is a Go error.
+func (r *Runtime) isMappedGoError(o *Object) bool {
+	cause := o.self.getStr("cause", nil)
+	if cause == nil {
+		return false
+	}
+	if et := cause.ExportType(); et == nil || !et.Implements(reflectTypeError) {
+		return false
+	}
+	for _, m := range r.errorMappings {
+		if m.ctor.self.hasInstance(o) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Runtime) mapGoError(err error) *Object {
+	if len(r.errorMappings) == 0 {
+		return nil
+	}
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		t := reflect.TypeOf(e)
+		for _, m := range r.errorMappings {
+			if t == m.typ || m.typ.Kind() == reflect.Interface && t.Implements(m.typ) {
+				cause := r.ToValue(err)
+				opts := r.NewObject()
+				opts.self._putProp("cause", cause, true, true, true)
+				var o *Object
+				sp := r.vm.sp
+				if ex := r.vm.try(func() {
+					o = r.toConstructor(m.ctor)([]Value{newStringValue(err.Error()), opts}, nil)
+					if !o.self.hasOwnPropertyStr("cause") {
+						o.self._putProp("cause", cause, true, false, true)
+					}
+				}); ex != nil {
+					// the constructor has thrown, a plain GoError is created instead
+					r.vm.sp = sp
+					return nil
+				}
+				return o
+			}
+		}
+	}
+	return nil
+}
+
 func (r *Runtime) newFunc(name unistring.String, length int, strict bool) (f *funcObject) {
 	f = &funcObject{}
 	r.initBaseJsFunction(&f.baseJsFuncObject, strict)
@@ -780,7 +1144,7 @@ func (r *Runtime) newNativeFunc(call func(FunctionCall) Value, construct func(ar
 	return v
 }
 
-func (r *Runtime) newWrappedFunc(value reflect.Value) *Object {
+func (r *Runtime) newWrappedFunc(value reflect.Value, resultNames []string) *Object {
 
 	v := &Object{runtime: r}
 
@@ -794,7 +1158,7 @@ func (r *Runtime) newWrappedFunc(value reflect.Value) *Object {
 					prototype:  r.global.FunctionPrototype,
 				},
 			},
-			f: r.wrapReflectFunc(value),
+			f: r.wrapReflectFunc(value, resultNames),
 		},
 		wrapped: value,
 	}
@@ -864,7 +1228,7 @@ func (r *Runtime) newPrimitiveObject(value Value, proto *Object, class string) *
 
 func (r *Runtime) builtin_Number(call FunctionCall) Value {
 	if len(call.Arguments) > 0 {
-		return call.Arguments[0].ToNumber()
+		return toNumberFromNumeric(call.Arguments[0])
 	} else {
 		return valueInt(0)
 	}
@@ -873,7 +1237,7 @@ func (r *Runtime) builtin_Number(call FunctionCall) Value {
 func (r *Runtime) builtin_newNumber(args []Value, proto *Object) *Object {
 	var v Value
 	if len(args) > 0 {
-		v = args[0].ToNumber()
+		v = toNumberFromNumeric(args[0])
 	} else {
 		v = intToValue(0)
 	}
@@ -1312,14 +1676,14 @@ func New() *Runtime {
 // method. This representation is not linked to a runtime in any way and can be run in multiple runtimes (possibly
 // at the same time).
 func Compile(name, src string, strict bool) (*Program, error) {
-	return compile(name, src, strict, true, nil)
+	return compile(name, src, strict, true, false, nil)
 }
 
 // CompileAST creates an internal representation of the JavaScript code that can be later run using the Runtime.RunProgram()
 // method. This representation is not linked to a runtime in any way and can be run in multiple runtimes (possibly
 // at the same time).
 func CompileAST(prg *js_ast.Program, strict bool) (*Program, error) {
-	return compileAST(prg, strict, true, nil)
+	return compileAST(prg, strict, true, false, nil)
 }
 
 // MustCompile is like Compile but panics if the code cannot be compiled.
@@ -1355,17 +1719,18 @@ func Parse(name, src string, options ...parser.Option) (prg *js_ast.Program, err
 	return
 }
 
-func compile(name, src string, strict, inGlobal bool, evalVm *vm, parserOptions ...parser.Option) (p *Program, err error) {
+func compile(name, src string, strict, inGlobal, tailCalls bool, evalVm *vm, parserOptions ...parser.Option) (p *Program, err error) {
 	prg, err := Parse(name, src, parserOptions...)
 	if err != nil {
 		return
 	}
 
-	return compileAST(prg, strict, inGlobal, evalVm)
+	return compileAST(prg, strict, inGlobal, tailCalls, evalVm)
 }
 
-func compileAST(prg *js_ast.Program, strict, inGlobal bool, evalVm *vm) (p *Program, err error) {
+func compileAST(prg *js_ast.Program, strict, inGlobal, tailCalls bool, evalVm *vm) (p *Program, err error) {
 	c := newCompiler()
+	c.tailCalls = tailCalls
 
 	defer func() {
 		if x := recover(); x != nil {
@@ -1385,22 +1750,27 @@ func compileAST(prg *js_ast.Program, strict, inGlobal bool, evalVm *vm) (p *Prog
 }
 
 func (r *Runtime) compile(name, src string, strict, inGlobal bool, evalVm *vm) (p *Program, err error) {
-	p, err = compile(name, src, strict, inGlobal, evalVm, r.parserOptions...)
+	p, err = compile(name, src, strict, inGlobal, r.tailCalls, evalVm, r.parserOptions...)
 	if err != nil {
-		switch x1 := err.(type) {
-		case *CompilerSyntaxError:
-			err = &Exception{
-				val: r.builtin_new(r.global.SyntaxError, []Value{newStringValue(x1.Error())}),
-			}
-		case *CompilerReferenceError:
-			err = &Exception{
-				val: r.newError(r.global.ReferenceError, x1.Message),
-			} // TODO proper message
-		}
+		err = r.wrapCompilerError(err)
 	}
 	return
 }
 
+func (r *Runtime) wrapCompilerError(err error) error {
+	switch x1 := err.(type) {
+	case *CompilerSyntaxError:
+		return &Exception{
+			val: r.builtin_new(r.global.SyntaxError, []Value{newStringValue(x1.Error())}),
+		}
+	case *CompilerReferenceError:
+		return &Exception{
+			val: r.newError(r.global.ReferenceError, x1.Message),
+		} // TODO proper message
+	}
+	return err
+}
+
 // RunString executes the given string in the global context.
 func (r *Runtime) RunString(str string) (Value, error) {
 	return r.RunScript("", str)
@@ -1417,6 +1787,63 @@ func (r *Runtime) RunScript(name, src string) (Value, error) {
 	return r.RunProgram(p)
 }
 
+// RunStringContext is like RunString, but makes ctx available to the Go functions called by the script, see
+// RunProgramContext.
+func (r *Runtime) RunStringContext(ctx gocontext.Context, str string) (Value, error) {
+	p, err := r.compile("", str, false, true, nil)
+	if err != nil {
+		return nil, err
+	}
+	return r.RunProgramContext(ctx, p)
+}
+
+// RunProgramContext is like RunProgram, but makes ctx available to the Go functions called by the program, so
+// that they can honour its deadline and cancellation. Such functions can obtain ctx by calling Context(), or,
+// if they are wrapped by ToValue(), by declaring a first parameter of type context.Context (see ToValue).
+//
+// If ctx is done before the program completes, the program is interrupted (see Interrupt) with ctx.Err() as the
+// value, so the returned *InterruptedError matches the error with errors.Is. Like Interrupt, it has no effect
+// while a Go function is running, such function should check ctx itself.
+//
+// Nested calls are allowed, the previous context is restored when the call returns.
+func (r *Runtime) RunProgramContext(ctx gocontext.Context, p *Program) (Value, error) {
+	prev := r.ctx
+	r.ctx = ctx
+	defer func() {
+		r.ctx = prev
+	}()
+	if done := ctx.Done(); done != nil {
+		stop := make(chan struct{})
+		stopped := make(chan bool)
+		go func() {
+			select {
+			case <-done:
+				r.Interrupt(ctx.Err())
+				stopped <- true
+			case <-stop:
+				stopped <- false
+			}
+		}()
+		defer func() {
+			close(stop)
+			if <-stopped {
+				// the interrupt could have arrived after the program had completed
+				r.ClearInterrupt()
+			}
+		}()
+	}
+	return r.RunProgram(p)
+}
+
+// Context returns the context of the innermost RunProgramContext or RunStringContext call which is in progress,
+// or context.Background() if there is none.
+func (r *Runtime) Context() gocontext.Context {
+	if r.ctx != nil {
+		return r.ctx
+	}
+	return gocontext.Background()
+}
+
 func isUncatchableException(e error) bool {
 	for ; e != nil; e = errors.Unwrap(e) {
 		if _, ok := e.(uncatchableException); ok {
@@ -1471,6 +1898,7 @@ func (r *Runtime) RunProgram(p *Program) (result Value, err error) {
 		vm.sp = sp + 2
 	} else {
 		vm.callStack = append(vm.callStack, context{})
+		vm.startTimeLimit()
 	}
 	vm.prg = p
 	vm.pc = 0
@@ -1638,7 +2066,9 @@ invalid characters).
 
 # Nil
 
-Nil is converted to null.
+Nil is converted to null. By default nil pointers and interfaces are converted to null as well, but nil slices and
+maps (except map[string]interface{}) are converted into empty host objects, like the non-nil ones. This can be changed
+with SetNilConversion.
 
 # Functions
 
@@ -1648,6 +2078,9 @@ the function as a constructor will result in a TypeError.
 
 func(FunctionCall, *Runtime) Value is treated as above, except the *Runtime is also passed as a parameter.
 
+func(context.Context, FunctionCall) Value is treated as above, except the result of Context() is also passed as a
+parameter, so the function can honour the context passed to RunProgramContext.
+
 func(ConstructorCall) *Object is treated as a native constructor, allowing to use it with the new
 operator:
 
@@ -1689,10 +2122,14 @@ Any other Go function is wrapped so that the arguments are automatically convert
 return value is converted to a JavaScript value (using this method).  If conversion is not possible, a TypeError is
 thrown.
 
+If the first parameter of such function is a context.Context, it receives the result of Context() and the first
+argument of the call is converted into the second parameter, and so on.
+
 Functions with multiple return values return an Array. If the last return value is an `error` it is not returned but
 converted into a JS exception. If the error is *Exception, it is thrown as is, otherwise it's wrapped in a GoEerror.
 Note that if there are exactly two return values and the last is an `error`, the function returns the first value as is,
 not an Array.
+To return an object with named properties instead of an Array, wrap the function with NamedResults.
 
 # Structs
 
@@ -1700,6 +2137,14 @@ Structs are converted to Object-like values. Fields and methods are available as
 results of this method (ToValue()) applied to the corresponding Go value.
 
 Field properties are writable and non-configurable. Method properties are non-writable and non-configurable.
+After SetAccessorMethods(true) the getter and setter methods (GetX and SetX) are exposed as non-configurable accessor
+properties instead.
+
+The methods declared with pointer receivers are available as well, even if the struct is passed by value (or comes
+from a map or an interface): a non-addressable struct is copied into an addressable one when it's wrapped, so the
+methods are called on the copy, which is also what Value.Export() returns, and the changes they make do not affect
+the original. Note that a map value or an interface element of a slice is wrapped anew on every access, so the
+changes made through it are lost.
 
 Attempt to define a new property or delete an existing property will fail (throw in strict mode) unless it's a Symbol
 property. Symbol properties only exist in the wrapper and do not affect the underlying Go value.
@@ -1729,12 +2174,14 @@ The same applies to values from maps and slices as well.
 
 # Handling of time.Time
 
-time.Time does not get special treatment and therefore is converted just like any other `struct` providing access to
-all its methods. This is done deliberately instead of converting it to a `Date` because these two types are not fully
-compatible: `time.Time` includes zone, whereas JS `Date` doesn't. Doing the conversion implicitly therefore would
-result in a loss of information.
+By default time.Time does not get special treatment and therefore is converted just like any other `struct` providing
+access to all its methods. This is done deliberately instead of converting it to a `Date` because these two types are
+not fully compatible: `time.Time` includes zone and nanoseconds, whereas JS `Date` doesn't. Doing the conversion
+implicitly therefore would result in a loss of information.
 
-If you need to convert it to a `Date`, it can be done either in JS:
+SetTimeToDate(true) makes time.Time and *time.Time convert to a `Date`. The original value is remembered, so
+exporting the `Date` returns it unchanged unless the `Date` has been modified (in which case the result keeps the
+location of the original value). Otherwise, the conversion can be done either in JS:
 
 	var d = new Date(goval.UnixNano()/1e6);
 
@@ -1750,9 +2197,39 @@ If you need to convert it to a `Date`, it can be done either in JS:
 
 Note that Value.Export() for a `Date` value returns time.Time in local timezone.
 
+# Handling of time.Duration
+
+By default time.Duration is converted like any other int64-based type, i.e. into a host object that has the methods
+of time.Duration (such as String()) and behaves as the number of nanoseconds in arithmetic. After SetDurationUnit
+has been called it is converted into a number of the given units instead, which is fractional if the duration is
+not a whole number of units, and ExportTo() converts such numbers back into time.Duration.
+
+# Handling of math/big
+
+*big.Int is converted to a BigInt (the value is copied), a nil pointer is converted to null. *big.Float is converted
+like any other type unless SetBigFloatToNumber(true) has been called, in which case it becomes a Number, possibly
+losing precision. ExportTo() converts BigInts and integral Numbers into *big.Int and Numbers and BigInts into
+*big.Float.
+
+# Handling of database/sql types
+
+By default the nullable types of database/sql (sql.NullString, sql.NullInt64, sql.NullTime, sql.Null[T], etc.) are
+converted like any other struct. After SetSQLNullToValue(true) they are converted into null (or undefined, see
+SetNilConversion) if they are not valid, and into the result of this method applied to their value (as returned by
+their Value method) otherwise, e.g. sql.NullTime becomes a time.Time value (or a Date, see SetTimeToDate). This
+applies to the pointers to these types and to the struct fields of these types as well, so the structs holding
+database rows can be passed to scripts as is.
+
+# Handling of json.Marshaler
+
+By default the values implementing json.Marshaler are converted like any other value of their type. After
+SetJSONMarshalerToValue(true) they are converted into the result of parsing their JSON representation instead.
+
 # Maps
 
 Maps with string or integer key type are converted into host objects that largely behave like a JavaScript Object.
+The order of their keys (e.g. in for...in loops, Object.keys() and JSON.stringify()) follows Go's map iteration order,
+i.e. it's random, unless SetSortedMapKeys(true) has been called.
 
 # Maps with methods
 
@@ -1775,6 +2252,16 @@ values (as opposed to pointers).
 Arrays are converted similarly to slices, except the resulting Arrays are not resizable (and therefore the 'length'
 property is non-writable).
 
+# Channels
+
+If the Runtime has a Loop (see NewLoop), a non-nil receive-only channel (<-chan T) is converted into an async iterator,
+so a script can consume the values sent by Go code with 'for await (const v of ch)'. Each call of next() receives a
+value from the channel on a separate goroutine and resolves the returned promise with the result of this method
+(ToValue()) applied to it. The iteration completes when the channel is closed. Calling return() (e.g. by breaking out
+of the loop) abandons the receive in progress, the channel itself is not affected. While a value is being received
+the loop does not stop. Exporting the iterator returns the original channel. Other channels are converted like any
+other type, see below.
+
 Any other type is converted to a generic reflect based host object. Depending on the underlying type it behaves similar
 to a Number, String, Boolean or Object.
 
@@ -1785,13 +2272,34 @@ func (r *Runtime) ToValue(i interface{}) Value {
 	return r.toValue(i, reflect.Value{})
 }
 
+// ToValueReflect is like ToValue(v.Interface()), but if v is addressable (e.g. it's a field of a struct accessed
+// through a pointer, or an element of a slice), the resulting host object refers to v itself rather than to a copy
+// of it, so the changes made by scripts are visible in the original value. An invalid v is converted like nil.
+//
+// ToValueReflect panics if v has been obtained through unexported struct fields (i.e. v.CanInterface() is false),
+// because the value must not be exposed to scripts.
+func (r *Runtime) ToValueReflect(v reflect.Value) Value {
+	if !v.IsValid() {
+		return r.toValue(nil, v)
+	}
+	if !v.CanInterface() {
+		panic(fmt.Errorf("cannot convert a value of type %v obtained through unexported fields", v.Type()))
+	}
+	return r.toValue(v.Interface(), v)
+}
+
 func (r *Runtime) toValue(i interface{}, origValue reflect.Value) Value {
+	if r.converters != nil && i != nil {
+		if c := r.converters[reflect.TypeOf(i)]; c.toValue != nil {
+			return c.toValue(r, i)
+		}
+	}
 	switch i := i.(type) {
 	case nil:
-		return _null
+		return r.nilValue()
 	case *Object:
 		if i == nil || i.self == nil {
-			return _null
+			return r.nilValue()
 		}
 		if i.runtime != nil && i.runtime != r {
 			panic(r.NewTypeError("Illegal runtime transition of an Object"))
@@ -1823,6 +2331,13 @@ func (r *Runtime) toValue(i interface{}, origValue reflect.Value) Value {
 		return r.newNativeFunc(func(call FunctionCall) Value {
 			return i(call, r)
 		}, nil, name, nil, 0)
+	case func(gocontext.Context, FunctionCall) Value:
+		name := unistring.NewFromString(runtime.FuncForPC(reflect.ValueOf(i).Pointer()).Name())
+		return r.newNativeFunc(func(call FunctionCall) Value {
+			return i(r.Context(), call)
+		}, nil, name, nil, 0)
+	case namedResultsFunc:
+		return r.newWrappedFunc(i.fn, i.names)
 	case func(ConstructorCall) *Object:
 		name := unistring.NewFromString(runtime.FuncForPC(reflect.ValueOf(i).Pointer()).Name())
 		return r.newNativeConstructor(i, name, 0)
@@ -1862,9 +2377,42 @@ func (r *Runtime) toValue(i interface{}, origValue reflect.Value) Value {
 		return floatToValue(float64(i))
 	case float64:
 		return floatToValue(i)
+	case time.Time:
+		if r.timeToDate {
+			return r.newDateFromGoTime(i)
+		}
+	case *time.Time:
+		if r.timeToDate {
+			if i == nil {
+				return r.nilValue()
+			}
+			return r.newDateFromGoTime(*i)
+		}
+	case *big.Int:
+		if i == nil {
+			return r.nilValue()
+		}
+		return (*valueBigInt)(new(big.Int).Set(i))
+	case *big.Float:
+		if r.bigFloatToNumber {
+			if i == nil {
+				return r.nilValue()
+			}
+			f, _ := i.Float64()
+			return floatToValue(f)
+		}
+	case time.Duration:
+		unit := r.durationUnit
+		if unit <= 0 {
+			break
+		}
+		if i%unit == 0 {
+			return intToValue(int64(i / unit))
+		}
+		return floatToValue(float64(i) / float64(unit))
 	case map[string]interface{}:
 		if i == nil {
-			return _null
+			return r.nilValue()
 		}
 		obj := &Object{runtime: r}
 		m := &objectGoMapSimple{
@@ -1878,25 +2426,49 @@ func (r *Runtime) toValue(i interface{}, origValue reflect.Value) Value {
 		m.init()
 		return obj
 	case []interface{}:
+		if i == nil && r.nilConversion != NilDefault {
+			return r.nilValue()
+		}
 		return r.newObjectGoSlice(&i).val
 	case *[]interface{}:
 		if i == nil {
-			return _null
+			return r.nilValue()
 		}
 		return r.newObjectGoSlice(i).val
 	}
 
+	if r.sqlNulls {
+		if v, ok := r.sqlNullToValue(i); ok {
+			return v
+		}
+	}
+
+	if r.jsonMarshalers {
+		if v, ok := r.marshalerToValue(i, origValue); ok {
+			return v
+		}
+	}
+
 	if !origValue.IsValid() {
 		origValue = reflect.ValueOf(i)
 	}
 
+	if r.nilConversion != NilDefault {
+		switch origValue.Kind() {
+		case reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.Interface:
+			if origValue.IsNil() {
+				return r.nilValue()
+			}
+		}
+	}
+
 	value := origValue
 	for value.Kind() == reflect.Ptr {
 		value = value.Elem()
 	}
 
 	if !value.IsValid() {
-		return _null
+		return r.nilValue()
 	}
 
 	switch value.Kind() {
@@ -1954,7 +2526,11 @@ func (r *Runtime) toValue(i interface{}, origValue reflect.Value) Value {
 		obj.self = a
 		return obj
 	case reflect.Func:
-		return r.newWrappedFunc(value)
+		return r.newWrappedFunc(value, nil)
+	case reflect.Chan:
+		if r.loop != nil && value.Type().ChanDir() == reflect.RecvDir && !value.IsNil() {
+			return r.loop.newChanIterator(value)
+		}
 	}
 
 	obj := &Object{runtime: r}
@@ -1970,70 +2546,128 @@ func (r *Runtime) toValue(i interface{}, origValue reflect.Value) Value {
 	return obj
 }
 
-func (r *Runtime) wrapReflectFunc(value reflect.Value) func(FunctionCall) Value {
-	return func(call FunctionCall) Value {
-		typ := value.Type()
-		nargs := typ.NumIn()
-		var in []reflect.Value
+// namedResultsFunc is the value returned by NamedResults.
+type namedResultsFunc struct {
+	fn    reflect.Value
+	names []string
+}
 
-		if l := len(call.Arguments); l < nargs {
-			// fill missing arguments with zero values
-			n := nargs
-			if typ.IsVariadic() {
-				n--
-			}
-			in = make([]reflect.Value, n)
-			for i := l; i < n; i++ {
-				in[i] = reflect.Zero(typ.In(i))
+// NamedResults returns a value which ToValue() converts into a function that calls fn (wrapped as described in
+// ToValue) and returns its results as an object with the given property names rather than an Array, e.g.
+//
+//	vm.Set("divmod", goja.NamedResults(func(a, b int) (int, int) {
+//		return a / b, a % b
+//	}, "quotient", "remainder"))
+//
+// A trailing error result is not counted, it's thrown as usual. NamedResults panics if fn is not a function or
+// the number of names does not match the number of the other results.
+func NamedResults(fn interface{}, names ...string) interface{} {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func {
+		panic(fmt.Errorf("NamedResults: %T is not a function", fn))
+	}
+	typ := v.Type()
+	n := typ.NumOut()
+	if n > 0 && typ.Out(n-1) == reflectTypeError {
+		n--
+	}
+	if len(names) != n {
+		panic(fmt.Errorf("NamedResults: %d names for %d results of %v", len(names), n, typ))
+	}
+	return namedResultsFunc{fn: v, names: names}
+}
+
+// callReflectFunc calls the Go function value with the arguments converted into its parameters (see ToValue) and
+// returns its results. A trailing error result is thrown if it's not nil and removed from the results otherwise.
+func (r *Runtime) callReflectFunc(value reflect.Value, args []Value) []reflect.Value {
+	typ := value.Type()
+	nargs := typ.NumIn()
+	var in []reflect.Value
+
+	// the index of the parameter that receives the first argument, 1 if the context is passed as the first one
+	first := 0
+	if nargs > 0 && typ.In(0) == typeContext && !(nargs == 1 && typ.IsVariadic()) {
+		first = 1
+	}
+
+	if l := len(args) + first; l < nargs {
+		// fill missing arguments with zero values
+		n := nargs
+		if typ.IsVariadic() {
+			n--
+		}
+		in = make([]reflect.Value, n)
+		for i := l; i < n; i++ {
+			in[i] = reflect.Zero(typ.In(i))
+		}
+	} else {
+		if l > nargs && !typ.IsVariadic() {
+			l = nargs
+		}
+		in = make([]reflect.Value, l)
+	}
+	if first > 0 {
+		in[0] = reflect.ValueOf(r.Context())
+	}
+
+	for i, a := range args {
+		var t reflect.Type
+
+		n := i + first
+		if n >= nargs-1 && typ.IsVariadic() {
+			if n > nargs-1 {
+				n = nargs - 1
 			}
+
+			t = typ.In(n).Elem()
+		} else if n > nargs-1 { // ignore extra arguments
+			break
 		} else {
-			if l > nargs && !typ.IsVariadic() {
-				l = nargs
-			}
-			in = make([]reflect.Value, l)
+			t = typ.In(n)
 		}
 
-		for i, a := range call.Arguments {
-			var t reflect.Type
+		v := reflect.New(t).Elem()
+		err := r.toReflectValue(a, v, &objectExportCtx{})
+		if err != nil {
+			panic(r.NewTypeError("could not convert function call parameter %d: %v", i, err))
+		}
+		in[i+first] = v
+	}
 
-			n := i
-			if n >= nargs-1 && typ.IsVariadic() {
-				if n > nargs-1 {
-					n = nargs - 1
-				}
+	out := value.Call(in)
+	if len(out) == 0 {
+		return out
+	}
 
-				t = typ.In(n).Elem()
-			} else if n > nargs-1 { // ignore extra arguments
-				break
-			} else {
-				t = typ.In(n)
+	if last := out[len(out)-1]; last.Type() == reflectTypeError {
+		if !last.IsNil() {
+			err := last.Interface().(error)
+			if _, ok := err.(*Exception); ok {
+				panic(err)
 			}
-
-			v := reflect.New(t).Elem()
-			err := r.toReflectValue(a, v, &objectExportCtx{})
-			if err != nil {
-				panic(r.NewTypeError("could not convert function call parameter %d: %v", i, err))
+			if isUncatchableException(err) {
+				panic(err)
 			}
-			in[i] = v
+			panic(r.NewGoError(err))
 		}
+		out = out[:len(out)-1]
+	}
+	return out
+}
 
-		out := value.Call(in)
+func (r *Runtime) wrapReflectFunc(value reflect.Value, resultNames []string) func(FunctionCall) Value {
+	return func(call FunctionCall) Value {
+		out := r.callReflectFunc(value, call.Arguments)
 		if len(out) == 0 {
 			return _undefined
 		}
 
-		if last := out[len(out)-1]; last.Type() == reflectTypeError {
-			if !last.IsNil() {
-				err := last.Interface().(error)
-				if _, ok := err.(*Exception); ok {
-					panic(err)
-				}
-				if isUncatchableException(err) {
-					panic(err)
-				}
-				panic(r.NewGoError(err))
+		if resultNames != nil {
+			o := r.NewObject()
+			for i, v := range out {
+				o.self._putProp(unistring.NewFromString(resultNames[i]), r.ToValue(v.Interface()), true, true, true)
 			}
-			out = out[:len(out)-1]
+			return o
 		}
 
 		switch len(out) {
@@ -2060,6 +2694,25 @@ func (r *Runtime) toReflectValue(v Value, dst reflect.Value, ctx *objectExportCt
 		return nil
 	}
 
+	if r.converters != nil {
+		if c := r.converters[typ]; c.export != nil {
+			res, err := c.export(r, v)
+			if err != nil {
+				return fmt.Errorf("could not convert %v to %v: %w", v, typ, err)
+			}
+			if res == nil {
+				dst.Set(reflect.Zero(typ))
+				return nil
+			}
+			rv := reflect.ValueOf(res)
+			if !rv.Type().AssignableTo(typ) {
+				return fmt.Errorf("could not convert %v to %v: the converter returned %v", v, typ, rv.Type())
+			}
+			dst.Set(rv)
+			return nil
+		}
+	}
+
 	if typ == typeObject {
 		if obj, ok := v.(*Object); ok {
 			dst.Set(reflect.ValueOf(obj))
@@ -2074,13 +2727,74 @@ func (r *Runtime) toReflectValue(v Value, dst reflect.Value, ctx *objectExportCt
 		}
 	}
 
+	kind := typ.Kind()
+	if kind == reflect.Struct && typ.ConvertibleTo(typeTime) {
+		if tme, ok, err := r.exportToTime(v); ok {
+			if err != nil {
+				return fmt.Errorf("could not convert %v to %v: %w", v, typ, err)
+			}
+			dst.Set(reflect.ValueOf(tme).Convert(typ))
+			return nil
+		}
+	}
+
+	if kind == reflect.Slice && typ.Elem() == typeBytes.Elem() {
+		if b, ok, err := r.uint8ArrayBytes(v); ok {
+			if err != nil {
+				return fmt.Errorf("could not convert %v to %v: %w", v, typ, err)
+			}
+			if !r.unsafeBytesExport {
+				b = append([]byte{}, b...)
+			}
+			dst.Set(reflect.ValueOf(b).Convert(typ))
+			return nil
+		}
+	}
+
+	if typ == typeDuration && r.durationUnit > 0 {
+		if d, ok, err := r.exportToDuration(v); ok {
+			if err != nil {
+				return fmt.Errorf("could not convert %v to %v: %w", v, typ, err)
+			}
+			dst.Set(reflect.ValueOf(d))
+			return nil
+		}
+	}
+
+	switch typ {
+	case reflectTypeBigInt:
+		if b, ok, err := exportToBigInt(v); ok {
+			if err != nil {
+				return fmt.Errorf("could not convert %v to %v: %w", v, typ, err)
+			}
+			dst.Set(reflect.ValueOf(b))
+			return nil
+		}
+	case typeBigFloat:
+		if f, ok, err := exportToBigFloat(v); ok {
+			if err != nil {
+				return fmt.Errorf("could not convert %v to %v: %w", v, typ, err)
+			}
+			dst.Set(reflect.ValueOf(f))
+			return nil
+		}
+	}
+
+	if kind == reflect.Struct && isSQLNullType(typ) {
+		if ok, err := r.exportToSQLNull(v, dst); ok {
+			return err
+		}
+	}
+
 	et := v.ExportType()
 	if et == nil || et == reflectTypeNil {
+		if kind == reflect.Ptr && r.strictPointerExport && v == _undefined {
+			return fmt.Errorf("could not convert undefined to %v", typ)
+		}
 		dst.Set(reflect.Zero(typ))
 		return nil
 	}
 
-	kind := typ.Kind()
 	for i := 0; ; i++ {
 		if et.AssignableTo(typ) {
 			ev := reflect.ValueOf(exportValue(v, ctx))
@@ -2106,23 +2820,6 @@ func (r *Runtime) toReflectValue(v Value, dst reflect.Value, ctx *objectExportCt
 		}
 	}
 
-	if typ == typeTime {
-		if obj, ok := v.(*Object); ok {
-			if d, ok := obj.self.(*dateObject); ok {
-				dst.Set(reflect.ValueOf(d.time()))
-				return nil
-			}
-		}
-		if et.Kind() == reflect.String {
-			tme, ok := dateParse(v.String())
-			if !ok {
-				return fmt.Errorf("could not convert string %v to %v", v, typ)
-			}
-			dst.Set(reflect.ValueOf(tme))
-			return nil
-		}
-	}
-
 	switch kind {
 	case reflect.String:
 		dst.Set(reflect.ValueOf(v.String()).Convert(typ))
@@ -2189,37 +2886,24 @@ func (r *Runtime) toReflectValue(v Value, dst reflect.Value, ctx *objectExportCt
 				dst.Set(reflect.ValueOf(v).Elem())
 				return nil
 			}
-			s := dst
-			ctx.putTyped(o, t, s.Addr().Interface())
-			for i := 0; i < typ.NumField(); i++ {
-				field := typ.Field(i)
-				if ast.IsExported(field.Name) {
-					name := field.Name
-					if r.fieldNameMapper != nil {
-						name = r.fieldNameMapper.FieldName(typ, field)
-					}
-					var v Value
-					if field.Anonymous {
-						v = o
-					} else {
-						v = o.self.getStr(unistring.NewFromString(name), nil)
-					}
-
-					if v != nil {
-						err := r.toReflectValue(v, s.Field(i), ctx)
-						if err != nil {
-							return fmt.Errorf("could not convert struct value %v to %v for field %s: %w", v, field.Type, field.Name, err)
-						}
-					}
-				}
-			}
-			return nil
+			ctx.putTyped(o, t, dst.Addr().Interface())
+			return r.exportStructFields(o, dst, ctx)
+		}
+	case reflect.Chan:
+		if o, ok := v.(*Object); ok && r.loop != nil {
+			return r.loop.exportToChan(o, dst, typ)
 		}
 	case reflect.Func:
 		if fn, ok := AssertFunction(v); ok {
 			dst.Set(reflect.MakeFunc(typ, r.wrapJSFunc(fn, typ)))
 			return nil
 		}
+	case reflect.Interface:
+		if o, ok := v.(*Object); ok {
+			if f := r.interfaceProxies[typ]; f != nil {
+				return r.exportToProxy(o, dst, f)
+			}
+		}
 	case reflect.Ptr:
 		if o, ok := v.(*Object); ok {
 			if v, exists := ctx.getTyped(o, typ); exists {
@@ -2236,6 +2920,93 @@ func (r *Runtime) toReflectValue(v Value, dst reflect.Value, ctx *objectExportCt
 	return fmt.Errorf("could not convert %v to %v", v, typ)
 }
 
+// exportStructFields exports the properties of o into the fields of the struct s. The fields of unexported embedded
+// structs are exported one by one, as the struct value itself cannot be set.
+func (r *Runtime) exportStructFields(o *Object, s reflect.Value, ctx *objectExportCtx) error {
+	typ := s.Type()
+	names := r.fieldNames(typ)
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !ast.IsExported(field.Name) {
+			if r.promotesEmbedded(field) {
+				if err := r.exportStructFields(o, s.Field(i), ctx); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		var v Value
+		if field.Anonymous && r.embeddedStructMode != EmbeddedStructNest {
+			v = o
+		} else {
+			v = o.self.getStr(unistring.NewFromString(names[i]), nil)
+		}
+
+		if v != nil {
+			err := r.toReflectValue(v, s.Field(i), ctx)
+			if err != nil {
+				return fmt.Errorf("could not convert struct value %v to %v for field %s: %w", v, field.Type, field.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// exportToTime converts a Date, a number of milliseconds since the epoch or a date string into time.Time. The
+// second result is false if v is none of these.
+func (r *Runtime) exportToTime(v Value) (time.Time, bool, error) {
+	switch v := v.(type) {
+	case *Object:
+		if d, ok := v.self.(*dateObject); ok {
+			if !d.isSet() {
+				return time.Time{}, true, errors.New("invalid Date")
+			}
+			return d.exportedTime(), true, nil
+		}
+	case valueInt, valueFloat:
+		msec := v.ToFloat()
+		if math.IsNaN(msec) || math.Abs(msec) > maxTime {
+			return time.Time{}, true, errors.New("time value out of range")
+		}
+		return timeFromMsec(int64(msec)), true, nil
+	case valueString:
+		if tme, ok := r.dateParse(v.String()); ok {
+			return tme, true, nil
+		}
+		return time.Time{}, true, errors.New("invalid date string")
+	}
+	return time.Time{}, false, nil
+}
+
+// exportToDuration converts a number of duration units (see SetDurationUnit) or a string accepted by
+// time.ParseDuration into time.Duration. The second result is false if v is an object, null or undefined, which are
+// converted the usual way.
+func (r *Runtime) exportToDuration(v Value) (time.Duration, bool, error) {
+	var f float64
+	switch v := v.(type) {
+	case valueInt, valueFloat:
+		f = v.ToFloat()
+	case valueString:
+		if d, err := time.ParseDuration(v.String()); err == nil {
+			return d, true, nil
+		}
+		f = v.ToFloat()
+		if math.IsNaN(f) {
+			return 0, true, errors.New("invalid duration string")
+		}
+	case *Object, valueUndefined, valueNull:
+		return 0, false, nil
+	default:
+		return 0, true, errors.New("not a number or a string")
+	}
+	f *= float64(r.durationUnit)
+	// float64(math.MaxInt64) is 2^63, which is already out of range
+	if math.IsNaN(f) || f >= math.MaxInt64 || f < math.MinInt64 {
+		return 0, true, errors.New("duration out of range")
+	}
+	return time.Duration(f), true, nil
+}
+
 func (r *Runtime) wrapJSFunc(fn Callable, typ reflect.Type) func(args []reflect.Value) (results []reflect.Value) {
 	return func(args []reflect.Value) (results []reflect.Value) {
 		jsArgs := make([]Value, len(args))
@@ -2247,7 +3018,13 @@ func (r *Runtime) wrapJSFunc(fn Callable, typ reflect.Type) func(args []reflect.
 		results = make([]reflect.Value, numOut)
 		res, err := fn(_undefined, jsArgs...)
 		if err == nil {
-			if numOut > 0 {
+			values := numOut
+			if values > 0 && typ.Out(numOut-1) == reflectTypeError {
+				values--
+			}
+			if values > 1 {
+				err = r.exportResults(res, results[:values], typ)
+			} else if numOut > 0 {
 				v := reflect.New(typ.Out(0)).Elem()
 				err = r.toReflectValue(res, v, &objectExportCtx{})
 				if err == nil {
@@ -2258,15 +3035,7 @@ func (r *Runtime) wrapJSFunc(fn Callable, typ reflect.Type) func(args []reflect.
 
 		if err != nil {
 			if numOut > 0 && typ.Out(numOut-1) == reflectTypeError {
-				if ex, ok := err.(*Exception); ok {
-					if exo, ok := ex.val.(*Object); ok {
-						if v := exo.self.getStr("value", nil); v != nil {
-							if v.ExportType().AssignableTo(reflectTypeError) {
-								err = v.Export().(error)
-							}
-						}
-					}
-				}
+				err = r.unwrapGoError(err)
 				results[numOut-1] = reflect.ValueOf(err).Convert(typ.Out(numOut - 1))
 			} else {
 				panic(err)
@@ -2283,6 +3052,47 @@ func (r *Runtime) wrapJSFunc(fn Callable, typ reflect.Type) func(args []reflect.
 	}
 }
 
+// unwrapGoError returns the Go error carried by the exception err if it's a GoError or an instance of a constructor
+// registered with RegisterErrorMapping, otherwise it returns err.
+func (r *Runtime) unwrapGoError(err error) error {
+	if ex, ok := err.(*Exception); ok {
+		if exo, ok := ex.val.(*Object); ok {
+			if v := exo.self.getStr("value", nil); v != nil {
+				if v.ExportType().AssignableTo(reflectTypeError) {
+					return v.Export().(error)
+				}
+			} else if r.isMappedGoError(exo) {
+				return exo.self.getStr("cause", nil).Export().(error)
+			}
+		}
+	}
+	return err
+}
+
+// exportResults converts the elements of res, which must be an Array or another iterable or array-like object,
+// into the results of a function of type typ. The missing results are left zero.
+func (r *Runtime) exportResults(res Value, results []reflect.Value, typ reflect.Type) error {
+	if res == _undefined || res == _null {
+		return nil
+	}
+	var values []Value
+	ctx := &objectExportCtx{}
+	if err := r.toReflectValue(res, reflect.ValueOf(&values).Elem(), ctx); err != nil {
+		return fmt.Errorf("could not convert %v into the results of %v: %w", res, typ, err)
+	}
+	for i := range results {
+		if i >= len(values) {
+			break
+		}
+		v := reflect.New(typ.Out(i)).Elem()
+		if err := r.toReflectValue(values[i], v, ctx); err != nil {
+			return err
+		}
+		results[i] = v
+	}
+	return nil
+}
+
 // ExportTo converts a JavaScript value into the specified Go value. The second parameter must be a non-nil pointer.
 // Returns error if conversion is not possible.
 //
@@ -2297,14 +3107,43 @@ func (r *Runtime) wrapJSFunc(fn Callable, typ reflect.Type) func(args []reflect.
 // Exporting to numeric types uses the standard ECMAScript conversion operations, same as used when assigning
 // values to non-clamped typed array items, e.g. https://262.ecma-international.org/#sec-toint32.
 //
+// # Time
+//
+// A Date can be exported into time.Time (or a type based on it, or a pointer to one of these), the result has the
+// local time zone. Numbers are treated as milliseconds since the Unix epoch and strings are parsed the same way
+// Date.parse() does. An invalid Date results in an error. null and undefined produce the zero time.
+//
+// # Durations
+//
+// If a duration unit has been set with SetDurationUnit, exporting to time.Duration accepts numbers, which are
+// multiplied by the unit, and strings in the format accepted by time.ParseDuration, such as "1h30m". Numeric
+// strings are treated as numbers, other primitive values result in an error.
+//
+// # Big numbers
+//
+// Exporting a BigInt or a Number with an integral value to *big.Int results in an equal value, other Numbers result
+// in an error. Exporting a Number (except NaN) or a BigInt to *big.Float is exact.
+//
+// # Nullable database/sql types
+//
+// Exporting null or undefined to a nullable type of database/sql (such as sql.NullString or sql.Null[T]) results
+// in a value which is not valid, other values are exported (see Value.Export) and then converted by the Scan method
+// of the type, so e.g. a Date can be exported into sql.NullTime and a Number into sql.NullInt64 (which fails if
+// it's not an integer).
+//
 // # Functions
 //
 // Exporting to a 'func' creates a strictly typed 'gateway' into an ES function which can be called from Go.
 // The arguments are converted into ES values using Runtime.ToValue(). If the func has no return values,
 // the return value is ignored. If the func has exactly one return value, it is converted to the appropriate
 // type using ExportTo(). If the last return value is 'error', exceptions are caught and returned as *Exception
-// (instances of GoError are unwrapped, i.e. their 'value' is returned instead). In all other cases exceptions
-// result in a panic. Any extra return values are zeroed.
+// (instances of GoError are unwrapped, i.e. their 'value' is returned instead, and so are the instances of the
+// constructors registered with RegisterErrorMapping, whose 'cause' is returned). In all other cases exceptions
+// result in a panic.
+//
+// If the func has more than one return value not counting the trailing 'error', the ES function is expected to
+// return an Array (or any other value that can be exported into a slice) whose elements are converted into the
+// return values in order. Missing elements, as well as undefined or null instead of an Array, result in zero values.
 //
 // 'this' value will always be set to 'undefined'.
 //
@@ -2312,18 +3151,28 @@ func (r *Runtime) wrapJSFunc(fn Callable, typ reflect.Type) func(args []reflect.
 //
 // # Map types
 //
-// An ES Map can be exported into a Go map type. If any exported key value is non-hashable, the operation panics
-// (as reflect.Value.SetMapIndex() would). Symbol.iterator is ignored.
+// An ES Map can be exported into a Go map type. The keys and the values are converted to the key and the element
+// types of the map following the same rules as any other value, so a Map with numeric keys can be exported into
+// map[int]string and a Map with object keys into a map with a struct key type. If any exported key value is
+// non-hashable (e.g. an object exported into interface{}) or if two keys are converted into the same Go value
+// (e.g. 1 and "1" exported into map[string]string), an error is returned. Symbol.iterator is ignored.
 //
 // Exporting an ES Set into a map type results in the map being populated with (element) -> (zero value) key/value
-// pairs. If any value is non-hashable, the operation panics (as reflect.Value.SetMapIndex() would).
+// pairs, or (element) -> true if the element type of the map is a bool, so both map[T]struct{} and map[T]bool
+// can be used as sets. The elements are converted to the key type of the map. If any exported element is
+// non-hashable, an error is returned. Elements which are converted into the same Go value are merged.
 // Symbol.iterator is ignored.
 //
 // Any other Object populates the map with own enumerable non-symbol properties.
 //
 // # Slice types
 //
-// Exporting an ES Set into a slice type results in its elements being exported.
+// Exporting an ES Set into a slice type results in its elements being exported into the element type of the
+// slice, in the insertion order.
+//
+// Exporting a Uint8Array or a Uint8ClampedArray into a slice type with the byte element type (such as []byte)
+// copies its bytes directly, or, if SetUnsafeBytesExport(true) has been called, returns a slice sharing its buffer.
+// If the buffer is detached, an error is returned.
 //
 // Exporting any Object that implements the iterable protocol (https://developer.mozilla.org/en-US/docs/Web/JavaScript/Reference/Iteration_protocols#the_iterable_protocol)
 // into a slice type results in the slice being populated with the results of the iteration.
@@ -2340,6 +3189,23 @@ func (r *Runtime) wrapJSFunc(fn Callable, typ reflect.Type) func(args []reflect.
 // Anything that can be exported to a slice type can also be exported to an array type, as long as the lengths
 // match. If they do not, an error is returned.
 //
+// # Channel types
+//
+// If the Runtime has a Loop (see NewLoop), any async iterable or iterable Object (such as a ReadableStream or an
+// Array) can be exported into a channel type that allows receiving. The resulting unbuffered channel is fed by
+// the loop: each value produced by the iterator is converted into the element type of the channel and the next
+// one is requested once it has been received. The channel is closed when the iteration completes. If the iterator
+// throws, returns a rejected promise, or a value can't be converted, the channel is closed and the error is either
+// sent to the channel set by Loop.SetExportErrors or, if there is none, stops the loop. Note that the values are
+// only produced while the loop is running.
+//
+// # Interface types
+//
+// An Object can be exported into an interface type with methods if a factory has been registered for it with
+// RegisterInterfaceProxy. The factory receives an ObjectProxy and returns a value implementing the interface
+// by calling the object's methods. Without a factory only the values whose exported type implements the
+// interface (such as wrapped Go values) can be exported.
+//
 // # Proxy
 //
 // Proxy objects are treated the same way as if they were accessed from ES code in regard to their properties
@@ -2354,6 +3220,15 @@ func (r *Runtime) ExportTo(v Value, target interface{}) error {
 	return r.toReflectValue(v, tval.Elem(), &objectExportCtx{})
 }
 
+// ExportToReflect is like ExportTo, but it exports v directly into dst, which must be settable (see
+// reflect.Value.CanSet), so e.g. a field of a struct can be set without taking its address.
+func (r *Runtime) ExportToReflect(v Value, dst reflect.Value) error {
+	if !dst.IsValid() || !dst.CanSet() {
+		return errors.New("target must be a settable value")
+	}
+	return r.toReflectValue(v, dst, &objectExportCtx{})
+}
+
 // GlobalObject returns the global object.
 func (r *Runtime) GlobalObject() *Object {
 	return r.globalObject
@@ -2398,12 +3273,203 @@ func (r *Runtime) SetRandSource(source RandSource) {
 	r.rand = source
 }
 
+// SetDurationUnit makes ToValue() convert time.Duration values into numbers of the given unit (e.g. time.Millisecond)
+// rather than into reflect based host objects (the default), and ExportTo() convert numbers and duration strings
+// back into time.Duration. A duration which is not a whole number of units becomes a fractional number. A unit which
+// is not positive restores the default behaviour.
+func (r *Runtime) SetDurationUnit(unit time.Duration) {
+	r.durationUnit = unit
+}
+
+// SetTimeToDate sets whether ToValue() converts time.Time and *time.Time values to Date objects rather than to
+// reflect based host objects (the default). See ToValue() for details. It applies to the struct fields, map
+// values, etc. of these types as well.
+func (r *Runtime) SetTimeToDate(enabled bool) {
+	r.timeToDate = enabled
+}
+
+// NilConversion defines how ToValue() converts nil Go values. See SetNilConversion.
+type NilConversion int
+
+const (
+	// NilDefault converts nil pointers and interfaces to null, nil slices and maps are converted into empty host
+	// objects, except a nil map[string]interface{} which is converted to null. This is the default.
+	NilDefault NilConversion = iota
+
+	// NilToNull converts all nil pointers, interfaces, maps, slices, functions and channels to null.
+	NilToNull
+
+	// NilToUndefined converts all nil pointers, interfaces, maps, slices, functions and channels to undefined.
+	NilToUndefined
+)
+
+// SetNilConversion sets how ToValue() converts nil values, including the untyped nil. It applies to the results of
+// the wrapped Go functions, the struct fields, map values and slice elements as well. It does not change how Export()
+// treats null and undefined, both are exported as nil.
+func (r *Runtime) SetNilConversion(mode NilConversion) {
+	r.nilConversion = mode
+}
+
+func (r *Runtime) nilValue() Value {
+	if r.nilConversion == NilToUndefined {
+		return _undefined
+	}
+	return _null
+}
+
+// SetStrictPointerExport sets whether ExportTo() into a pointer type returns an error if the value is undefined
+// rather than setting the pointer to nil (the default). null is still exported as a nil pointer. This applies to the
+// arguments of the wrapped Go functions (an error results in a TypeError) and to the fields of structs, however the
+// missing arguments and properties are not affected: the former are set to nil, the latter are left unchanged.
+func (r *Runtime) SetStrictPointerExport(enabled bool) {
+	r.strictPointerExport = enabled
+}
+
+// SetSortedMapKeys sets whether the Go maps converted by ToValue() enumerate their keys in the ascending order
+// (numerically for the numeric key types) rather than in Go's random map iteration order (the default). This makes
+// the output of for...in loops, Object.keys() and JSON.stringify() deterministic, at the cost of sorting the keys
+// on each enumeration. It applies to the maps which are already wrapped as well.
+func (r *Runtime) SetSortedMapKeys(enabled bool) {
+	r.sortedMapKeys = enabled
+}
+
+// SetBigFloatToNumber sets whether ToValue() converts *big.Float values to Numbers rather than to reflect based
+// host objects (the default). The conversion is lossy: the value is rounded to the nearest float64, and the values
+// beyond its range become Infinity or -Infinity. ExportTo() into *big.Float accepts Numbers and BigInts regardless
+// of this setting.
+func (r *Runtime) SetBigFloatToNumber(enabled bool) {
+	r.bigFloatToNumber = enabled
+}
+
+// SetSQLNullToValue sets whether ToValue() converts the nullable types of database/sql (such as sql.NullString or
+// sql.Null[T]) into null or their values rather than into reflect based host objects (the default). See ToValue()
+// for details. ExportTo() into these types accepts null, undefined and the values accepted by their Scan method
+// regardless of this setting.
+func (r *Runtime) SetSQLNullToValue(enabled bool) {
+	r.sqlNulls = enabled
+}
+
+// SetJSONMarshalerToValue sets whether ToValue() converts the values implementing json.Marshaler (such as
+// json.RawMessage), or whose address implements it, by parsing the output of their MarshalJSON method, the same
+// way JSON.parse() would, rather than wrapping them as reflect based host objects (the default). This makes types
+// with a custom JSON representation appear in scripts (and in the output of JSON.stringify()) the way they appear
+// in Go's JSON. It applies to the struct fields, map values, etc. as well. The result is a plain JavaScript value,
+// so, unlike the wrapped values, it's not connected to the original one: changing it does not affect the Go value
+// and Export() returns a map[string]interface{}, a []interface{} or a primitive value.
+//
+// A nil pointer is converted to null (or undefined, see SetNilConversion). If MarshalJSON returns an error or
+// invalid JSON, a GoError is thrown.
+func (r *Runtime) SetJSONMarshalerToValue(enabled bool) {
+	r.jsonMarshalers = enabled
+}
+
+// marshalerToValue converts i using its MarshalJSON method. The second result is false if neither i nor the address
+// of origValue implements json.Marshaler.
+func (r *Runtime) marshalerToValue(i interface{}, origValue reflect.Value) (Value, bool) {
+	m, ok := i.(json.Marshaler)
+	if ok {
+		if v := reflect.ValueOf(i); v.Kind() == reflect.Ptr && v.IsNil() {
+			return r.nilValue(), true
+		}
+	} else {
+		if !origValue.IsValid() || !origValue.CanAddr() || !origValue.CanInterface() {
+			return nil, false
+		}
+		if m, ok = origValue.Addr().Interface().(json.Marshaler); !ok {
+			return nil, false
+		}
+	}
+	b, err := m.MarshalJSON()
+	if err != nil {
+		panic(r.NewGoError(err))
+	}
+	v, err := r.parseJSON(string(b))
+	if err != nil {
+		panic(r.NewGoError(fmt.Errorf("invalid JSON returned by MarshalJSON of %T: %w", m, err)))
+	}
+	return v, true
+}
+
+// ToValueFunc converts a Go value into a JavaScript value, see RegisterConverter.
+type ToValueFunc func(r *Runtime, v interface{}) Value
+
+// ExportFunc converts a JavaScript value into a Go value, see RegisterConverter.
+type ExportFunc func(r *Runtime, v Value) (interface{}, error)
+
+type typeConverter struct {
+	toValue ToValueFunc
+	export  ExportFunc
+}
+
+// RegisterConverter sets the functions used to convert the values of the Go type typ to and from JavaScript,
+// overriding the default rules described in ToValue and ExportTo. It's meant for the types that have a natural
+// JavaScript representation the default rules don't produce, such as UUIDs or decimals (which can be represented
+// as strings) or protobuf messages (plain objects).
+//
+// toValue is called by ToValue for any value whose dynamic type is exactly typ, including the struct fields, map
+// values, slice elements and function results converted on access. export is called by ExportTo when the target
+// type is exactly typ, including the nested targets such as struct fields and function parameters; it must return
+// a value assignable to typ (or nil for the zero value), an error it returns is wrapped by the error of ExportTo.
+// Export() is not affected, because it has no target type.
+//
+// Either function can be nil, in which case the default rules apply in that direction. Calling RegisterConverter
+// with both of them nil removes the converter.
+func (r *Runtime) RegisterConverter(typ reflect.Type, toValue ToValueFunc, export ExportFunc) {
+	if toValue == nil && export == nil {
+		delete(r.converters, typ)
+		return
+	}
+	if r.converters == nil {
+		r.converters = make(map[reflect.Type]typeConverter)
+	}
+	r.converters[typ] = typeConverter{toValue: toValue, export: export}
+}
+
+// SetUnsafeBytesExport sets whether ExportTo() of a Uint8Array or a Uint8ClampedArray into []byte (or another slice
+// type with the byte element type) returns a slice that shares the memory of the array's buffer instead of a copy
+// (the default). This avoids copying in high-throughput binary pipelines, but it's unsafe: the changes made on
+// either side are visible on the other one, and the slice keeps referring to the old memory if the buffer is
+// detached. The capacity of the slice is limited to the length of the array, so appending to it never overwrites
+// the rest of the buffer.
+func (r *Runtime) SetUnsafeBytesExport(enabled bool) {
+	r.unsafeBytesExport = enabled
+}
+
 // SetTimeSource sets the current time source for this Runtime.
 // If not called, the default time.Now() is used.
 func (r *Runtime) SetTimeSource(now Now) {
 	r.now = now
 }
 
+// SetLocaleProvider sets the source of the locale data, see LocaleProvider. Pass nil to restore the default one
+// (see DefaultLocaleProvider).
+func (r *Runtime) SetLocaleProvider(provider LocaleProvider) {
+	r.localeProvider = provider
+}
+
+// SetStackTraceFormat sets the format of the 'stack' property of Error objects. The property is computed on the
+// first access, so the format applies to all the Error objects whose 'stack' has not been accessed yet.
+// It does not affect Exception.String() and Exception.Error().
+func (r *Runtime) SetStackTraceFormat(format StackTraceFormat) {
+	r.stackTraceFormat = format
+}
+
+// SetPrepareStackTrace sets a function that computes the value of the 'stack' property of Error objects instead of
+// the built-in formatter, similar to Error.prepareStackTrace in V8. The 'stack' property is computed lazily, on the
+// first access, so the function is called from whatever code reads it first and may throw (by panicking with a
+// value created by the Runtime, e.g. NewTypeError). The property is not yet defined while the function runs.
+// Pass nil to restore the default behaviour.
+func (r *Runtime) SetPrepareStackTrace(fn PrepareStackTraceFunc) {
+	r.prepareStackTrace = fn
+}
+
+// SetDateParseLayouts sets additional layouts (in the format accepted by time.Parse) that Date.parse() and
+// the Date constructor try after the built-in ones. Values that do not specify a time zone are interpreted
+// in the local time zone.
+func (r *Runtime) SetDateParseLayouts(layouts ...string) {
+	r.dateParseLayouts = layouts
+}
+
 // SetParserOptions sets parser options to be used by RunString, RunScript and eval() within the code.
 func (r *Runtime) SetParserOptions(opts ...parser.Option) {
 	r.parserOptions = opts
@@ -2412,10 +3478,51 @@ func (r *Runtime) SetParserOptions(opts ...parser.Option) {
 // SetMaxCallStackSize sets the maximum function call depth. When exceeded, a *StackOverflowError is thrown and
 // returned by RunProgram or by a Callable call. This is useful to prevent memory exhaustion caused by an
 // infinite recursion. The default value is math.MaxInt32.
+// The limit also bounds the depth of the Go stack used by the calls between the native functions and the
+// JavaScript ones (e.g. a recursive Array.prototype.map callback), which would otherwise crash the process with a Go
+// stack overflow. If the Runtime is not running, the memory used by a deeper call stack is released.
 // This method (as the rest of the Set* methods) is not safe for concurrent use and may only be called
 // from the vm goroutine or when the vm is not running.
 func (r *Runtime) SetMaxCallStackSize(size int) {
-	r.vm.maxCallStackSize = size
+	vm := r.vm
+	vm.maxCallStackSize = size
+	if len(vm.callStack) == 0 && cap(vm.callStack) > size {
+		vm.callStack = nil
+	}
+}
+
+// SetCatchableStackOverflow sets whether exceeding the maximum call stack size (see SetMaxCallStackSize) throws a
+// RangeError which can be caught by the script, like in the browsers, instead of the uncatchable
+// *StackOverflowError. The RangeError is returned as *Exception if it's not caught. Disabled by default.
+func (r *Runtime) SetCatchableStackOverflow(enabled bool) {
+	r.vm.catchableStackOverflow = enabled
+}
+
+// SetTimeLimit limits the wall-clock time a run may take, a non-positive d removes the limit. A run is a call from Go
+// into the Runtime, such as RunString, RunProgram or a Callable call, including the promise jobs which run before it
+// returns. The clock starts when the run begins and is checked on the loop iterations and the function calls. When
+// the limit is exceeded the Runtime is interrupted (see Interrupt) with a *TimeLimitError, so the run returns an
+// *InterruptedError that can't be caught by the script. A single call of a built-in function (e.g. sorting a huge
+// array) is not interrupted until it returns.
+//
+// Unlike Interrupt, this method is not goroutine-safe, it may only be called when the Runtime is not running.
+func (r *Runtime) SetTimeLimit(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	r.vm.timeLimit = d
+	r.vm.deadline = time.Time{}
+}
+
+// SetTailCalls enables or disables proper tail calls for the code compiled by RunString, RunScript, CompileModule,
+// eval() and the Function constructor. When enabled, a call in a tail position (i.e. 'return f(...)') replaces the
+// calling function's frame instead of creating a new one, so that deeply recursive functional-style code does not
+// exceed the maximum call stack size. Frames replaced this way do not appear in stack traces.
+// Calls inside try blocks, for-in and for-of loops, async functions and derived class constructors are never
+// optimised, and calls made through bound functions or proxies still consume the stack. Programs created by Compile
+// are not affected. Disabled by default.
+func (r *Runtime) SetTailCalls(enabled bool) {
+	r.tailCalls = enabled
 }
 
 // New is an equivalent of the 'new' operator allowing to call it directly from Go.
@@ -2481,6 +3588,9 @@ func (r *Runtime) runWrapped(f func()) (err error) {
 			}
 		}
 	}()
+	if len(r.vm.callStack) == 0 {
+		r.vm.startTimeLimit()
+	}
 	ex := r.vm.try(f)
 	if ex != nil {
 		err = ex
@@ -2764,20 +3874,27 @@ func (r *Runtime) getHash() *maphash.Hash {
 
 // called when the top level function returns normally (i.e. control is passed outside the Runtime).
 func (r *Runtime) leave() {
-	var jobs []func()
-	for len(r.jobQueue) > 0 {
-		jobs, r.jobQueue = r.jobQueue, jobs[:0]
-		for _, job := range jobs {
-			job()
+	if !r.manualJobs {
+		var jobs []func()
+		for len(r.jobQueue) > 0 {
+			jobs, r.jobQueue = r.jobQueue, jobs[:0]
+			for _, job := range jobs {
+				job()
+			}
 		}
+		r.jobQueue = nil
 	}
-	r.jobQueue = nil
+	r.keptObjects = nil
 	r.vm.stack = nil
+	r.vm.deadline = time.Time{}
+	r.cleanupWeakCollections()
 }
 
 // called when the top level function returns (i.e. control is passed outside the Runtime) but it was due to an interrupt
 func (r *Runtime) leaveAbrupt() {
 	r.jobQueue = nil
+	r.keptObjects = nil
+	r.vm.deadline = time.Time{}
 	r.ClearInterrupt()
 }
 
@@ -2889,6 +4006,9 @@ func (r *Runtime) setGlobal(name unistring.String, v Value, strict bool) {
 }
 
 func (r *Runtime) trackPromiseRejection(p *Promise, operation PromiseRejectionOperation) {
+	if r.loopRejectionTracker != nil {
+		r.loopRejectionTracker(p, operation)
+	}
 	if r.promiseRejectionTracker != nil {
 		r.promiseRejectionTracker(p, operation)
 	}
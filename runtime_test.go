@@ -1,6 +1,9 @@
 package goja
 
 import (
+	gocontext "context"
+	"database/sql"
+	gohex "encoding/hex"
 	"errors"
 	"fmt"
 	"math"
@@ -406,6 +409,247 @@ func TestInterrupt(t *testing.T) {
 	}
 }
 
+func TestTimeLimit(t *testing.T) {
+	vm := New()
+	vm.SetTimeLimit(50 * time.Millisecond)
+	for _, script := range []string{
+		`for (;;) {}`,
+		`do {} while (true)`,
+		`function f(n) { if (n > 0) { f(n - 1); f(n - 1); } } f(100)`,
+		`try { for (;;) {} } catch (e) {} finally {}`,
+		`while (true) {}`,
+		`try { while (true) {} } catch (e) {} 1`,
+		`[1, 2, 3].forEach(function f() { while (true) {} })`,
+		`var stop = false; do {} while (!stop)`,
+		`for (;;) { try { continue; } finally {} }`,
+		`for (;;) { try { throw 1; } catch (e) {} }`,
+	} {
+		start := time.Now()
+		watchdog := time.AfterFunc(5*time.Second, func() {
+			vm.Interrupt("not interrupted by the time limit")
+		})
+		_, err := vm.RunString(script)
+		watchdog.Stop()
+		var tle *TimeLimitError
+		if !errors.As(err, &tle) || tle.Limit != 50*time.Millisecond {
+			t.Fatalf("%s: unexpected error: %v", script, err)
+		}
+		if _, ok := err.(*InterruptedError); !ok {
+			t.Fatalf("%s: unexpected error type: %T", script, err)
+		}
+		if d := time.Since(start); d > 5*time.Second {
+			t.Fatalf("%s: took %v", script, d)
+		}
+	}
+
+	res, err := vm.RunString(`let n = 0; for (let i = 0; i < 1000; i++) { n += i; } n`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.ToInteger() != 499500 {
+		t.Fatal(res)
+	}
+
+	vm.SetTimeLimit(0)
+	if _, err := vm.RunString(`for (let i = 0; i < 1e5; i++) {}`); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMultipleResults(t *testing.T) {
+	vm := New()
+	vm.Set("divmod", NamedResults(func(a, b int) (int, int, error) {
+		if b == 0 {
+			return 0, 0, errors.New("division by zero")
+		}
+		return a / b, a % b, nil
+	}, "quotient", "remainder"))
+	vm.Set("pair", func() (string, int) {
+		return "a", 1
+	})
+	res, err := vm.RunString(`
+	var r = divmod(7, 2);
+	var [s, n] = pair();
+	try {
+		divmod(1, 0);
+		throw new Error("no exception");
+	} catch (e) {
+		if (e.value.Error() !== "division by zero") throw e;
+	}
+	r.quotient + "," + r.remainder + "," + s + n;
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := res.String(); s != "3,1,a1" {
+		t.Fatal(s)
+	}
+
+	var split func(s string) (string, int, error)
+	v, err := vm.RunString(`(function(s) { if (s === "") throw new Error("empty"); return [s.toUpperCase(), s.length] })`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.ExportTo(v, &split); err != nil {
+		t.Fatal(err)
+	}
+	if s, n, err := split("abc"); s != "ABC" || n != 3 || err != nil {
+		t.Fatal(s, n, err)
+	}
+	if _, _, err := split(""); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expected a panic")
+			}
+		}()
+		NamedResults(func() (int, int) { return 0, 0 }, "a")
+	}()
+}
+
+type testTimeoutError struct {
+	op string
+}
+
+func (e *testTimeoutError) Error() string {
+	return e.op + " timed out"
+}
+
+func TestErrorMapping(t *testing.T) {
+	vm := New()
+	ctor, err := vm.RunString(`
+	class TimeoutError extends Error {
+		get name() { return "TimeoutError" }
+	}
+	TimeoutError;
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.RegisterErrorMapping(reflect.TypeOf(&testTimeoutError{}), ctor); err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.RegisterErrorMapping(reflect.TypeOf(0), ctor); err == nil {
+		t.Fatal("expected an error for a non-error type")
+	}
+	if err := vm.RegisterErrorMapping(reflect.TypeOf(&testTimeoutError{}), vm.ToValue(1)); err == nil {
+		t.Fatal("expected an error for a non-constructor")
+	}
+	timeout := &testTimeoutError{op: "read"}
+	vm.Set("f", func(wrap bool) error {
+		if wrap {
+			return fmt.Errorf("fetching: %w", timeout)
+		}
+		return errors.New("other")
+	})
+	res, err := vm.RunString(`
+	var results = [];
+	for (const wrap of [true, false]) {
+		try {
+			f(wrap);
+		} catch (e) {
+			results.push(e instanceof TimeoutError, e.name, e.message, e.cause && e.cause.Error());
+		}
+	}
+	results.join();
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := res.String(); s != "true,TimeoutError,fetching: read timed out,fetching: read timed out,false,GoError,other," {
+		t.Fatal(s)
+	}
+
+	var g func() error
+	v, err := vm.RunString(`(function() { f(true) })`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.ExportTo(v, &g); err != nil {
+		t.Fatal(err)
+	}
+	if err := g(); !errors.Is(err, timeout) {
+		t.Fatal(err)
+	}
+}
+
+func TestErrorMappingThrowingConstructor(t *testing.T) {
+	vm := New()
+	ctor, err := vm.RunString(`
+	(class BrokenError extends Error {
+		constructor(message) {
+			super(message);
+			throw new Error("broken");
+		}
+	})
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.RegisterErrorMapping(reflect.TypeOf(&testTimeoutError{}), ctor); err != nil {
+		t.Fatal(err)
+	}
+	vm.Set("f", func() error {
+		return &testTimeoutError{op: "write"}
+	})
+	res, err := vm.RunString(`
+	var results = [];
+	for (let i = 0; i < 3; i++) {
+		try {
+			[1, 2, 3].map(x => x + f());
+		} catch (e) {
+			results.push(e.name + ": " + e.message);
+		}
+	}
+	results.join();
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := res.String(); s != "GoError: write timed out,GoError: write timed out,GoError: write timed out" {
+		t.Fatal(s)
+	}
+	if e := vm.NewGoError(&testTimeoutError{op: "read"}); e.Get("name").String() != "GoError" {
+		t.Fatal(e)
+	}
+}
+
+type ctxKey struct{}
+
+func TestRunContext(t *testing.T) {
+	vm := New()
+	ctx := gocontext.WithValue(gocontext.Background(), ctxKey{}, "value")
+	vm.Set("native", func(ctx gocontext.Context, call FunctionCall) Value {
+		return vm.ToValue(ctx.Value(ctxKey{}))
+	})
+	vm.Set("wrapped", func(ctx gocontext.Context, s string, n ...int) string {
+		return fmt.Sprint(ctx.Value(ctxKey{}), s, n)
+	})
+	res, err := vm.RunStringContext(ctx, `native() + " " + wrapped("a", 1, 2) + " " + wrapped()`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := res.String(); s != "value valuea[1 2] value[]" {
+		t.Fatal(s)
+	}
+	if vm.Context() != gocontext.Background() {
+		t.Fatal("the context has not been restored")
+	}
+
+	ctx, cancel := gocontext.WithTimeout(gocontext.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = vm.RunStringContext(ctx, `for (;;) {}`)
+	if !errors.Is(err, gocontext.DeadlineExceeded) {
+		t.Fatal(err)
+	}
+	if _, err := vm.RunString(`1`); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestRuntime_ExportToNumbers(t *testing.T) {
 	vm := New()
 	t.Run("int8/no overflow", func(t *testing.T) {
@@ -1175,6 +1419,37 @@ func TestJsonEncodable(t *testing.T) {
 	}
 }
 
+type customRawJsonEncodable struct {
+	digits string
+}
+
+func (e customRawJsonEncodable) JsonEncodable() interface{} {
+	return RawJSON(e.digits)
+}
+
+func TestJsonEncodableRaw(t *testing.T) {
+	vm := New()
+	vm.Set("n", customRawJsonEncodable{digits: "12345678901234567890.5"})
+	raw, err := vm.NewRawJSON("123456789012345678901234567890")
+	if err != nil {
+		t.Fatal(err)
+	}
+	vm.Set("raw", raw)
+
+	ret, err := vm.RunString(`JSON.stringify({n: n, raw: raw, isRaw: JSON.isRawJSON(raw)})`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := ret.String(); s != `{"n":12345678901234567890.5,"raw":123456789012345678901234567890,"isRaw":true}` {
+		t.Fatalf("Unexpected result: %s", s)
+	}
+
+	_, err = vm.NewRawJSON("{}")
+	if ex, ok := err.(*Exception); !ok || !ex.Value().ToObject(vm).Get("name").SameAs(asciiString("SyntaxError")) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
 func TestSortComparatorReturnValues(t *testing.T) {
 	const SCRIPT = `
 	var a = [];
@@ -2245,6 +2520,43 @@ func TestStackOverflowError(t *testing.T) {
 	}
 }
 
+func TestCatchableStackOverflow(t *testing.T) {
+	vm := New()
+	vm.SetMaxCallStackSize(50)
+	vm.SetCatchableStackOverflow(true)
+	res, err := vm.RunString(`
+	let depth = 0;
+	function f() {
+		depth++;
+		[1].map(f);
+	}
+	let caught;
+	try {
+		f();
+	} catch (e) {
+		caught = e instanceof RangeError && e.message;
+	}
+	caught + " at " + depth;
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := res.String(); s != "Maximum call stack size exceeded at 25" {
+		t.Fatal(s)
+	}
+
+	_, err = vm.RunString(`(function g() { g(); })()`)
+	if ex, ok := err.(*Exception); !ok || !strings.Contains(ex.Error(), "RangeError: Maximum call stack size exceeded") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	vm.SetCatchableStackOverflow(false)
+	_, err = vm.RunString(`try { f(); } catch (e) {}`)
+	if _, ok := err.(*StackOverflowError); !ok {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 func TestStacktraceLocationThrowFromCatch(t *testing.T) {
 	vm := New()
 	_, err := vm.RunString(`
@@ -2599,6 +2911,92 @@ func TestPromiseExport(t *testing.T) {
 	}
 }
 
+func TestPromiseRejectionTracker(t *testing.T) {
+	vm := New()
+	unhandled := make(map[*Promise]struct{})
+	var log []string
+	vm.SetPromiseRejectionTracker(func(p *Promise, operation PromiseRejectionOperation) {
+		switch operation {
+		case PromiseRejectionReject:
+			log = append(log, "reject "+p.Result().String())
+			unhandled[p] = struct{}{}
+		case PromiseRejectionHandle:
+			log = append(log, "handle "+p.Result().String())
+			delete(unhandled, p)
+		}
+	})
+	_, err := vm.RunString(`
+	Promise.reject("late").catch(() => {});
+	const p = Promise.reject("unhandled");
+	Promise.reject("handled").catch(() => {});
+	(async () => { throw "async"; })();
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := strings.Join(log, ","); s != "reject late,handle late,reject unhandled,reject handled,handle handled,reject async" {
+		t.Fatal(s)
+	}
+	if len(unhandled) != 2 {
+		t.Fatalf("unhandled: %d", len(unhandled))
+	}
+	if _, err := vm.RunString(`p.then(null, () => {})`); err != nil {
+		t.Fatal(err)
+	}
+	if len(unhandled) != 1 {
+		t.Fatalf("unhandled: %d", len(unhandled))
+	}
+}
+
+func TestManualJobs(t *testing.T) {
+	vm := New()
+	vm.SetManualJobs(true)
+	_, err := vm.RunString(`
+	var log = [];
+	Promise.resolve().then(() => log.push(1)).then(() => log.push(2));
+	(async () => { await null; log.push("async"); })();
+	function loop() { Promise.resolve().then(loop); }
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := vm.PendingJobs(); n != 2 {
+		t.Fatalf("pending: %d", n)
+	}
+	if n, err := vm.DrainJobs(0); err != nil || n != 3 {
+		t.Fatalf("%d, %v", n, err)
+	}
+	if res := vm.Get("log").String(); res != "1,async,2" {
+		t.Fatal(res)
+	}
+
+	if _, err := vm.RunString(`loop()`); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := vm.DrainJobs(100); err != nil || n != 100 {
+		t.Fatalf("%d, %v", n, err)
+	}
+	if n := vm.PendingJobs(); n != 1 {
+		t.Fatalf("pending: %d", n)
+	}
+
+	vm.Interrupt("halt")
+	if _, err := vm.DrainJobs(0); err == nil {
+		t.Fatal("expected an error")
+	}
+	if n := vm.PendingJobs(); n != 0 {
+		t.Fatalf("pending: %d", n)
+	}
+
+	vm.SetManualJobs(false)
+	if _, err := vm.RunString(`Promise.resolve().then(() => log.push(3))`); err != nil {
+		t.Fatal(err)
+	}
+	if res := vm.Get("log").String(); res != "1,async,2,3" {
+		t.Fatal(res)
+	}
+}
+
 func TestErrorStack(t *testing.T) {
 	const SCRIPT = `
 	const err = new Error("test");
@@ -2620,6 +3018,79 @@ func TestErrorStack(t *testing.T) {
 	testScript(SCRIPT, _undefined, t)
 }
 
+func TestErrorStackV8Format(t *testing.T) {
+	const SCRIPT = `
+	function f() {
+		return new Error("test");
+	}
+	const err = f();
+	const err1 = (() => new TypeError("anon"))();
+	[err.stack, err1.stack, [1].map(function g() { return new Error("x") })[0].stack];
+	`
+	r := New()
+	r.SetStackTraceFormat(StackTraceFormatV8)
+	prg := MustCompile("test.js", SCRIPT, false)
+	v, err := r.RunProgram(prg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stacks []string
+	if err := r.ExportTo(v, &stacks); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"Error: test\n    at f (test.js:3:10)\n    at test.js:5:15",
+		"TypeError: anon\n    at test.js:6:22\n    at test.js:6:44",
+		"Error: x\n    at g (test.js:7:56)\n    at map (native)\n    at test.js:7:33",
+	}
+	for i, s := range expected {
+		if stacks[i] != s {
+			t.Fatalf("%d: %q", i, stacks[i])
+		}
+	}
+}
+
+func TestSetPrepareStackTrace(t *testing.T) {
+	const SCRIPT = `
+	function f() {
+		return new Error("test");
+	}
+	const err = f();
+	[err.stack, Object.getOwnPropertyDescriptor(err, "stack").writable, new Error("def").stack];
+	`
+	r := New()
+	var inside Value
+	r.SetPrepareStackTrace(func(err *Object, frames []StackFrame) Value {
+		if err.Get("message").String() == "def" {
+			return nil
+		}
+		inside = err.Get("stack")
+		var names []string
+		for _, frame := range frames {
+			p := frame.Position()
+			names = append(names, fmt.Sprintf("%s@%d:%d", frame.FuncName(), p.Line, p.Column))
+		}
+		return r.ToValue(strings.Join(names, ","))
+	})
+	v, err := r.RunScript("test.js", SCRIPT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	arr := v.(*Object)
+	if s := arr.Get("0").String(); s != "f@3:10,<anonymous>@5:15" {
+		t.Fatal(s)
+	}
+	if !arr.Get("1").ToBoolean() {
+		t.Fatal("not writable")
+	}
+	if s := arr.Get("2").String(); s != "Error: def\n\tat test.js:6:70(18)\n" {
+		t.Fatalf("%q", s)
+	}
+	if inside != nil {
+		t.Fatal(inside)
+	}
+}
+
 func TestErrorFormatSymbols(t *testing.T) {
 	vm := New()
 	vm.Set("a", func() (Value, error) { return nil, errors.New("something %s %f") })
@@ -2946,3 +3417,316 @@ func BenchmarkAsciiStringMapGet(b *testing.B) {
 		}
 	}
 }
+
+func TestDurationConversion(t *testing.T) {
+	vm := New()
+	vm.Set("d", 1500*time.Nanosecond)
+	res, err := vm.RunString(`d.String() === "1.5µs" && d == 1500`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.ToBoolean() {
+		t.Fatal("unexpected default conversion")
+	}
+	var dur time.Duration
+	if err := vm.ExportTo(vm.Get("d"), &dur); err != nil || dur != 1500*time.Nanosecond {
+		t.Fatal(dur, err)
+	}
+
+	vm.SetDurationUnit(time.Millisecond)
+	type config struct {
+		Timeout time.Duration
+		Retry   time.Duration
+		Max     time.Duration
+	}
+	vm.Set("c", &config{Timeout: 1500 * time.Millisecond, Retry: 1500 * time.Microsecond})
+	vm.Set("d", 2*time.Second)
+	res, err = vm.RunString(`c.Timeout === 1500 && c.Retry === 1.5 && d === 2000`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.ToBoolean() {
+		t.Fatal("unexpected values")
+	}
+
+	var cfg config
+	v, err := vm.RunString(`({ Timeout: 250, Retry: "1h30m", Max: "2.5" })`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.ExportTo(v, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Timeout != 250*time.Millisecond || cfg.Retry != 90*time.Minute || cfg.Max != 2500*time.Microsecond {
+		t.Fatal(cfg)
+	}
+
+	var d time.Duration
+	for _, src := range []string{`"soon"`, `NaN`, `Infinity`, `1e300`, `true`, `9223372036854.775807`} {
+		v, err := vm.RunString(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := vm.ExportTo(v, &d); err == nil {
+			t.Fatalf("%s: expected an error", src)
+		}
+	}
+
+	vm.SetDurationUnit(time.Second)
+	if v := vm.ToValue(1500 * time.Millisecond); v.ToFloat() != 1.5 {
+		t.Fatal(v)
+	}
+	if err := vm.ExportTo(vm.ToValue(3), &d); err != nil || d != 3*time.Second {
+		t.Fatal(d, err)
+	}
+
+	vm.SetDurationUnit(0)
+	if _, ok := vm.ToValue(time.Second).(*Object); !ok {
+		t.Fatal("expected the default conversion")
+	}
+}
+
+type testUUID [4]byte
+
+func TestRegisterConverter(t *testing.T) {
+	vm := New()
+	vm.RegisterConverter(reflect.TypeOf(testUUID{}), func(r *Runtime, v interface{}) Value {
+		u := v.(testUUID)
+		return r.ToValue(fmt.Sprintf("%x", u[:]))
+	}, func(r *Runtime, v Value) (interface{}, error) {
+		var u testUUID
+		b, err := gohex.DecodeString(v.String())
+		if err != nil {
+			return nil, err
+		}
+		copy(u[:], b)
+		return u, nil
+	})
+	type S struct {
+		ID   testUUID
+		IDs  []testUUID
+		Next func(testUUID) testUUID
+	}
+	s := &S{
+		ID:  testUUID{1, 2, 3, 4},
+		IDs: []testUUID{{0xa, 0xb, 0xc, 0xd}},
+		Next: func(u testUUID) testUUID {
+			u[3]++
+			return u
+		},
+	}
+	vm.Set("s", s)
+	res, err := vm.RunString(`
+	s.ID = s.Next("00000001");
+	typeof s.IDs[0] + "," + s.IDs[0] + "," + s.ID;
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if str := res.String(); str != "string,0a0b0c0d,00000002" {
+		t.Fatal(str)
+	}
+	if s.ID != (testUUID{0, 0, 0, 2}) {
+		t.Fatal(s.ID)
+	}
+	var u testUUID
+	if err := vm.ExportTo(vm.ToValue("zz"), &u); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	vm.RegisterConverter(reflect.TypeOf(testUUID{}), nil, nil)
+	if _, ok := vm.ToValue(testUUID{}).(*Object); !ok {
+		t.Fatal("the converter has not been removed")
+	}
+}
+
+func TestNilConversion(t *testing.T) {
+	type S struct {
+		P *int
+		M map[string]int
+		L []int
+		I interface{}
+	}
+	vm := New()
+	vm.Set("f", func() *S { return nil })
+	vm.Set("g", func(p *int) bool { return p == nil })
+
+	const SCRIPT = `[n, s.P, s.M, s.L, s.I, f(), l].map(v => v === undefined ? "undefined" : v === null ? "null" : typeof v).join()`
+	for _, tc := range []struct {
+		mode     NilConversion
+		expected string
+	}{
+		{NilDefault, "null,null,object,object,null,null,object"},
+		{NilToNull, "null,null,null,null,null,null,null"},
+		{NilToUndefined, "undefined,undefined,undefined,undefined,undefined,undefined,undefined"},
+	} {
+		vm.SetNilConversion(tc.mode)
+		vm.Set("s", &S{})
+		vm.Set("n", nil)
+		vm.Set("l", []interface{}(nil))
+		res, err := vm.RunString(SCRIPT)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := res.String(); s != tc.expected {
+			t.Fatalf("%d: %s", tc.mode, s)
+		}
+	}
+	if !vm.ToValue(map[string]interface{}{"a": nil}).(*Object).Get("a").SameAs(_undefined) {
+		t.Fatal("map value")
+	}
+
+	var p *int
+	one := 1
+	p = &one
+	if err := vm.ExportTo(_undefined, &p); err != nil || p != nil {
+		t.Fatal(p, err)
+	}
+	vm.SetStrictPointerExport(true)
+	p = &one
+	if err := vm.ExportTo(_undefined, &p); err == nil {
+		t.Fatal("expected an error")
+	}
+	if err := vm.ExportTo(_null, &p); err != nil || p != nil {
+		t.Fatal(p, err)
+	}
+	res, err := vm.RunString(`
+	let r = [g(null), g()];
+	try {
+		g(undefined);
+		r.push("no error");
+	} catch (e) {
+		r.push(e instanceof TypeError);
+	}
+	r.join();
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := res.String(); s != "true,true,true" {
+		t.Fatal(s)
+	}
+}
+
+func TestSQLNullTypes(t *testing.T) {
+	type Row struct {
+		Name  sql.NullString
+		Age   sql.NullInt64
+		Score *sql.NullFloat64
+		Seen  sql.NullTime
+	}
+	vm := New()
+	vm.SetTimeToDate(true)
+	row := &Row{
+		Name: sql.NullString{String: "John", Valid: true},
+		Seen: sql.NullTime{Time: time.UnixMilli(1000).UTC(), Valid: true},
+	}
+	vm.Set("row", row)
+	res, err := vm.RunString(`typeof row.Name.String`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := res.String(); s != "string" {
+		t.Fatal("sql types are converted by default", s)
+	}
+
+	vm.SetSQLNullToValue(true)
+	vm.Set("row", row)
+	res, err = vm.RunString(`[row.Name, row.Age, row.Score, row.Seen.getTime()].map(String).join()`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := res.String(); s != "John,null,null,1000" {
+		t.Fatal(s)
+	}
+
+	_, err = vm.RunString(`
+	row.Name = null;
+	row.Age = 42;
+	row.Score = 1.5;
+	row.Seen = undefined;
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row.Name.Valid || !row.Age.Valid || row.Age.Int64 != 42 || row.Score == nil || !row.Score.Valid ||
+		row.Score.Float64 != 1.5 || row.Seen.Valid {
+		t.Fatalf("%+v", row)
+	}
+
+	var n sql.NullInt64
+	if err := vm.ExportTo(vm.ToValue(1.5), &n); err == nil {
+		t.Fatal("expected an error")
+	}
+	var tm sql.NullTime
+	if err := vm.ExportTo(vm.ToValue(time.UnixMilli(2000)), &tm); err != nil || !tm.Valid || tm.Time.UnixMilli() != 2000 {
+		t.Fatal(tm, err)
+	}
+	var s sql.NullString
+	if err := vm.ExportTo(vm.ToValue(sql.NullString{String: "a", Valid: true}), &s); err != nil || s.String != "a" {
+		t.Fatal(s, err)
+	}
+	if err := vm.ExportTo(vm.ToValue(123), &s); err != nil || !s.Valid || s.String != "123" {
+		t.Fatal(s, err)
+	}
+
+	vm.SetNilConversion(NilToUndefined)
+	if v := vm.ToValue(sql.NullInt64{}); v != _undefined {
+		t.Fatal(v)
+	}
+	if v := vm.ToValue((*sql.NullInt64)(nil)); v != _undefined {
+		t.Fatal(v)
+	}
+}
+
+func TestToValueReflect(t *testing.T) {
+	type Inner struct {
+		N int
+	}
+	type Outer struct {
+		Inner Inner
+		List  []int
+		inner Inner
+	}
+	vm := New()
+	o := &Outer{List: []int{1}}
+	rv := reflect.ValueOf(o).Elem()
+
+	vm.Set("inner", vm.ToValueReflect(rv.Field(0)))
+	vm.Set("copy", vm.ToValue(rv.Field(0).Interface()))
+	_, err := vm.RunString(`inner.N = 1; copy.N = 2`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Inner.N != 1 {
+		t.Fatal(o.Inner.N)
+	}
+	if !vm.ToValueReflect(reflect.Value{}).SameAs(_null) {
+		t.Fatal("invalid value")
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expected a panic")
+			}
+		}()
+		vm.ToValueReflect(rv.Field(2))
+	}()
+
+	v, err := vm.RunString(`[2, 3]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.ExportToReflect(v, rv.Field(1)); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(o.List, []int{2, 3}) {
+		t.Fatal(o.List)
+	}
+	if err := vm.ExportToReflect(v, reflect.ValueOf(o.List)); err == nil {
+		t.Fatal("expected an error")
+	}
+	if err := vm.ExportToReflect(vm.ToValue(1), rv.Field(2).Field(0)); err == nil {
+		t.Fatal("expected an error")
+	}
+}
@@ -0,0 +1,76 @@
+package goja
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// sqlScanner is sql.Scanner, it's declared here so that the package does not depend on database/sql.
+type sqlScanner interface {
+	Scan(src interface{}) error
+}
+
+// isSQLNullType returns true if typ is one of the nullable types of database/sql, such as sql.NullString or
+// sql.Null[T].
+func isSQLNullType(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && typ.PkgPath() == "database/sql" && strings.HasPrefix(typ.Name(), "Null")
+}
+
+// sqlNullToValue converts a value of a nullable database/sql type, or a pointer to it, into null (or undefined, see
+// SetNilConversion) if it's not valid and into the result of ToValue() applied to its driver value otherwise. The
+// second result is false if i is not of such a type.
+func (r *Runtime) sqlNullToValue(i interface{}) (Value, bool) {
+	typ := reflect.TypeOf(i)
+	if typ == nil {
+		return nil, false
+	}
+	if typ.Kind() == reflect.Ptr {
+		if !isSQLNullType(typ.Elem()) {
+			return nil, false
+		}
+		v := reflect.ValueOf(i)
+		if v.IsNil() {
+			return r.nilValue(), true
+		}
+		i = v.Elem().Interface()
+	} else if !isSQLNullType(typ) {
+		return nil, false
+	}
+	valuer, ok := i.(driver.Valuer)
+	if !ok {
+		return nil, false
+	}
+	v, err := valuer.Value()
+	if err != nil {
+		panic(r.NewGoError(err))
+	}
+	if v == nil {
+		return r.nilValue(), true
+	}
+	return r.ToValue(v), true
+}
+
+// exportToSQLNull exports v into dst of a nullable database/sql type using its Scan method. The first result is
+// false if v is a value of this type, so it's exported as usual.
+func (r *Runtime) exportToSQLNull(v Value, dst reflect.Value) (bool, error) {
+	typ := dst.Type()
+	if et := v.ExportType(); et != nil && et.AssignableTo(typ) {
+		return false, nil
+	}
+	var src interface{}
+	if !IsUndefined(v) && !IsNull(v) {
+		src = v.Export()
+	}
+	res := reflect.New(typ)
+	scanner, ok := res.Interface().(sqlScanner)
+	if !ok {
+		return false, nil
+	}
+	if err := scanner.Scan(src); err != nil {
+		return true, fmt.Errorf("could not convert %v to %v: %w", v, typ, err)
+	}
+	dst.Set(res.Elem())
+	return true, nil
+}
@@ -24,21 +24,25 @@ var (
 	stringString      valueString = asciiString("string")
 	stringSymbol      valueString = asciiString("symbol")
 	stringNumber      valueString = asciiString("number")
+	stringBigInt      valueString = asciiString("bigint")
+	stringRecord      valueString = asciiString("record")
+	stringTuple       valueString = asciiString("tuple")
 	stringNaN         valueString = asciiString("NaN")
 	stringInfinity                = asciiString("Infinity")
 	stringNegInfinity             = asciiString("-Infinity")
 	stringBound_      valueString = asciiString("bound ")
 	stringEmpty       valueString = asciiString("")
 
-	stringError          valueString = asciiString("Error")
-	stringAggregateError valueString = asciiString("AggregateError")
-	stringTypeError      valueString = asciiString("TypeError")
-	stringReferenceError valueString = asciiString("ReferenceError")
-	stringSyntaxError    valueString = asciiString("SyntaxError")
-	stringRangeError     valueString = asciiString("RangeError")
-	stringEvalError      valueString = asciiString("EvalError")
-	stringURIError       valueString = asciiString("URIError")
-	stringGoError        valueString = asciiString("GoError")
+	stringError           valueString = asciiString("Error")
+	stringAggregateError  valueString = asciiString("AggregateError")
+	stringSuppressedError valueString = asciiString("SuppressedError")
+	stringTypeError       valueString = asciiString("TypeError")
+	stringReferenceError  valueString = asciiString("ReferenceError")
+	stringSyntaxError     valueString = asciiString("SyntaxError")
+	stringRangeError      valueString = asciiString("RangeError")
+	stringEvalError       valueString = asciiString("EvalError")
+	stringURIError        valueString = asciiString("URIError")
+	stringGoError         valueString = asciiString("GoError")
 
 	stringObjectNull      valueString = asciiString("[object Null]")
 	stringObjectUndefined valueString = asciiString("[object Undefined]")
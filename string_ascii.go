@@ -205,6 +205,10 @@ func (s asciiString) Equals(other Value) bool {
 		return false
 	}
 
+	if o, ok := other.(*valueBigInt); ok {
+		return o.Equals(s)
+	}
+
 	if o, ok := other.(*Object); ok {
 		return s.Equals(o.toPrimitive())
 	}
@@ -195,6 +195,13 @@ func (b *valueStringBuilder) ascii() bool {
 	return len(b.unicodeBuilder.buf) == 0
 }
 
+func (b *valueStringBuilder) length() int {
+	if b.ascii() {
+		return b.asciiBuilder.Len()
+	}
+	return len(b.unicodeBuilder.buf) - 1
+}
+
 func (b *valueStringBuilder) WriteString(s valueString) {
 	a, u := devirtualizeString(s)
 	if u != nil {
@@ -367,6 +374,10 @@ func (s unicodeString) Equals(other Value) bool {
 		return true
 	}
 
+	if o, ok := other.(*valueBigInt); ok {
+		return o.Equals(s)
+	}
+
 	if o, ok := other.(*Object); ok {
 		return s.Equals(o.toPrimitive())
 	}
@@ -394,6 +405,26 @@ func (s unicodeString) baseObject(r *Runtime) *Object {
 	return ss.val
 }
 
+// loneSurrogateIndex returns the index of the first surrogate code unit that is not a part of a surrogate pair,
+// or -1 if the string is well-formed.
+func (s unicodeString) loneSurrogateIndex(start int) int {
+	l := s.length()
+	for i := start; i < l; i++ {
+		c := s.charAt(i)
+		if isUTF16FirstSurrogate(c) {
+			if i+1 < l && isUTF16SecondSurrogate(s.charAt(i+1)) {
+				i++
+				continue
+			}
+			return i
+		}
+		if isUTF16SecondSurrogate(c) {
+			return i
+		}
+	}
+	return -1
+}
+
 func (s unicodeString) charAt(idx int) rune {
 	return rune(s[idx+1])
 }
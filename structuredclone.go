@@ -0,0 +1,418 @@
+package goja
+
+import (
+	"time"
+)
+
+type clonedKind int
+
+const (
+	clonedPlainObject clonedKind = iota
+	clonedArray
+	clonedBoxed
+	clonedDate
+	clonedRegExp
+	clonedArrayBuffer
+	clonedTypedArray
+	clonedDataView
+	clonedMap
+	clonedSet
+	clonedError
+	clonedMessagePort
+)
+
+// clonedValue is the result of the structured serialization of a value (see
+// https://html.spec.whatwg.org/multipage/structured-data.html). It does not reference the Runtime it was
+// created in, so it can be passed to another goroutine and deserialized into a different Runtime.
+type clonedValue struct {
+	prim Value
+	obj  *clonedObject
+}
+
+type clonedObject struct {
+	kind clonedKind
+
+	prim   Value
+	msec   int64
+	source valueString
+	flags  string
+
+	data   []byte
+	buffer *clonedObject
+	ctor   string
+	offset int
+	length int
+
+	name, message valueString
+
+	keys    []Value
+	values  []clonedValue
+	entries []clonedValue
+
+	port *MessagePort
+}
+
+// clonedMessage is a serialized message along with the MessagePorts transferred with it.
+type clonedMessage struct {
+	data  clonedValue
+	ports []*clonedObject
+}
+
+// transferables are the objects listed in the transfer argument of postMessage().
+type transferables struct {
+	buffers []*arrayBufferObject
+	ports   []*messagePortObject
+}
+
+type structuredSerializer struct {
+	r        *Runtime
+	memo     map[*Object]*clonedObject
+	transfer map[*arrayBufferObject]bool
+}
+
+func (r *Runtime) newDataCloneError(format string, args ...interface{}) *Object {
+	e := r.newError(r.global.Error, format, args...).(*Object)
+	e.self._putProp("name", asciiString("DataCloneError"), true, false, true)
+	return e
+}
+
+// transferList converts the transfer argument of postMessage(), which is either an iterable or an options object
+// with the 'transfer' member, into a list of ArrayBuffers and MessagePorts.
+func (r *Runtime) transferList(v Value) transferables {
+	var list transferables
+	if v == _undefined || v == _null {
+		return list
+	}
+	if o, ok := v.(*Object); ok && !isArray(o) {
+		if toMethod(r.getV(o, SymIterator)) == nil {
+			v = nilSafe(o.self.getStr("transfer", nil))
+			if v == _undefined {
+				return list
+			}
+		}
+	}
+	for i, item := range r.iterableToList(v, nil) {
+		o, _ := item.(*Object)
+		if o == nil {
+			panic(r.newDataCloneError("Value at index %d does not have a transferable type", i))
+		}
+		switch self := o.self.(type) {
+		case *arrayBufferObject:
+			for _, b := range list.buffers {
+				if b == self {
+					panic(r.newDataCloneError("ArrayBuffer at index %d is a duplicate of an earlier ArrayBuffer", i))
+				}
+			}
+			if self.detached {
+				panic(r.newDataCloneError("ArrayBuffer at index %d is already detached", i))
+			}
+			if self.external {
+				panic(r.newDataCloneError("ArrayBuffer at index %d is owned by the host and cannot be transferred", i))
+			}
+			list.buffers = append(list.buffers, self)
+		case *messagePortObject:
+			for _, p := range list.ports {
+				if p == self {
+					panic(r.newDataCloneError("MessagePort at index %d is a duplicate of an earlier MessagePort", i))
+				}
+			}
+			if self.port == nil {
+				panic(r.newDataCloneError("MessagePort at index %d has already been transferred", i))
+			}
+			list.ports = append(list.ports, self)
+		default:
+			panic(r.newDataCloneError("Value at index %d does not have a transferable type", i))
+		}
+	}
+	return list
+}
+
+// structuredSerialize implements StructuredSerializeWithTransfer. The transferred ArrayBuffers are detached and
+// their data is moved into the result rather than copied. The transferred MessagePorts are unbound from the loop.
+func (r *Runtime) structuredSerialize(v Value, transfer transferables) clonedMessage {
+	s := &structuredSerializer{
+		r:    r,
+		memo: make(map[*Object]*clonedObject),
+	}
+	if len(transfer.buffers) > 0 {
+		s.transfer = make(map[*arrayBufferObject]bool, len(transfer.buffers))
+		for _, buf := range transfer.buffers {
+			s.transfer[buf] = true
+		}
+	}
+	var res clonedMessage
+	for _, p := range transfer.ports {
+		c := &clonedObject{kind: clonedMessagePort, port: p.port}
+		s.memo[p.val] = c
+		res.ports = append(res.ports, c)
+	}
+	res.data = s.serialize(v)
+	for _, buf := range transfer.buffers {
+		buf.detach()
+	}
+	for _, p := range transfer.ports {
+		p.ship()
+	}
+	return res
+}
+
+func (s *structuredSerializer) serialize(v Value) clonedValue {
+	o, ok := v.(*Object)
+	if !ok {
+		if _, ok := v.(*Symbol); ok {
+			panic(s.r.newDataCloneError("%s could not be cloned", v.String()))
+		}
+		return clonedValue{prim: v}
+	}
+	if c := s.memo[o]; c != nil {
+		return clonedValue{obj: c}
+	}
+	c := &clonedObject{}
+	s.memo[o] = c
+	switch self := o.self.(type) {
+	case *primitiveValueObject:
+		if _, ok := self.pValue.(*Symbol); ok {
+			panic(s.r.newDataCloneError("Symbol object could not be cloned"))
+		}
+		c.kind = clonedBoxed
+		c.prim = self.pValue
+	case *messagePortObject:
+		panic(s.r.newDataCloneError("A MessagePort could not be cloned because it was not transferred"))
+	case *dateObject:
+		c.kind = clonedDate
+		c.msec = self.msec
+	case *regexpObject:
+		c.kind = clonedRegExp
+		c.source = self.source
+		c.flags = regexpPatternFlags(self.pattern)
+	case *arrayBufferObject:
+		c.kind = clonedArrayBuffer
+		if s.transfer[self] {
+			c.data = self.data
+		} else {
+			if self.detached {
+				panic(s.r.newDataCloneError("A detached ArrayBuffer could not be cloned"))
+			}
+			c.data = append([]byte{}, self.data...)
+		}
+	case *typedArrayObject:
+		if self.viewedArrayBuf.detached {
+			panic(s.r.newDataCloneError("A TypedArray with a detached buffer could not be cloned"))
+		}
+		c.kind = clonedTypedArray
+		c.buffer = s.serialize(self.viewedArrayBuf.val).obj
+		c.ctor = s.r.typedArrayCtorName(self.defaultCtor)
+		c.offset = self.offset * self.elemSize
+		c.length = self.length
+	case *dataViewObject:
+		if self.viewedArrayBuf.detached {
+			panic(s.r.newDataCloneError("A DataView with a detached buffer could not be cloned"))
+		}
+		c.kind = clonedDataView
+		c.buffer = s.serialize(self.viewedArrayBuf.val).obj
+		c.offset = self.byteOffset
+		c.length = self.byteLen
+	case *mapObject:
+		c.kind = clonedMap
+		var entries []Value
+		for item := self.m.iterFirst; item != nil; item = item.iterNext {
+			entries = append(entries, item.key, item.value)
+		}
+		for _, e := range entries {
+			c.entries = append(c.entries, s.serialize(e))
+		}
+	case *setObject:
+		c.kind = clonedSet
+		var entries []Value
+		for item := self.m.iterFirst; item != nil; item = item.iterNext {
+			entries = append(entries, item.key)
+		}
+		for _, e := range entries {
+			c.entries = append(c.entries, s.serialize(e))
+		}
+	case *errorObject:
+		c.kind = clonedError
+		c.name = asciiString("Error")
+		if name, ok := nilSafe(o.self.getStr("name", nil)).(valueString); ok {
+			switch name.String() {
+			case "EvalError", "RangeError", "ReferenceError", "SyntaxError", "TypeError", "URIError":
+				c.name = name
+			}
+		}
+		prop := o.self.getOwnPropStr("message")
+		if p, ok := prop.(*valueProperty); ok {
+			if p.accessor {
+				prop = nil
+			} else {
+				prop = p.value
+			}
+		}
+		if prop != nil {
+			c.message = prop.toString()
+		}
+	default:
+		switch {
+		case isArray(o) && o.self.className() == classArray:
+			c.kind = clonedArray
+			c.length = int(toLength(o.self.getStr("length", nil)))
+		case o.self.className() == classObject && isPlainObject(o):
+			c.kind = clonedPlainObject
+		default:
+			panic(s.r.newDataCloneError("%s could not be cloned", o.self.className()))
+		}
+		for item, next := iterateEnumerableStringProperties(o)(); next != nil; item, next = next() {
+			c.keys = append(c.keys, item.name)
+			c.values = append(c.values, s.serialize(item.value))
+		}
+	}
+	return clonedValue{obj: c}
+}
+
+// isPlainObject returns true if the object is an ordinary object (i.e. not a function, a proxy or a host object).
+func isPlainObject(o *Object) bool {
+	_, ok := o.self.(*baseObject)
+	return ok
+}
+
+func regexpPatternFlags(p *regexpPattern) string {
+	var flags []byte
+	if p.global {
+		flags = append(flags, 'g')
+	}
+	if p.ignoreCase {
+		flags = append(flags, 'i')
+	}
+	if p.multiline {
+		flags = append(flags, 'm')
+	}
+	if p.dotAll {
+		flags = append(flags, 's')
+	}
+	if p.unicode {
+		flags = append(flags, 'u')
+	}
+	if p.sticky {
+		flags = append(flags, 'y')
+	}
+	return string(flags)
+}
+
+type namedTypedArrayCtor struct {
+	name string
+	ctor *Object
+}
+
+func (r *Runtime) typedArrayCtors() []namedTypedArrayCtor {
+	return []namedTypedArrayCtor{
+		{"Int8Array", r.global.Int8Array},
+		{"Uint8Array", r.global.Uint8Array},
+		{"Uint8ClampedArray", r.global.Uint8ClampedArray},
+		{"Int16Array", r.global.Int16Array},
+		{"Uint16Array", r.global.Uint16Array},
+		{"Int32Array", r.global.Int32Array},
+		{"Uint32Array", r.global.Uint32Array},
+		{"Float32Array", r.global.Float32Array},
+		{"Float64Array", r.global.Float64Array},
+		{"BigInt64Array", r.global.BigInt64Array},
+		{"BigUint64Array", r.global.BigUint64Array},
+	}
+}
+
+func (r *Runtime) typedArrayCtorName(ctor *Object) string {
+	for _, c := range r.typedArrayCtors() {
+		if c.ctor == ctor {
+			return c.name
+		}
+	}
+	return "Uint8Array"
+}
+
+func (r *Runtime) typedArrayCtorByName(name string) *Object {
+	for _, c := range r.typedArrayCtors() {
+		if c.name == name {
+			return c.ctor
+		}
+	}
+	return r.global.Uint8Array
+}
+
+// structuredDeserialize creates the value described by v in this Runtime. v must not contain MessagePorts, use
+// Loop.deserializeMessage for the messages which may have them.
+func (r *Runtime) structuredDeserialize(v clonedValue) Value {
+	return r.deserializeCloned(v, make(map[*clonedObject]*Object))
+}
+
+func (r *Runtime) deserializeCloned(v clonedValue, memo map[*clonedObject]*Object) Value {
+	c := v.obj
+	if c == nil {
+		return v.prim
+	}
+	if o := memo[c]; o != nil {
+		return o
+	}
+	var o *Object
+	switch c.kind {
+	case clonedBoxed:
+		o = c.prim.ToObject(r)
+	case clonedDate:
+		o = r.newDateObject(time.Time{}, false, r.global.DatePrototype)
+		o.self.(*dateObject).msec = c.msec
+	case clonedRegExp:
+		o = r.newRegExp(c.source, asciiString(c.flags), r.global.RegExpPrototype).val
+	case clonedArrayBuffer:
+		o = r.NewArrayBuffer(c.data).buf.val
+	case clonedTypedArray:
+		buf := r.deserializeCloned(clonedValue{obj: c.buffer}, memo)
+		o = r.toConstructor(r.typedArrayCtorByName(c.ctor))([]Value{buf, intToValue(int64(c.offset)), intToValue(int64(c.length))}, nil)
+	case clonedDataView:
+		buf := r.deserializeCloned(clonedValue{obj: c.buffer}, memo)
+		o = r.toConstructor(r.global.DataView)([]Value{buf, intToValue(int64(c.offset)), intToValue(int64(c.length))}, nil)
+	case clonedMap:
+		o = r.builtin_newMap(nil, r.global.Map)
+		m := o.self.(*mapObject)
+		memo[c] = o
+		for i := 0; i < len(c.entries); i += 2 {
+			m.m.set(r.deserializeCloned(c.entries[i], memo), r.deserializeCloned(c.entries[i+1], memo))
+		}
+	case clonedSet:
+		o = r.builtin_newSet(nil, r.global.Set)
+		s := o.self.(*setObject)
+		memo[c] = o
+		for _, e := range c.entries {
+			s.m.set(r.deserializeCloned(e, memo), nil)
+		}
+	case clonedError:
+		var args []Value
+		if c.message != nil {
+			args = []Value{c.message}
+		}
+		o = r.builtin_new(r.getErrorCtorByName(c.name.String()), args)
+	case clonedArray:
+		o = r.newArrayLength(int64(c.length))
+	default:
+		o = r.NewObject()
+	}
+	memo[c] = o
+	for i, key := range c.keys {
+		createDataProperty(o, key, r.deserializeCloned(c.values[i], memo))
+	}
+	return o
+}
+
+func (r *Runtime) getErrorCtorByName(name string) *Object {
+	switch name {
+	case "EvalError":
+		return r.global.EvalError
+	case "RangeError":
+		return r.global.RangeError
+	case "ReferenceError":
+		return r.global.ReferenceError
+	case "SyntaxError":
+		return r.global.SyntaxError
+	case "TypeError":
+		return r.global.TypeError
+	case "URIError":
+		return r.global.URIError
+	}
+	return r.global.Error
+}
@@ -0,0 +1,1125 @@
+package goja
+
+import (
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// This file contains the calendar and time zone arithmetic used by the Temporal builtins. Only the ISO 8601
+// calendar is supported.
+
+type temporalUnit int
+
+const (
+	temporalUnitAuto temporalUnit = iota
+	temporalUnitYear
+	temporalUnitMonth
+	temporalUnitWeek
+	temporalUnitDay
+	temporalUnitHour
+	temporalUnitMinute
+	temporalUnitSecond
+	temporalUnitMillisecond
+	temporalUnitMicrosecond
+	temporalUnitNanosecond
+)
+
+const (
+	nsPerDay = 86400 * 1e9
+
+	temporalPrecisionMinute = -2
+	temporalPrecisionAuto   = -1
+
+	// the limits of the representable dates, in days since the epoch
+	temporalMinEpochDays = -100000001
+	temporalMaxEpochDays = 100000000
+
+	// the year of the dates that represent a Temporal.PlainMonthDay
+	temporalReferenceYear = 1972
+)
+
+var temporalUnitNames = [...]string{"auto", "year", "month", "week", "day", "hour", "minute", "second", "millisecond", "microsecond", "nanosecond"}
+
+var temporalUnitNs = [...]int64{0, 0, 0, 0, nsPerDay, 3600 * 1e9, 60 * 1e9, 1e9, 1e6, 1e3, 1}
+
+var (
+	bigNsPerDay        = big.NewInt(nsPerDay)
+	bigNsPerSecond     = big.NewInt(1e9)
+	bigNsPerMs         = big.NewInt(1e6)
+	temporalMaxEpochNs = new(big.Int).Mul(big.NewInt(1e8), bigNsPerDay)
+)
+
+func (u temporalUnit) String() string {
+	return temporalUnitNames[u]
+}
+
+func (u temporalUnit) isCalendarUnit() bool {
+	return u >= temporalUnitYear && u <= temporalUnitWeek
+}
+
+func (u temporalUnit) isTimeUnit() bool {
+	return u >= temporalUnitHour
+}
+
+func largerTemporalUnit(u1, u2 temporalUnit) temporalUnit {
+	if u1 < u2 {
+		return u1
+	}
+	return u2
+}
+
+func floorDivInt64(a, b int64) int64 {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+func floorModInt64(a, b int64) int64 {
+	return a - floorDivInt64(a, b)*b
+}
+
+type isoDate struct {
+	year, month, day int
+}
+
+type isoTime struct {
+	hour, minute, second, millisecond, microsecond, nanosecond int
+}
+
+func isoIsLeapYear(y int) bool {
+	return y%4 == 0 && (y%100 != 0 || y%400 == 0)
+}
+
+func isoDaysInMonth(y, m int) int {
+	switch m {
+	case 2:
+		if isoIsLeapYear(y) {
+			return 29
+		}
+		return 28
+	case 4, 6, 9, 11:
+		return 30
+	}
+	return 31
+}
+
+func isoDaysInYear(y int) int {
+	if isoIsLeapYear(y) {
+		return 366
+	}
+	return 365
+}
+
+func isValidISODate(y, m, d float64) bool {
+	if m < 1 || m > 12 || d < 1 || math.Abs(y) > 1e6 {
+		return false
+	}
+	return d <= float64(isoDaysInMonth(int(y), int(m)))
+}
+
+// epochDays returns the number of days since 1970-01-01.
+func (d isoDate) epochDays() int64 {
+	y := int64(d.year)
+	m := int64(d.month)
+	if m <= 2 {
+		y--
+	}
+	era := floorDivInt64(y, 400)
+	yoe := y - era*400
+	mp := (m + 9) % 12
+	doy := (153*mp+2)/5 + int64(d.day) - 1
+	doe := yoe*365 + yoe/4 - yoe/100 + doy
+	return era*146097 + doe - 719468
+}
+
+func isoDateFromEpochDays(z int64) isoDate {
+	z += 719468
+	era := floorDivInt64(z, 146097)
+	doe := z - era*146097
+	yoe := (doe - doe/1460 + doe/36524 - doe/146096) / 365
+	y := yoe + era*400
+	doy := doe - (365*yoe + yoe/4 - yoe/100)
+	mp := (5*doy + 2) / 153
+	d := doy - (153*mp+2)/5 + 1
+	m := mp + 3
+	if m > 12 {
+		m -= 12
+	}
+	if m <= 2 {
+		y++
+	}
+	return isoDate{year: int(y), month: int(m), day: int(d)}
+}
+
+func balanceISOYearMonth(y, m int64) (int, int) {
+	m--
+	return int(y + floorDivInt64(m, 12)), int(floorModInt64(m, 12)) + 1
+}
+
+func (d isoDate) addDays(days int64) isoDate {
+	return isoDateFromEpochDays(d.epochDays() + days)
+}
+
+func (d isoDate) compare(other isoDate) int {
+	switch {
+	case d.year != other.year:
+		return compareInt(d.year, other.year)
+	case d.month != other.month:
+		return compareInt(d.month, other.month)
+	}
+	return compareInt(d.day, other.day)
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// dayOfWeek returns the ISO day of week (1 is Monday, 7 is Sunday).
+func (d isoDate) dayOfWeek() int {
+	return int(floorModInt64(d.epochDays()+3, 7)) + 1
+}
+
+func (d isoDate) dayOfYear() int {
+	return int(d.epochDays()-isoDate{year: d.year, month: 1, day: 1}.epochDays()) + 1
+}
+
+func isoWeeksInYear(y int) int {
+	dec28 := isoDate{year: y, month: 12, day: 28}
+	return (dec28.dayOfYear() - dec28.dayOfWeek() + 10) / 7
+}
+
+// weekOfYear returns the ISO week number and the week-numbering year.
+func (d isoDate) weekOfYear() (week, year int) {
+	week = (d.dayOfYear() - d.dayOfWeek() + 10) / 7
+	year = d.year
+	if week < 1 {
+		year--
+		week = isoWeeksInYear(year)
+	} else if week > isoWeeksInYear(year) {
+		year++
+		week = 1
+	}
+	return
+}
+
+func (d isoDate) withinLimits() bool {
+	return isoDateTimeWithinLimits(d, isoTime{hour: 12})
+}
+
+// addDate adds the date portion of a duration. If reject is true and the resulting day of month is out of range,
+// ok is false, otherwise the day is constrained.
+func (d isoDate) addDate(years, months, weeks, days float64, reject bool) (res isoDate, ok bool) {
+	if math.Abs(years) > 1e6 || math.Abs(months) > 12e6 || math.Abs(weeks) > 1e9 || math.Abs(days) > 1e10 {
+		return
+	}
+	y, m := balanceISOYearMonth(int64(d.year)+int64(years), int64(d.month)+int64(months))
+	day := d.day
+	if dim := isoDaysInMonth(y, m); day > dim {
+		if reject {
+			return
+		}
+		day = dim
+	}
+	res = isoDate{year: y, month: m, day: day}.addDays(int64(weeks)*7 + int64(days))
+	return res, true
+}
+
+func isoDateSurpasses(sign int, y, m, d int, other isoDate) bool {
+	switch {
+	case y != other.year:
+		return sign*(y-other.year) > 0
+	case m != other.month:
+		return sign*(m-other.month) > 0
+	case d != other.day:
+		return sign*(d-other.day) > 0
+	}
+	return false
+}
+
+// differenceISODate implements https://tc39.es/proposal-temporal/#sec-temporal-calendardateuntil for the ISO
+// calendar.
+func differenceISODate(one, two isoDate, largestUnit temporalUnit) (res temporalDuration) {
+	sign := -one.compare(two)
+	if sign == 0 {
+		return
+	}
+	if largestUnit == temporalUnitYear || largestUnit == temporalUnitMonth {
+		years := 0
+		if largestUnit == temporalUnitYear {
+			years = two.year - one.year
+			for years != 0 && isoDateSurpasses(sign, one.year+years, one.month, one.day, two) {
+				years -= sign
+			}
+		}
+		months := (two.year-one.year-years)*12 + two.month - one.month
+		for {
+			y, m := balanceISOYearMonth(int64(one.year+years), int64(one.month+months))
+			if months == 0 || !isoDateSurpasses(sign, y, m, one.day, two) {
+				break
+			}
+			months -= sign
+		}
+		y, m := balanceISOYearMonth(int64(one.year+years), int64(one.month+months))
+		day := one.day
+		if dim := isoDaysInMonth(y, m); day > dim {
+			day = dim
+		}
+		res[0] = float64(years)
+		res[1] = float64(months)
+		res[3] = float64(two.epochDays() - isoDate{year: y, month: m, day: day}.epochDays())
+		return
+	}
+	days := two.epochDays() - one.epochDays()
+	if largestUnit == temporalUnitWeek {
+		res[2] = float64(days / 7)
+		days %= 7
+	}
+	res[3] = float64(days)
+	return
+}
+
+// differenceISODateTime returns the difference between two wall-clock times as a date duration and a time duration
+// in nanoseconds (see https://tc39.es/proposal-temporal/#sec-temporal-differenceisodatetime).
+func differenceISODateTime(d1 isoDate, t1 isoTime, d2 isoDate, t2 isoTime, largestUnit temporalUnit) (temporalDuration, *big.Int) {
+	timeNs := t2.ns() - t1.ns()
+	timeSign := 0
+	if timeNs > 0 {
+		timeSign = 1
+	} else if timeNs < 0 {
+		timeSign = -1
+	}
+	adjusted := d2
+	if timeSign != 0 && timeSign == d1.compare(d2) {
+		adjusted = d2.addDays(int64(timeSign))
+		timeNs -= int64(timeSign) * nsPerDay
+	}
+	res := differenceISODate(d1, adjusted, largerTemporalUnit(temporalUnitDay, largestUnit))
+	t := big.NewInt(timeNs)
+	if largestUnit.isTimeUnit() {
+		days := big.NewInt(int64(res[3]))
+		t.Add(t, days.Mul(days, bigNsPerDay))
+		res[3] = 0
+	}
+	return res, t
+}
+
+// roundCalendarDuration rounds the date portion of a duration d (which is the difference between origin and
+// target) to the calendar unit smallestUnit (see https://tc39.es/proposal-temporal/#sec-temporal-nudgetocalendarunit).
+// toNs converts a date into an exact time comparable with target. The time portion of the result is zero.
+func roundCalendarDuration(d temporalDuration, sign int, origin isoDate, toNs func(isoDate) *big.Int, target *big.Int,
+	largestUnit, smallestUnit temporalUnit, increment int64, mode string) temporalDuration {
+	inc := float64(increment)
+	var start, end temporalDuration
+	var r1 float64
+	switch smallestUnit {
+	case temporalUnitYear:
+		r1 = math.Trunc(d[0]/inc) * inc
+		start[0], end[0] = r1, r1+inc*float64(sign)
+	case temporalUnitMonth:
+		r1 = math.Trunc(d[1]/inc) * inc
+		start[0], end[0] = d[0], d[0]
+		start[1], end[1] = r1, r1+inc*float64(sign)
+	case temporalUnitWeek:
+		weeksStart, _ := origin.addDate(d[0], d[1], 0, 0, false)
+		weeks := differenceISODate(weeksStart, weeksStart.addDays(int64(d[3])), temporalUnitWeek)[2]
+		r1 = math.Trunc((d[2]+weeks)/inc) * inc
+		start[0], end[0] = d[0], d[0]
+		start[1], end[1] = d[1], d[1]
+		start[2], end[2] = r1, r1+inc*float64(sign)
+	default:
+		r1 = math.Trunc(d[3]/inc) * inc
+		copy(start[:3], d[:3])
+		copy(end[:3], d[:3])
+		start[3], end[3] = r1, r1+inc*float64(sign)
+	}
+	startDate, _ := origin.addDate(start[0], start[1], start[2], start[3], false)
+	endDate, _ := origin.addDate(end[0], end[1], end[2], end[3], false)
+	startNs := toNs(startDate)
+	endNs := toNs(endDate)
+	num := new(big.Int).Sub(target, startNs)
+	if num.Sign() == 0 {
+		return start
+	}
+	den := new(big.Int).Sub(endNs, startNs)
+	cmpHalf := new(big.Int).Mul(num.Abs(num), big.NewInt(2)).CmpAbs(den)
+	if !temporalRoundExpand(mode, sign, cmpHalf, int64(r1/inc)%2 != 0) {
+		return start
+	}
+	res := end
+	// bubble up to the larger units if the rounded value reaches their boundary
+	for u := smallestUnit - 1; u >= largestUnit && u >= temporalUnitYear; u-- {
+		if u == temporalUnitWeek && largestUnit != temporalUnitWeek {
+			continue
+		}
+		var cand temporalDuration
+		copy(cand[:u-temporalUnitYear+1], res[:u-temporalUnitYear+1])
+		cand[u-temporalUnitYear] += float64(sign)
+		candDate, _ := origin.addDate(cand[0], cand[1], cand[2], cand[3], false)
+		if new(big.Int).Sub(endNs, toNs(candDate)).Sign()*sign < 0 {
+			break
+		}
+		res = cand
+	}
+	return res
+}
+
+func (d isoDate) String() string {
+	var b strings.Builder
+	writeISODate(&b, d)
+	return b.String()
+}
+
+func writeISOYear(b *strings.Builder, y int) {
+	if y >= 0 && y <= 9999 {
+		writePadded(b, y, 4)
+		return
+	}
+	if y < 0 {
+		b.WriteByte('-')
+		y = -y
+	} else {
+		b.WriteByte('+')
+	}
+	writePadded(b, y, 6)
+}
+
+func writePadded(b *strings.Builder, n, width int) {
+	s := strconv.Itoa(n)
+	for i := len(s); i < width; i++ {
+		b.WriteByte('0')
+	}
+	b.WriteString(s)
+}
+
+func writeISODate(b *strings.Builder, d isoDate) {
+	writeISOYear(b, d.year)
+	b.WriteByte('-')
+	writePadded(b, d.month, 2)
+	b.WriteByte('-')
+	writePadded(b, d.day, 2)
+}
+
+func (t isoTime) ns() int64 {
+	return ((int64(t.hour)*60+int64(t.minute))*60+int64(t.second))*1e9 + int64(t.millisecond)*1e6 + int64(t.microsecond)*1e3 + int64(t.nanosecond)
+}
+
+// isoTimeFromNs converts the number of nanoseconds since midnight into a time, returning the number of whole days
+// that did not fit.
+func isoTimeFromNs(ns int64) (isoTime, int64) {
+	days := floorDivInt64(ns, nsPerDay)
+	ns -= days * nsPerDay
+	return isoTime{
+		hour:        int(ns / 3600e9),
+		minute:      int(ns / 60e9 % 60),
+		second:      int(ns / 1e9 % 60),
+		millisecond: int(ns / 1e6 % 1e3),
+		microsecond: int(ns / 1e3 % 1e3),
+		nanosecond:  int(ns % 1e3),
+	}, days
+}
+
+func isValidISOTime(h, m, s, ms, us, ns float64) bool {
+	return h >= 0 && h <= 23 && m >= 0 && m <= 59 && s >= 0 && s <= 59 &&
+		ms >= 0 && ms <= 999 && us >= 0 && us <= 999 && ns >= 0 && ns <= 999
+}
+
+func (t isoTime) compare(other isoTime) int {
+	a, b := t.ns(), other.ns()
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func writeISOTime(b *strings.Builder, t isoTime, precision int) {
+	writePadded(b, t.hour, 2)
+	b.WriteByte(':')
+	writePadded(b, t.minute, 2)
+	if precision == temporalPrecisionMinute {
+		return
+	}
+	b.WriteByte(':')
+	writePadded(b, t.second, 2)
+	writeFraction(b, int64(t.millisecond)*1e6+int64(t.microsecond)*1e3+int64(t.nanosecond), precision)
+}
+
+// writeFraction writes the fractional part of a second (given in nanoseconds), including the decimal point if
+// anything is written.
+func writeFraction(b *strings.Builder, ns int64, precision int) {
+	if precision == 0 || precision == temporalPrecisionAuto && ns == 0 {
+		return
+	}
+	s := strconv.FormatInt(ns, 10)
+	s = strings.Repeat("0", 9-len(s)) + s
+	if precision == temporalPrecisionAuto {
+		s = strings.TrimRight(s, "0")
+	} else {
+		s = s[:precision]
+	}
+	b.WriteByte('.')
+	b.WriteString(s)
+}
+
+func isoDateTimeWithinLimits(d isoDate, t isoTime) bool {
+	if math.Abs(float64(d.year)) > 300000 {
+		return false
+	}
+	days := d.epochDays()
+	if days < temporalMinEpochDays || days > temporalMaxEpochDays {
+		return false
+	}
+	return days != temporalMinEpochDays || t.ns() != 0
+}
+
+// isoYearMonthWithinLimits reports whether the month contains a date that is within the limits (see
+// https://tc39.es/proposal-temporal/#sec-temporal-isoyearmonthwithinlimits).
+func isoYearMonthWithinLimits(y, m int) bool {
+	if y < -271821 || y > 275760 {
+		return false
+	}
+	return (y != -271821 || m >= 4) && (y != 275760 || m <= 9)
+}
+
+func isoDateTimeToEpochNs(d isoDate, t isoTime) *big.Int {
+	res := new(big.Int).Mul(big.NewInt(d.epochDays()), bigNsPerDay)
+	return res.Add(res, big.NewInt(t.ns()))
+}
+
+func isValidEpochNs(ns *big.Int) bool {
+	return new(big.Int).Abs(ns).Cmp(temporalMaxEpochNs) <= 0
+}
+
+func epochNsToTime(ns *big.Int, loc *time.Location) time.Time {
+	sec, nsec := new(big.Int).DivMod(ns, bigNsPerSecond, new(big.Int))
+	return time.Unix(sec.Int64(), nsec.Int64()).In(loc)
+}
+
+func timeToEpochNs(t time.Time) *big.Int {
+	res := new(big.Int).Mul(big.NewInt(t.Unix()), bigNsPerSecond)
+	return res.Add(res, big.NewInt(int64(t.Nanosecond())))
+}
+
+// epochMsFromNs returns floor(ns / 1e6).
+func epochMsFromNs(ns *big.Int) int64 {
+	q, _ := new(big.Int).DivMod(ns, bigNsPerMs, new(big.Int))
+	return q.Int64()
+}
+
+func isoDateTimeFromTime(t time.Time) (isoDate, isoTime) {
+	nsec := t.Nanosecond()
+	return isoDate{year: t.Year(), month: int(t.Month()), day: t.Day()},
+		isoTime{
+			hour:        t.Hour(),
+			minute:      t.Minute(),
+			second:      t.Second(),
+			millisecond: nsec / 1e6,
+			microsecond: nsec / 1e3 % 1e3,
+			nanosecond:  nsec % 1e3,
+		}
+}
+
+// temporalRoundExpand decides whether a value with the given sign that lies between two multiples of a rounding
+// increment should be rounded away from zero. cmpHalf is the result of comparing the remainder with a half of the
+// increment and odd reports whether the truncated quotient is odd.
+func temporalRoundExpand(mode string, sign, cmpHalf int, odd bool) bool {
+	switch mode {
+	case "ceil":
+		return sign > 0
+	case "floor":
+		return sign < 0
+	case "expand":
+		return true
+	case "trunc":
+		return false
+	}
+	if cmpHalf != 0 {
+		return cmpHalf > 0
+	}
+	switch mode {
+	case "halfCeil":
+		return sign > 0
+	case "halfFloor":
+		return sign < 0
+	case "halfExpand":
+		return true
+	case "halfEven":
+		return odd
+	}
+	return false
+}
+
+// temporalNegateRoundingMode returns the rounding mode to be used when the sign of the value is inverted.
+func temporalNegateRoundingMode(mode string) string {
+	switch mode {
+	case "ceil":
+		return "floor"
+	case "floor":
+		return "ceil"
+	case "halfCeil":
+		return "halfFloor"
+	case "halfFloor":
+		return "halfCeil"
+	}
+	return mode
+}
+
+// temporalRound rounds x to a multiple of increment using the specified rounding mode
+// (see https://tc39.es/proposal-temporal/#sec-temporal-roundnumbertoincrement).
+func temporalRound(x, increment *big.Int, mode string) *big.Int {
+	q, rem := new(big.Int).QuoRem(x, increment, new(big.Int))
+	if rem.Sign() == 0 {
+		return x
+	}
+	sign := x.Sign()
+	cmpHalf := new(big.Int).Mul(rem.Abs(rem), big.NewInt(2)).Cmp(increment)
+	if temporalRoundExpand(mode, sign, cmpHalf, q.Bit(0) == 1) {
+		q.Add(q, big.NewInt(int64(sign)))
+	}
+	return q.Mul(q, increment)
+}
+
+// temporalDuration holds the fields of a Temporal.Duration in the order from years to nanoseconds (i.e. the field
+// for a unit u is at the index u-temporalUnitYear).
+type temporalDuration [10]float64
+
+var temporalDurationFields = [...]string{"years", "months", "weeks", "days", "hours", "minutes", "seconds", "milliseconds", "microseconds", "nanoseconds"}
+
+// temporalDurationFieldsSorted lists the indexes of the fields in the alphabetical order in which property bags
+// are read.
+var temporalDurationFieldsSorted = [...]int{3, 4, 8, 7, 5, 1, 9, 6, 2, 0}
+
+func (d *temporalDuration) sign() int {
+	for _, f := range d {
+		if f < 0 {
+			return -1
+		}
+		if f > 0 {
+			return 1
+		}
+	}
+	return 0
+}
+
+func (d *temporalDuration) isValid() bool {
+	sign := d.sign()
+	for _, f := range d {
+		if math.IsNaN(f) || math.IsInf(f, 0) || f != 0 && (f < 0) != (sign < 0) {
+			return false
+		}
+	}
+	if math.Abs(d[0]) >= 1<<32 || math.Abs(d[1]) >= 1<<32 || math.Abs(d[2]) >= 1<<32 {
+		return false
+	}
+	secs := new(big.Int).Quo(d.timeNs(true), bigNsPerSecond)
+	return secs.CmpAbs(big.NewInt(1<<53)) < 0
+}
+
+func (d *temporalDuration) hasCalendarUnits() bool {
+	return d[0] != 0 || d[1] != 0 || d[2] != 0
+}
+
+// largestUnit returns the largest unit with a non-zero value, or nanosecond if the duration is blank.
+func (d *temporalDuration) largestUnit() temporalUnit {
+	for i, f := range d {
+		if f != 0 {
+			return temporalUnit(i) + temporalUnitYear
+		}
+	}
+	return temporalUnitNanosecond
+}
+
+func bigIntFromFloat(f float64) *big.Int {
+	res, _ := big.NewFloat(f).Int(nil)
+	return res
+}
+
+// timeNs returns the total length of the time portion of the duration in nanoseconds, optionally counting days as
+// 24 hours.
+func (d *temporalDuration) timeNs(withDays bool) *big.Int {
+	res := new(big.Int)
+	for i := 3; i < len(d); i++ {
+		if i == 3 && !withDays || d[i] == 0 {
+			continue
+		}
+		f := bigIntFromFloat(d[i])
+		res.Add(res, f.Mul(f, big.NewInt(temporalUnitNs[i+int(temporalUnitYear)])))
+	}
+	return res
+}
+
+// balanceTimeDuration distributes the given number of nanoseconds between the units starting from largestUnit
+// (which must be day or smaller).
+func balanceTimeDuration(ns *big.Int, largestUnit temporalUnit) (res temporalDuration) {
+	rem := new(big.Int).Set(ns)
+	for u := largestUnit; u <= temporalUnitNanosecond; u++ {
+		q := new(big.Int)
+		q.QuoRem(rem, big.NewInt(temporalUnitNs[u]), rem)
+		f, _ := new(big.Float).SetInt(q).Float64()
+		res[u-temporalUnitYear] = f + 0 // avoid -0
+	}
+	return
+}
+
+func (d *temporalDuration) negated() (res temporalDuration) {
+	for i, f := range d {
+		if f != 0 {
+			res[i] = -f
+		}
+	}
+	return
+}
+
+func writeDurationNumber(b *strings.Builder, f float64, designator byte) {
+	if f != 0 {
+		b.WriteString(strconv.FormatFloat(math.Abs(f), 'f', -1, 64))
+		b.WriteByte(designator)
+	}
+}
+
+func (d *temporalDuration) format(precision int) string {
+	var b strings.Builder
+	sign := d.sign()
+	if sign < 0 {
+		b.WriteByte('-')
+	}
+	b.WriteByte('P')
+	writeDurationNumber(&b, d[0], 'Y')
+	writeDurationNumber(&b, d[1], 'M')
+	writeDurationNumber(&b, d[2], 'W')
+	writeDurationNumber(&b, d[3], 'D')
+
+	var secNs big.Int
+	for i := 6; i < len(d); i++ {
+		f := bigIntFromFloat(math.Abs(d[i]))
+		secNs.Add(&secNs, f.Mul(f, big.NewInt(temporalUnitNs[i+int(temporalUnitYear)])))
+	}
+	sec, frac := new(big.Int).QuoRem(&secNs, bigNsPerSecond, new(big.Int))
+
+	var t strings.Builder
+	writeDurationNumber(&t, d[4], 'H')
+	writeDurationNumber(&t, d[5], 'M')
+	if secNs.Sign() != 0 || precision != temporalPrecisionAuto || sign == 0 {
+		t.WriteString(sec.String())
+		writeFraction(&t, frac.Int64(), precision)
+		t.WriteByte('S')
+	}
+	if t.Len() > 0 {
+		b.WriteByte('T')
+		b.WriteString(t.String())
+	}
+	return b.String()
+}
+
+var temporalDurationRe = regexp.MustCompile(`(?i)^([+-])?P(?:(\d+)Y)?(?:(\d+)M)?(?:(\d+)W)?(?:(\d+)D)?(T(?:(\d+)(?:[.,](\d{1,9}))?H)?(?:(\d+)(?:[.,](\d{1,9}))?M)?(?:(\d+)(?:[.,](\d{1,9}))?S)?)?$`)
+
+func parseFraction(s string) int64 {
+	if s == "" {
+		return 0
+	}
+	n, _ := strconv.ParseInt(s+strings.Repeat("0", 9-len(s)), 10, 64)
+	return n
+}
+
+func parseTemporalDuration(s string) (res temporalDuration, ok bool) {
+	m := temporalDurationRe.FindStringSubmatch(s)
+	if m == nil {
+		return
+	}
+	// m[6] is the whole time part, m[7]..m[12] are hours, minutes and seconds with their fractions
+	if m[2] == "" && m[3] == "" && m[4] == "" && m[5] == "" && m[6] == "" {
+		return
+	}
+	if m[6] != "" && m[7] == "" && m[9] == "" && m[11] == "" {
+		return
+	}
+	if m[8] != "" && (m[9] != "" || m[11] != "") || m[10] != "" && m[11] != "" {
+		return
+	}
+	for i, idx := range [...]int{2, 3, 4, 5, 7, 9, 11} {
+		if m[idx] != "" {
+			res[i], _ = strconv.ParseFloat(m[idx], 64)
+		}
+	}
+	var rem int64
+	switch {
+	case m[8] != "":
+		rem = parseFraction(m[8]) * 3600
+	case m[10] != "":
+		rem = parseFraction(m[10]) * 60
+	case m[12] != "":
+		rem = parseFraction(m[12])
+	}
+	if rem != 0 {
+		frac := balanceTimeDuration(big.NewInt(rem), temporalUnitMinute)
+		start := 5
+		if m[8] == "" {
+			start = 6
+			if m[10] == "" {
+				start = 7
+			}
+		}
+		for i := start; i < len(res); i++ {
+			res[i] += frac[i]
+		}
+	}
+	if m[1] == "-" {
+		res = res.negated()
+	}
+	return res, res.isValid()
+}
+
+type temporalParseResult struct {
+	date      isoDate
+	time      isoTime
+	hasDate   bool
+	hasTime   bool
+	z         bool
+	hasOffset bool
+	offsetNs  int64
+	// the offset is specified with a sub-minute precision
+	offsetPrecise bool
+	tz            string
+	calendar      string
+}
+
+const (
+	temporalTimeReStr        = `(\d{2})(?::?(\d{2})(?::?(\d{2})(?:[.,](\d{1,9}))?)?)?`
+	temporalOffsetReStr      = `[+-]\d{2}(?::?\d{2}(?::?\d{2}(?:[.,]\d{1,9})?)?)?`
+	temporalAnnotationsReStr = `((?:\[[^\]]*\])*)`
+)
+
+var (
+	temporalDateTimeRe  = regexp.MustCompile(`(?i)^([+-]\d{6}|\d{4})-?(\d{2})-?(\d{2})(?:[T ]` + temporalTimeReStr + `)?(Z|` + temporalOffsetReStr + `)?` + temporalAnnotationsReStr + `$`)
+	temporalYearMonthRe = regexp.MustCompile(`^([+-]\d{6}|\d{4})-?(\d{2})` + temporalAnnotationsReStr + `$`)
+	temporalMonthDayRe  = regexp.MustCompile(`^(?:--)?(\d{2})-?(\d{2})` + temporalAnnotationsReStr + `$`)
+	temporalTimeRe      = regexp.MustCompile(`(?i)^T?` + temporalTimeReStr + `(Z|` + temporalOffsetReStr + `)?` + temporalAnnotationsReStr + `$`)
+	temporalOffsetRe    = regexp.MustCompile(`^([+-])(\d{2})(?::?(\d{2})(?::?(\d{2})(?:[.,](\d{1,9}))?)?)?$`)
+	temporalAnnotRe     = regexp.MustCompile(`\[(!?)([^\]]*)\]`)
+)
+
+// parseTemporalOffset parses a UTC offset such as "+01:00" and returns it in nanoseconds.
+func parseTemporalOffset(s string) (ns int64, precise bool, ok bool) {
+	m := temporalOffsetRe.FindStringSubmatch(s)
+	if m == nil {
+		return
+	}
+	h, _ := strconv.Atoi(m[2])
+	min, _ := strconv.Atoi(m[3])
+	sec, _ := strconv.Atoi(m[4])
+	if h > 23 || min > 59 || sec > 59 {
+		return
+	}
+	ns = (int64(h)*3600+int64(min)*60+int64(sec))*1e9 + parseFraction(m[5])
+	if m[1] == "-" {
+		ns = -ns
+	}
+	return ns, m[4] != "", true
+}
+
+func (p *temporalParseResult) parseTime(m []string) bool {
+	h, _ := strconv.Atoi(m[0])
+	min, _ := strconv.Atoi(m[1])
+	s, _ := strconv.Atoi(m[2])
+	if s == 60 {
+		s = 59
+	}
+	if h > 23 || min > 59 || s > 59 {
+		return false
+	}
+	f := parseFraction(m[3])
+	p.time = isoTime{hour: h, minute: min, second: s, millisecond: int(f / 1e6), microsecond: int(f / 1e3 % 1e3), nanosecond: int(f % 1e3)}
+	p.hasTime = true
+	return true
+}
+
+func (p *temporalParseResult) parseOffset(s string) bool {
+	if s == "" {
+		return true
+	}
+	if s == "Z" || s == "z" {
+		p.z = true
+		return true
+	}
+	ns, precise, ok := parseTemporalOffset(s)
+	if !ok {
+		return false
+	}
+	p.hasOffset, p.offsetNs, p.offsetPrecise = true, ns, precise
+	return true
+}
+
+func (p *temporalParseResult) parseAnnotations(s string) bool {
+	hasCalendar := false
+	for i, m := range temporalAnnotRe.FindAllStringSubmatch(s, -1) {
+		critical := m[1] == "!"
+		if eq := strings.IndexByte(m[2], '='); eq != -1 {
+			key, value := m[2][:eq], m[2][eq+1:]
+			if key == "u-ca" {
+				if !hasCalendar {
+					p.calendar = value
+					hasCalendar = true
+				} else if critical {
+					return false
+				}
+			} else if critical || key != strings.ToLower(key) {
+				return false
+			}
+			continue
+		}
+		if i != 0 || m[2] == "" {
+			return false
+		}
+		p.tz = m[2]
+	}
+	return true
+}
+
+// parseTemporalDateTimeString parses an ISO 8601 date-time string with optional UTC offset and annotations
+// (see https://tc39.es/proposal-temporal/#sec-temporal-iso8601grammar).
+func parseTemporalDateTimeString(s string) (p temporalParseResult, ok bool) {
+	m := temporalDateTimeRe.FindStringSubmatch(s)
+	if m == nil || m[1] == "-000000" {
+		return
+	}
+	y, _ := strconv.Atoi(m[1])
+	mon, _ := strconv.Atoi(m[2])
+	d, _ := strconv.Atoi(m[3])
+	if !isValidISODate(float64(y), float64(mon), float64(d)) {
+		return
+	}
+	p.date = isoDate{year: y, month: mon, day: d}
+	p.hasDate = true
+	if m[4] != "" && !p.parseTime(m[4:8]) {
+		return
+	}
+	if m[8] != "" && !p.hasTime || !p.parseOffset(m[8]) || !p.parseAnnotations(m[9]) {
+		return
+	}
+	return p, true
+}
+
+// parseTemporalYearMonthString parses either a date-time string or a year and month such as "2024-05". In the latter
+// case the day of the result is 1.
+func parseTemporalYearMonthString(s string) (p temporalParseResult, ok bool) {
+	if p, ok = parseTemporalDateTimeString(s); ok {
+		return
+	}
+	m := temporalYearMonthRe.FindStringSubmatch(s)
+	if m == nil || m[1] == "-000000" {
+		return
+	}
+	y, _ := strconv.Atoi(m[1])
+	mon, _ := strconv.Atoi(m[2])
+	if mon < 1 || mon > 12 || !p.parseAnnotations(m[3]) {
+		return
+	}
+	p.date = isoDate{year: y, month: mon, day: 1}
+	p.hasDate = true
+	return p, true
+}
+
+// parseTemporalMonthDayString parses either a date-time string or a month and day such as "05-20" or "--05-20". In
+// the latter case the year of the result is temporalReferenceYear.
+func parseTemporalMonthDayString(s string) (p temporalParseResult, ok bool) {
+	if p, ok = parseTemporalDateTimeString(s); ok {
+		return
+	}
+	m := temporalMonthDayRe.FindStringSubmatch(s)
+	if m == nil {
+		return
+	}
+	mon, _ := strconv.Atoi(m[1])
+	d, _ := strconv.Atoi(m[2])
+	if !isValidISODate(temporalReferenceYear, float64(mon), float64(d)) || !p.parseAnnotations(m[3]) {
+		return
+	}
+	p.date = isoDate{year: temporalReferenceYear, month: mon, day: d}
+	p.hasDate = true
+	return p, true
+}
+
+func parseTemporalTimeString(s string) (p temporalParseResult, ok bool) {
+	if p, ok = parseTemporalDateTimeString(s); ok {
+		return p, p.hasTime
+	}
+	m := temporalTimeRe.FindStringSubmatch(s)
+	if m == nil || !p.parseTime(m[1:5]) || !p.parseOffset(m[5]) || !p.parseAnnotations(m[6]) {
+		return
+	}
+	return p, true
+}
+
+type temporalTimeZone struct {
+	id  string
+	loc *time.Location
+}
+
+var temporalUTC = &temporalTimeZone{id: "UTC", loc: time.UTC}
+
+func formatTemporalOffset(ns int64, precise bool) string {
+	var b strings.Builder
+	if ns < 0 {
+		b.WriteByte('-')
+		ns = -ns
+	} else {
+		b.WriteByte('+')
+	}
+	if !precise {
+		ns = (ns + 30e9) / 60e9 * 60e9
+	}
+	writePadded(&b, int(ns/3600e9), 2)
+	b.WriteByte(':')
+	writePadded(&b, int(ns/60e9%60), 2)
+	if precise && ns%60e9 != 0 {
+		b.WriteByte(':')
+		writePadded(&b, int(ns/1e9%60), 2)
+		writeFraction(&b, ns%1e9, temporalPrecisionAuto)
+	}
+	return b.String()
+}
+
+func newOffsetTimeZone(ns int64) *temporalTimeZone {
+	id := formatTemporalOffset(ns, false)
+	return &temporalTimeZone{id: id, loc: time.FixedZone(id, int(ns/1e9))}
+}
+
+// parseTemporalTimeZoneId parses a time zone identifier, which is either an IANA time zone name or a UTC offset
+// with minutes precision.
+func parseTemporalTimeZoneId(id string) (*temporalTimeZone, bool) {
+	if strings.EqualFold(id, "UTC") {
+		return temporalUTC, true
+	}
+	if id != "" && (id[0] == '+' || id[0] == '-') {
+		ns, precise, ok := parseTemporalOffset(id)
+		if !ok || precise {
+			return nil, false
+		}
+		return newOffsetTimeZone(ns), true
+	}
+	if id == "" || id == "Local" {
+		return nil, false
+	}
+	loc, err := time.LoadLocation(id)
+	if err != nil {
+		return nil, false
+	}
+	return &temporalTimeZone{id: id, loc: loc}, true
+}
+
+// localTemporalTimeZone returns the time zone corresponding to time.Local. If its IANA name is unknown, a fixed
+// offset zone with the current offset is returned.
+func localTemporalTimeZone(now time.Time) *temporalTimeZone {
+	if name := time.Local.String(); name != "Local" {
+		if tz, ok := parseTemporalTimeZoneId(name); ok {
+			return tz
+		}
+	}
+	_, off := now.In(time.Local).Zone()
+	return newOffsetTimeZone(int64(off) * 1e9)
+}
+
+func (tz *temporalTimeZone) offsetNsAt(ns *big.Int) int64 {
+	_, off := epochNsToTime(ns, tz.loc).Zone()
+	return int64(off) * 1e9
+}
+
+// possibleEpochNs returns the exact times that correspond to the given wall-clock time in this time zone, in
+// ascending order. The result is empty if the wall-clock time falls into a gap (e.g. when DST starts) and contains
+// two values if it is ambiguous (e.g. when DST ends).
+func (tz *temporalTimeZone) possibleEpochNs(d isoDate, t isoTime) []*big.Int {
+	e := isoDateTimeToEpochNs(d, t)
+	before := tz.offsetNsAt(new(big.Int).Sub(e, bigNsPerDay))
+	after := tz.offsetNsAt(new(big.Int).Add(e, bigNsPerDay))
+	var res []*big.Int
+	for i, off := range [...]int64{before, after} {
+		if i == 1 && off == before {
+			break
+		}
+		c := new(big.Int).Sub(e, big.NewInt(off))
+		if tz.offsetNsAt(c) == off {
+			res = append(res, c)
+		}
+	}
+	if len(res) == 2 && res[0].Cmp(res[1]) > 0 {
+		res[0], res[1] = res[1], res[0]
+	}
+	return res
+}
+
+// epochNsFor converts a wall-clock time into an exact time using the specified disambiguation
+// (see https://tc39.es/proposal-temporal/#sec-temporal-getepochnanosecondsfor). Returns nil if the disambiguation
+// is "reject" and the wall-clock time is ambiguous or does not exist.
+func (tz *temporalTimeZone) epochNsFor(d isoDate, t isoTime, disambiguation string) *big.Int {
+	possible := tz.possibleEpochNs(d, t)
+	switch len(possible) {
+	case 1:
+		return possible[0]
+	case 2:
+		switch disambiguation {
+		case "earlier", "compatible":
+			return possible[0]
+		case "later":
+			return possible[1]
+		}
+		return nil
+	}
+	e := isoDateTimeToEpochNs(d, t)
+	before := tz.offsetNsAt(new(big.Int).Sub(e, bigNsPerDay))
+	after := tz.offsetNsAt(new(big.Int).Add(e, bigNsPerDay))
+	switch disambiguation {
+	case "earlier":
+		return e.Sub(e, big.NewInt(after))
+	case "later", "compatible":
+		return e.Sub(e, big.NewInt(before))
+	}
+	return nil
+}
+
+// differenceZoned returns the difference between two exact times as a date duration (in the wall-clock time of this
+// time zone) and a time duration in nanoseconds
+// (see https://tc39.es/proposal-temporal/#sec-temporal-differencezoneddatetime).
+func (tz *temporalTimeZone) differenceZoned(ns1, ns2 *big.Int, largestUnit temporalUnit) (temporalDuration, *big.Int) {
+	sign := ns2.Cmp(ns1)
+	if sign == 0 {
+		return temporalDuration{}, new(big.Int)
+	}
+	d1, t1 := tz.isoDateTimeAt(ns1)
+	d2, t2 := tz.isoDateTimeAt(ns2)
+	maxCorrection := 1
+	if sign == 1 {
+		maxCorrection = 2
+	}
+	correction := 0
+	if timeNs := t2.ns() - t1.ns(); timeNs != 0 && (timeNs < 0) == (sign > 0) {
+		correction = 1
+	}
+	var intermediate isoDate
+	var rem *big.Int
+	for ; correction <= maxCorrection; correction++ {
+		intermediate = d2.addDays(int64(-correction * sign))
+		rem = new(big.Int).Sub(ns2, tz.epochNsFor(intermediate, t1, "compatible"))
+		if rem.Sign() != -sign {
+			break
+		}
+	}
+	return differenceISODate(d1, intermediate, largestUnit), rem
+}
+
+func (tz *temporalTimeZone) isoDateTimeAt(ns *big.Int) (isoDate, isoTime) {
+	return isoDateTimeFromTime(epochNsToTime(ns, tz.loc))
+}
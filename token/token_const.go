@@ -38,6 +38,10 @@ const (
 	SHIFT_RIGHT_ASSIGN          // >>=
 	UNSIGNED_SHIFT_RIGHT_ASSIGN // >>>=
 
+	LOGICAL_AND_ASSIGN // &&=
+	LOGICAL_OR_ASSIGN  // ||=
+	COALESCE_ASSIGN    // ??=
+
 	LOGICAL_AND // &&
 	LOGICAL_OR  // ||
 	COALESCE    // ??
@@ -112,6 +116,8 @@ const (
 	TYPEOF
 	DELETE
 	SWITCH
+	IMPORT
+	EXPORT
 
 	DEFAULT
 	FINALLY
@@ -131,6 +137,7 @@ const (
 	ASYNC
 	AWAIT
 	YIELD
+	USING // never produced by the lexer, used for the 'using' declarations
 )
 
 var token2string = [...]string{
@@ -168,6 +175,9 @@ var token2string = [...]string{
 	SHIFT_LEFT_ASSIGN:           "<<=",
 	SHIFT_RIGHT_ASSIGN:          ">>=",
 	UNSIGNED_SHIFT_RIGHT_ASSIGN: ">>>=",
+	LOGICAL_AND_ASSIGN:          "&&=",
+	LOGICAL_OR_ASSIGN:           "||=",
+	COALESCE_ASSIGN:             "??=",
 	LOGICAL_AND:                 "&&",
 	LOGICAL_OR:                  "||",
 	COALESCE:                    "??",
@@ -216,6 +226,7 @@ var token2string = [...]string{
 	ASYNC:                       "async",
 	AWAIT:                       "await",
 	YIELD:                       "yield",
+	USING:                       "using",
 	CONST:                       "const",
 	WHILE:                       "while",
 	BREAK:                       "break",
@@ -227,6 +238,8 @@ var token2string = [...]string{
 	TYPEOF:                      "typeof",
 	DELETE:                      "delete",
 	SWITCH:                      "switch",
+	IMPORT:                      "import",
+	EXPORT:                      "export",
 	STATIC:                      "static",
 	DEFAULT:                     "default",
 	FINALLY:                     "finally",
@@ -330,15 +343,13 @@ var keywordTable = map[string]_keyword{
 		futureKeyword: true,
 	},
 	"export": {
-		token:         KEYWORD,
-		futureKeyword: true,
+		token: EXPORT,
 	},
 	"extends": {
 		token: EXTENDS,
 	},
 	"import": {
-		token:         KEYWORD,
-		futureKeyword: true,
+		token: IMPORT,
 	},
 	"super": {
 		token: SUPER,
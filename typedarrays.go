@@ -1,8 +1,11 @@
 package goja
 
 import (
+	"errors"
 	"math"
+	"math/big"
 	"reflect"
+	"sort"
 	"strconv"
 	"unsafe"
 
@@ -27,6 +30,8 @@ type typedArrayObjectCtor func(buf *arrayBufferObject, offset, length int, proto
 type arrayBufferObject struct {
 	baseObject
 	detached bool
+	// set for the buffers created by NewExternalArrayBuffer, which can only be detached by the host
+	external bool
 	data     []byte
 }
 
@@ -49,7 +54,8 @@ type typedArray interface {
 	set(idx int, value Value)
 	getRaw(idx int) uint64
 	setRaw(idx int, raw uint64)
-	less(i, j int) bool
+	// sort sorts the elements in [from, to) in the ascending numeric order
+	sort(from, to int)
 	swap(i, j int)
 	typeMatch(v Value) bool
 }
@@ -63,6 +69,8 @@ type uint32Array []uint32
 type int32Array []int32
 type float32Array []float32
 type float64Array []float64
+type bigInt64Array []int64
+type bigUint64Array []uint64
 
 type typedArrayObject struct {
 	baseObject
@@ -108,6 +116,49 @@ func (a ArrayBuffer) Detached() bool {
 	return a.buf.detached
 }
 
+// uint8ArrayBytes returns the part of the buffer viewed by a Uint8Array or a Uint8ClampedArray. The second result is
+// false if v is not one of them, the error is set if the buffer is detached.
+func (r *Runtime) uint8ArrayBytes(v Value) ([]byte, bool, error) {
+	if o, ok := v.(*Object); ok {
+		if ta, ok := o.self.(*typedArrayObject); ok {
+			switch ta.typedArray.(type) {
+			case *uint8Array, *uint8ClampedArray:
+				if ta.viewedArrayBuf.detached {
+					return nil, true, errors.New("the ArrayBuffer is detached")
+				}
+				end := ta.offset + ta.length
+				return ta.viewedArrayBuf.data[ta.offset:end:end], true, nil
+			}
+		}
+	}
+	return nil, false, nil
+}
+
+// Release detaches the ArrayBuffer and returns its data, so the caller regains exclusive ownership of it. After
+// that any attempt to use the ArrayBuffer or its views results in a TypeError, as with Detach. Returns nil if it
+// was already detached. Like Detach, this method may only be called from the goroutine that 'owns' the Runtime.
+func (a ArrayBuffer) Release() []byte {
+	if a.buf.detached {
+		return nil
+	}
+	data := a.buf.data
+	a.buf.detach()
+	return data
+}
+
+// NewExternalArrayBuffer creates an ArrayBuffer that uses data as its contents without copying it, so any changes
+// made by scripts are visible in data and vice versa. Unlike the ones created by NewArrayBuffer, such buffer can
+// only be detached by the host (see ArrayBuffer.Release and ArrayBuffer.Detach): an attempt to transfer it with
+// postMessage() results in a DataCloneError, so the data is never handed over to another Runtime or goroutine.
+//
+// The caller must not modify or reuse data concurrently with the Runtime, and should call Release before reusing
+// it once the scripts are done with it.
+func (r *Runtime) NewExternalArrayBuffer(data []byte) ArrayBuffer {
+	a := r.NewArrayBuffer(data)
+	a.buf.external = true
+	return a
+}
+
 func (r *Runtime) NewArrayBuffer(data []byte) ArrayBuffer {
 	buf := r._newArrayBuffer(r.global.ArrayBufferPrototype, nil)
 	buf.data = data
@@ -136,8 +187,8 @@ func (a *uint8Array) setRaw(idx int, v uint64) {
 	(*a)[idx] = uint8(v)
 }
 
-func (a *uint8Array) less(i, j int) bool {
-	return (*a)[i] < (*a)[j]
+func (a *uint8Array) sort(from, to int) {
+	countingSortUint8((*a)[from:to])
 }
 
 func (a *uint8Array) swap(i, j int) {
@@ -171,8 +222,8 @@ func (a *uint8ClampedArray) setRaw(idx int, v uint64) {
 	(*a)[idx] = uint8(v)
 }
 
-func (a *uint8ClampedArray) less(i, j int) bool {
-	return (*a)[i] < (*a)[j]
+func (a *uint8ClampedArray) sort(from, to int) {
+	countingSortUint8((*a)[from:to])
 }
 
 func (a *uint8ClampedArray) swap(i, j int) {
@@ -206,8 +257,8 @@ func (a *int8Array) setRaw(idx int, v uint64) {
 	(*a)[idx] = int8(v)
 }
 
-func (a *int8Array) less(i, j int) bool {
-	return (*a)[i] < (*a)[j]
+func (a *int8Array) sort(from, to int) {
+	countingSortInt8((*a)[from:to])
 }
 
 func (a *int8Array) swap(i, j int) {
@@ -241,8 +292,8 @@ func (a *uint16Array) setRaw(idx int, v uint64) {
 	(*a)[idx] = uint16(v)
 }
 
-func (a *uint16Array) less(i, j int) bool {
-	return (*a)[i] < (*a)[j]
+func (a *uint16Array) sort(from, to int) {
+	sort.Sort(uint16Slice((*a)[from:to]))
 }
 
 func (a *uint16Array) swap(i, j int) {
@@ -276,8 +327,8 @@ func (a *int16Array) setRaw(idx int, v uint64) {
 	(*a)[idx] = int16(v)
 }
 
-func (a *int16Array) less(i, j int) bool {
-	return (*a)[i] < (*a)[j]
+func (a *int16Array) sort(from, to int) {
+	sort.Sort(int16Slice((*a)[from:to]))
 }
 
 func (a *int16Array) swap(i, j int) {
@@ -311,8 +362,8 @@ func (a *uint32Array) setRaw(idx int, v uint64) {
 	(*a)[idx] = uint32(v)
 }
 
-func (a *uint32Array) less(i, j int) bool {
-	return (*a)[i] < (*a)[j]
+func (a *uint32Array) sort(from, to int) {
+	sort.Sort(uint32Slice((*a)[from:to]))
 }
 
 func (a *uint32Array) swap(i, j int) {
@@ -346,8 +397,8 @@ func (a *int32Array) setRaw(idx int, v uint64) {
 	(*a)[idx] = int32(v)
 }
 
-func (a *int32Array) less(i, j int) bool {
-	return (*a)[i] < (*a)[j]
+func (a *int32Array) sort(from, to int) {
+	sort.Sort(int32Slice((*a)[from:to]))
 }
 
 func (a *int32Array) swap(i, j int) {
@@ -395,8 +446,8 @@ func typedFloatLess(x, y float64) bool {
 	return x < y
 }
 
-func (a *float32Array) less(i, j int) bool {
-	return typedFloatLess(float64((*a)[i]), float64((*a)[j]))
+func (a *float32Array) sort(from, to int) {
+	sortFloat32s((*a)[from:to])
 }
 
 func (a *float32Array) swap(i, j int) {
@@ -431,8 +482,8 @@ func (a *float64Array) setRaw(idx int, v uint64) {
 	(*a)[idx] = math.Float64frombits(v)
 }
 
-func (a *float64Array) less(i, j int) bool {
-	return typedFloatLess((*a)[i], (*a)[j])
+func (a *float64Array) sort(from, to int) {
+	sortFloat64s((*a)[from:to])
 }
 
 func (a *float64Array) swap(i, j int) {
@@ -447,6 +498,76 @@ func (a *float64Array) typeMatch(v Value) bool {
 	return false
 }
 
+func (a *bigInt64Array) get(idx int) Value {
+	return (*valueBigInt)(big.NewInt((*a)[idx]))
+}
+
+func (a *bigInt64Array) getRaw(idx int) uint64 {
+	return uint64((*a)[idx])
+}
+
+func (a *bigInt64Array) set(idx int, value Value) {
+	(*a)[idx] = toBigInt64(value)
+}
+
+func (a *bigInt64Array) toRaw(v Value) uint64 {
+	return uint64(toBigInt64(v))
+}
+
+func (a *bigInt64Array) setRaw(idx int, v uint64) {
+	(*a)[idx] = int64(v)
+}
+
+func (a *bigInt64Array) sort(from, to int) {
+	sort.Sort(int64Slice((*a)[from:to]))
+}
+
+func (a *bigInt64Array) swap(i, j int) {
+	(*a)[i], (*a)[j] = (*a)[j], (*a)[i]
+}
+
+func (a *bigInt64Array) typeMatch(v Value) bool {
+	if b, ok := v.(*valueBigInt); ok {
+		return (*big.Int)(b).IsInt64()
+	}
+	return false
+}
+
+func (a *bigUint64Array) get(idx int) Value {
+	return (*valueBigInt)(new(big.Int).SetUint64((*a)[idx]))
+}
+
+func (a *bigUint64Array) getRaw(idx int) uint64 {
+	return (*a)[idx]
+}
+
+func (a *bigUint64Array) set(idx int, value Value) {
+	(*a)[idx] = toBigUint64(value)
+}
+
+func (a *bigUint64Array) toRaw(v Value) uint64 {
+	return toBigUint64(v)
+}
+
+func (a *bigUint64Array) setRaw(idx int, v uint64) {
+	(*a)[idx] = v
+}
+
+func (a *bigUint64Array) sort(from, to int) {
+	sort.Sort(uint64Slice((*a)[from:to]))
+}
+
+func (a *bigUint64Array) swap(i, j int) {
+	(*a)[i], (*a)[j] = (*a)[j], (*a)[i]
+}
+
+func (a *bigUint64Array) typeMatch(v Value) bool {
+	if b, ok := v.(*valueBigInt); ok {
+		return (*big.Int)(b).IsUint64()
+	}
+	return false
+}
+
 func (a *typedArrayObject) _getIdx(idx int) Value {
 	if 0 <= idx && idx < a.length {
 		if !a.viewedArrayBuf.ensureNotDetached(false) {
@@ -514,10 +635,19 @@ func (a *typedArrayObject) isValidIntegerIndex(idx int) bool {
 	return false
 }
 
+// isBigInt returns true if the content type of the array is BigInt.
+func (a *typedArrayObject) isBigInt() bool {
+	switch a.typedArray.(type) {
+	case *bigInt64Array, *bigUint64Array:
+		return true
+	}
+	return false
+}
+
 func (a *typedArrayObject) _putIdx(idx int, v Value) {
-	v = v.ToNumber()
+	raw := a.typedArray.toRaw(v)
 	if a.isValidIntegerIndex(idx) {
-		a.typedArray.set(idx+a.offset, v)
+		a.typedArray.setRaw(idx+a.offset, raw)
 	}
 }
 
@@ -532,7 +662,7 @@ func (a *typedArrayObject) setOwnStr(p unistring.String, v Value, throw bool) bo
 		return true
 	}
 	if idx == 0 {
-		v.ToNumber() // make sure it throws
+		a.typedArray.toRaw(v) // make sure it throws
 		return true
 	}
 	return a.baseObject.setOwnStr(p, v, throw)
@@ -729,6 +859,14 @@ func (r *Runtime) newFloat64ArrayObject(buf *arrayBufferObject, offset, length i
 	return r._newTypedArrayObject(buf, offset, length, 8, r.global.Float64Array, (*float64Array)(unsafe.Pointer(&buf.data)), proto)
 }
 
+func (r *Runtime) newBigInt64ArrayObject(buf *arrayBufferObject, offset, length int, proto *Object) *typedArrayObject {
+	return r._newTypedArrayObject(buf, offset, length, 8, r.global.BigInt64Array, (*bigInt64Array)(unsafe.Pointer(&buf.data)), proto)
+}
+
+func (r *Runtime) newBigUint64ArrayObject(buf *arrayBufferObject, offset, length int, proto *Object) *typedArrayObject {
+	return r._newTypedArrayObject(buf, offset, length, 8, r.global.BigUint64Array, (*bigUint64Array)(unsafe.Pointer(&buf.data)), proto)
+}
+
 func (o *dataViewObject) getIdxAndByteOrder(getIdx int, littleEndianVal Value, size int) (int, byteOrder) {
 	o.viewedArrayBuf.ensureNotDetached(true)
 	if getIdx+size > o.byteLen {
@@ -916,3 +1054,114 @@ func init() {
 		panic("Could not determine native endianness.")
 	}
 }
+
+func countingSortUint8(s []uint8) {
+	var counts [256]int
+	for _, v := range s {
+		counts[v]++
+	}
+	i := 0
+	for v, n := range counts {
+		for ; n > 0; n-- {
+			s[i] = uint8(v)
+			i++
+		}
+	}
+}
+
+func countingSortInt8(s []int8) {
+	var counts [256]int
+	for _, v := range s {
+		counts[uint8(v)^0x80]++
+	}
+	i := 0
+	for v, n := range counts {
+		for ; n > 0; n-- {
+			s[i] = int8(uint8(v) ^ 0x80)
+			i++
+		}
+	}
+}
+
+type uint16Slice []uint16
+
+func (s uint16Slice) Len() int           { return len(s) }
+func (s uint16Slice) Less(i, j int) bool { return s[i] < s[j] }
+func (s uint16Slice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+type int16Slice []int16
+
+func (s int16Slice) Len() int           { return len(s) }
+func (s int16Slice) Less(i, j int) bool { return s[i] < s[j] }
+func (s int16Slice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+type uint32Slice []uint32
+
+func (s uint32Slice) Len() int           { return len(s) }
+func (s uint32Slice) Less(i, j int) bool { return s[i] < s[j] }
+func (s uint32Slice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+type int32Slice []int32
+
+func (s int32Slice) Len() int           { return len(s) }
+func (s int32Slice) Less(i, j int) bool { return s[i] < s[j] }
+func (s int32Slice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+type int64Slice []int64
+
+func (s int64Slice) Len() int           { return len(s) }
+func (s int64Slice) Less(i, j int) bool { return s[i] < s[j] }
+func (s int64Slice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+type uint64Slice []uint64
+
+func (s uint64Slice) Len() int           { return len(s) }
+func (s uint64Slice) Less(i, j int) bool { return s[i] < s[j] }
+func (s uint64Slice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// float32Slice and float64Slice must not contain NaNs. -0 is ordered before +0, so the elements that compare
+// equal are indistinguishable and an unstable sort can be used.
+type float32Slice []float32
+
+func (s float32Slice) Len() int           { return len(s) }
+func (s float32Slice) Less(i, j int) bool { return typedFloatLess(float64(s[i]), float64(s[j])) }
+func (s float32Slice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+type float64Slice []float64
+
+func (s float64Slice) Len() int           { return len(s) }
+func (s float64Slice) Less(i, j int) bool { return typedFloatLess(s[i], s[j]) }
+func (s float64Slice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// sortFloat32s moves the NaNs to the end preserving their order (they may have different bit patterns which is
+// observable through another view of the same buffer), then sorts the rest.
+func sortFloat32s(s []float32) {
+	var nans []float32
+	n := 0
+	for _, v := range s {
+		if v != v {
+			nans = append(nans, v)
+		} else {
+			s[n] = v
+			n++
+		}
+	}
+	copy(s[n:], nans)
+	sort.Sort(float32Slice(s[:n]))
+}
+
+// sortFloat64s is the same as sortFloat32s for float64.
+func sortFloat64s(s []float64) {
+	var nans []float64
+	n := 0
+	for _, v := range s {
+		if v != v {
+			nans = append(nans, v)
+		} else {
+			s[n] = v
+			n++
+		}
+	}
+	copy(s[n:], nans)
+	sort.Sort(float64Slice(s[:n]))
+}
@@ -1,6 +1,9 @@
 package goja
 
-import "testing"
+import (
+	"encoding/json"
+	"testing"
+)
 
 func TestUint16ArrayObject(t *testing.T) {
 	vm := New()
@@ -65,6 +68,101 @@ func TestArrayBufferGoWrapper(t *testing.T) {
 	}
 }
 
+func TestExternalArrayBuffer(t *testing.T) {
+	vm := New()
+	l := NewLoop(vm)
+	l.EnableMessageChannels()
+	data := []byte{1, 2, 3}
+	buf := vm.NewExternalArrayBuffer(data)
+	err := l.Run(func(r *Runtime) {
+		r.Set("buf", buf)
+		_, err := r.RunString(`
+		var a = new Uint8Array(buf);
+		a[0] = 42;
+		var ch = new MessageChannel();
+		try {
+			ch.port1.postMessage(buf, [buf]);
+			throw new Error("no exception");
+		} catch (e) {
+			if (e.name !== "DataCloneError") throw e;
+		}
+		ch.port1.close();
+		`)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data[0] != 42 {
+		t.Fatal(data)
+	}
+	if released := buf.Release(); &released[0] != &data[0] {
+		t.Fatal("Release() returned a copy")
+	}
+	if buf.Release() != nil {
+		t.Fatal("second Release() returned data")
+	}
+	res, err := vm.RunString(`a[0] === undefined && a.length === 0`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.ToBoolean() {
+		t.Fatal("the buffer is still accessible")
+	}
+}
+
+func TestUint8ArrayExportToBytes(t *testing.T) {
+	vm := New()
+	v, err := vm.RunString(`var a = new Uint8Array([1, 2, 3, 4]).subarray(1, 3); a`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b []byte
+	if err := vm.ExportTo(v, &b); err != nil {
+		t.Fatal(err)
+	}
+	if len(b) != 2 || b[0] != 2 || b[1] != 3 {
+		t.Fatal(b)
+	}
+	b[0] = 42
+	if res, _ := vm.RunString(`a[0]`); res.ToInteger() != 2 {
+		t.Fatal("the copy shares the buffer")
+	}
+
+	vm.SetUnsafeBytesExport(true)
+	var raw json.RawMessage
+	if err := vm.ExportTo(v, &raw); err != nil {
+		t.Fatal(err)
+	}
+	raw[0] = 42
+	if res, _ := vm.RunString(`a[0]`); res.ToInteger() != 42 {
+		t.Fatal("the buffer is not shared")
+	}
+	if cap(raw) != 2 {
+		t.Fatal(cap(raw))
+	}
+
+	type myByte byte
+	var mb []myByte
+	if err := vm.ExportTo(v, &mb); err != nil {
+		t.Fatal(err)
+	}
+	if len(mb) != 2 || mb[0] != 42 || mb[1] != 3 {
+		t.Fatal(mb)
+	}
+
+	buf, err := vm.RunString(`a.buffer`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Export().(ArrayBuffer).Detach()
+	if err := vm.ExportTo(v, &b); err == nil {
+		t.Fatal("expected an error for a detached buffer")
+	}
+}
+
 func TestTypedArrayIdx(t *testing.T) {
 	const SCRIPT = `
 	var a = new Uint8Array(1);
@@ -340,3 +438,72 @@ func TestTypedArrayGetInvalidIndex(t *testing.T) {
 	`
 	testScriptWithTestLib(SCRIPT, _undefined, t)
 }
+
+func TestTypedArraySort(t *testing.T) {
+	const SCRIPT = `
+	var buf = new ArrayBuffer(16);
+	var view = new Int8Array(buf, 4, 8);
+	new Int8Array(buf).fill(-1);
+	[5, -3, 127, -128, 0, 5, -1, 1].forEach(function(v, i) { view[i] = v });
+	view.sort();
+	assert(compareArray(view, [-128, -3, -1, 0, 1, 5, 5, 127]), "int8: " + view);
+	assert(compareArray(new Int8Array(buf, 0, 4), [-1, -1, -1, -1]), "outside of the view before");
+	assert(compareArray(new Int8Array(buf, 12), [-1, -1, -1, -1]), "outside of the view after");
+
+	assert(compareArray(new Uint8Array([3, 255, 0, 3]).sort(), [0, 3, 3, 255]), "uint8");
+	assert(compareArray(new Uint16Array([300, 2, 65535, 1]).sort(), [1, 2, 300, 65535]), "uint16");
+	assert(compareArray(new Int32Array([3, -2147483648, 2147483647, 0]).sort(), [-2147483648, 0, 3, 2147483647]), "int32");
+	assert(compareArray(new Uint32Array([4294967295, 1, 0]).sort(), [0, 1, 4294967295]), "uint32");
+	assert(compareArray(new BigInt64Array([3n, -5n, 0n]).sort(), [-5n, 0n, 3n]), "bigint64");
+	assert(compareArray(new BigUint64Array([3n, 18446744073709551615n, 0n]).sort(), [0n, 3n, 18446744073709551615n]), "biguint64");
+
+	var f = new Float64Array([NaN, 1, -0, Infinity, 0, -Infinity, NaN, -1]).sort();
+	assert(compareArray(f.subarray(0, 6), [-Infinity, -1, -0, 0, 1, Infinity]) && isNaN(f[6]) && isNaN(f[7]), "float64: " + f);
+	assert.sameValue(1 / f[2], -Infinity, "-0 before +0");
+	f = new Float32Array([NaN, 0.5, -0, 0]).sort();
+	assert(compareArray(f.subarray(0, 3), [-0, 0, 0.5]) && isNaN(f[3]), "float32: " + f);
+	assert.sameValue(1 / f[0], -Infinity, "float32 -0 before +0");
+
+	// NaNs with different bit patterns keep their order
+	var nans = new Float64Array(3);
+	var bytes = new Uint8Array(nans.buffer);
+	nans[0] = NaN;
+	nans[1] = 1;
+	nans[2] = NaN;
+	bytes[0] = 1;
+	nans.sort();
+	assert.sameValue(nans[0], 1);
+	assert.sameValue(bytes[8], 1, "NaN order");
+
+	// stable with a comparator
+	var a = new Int32Array(100);
+	for (var i = 0; i < a.length; i++) {
+		a[i] = (i % 7) * 1000 + i;
+	}
+	a.sort(function(x, y) { return Math.floor(x / 1000) - Math.floor(y / 1000) });
+	for (var i = 1; i < a.length; i++) {
+		var px = Math.floor(a[i-1] / 1000), x = Math.floor(a[i] / 1000);
+		assert(px < x || px === x && a[i-1] % 1000 < a[i] % 1000, "not stable at " + i);
+	}
+	assert(compareArray(new Uint8Array([1, 2, 3]).sort(function(x, y) { return y - x }), [3, 2, 1]), "descending");
+	`
+	testScriptWithTestLib(SCRIPT, _undefined, t)
+}
+
+func BenchmarkTypedArraySort(b *testing.B) {
+	vm := New()
+	prg := MustCompile("test.js", `
+	var a = new Float64Array(10000);
+	for (var i = 0; i < a.length; i++) {
+		a[i] = Math.random();
+	}
+	a.sort();
+	`, false)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := vm.RunProgram(prg)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
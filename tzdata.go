@@ -0,0 +1,8 @@
+//go:build goja_tzdata
+// +build goja_tzdata
+
+package goja
+
+// The goja_tzdata build tag embeds the IANA Time Zone Database (about 450 KB), so that the time zones can be
+// resolved regardless of the host system.
+import _ "time/tzdata"
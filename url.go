@@ -0,0 +1,1273 @@
+package goja
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// This file contains an implementation of the URL parser and serialiser described in https://url.spec.whatwg.org/.
+
+type urlState int
+
+const (
+	urlStateNone urlState = iota
+	urlStateSchemeStart
+	urlStateScheme
+	urlStateNoScheme
+	urlStateSpecialRelativeOrAuthority
+	urlStatePathOrAuthority
+	urlStateRelative
+	urlStateRelativeSlash
+	urlStateSpecialAuthoritySlashes
+	urlStateSpecialAuthorityIgnoreSlashes
+	urlStateAuthority
+	urlStateHost
+	urlStateHostname
+	urlStatePort
+	urlStateFile
+	urlStateFileSlash
+	urlStateFileHost
+	urlStatePathStart
+	urlStatePath
+	urlStateOpaquePath
+	urlStateQuery
+	urlStateFragment
+)
+
+const urlEOF = -1
+
+// urlRecord is a URL record (https://url.spec.whatwg.org/#concept-url).
+type urlRecord struct {
+	scheme, username, password string
+
+	host    string
+	hasHost bool
+
+	port int // -1 if null
+
+	path       []string
+	opaquePath string
+	isOpaque   bool
+
+	query    string
+	hasQuery bool
+
+	fragment    string
+	hasFragment bool
+}
+
+var urlSpecialSchemes = map[string]int{
+	"ftp":   21,
+	"file":  -1,
+	"http":  80,
+	"https": 443,
+	"ws":    80,
+	"wss":   443,
+}
+
+func (u *urlRecord) isSpecial() bool {
+	_, ok := urlSpecialSchemes[u.scheme]
+	return ok
+}
+
+func urlDefaultPort(scheme string) int {
+	if port, ok := urlSpecialSchemes[scheme]; ok {
+		return port
+	}
+	return -1
+}
+
+func (u *urlRecord) includesCredentials() bool {
+	return u.username != "" || u.password != ""
+}
+
+func (u *urlRecord) cannotHaveUsernamePasswordPort() bool {
+	return !u.hasHost || u.host == "" || u.scheme == "file"
+}
+
+func (u *urlRecord) clone() *urlRecord {
+	c := *u
+	c.path = append([]string(nil), u.path...)
+	return &c
+}
+
+func (u *urlRecord) shortenPath() {
+	if u.scheme == "file" && len(u.path) == 1 && isNormalizedWindowsDriveLetter(u.path[0]) {
+		return
+	}
+	if len(u.path) > 0 {
+		u.path = u.path[:len(u.path)-1]
+	}
+}
+
+// percent-encode sets (https://url.spec.whatwg.org/#percent-encoded-bytes)
+type urlEncodeSet func(c byte) bool
+
+func urlC0ControlSet(c byte) bool {
+	return c < 0x20 || c > 0x7E
+}
+
+func urlFragmentSet(c byte) bool {
+	return urlC0ControlSet(c) || c == ' ' || c == '"' || c == '<' || c == '>' || c == '`'
+}
+
+func urlQuerySet(c byte) bool {
+	return urlC0ControlSet(c) || c == ' ' || c == '"' || c == '#' || c == '<' || c == '>'
+}
+
+func urlSpecialQuerySet(c byte) bool {
+	return urlQuerySet(c) || c == '\''
+}
+
+func urlPathSet(c byte) bool {
+	return urlQuerySet(c) || c == '?' || c == '^' || c == '`' || c == '{' || c == '}'
+}
+
+func urlUserinfoSet(c byte) bool {
+	return urlPathSet(c) || c == '/' || c == ':' || c == ';' || c == '=' || c == '@' || c >= '[' && c <= ']' || c == '|'
+}
+
+func urlComponentSet(c byte) bool {
+	return urlUserinfoSet(c) || c >= '$' && c <= '&' || c == '+' || c == ','
+}
+
+func urlFormSet(c byte) bool {
+	return urlComponentSet(c) || c == '!' || c >= '\'' && c <= ')' || c == '~'
+}
+
+func urlPercentEncode(sb *strings.Builder, s string, set urlEncodeSet, spaceAsPlus bool) {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if spaceAsPlus && c == ' ' {
+			sb.WriteByte('+')
+		} else if set(c) {
+			sb.WriteByte('%')
+			sb.WriteByte(upperHex[c>>4])
+			sb.WriteByte(upperHex[c&0xF])
+		} else {
+			sb.WriteByte(c)
+		}
+	}
+}
+
+func urlPercentEncodeString(s string, set urlEncodeSet) string {
+	var sb strings.Builder
+	urlPercentEncode(&sb, s, set, false)
+	return sb.String()
+}
+
+const upperHex = "0123456789ABCDEF"
+
+func urlPercentDecode(s string) []byte {
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '%' && i+2 < len(s) {
+			if hi, ok := hexDigit(s[i+1]); ok {
+				if lo, ok := hexDigit(s[i+2]); ok {
+					b = append(b, hi<<4|lo)
+					i += 2
+					continue
+				}
+			}
+		}
+		b = append(b, c)
+	}
+	return b
+}
+
+// utf8DecodeWithoutBOM converts b to a valid UTF-8 string replacing invalid sequences with U+FFFD.
+func utf8DecodeWithoutBOM(b []byte) string {
+	var sb strings.Builder
+	decodeUTF8(b, true, false, &sb)
+	return sb.String()
+}
+
+func isASCIIAlpha(c rune) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isASCIIDigit(c rune) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isWindowsDriveLetter(s string) bool {
+	return len(s) == 2 && isASCIIAlpha(rune(s[0])) && (s[1] == ':' || s[1] == '|')
+}
+
+func isNormalizedWindowsDriveLetter(s string) bool {
+	return len(s) == 2 && isASCIIAlpha(rune(s[0])) && s[1] == ':'
+}
+
+func startsWithWindowsDriveLetter(input []rune) bool {
+	if len(input) < 2 || !isASCIIAlpha(input[0]) || input[1] != ':' && input[1] != '|' {
+		return false
+	}
+	if len(input) == 2 {
+		return true
+	}
+	switch input[2] {
+	case '/', '\\', '?', '#':
+		return true
+	}
+	return false
+}
+
+func isSingleDotSegment(s string) bool {
+	return s == "." || strings.EqualFold(s, "%2e")
+}
+
+func isDoubleDotSegment(s string) bool {
+	switch strings.ToLower(s) {
+	case "..", ".%2e", "%2e.", "%2e%2e":
+		return true
+	}
+	return false
+}
+
+func urlTrimControlAndSpace(s string) string {
+	return strings.TrimFunc(s, func(r rune) bool {
+		return r <= ' '
+	})
+}
+
+func urlRemoveTabAndNewline(s string) string {
+	if !strings.ContainsAny(s, "\t\n\r") {
+		return s
+	}
+	return strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// parseURL implements the basic URL parser without a state override. base may be nil.
+func parseURL(input string, base *urlRecord) (*urlRecord, bool) {
+	u := &urlRecord{port: -1}
+	if !u.parse(urlTrimControlAndSpace(input), base, urlStateNone) {
+		return nil, false
+	}
+	return u, true
+}
+
+// parse implements https://url.spec.whatwg.org/#concept-basic-url-parser. If override is not urlStateNone the
+// URL is modified in place and the result indicates whether the input was valid.
+func (u *urlRecord) parse(s string, base *urlRecord, override urlState) bool {
+	input := []rune(urlRemoveTabAndNewline(s))
+	state := override
+	if state == urlStateNone {
+		state = urlStateSchemeStart
+	}
+	var buf strings.Builder
+	atSignSeen, insideBrackets, passwordTokenSeen := false, false, false
+
+	remainingStartsWith := func(pointer int, s string) bool {
+		return pointer+1 < len(input) && strings.HasPrefix(string(input[pointer+1:]), s)
+	}
+
+	for pointer := 0; ; pointer++ {
+		c := rune(urlEOF)
+		if pointer < len(input) {
+			c = input[pointer]
+		}
+		switch state {
+		case urlStateSchemeStart:
+			if isASCIIAlpha(c) {
+				buf.WriteRune(c | 0x20)
+				state = urlStateScheme
+			} else if override == urlStateNone {
+				state = urlStateNoScheme
+				pointer--
+			} else {
+				return false
+			}
+		case urlStateScheme:
+			if isASCIIAlpha(c) || isASCIIDigit(c) || c == '+' || c == '-' || c == '.' {
+				if isASCIIAlpha(c) {
+					c |= 0x20
+				}
+				buf.WriteRune(c)
+			} else if c == ':' {
+				scheme := buf.String()
+				if override != urlStateNone {
+					_, special := urlSpecialSchemes[scheme]
+					if u.isSpecial() != special {
+						return false
+					}
+					if (u.includesCredentials() || u.port != -1) && scheme == "file" {
+						return false
+					}
+					if u.scheme == "file" && u.hasHost && u.host == "" {
+						return false
+					}
+				}
+				u.scheme = scheme
+				if override != urlStateNone {
+					if u.port == urlDefaultPort(u.scheme) {
+						u.port = -1
+					}
+					return true
+				}
+				buf.Reset()
+				if u.scheme == "file" {
+					state = urlStateFile
+				} else if u.isSpecial() && base != nil && base.scheme == u.scheme {
+					state = urlStateSpecialRelativeOrAuthority
+				} else if u.isSpecial() {
+					state = urlStateSpecialAuthoritySlashes
+				} else if remainingStartsWith(pointer, "/") {
+					state = urlStatePathOrAuthority
+					pointer++
+				} else {
+					u.isOpaque = true
+					u.opaquePath = ""
+					state = urlStateOpaquePath
+				}
+			} else if override == urlStateNone {
+				buf.Reset()
+				state = urlStateNoScheme
+				pointer = -1
+			} else {
+				return false
+			}
+		case urlStateNoScheme:
+			if base == nil || base.isOpaque && c != '#' {
+				return false
+			} else if base.isOpaque && c == '#' {
+				u.scheme = base.scheme
+				u.isOpaque = true
+				u.opaquePath = base.opaquePath
+				u.query, u.hasQuery = base.query, base.hasQuery
+				u.fragment, u.hasFragment = "", true
+				state = urlStateFragment
+			} else if base.scheme != "file" {
+				state = urlStateRelative
+				pointer--
+			} else {
+				state = urlStateFile
+				pointer--
+			}
+		case urlStateSpecialRelativeOrAuthority:
+			if c == '/' && remainingStartsWith(pointer, "/") {
+				state = urlStateSpecialAuthorityIgnoreSlashes
+				pointer++
+			} else {
+				state = urlStateRelative
+				pointer--
+			}
+		case urlStatePathOrAuthority:
+			if c == '/' {
+				state = urlStateAuthority
+			} else {
+				state = urlStatePath
+				pointer--
+			}
+		case urlStateRelative:
+			u.scheme = base.scheme
+			if c == '/' || u.isSpecial() && c == '\\' {
+				state = urlStateRelativeSlash
+			} else {
+				u.username, u.password = base.username, base.password
+				u.host, u.hasHost, u.port = base.host, base.hasHost, base.port
+				u.path = append([]string(nil), base.path...)
+				u.query, u.hasQuery = base.query, base.hasQuery
+				if c == '?' {
+					u.query, u.hasQuery = "", true
+					state = urlStateQuery
+				} else if c == '#' {
+					u.fragment, u.hasFragment = "", true
+					state = urlStateFragment
+				} else if c != urlEOF {
+					u.query, u.hasQuery = "", false
+					u.shortenPath()
+					state = urlStatePath
+					pointer--
+				}
+			}
+		case urlStateRelativeSlash:
+			if u.isSpecial() && (c == '/' || c == '\\') {
+				state = urlStateSpecialAuthorityIgnoreSlashes
+			} else if c == '/' {
+				state = urlStateAuthority
+			} else {
+				u.username, u.password = base.username, base.password
+				u.host, u.hasHost, u.port = base.host, base.hasHost, base.port
+				state = urlStatePath
+				pointer--
+			}
+		case urlStateSpecialAuthoritySlashes:
+			state = urlStateSpecialAuthorityIgnoreSlashes
+			if c == '/' && remainingStartsWith(pointer, "/") {
+				pointer++
+			} else {
+				pointer--
+			}
+		case urlStateSpecialAuthorityIgnoreSlashes:
+			if c != '/' && c != '\\' {
+				state = urlStateAuthority
+				pointer--
+			}
+		case urlStateAuthority:
+			if c == '@' {
+				b := buf.String()
+				if atSignSeen {
+					b = "%40" + b
+				}
+				atSignSeen = true
+				for _, cp := range b {
+					if cp == ':' && !passwordTokenSeen {
+						passwordTokenSeen = true
+						continue
+					}
+					encoded := urlPercentEncodeString(string(cp), urlUserinfoSet)
+					if passwordTokenSeen {
+						u.password += encoded
+					} else {
+						u.username += encoded
+					}
+				}
+				buf.Reset()
+			} else if c == urlEOF || c == '/' || c == '?' || c == '#' || u.isSpecial() && c == '\\' {
+				if atSignSeen && buf.Len() == 0 {
+					return false
+				}
+				pointer -= utf8.RuneCountInString(buf.String()) + 1
+				buf.Reset()
+				state = urlStateHost
+			} else {
+				buf.WriteRune(c)
+			}
+		case urlStateHost, urlStateHostname:
+			if override != urlStateNone && u.scheme == "file" {
+				pointer--
+				state = urlStateFileHost
+			} else if c == ':' && !insideBrackets {
+				if buf.Len() == 0 || override == urlStateHostname {
+					return false
+				}
+				host, ok := parseURLHost(buf.String(), !u.isSpecial())
+				if !ok {
+					return false
+				}
+				u.host, u.hasHost = host, true
+				buf.Reset()
+				state = urlStatePort
+			} else if c == urlEOF || c == '/' || c == '?' || c == '#' || u.isSpecial() && c == '\\' {
+				pointer--
+				if u.isSpecial() && buf.Len() == 0 {
+					return false
+				} else if override != urlStateNone && buf.Len() == 0 && (u.includesCredentials() || u.port != -1) {
+					return false
+				}
+				host, ok := parseURLHost(buf.String(), !u.isSpecial())
+				if !ok {
+					return false
+				}
+				u.host, u.hasHost = host, true
+				buf.Reset()
+				state = urlStatePathStart
+				if override != urlStateNone {
+					return true
+				}
+			} else {
+				if c == '[' {
+					insideBrackets = true
+				} else if c == ']' {
+					insideBrackets = false
+				}
+				buf.WriteRune(c)
+			}
+		case urlStatePort:
+			if isASCIIDigit(c) {
+				buf.WriteRune(c)
+			} else if c == urlEOF || c == '/' || c == '?' || c == '#' || u.isSpecial() && c == '\\' || override != urlStateNone {
+				if buf.Len() != 0 {
+					digits := strings.TrimLeft(buf.String(), "0")
+					port := 0
+					if digits != "" {
+						n, err := strconv.ParseUint(digits, 10, 16)
+						if err != nil {
+							return false
+						}
+						port = int(n)
+					}
+					if port == urlDefaultPort(u.scheme) {
+						u.port = -1
+					} else {
+						u.port = port
+					}
+					buf.Reset()
+					if override != urlStateNone {
+						return true
+					}
+				}
+				if override != urlStateNone {
+					return false
+				}
+				state = urlStatePathStart
+				pointer--
+			} else {
+				return false
+			}
+		case urlStateFile:
+			u.scheme = "file"
+			u.host, u.hasHost = "", true
+			if c == '/' || c == '\\' {
+				state = urlStateFileSlash
+			} else if base != nil && base.scheme == "file" {
+				u.host, u.hasHost = base.host, base.hasHost
+				u.path = append([]string(nil), base.path...)
+				u.query, u.hasQuery = base.query, base.hasQuery
+				if c == '?' {
+					u.query, u.hasQuery = "", true
+					state = urlStateQuery
+				} else if c == '#' {
+					u.fragment, u.hasFragment = "", true
+					state = urlStateFragment
+				} else if c != urlEOF {
+					u.query, u.hasQuery = "", false
+					if !startsWithWindowsDriveLetter(input[pointer:]) {
+						u.shortenPath()
+					} else {
+						u.path = nil
+					}
+					state = urlStatePath
+					pointer--
+				}
+			} else {
+				state = urlStatePath
+				pointer--
+			}
+		case urlStateFileSlash:
+			if c == '/' || c == '\\' {
+				state = urlStateFileHost
+			} else {
+				if base != nil && base.scheme == "file" {
+					u.host, u.hasHost = base.host, base.hasHost
+					if (pointer >= len(input) || !startsWithWindowsDriveLetter(input[pointer:])) && len(base.path) > 0 && isNormalizedWindowsDriveLetter(base.path[0]) {
+						u.path = append(u.path, base.path[0])
+					}
+				}
+				state = urlStatePath
+				pointer--
+			}
+		case urlStateFileHost:
+			if c == urlEOF || c == '/' || c == '\\' || c == '?' || c == '#' {
+				pointer--
+				if override == urlStateNone && isWindowsDriveLetter(buf.String()) {
+					// the buffer is not reset and is used as the first path segment
+					state = urlStatePath
+				} else if buf.Len() == 0 {
+					u.host, u.hasHost = "", true
+					if override != urlStateNone {
+						return true
+					}
+					state = urlStatePathStart
+				} else {
+					host, ok := parseURLHost(buf.String(), !u.isSpecial())
+					if !ok {
+						return false
+					}
+					if host == "localhost" {
+						host = ""
+					}
+					u.host, u.hasHost = host, true
+					if override != urlStateNone {
+						return true
+					}
+					buf.Reset()
+					state = urlStatePathStart
+				}
+			} else {
+				buf.WriteRune(c)
+			}
+		case urlStatePathStart:
+			if u.isSpecial() {
+				state = urlStatePath
+				if c != '/' && c != '\\' {
+					pointer--
+				}
+			} else if override == urlStateNone && c == '?' {
+				u.query, u.hasQuery = "", true
+				state = urlStateQuery
+			} else if override == urlStateNone && c == '#' {
+				u.fragment, u.hasFragment = "", true
+				state = urlStateFragment
+			} else if c != urlEOF {
+				state = urlStatePath
+				if c != '/' {
+					pointer--
+				}
+			} else if override != urlStateNone && !u.hasHost {
+				u.path = append(u.path, "")
+			}
+		case urlStatePath:
+			slash := c == '/' || u.isSpecial() && c == '\\'
+			if c == urlEOF || slash || override == urlStateNone && (c == '?' || c == '#') {
+				segment := buf.String()
+				if isDoubleDotSegment(segment) {
+					u.shortenPath()
+					if !slash {
+						u.path = append(u.path, "")
+					}
+				} else if isSingleDotSegment(segment) {
+					if !slash {
+						u.path = append(u.path, "")
+					}
+				} else {
+					if u.scheme == "file" && len(u.path) == 0 && isWindowsDriveLetter(segment) {
+						segment = segment[:1] + ":"
+					}
+					u.path = append(u.path, segment)
+				}
+				buf.Reset()
+				if c == '?' {
+					u.query, u.hasQuery = "", true
+					state = urlStateQuery
+				} else if c == '#' {
+					u.fragment, u.hasFragment = "", true
+					state = urlStateFragment
+				}
+			} else {
+				urlPercentEncode(&buf, string(c), urlPathSet, false)
+			}
+		case urlStateOpaquePath:
+			if c == '?' {
+				u.query, u.hasQuery = "", true
+				state = urlStateQuery
+			} else if c == '#' {
+				u.fragment, u.hasFragment = "", true
+				state = urlStateFragment
+			} else if c != urlEOF {
+				u.opaquePath += urlPercentEncodeString(string(c), urlC0ControlSet)
+			}
+		case urlStateQuery:
+			if override == urlStateNone && c == '#' || c == urlEOF {
+				set := urlQuerySet
+				if u.isSpecial() {
+					set = urlSpecialQuerySet
+				}
+				u.query += urlPercentEncodeString(buf.String(), set)
+				buf.Reset()
+				if c == '#' {
+					u.fragment, u.hasFragment = "", true
+					state = urlStateFragment
+				}
+			} else if c != urlEOF {
+				buf.WriteRune(c)
+			}
+		case urlStateFragment:
+			if c != urlEOF {
+				u.fragment += urlPercentEncodeString(string(c), urlFragmentSet)
+			}
+		}
+		if pointer >= len(input) {
+			break
+		}
+	}
+	return true
+}
+
+// serialize implements https://url.spec.whatwg.org/#concept-url-serializer
+func (u *urlRecord) serialize(excludeFragment bool) string {
+	var sb strings.Builder
+	sb.WriteString(u.scheme)
+	sb.WriteByte(':')
+	if u.hasHost {
+		sb.WriteString("//")
+		if u.includesCredentials() {
+			sb.WriteString(u.username)
+			if u.password != "" {
+				sb.WriteByte(':')
+				sb.WriteString(u.password)
+			}
+			sb.WriteByte('@')
+		}
+		sb.WriteString(u.host)
+		if u.port != -1 {
+			sb.WriteByte(':')
+			sb.WriteString(strconv.Itoa(u.port))
+		}
+	} else if !u.isOpaque && len(u.path) > 1 && u.path[0] == "" {
+		sb.WriteString("/.")
+	}
+	sb.WriteString(u.pathname())
+	if u.hasQuery {
+		sb.WriteByte('?')
+		sb.WriteString(u.query)
+	}
+	if !excludeFragment && u.hasFragment {
+		sb.WriteByte('#')
+		sb.WriteString(u.fragment)
+	}
+	return sb.String()
+}
+
+func (u *urlRecord) pathname() string {
+	if u.isOpaque {
+		return u.opaquePath
+	}
+	var sb strings.Builder
+	for _, segment := range u.path {
+		sb.WriteByte('/')
+		sb.WriteString(segment)
+	}
+	return sb.String()
+}
+
+// origin returns the serialisation of the URL's origin (https://url.spec.whatwg.org/#concept-url-origin).
+func (u *urlRecord) origin() string {
+	switch u.scheme {
+	case "blob":
+		if inner, ok := parseURL(u.pathname(), nil); ok && (inner.scheme == "http" || inner.scheme == "https") {
+			return inner.origin()
+		}
+	case "ftp", "http", "https", "ws", "wss":
+		res := u.scheme + "://" + u.host
+		if u.port != -1 {
+			res += ":" + strconv.Itoa(u.port)
+		}
+		return res
+	}
+	return "null"
+}
+
+func isForbiddenHostCodePoint(c rune) bool {
+	switch c {
+	case 0, '\t', '\n', '\r', ' ', '#', '/', ':', '<', '>', '?', '@', '[', '\\', ']', '^', '|':
+		return true
+	}
+	return false
+}
+
+func isForbiddenDomainCodePoint(c rune) bool {
+	return isForbiddenHostCodePoint(c) || c <= 0x1F || c == '%' || c == 0x7F
+}
+
+// parseURLHost implements https://url.spec.whatwg.org/#concept-host-parser and returns the serialised host.
+func parseURLHost(input string, isOpaque bool) (string, bool) {
+	if strings.HasPrefix(input, "[") {
+		if !strings.HasSuffix(input, "]") {
+			return "", false
+		}
+		addr, ok := parseIPv6(input[1 : len(input)-1])
+		if !ok {
+			return "", false
+		}
+		return "[" + serializeIPv6(addr) + "]", true
+	}
+	if isOpaque {
+		for _, c := range input {
+			if isForbiddenHostCodePoint(c) {
+				return "", false
+			}
+		}
+		return urlPercentEncodeString(input, urlC0ControlSet), true
+	}
+	domain := utf8DecodeWithoutBOM(urlPercentDecode(input))
+	asciiDomain, ok := domainToASCII(domain)
+	if !ok {
+		return "", false
+	}
+	for _, c := range asciiDomain {
+		if isForbiddenDomainCodePoint(c) {
+			return "", false
+		}
+	}
+	if endsInANumber(asciiDomain) {
+		addr, ok := parseIPv4(asciiDomain)
+		if !ok {
+			return "", false
+		}
+		return serializeIPv4(addr), true
+	}
+	return asciiDomain, true
+}
+
+// domainToASCII is an approximation of UTS #46 ToASCII as used by https://url.spec.whatwg.org/#concept-domain-to-ascii.
+// The mapping step is performed by lower-casing and NFKC normalisation rather than the full IDNA mapping table
+// and the validity criteria are limited to the Punycode encoding.
+func domainToASCII(domain string) (string, bool) {
+	ascii := true
+	for i := 0; i < len(domain); i++ {
+		if domain[i] >= utf8.RuneSelf {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		domain = strings.ToLower(domain)
+	} else {
+		domain = norm.NFKC.String(strings.ToLower(domain))
+		domain = strings.NewReplacer("。", ".", "．", ".", "｡", ".").Replace(domain)
+	}
+	labels := strings.Split(domain, ".")
+	for i, label := range labels {
+		if strings.HasPrefix(label, "xn--") {
+			if _, ok := punycodeDecode(label[4:]); !ok {
+				return "", false
+			}
+			continue
+		}
+		for j := 0; j < len(label); j++ {
+			if label[j] >= utf8.RuneSelf {
+				encoded, ok := punycodeEncode(label)
+				if !ok {
+					return "", false
+				}
+				labels[i] = "xn--" + encoded
+				break
+			}
+		}
+	}
+	res := strings.Join(labels, ".")
+	if res == "" {
+		return "", false
+	}
+	return res, true
+}
+
+// Punycode parameters (https://www.rfc-editor.org/rfc/rfc3492#section-5)
+const (
+	punycodeBase        = 36
+	punycodeTMin        = 1
+	punycodeTMax        = 26
+	punycodeSkew        = 38
+	punycodeDamp        = 700
+	punycodeInitialBias = 72
+	punycodeInitialN    = 128
+	punycodeMaxInt      = 1<<31 - 1
+)
+
+func punycodeAdapt(delta, numPoints int, firstTime bool) int {
+	if firstTime {
+		delta /= punycodeDamp
+	} else {
+		delta /= 2
+	}
+	delta += delta / numPoints
+	k := 0
+	for delta > ((punycodeBase-punycodeTMin)*punycodeTMax)/2 {
+		delta /= punycodeBase - punycodeTMin
+		k += punycodeBase
+	}
+	return k + (punycodeBase-punycodeTMin+1)*delta/(delta+punycodeSkew)
+}
+
+func punycodeDigit(d int) byte {
+	if d < 26 {
+		return byte('a' + d)
+	}
+	return byte('0' + d - 26)
+}
+
+func punycodeThreshold(k, bias int) int {
+	switch {
+	case k <= bias:
+		return punycodeTMin
+	case k >= bias+punycodeTMax:
+		return punycodeTMax
+	}
+	return k - bias
+}
+
+func punycodeEncode(s string) (string, bool) {
+	input := []rune(s)
+	var out []byte
+	for _, c := range input {
+		if c < 0x80 {
+			out = append(out, byte(c))
+		}
+	}
+	b := len(out)
+	h := b
+	if b > 0 {
+		out = append(out, '-')
+	}
+	n, delta, bias := punycodeInitialN, 0, punycodeInitialBias
+	for h < len(input) {
+		m := punycodeMaxInt
+		for _, c := range input {
+			if int(c) >= n && int(c) < m {
+				m = int(c)
+			}
+		}
+		if (m - n) > (punycodeMaxInt-delta)/(h+1) {
+			return "", false
+		}
+		delta += (m - n) * (h + 1)
+		n = m
+		for _, c := range input {
+			if int(c) < n {
+				delta++
+				if delta > punycodeMaxInt {
+					return "", false
+				}
+			}
+			if int(c) == n {
+				q := delta
+				for k := punycodeBase; ; k += punycodeBase {
+					t := punycodeThreshold(k, bias)
+					if q < t {
+						break
+					}
+					out = append(out, punycodeDigit(t+(q-t)%(punycodeBase-t)))
+					q = (q - t) / (punycodeBase - t)
+				}
+				out = append(out, punycodeDigit(q))
+				bias = punycodeAdapt(delta, h+1, h == b)
+				delta = 0
+				h++
+			}
+		}
+		delta++
+		n++
+	}
+	return string(out), true
+}
+
+func punycodeDecode(s string) (string, bool) {
+	var output []rune
+	if i := strings.LastIndexByte(s, '-'); i >= 0 {
+		for j := 0; j < i; j++ {
+			if s[j] >= 0x80 {
+				return "", false
+			}
+			output = append(output, rune(s[j]))
+		}
+		s = s[i+1:]
+	}
+	n, i, bias := punycodeInitialN, 0, punycodeInitialBias
+	for pos := 0; pos < len(s); {
+		oldi, w := i, 1
+		for k := punycodeBase; ; k += punycodeBase {
+			if pos >= len(s) {
+				return "", false
+			}
+			c := s[pos]
+			pos++
+			var digit int
+			switch {
+			case c >= '0' && c <= '9':
+				digit = int(c-'0') + 26
+			case c >= 'a' && c <= 'z':
+				digit = int(c - 'a')
+			case c >= 'A' && c <= 'Z':
+				digit = int(c - 'A')
+			default:
+				return "", false
+			}
+			if digit > (punycodeMaxInt-i)/w {
+				return "", false
+			}
+			i += digit * w
+			t := punycodeThreshold(k, bias)
+			if digit < t {
+				break
+			}
+			if w > punycodeMaxInt/(punycodeBase-t) {
+				return "", false
+			}
+			w *= punycodeBase - t
+		}
+		bias = punycodeAdapt(i-oldi, len(output)+1, oldi == 0)
+		if i/(len(output)+1) > punycodeMaxInt-n {
+			return "", false
+		}
+		n += i / (len(output) + 1)
+		i %= len(output) + 1
+		if n > utf8.MaxRune {
+			return "", false
+		}
+		output = append(output, 0)
+		copy(output[i+1:], output[i:])
+		output[i] = rune(n)
+		i++
+	}
+	return string(output), true
+}
+
+// parseIPv4Number implements https://url.spec.whatwg.org/#ipv4-number-parser
+func parseIPv4Number(s string) (uint64, bool) {
+	if s == "" {
+		return 0, false
+	}
+	radix := 10
+	if len(s) >= 2 && (s[:2] == "0x" || s[:2] == "0X") {
+		s = s[2:]
+		radix = 16
+	} else if len(s) >= 2 && s[0] == '0' {
+		s = s[1:]
+		radix = 8
+	}
+	if s == "" {
+		return 0, true
+	}
+	for i := 0; i < len(s); i++ {
+		if _, ok := hexDigit(s[i]); !ok {
+			return 0, false
+		}
+	}
+	n, err := strconv.ParseUint(s, radix, 64)
+	if err != nil {
+		if ne, ok := err.(*strconv.NumError); ok && ne.Err == strconv.ErrRange {
+			return 1 << 40, true
+		}
+		return 0, false
+	}
+	return n, true
+}
+
+func endsInANumber(s string) bool {
+	parts := strings.Split(s, ".")
+	if parts[len(parts)-1] == "" {
+		if len(parts) == 1 {
+			return false
+		}
+		parts = parts[:len(parts)-1]
+	}
+	last := parts[len(parts)-1]
+	if last != "" && strings.Trim(last, "0123456789") == "" {
+		return true
+	}
+	_, ok := parseIPv4Number(last)
+	return ok
+}
+
+// parseIPv4 implements https://url.spec.whatwg.org/#concept-ipv4-parser
+func parseIPv4(s string) (uint32, bool) {
+	parts := strings.Split(s, ".")
+	if parts[len(parts)-1] == "" && len(parts) > 1 {
+		parts = parts[:len(parts)-1]
+	}
+	if len(parts) > 4 {
+		return 0, false
+	}
+	numbers := make([]uint64, len(parts))
+	for i, part := range parts {
+		n, ok := parseIPv4Number(part)
+		if !ok {
+			return 0, false
+		}
+		numbers[i] = n
+	}
+	for _, n := range numbers[:len(numbers)-1] {
+		if n > 255 {
+			return 0, false
+		}
+	}
+	last := numbers[len(numbers)-1]
+	if last >= 1<<(8*(5-len(numbers))) {
+		return 0, false
+	}
+	ipv4 := last
+	for i, n := range numbers[:len(numbers)-1] {
+		ipv4 += n << (8 * (3 - i))
+	}
+	return uint32(ipv4), true
+}
+
+func serializeIPv4(addr uint32) string {
+	return strconv.Itoa(int(addr>>24)) + "." + strconv.Itoa(int(addr>>16&0xFF)) + "." +
+		strconv.Itoa(int(addr>>8&0xFF)) + "." + strconv.Itoa(int(addr&0xFF))
+}
+
+// parseIPv6 implements https://url.spec.whatwg.org/#concept-ipv6-parser
+func parseIPv6(s string) ([8]uint16, bool) {
+	var addr [8]uint16
+	input := []byte(s)
+	pieceIndex, compress := 0, -1
+	pointer := 0
+	at := func(i int) int {
+		if i < len(input) {
+			return int(input[i])
+		}
+		return urlEOF
+	}
+	if at(0) == ':' {
+		if at(1) != ':' {
+			return addr, false
+		}
+		pointer += 2
+		pieceIndex++
+		compress = pieceIndex
+	}
+	for at(pointer) != urlEOF {
+		if pieceIndex == 8 {
+			return addr, false
+		}
+		if at(pointer) == ':' {
+			if compress != -1 {
+				return addr, false
+			}
+			pointer++
+			pieceIndex++
+			compress = pieceIndex
+			continue
+		}
+		value, length := 0, 0
+		for length < 4 && at(pointer) != urlEOF {
+			d, ok := hexDigit(input[pointer])
+			if !ok {
+				break
+			}
+			value = value*0x10 + int(d)
+			pointer++
+			length++
+		}
+		if at(pointer) == '.' {
+			if length == 0 {
+				return addr, false
+			}
+			pointer -= length
+			if pieceIndex > 6 {
+				return addr, false
+			}
+			numbersSeen := 0
+			for at(pointer) != urlEOF {
+				ipv4Piece := -1
+				if numbersSeen > 0 {
+					if at(pointer) == '.' && numbersSeen < 4 {
+						pointer++
+					} else {
+						return addr, false
+					}
+				}
+				if !isASCIIDigit(rune(at(pointer))) {
+					return addr, false
+				}
+				for isASCIIDigit(rune(at(pointer))) {
+					number := at(pointer) - '0'
+					if ipv4Piece == -1 {
+						ipv4Piece = number
+					} else if ipv4Piece == 0 {
+						return addr, false
+					} else {
+						ipv4Piece = ipv4Piece*10 + number
+					}
+					if ipv4Piece > 255 {
+						return addr, false
+					}
+					pointer++
+				}
+				addr[pieceIndex] = addr[pieceIndex]*0x100 + uint16(ipv4Piece)
+				numbersSeen++
+				if numbersSeen == 2 || numbersSeen == 4 {
+					pieceIndex++
+				}
+			}
+			if numbersSeen != 4 {
+				return addr, false
+			}
+			break
+		} else if at(pointer) == ':' {
+			pointer++
+			if at(pointer) == urlEOF {
+				return addr, false
+			}
+		} else if at(pointer) != urlEOF {
+			return addr, false
+		}
+		addr[pieceIndex] = uint16(value)
+		pieceIndex++
+	}
+	if compress != -1 {
+		swaps := pieceIndex - compress
+		pieceIndex = 7
+		for pieceIndex != 0 && swaps > 0 {
+			addr[pieceIndex], addr[compress+swaps-1] = addr[compress+swaps-1], addr[pieceIndex]
+			pieceIndex--
+			swaps--
+		}
+	} else if pieceIndex != 8 {
+		return addr, false
+	}
+	return addr, true
+}
+
+// serializeIPv6 implements https://url.spec.whatwg.org/#concept-ipv6-serializer
+func serializeIPv6(addr [8]uint16) string {
+	// find the first longest sequence of zero pieces longer than 1
+	compress, longest := -1, 1
+	for i := 0; i < 8; {
+		if addr[i] != 0 {
+			i++
+			continue
+		}
+		j := i
+		for j < 8 && addr[j] == 0 {
+			j++
+		}
+		if j-i > longest {
+			compress, longest = i, j-i
+		}
+		i = j
+	}
+	var sb strings.Builder
+	ignore0 := false
+	for i := 0; i < 8; i++ {
+		if ignore0 && addr[i] == 0 {
+			continue
+		}
+		ignore0 = false
+		if compress == i {
+			if i == 0 {
+				sb.WriteString("::")
+			} else {
+				sb.WriteByte(':')
+			}
+			ignore0 = true
+			continue
+		}
+		sb.WriteString(strconv.FormatUint(uint64(addr[i]), 16))
+		if i != 7 {
+			sb.WriteByte(':')
+		}
+	}
+	return sb.String()
+}
+
+// urlQueryPair is a name-value pair of application/x-www-form-urlencoded data.
+type urlQueryPair struct {
+	name, value string
+}
+
+// parseURLEncoded implements https://url.spec.whatwg.org/#concept-urlencoded-parser
+func parseURLEncoded(s string) []urlQueryPair {
+	var res []urlQueryPair
+	for _, seq := range strings.Split(s, "&") {
+		if seq == "" {
+			continue
+		}
+		name, value := seq, ""
+		if i := strings.IndexByte(seq, '='); i >= 0 {
+			name, value = seq[:i], seq[i+1:]
+		}
+		name = strings.ReplaceAll(name, "+", " ")
+		value = strings.ReplaceAll(value, "+", " ")
+		res = append(res, urlQueryPair{
+			name:  utf8DecodeWithoutBOM(urlPercentDecode(name)),
+			value: utf8DecodeWithoutBOM(urlPercentDecode(value)),
+		})
+	}
+	return res
+}
+
+// serializeURLEncoded implements https://url.spec.whatwg.org/#concept-urlencoded-serializer
+func serializeURLEncoded(list []urlQueryPair) string {
+	var sb strings.Builder
+	for i, p := range list {
+		if i > 0 {
+			sb.WriteByte('&')
+		}
+		urlPercentEncode(&sb, p.name, urlFormSet, true)
+		sb.WriteByte('=')
+		urlPercentEncode(&sb, p.value, urlFormSet, true)
+	}
+	return sb.String()
+}
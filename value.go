@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"hash/maphash"
 	"math"
+	"math/big"
 	"reflect"
 	"strconv"
 	"unsafe"
@@ -20,6 +21,9 @@ var (
 	hashTrue  = randomHash()
 	hashNull  = randomHash()
 	hashUndef = randomHash()
+
+	hashRecord = randomHash()
+	hashTuple  = randomHash()
 )
 
 // Not goroutine-safe, do not use for anything other than package level init
@@ -47,6 +51,7 @@ var (
 	reflectTypeBool   = reflect.TypeOf(false)
 	reflectTypeNil    = reflect.TypeOf(nil)
 	reflectTypeFloat  = reflect.TypeOf(float64(0))
+	reflectTypeBigInt = reflect.TypeOf((*big.Int)(nil))
 	reflectTypeMap    = reflect.TypeOf(map[string]interface{}{})
 	reflectTypeArray  = reflect.TypeOf([]interface{}{})
 	reflectTypeString = reflect.TypeOf("")
@@ -64,6 +69,8 @@ var intCache [256]Value
 //
 // For any other numbers (including Infinities, NaN and negative zero) it's float64.
 //
+// For BigInt it's *big.Int.
+//
 // For string it's a string. Note that unicode strings are converted into UTF-8 with invalid code points replaced with utf8.RuneError.
 //
 // For boolean it's bool.
@@ -103,6 +110,7 @@ type syntaxError string
 
 type valueInt int64
 type valueFloat float64
+type valueBigInt big.Int
 type valueBool bool
 type valueNull struct{}
 type valueUndefined struct {
@@ -140,6 +148,8 @@ type valueProperty struct {
 var (
 	errAccessBeforeInit = referenceError("Cannot access a variable before initialization")
 	errAssignToConst    = typeError("Assignment to constant variable.")
+	errMixBigIntType    = typeError("Cannot mix BigInt and other types, use explicit conversions")
+	errBigIntToNumber   = typeError("Cannot convert a BigInt value to a number")
 )
 
 func propGetter(o Value, v Value, r *Runtime) *Object {
@@ -223,6 +233,8 @@ func (i valueInt) Equals(other Value) bool {
 		return o.ToNumber().Equals(i)
 	case valueBool:
 		return int64(i) == o.ToInteger()
+	case *valueBigInt:
+		return o.Equals(i)
 	case *Object:
 		return i.Equals(o.toPrimitive())
 	}
@@ -644,6 +656,8 @@ func (f valueFloat) Equals(other Value) bool {
 		return float64(f) == float64(o)
 	case valueString, valueBool:
 		return float64(f) == o.ToFloat()
+	case *valueBigInt:
+		return o.Equals(f)
 	case *Object:
 		return f.Equals(o.toPrimitive())
 	}
@@ -681,6 +695,96 @@ func (f valueFloat) hash(*maphash.Hash) uint64 {
 	return math.Float64bits(float64(f))
 }
 
+func (b *valueBigInt) ToInteger() int64 {
+	panic(errBigIntToNumber)
+}
+
+func (b *valueBigInt) toString() valueString {
+	return asciiString(b.String())
+}
+
+func (b *valueBigInt) string() unistring.String {
+	return unistring.String(b.String())
+}
+
+func (b *valueBigInt) ToString() Value {
+	return b
+}
+
+func (b *valueBigInt) String() string {
+	return (*big.Int)(b).String()
+}
+
+func (b *valueBigInt) ToFloat() float64 {
+	panic(errBigIntToNumber)
+}
+
+func (b *valueBigInt) ToBoolean() bool {
+	return (*big.Int)(b).Sign() != 0
+}
+
+func (b *valueBigInt) ToObject(r *Runtime) *Object {
+	return r.newPrimitiveObject(b, r.global.BigIntPrototype, classBigInt)
+}
+
+func (b *valueBigInt) ToNumber() Value {
+	panic(errBigIntToNumber)
+}
+
+func (b *valueBigInt) SameAs(other Value) bool {
+	if o, ok := other.(*valueBigInt); ok {
+		return (*big.Int)(b).Cmp((*big.Int)(o)) == 0
+	}
+	return false
+}
+
+func (b *valueBigInt) Equals(other Value) bool {
+	switch o := other.(type) {
+	case *valueBigInt:
+		return (*big.Int)(b).Cmp((*big.Int)(o)) == 0
+	case valueInt:
+		return (*big.Int)(b).IsInt64() && (*big.Int)(b).Int64() == int64(o)
+	case valueFloat:
+		c, ok := compareBigIntFloat((*big.Int)(b), float64(o))
+		return ok && c == 0
+	case valueString:
+		if ob := stringToBigInt(o); ob != nil {
+			return (*big.Int)(b).Cmp(ob) == 0
+		}
+	case valueBool:
+		return b.Equals(o.ToNumber())
+	case *Object:
+		return b.Equals(o.toPrimitive())
+	}
+	return false
+}
+
+func (b *valueBigInt) StrictEquals(other Value) bool {
+	return b.SameAs(other)
+}
+
+func (b *valueBigInt) baseObject(r *Runtime) *Object {
+	return r.global.BigIntPrototype
+}
+
+func (b *valueBigInt) Export() interface{} {
+	return new(big.Int).Set((*big.Int)(b))
+}
+
+func (b *valueBigInt) ExportType() reflect.Type {
+	return reflectTypeBigInt
+}
+
+func (b *valueBigInt) hash(hash *maphash.Hash) uint64 {
+	if (*big.Int)(b).Sign() < 0 {
+		_ = hash.WriteByte('-')
+	}
+	_, _ = hash.Write((*big.Int)(b).Bytes())
+	h := hash.Sum64()
+	hash.Reset()
+	return h
+}
+
 func (o *Object) ToInteger() int64 {
 	return o.toPrimitiveNumber().ToNumber().ToInteger()
 }
@@ -730,7 +834,7 @@ func (o *Object) Equals(other Value) bool {
 	}
 
 	switch o1 := other.(type) {
-	case valueInt, valueFloat, valueString, *Symbol:
+	case valueInt, valueFloat, *valueBigInt, valueString, *Symbol:
 		return o.toPrimitive().Equals(other)
 	case valueBool:
 		return o.Equals(o1.ToNumber())
@@ -3,10 +3,12 @@ package goja
 import (
 	"fmt"
 	"math"
+	"math/big"
 	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/dop251/goja/unistring"
 )
@@ -110,6 +112,10 @@ type iterStackItem struct {
 	val  Value
 	f    iterNextFunc
 	iter *iteratorRecord
+
+	// set instead of iter while the result of an async iterator's next() is being awaited, so that the
+	// iterator is not closed if the await throws
+	awaiting *iteratorRecord
 }
 
 type ref interface {
@@ -195,6 +201,25 @@ func (r *stashRefLex) init(v Value) {
 	(*r.v)[r.idx] = v
 }
 
+type importRef struct {
+	stashRef
+}
+
+func (r *importRef) get() Value {
+	v := (*r.v)[r.idx]
+	if b, ok := v.(*importedBinding); ok {
+		v = b.get()
+	}
+	if v == nil {
+		panic(errAccessBeforeInit)
+	}
+	return v
+}
+
+func (r *importRef) set(Value) {
+	panic(errAssignToConst)
+}
+
 type stashRefConst struct {
 	stashRefLex
 	strictConst bool
@@ -215,7 +240,11 @@ type objRef struct {
 }
 
 func (r *objRef) get() Value {
-	return r.base.self.getStr(r.name, r.this)
+	v := r.base.self.getStr(r.name, r.this)
+	if v == nil && !r.binding {
+		return _undefined
+	}
+	return v
 }
 
 func (r *objRef) set(v Value) {
@@ -241,6 +270,34 @@ func (r *objRef) refname() unistring.String {
 	return r.name
 }
 
+// objKeyRef is a reference to an object property with an arbitrary (i.e. possibly Symbol) key.
+type objKeyRef struct {
+	base   *Object
+	name   Value
+	this   Value
+	strict bool
+}
+
+func (r *objKeyRef) get() Value {
+	return nilSafe(r.base.get(r.name, r.this))
+}
+
+func (r *objKeyRef) set(v Value) {
+	if r.this != nil {
+		r.base.set(r.name, v, r.this, r.strict)
+	} else {
+		r.base.setOwn(r.name, v, r.strict)
+	}
+}
+
+func (r *objKeyRef) init(v Value) {
+	r.set(v)
+}
+
+func (r *objKeyRef) refname() unistring.String {
+	return r.name.string()
+}
+
 type privateRefRes struct {
 	base *Object
 	name *resolvedPrivateName
@@ -322,12 +379,21 @@ type vm struct {
 	result    Value
 
 	maxCallStackSize int
+	// if set, exceeding maxCallStackSize throws a RangeError instead of *StackOverflowError
+	catchableStackOverflow bool
+
+	// the budget set by Runtime.SetTimeLimit and the time it runs out for the current run (zero until the first check)
+	timeLimit  time.Duration
+	deadline   time.Time
+	timeChecks uint32
 
 	stashAllocs int
 
 	interrupted   uint32
 	interruptVal  interface{}
 	interruptLock sync.Mutex
+	// closed by Interrupt to wake up the blocking operations (such as Atomics.wait), see interruptChan
+	interruptCh chan struct{}
 
 	curAsyncRunner *asyncRunner
 }
@@ -447,6 +513,9 @@ func (s *stash) getByName(name unistring.String) (v Value, exists bool) {
 	}
 	if idx, exists := s.names[name]; exists {
 		v := s.values[idx&^maskTyp]
+		if b, ok := v.(*importedBinding); ok {
+			v = b.get()
+		}
 		if v == nil {
 			if idx&maskVar == 0 {
 				panic(errAccessBeforeInit)
@@ -472,6 +541,15 @@ func (s *stash) getRefByName(name unistring.String, strict bool) ref {
 	} else {
 		if idx, exists := s.names[name]; exists {
 			if idx&maskVar == 0 {
+				if _, ok := s.values[idx&^maskTyp].(*importedBinding); ok {
+					return &importRef{
+						stashRef: stashRef{
+							n:   name,
+							v:   &s.values,
+							idx: int(idx &^ maskTyp),
+						},
+					}
+				}
 				if idx&maskConst == 0 {
 					return &stashRefLex{
 						stashRef: stashRef{
@@ -582,13 +660,71 @@ func (vm *vm) Interrupt(v interface{}) {
 	vm.interruptLock.Lock()
 	vm.interruptVal = v
 	atomic.StoreUint32(&vm.interrupted, 1)
+	if vm.interruptCh != nil {
+		close(vm.interruptCh)
+		vm.interruptCh = nil
+	}
 	vm.interruptLock.Unlock()
 }
 
+// interruptChan returns a channel which is closed when the vm is interrupted (it's already closed if the vm has
+// been interrupted). The native functions that block use it to return early, the interrupt then takes effect
+// when they return.
+func (vm *vm) interruptChan() <-chan struct{} {
+	vm.interruptLock.Lock()
+	defer vm.interruptLock.Unlock()
+	if atomic.LoadUint32(&vm.interrupted) != 0 {
+		ch := make(chan struct{})
+		close(ch)
+		return ch
+	}
+	if vm.interruptCh == nil {
+		vm.interruptCh = make(chan struct{})
+	}
+	return vm.interruptCh
+}
+
+// timeLimitChan returns a channel which receives when the time limit of the current run (see Runtime.SetTimeLimit)
+// is exceeded, nil if there is no limit. stop must be called to release the timer.
+func (vm *vm) timeLimitChan() (ch <-chan time.Time, stop func() bool) {
+	if vm.timeLimit <= 0 {
+		return nil, func() bool { return false }
+	}
+	if vm.deadline.IsZero() {
+		vm.deadline = time.Now().Add(vm.timeLimit)
+	}
+	t := time.NewTimer(time.Until(vm.deadline))
+	return t.C, t.Stop
+}
+
 func (vm *vm) ClearInterrupt() {
 	atomic.StoreUint32(&vm.interrupted, 0)
 }
 
+// timeLimitCheckInterval is the number of loop iterations and function calls between the clock readings.
+const timeLimitCheckInterval = 256
+
+// startTimeLimit starts the clock of the time limit when a run begins.
+func (vm *vm) startTimeLimit() {
+	if vm.timeLimit > 0 && vm.deadline.IsZero() {
+		vm.deadline = time.Now().Add(vm.timeLimit)
+		vm.timeChecks = 0
+	}
+}
+
+// checkTimeLimit interrupts the vm if the time limit of the current run has been exceeded. It's called on the
+// backward (and self) jumps, which close every loop, and on the function calls.
+func (vm *vm) checkTimeLimit() {
+	if vm.deadline.IsZero() {
+		vm.startTimeLimit()
+		return
+	}
+	vm.timeChecks++
+	if vm.timeChecks%timeLimitCheckInterval == 0 && time.Now().After(vm.deadline) {
+		vm.Interrupt(&TimeLimitError{Limit: vm.timeLimit})
+	}
+}
+
 func getFuncName(stack []Value, sb int) unistring.String {
 	if sb > 0 {
 		if f, ok := stack[sb-1].(*Object); ok {
@@ -799,10 +935,16 @@ func (vm *vm) saveCtx(ctx *context) {
 
 func (vm *vm) pushCtx() {
 	if len(vm.callStack) > vm.maxCallStackSize {
+		if vm.catchableStackOverflow {
+			panic(vm.r.newError(vm.r.global.RangeError, "Maximum call stack size exceeded"))
+		}
 		ex := &StackOverflowError{}
 		ex.stack = vm.captureStack(nil, 0)
 		panic(ex)
 	}
+	if vm.timeLimit > 0 {
+		vm.checkTimeLimit()
+	}
 	vm.callStack = append(vm.callStack, context{})
 	ctx := &vm.callStack[len(vm.callStack)-1]
 	vm.saveCtx(ctx)
@@ -1125,7 +1267,7 @@ type _toNumber struct{}
 var toNumber _toNumber
 
 func (_toNumber) exec(vm *vm) {
-	vm.stack[vm.sp-1] = vm.stack[vm.sp-1].ToNumber()
+	vm.stack[vm.sp-1] = toNumeric(vm.stack[vm.sp-1])
 	vm.pc++
 }
 
@@ -1134,6 +1276,10 @@ type _add struct{}
 var add _add
 
 func (_add) exec(vm *vm) {
+	if vm.decimalBinaryOp(decimalAdd) {
+		return
+	}
+
 	right := vm.stack[vm.sp-1]
 	left := vm.stack[vm.sp-2]
 
@@ -1157,18 +1303,24 @@ func (_add) exec(vm *vm) {
 		if !isRightString {
 			rightString = right.toString()
 		}
+		if vm.r.maxStringLength > 0 {
+			vm.r.checkStringLength(int64(leftString.length()) + int64(rightString.length()))
+		}
 		ret = leftString.concat(rightString)
 	} else {
 		if leftInt, ok := left.(valueInt); ok {
 			if rightInt, ok := right.(valueInt); ok {
 				ret = intToValue(int64(leftInt) + int64(rightInt))
-			} else {
-				ret = floatToValue(float64(leftInt) + right.ToFloat())
+				goto end
 			}
+		}
+		if leftBi, rightBi, ok := bigIntOperands(left, right); ok {
+			ret = (*valueBigInt)(new(big.Int).Add(leftBi, rightBi))
 		} else {
 			ret = floatToValue(left.ToFloat() + right.ToFloat())
 		}
 	}
+end:
 
 	vm.stack[vm.sp-2] = ret
 	vm.sp--
@@ -1180,6 +1332,10 @@ type _sub struct{}
 var sub _sub
 
 func (_sub) exec(vm *vm) {
+	if vm.decimalBinaryOp(decimalSub) {
+		return
+	}
+
 	right := vm.stack[vm.sp-1]
 	left := vm.stack[vm.sp-2]
 
@@ -1192,6 +1348,13 @@ func (_sub) exec(vm *vm) {
 		}
 	}
 
+	left = toNumeric(left)
+	right = toNumeric(right)
+	if leftBi, rightBi, ok := bigIntOperands(left, right); ok {
+		result = (*valueBigInt)(new(big.Int).Sub(leftBi, rightBi))
+		goto end
+	}
+
 	result = floatToValue(left.ToFloat() - right.ToFloat())
 end:
 	vm.sp--
@@ -1204,11 +1367,20 @@ type _mul struct{}
 var mul _mul
 
 func (_mul) exec(vm *vm) {
-	left := vm.stack[vm.sp-2]
-	right := vm.stack[vm.sp-1]
+	if vm.decimalBinaryOp(decimalMul) {
+		return
+	}
+
+	left := toNumeric(vm.stack[vm.sp-2])
+	right := toNumeric(vm.stack[vm.sp-1])
 
 	var result Value
 
+	if leftBi, rightBi, ok := bigIntOperands(left, right); ok {
+		result = (*valueBigInt)(new(big.Int).Mul(leftBi, rightBi))
+		goto end
+	}
+
 	if left, ok := assertInt64(left); ok {
 		if right, ok := assertInt64(right); ok {
 			if left == 0 && right == -1 || left == -1 && right == 0 {
@@ -1239,7 +1411,13 @@ var exp _exp
 
 func (_exp) exec(vm *vm) {
 	vm.sp--
-	vm.stack[vm.sp-1] = pow(vm.stack[vm.sp-1], vm.stack[vm.sp])
+	x := toNumeric(vm.stack[vm.sp-1])
+	y := toNumeric(vm.stack[vm.sp])
+	if xBi, yBi, ok := bigIntOperands(x, y); ok {
+		vm.stack[vm.sp-1] = bigIntPow(xBi, yBi)
+	} else {
+		vm.stack[vm.sp-1] = pow(x, y)
+	}
 	vm.pc++
 }
 
@@ -1248,10 +1426,23 @@ type _div struct{}
 var div _div
 
 func (_div) exec(vm *vm) {
-	left := vm.stack[vm.sp-2].ToFloat()
-	right := vm.stack[vm.sp-1].ToFloat()
+	if vm.decimalBinaryOp(decimalDiv) {
+		return
+	}
+
+	leftNum := toNumeric(vm.stack[vm.sp-2])
+	rightNum := toNumeric(vm.stack[vm.sp-1])
 
 	var result Value
+	var left, right float64
+
+	if leftBi, rightBi, ok := bigIntOperands(leftNum, rightNum); ok {
+		result = bigIntQuo(leftBi, rightBi)
+		goto end
+	}
+
+	left = leftNum.ToFloat()
+	right = rightNum.ToFloat()
 
 	if math.IsNaN(left) || math.IsNaN(right) {
 		result = _NaN
@@ -1307,11 +1498,20 @@ type _mod struct{}
 var mod _mod
 
 func (_mod) exec(vm *vm) {
-	left := vm.stack[vm.sp-2]
-	right := vm.stack[vm.sp-1]
+	if vm.decimalBinaryOp(decimalMod) {
+		return
+	}
+
+	left := toNumeric(vm.stack[vm.sp-2])
+	right := toNumeric(vm.stack[vm.sp-1])
 
 	var result Value
 
+	if leftBi, rightBi, ok := bigIntOperands(left, right); ok {
+		result = bigIntRem(leftBi, rightBi)
+		goto end
+	}
+
 	if leftInt, ok := assertInt64(left); ok {
 		if rightInt, ok := assertInt64(right); ok {
 			if rightInt == 0 {
@@ -1340,11 +1540,17 @@ type _neg struct{}
 var neg _neg
 
 func (_neg) exec(vm *vm) {
-	operand := vm.stack[vm.sp-1]
+	if vm.decimalNegOp() {
+		return
+	}
+
+	operand := toNumeric(vm.stack[vm.sp-1])
 
 	var result Value
 
-	if i, ok := assertInt64(operand); ok {
+	if bi, ok := operand.(*valueBigInt); ok {
+		result = (*valueBigInt)(new(big.Int).Neg((*big.Int)(bi)))
+	} else if i, ok := assertInt64(operand); ok {
 		if i == 0 {
 			result = _negativeZero
 		} else {
@@ -1376,7 +1582,12 @@ type _inc struct{}
 var inc _inc
 
 func (_inc) exec(vm *vm) {
-	v := vm.stack[vm.sp-1]
+	v := toNumeric(vm.stack[vm.sp-1])
+
+	if bi, ok := v.(*valueBigInt); ok {
+		v = (*valueBigInt)(new(big.Int).Add((*big.Int)(bi), bigIntOne))
+		goto end
+	}
 
 	if i, ok := assertInt64(v); ok {
 		v = intToValue(i + 1)
@@ -1395,7 +1606,12 @@ type _dec struct{}
 var dec _dec
 
 func (_dec) exec(vm *vm) {
-	v := vm.stack[vm.sp-1]
+	v := toNumeric(vm.stack[vm.sp-1])
+
+	if bi, ok := v.(*valueBigInt); ok {
+		v = (*valueBigInt)(new(big.Int).Sub((*big.Int)(bi), bigIntOne))
+		goto end
+	}
 
 	if i, ok := assertInt64(v); ok {
 		v = intToValue(i - 1)
@@ -1414,9 +1630,13 @@ type _and struct{}
 var and _and
 
 func (_and) exec(vm *vm) {
-	left := toInt32(vm.stack[vm.sp-2])
-	right := toInt32(vm.stack[vm.sp-1])
-	vm.stack[vm.sp-2] = intToValue(int64(left & right))
+	left := toNumeric(vm.stack[vm.sp-2])
+	right := toNumeric(vm.stack[vm.sp-1])
+	if leftBi, rightBi, ok := bigIntOperands(left, right); ok {
+		vm.stack[vm.sp-2] = (*valueBigInt)(new(big.Int).And(leftBi, rightBi))
+	} else {
+		vm.stack[vm.sp-2] = intToValue(int64(toInt32(left) & toInt32(right)))
+	}
 	vm.sp--
 	vm.pc++
 }
@@ -1426,9 +1646,13 @@ type _or struct{}
 var or _or
 
 func (_or) exec(vm *vm) {
-	left := toInt32(vm.stack[vm.sp-2])
-	right := toInt32(vm.stack[vm.sp-1])
-	vm.stack[vm.sp-2] = intToValue(int64(left | right))
+	left := toNumeric(vm.stack[vm.sp-2])
+	right := toNumeric(vm.stack[vm.sp-1])
+	if leftBi, rightBi, ok := bigIntOperands(left, right); ok {
+		vm.stack[vm.sp-2] = (*valueBigInt)(new(big.Int).Or(leftBi, rightBi))
+	} else {
+		vm.stack[vm.sp-2] = intToValue(int64(toInt32(left) | toInt32(right)))
+	}
 	vm.sp--
 	vm.pc++
 }
@@ -1438,9 +1662,13 @@ type _xor struct{}
 var xor _xor
 
 func (_xor) exec(vm *vm) {
-	left := toInt32(vm.stack[vm.sp-2])
-	right := toInt32(vm.stack[vm.sp-1])
-	vm.stack[vm.sp-2] = intToValue(int64(left ^ right))
+	left := toNumeric(vm.stack[vm.sp-2])
+	right := toNumeric(vm.stack[vm.sp-1])
+	if leftBi, rightBi, ok := bigIntOperands(left, right); ok {
+		vm.stack[vm.sp-2] = (*valueBigInt)(new(big.Int).Xor(leftBi, rightBi))
+	} else {
+		vm.stack[vm.sp-2] = intToValue(int64(toInt32(left) ^ toInt32(right)))
+	}
 	vm.sp--
 	vm.pc++
 }
@@ -1450,8 +1678,12 @@ type _bnot struct{}
 var bnot _bnot
 
 func (_bnot) exec(vm *vm) {
-	op := toInt32(vm.stack[vm.sp-1])
-	vm.stack[vm.sp-1] = intToValue(int64(^op))
+	op := toNumeric(vm.stack[vm.sp-1])
+	if bi, ok := op.(*valueBigInt); ok {
+		vm.stack[vm.sp-1] = (*valueBigInt)(new(big.Int).Not((*big.Int)(bi)))
+	} else {
+		vm.stack[vm.sp-1] = intToValue(int64(^toInt32(op)))
+	}
 	vm.pc++
 }
 
@@ -1460,9 +1692,13 @@ type _sal struct{}
 var sal _sal
 
 func (_sal) exec(vm *vm) {
-	left := toInt32(vm.stack[vm.sp-2])
-	right := toUint32(vm.stack[vm.sp-1])
-	vm.stack[vm.sp-2] = intToValue(int64(left << (right & 0x1F)))
+	left := toNumeric(vm.stack[vm.sp-2])
+	right := toNumeric(vm.stack[vm.sp-1])
+	if leftBi, rightBi, ok := bigIntOperands(left, right); ok {
+		vm.stack[vm.sp-2] = bigIntShiftLeft(leftBi, rightBi)
+	} else {
+		vm.stack[vm.sp-2] = intToValue(int64(toInt32(left) << (toUint32(right) & 0x1F)))
+	}
 	vm.sp--
 	vm.pc++
 }
@@ -1472,9 +1708,13 @@ type _sar struct{}
 var sar _sar
 
 func (_sar) exec(vm *vm) {
-	left := toInt32(vm.stack[vm.sp-2])
-	right := toUint32(vm.stack[vm.sp-1])
-	vm.stack[vm.sp-2] = intToValue(int64(left >> (right & 0x1F)))
+	left := toNumeric(vm.stack[vm.sp-2])
+	right := toNumeric(vm.stack[vm.sp-1])
+	if leftBi, rightBi, ok := bigIntOperands(left, right); ok {
+		vm.stack[vm.sp-2] = bigIntShiftRight(leftBi, rightBi)
+	} else {
+		vm.stack[vm.sp-2] = intToValue(int64(toInt32(left) >> (toUint32(right) & 0x1F)))
+	}
 	vm.sp--
 	vm.pc++
 }
@@ -1484,9 +1724,12 @@ type _shr struct{}
 var shr _shr
 
 func (_shr) exec(vm *vm) {
-	left := toUint32(vm.stack[vm.sp-2])
-	right := toUint32(vm.stack[vm.sp-1])
-	vm.stack[vm.sp-2] = intToValue(int64(left >> (right & 0x1F)))
+	left := toNumeric(vm.stack[vm.sp-2])
+	right := toNumeric(vm.stack[vm.sp-1])
+	if _, _, ok := bigIntOperands(left, right); ok {
+		panic(typeError("BigInts have no unsigned right shift, use >> instead"))
+	}
+	vm.stack[vm.sp-2] = intToValue(int64(toUint32(left) >> (toUint32(right) & 0x1F)))
 	vm.sp--
 	vm.pc++
 }
@@ -1494,6 +1737,9 @@ func (_shr) exec(vm *vm) {
 type jump int32
 
 func (j jump) exec(vm *vm) {
+	if j <= 0 && vm.timeLimit > 0 {
+		vm.checkTimeLimit()
+	}
 	vm.pc += int(j)
 }
 
@@ -1520,9 +1766,9 @@ var getElemRef _getElemRef
 func (_getElemRef) exec(vm *vm) {
 	obj := vm.stack[vm.sp-2].ToObject(vm.r)
 	propName := toPropertyKey(vm.stack[vm.sp-1])
-	vm.refStack = append(vm.refStack, &objRef{
+	vm.refStack = append(vm.refStack, &objKeyRef{
 		base: obj,
-		name: propName.string(),
+		name: propName,
 	})
 	vm.sp -= 2
 	vm.pc++
@@ -1535,9 +1781,9 @@ var getElemRefRecv _getElemRefRecv
 func (_getElemRefRecv) exec(vm *vm) {
 	obj := vm.stack[vm.sp-1].ToObject(vm.r)
 	propName := toPropertyKey(vm.stack[vm.sp-2])
-	vm.refStack = append(vm.refStack, &objRef{
+	vm.refStack = append(vm.refStack, &objKeyRef{
 		base: obj,
-		name: propName.string(),
+		name: propName,
 		this: vm.stack[vm.sp-3],
 	})
 	vm.sp -= 3
@@ -1551,9 +1797,9 @@ var getElemRefStrict _getElemRefStrict
 func (_getElemRefStrict) exec(vm *vm) {
 	obj := vm.stack[vm.sp-2].ToObject(vm.r)
 	propName := toPropertyKey(vm.stack[vm.sp-1])
-	vm.refStack = append(vm.refStack, &objRef{
+	vm.refStack = append(vm.refStack, &objKeyRef{
 		base:   obj,
-		name:   propName.string(),
+		name:   propName,
 		strict: true,
 	})
 	vm.sp -= 2
@@ -1567,9 +1813,9 @@ var getElemRefRecvStrict _getElemRefRecvStrict
 func (_getElemRefRecvStrict) exec(vm *vm) {
 	obj := vm.stack[vm.sp-1].ToObject(vm.r)
 	propName := toPropertyKey(vm.stack[vm.sp-2])
-	vm.refStack = append(vm.refStack, &objRef{
+	vm.refStack = append(vm.refStack, &objKeyRef{
 		base:   obj,
-		name:   propName.string(),
+		name:   propName,
 		this:   vm.stack[vm.sp-3],
 		strict: true,
 	})
@@ -2414,6 +2660,7 @@ var pushArrayItem _pushArrayItem
 
 func (_pushArrayItem) exec(vm *vm) {
 	arr := vm.stack[vm.sp-2].(*Object).self.(*arrayObject)
+	vm.r.checkArrayLength(int64(arr.length) + 1)
 	if arr.length < math.MaxUint32 {
 		arr.length++
 	} else {
@@ -2436,6 +2683,7 @@ var pushArraySpread _pushArraySpread
 func (_pushArraySpread) exec(vm *vm) {
 	arr := vm.stack[vm.sp-2].(*Object).self.(*arrayObject)
 	vm.r.getIterator(vm.stack[vm.sp-1], nil).iterate(func(val Value) {
+		vm.r.checkArrayLength(int64(arr.length) + 1)
 		if arr.length < math.MaxUint32 {
 			arr.length++
 		} else {
@@ -2456,7 +2704,10 @@ var pushSpread _pushSpread
 func (_pushSpread) exec(vm *vm) {
 	vm.sp--
 	obj := vm.stack[vm.sp]
+	var n int64
 	vm.r.getIterator(obj, nil).iterate(func(val Value) {
+		n++
+		vm.r.checkArguments(n)
 		vm.push(val)
 	})
 	vm.pc++
@@ -2734,6 +2985,29 @@ func (g loadStashLex) exec(vm *vm) {
 	vm.pc++
 }
 
+// load an imported binding (see importedBinding)
+type loadImport uint32
+
+func (g loadImport) exec(vm *vm) {
+	level := int(g >> 24)
+	idx := uint32(g & 0x00FFFFFF)
+	stash := vm.stash
+	for i := 0; i < level; i++ {
+		stash = stash.outer
+	}
+
+	v := stash.getByIdx(idx)
+	if b, ok := v.(*importedBinding); ok {
+		v = b.get()
+	}
+	if v == nil {
+		vm.throw(errAccessBeforeInit)
+		return
+	}
+	vm.push(v)
+	vm.pc++
+}
+
 // scan dynamic stashes up to the given level (encoded as 8 most significant bits of idx), if not found
 // return the indexed var binding value from stash
 type loadMixed struct {
@@ -2968,6 +3242,14 @@ func newStashRef(typ varType, name unistring.String, v *[]Value, idx int) ref {
 			},
 			strictConst: typ == varTypeStrictConst,
 		}
+	case varTypeImport:
+		return &importRef{
+			stashRef: stashRef{
+				n:   name,
+				v:   v,
+				idx: idx,
+			},
+		}
 	}
 	panic("unsupported var type")
 }
@@ -3097,6 +3379,17 @@ func (_putValueP) exec(vm *vm) {
 	vm.pc++
 }
 
+type _popRef struct{}
+
+var popRef _popRef
+
+func (_popRef) exec(vm *vm) {
+	l := len(vm.refStack) - 1
+	vm.refStack[l] = nil
+	vm.refStack = vm.refStack[:l]
+	vm.pc++
+}
+
 type _initValueP struct{}
 
 var initValueP _initValueP
@@ -3555,6 +3848,30 @@ func (e *enterFuncBody) exec(vm *vm) {
 	vm.pc++
 }
 
+type tailCall uint32
+
+func (numargs tailCall) exec(vm *vm) {
+	// this
+	// callee
+	// arg0
+	// ...
+	// arg<numargs-1>
+	n := int(numargs)
+	obj := vm.toCallee(vm.stack[vm.sp-n-1])
+	if _, ok := obj.self.assertCallable(); !ok {
+		// let it throw from the current frame
+		obj.self.vmCall(vm, n)
+		return
+	}
+	// Replace the current frame with the callee and return to the caller, so that when the callee
+	// returns, it returns directly to the caller.
+	base := vm.sb - 1
+	copy(vm.stack[base:], vm.stack[vm.sp-n-2:vm.sp])
+	vm.sp = base + n + 2
+	vm.popCtx()
+	obj.self.vmCall(vm, n)
+}
+
 type _ret struct{}
 
 var ret _ret
@@ -3949,6 +4266,9 @@ type jne int32
 func (j jne) exec(vm *vm) {
 	vm.sp--
 	if !vm.stack[vm.sp].ToBoolean() {
+		if j <= 0 && vm.timeLimit > 0 {
+			vm.checkTimeLimit()
+		}
 		vm.pc += int(j)
 	} else {
 		vm.pc++
@@ -3960,6 +4280,9 @@ type jeq int32
 func (j jeq) exec(vm *vm) {
 	vm.sp--
 	if vm.stack[vm.sp].ToBoolean() {
+		if j <= 0 && vm.timeLimit > 0 {
+			vm.checkTimeLimit()
+		}
 		vm.pc += int(j)
 	} else {
 		vm.pc++
@@ -3970,6 +4293,9 @@ type jeq1 int32
 
 func (j jeq1) exec(vm *vm) {
 	if vm.stack[vm.sp-1].ToBoolean() {
+		if j <= 0 && vm.timeLimit > 0 {
+			vm.checkTimeLimit()
+		}
 		vm.pc += int(j)
 	} else {
 		vm.sp--
@@ -3981,6 +4307,9 @@ type jneq1 int32
 
 func (j jneq1) exec(vm *vm) {
 	if !vm.stack[vm.sp-1].ToBoolean() {
+		if j <= 0 && vm.timeLimit > 0 {
+			vm.checkTimeLimit()
+		}
 		vm.pc += int(j)
 	} else {
 		vm.sp--
@@ -4069,6 +4398,21 @@ func toPrimitiveNumber(v Value) Value {
 	return v
 }
 
+// toNumeric implements https://tc39.es/ecma262/#sec-tonumeric
+func toNumeric(v Value) Value {
+	switch v := v.(type) {
+	case valueInt, valueFloat, *valueBigInt:
+		return v
+	case *Object:
+		prim := v.toPrimitiveNumber()
+		if bi, ok := prim.(*valueBigInt); ok {
+			return bi
+		}
+		return prim.ToNumber()
+	}
+	return v.ToNumber()
+}
+
 func toPrimitive(v Value) Value {
 	if o, ok := v.(*Object); ok {
 		return o.toPrimitive()
@@ -4094,6 +4438,13 @@ func cmp(px, py Value) Value {
 		}
 	}
 
+	if _, ok := px.(*valueBigInt); ok {
+		return cmpBigInt(px, py)
+	}
+	if _, ok := py.(*valueBigInt); ok {
+		return cmpBigInt(px, py)
+	}
+
 	nx = px.ToFloat()
 	ny = py.ToFloat()
 
@@ -4111,11 +4462,55 @@ end:
 
 }
 
+// cmpBigInt is the part of cmp() that handles the case when at least one of the operands is a BigInt.
+func cmpBigInt(px, py Value) Value {
+	var c int
+	if x, ok := px.(*valueBigInt); ok {
+		switch y := py.(type) {
+		case *valueBigInt:
+			c = (*big.Int)(x).Cmp((*big.Int)(y))
+		case valueString:
+			yb := stringToBigInt(y)
+			if yb == nil {
+				return _undefined
+			}
+			c = (*big.Int)(x).Cmp(yb)
+		default:
+			var ok bool
+			if c, ok = compareBigIntFloat((*big.Int)(x), py.ToFloat()); !ok {
+				return _undefined
+			}
+		}
+	} else {
+		y := (*big.Int)(py.(*valueBigInt))
+		if xs, ok := px.(valueString); ok {
+			xb := stringToBigInt(xs)
+			if xb == nil {
+				return _undefined
+			}
+			c = xb.Cmp(y)
+		} else {
+			if c, ok = compareBigIntFloat(y, px.ToFloat()); !ok {
+				return _undefined
+			}
+			c = -c
+		}
+	}
+	if c < 0 {
+		return valueTrue
+	}
+	return valueFalse
+}
+
 type _op_lt struct{}
 
 var op_lt _op_lt
 
 func (_op_lt) exec(vm *vm) {
+	if vm.decimalCmpOp(func(c int) bool { return c < 0 }) {
+		return
+	}
+
 	left := toPrimitiveNumber(vm.stack[vm.sp-2])
 	right := toPrimitiveNumber(vm.stack[vm.sp-1])
 
@@ -4134,6 +4529,10 @@ type _op_lte struct{}
 var op_lte _op_lte
 
 func (_op_lte) exec(vm *vm) {
+	if vm.decimalCmpOp(func(c int) bool { return c <= 0 }) {
+		return
+	}
+
 	left := toPrimitiveNumber(vm.stack[vm.sp-2])
 	right := toPrimitiveNumber(vm.stack[vm.sp-1])
 
@@ -4153,6 +4552,10 @@ type _op_gt struct{}
 var op_gt _op_gt
 
 func (_op_gt) exec(vm *vm) {
+	if vm.decimalCmpOp(func(c int) bool { return c > 0 }) {
+		return
+	}
+
 	left := toPrimitiveNumber(vm.stack[vm.sp-2])
 	right := toPrimitiveNumber(vm.stack[vm.sp-1])
 
@@ -4171,6 +4574,10 @@ type _op_gte struct{}
 var op_gte _op_gte
 
 func (_op_gte) exec(vm *vm) {
+	if vm.decimalCmpOp(func(c int) bool { return c >= 0 }) {
+		return
+	}
+
 	left := toPrimitiveNumber(vm.stack[vm.sp-2])
 	right := toPrimitiveNumber(vm.stack[vm.sp-1])
 
@@ -4445,6 +4852,34 @@ func (_loadNewTarget) exec(vm *vm) {
 	vm.pc++
 }
 
+type loadImportMeta struct {
+	module *SourceTextModuleRecord
+}
+
+func (l *loadImportMeta) exec(vm *vm) {
+	vm.push(vm.r.getImportMeta(l.module))
+	vm.pc++
+}
+
+type importCall struct {
+	referrer   *SourceTextModuleRecord
+	hasOptions bool
+}
+
+func (i *importCall) exec(vm *vm) {
+	var referrer ModuleRecord
+	if i.referrer != nil {
+		referrer = i.referrer
+	}
+	options := Value(_undefined)
+	if i.hasOptions {
+		options = vm.stack[vm.sp-1]
+		vm.sp--
+	}
+	vm.stack[vm.sp-1] = vm.r.importModuleDynamically(referrer, vm.stack[vm.sp-1], options)
+	vm.pc++
+}
+
 type _typeof struct{}
 
 var typeof _typeof
@@ -4464,8 +4899,14 @@ func (_typeof) exec(vm *vm) {
 		r = stringString
 	case valueInt, valueFloat:
 		r = stringNumber
+	case *valueBigInt:
+		r = stringBigInt
 	case *Symbol:
 		r = stringSymbol
+	case *valueRecord:
+		r = stringRecord
+	case *valueTuple:
+		r = stringTuple
 	default:
 		panic(newTypeError("Compiler bug: unknown type: %T", v))
 	}
@@ -4691,6 +5132,111 @@ func (iterGetNextOrUndef) exec(vm *vm) {
 	vm.pc++
 }
 
+type _iterateAsyncP struct{}
+
+var iterateAsyncP _iterateAsyncP
+
+func (_iterateAsyncP) exec(vm *vm) {
+	iter := vm.r.getAsyncIterator(vm.stack[vm.sp-1])
+	vm.iterStack = append(vm.iterStack, iterStackItem{iter: iter})
+	vm.sp--
+	vm.pc++
+}
+
+func (vm *vm) popIterThrow(ex *Exception) {
+	l := len(vm.iterStack) - 1
+	vm.iterStack[l] = iterStackItem{}
+	vm.iterStack = vm.iterStack[:l]
+	vm.throw(ex.val)
+}
+
+type _iterNextAsync struct{}
+
+// iterNextAsync calls next() on the async iterator and pushes the result which must then be awaited.
+var iterNextAsync _iterNextAsync
+
+func (_iterNextAsync) exec(vm *vm) {
+	l := len(vm.iterStack) - 1
+	iter := vm.iterStack[l].iter
+	var res Value
+	ex := vm.try(func() {
+		if iter.next == nil {
+			panic(vm.r.NewTypeError("iterator.next is missing or not a function"))
+		}
+		res = iter.next(FunctionCall{This: iter.iterator})
+	})
+	if ex != nil {
+		vm.popIterThrow(ex)
+		return
+	}
+	vm.iterStack[l].iter, vm.iterStack[l].awaiting = nil, iter
+	vm.push(res)
+	vm.pc++
+}
+
+// iterNextAsyncResult processes the awaited result of iterNextAsync. If the iterator is done it jumps to the end
+// of the loop, otherwise the value is stored so that enumGet can retrieve it.
+type iterNextAsyncResult int32
+
+func (jmp iterNextAsyncResult) exec(vm *vm) {
+	l := len(vm.iterStack) - 1
+	vm.iterStack[l].iter, vm.iterStack[l].awaiting = vm.iterStack[l].awaiting, nil
+	res := vm.stack[vm.sp-1]
+	vm.sp--
+	var value Value
+	ex := vm.try(func() {
+		obj, ok := res.(*Object)
+		if !ok {
+			panic(vm.r.NewTypeError("Iterator result %s is not an object", res.String()))
+		}
+		if !nilSafe(obj.self.getStr("done", nil)).ToBoolean() {
+			value = nilSafe(obj.self.getStr("value", nil))
+		}
+	})
+	if ex != nil {
+		vm.popIterThrow(ex)
+		return
+	}
+	if value == nil {
+		vm.pc += int(jmp)
+	} else {
+		vm.iterStack[l].val = value
+		vm.pc++
+	}
+}
+
+// iterCloseAsync pops the async iterator and calls its return() method pushing the result which must then be
+// awaited. If there is no return() method it jumps over the code that does that.
+type iterCloseAsync int32
+
+func (jmp iterCloseAsync) exec(vm *vm) {
+	l := len(vm.iterStack) - 1
+	iter := vm.iterStack[l].iter
+	vm.iterStack[l] = iterStackItem{}
+	vm.iterStack = vm.iterStack[:l]
+	if iterator := iter.iterator; iterator != nil {
+		iter.close()
+		if retMethod := toMethod(iterator.self.getStr("return", nil)); retMethod != nil {
+			vm.push(retMethod(FunctionCall{This: iterator}))
+			vm.pc++
+			return
+		}
+	}
+	vm.pc += int(jmp)
+}
+
+type _iterCloseAsyncResult struct{}
+
+var iterCloseAsyncResult _iterCloseAsyncResult
+
+func (_iterCloseAsyncResult) exec(vm *vm) {
+	if _, ok := vm.stack[vm.sp-1].(*Object); !ok {
+		panic(vm.r.NewTypeError("Iterator result %s is not an object", vm.stack[vm.sp-1].String()))
+	}
+	vm.sp--
+	vm.pc++
+}
+
 type copyStash struct{}
 
 func (copyStash) exec(vm *vm) {
@@ -4815,6 +5361,7 @@ func (n concatStrings) exec(vm *vm) {
 		}
 	}
 
+	vm.r.checkStringLength(int64(length))
 	vm.sp -= int(n) - 1
 	if allAscii {
 		var buf strings.Builder
@@ -4838,25 +5385,19 @@ type getTaggedTmplObject struct {
 	raw, cooked []Value
 }
 
-// As tagged template objects are not cached (because it's hard to ensure the cache is cleaned without using
-// finalizers) this wrapper is needed to override the equality method so that two objects for the same template
-// literal appeared to be equal from the code's point of view.
-type taggedTemplateArray struct {
-	*arrayObject
-	idPtr *[]Value
-}
-
-func (a *taggedTemplateArray) equal(other objectImpl) bool {
-	if o, ok := other.(*taggedTemplateArray); ok {
-		return a.idPtr == o.idPtr
-	}
-	return false
-}
-
+// exec implements GetTemplateObject (https://tc39.es/ecma262/#sec-gettemplateobject). The template objects are
+// kept in the Runtime's template registry so that each evaluation of the same tagged template returns the same
+// object. The instruction is used as the key because a Program can be shared between multiple runtimes.
 func (c *getTaggedTmplObject) exec(vm *vm) {
-	cooked := vm.r.newArrayObject()
+	r := vm.r
+	if obj := r.templateRegistry[c]; obj != nil {
+		vm.push(obj)
+		vm.pc++
+		return
+	}
+	cooked := r.newArrayObject()
 	setArrayValues(cooked, c.cooked)
-	raw := vm.r.newArrayObject()
+	raw := r.newArrayObject()
 	setArrayValues(raw, c.raw)
 
 	cooked.propValueCount = len(c.cooked)
@@ -4866,17 +5407,14 @@ func (c *getTaggedTmplObject) exec(vm *vm) {
 	raw.lengthProp.writable = false
 
 	raw.preventExtensions(true)
-	raw.val.self = &taggedTemplateArray{
-		arrayObject: raw,
-		idPtr:       &c.raw,
-	}
 
 	cooked._putProp("raw", raw.val, false, false, false)
 	cooked.preventExtensions(true)
-	cooked.val.self = &taggedTemplateArray{
-		arrayObject: cooked,
-		idPtr:       &c.cooked,
+
+	if r.templateRegistry == nil {
+		r.templateRegistry = make(map[*getTaggedTmplObject]*Object)
 	}
+	r.templateRegistry[c] = cooked.val
 
 	vm.push(cooked.val)
 	vm.pc++
@@ -5502,3 +6040,65 @@ func (await) exec(vm *vm) {
 	vm.pc = -vm.pc             // this will terminate the run loop
 	vm.push(resultAwaitMarker) // a special marker value to indicate this is an await, not return
 }
+
+type _newDisposeCapability struct{}
+
+// newDisposeCapability pushes a new holder for the resources of a block with 'using' declarations.
+var newDisposeCapability _newDisposeCapability
+
+func (_newDisposeCapability) exec(vm *vm) {
+	vm.push(vm.r.newDisposableStackObject(nil, classDisposableStack).val)
+	vm.pc++
+}
+
+// addDisposableResource adds the value below the dispose capability on the stack to the list of resources.
+// The capability is popped, the value is left on the stack. The value of the instruction is set for
+// 'await using' declarations.
+type addDisposableResource bool
+
+func (a addDisposableResource) exec(vm *vm) {
+	s := vm.stack[vm.sp-1].(*Object).self.(*disposableStackObject)
+	vm.sp--
+	s.capability.add(vm.r, vm.stack[vm.sp-1], bool(a))
+	vm.pc++
+}
+
+type _disposeError struct{}
+
+// disposeError records the exception below the dispose capability on the stack and pops both.
+var disposeError _disposeError
+
+func (_disposeError) exec(vm *vm) {
+	s := vm.stack[vm.sp-1].(*Object).self.(*disposableStackObject)
+	s.capability.addError(vm.r, vm.stack[vm.sp-2])
+	vm.sp -= 2
+	vm.pc++
+}
+
+type _disposeResources struct{}
+
+// disposeResources pops the dispose capability, disposes the remaining resources and throws the recorded
+// exception, if any.
+var disposeResources _disposeResources
+
+func (_disposeResources) exec(vm *vm) {
+	s := vm.stack[vm.sp-1].(*Object).self.(*disposableStackObject)
+	vm.sp--
+	s.capability.disposeSync(vm.r)
+	vm.pc++
+}
+
+// disposeNextAsync pops the dispose capability and disposes the resources until it reaches an 'await using'
+// one. The value that needs to be awaited is pushed onto the stack. If there are no resources left it jumps.
+type disposeNextAsync int32
+
+func (jmp disposeNextAsync) exec(vm *vm) {
+	s := vm.stack[vm.sp-1].(*Object).self.(*disposableStackObject)
+	vm.sp--
+	if v, ok := s.capability.next(vm.r); ok {
+		vm.push(v)
+		vm.pc++
+	} else {
+		vm.pc += int(jmp)
+	}
+}
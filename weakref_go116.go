@@ -0,0 +1,33 @@
+//go:build !go1.24
+// +build !go1.24
+
+package goja
+
+// weakObjectRef is a reference to an Object. Weak pointers require Go 1.24 or later, with older versions
+// the reference is strong, i.e. WeakRef targets are never collected while the WeakRef is reachable.
+type weakObjectRef struct {
+	o *Object
+}
+
+func newWeakObjectRef(o *Object) weakObjectRef {
+	return weakObjectRef{o: o}
+}
+
+func (w weakObjectRef) get() *Object {
+	return w.o
+}
+
+// weakCollectionKeys is a no-op with older Go versions: the keys cannot be tracked without keeping them alive,
+// so the entries of a collected WeakMap or WeakSet remain in its keys until the keys themselves are collected.
+type weakCollectionKeys struct{}
+
+func (weakCollectionKeys) add(*Object)    {}
+func (weakCollectionKeys) remove(*Object) {}
+
+type weakCollectionCleanupQueue struct{}
+
+func (r *Runtime) newWeakCollection(*Object) *weakCollection {
+	return &weakCollection{id: weakMap(r.genId())}
+}
+
+func (r *Runtime) cleanupWeakCollections() {}
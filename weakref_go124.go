@@ -0,0 +1,94 @@
+//go:build go1.24
+// +build go1.24
+
+package goja
+
+import (
+	"runtime"
+	"sync"
+	"weak"
+)
+
+// weakObjectRef is a reference to an Object which does not prevent it from being garbage collected.
+type weakObjectRef weak.Pointer[Object]
+
+func newWeakObjectRef(o *Object) weakObjectRef {
+	return weakObjectRef(weak.Make(o))
+}
+
+// get returns the referenced Object or nil if it has been garbage collected.
+func (w weakObjectRef) get() *Object {
+	return weak.Pointer[Object](w).Value()
+}
+
+// weakCollectionKeys tracks the keys of a WeakMap or a WeakSet without keeping them alive.
+type weakCollectionKeys struct {
+	refs    map[weakObjectRef]struct{}
+	pruneAt int
+}
+
+func (k *weakCollectionKeys) add(o *Object) {
+	if k.refs == nil {
+		k.refs = make(map[weakObjectRef]struct{})
+	}
+	k.refs[newWeakObjectRef(o)] = struct{}{}
+	if len(k.refs) >= k.pruneAt {
+		// drop the references to the keys that have been collected, amortised over the insertions
+		for ref := range k.refs {
+			if ref.get() == nil {
+				delete(k.refs, ref)
+			}
+		}
+		k.pruneAt = 2*len(k.refs) + 16
+	}
+}
+
+func (k *weakCollectionKeys) remove(o *Object) {
+	delete(k.refs, newWeakObjectRef(o))
+}
+
+// weakCollectionCleanupQueue receives the collections that have been garbage collected. It is filled by
+// runtime cleanups, which run in a separate goroutine, and drained by the Runtime. It must not reference the
+// Runtime, otherwise the cleanups would keep it, and everything reachable from it, alive.
+type weakCollectionCleanupQueue struct {
+	mu    sync.Mutex
+	queue []*weakCollection
+}
+
+func (q *weakCollectionCleanupQueue) push(c *weakCollection) {
+	q.mu.Lock()
+	q.queue = append(q.queue, c)
+	q.mu.Unlock()
+}
+
+func (r *Runtime) newWeakCollection(o *Object) *weakCollection {
+	r.cleanupWeakCollections()
+	c := &weakCollection{id: weakMap(r.genId())}
+	q := r.weakCollectionCleanup
+	if q == nil {
+		q = &weakCollectionCleanupQueue{}
+		r.weakCollectionCleanup = q
+	}
+	runtime.AddCleanup(o, q.push, c)
+	return c
+}
+
+// cleanupWeakCollections removes the entries of the collections that have been garbage collected from their
+// keys. It must be called from the Runtime's goroutine.
+func (r *Runtime) cleanupWeakCollections() {
+	q := r.weakCollectionCleanup
+	if q == nil {
+		return
+	}
+	q.mu.Lock()
+	queue := q.queue
+	q.queue = nil
+	q.mu.Unlock()
+	for _, c := range queue {
+		for ref := range c.keys.refs {
+			if o := ref.get(); o != nil {
+				delete(o.weakRefs, c.id)
+			}
+		}
+	}
+}
@@ -0,0 +1,118 @@
+//go:build go1.24
+// +build go1.24
+
+package goja
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestWeakRefCollected(t *testing.T) {
+	vm := New()
+	_, err := vm.RunString(`
+	var ref;
+	(function() {
+		ref = new WeakRef({});
+	})();
+	var strong = {};
+	var strongRef = new WeakRef(strong);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	runtime.GC()
+	runtime.GC()
+	res, err := vm.RunString(`ref.deref() === undefined && strongRef.deref() === strong`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != valueTrue {
+		t.Fatal("The target has not been collected")
+	}
+}
+
+func TestWeakRefKeptDuringJob(t *testing.T) {
+	vm := New()
+	vm.Set("gc", func() {
+		runtime.GC()
+		runtime.GC()
+	})
+	res, err := vm.RunString(`
+	var target = new WeakRef({});
+	gc();
+	target.deref() !== undefined;
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != valueTrue {
+		t.Fatal("The target has been collected before the end of the job")
+	}
+}
+
+func TestWeakMapCollectedEntriesRemoved(t *testing.T) {
+	vm := New()
+	_, err := vm.RunString(`
+	var key = {};
+	for (var i = 0; i < 100; i++) {
+		new WeakMap().set(key, {});
+		new WeakSet().add(key);
+	}
+	var wm = new WeakMap();
+	wm.set(key, 42);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := vm.Get("key").(*Object)
+	for i := 0; i < 100; i++ {
+		runtime.GC()
+		// the cleanups run asynchronously, the entries are removed when control leaves the Runtime
+		time.Sleep(time.Millisecond)
+		if _, err := vm.RunString(""); err != nil {
+			t.Fatal(err)
+		}
+		if len(key.weakRefs) == 1 {
+			break
+		}
+	}
+	if l := len(key.weakRefs); l != 1 {
+		t.Fatalf("Unexpected number of entries: %d", l)
+	}
+	res, err := vm.RunString(`wm.get(key)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.ToInteger() != 42 {
+		t.Fatalf("Unexpected value: %v", res)
+	}
+}
+
+func TestWeakMapRuntimeCollected(t *testing.T) {
+	collected := make(chan struct{})
+	func() {
+		vm := New()
+		_, err := vm.RunString(`
+		var key = {};
+		var wm = new WeakMap();
+		wm.set(key, wm);
+		var ws = new WeakSet();
+		ws.add(key);
+		`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		runtime.AddCleanup(vm, func(ch chan struct{}) { close(ch) }, collected)
+	}()
+	for i := 0; i < 100; i++ {
+		runtime.GC()
+		select {
+		case <-collected:
+			return
+		case <-time.After(time.Millisecond):
+		}
+	}
+	t.Fatal("The Runtime has not been collected")
+}